@@ -0,0 +1,120 @@
+// Package benchprog 加载testdata/bench中有代表性的脚本，供各个包的基准测试共用
+// 树遍历解释器和之后的字节码虚拟机在同一组脚本上运行，结果可以直接比较
+// 脚本不使用与时间有关的内置函数，输出只由源代码决定，并用assert检查计算结果
+package benchprog
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Program 基准测试脚本
+
+type Program struct {
+	Name   string // 脚本名，即不含扩展名的文件名
+	File   string // 脚本文件的路径
+	Source string // 源代码
+}
+
+// Runner 完整执行一个脚本，包括分析和执行，脚本出错或断言失败时返回错误
+// 每个执行引擎提供一个Runner，在同一组脚本上比较
+
+type Runner func(file, source string) error
+
+// Dir 返回基准测试脚本所在的目录
+//
+// 返回值:
+//
+//	string - 仓库根目录下的testdata/bench目录
+func Dir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "testdata", "bench")
+}
+
+// Load 按文件名顺序加载所有基准测试脚本
+//
+// 参数:
+//
+//	tb - 测试或基准测试，读取失败时终止
+//
+// 返回值:
+//
+//	[]*Program - 所有基准测试脚本
+func Load(tb testing.TB) []*Program {
+	tb.Helper()
+	files, err := filepath.Glob(filepath.Join(Dir(), "*.gh"))
+	if err != nil || len(files) == 0 {
+		tb.Fatalf("no benchmark programs in %s: %v", Dir(), err)
+	}
+	programs := make([]*Program, 0, len(files))
+	for _, file := range files {
+		programs = append(programs, read(tb, file))
+	}
+	return programs
+}
+
+// Get 加载指定名称的基准测试脚本
+//
+// 参数:
+//
+//	tb - 测试或基准测试，读取失败时终止
+//	name - 脚本名，不含扩展名
+//
+// 返回值:
+//
+//	*Program - 基准测试脚本
+func Get(tb testing.TB, name string) *Program {
+	tb.Helper()
+	return read(tb, filepath.Join(Dir(), name+".gh"))
+}
+
+// read 读取基准测试脚本
+//
+// 参数:
+//
+//	tb - 测试或基准测试，读取失败时终止
+//	file - 脚本文件的路径
+//
+// 返回值:
+//
+//	*Program - 基准测试脚本
+func read(tb testing.TB, file string) *Program {
+	tb.Helper()
+	source, err := os.ReadFile(file)
+	if err != nil {
+		tb.Fatalf("err = %+v, expected nil", err)
+	}
+	return &Program{
+		Name:   strings.TrimSuffix(filepath.Base(file), ".gh"),
+		File:   file,
+		Source: string(source),
+	}
+}
+
+// Run 用runner依次执行每个脚本，每个脚本是一个子基准测试并报告内存分配
+// 计时之前先执行一次，脚本出错时终止，避免测量出错的执行
+//
+// 参数:
+//
+//	b - 基准测试
+//	programs - 要执行的脚本
+//	runner - 执行引擎
+func Run(b *testing.B, programs []*Program, runner Runner) {
+	for _, program := range programs {
+		b.Run(program.Name, func(b *testing.B) {
+			if err := runner(program.File, program.Source); err != nil {
+				b.Fatalf("err = %+v, expected nil", err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				if err := runner(program.File, program.Source); err != nil {
+					b.Fatalf("err = %+v, expected nil", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+// DumpAST 解析指定的.gh文件并输出AST，不执行程序
+// 解析失败时输出错误并返回非零退出码
+//
+// 参数:
+//
+//	fileName - 要解析的文件路径
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) DumpAST(fileName string) int {
+	tree, err := dumpAST(fileName)
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	fmt.Fprint(s.stdout, tree)
+	// 刷新标准输出缓冲区
+	syncOutput(s.stdout)
+	return exitOK
+}
+
+// dumpAST 解析指定的.gh文件并返回AST的缩进树表示
+//
+// 参数:
+//
+//	fileName - 要解析的文件路径
+//
+// 返回值:
+//
+//	string - AST的缩进树表示
+//	error - 读取或解析过程中的错误
+func dumpAST(fileName string) (string, error) {
+	absPath, code, err := readSourceFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	l := lexer.NewLexer(filepath.Base(absPath), code)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		return "", err
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return "", p.Err
+	}
+	return ast.Dump(program), nil
+}
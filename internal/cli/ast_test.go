@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCLI_DumpAST(t *testing.T) {
+	got, err := dumpAST("testdata/ast.gh")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	expected, err := os.ReadFile("testdata/ast.golden")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %+v", err)
+	}
+	if got != string(expected) {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCLI_DumpAST_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+	}{
+		{
+			name:     "Invalid Extension",
+			fileName: "testdata/ast.golden",
+		},
+		{
+			name:     "File Not Found",
+			fileName: "testdata/missing.gh",
+		},
+		{
+			name:     "Syntax Error",
+			fileName: "testdata/syntax_error.gh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := dumpAST(tt.fileName); err == nil {
+				t.Errorf("err = nil, expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/Ghost-Xiao/ghost-lang/pkg/ghost"
+)
+
+// benchTime 未指定 -count 时基准测试的目标总执行时间
+const benchTime = time.Second
+
+// maxBenchRuns 未指定 -count 时基准测试的最大执行次数
+const maxBenchRuns = 1000000
+
+// benchResult 基准测试的统计结果
+
+type benchResult struct {
+	Runs         int           // 执行次数
+	Min          time.Duration // 最短执行时间
+	Median       time.Duration // 执行时间的中位数
+	Mean         time.Duration // 平均执行时间
+	BytesPerRun  uint64        // 每次执行平均分配的字节数
+	AllocsPerRun uint64        // 每次执行平均的内存分配次数
+}
+
+// RunBench 执行bench子命令，多次执行脚本并报告执行时间和内存分配统计
+// 脚本只解析一次，只对执行阶段计时，执行期间脚本的标准输出被丢弃
+//
+// 参数:
+//
+//	args - bench子命令的参数，包括 -count 标志、脚本路径和传递给脚本的参数
+//
+// 返回值:
+//
+//	int - 进程退出码，脚本出错时与直接运行脚本相同
+func (s *session) RunBench(args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	count := flags.Int("count", 0, "Count")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 || *count < 0 {
+		return s.invalidArguments()
+	}
+	fileName := flags.Arg(0)
+	absPath, code, err := readSourceFile(fileName)
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	program, err := ghost.Compile(absPath, code)
+	if err != nil {
+		err = internalError(err)
+		s.printError(err)
+		return exitCode(err)
+	}
+	result, err := s.benchProgram(program, flags.Args()[1:], *count)
+	if err != nil {
+		if status, ok := requestedExit(err); ok {
+			return status
+		}
+		s.printError(err)
+		return exitCode(err)
+	}
+	fmt.Fprintln(s.stdout, formatBenchResult(fileName, result))
+	return exitOK
+}
+
+// benchProgram 多次执行已加载的程序并统计结果
+// count为0时持续执行直到总执行时间达到benchTime
+//
+// 参数:
+//
+//	program - 编译后的程序
+//	scriptArgs - 传递给脚本的命令行参数
+//	count - 执行次数，为0时自动确定
+//
+// 返回值:
+//
+//	*benchResult - 统计结果
+//	error - 执行出错时的错误，调用exit(0)不视为错误
+func (s *session) benchProgram(program *ghost.Program, scriptArgs []string, count int) (*benchResult, error) {
+	// 执行期间丢弃脚本的标准输出
+	quiet := *s
+	quiet.stdout = io.Discard
+
+	var durations []time.Duration
+	var total time.Duration
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for {
+		if count > 0 && len(durations) == count {
+			break
+		}
+		if count == 0 && len(durations) > 0 && (total >= benchTime || len(durations) == maxBenchRuns) {
+			break
+		}
+		startTime := time.Now()
+		_, err := quiet.executeProgram(program, scriptArgs)
+		elapsed := time.Since(startTime)
+		if status, ok := requestedExit(err); ok && status == exitOK {
+			err = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, elapsed)
+		total += elapsed
+	}
+	runtime.ReadMemStats(&after)
+	return summarizeBench(durations, after.TotalAlloc-before.TotalAlloc, after.Mallocs-before.Mallocs), nil
+}
+
+// summarizeBench 根据每次执行的时间和总内存分配计算统计结果
+//
+// 参数:
+//
+//	durations - 每次执行的时间，至少包含一项
+//	bytes - 所有执行分配的总字节数
+//	allocs - 所有执行的总内存分配次数
+//
+// 返回值:
+//
+//	*benchResult - 统计结果
+func summarizeBench(durations []time.Duration, bytes, allocs uint64) *benchResult {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	n := len(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return &benchResult{
+		Runs:         n,
+		Min:          sorted[0],
+		Median:       median,
+		Mean:         total / time.Duration(n),
+		BytesPerRun:  bytes / uint64(n),
+		AllocsPerRun: allocs / uint64(n),
+	}
+}
+
+// formatBenchResult 将统计结果格式化为单行文本，各项以制表符分隔，时间统一以纳秒为单位，便于在不同提交之间比较
+//
+// 参数:
+//
+//	name - 脚本名称
+//	result - 统计结果
+//
+// 返回值:
+//
+//	string - 格式化后的统计结果
+func formatBenchResult(name string, result *benchResult) string {
+	return fmt.Sprintf("%s\t%d runs\t%d ns/op min\t%d ns/op median\t%d ns/op mean\t%d B/op\t%d allocs/op",
+		name, result.Runs, result.Min.Nanoseconds(), result.Median.Nanoseconds(), result.Mean.Nanoseconds(),
+		result.BytesPerRun, result.AllocsPerRun)
+}
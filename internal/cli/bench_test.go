@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Ghost-Xiao/ghost-lang/pkg/ghost"
+)
+
+func TestCLI_SummarizeBench(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		bytes     uint64
+		allocs    uint64
+		excepted  *benchResult
+	}{
+		{
+			name:      "Odd Count",
+			durations: []time.Duration{30, 10, 20},
+			bytes:     300,
+			allocs:    9,
+			excepted:  &benchResult{Runs: 3, Min: 10, Median: 20, Mean: 20, BytesPerRun: 100, AllocsPerRun: 3},
+		},
+		{
+			name:      "Even Count",
+			durations: []time.Duration{40, 10, 30, 20},
+			bytes:     10,
+			allocs:    0,
+			excepted:  &benchResult{Runs: 4, Min: 10, Median: 25, Mean: 25, BytesPerRun: 2, AllocsPerRun: 0},
+		},
+		{
+			name:      "Single Run",
+			durations: []time.Duration{7},
+			excepted:  &benchResult{Runs: 1, Min: 7, Median: 7, Mean: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeBench(tt.durations, tt.bytes, tt.allocs)
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %+v, got %+v", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestCLI_FormatBenchResult(t *testing.T) {
+	result := &benchResult{Runs: 3, Min: 1500, Median: 2000, Mean: 2100, BytesPerRun: 512, AllocsPerRun: 12}
+	excepted := "main.gh\t3 runs\t1500 ns/op min\t2000 ns/op median\t2100 ns/op mean\t512 B/op\t12 allocs/op"
+	if got := formatBenchResult("main.gh", result); got != excepted {
+		t.Errorf("excepted %q, got %q", excepted, got)
+	}
+}
+
+func TestCLI_RunBench(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		excepted int
+	}{
+		{
+			name:     "Fixed Count",
+			args:     []string{"-count", "3", "testdata/exit_success.gh"},
+			excepted: exitOK,
+		},
+		{
+			name:     "Requested Exit",
+			args:     []string{"-count", "2", "testdata/exit_requested.gh"},
+			excepted: 7,
+		},
+		{
+			name:     "Runtime Error",
+			args:     []string{"-count", "2", "testdata/exit_runtime_error.gh"},
+			excepted: exitFailure,
+		},
+		{
+			name:     "Syntax Error",
+			args:     []string{"testdata/syntax_error.gh"},
+			excepted: exitSyntaxError,
+		},
+		{
+			name:     "Missing Script",
+			args:     []string{"-count", "2"},
+			excepted: exitFailure,
+		},
+		{
+			name:     "Negative Count",
+			args:     []string{"-count", "-1", "testdata/exit_success.gh"},
+			excepted: exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := discardSession().RunBench(tt.args); code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+		})
+	}
+}
+
+func TestCLI_BenchProgramAutoCount(t *testing.T) {
+	absPath, code, err := readSourceFile("testdata/exit_success.gh")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	program, err := ghost.Compile(absPath, code)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	result, err := discardSession().benchProgram(program, nil, 0)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if result.Runs < 2 || result.Min > result.Median || result.Median > time.Duration(result.Runs)*result.Mean {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// replIndent REPL续行时每层括号的缩进
+const replIndent = "    "
+
+// closingBrackets 闭括号对应的开括号
+var closingBrackets = map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+// scanBrackets 扫描源代码中的括号，跳过字符串字面量和注释中的括号
+// 用于REPL在交给语法分析器之前判断输入是否需要续行，以及是否包含无法通过继续输入修复的闭括号
+//
+// 参数:
+//
+//	name - 源名称
+//	source - 源代码文本
+//
+// 返回值:
+//
+//	[]*util.Pos - 未闭合的开括号的位置，按嵌套顺序排列，长度即为嵌套深度
+//	error - 多余的闭括号或与开括号不匹配的闭括号产生的语法错误
+func scanBrackets(name, source string) ([]*util.Pos, error) {
+	var open []*util.Pos
+	var quote rune
+	lineComment, blockComment := false, false
+	for pos := util.NewPos(1, 1, 0, util.NewSourceFile(name, source)); pos.Idx < len(source); pos.Advance() {
+		ch := pos.Char
+		next := rune(0)
+		if pos.Idx+1 < len(source) {
+			next = rune(source[pos.Idx+1])
+		}
+		switch {
+		case lineComment:
+			lineComment = ch != '\n'
+		case blockComment:
+			if ch == '*' && next == '/' {
+				pos.Advance()
+				blockComment = false
+			}
+		case quote != 0:
+			// 反引号字符串不支持转义
+			if ch == '\\' && quote != '`' {
+				pos.Advance()
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '/' && next == '/':
+			lineComment = true
+		case ch == '/' && next == '*':
+			pos.Advance()
+			blockComment = true
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			open = append(open, pos.Copy())
+		default:
+			opener, ok := closingBrackets[ch]
+			if !ok {
+				continue
+			}
+			if len(open) == 0 {
+				return open, bracketError(errcode.UnmatchedDelimiter, fmt.Sprintf("unmatched '%c'.", ch), pos)
+			}
+			last := open[len(open)-1]
+			if last.Char != opener {
+				return open, bracketError(errcode.UnmatchedDelimiter, fmt.Sprintf("mismatched '%c' for '%c' opened at %d:%d.", ch, last.Char, last.Row, last.Col), pos)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	return open, nil
+}
+
+// bracketError 创建闭括号位置的语法错误
+//
+// 参数:
+//
+//	code - 错误代码
+//	message - 错误描述
+//	pos - 闭括号的位置
+//
+// 返回值:
+//
+//	error - 语法错误
+func bracketError(code, message string, pos *util.Pos) error {
+	posEnd := pos.Copy()
+	posEnd.Advance()
+	return &parser.SyntaxError{Code: code, Message: message, PosStart: pos.Copy(), PosEnd: posEnd}
+}
+
+// indentLine 按括号嵌套深度缩进REPL的续行，以闭括号开头的行减少相应的缩进
+// 输入本身的前导空白被替换为自动缩进
+//
+// 参数:
+//
+//	line - 输入的一行
+//	depth - 该行之前未闭合的括号数
+//
+// 返回值:
+//
+//	string - 缩进后的行
+func indentLine(line string, depth int) string {
+	line = strings.TrimLeft(line, " \t")
+	for _, ch := range line {
+		if _, ok := closingBrackets[ch]; !ok || depth == 0 {
+			break
+		}
+		depth--
+	}
+	return strings.Repeat(replIndent, depth) + line
+}
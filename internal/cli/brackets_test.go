@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+)
+
+func TestCLI_ScanBrackets(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted int
+		err      string
+	}{
+		{
+			name:     "Balanced",
+			input:    "func f(a) { return [a, (a + 1)]; };",
+			excepted: 0,
+		},
+		{
+			name:     "Open Block",
+			input:    "func f(a) {\n    if a {",
+			excepted: 2,
+		},
+		{
+			name:     "Braces In Strings",
+			input:    `var s = "{[(" + '}' + ` + "`)`" + `; if true {`,
+			excepted: 1,
+		},
+		{
+			name:     "Escaped Quote In String",
+			input:    `var s = "a\"}"; {`,
+			excepted: 1,
+		},
+		{
+			name:  "Backslash In Raw String",
+			input: "var s = `\\`; }",
+			err:   "unmatched '}'.",
+		},
+		{
+			name:     "Multi-Line String",
+			input:    "var s = \"{\n}\n",
+			excepted: 0,
+		},
+		{
+			name:     "Unterminated String",
+			input:    "var s = \"}",
+			excepted: 0,
+		},
+		{
+			name:     "Line Comment",
+			input:    "{ // }\n",
+			excepted: 1,
+		},
+		{
+			name:     "Block Comment",
+			input:    "/* } ) */ [ /* ] */",
+			excepted: 1,
+		},
+		{
+			name:     "Division Is Not A Comment",
+			input:    "(1 / 2",
+			excepted: 1,
+		},
+		{
+			name:  "Extra Closer",
+			input: "var x = 1; }",
+			err:   "unmatched '}'.",
+		},
+		{
+			name:  "Extra Closer After Balanced Block",
+			input: "if true { 1; }}",
+			err:   "unmatched '}'.",
+		},
+		{
+			name:  "Mismatched Closer",
+			input: "var xs = [1,\n    2);",
+			err:   "mismatched ')' for '[' opened at 1:10.",
+		},
+		{
+			name:     "Unicode Before Brackets",
+			input:    `var s = "幽灵"; (`,
+			excepted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, err := scanBrackets("<stdin>", tt.input)
+			if tt.err != "" {
+				var syntaxErr *parser.SyntaxError
+				if !errors.As(err, &syntaxErr) || syntaxErr.Message != tt.err {
+					t.Fatalf("err = %+v, expected %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if len(open) != tt.excepted {
+				t.Errorf("excepted depth %d, got %d", tt.excepted, len(open))
+			}
+		})
+	}
+}
+
+func TestCLI_IndentLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		depth    int
+		excepted string
+	}{
+		{name: "Indent", line: "var x = 1;", depth: 2, excepted: "        var x = 1;"},
+		{name: "Replace Typed Indentation", line: "\t  x;", depth: 1, excepted: "    x;"},
+		{name: "Dedent Closer", line: "};", depth: 2, excepted: "    };"},
+		{name: "Dedent Else", line: "} else {", depth: 1, excepted: "} else {"},
+		{name: "Dedent Several Closers", line: "  ]);", depth: 3, excepted: "    ]);"},
+		{name: "Closer At Top Level", line: "}", depth: 0, excepted: "}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indentLine(tt.line, tt.depth); got != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestCLI_REPLBrackets(t *testing.T) {
+	input := "func add(a, b) {\nreturn a + b;\n};\nadd(1, 2)\nvar x = 1; }\nadd(\"a\", \"b\")\n"
+	stdout, stderr, code := runWithStdin(t, input, []string{"repl"})
+	if code != exitOK {
+		t.Errorf("excepted exit code %d, got %d", exitOK, code)
+	}
+	for _, excepted := range []string{"... " + replIndent, "::: 3\n", "::: ab\n"} {
+		if !strings.Contains(stdout, excepted) {
+			t.Errorf("excepted stdout to contain %q, got %q", excepted, stdout)
+		}
+	}
+	if !strings.Contains(stderr, "Syntax Error[E2004]: unmatched '}'.") {
+		t.Errorf("excepted unmatched brace error, got %q", stderr)
+	}
+}
+
+func TestCLI_REPLUnterminatedInterpolation(t *testing.T) {
+	stdout, stderr, code := runWithStdin(t, "var x = 2;\n\"a ${x\n}\"\n", []string{"repl"})
+	if code != exitOK {
+		t.Errorf("excepted exit code %d, got %d", exitOK, code)
+	}
+	if !strings.Contains(stdout, "::: a 2\n") {
+		t.Errorf("excepted the interpolation to continue on the next line, got stdout %q, stderr %q", stdout, stderr)
+	}
+}
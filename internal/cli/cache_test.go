@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLI_ParseCache(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "main.gh")
+	cacheFile := script + ".ghostc"
+	steps := []struct {
+		name     string
+		setup    func()
+		excepted string
+	}{
+		{
+			name:     "Writes Cache",
+			setup:    func() { writeFile(t, script, "println(1 + 2);") },
+			excepted: "3\n",
+		},
+		{
+			name:     "Uses Cache",
+			setup:    func() {},
+			excepted: "3\n",
+		},
+		{
+			name:     "Stale Cache",
+			setup:    func() { writeFile(t, script, "println(\"changed\");") },
+			excepted: "changed\n",
+		},
+		{
+			name:     "Corrupt Cache",
+			setup:    func() { writeFile(t, cacheFile, "garbage") },
+			excepted: "changed\n",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.setup()
+			stdout, stderr, code := runWithStdin(t, "", []string{"--cache", script})
+			if code != exitOK {
+				t.Fatalf("excepted exit code %d, got %d (stderr %q)", exitOK, code, stderr)
+			}
+			if !strings.Contains(stdout, step.excepted) {
+				t.Errorf("excepted output to contain %q, got %q", step.excepted, stdout)
+			}
+			if _, err := os.Stat(cacheFile); err != nil {
+				t.Errorf("excepted cache file to exist: %+v", err)
+			}
+		})
+	}
+}
+
+func TestCLI_ParseCacheSyntaxError(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "main.gh")
+	writeFile(t, script, "var x = (1 + 2;")
+	_, _, code := runWithStdin(t, "", []string{"--cache", script})
+	if code != exitSyntaxError {
+		t.Errorf("excepted exit code %d, got %d", exitSyntaxError, code)
+	}
+	if _, err := os.Stat(script + ".ghostc"); !os.IsNotExist(err) {
+		t.Errorf("excepted no cache file for a script with syntax errors, got %+v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lint"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+)
+
+// CheckFiles 对指定的.gh文件逐个进行词法和语法检查并解析顶层导入的模块，不执行程序
+// 之后进行语义检查，报告未定义的变量、对常量的赋值等执行时必然发生的错误
+// 没有错误的文件再进行静态检查，报告从未使用的变量等警告，警告不影响退出码，指定 -Werror 时作为错误处理
+// 每条诊断信息输出一行，格式为：<文件>:<行>:<列>: <错误信息>，便于编辑器解析
+// 先输出所有警告，再输出最多maxErrors条错误，多于一条错误时最后输出错误总数
+// 返回各文件退出码中的最大值：文件无法读取为文件错误，词法、语法或语义错误为语法错误，找不到导入的模块为运行时错误
+//
+// 参数:
+//
+//	fileNames - 要检查的文件路径列表
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) CheckFiles(fileNames []string) int {
+	// JSON模式下逐个记录错误和警告，由Run统一输出
+	if s.diagnostics == "json" {
+		code := exitOK
+		for _, fileName := range fileNames {
+			warnings, err := s.checkFile(fileName)
+			for _, warning := range warnings {
+				s.printError(warning)
+			}
+			if err != nil {
+				s.printError(err)
+				code = max(code, exitCode(err))
+			}
+		}
+		return code
+	}
+	diagnostics, warnings, code := s.checkFiles(fileNames)
+	for _, line := range warnings {
+		fmt.Fprintln(s.stdout, line)
+	}
+	shown := len(diagnostics)
+	if s.maxErrors > 0 && shown > s.maxErrors {
+		shown = s.maxErrors
+	}
+	for _, line := range diagnostics[:shown] {
+		fmt.Fprintln(s.stdout, line)
+	}
+	for _, line := range diagnostic.Summary(len(diagnostics), shown) {
+		fmt.Fprintln(s.stdout, line)
+	}
+	// 刷新标准输出缓冲区
+	syncOutput(s.stdout)
+	return code
+}
+
+// checkFiles 依次检查多个.gh文件并汇总诊断信息
+//
+// 参数:
+//
+//	fileNames - 要检查的文件路径列表
+//
+// 返回值:
+//
+//	[]string - 所有文件的错误，按文件顺序排列，同一文件中按源代码顺序排列
+//	[]string - 所有文件的警告，按文件顺序排列，同一文件中按源代码顺序排列
+//	int - 进程退出码，取各文件退出码中的最大值
+func (s *session) checkFiles(fileNames []string) ([]string, []string, int) {
+	var diagnostics, warnings []string
+	code := exitOK
+	for _, fileName := range fileNames {
+		fileWarnings, err := s.checkFile(fileName)
+		for _, warning := range fileWarnings {
+			warnings = append(warnings, formatDiagnostic(fileName, warning))
+		}
+		if err == nil {
+			continue
+		}
+		code = max(code, exitCode(err))
+		errs, ok := err.(syntaxErrors)
+		if !ok {
+			errs = syntaxErrors{err}
+		}
+		for _, err := range errs {
+			diagnostics = append(diagnostics, formatDiagnostic(fileName, err))
+		}
+	}
+	return diagnostics, warnings, code
+}
+
+// checkFile 对单个.gh文件进行词法和语法检查，然后解析顶层import语句导入的模块并进行语义检查，没有错误时再进行静态检查
+// 语法分析器在语法错误后跳过出错的语句继续分析，多个错误合并为syntaxErrors
+//
+// 参数:
+//
+//	fileName - 要检查的文件路径
+//
+// 返回值:
+//
+//	[]*lint.Warning - 静态检查的警告，指定 -Werror 时警告作为错误返回，此时为nil
+//	error - 读取文件、词法分析、语法分析、模块解析或语义检查过程中的错误，文件无错误时为nil
+func (s *session) checkFile(fileName string) ([]*lint.Warning, error) {
+	absPath, code, err := readSourceFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	l := lexer.NewLexer(filepath.Base(absPath), code)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		return nil, err
+	}
+	p.Recover = true
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return nil, parseErrors(p)
+	}
+	// 只查找导入的模块，不执行
+	e := evaluator.NewEvaluator(&frame.Frame{FuncName: filepath.Base(absPath)})
+	e.File = absPath
+	if err := e.ResolveImports(program); err != nil {
+		return nil, err
+	}
+	if _, errs := sema.Check(program, semaOptions(absPath, nil)); errs != nil {
+		return nil, semaErrors(errs)
+	}
+	warnings := lint.Check(program)
+	if s.werror {
+		return nil, warningErrors(warnings)
+	}
+	return warnings, nil
+}
+
+// formatDiagnostic 将词法、语法、导入或语义错误以及静态检查的警告格式化为单行诊断信息
+//
+// 参数:
+//
+//	fileName - 出错的文件路径
+//	err - 词法、语法、导入或语义错误，或静态检查的警告
+//
+// 返回值:
+//
+//	string - 格式为<文件>:<行>:<列>: <错误信息>的诊断信息
+func formatDiagnostic(fileName string, err error) string {
+	switch e := err.(type) {
+	case *lexer.IllegalTokenError:
+		return fmt.Sprintf("%s:%d:%d: Illegal Token Error[%s]: %s", fileName, e.PosStart.Row, e.PosStart.Col, e.Code, e.Message)
+	case *parser.SyntaxError:
+		return fmt.Sprintf("%s:%d:%d: Syntax Error[%s]: %s", fileName, e.PosStart.Row, e.PosStart.Col, e.Code, e.Message)
+	case *evaluator.ImportError:
+		return fmt.Sprintf("%s:%d:%d: Import Error[%s]: %s", fileName, e.PosStart.Row, e.PosStart.Col, e.Code, e.Message)
+	case *sema.Error:
+		return fmt.Sprintf("%s:%d:%d: %s[%s]: %s", fileName, e.PosStart.Row, e.PosStart.Col, e.Kind, e.Code, e.Message)
+	case *lint.Warning:
+		return fmt.Sprintf("%s:%d:%d: Warning[%s]: %s", fileName, e.PosStart.Row, e.PosStart.Col, e.Code, e.Message)
+	default:
+		return fileName + ": " + err.Error()
+	}
+}
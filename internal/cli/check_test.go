@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCLI_CheckFiles(t *testing.T) {
+	t.Setenv("GHOSTPATH", "")
+	modules, err := filepath.Abs(filepath.Join("testdata", "ghost_modules"))
+	if err != nil {
+		t.Fatalf("failed to resolve path: %+v", err)
+	}
+	tests := []struct {
+		name      string
+		fileNames []string
+		excepted  []string
+		warnings  []string
+		code      int
+	}{
+		{
+			name:      "Clean File",
+			fileNames: []string{"testdata/check_clean.gh"},
+			excepted:  nil,
+			code:      exitOK,
+		},
+		{
+			name:      "File With Two Errors",
+			fileNames: []string{"testdata/check_two_errors.gh"},
+			excepted: []string{
+				`testdata/check_two_errors.gh:1:9: Syntax Error[E2003]: unclosed "(" opened at line 1, column 9.`,
+				`testdata/check_two_errors.gh:2:9: Illegal Token Error[E1001]: illegal token "@".`,
+			},
+			code: exitSyntaxError,
+		},
+		{
+			name:      "Resolved Imports",
+			fileNames: []string{"testdata/check_imports.gh"},
+			excepted:  nil,
+			code:      exitOK,
+		},
+		{
+			name:      "Missing Import",
+			fileNames: []string{"testdata/check_missing_import.gh"},
+			excepted: []string{
+				fmt.Sprintf(`testdata/check_missing_import.gh:2:1: Import Error[E3010]: cannot find module "missing_module" (tried "%s", "<stdlib>/missing_module.gh").`,
+					filepath.Join(modules, "missing_module.gh")),
+			},
+			code: exitFailure,
+		},
+		{
+			name:      "Unused Variable",
+			fileNames: []string{"testdata/check_unused.gh"},
+			excepted:  nil,
+			warnings: []string{
+				`testdata/check_unused.gh:3:9: Warning[W1001]: variable "unused" is declared but never used.`,
+			},
+			code: exitOK,
+		},
+		{
+			name:      "Semantic Errors",
+			fileNames: []string{"testdata/check_semantic.gh"},
+			excepted: []string{
+				`testdata/check_semantic.gh:7:1: Variable Error[E3005]: cannot redefine constant "limit".`,
+				`testdata/check_semantic.gh:8:9: Variable Error[E3005]: undefined variable "scal". did you mean "scale"?`,
+			},
+			code: exitSyntaxError,
+		},
+		{
+			name: "Mixed Invocation",
+			fileNames: []string{
+				"testdata/check_clean.gh",
+				"testdata/tokens_error.gh",
+				"testdata/missing.gh",
+				"testdata/ast.gh",
+			},
+			excepted: []string{
+				`testdata/tokens_error.gh:2:9: Illegal Token Error[E1001]: illegal token "@".`,
+				`testdata/missing.gh: ghost-lang: file not found: "testdata/missing.gh".`,
+			},
+			code: exitFileError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, code := discardSession().checkFiles(tt.fileNames)
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("excepted warnings %q, got %q", tt.warnings, warnings)
+			}
+			if code != tt.code {
+				t.Errorf("excepted exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestCLI_MultipleSyntaxErrors(t *testing.T) {
+	// 25条出错的语句与正确的语句交错
+	var sb strings.Builder
+	for i := 1; i <= 25; i++ {
+		fmt.Fprintf(&sb, "println(%d);\nvar x%d = ;\n", i, i)
+	}
+	script := filepath.Join(t.TempDir(), "broken.gh")
+	writeFile(t, script, sb.String())
+	errorAt := func(i int) string {
+		return fmt.Sprintf(`broken.gh:%d:%d: Syntax Error[E2002]: unexpected "SEMICOLON".`, 2*i, len(fmt.Sprintf("var x%d = ", i))+1)
+	}
+	tests := []struct {
+		name      string
+		arguments []string
+		shown     int
+		summary   []string
+	}{
+		{
+			name:      "Check Default Cap",
+			arguments: []string{"--check", script},
+			shown:     20,
+			summary:   []string{"and 5 more errors", "25 errors found"},
+		},
+		{
+			name:      "Check Unlimited",
+			arguments: []string{"--max-errors", "0", "--check", script},
+			shown:     25,
+			summary:   []string{"25 errors found"},
+		},
+		{
+			name:      "Run Capped",
+			arguments: []string{"--max-errors", "3", script},
+			shown:     3,
+			summary:   []string{"and 22 more errors", "25 errors found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runWithStdin(t, "", tt.arguments)
+			if code != exitSyntaxError {
+				t.Errorf("excepted exit code %d, got %d", exitSyntaxError, code)
+			}
+			output := stdout + stderr
+			// 出错的文件不会被执行
+			if strings.HasPrefix(stdout, "1\n") {
+				t.Errorf("excepted script not to run, got %q", stdout)
+			}
+			last := -1
+			for i := 1; i <= 25; i++ {
+				idx := strings.Index(output, errorAt(i)+"\n")
+				if i > tt.shown {
+					if idx >= 0 {
+						t.Errorf("excepted error %d to be omitted", i)
+					}
+					continue
+				}
+				if idx <= last {
+					t.Fatalf("excepted error %d after error %d in %q", i, i-1, output)
+				}
+				last = idx
+			}
+			if !strings.HasSuffix(output, strings.Join(tt.summary, "\n")+"\n") {
+				t.Errorf("excepted output to end with %q, got %q", tt.summary, output)
+			}
+		})
+	}
+}
+
+func TestCLI_Warnings(t *testing.T) {
+	script := "testdata/check_unused.gh"
+	warning := `check_unused.gh:3:9: Warning[W1001]: variable "unused" is declared but never used.`
+	tests := []struct {
+		name      string
+		arguments []string
+		stdout    string
+		stderr    string
+		code      int
+	}{
+		{
+			name:      "Disabled By Default",
+			arguments: []string{"run", script},
+			stdout:    "6\n",
+			code:      exitOK,
+		},
+		{
+			name:      "Warnings Do Not Stop The Script",
+			arguments: []string{"-W", "run", script},
+			stdout:    "6\n",
+			stderr:    warning,
+			code:      exitOK,
+		},
+		{
+			name:      "Warnings As Errors",
+			arguments: []string{"-Werror", "run", script},
+			stderr:    warning,
+			code:      exitSyntaxError,
+		},
+		{
+			name:      "Check Warnings As Errors",
+			arguments: []string{"-Werror", "--check", script},
+			stdout:    "testdata/" + warning,
+			code:      exitSyntaxError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runWithStdin(t, "", tt.arguments)
+			if code != tt.code {
+				t.Errorf("excepted exit code %d, got %d (stderr %q)", tt.code, code, stderr)
+			}
+			if !strings.Contains(stdout, tt.stdout) {
+				t.Errorf("excepted stdout to contain %q, got %q", tt.stdout, stdout)
+			}
+			if tt.stdout == "" && strings.Contains(stdout, "6\n") {
+				t.Errorf("excepted script not to run, got %q", stdout)
+			}
+			if tt.stderr == "" && strings.Contains(stderr, "Warning") || !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("excepted stderr to contain %q, got %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestCLI_Precheck(t *testing.T) {
+	script := "testdata/check_semantic.gh"
+	tests := []struct {
+		name      string
+		arguments []string
+		stdout    string
+		stderr    string
+		code      int
+	}{
+		{
+			name:      "Disabled By Default",
+			arguments: []string{"run", script},
+			stdout:    "before\n20\n",
+			stderr:    `check_semantic.gh:7:1: Variable Error[E3005]: cannot redefine constant "limit".`,
+			code:      exitFailure,
+		},
+		{
+			name:      "Errors Before Side Effects",
+			arguments: []string{"--precheck", "run", script},
+			stderr:    `check_semantic.gh:8:9: Variable Error[E3005]: undefined variable "scal". did you mean "scale"?`,
+			code:      exitSyntaxError,
+		},
+		{
+			name:      "Names From Earlier Files",
+			arguments: []string{"--precheck", "run", "testdata/check_clean.gh", "testdata/check_globals.gh"},
+			stdout:    "3\n4\n",
+			code:      exitOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runWithStdin(t, "", tt.arguments)
+			if code != tt.code {
+				t.Errorf("excepted exit code %d, got %d (stderr %q)", tt.code, code, stderr)
+			}
+			if !strings.Contains(stdout, tt.stdout) {
+				t.Errorf("excepted stdout to contain %q, got %q", tt.stdout, stdout)
+			}
+			if tt.stdout == "" && strings.Contains(stdout, "before") {
+				t.Errorf("excepted script not to run, got %q", stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("excepted stderr to contain %q, got %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
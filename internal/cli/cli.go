@@ -2,49 +2,193 @@ package cli
 
 import (
 	"flag"
-	"os"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lint"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+)
+
+// options 命令行解析结果
+
+type options struct {
+	repl        bool          // 启动REPL
+	version     bool          // 输出版本
+	help        bool          // 输出帮助
+	ast         bool          // 输出AST
+	tokens      bool          // 输出令牌流
+	check       bool          // 检查文件
+	watch       bool          // 监视脚本文件，修改后重新执行
+	strict      bool          // 严格数值模式，禁止整数和浮点数混合运算
+	sandbox     bool          // 沙箱模式，禁用访问宿主系统的内置函数和文件模块导入
+	maxSteps    int64         // 最多执行的节点数，0表示不限制
+	maxDepth    int           // 函数调用的最大嵌套深度，0表示不限制
+	maxAlloc    int64         // 单个字符串或列表的最大字节数，0表示不限制
+	timeout     time.Duration // 最长执行时间，0表示不限制
+	maxErrors   int           // 最多输出的语法错误数，0表示不限制
+	warnings    bool          // 执行脚本之前进行静态检查并输出警告
+	werror      bool          // 将静态检查的警告作为错误处理
+	precheck    bool          // 执行脚本之前进行语义检查，有错误时不执行
+	diagnostics string        // 错误的输出格式，"text"或"json"
+	cpuProfile  string        // 解释器自身的CPU分析输出文件，为空时不分析
+	memProfile  string        // 解释器自身的内存分配分析输出文件，为空时不分析
+	cache       bool          // 将脚本的语法树缓存到<script>.ghostc文件中
+	debug       bool          // 解释器内部错误时输出Go调用栈
+	args        []string      // 全局标志之后的剩余参数
+	separated   bool          // 剩余参数是否由"--"分隔，分隔后第一个参数总是被视为脚本
+}
+
+// 进程退出码
+
+const (
+	exitOK          = 0 // 执行成功
+	exitFailure     = 1 // 运行时错误或命令行参数非法
+	exitSyntaxError = 2 // 词法、语法或语义检查发现的错误
+	exitUsage       = 2 // 命令行标志未定义或取值非法，与flag包的约定一致
+	exitFileError   = 3 // 脚本文件不存在或无法读取
 )
 
-func Run() {
-	// 定义命令行标志
-	replMode := flag.Bool("r", false, "REPL")
-	versionMode := flag.Bool("v", false, "Version")
-	helpMode := flag.Bool("h", false, "Help")
-
-	// 禁用自动退出
-	flag.CommandLine.Init(flag.CommandLine.Name(), flag.ContinueOnError)
-	originalStderr := os.Stderr
-	nullFile, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0666)
-	os.Stderr = nullFile
+// Run 解析命令行参数并分发到相应模式
+// 命令的所有输入输出都通过传入的流进行，设置保存在每次调用各自的session中，可以在同一进程中同时调用
+//
+// 参数:
+//
+//	arguments - 不包含程序名的命令行参数
+//	in - 标准输入，从中读取REPL、调试器和"-"脚本的输入
+//	out - 标准输出，脚本、REPL和各命令的输出
+//	errOut - 标准错误，错误信息和帮助信息的输出
+//
+// 返回值:
+//
+//	int - 进程退出码
+func Run(arguments []string, in io.Reader, out, errOut io.Writer) (code int) {
+	s := newSession(in, out, errOut)
+	opts, err := parseOptions(arguments)
+	if err != nil {
+		return s.usageError(fmt.Sprintf("ghost-lang: %s.", err))
+	}
+	if opts.diagnostics != "text" && opts.diagnostics != "json" {
+		return s.usageError(fmt.Sprintf("ghost-lang: invalid value \"%s\" for flag -diagnostics.", opts.diagnostics))
+	}
+	limitFlags := []struct {
+		name  string
+		value int64
+	}{
+		{"max-steps", opts.maxSteps},
+		{"max-depth", int64(opts.maxDepth)},
+		{"max-alloc", opts.maxAlloc},
+		{"max-errors", int64(opts.maxErrors)},
+	}
+	for _, limit := range limitFlags {
+		if limit.value < 0 {
+			return s.usageError(fmt.Sprintf("ghost-lang: invalid value \"%d\" for flag -%s.", limit.value, limit.name))
+		}
+	}
+	s.diagnostics = opts.diagnostics
+	s.strict = opts.strict
+	s.sandbox = opts.sandbox
+	s.cache = opts.cache
+	s.maxErrors = opts.maxErrors
+	s.warnings = opts.warnings || opts.werror
+	s.werror = opts.werror
+	s.precheck = opts.precheck
+	s.goStack = opts.debug
+	if opts.timeout < 0 {
+		return s.usageError(fmt.Sprintf("ghost-lang: invalid value \"%s\" for flag -timeout.", opts.timeout))
+	}
+	s.limits = evaluator.Limits{MaxSteps: opts.maxSteps, MaxDepth: opts.maxDepth, MaxAlloc: opts.maxAlloc, Timeout: opts.timeout}
 	defer func() {
-		os.Stderr = originalStderr
-		_ = nullFile.Close()
+		if s.diagnostics == "json" {
+			s.flushDiagnostics(s.stdout)
+		}
 	}()
+	stopProfiling, err := startProfiling(opts.cpuProfile, opts.memProfile)
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	// 命令出错或脚本调用exit()时同样停止分析并写入文件
+	defer func() {
+		if err := stopProfiling(); err != nil {
+			s.printError(err)
+			if code == exitOK {
+				code = exitCode(err)
+			}
+		}
+	}()
+	return s.dispatch(opts)
+}
 
-	// 执行解析
-	flag.Parse()
-
+// dispatch 根据命令行解析结果分发到相应模式
+//
+// 参数:
+//
+//	opts - 命令行解析结果
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) dispatch(opts *options) int {
 	// 解析全局flag
-	if *replMode {
-		StartREPL()
-		return
+	if opts.repl {
+		return s.StartREPL()
 	}
-	if *versionMode {
-		PrintVersion()
-		return
+	if opts.version {
+		s.PrintVersion()
+		return exitOK
 	}
-	if *helpMode {
-		PrintHelp()
-		return
+	if opts.help {
+		s.PrintHelp()
+		return exitOK
 	}
 
 	// 剩余未解析的参数
-	args := flag.Args()
-	// 参数验证：未指定任何模式且无输入文件时显示错误
+	args := opts.args
+	// 输出AST
+	if opts.ast {
+		if len(args) != 1 {
+			return s.invalidArguments()
+		}
+		return s.DumpAST(args[0])
+	}
+	// 输出令牌流
+	if opts.tokens {
+		if len(args) != 1 {
+			return s.invalidArguments()
+		}
+		return s.DumpTokens(args[0])
+	}
+	// 检查文件语法
+	if opts.check {
+		if len(args) == 0 {
+			return s.invalidArguments()
+		}
+		return s.CheckFiles(args)
+	}
+	// 监视模式，只支持文本格式的错误输出
+	if opts.watch {
+		scripts, scriptArgs, ok := scriptInvocation(opts)
+		if !ok || len(scripts) != 1 || scripts[0] == stdinFileName || opts.diagnostics == "json" {
+			return s.invalidArguments()
+		}
+		return s.WatchFile(scripts[0], scriptArgs)
+	}
+	// 参数验证：未指定任何模式且无输入文件时，标准输入为管道或文件则执行其内容，否则显示错误
 	if len(args) == 0 {
-		printError("ghost-lang: invalid command line arguments.")
-		PrintHelp()
-		return
+		if !s.stdinIsTerminal() {
+			return s.RunStdin(nil)
+		}
+		return s.invalidArguments()
+	}
+
+	// 直接运行脚本
+	if scripts, scriptArgs, ok := scriptInvocation(opts); ok {
+		return s.runScript(scripts, scriptArgs)
 	}
 
 	// 分发子命令
@@ -52,16 +196,149 @@ func Run() {
 	switch command {
 	case "repl":
 		// 启动REPL
-		StartREPL()
-		return
+		return s.StartREPL()
 	case "run":
 		// 运行文件
-		RunFile(args[1])
-		return
+		return s.runCommand(args[1:])
+	case "fmt":
+		// 格式化文件
+		return s.FormatFiles(args[1:])
+	case "bench":
+		// 基准测试
+		return s.RunBench(args[1:])
+	case "test":
+		// 运行测试
+		return s.RunTests(args[1:])
+	case "debug":
+		// 调试文件
+		return s.RunDebug(args[1:])
+	case "lsp":
+		// 启动语言服务器
+		return s.StartLSP()
 	default:
 		// 显示错误
-		printError("ghost-lang: unknown command.")
-		PrintHelp()
-		return
+		s.printError("ghost-lang: unknown command.")
+		printUsage(s.stderr)
+		return exitFailure
+	}
+}
+
+// invalidArguments 输出命令行参数非法的错误，并将帮助信息输出到标准错误
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) invalidArguments() int {
+	s.printError("ghost-lang: invalid command line arguments.")
+	printUsage(s.stderr)
+	return exitFailure
+}
+
+// usageError 输出命令行标志非法的错误，并将帮助信息输出到标准错误
+//
+// 参数:
+//
+//	message - 错误信息
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) usageError(message string) int {
+	s.printError(message)
+	printUsage(s.stderr)
+	return exitUsage
+}
+
+// exitCode 根据错误类型确定进程退出码
+//
+// 参数:
+//
+//	err - 执行过程中产生的错误
+//
+// 返回值:
+//
+//	int - 进程退出码
+func exitCode(err error) int {
+	switch err.(type) {
+	case nil:
+		return exitOK
+	case *fileError:
+		return exitFileError
+	case *lexer.IllegalTokenError, *parser.SyntaxError, *sema.Error, *lint.Warning, syntaxErrors:
+		return exitSyntaxError
+	default:
+		return exitFailure
+	}
+}
+
+// parseOptions 解析全局标志，遇到第一个非标志参数或"--"时停止
+// 之后的参数原样保留，不会被ghost解释为标志
+//
+// 参数:
+//
+//	arguments - 不包含程序名的命令行参数
+//
+// 返回值:
+//
+//	*options - 解析结果
+//	error - 标志非法时的错误
+func parseOptions(arguments []string) (*options, error) {
+	opts := &options{}
+	flags := flag.NewFlagSet("ghost", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.BoolVar(&opts.repl, "r", false, "REPL")
+	flags.BoolVar(&opts.version, "v", false, "Version")
+	flags.BoolVar(&opts.version, "version", false, "Version")
+	flags.BoolVar(&opts.help, "h", false, "Help")
+	flags.BoolVar(&opts.help, "help", false, "Help")
+	flags.BoolVar(&opts.ast, "ast", false, "AST")
+	flags.BoolVar(&opts.tokens, "tokens", false, "Tokens")
+	flags.BoolVar(&opts.check, "check", false, "Check")
+	flags.BoolVar(&opts.watch, "watch", false, "Watch")
+	flags.BoolVar(&opts.strict, "strict", false, "Strict")
+	flags.BoolVar(&opts.sandbox, "sandbox", false, "Sandbox")
+	flags.Int64Var(&opts.maxSteps, "max-steps", 0, "Max steps")
+	flags.IntVar(&opts.maxDepth, "max-depth", 0, "Max call depth")
+	flags.Int64Var(&opts.maxAlloc, "max-alloc", 0, "Max allocation")
+	flags.DurationVar(&opts.timeout, "timeout", 0, "Timeout")
+	flags.IntVar(&opts.maxErrors, "max-errors", defaultMaxErrors, "Max syntax errors")
+	flags.BoolVar(&opts.warnings, "W", false, "Warnings")
+	flags.BoolVar(&opts.werror, "Werror", false, "Warnings as errors")
+	flags.BoolVar(&opts.precheck, "precheck", false, "Semantic check before running")
+	flags.StringVar(&opts.diagnostics, "diagnostics", "text", "Diagnostics format")
+	flags.StringVar(&opts.cpuProfile, "cpuprofile", "", "CPU profile")
+	flags.StringVar(&opts.memProfile, "memprofile", "", "Memory profile")
+	flags.BoolVar(&opts.cache, "cache", false, "Parse cache")
+	flags.BoolVar(&opts.debug, "debug", false, "Go stack of internal errors")
+	if err := flags.Parse(arguments); err != nil {
+		return nil, err
+	}
+	opts.args = flags.Args()
+	// 被解析的最后一个参数为"--"时，说明剩余参数由其分隔
+	consumed := len(arguments) - len(opts.args)
+	opts.separated = consumed > 0 && arguments[consumed-1] == "--"
+	return opts, nil
+}
+
+// scriptInvocation 判断剩余参数是否表示直接运行脚本，即 ghost <script.gh>... [args...]
+// 连续的多个.gh文件在同一个全局环境中依次执行，之后的参数全部传递给脚本，脚本为"-"时表示从标准输入读取
+//
+// 参数:
+//
+//	opts - 命令行解析结果
+//
+// 返回值:
+//
+//	[]string - 脚本路径
+//	[]string - 传递给脚本的参数
+//	bool - 是否为直接运行脚本
+func scriptInvocation(opts *options) ([]string, []string, bool) {
+	if len(opts.args) == 0 {
+		return nil, nil, false
+	}
+	if !opts.separated && opts.args[0] != stdinFileName && !strings.HasSuffix(opts.args[0], ".gh") {
+		return nil, nil, false
 	}
+	scripts, scriptArgs := splitScripts(opts.args)
+	return scripts, scriptArgs, true
 }
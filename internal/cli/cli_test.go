@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+func TestCLI_ScriptInvocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		arguments  []string
+		scripts    []string
+		scriptArgs []string
+		ok         bool
+	}{
+		{
+			name:       "Script With Arguments",
+			arguments:  []string{"process.gh", "input.csv", "--fast"},
+			scripts:    []string{"process.gh"},
+			scriptArgs: []string{"input.csv", "--fast"},
+			ok:         true,
+		},
+		{
+			name:       "Script Without Arguments",
+			arguments:  []string{"main.gh"},
+			scripts:    []string{"main.gh"},
+			scriptArgs: []string{},
+			ok:         true,
+		},
+		{
+			name:       "Ghost Flags Are Not Interpreted After Script",
+			arguments:  []string{"main.gh", "-v", "-h", "--", "x"},
+			scripts:    []string{"main.gh"},
+			scriptArgs: []string{"-v", "-h", "--", "x"},
+			ok:         true,
+		},
+		{
+			name:       "Separator Before Dash Script",
+			arguments:  []string{"--", "-script.gh", "-r"},
+			scripts:    []string{"-script.gh"},
+			scriptArgs: []string{"-r"},
+			ok:         true,
+		},
+		{
+			name:       "Separator Before Script Without Extension",
+			arguments:  []string{"--", "repl", "a"},
+			scripts:    []string{"repl"},
+			scriptArgs: []string{"a"},
+			ok:         true,
+		},
+		{
+			name:       "Dash Reads Stdin",
+			arguments:  []string{"-", "a"},
+			scripts:    []string{"-"},
+			scriptArgs: []string{"a"},
+			ok:         true,
+		},
+		{
+			name:       "Multiple Scripts",
+			arguments:  []string{"a.gh", "b.gh", "c.gh", "x", "y.gh"},
+			scripts:    []string{"a.gh", "b.gh", "c.gh"},
+			scriptArgs: []string{"x", "y.gh"},
+			ok:         true,
+		},
+		{
+			name:       "Separator After Scripts",
+			arguments:  []string{"a.gh", "b.gh", "--", "c.gh"},
+			scripts:    []string{"a.gh", "b.gh"},
+			scriptArgs: []string{"c.gh"},
+			ok:         true,
+		},
+		{
+			name:       "Dash Does Not Take More Scripts",
+			arguments:  []string{"-", "a.gh"},
+			scripts:    []string{"-"},
+			scriptArgs: []string{"a.gh"},
+			ok:         true,
+		},
+		{
+			name:      "Command",
+			arguments: []string{"run", "main.gh", "a"},
+			ok:        false,
+		},
+		{
+			name:      "No Arguments",
+			arguments: []string{},
+			ok:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptions(tt.arguments)
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			scripts, scriptArgs, ok := scriptInvocation(opts)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, expected %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(scripts, tt.scripts) {
+				t.Errorf("scripts = %q, expected %q", scripts, tt.scripts)
+			}
+			if !reflect.DeepEqual(scriptArgs, tt.scriptArgs) {
+				t.Errorf("scriptArgs = %q, expected %q", scriptArgs, tt.scriptArgs)
+			}
+		})
+	}
+}
+
+func TestCLI_ParseOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []string
+		excepted  *options
+		err       bool
+	}{
+		{
+			name:      "Flag Before Script",
+			arguments: []string{"--tokens", "main.gh"},
+			excepted:  &options{tokens: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"main.gh"}},
+		},
+		{
+			name:      "Multiple Flags",
+			arguments: []string{"-check", "a.gh", "b.gh"},
+			excepted:  &options{check: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"a.gh", "b.gh"}},
+		},
+		{
+			name:      "Version",
+			arguments: []string{"-v"},
+			excepted:  &options{version: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{}},
+		},
+		{
+			name:      "Long Help And Version",
+			arguments: []string{"--help", "--version"},
+			excepted:  &options{help: true, version: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{}},
+		},
+		{
+			name:      "Separator",
+			arguments: []string{"-ast", "--", "-main.gh"},
+			excepted:  &options{ast: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"-main.gh"}, separated: true},
+		},
+		{
+			name:      "JSON Diagnostics",
+			arguments: []string{"--diagnostics=json", "main.gh"},
+			excepted:  &options{diagnostics: "json", maxErrors: defaultMaxErrors, args: []string{"main.gh"}},
+		},
+		{
+			name:      "Watch",
+			arguments: []string{"--watch", "main.gh", "a"},
+			excepted:  &options{watch: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"main.gh", "a"}},
+		},
+		{
+			name:      "Strict",
+			arguments: []string{"--strict", "main.gh"},
+			excepted:  &options{strict: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"main.gh"}},
+		},
+		{
+			name:      "Sandbox",
+			arguments: []string{"--sandbox", "main.gh"},
+			excepted:  &options{sandbox: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"main.gh"}},
+		},
+		{
+			name:      "Profiles",
+			arguments: []string{"--cpuprofile", "cpu.out", "--memprofile=mem.out", "main.gh"},
+			excepted:  &options{cpuProfile: "cpu.out", memProfile: "mem.out", diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"main.gh"}},
+		},
+		{
+			name:      "Cache",
+			arguments: []string{"--cache", "main.gh"},
+			excepted:  &options{cache: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"main.gh"}},
+		},
+		{
+			name:      "Debug",
+			arguments: []string{"--debug", "main.gh"},
+			excepted:  &options{debug: true, diagnostics: "text", maxErrors: defaultMaxErrors, args: []string{"main.gh"}},
+		},
+		{
+			name:      "Max Errors",
+			arguments: []string{"--max-errors", "5", "main.gh"},
+			excepted:  &options{maxErrors: 5, diagnostics: "text", args: []string{"main.gh"}},
+		},
+		{
+			name:      "Unknown Flag",
+			arguments: []string{"--unknown", "main.gh"},
+			err:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptions(tt.arguments)
+			if tt.err {
+				if err == nil {
+					t.Errorf("err = nil, expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if !reflect.DeepEqual(opts, tt.excepted) {
+				t.Errorf("excepted %+v, got %+v", tt.excepted, opts)
+			}
+		})
+	}
+}
+
+func TestCLI_Usage(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []string
+		stdout    string
+		stderr    string
+		excepted  int
+	}{
+		{
+			name:      "Help",
+			arguments: []string{"--help"},
+			stdout:    "  -h, --help             Show help",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Version",
+			arguments: []string{"--version"},
+			stdout:    "ghost-lang: ghost " + Version + " (",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Unknown Flag",
+			arguments: []string{"--unknown", "main.gh"},
+			stderr:    "ghost-lang: flag provided but not defined: -unknown.",
+			excepted:  exitUsage,
+		},
+		{
+			name:      "Missing Flag Value",
+			arguments: []string{"--diagnostics"},
+			stderr:    "ghost-lang: flag needs an argument: -diagnostics.",
+			excepted:  exitUsage,
+		},
+		{
+			name:      "Invalid Flag Value",
+			arguments: []string{"--diagnostics=xml", "main.gh"},
+			stderr:    `ghost-lang: invalid value "xml" for flag -diagnostics.`,
+			excepted:  exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runWithStdin(t, "", tt.arguments)
+			if code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+			if !strings.Contains(stdout, tt.stdout) {
+				t.Errorf("excepted stdout to contain %q, got %q", tt.stdout, stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("excepted stderr to contain %q, got %q", tt.stderr, stderr)
+			}
+			// 参数非法时帮助信息输出到标准错误，不混入标准输出
+			if tt.excepted == exitUsage && (stdout != "" || !strings.Contains(stderr, "Usage: ghost")) {
+				t.Errorf("excepted usage on stderr only, got stdout %q, stderr %q", stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestCLI_ArgsBuiltin(t *testing.T) {
+	env := object.NewGlobalEnvironment([]string{"input.csv", "--fast"})
+	l := lexer.NewLexer("<test>", "args();")
+	p, _ := parser.NewParser(l)
+	program := p.ParseProgram()
+	e := evaluator.NewEvaluator(&frame.Frame{FuncName: "<test>"})
+	val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+	if e.Err != nil {
+		t.Fatalf("err = %+v, expected nil", e.Err)
+	}
+	excepted := &object.List{Elements: []object.Object{
+		&object.String{Value: "input.csv"},
+		&object.String{Value: "--fast"},
+	}}
+	if !reflect.DeepEqual(val, excepted) {
+		t.Errorf("excepted %+v, got %+v", excepted, val)
+	}
+}
+
+func TestCLI_PrintInternalError(t *testing.T) {
+	err := &evaluator.InternalError{
+		Frame:   &frame.Frame{FuncName: "<test>"},
+		Message: "interpreter panic: boom. this is a bug in ghost-lang.",
+		Stack:   "goroutine 1 [running]:\n",
+	}
+	for _, debug := range []bool{false, true} {
+		var out bytes.Buffer
+		s := newSession(strings.NewReader(""), io.Discard, &out)
+		s.goStack = debug
+		s.printError(err)
+		if !strings.Contains(out.String(), "Internal Error: interpreter panic: boom.") {
+			t.Errorf("excepted the internal error, got %q", out.String())
+		}
+		if hasStack := strings.Contains(out.String(), "Go stack:\ngoroutine 1 [running]:"); hasStack != debug {
+			t.Errorf("excepted Go stack printed to be %v with --debug %v, got %q", debug, debug, out.String())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/coverage"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+)
+
+// runWithCoverage 执行脚本并记录行覆盖率，结束后将带注释的源代码清单写入文件并输出各文件的覆盖率
+// 脚本出错时同样写入已记录的覆盖率
+//
+// 参数:
+//
+//	script - 脚本路径，为"-"时从标准输入读取源代码
+//	scriptArgs - 传递给脚本的命令行参数
+//	coverFile - 覆盖率清单的输出文件
+//
+// 返回值:
+//
+//	int - 进程退出码，与直接运行脚本相同，清单无法写入时为文件错误的退出码
+func (s *session) runWithCoverage(script string, scriptArgs []string, coverFile string) int {
+	var absPath, code string
+	var err error
+	if script == stdinFileName {
+		absPath, code, err = s.readStdin()
+	} else {
+		absPath, code, err = readSourceFile(script)
+	}
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	program, err := loadProgram(absPath, code)
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	env := object.NewGlobalEnvironment(scriptArgs)
+	e := s.newScriptEvaluator(absPath, env)
+	profile := coverage.NewProfile()
+	e.Hooks = profile.Hooks(e)
+	e.Eval(program, env)
+
+	status := exitOK
+	if e.Err != nil {
+		if code, ok := requestedExit(e.Err); ok {
+			status = code
+		} else {
+			s.printError(e.Err)
+			status = exitCode(e.Err)
+		}
+	}
+	if err := s.reportCoverage(profile, coverFile); err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	return status
+}
+
+// reportCoverage 输出各文件的覆盖率，指定了文件时同时写入带注释的源代码清单
+//
+// 参数:
+//
+//	profile - 覆盖率数据
+//	coverFile - 覆盖率清单的输出文件，为空时不写入
+//
+// 返回值:
+//
+//	error - 清单无法写入时的文件错误
+func (s *session) reportCoverage(profile *coverage.Profile, coverFile string) error {
+	for _, file := range profile.Files() {
+		s.printInfo(fmt.Sprintf("Coverage: %s.", file.Summary()))
+	}
+	if coverFile == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := profile.WriteListing(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(coverFile, buf.Bytes(), 0o644); err != nil {
+		return &fileError{File: coverFile, Message: fmt.Sprintf("ghost-lang: failed to write coverage file: \"%s\".", coverFile)}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLI_Coverage(t *testing.T) {
+	coverFile := filepath.Join(t.TempDir(), "cover.txt")
+	input := "var x = 1;\nif x > 1 {\n    println(\"big\");\n};\nprintln(x);"
+	stdout, _, code := runWithStdin(t, input, []string{"run", "--cover", coverFile, "-"})
+	if code != exitOK {
+		t.Fatalf("excepted exit code %d, got %d", exitOK, code)
+	}
+	if excepted := "Coverage: <stdin>: 75.0% of lines covered (3/4)."; !strings.Contains(stdout, excepted) {
+		t.Errorf("excepted stdout to contain %q, got %q", excepted, stdout)
+	}
+	data, err := os.ReadFile(coverFile)
+	if err != nil {
+		t.Fatalf("failed to read coverage file: %+v", err)
+	}
+	if excepted := "-    3 |     println(\"big\");\n"; !strings.Contains(string(data), excepted) {
+		t.Errorf("excepted listing to contain %q, got %q", excepted, data)
+	}
+
+	stdout, _, _ = runWithStdin(t, "", []string{"test", "-cover", filepath.Join("testdata", "tests")})
+	for _, excepted := range []string{
+		"Coverage: " + filepath.Join("testdata", "tests", "math.gh") + ": 100.0% of lines covered (4/4).",
+		"Coverage: " + filepath.Join("testdata", "tests", "math_test.gh") + ":",
+	} {
+		if !strings.Contains(stdout, excepted) {
+			t.Errorf("excepted stdout to contain %q, got %q", excepted, stdout)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// debugPrompt 调试器等待命令时显示的提示符
+const debugPrompt = "(debug) "
+
+// debugSourceName print命令中表达式的源名称
+const debugSourceName = "<debug>"
+
+// errDebugQuit 在调试器中输入quit时用于中止脚本执行的错误
+var errDebugQuit = errors.New("ghost-lang: debugger quit.")
+
+// debugHelpLines help命令输出的各行
+var debugHelpLines = []string{
+	"b, break <file>:<line>  Set a breakpoint (file defaults to the script)",
+	"s, step                 Run to the next statement, entering function calls",
+	"n, next                 Run to the next statement in the current or an outer function",
+	"c, continue             Run to the next breakpoint",
+	"p, print <expr>         Evaluate an expression in the paused scope",
+	"bt                      Print the call stack",
+	"locals                  Print the variables of the paused scope",
+	"q, quit                 Stop the script and exit",
+}
+
+// debugMode 脚本继续执行后的暂停条件
+
+type debugMode int
+
+const (
+	debugContinue debugMode = iota // 只在断点处暂停
+	debugStep                      // 在下一条语句处暂停
+	debugNext                      // 在调用栈深度不超过暂停时深度的下一条语句处暂停
+)
+
+// debugger 通过OnStatement回调在断点和单步位置暂停脚本的调试器
+
+type debugger struct {
+	session     *session             // 所属的命令调用，提供脚本的执行设置
+	e           *evaluator.Evaluator // 执行脚本的解释器
+	scanner     *bufio.Scanner       // 读取调试命令
+	out         io.Writer            // 调试器和脚本的输出
+	file        string               // 脚本的源名称，断点省略文件名时使用
+	breakpoints map[string]bool      // 以"文件名:行号"为键的断点
+	mode        debugMode            // 继续执行后的暂停条件
+	depth       int                  // 执行next时的调用栈深度
+	hitStart    *util.Pos            // 最近一次在断点处暂停的语句的起始位置
+	hitEnd      *util.Pos            // 最近一次在断点处暂停的语句的结束位置
+}
+
+// RunDebug 执行debug子命令，在调试器中运行脚本
+// 执行之前读取断点命令，直到输入run、continue或step，之后在断点和单步位置暂停并读取调试命令
+//
+// 参数:
+//
+//	args - debug子命令的参数，包括脚本路径和传递给脚本的参数
+//
+// 返回值:
+//
+//	int - 进程退出码，与直接运行脚本相同，输入quit时为0
+func (s *session) RunDebug(args []string) int {
+	if len(args) == 0 {
+		return s.invalidArguments()
+	}
+	absPath, code, err := readSourceFile(args[0])
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	program, err := loadProgram(absPath, code)
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	d := s.newDebugger(filepath.Base(absPath))
+	if err := d.run(absPath, program, args[1:]); err != nil {
+		if status, ok := requestedExit(err); ok {
+			return status
+		}
+		s.printError(err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// newDebugger 创建从标准输入读取调试命令、输出到标准输出的调试器
+//
+// 参数:
+//
+//	file - 脚本的源名称
+//
+// 返回值:
+//
+//	*debugger - 未设置断点的调试器
+func (s *session) newDebugger(file string) *debugger {
+	return &debugger{
+		session:     s,
+		scanner:     bufio.NewScanner(s.stdin),
+		out:         s.stdout,
+		file:        file,
+		breakpoints: make(map[string]bool),
+	}
+}
+
+// run 读取执行之前的命令，然后在新的全局环境中执行程序
+//
+// 参数:
+//
+//	absPath - 脚本的绝对路径
+//	program - 脚本的语法树
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	error - 运行时错误，输入quit时为nil
+func (d *debugger) run(absPath string, program *ast.Program, scriptArgs []string) error {
+	for started := false; !started; {
+		line, ok := d.readCommand()
+		if !ok {
+			// 输入结束后不再暂停，直接执行到脚本结束
+			d.breakpoints = make(map[string]bool)
+			break
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		switch command {
+		case "":
+		case "r", "run", "c", "continue":
+			started = true
+		case "s", "step":
+			d.mode = debugStep
+			started = true
+		case "b", "break":
+			d.setBreakpoint(strings.TrimSpace(arg))
+		case "h", "help":
+			d.printHelp()
+		case "q", "quit":
+			return nil
+		default:
+			d.printf("Command \"%s\" is not available before the script starts, use \"run\" or \"step\".\n", command)
+		}
+	}
+
+	env := object.NewGlobalEnvironment(scriptArgs)
+	d.e = d.session.newScriptEvaluator(absPath, env)
+	d.e.Stdout = d.out
+	d.e.Hooks = evaluator.Hooks{OnStatement: d.onStatement}
+	d.e.Eval(program, env)
+	if errors.Is(d.e.Err, errDebugQuit) {
+		return nil
+	}
+	return d.e.Err
+}
+
+// onStatement 在每条语句执行之前判断是否暂停，暂停时读取调试命令直到脚本继续执行
+//
+// 参数:
+//
+//	statement - 即将执行的语句
+//	env - 语句的执行环境
+//
+// 返回值:
+//
+//	error - 输入quit时为errDebugQuit
+func (d *debugger) onStatement(statement ast.Statement, env *object.Environment) error {
+	// 函数体和循环体的块本身不是暂停位置，只在其中的语句处暂停
+	if s, ok := statement.(*ast.ExpressionStatement); ok {
+		if _, ok := s.Expr.(*ast.BlockExpression); ok {
+			return nil
+		}
+	}
+	posStart, posEnd := ast.Span(statement)
+	if posStart == nil {
+		return nil
+	}
+	if !d.shouldPause(posStart, posEnd) {
+		return nil
+	}
+	d.printf("Stopped at %s:%d: %s\n", posStart.File(), posStart.Row, sourceLine(posStart))
+	for {
+		line, ok := d.readCommand()
+		if !ok {
+			// 输入结束后不再暂停，直接执行到脚本结束
+			d.mode = debugContinue
+			d.breakpoints = make(map[string]bool)
+			return nil
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case "":
+		case "s", "step":
+			d.mode = debugStep
+			return nil
+		case "n", "next":
+			d.mode = debugNext
+			d.depth = frameDepth(d.e.Frame)
+			return nil
+		case "c", "continue":
+			d.mode = debugContinue
+			return nil
+		case "p", "print":
+			d.printExpression(arg, env)
+		case "bt", "backtrace":
+			d.printBacktrace(posStart)
+		case "locals":
+			d.printLocals(env)
+		case "b", "break":
+			d.setBreakpoint(arg)
+		case "h", "help":
+			d.printHelp()
+		case "q", "quit":
+			return errDebugQuit
+		default:
+			d.printf("Unknown command \"%s\", type \"help\" for a list of commands.\n", command)
+		}
+	}
+}
+
+// shouldPause 根据当前的暂停条件和断点判断是否在语句处暂停
+// 在断点处暂停后，同一行中被该语句包含的语句不会再次因断点暂停
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
+//	posEnd - 语句的结束位置
+//
+// 返回值:
+//
+//	bool - 是否暂停
+func (d *debugger) shouldPause(posStart, posEnd *util.Pos) bool {
+	switch d.mode {
+	case debugStep:
+		d.hitStart, d.hitEnd = posStart, posEnd
+		return true
+	case debugNext:
+		if frameDepth(d.e.Frame) <= d.depth {
+			d.hitStart, d.hitEnd = posStart, posEnd
+			return true
+		}
+	}
+	if !d.breakpoints[fmt.Sprintf("%s:%d", posStart.File(), posStart.Row)] {
+		return false
+	}
+	if d.hitStart != nil && posStart.File() == d.hitStart.File() && posStart.Row == d.hitStart.Row &&
+		posStart.Idx > d.hitStart.Idx && posStart.Idx < d.hitEnd.Idx {
+		return false
+	}
+	d.hitStart, d.hitEnd = posStart, posEnd
+	return true
+}
+
+// readCommand 输出提示符并读取一行调试命令
+//
+// 返回值:
+//
+//	string - 去掉首尾空白的命令
+//	bool - 是否读取成功，输入结束时为false
+func (d *debugger) readCommand() (string, bool) {
+	d.printf("%s", debugPrompt)
+	if !d.scanner.Scan() {
+		d.printf("\n")
+		return "", false
+	}
+	return strings.TrimSpace(d.scanner.Text()), true
+}
+
+// setBreakpoint 解析"文件名:行号"或"行号"形式的位置并设置断点
+//
+// 参数:
+//
+//	location - 断点位置，省略文件名时为脚本本身
+func (d *debugger) setBreakpoint(location string) {
+	file, lineText := d.file, location
+	if i := strings.LastIndex(location, ":"); i >= 0 {
+		file, lineText = filepath.Base(location[:i]), location[i+1:]
+	}
+	line, err := strconv.Atoi(lineText)
+	if err != nil || line <= 0 || file == "" {
+		d.printf("Invalid breakpoint location \"%s\", expected <file>:<line>.\n", location)
+		return
+	}
+	d.breakpoints[fmt.Sprintf("%s:%d", file, line)] = true
+	d.printf("Breakpoint set at %s:%d.\n", file, line)
+}
+
+// printExpression 在暂停位置的环境中解析并执行表达式，输出结果或错误
+// 执行期间不触发回调，表达式中调用的函数不会暂停
+//
+// 参数:
+//
+//	source - 表达式源代码
+//	env - 暂停位置的执行环境
+func (d *debugger) printExpression(source string, env *object.Environment) {
+	if source == "" {
+		d.printf("Usage: print <expr>\n")
+		return
+	}
+	p, err := parser.NewParser(lexer.NewLexer(debugSourceName, source))
+	if err != nil {
+		d.printDebugError(err)
+		return
+	}
+	expr := p.ParseExpression(parser.LOWEST)
+	if p.Err != nil {
+		d.printDebugError(p.Err)
+		return
+	}
+	hooks, outerErr := d.e.Hooks, d.e.Err
+	d.e.Hooks = evaluator.Hooks{}
+	value := d.e.Eval(expr, env)
+	err = d.e.Err
+	d.e.Hooks, d.e.Err = hooks, outerErr
+	if err != nil {
+		d.printDebugError(err)
+		return
+	}
+	d.printf("%s\n", value)
+}
+
+// printBacktrace 从内到外输出调用栈，每层显示函数名和该层中正在执行的位置
+//
+// 参数:
+//
+//	posStart - 暂停位置的语句的起始位置
+func (d *debugger) printBacktrace(posStart *util.Pos) {
+	pos := posStart
+	for i, f := 0, d.e.Frame; f != nil; i, f = i+1, f.Parent {
+		if pos != nil {
+			d.printf("#%d %s at %s:%d\n", i, f.FuncName, pos.File(), pos.Row)
+		} else {
+			d.printf("#%d %s\n", i, f.FuncName)
+		}
+		pos = f.PosStart
+	}
+}
+
+// printLocals 按名称顺序输出暂停位置可见的变量
+// 在函数中只输出函数内部和闭包捕获的变量，在顶层输出除内置函数以外的全局变量
+//
+// 参数:
+//
+//	env - 暂停位置的执行环境
+func (d *debugger) printLocals(env *object.Environment) {
+	topLevel := d.e.Frame.Parent == nil
+	seen := make(map[string]bool)
+	var names []string
+	values := make(map[string]object.Object)
+	for scope := env; scope != nil; scope = scope.Outer {
+		global := scope.Outer == nil
+		if global && !topLevel {
+			break
+		}
+		for _, symbol := range scope.Locals() {
+			name := symbol.Name
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if _, ok := symbol.Value.(*object.BuiltinFunction); ok && global {
+				continue
+			}
+			names = append(names, name)
+			values[name] = symbol.Value
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		d.printf("%s = %s\n", name, values[name])
+	}
+}
+
+// printHelp 输出调试命令列表
+func (d *debugger) printHelp() {
+	for _, line := range debugHelpLines {
+		d.printf("%s\n", line)
+	}
+}
+
+// printDebugError 输出print命令产生的错误，只包含错误类型和描述
+//
+// 参数:
+//
+//	err - 解析或执行表达式时产生的错误
+func (d *debugger) printDebugError(err error) {
+	if diag, ok := diagnostic.FromError(err); ok {
+		d.printf("%s: %s\n", diag.Kind, diag.Message)
+		return
+	}
+	d.printf("%s\n", err)
+}
+
+// printf 向调试器的输出写入格式化文本
+//
+// 参数:
+//
+//	format - 格式字符串
+//	a - 格式参数
+func (d *debugger) printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(d.out, format, a...)
+}
+
+// frameDepth 计算调用栈的深度
+//
+// 参数:
+//
+//	f - 最内层的栈帧
+//
+// 返回值:
+//
+//	int - 栈帧数量
+func frameDepth(f *frame.Frame) int {
+	depth := 0
+	for ; f != nil; f = f.Parent {
+		depth++
+	}
+	return depth
+}
+
+// sourceLine 返回位置所在的源代码行，去掉首尾空白
+//
+// 参数:
+//
+//	pos - 源代码中的位置
+//
+// 返回值:
+//
+//	string - 位置所在的行
+func sourceLine(pos *util.Pos) string {
+	lines := strings.Split(pos.Text(), "\n")
+	if pos.Row < 1 || pos.Row > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[pos.Row-1])
+}
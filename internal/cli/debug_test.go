@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCLI_Debugger(t *testing.T) {
+	code := "var total = 0;\nfunc add(a, b) {\n    var sum = a + b;\n    return sum;\n};\ntotal = add(1, 2);\nprintln(total);"
+	tests := []struct {
+		name     string
+		commands string
+		excepted string
+	}{
+		{
+			name:     "Breakpoint",
+			commands: "b main.gh:3\nrun\np a + b\nbt\nlocals\nc\n",
+			excepted: "Breakpoint set at main.gh:3.\n" +
+				"Stopped at main.gh:3: var sum = a + b;\n" +
+				"3\n" +
+				"#0 <function \"add\"> at main.gh:3\n" +
+				"#1 main.gh at main.gh:6\n" +
+				"a = 1\nb = 2\n" +
+				"3\n",
+		},
+		{
+			name:     "Step Into Calls",
+			commands: "step\ns\ns\ns\ns\nlocals\ns\n",
+			excepted: "Stopped at main.gh:1: var total = 0;\n" +
+				"Stopped at main.gh:2: func add(a, b) {\n" +
+				"Stopped at main.gh:6: total = add(1, 2);\n" +
+				"Stopped at main.gh:3: var sum = a + b;\n" +
+				"Stopped at main.gh:4: return sum;\n" +
+				"a = 1\nb = 2\nsum = 3\n" +
+				"Stopped at main.gh:7: println(total);\n" +
+				"\n3\n",
+		},
+		{
+			name:     "Step Over Calls",
+			commands: "step\nn\nn\np total\nn\nlocals\nc\n",
+			excepted: "Stopped at main.gh:1: var total = 0;\n" +
+				"Stopped at main.gh:2: func add(a, b) {\n" +
+				"Stopped at main.gh:6: total = add(1, 2);\n" +
+				"0\n" +
+				"Stopped at main.gh:7: println(total);\n" +
+				"add = func add(a, b) {...}\ntotal = 3\n" +
+				"3\n",
+		},
+		{
+			name:     "Next Steps Out Of Function",
+			commands: "b 3\nrun\nn\nn\nc\n",
+			excepted: "Breakpoint set at main.gh:3.\n" +
+				"Stopped at main.gh:3: var sum = a + b;\n" +
+				"Stopped at main.gh:4: return sum;\n" +
+				"Stopped at main.gh:7: println(total);\n" +
+				"3\n",
+		},
+		{
+			name:     "Errors And Quit",
+			commands: "b main.gh:x\nb 4\nrun\np nope\np 1 +\nfoo\nq\n",
+			excepted: "Invalid breakpoint location \"main.gh:x\", expected <file>:<line>.\n" +
+				"Breakpoint set at main.gh:4.\n" +
+				"Stopped at main.gh:4: return sum;\n" +
+				"Variable Error: undefined variable \"nope\".\n" +
+				"Syntax Error: expected an expression before end of file.\n" +
+				"Unknown command \"foo\", type \"help\" for a list of commands.\n",
+		},
+		{
+			name:     "End Of Input Runs To Completion",
+			commands: "b 3\n",
+			excepted: "Breakpoint set at main.gh:3.\n\n3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := loadProgram("/tmp/main.gh", code)
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			var out bytes.Buffer
+			d := newSession(strings.NewReader(tt.commands), &out, io.Discard).newDebugger("main.gh")
+			if err := d.run("/tmp/main.gh", program, nil); err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if got := strings.ReplaceAll(out.String(), debugPrompt, ""); got != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
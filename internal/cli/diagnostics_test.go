@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+)
+
+func TestCLI_JSONDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func(s *session) int
+		code     int
+		excepted []diagnostic.Record
+	}{
+		{
+			name: "Syntax Error",
+			run: func(s *session) int {
+				return s.RunFile("testdata/syntax_error.gh", nil)
+			},
+			code: exitSyntaxError,
+			excepted: []diagnostic.Record{
+				{
+					File:      "syntax_error.gh",
+					StartLine: 1,
+					StartCol:  9,
+					EndLine:   1,
+					EndCol:    10,
+					Severity:  "error",
+					Kind:      "SyntaxError",
+					Code:      "E2003",
+					Message:   `unclosed "(" opened at line 1, column 9.`,
+				},
+			},
+		},
+		{
+			name: "Runtime Error",
+			run: func(s *session) int {
+				return s.RunFile("testdata/exit_runtime_error.gh", nil)
+			},
+			code: exitFailure,
+			excepted: []diagnostic.Record{
+				{
+					File:      "exit_runtime_error.gh",
+					StartLine: 2,
+					StartCol:  9,
+					EndLine:   2,
+					EndCol:    16,
+					Severity:  "error",
+					Kind:      "OperationError",
+					Code:      "E3002",
+					Message:   `invalid operation "+".`,
+				},
+			},
+		},
+		{
+			name: "File Not Found",
+			run: func(s *session) int {
+				return s.RunFile("testdata/missing.gh", nil)
+			},
+			code: exitFileError,
+			excepted: []diagnostic.Record{
+				{
+					File:     "testdata/missing.gh",
+					Severity: "error",
+					Kind:     "Error",
+					Message:  `ghost-lang: file not found: "testdata/missing.gh".`,
+				},
+			},
+		},
+		{
+			name: "Check Multiple Files",
+			run: func(s *session) int {
+				return s.CheckFiles([]string{"testdata/check_clean.gh", "testdata/tokens_error.gh"})
+			},
+			code: exitSyntaxError,
+			excepted: []diagnostic.Record{
+				{
+					File:      "tokens_error.gh",
+					StartLine: 2,
+					StartCol:  9,
+					EndLine:   2,
+					EndCol:    10,
+					Severity:  "error",
+					Kind:      "IllegalTokenError",
+					Code:      "E1001",
+					Message:   `illegal token "@".`,
+				},
+			},
+		},
+		{
+			name: "Success",
+			run: func(s *session) int {
+				return s.RunFile("testdata/exit_success.gh", nil)
+			},
+			code:     exitOK,
+			excepted: []diagnostic.Record{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := discardSession()
+			s.diagnostics = "json"
+			code := tt.run(s)
+			var buf bytes.Buffer
+			s.flushDiagnostics(&buf)
+			if code != tt.code {
+				t.Errorf("excepted exit code %d, got %d", tt.code, code)
+			}
+			var got []diagnostic.Record
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("failed to parse JSON output %q: %+v", buf.String(), err)
+			}
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %+v, got %+v", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestCLI_JSONDiagnosticsFields(t *testing.T) {
+	s := discardSession()
+	s.diagnostics = "json"
+	s.RunFile("testdata/syntax_error.gh", nil)
+	var buf bytes.Buffer
+	s.flushDiagnostics(&buf)
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse JSON output %q: %+v", buf.String(), err)
+	}
+	if len(got) != 1 || got[0]["code"] != "E2003" || got[0]["kind"] != "SyntaxError" {
+		t.Errorf("excepted code E2003 and kind SyntaxError, got %q", buf.String())
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCLI_RunExitCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []string
+		excepted  int
+	}{
+		{
+			name:      "Success",
+			arguments: []string{"testdata/exit_success.gh"},
+			excepted:  exitOK,
+		},
+		{
+			name:      "Runtime Error",
+			arguments: []string{"testdata/exit_runtime_error.gh"},
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Syntax Error",
+			arguments: []string{"run", "testdata/syntax_error.gh"},
+			excepted:  exitSyntaxError,
+		},
+		{
+			name:      "Illegal Token",
+			arguments: []string{"testdata/tokens_error.gh"},
+			excepted:  exitSyntaxError,
+		},
+		{
+			name:      "File Not Found",
+			arguments: []string{"testdata/missing.gh"},
+			excepted:  exitFileError,
+		},
+		{
+			name:      "Invalid File Extension",
+			arguments: []string{"run", "testdata/ast.golden"},
+			excepted:  exitFileError,
+		},
+		{
+			name:      "Exit Builtin",
+			arguments: []string{"testdata/exit_requested.gh"},
+			excepted:  7,
+		},
+		{
+			name:      "AST Syntax Error",
+			arguments: []string{"--ast", "testdata/syntax_error.gh"},
+			excepted:  exitSyntaxError,
+		},
+		{
+			name:      "Tokens File Not Found",
+			arguments: []string{"--tokens", "testdata/missing.gh"},
+			excepted:  exitFileError,
+		},
+		{
+			name:      "Check Clean File",
+			arguments: []string{"--check", "testdata/check_clean.gh"},
+			excepted:  exitOK,
+		},
+		{
+			name:      "Version",
+			arguments: []string{"-v"},
+			excepted:  exitOK,
+		},
+		{
+			name:      "Version Long Flag",
+			arguments: []string{"--version"},
+			excepted:  exitOK,
+		},
+		{
+			name:      "Help Long Flag",
+			arguments: []string{"--help"},
+			excepted:  exitOK,
+		},
+		{
+			name:      "Unknown Command",
+			arguments: []string{"build"},
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Invalid Flag",
+			arguments: []string{"--unknown"},
+			excepted:  exitUsage,
+		},
+		{
+			name:      "Invalid Diagnostics Format",
+			arguments: []string{"--diagnostics=xml", "main.gh"},
+			excepted:  exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Run(tt.arguments, strings.NewReader(""), io.Discard, io.Discard)
+			if got != tt.excepted {
+				t.Errorf("excepted %d, got %d", tt.excepted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/formatter"
+)
+
+// FormatFiles 执行fmt子命令，格式化指定的.gh文件或目录
+// 默认将格式化结果输出到标准输出；-w 将结果写回文件；-l 列出格式会发生变化的文件
+// 存在错误，或使用 -l 且有文件需要格式化时返回非零退出码
+//
+// 参数:
+//
+//	args - fmt子命令的参数，包括标志和文件或目录路径
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) FormatFiles(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	write := flags.Bool("w", false, "Write")
+	list := flags.Bool("l", false, "List")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return s.invalidArguments()
+	}
+	changed, errs := formatPaths(flags.Args(), *write, *list, s.stdout)
+	code := exitOK
+	for _, err := range errs {
+		s.printError(err)
+		code = max(code, exitCode(err))
+	}
+	// 刷新标准输出缓冲区
+	syncOutput(s.stdout)
+	if *list && changed {
+		code = max(code, exitFailure)
+	}
+	return code
+}
+
+// formatPaths 格式化多个文件或目录，目录中的.gh文件会被递归处理
+// 出错的文件会被跳过，不影响其他文件
+//
+// 参数:
+//
+//	paths - 文件或目录路径列表
+//	write - 是否将结果写回文件
+//	list - 是否列出格式会发生变化的文件
+//	out - 格式化结果和文件列表的输出位置
+//
+// 返回值:
+//
+//	bool - 是否有文件的格式发生变化
+//	[]error - 处理过程中遇到的错误
+func formatPaths(paths []string, write, list bool, out io.Writer) (bool, []error) {
+	var changed bool
+	var errs []error
+	for _, path := range paths {
+		var files []string
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && filepath.Ext(file) == ".gh" {
+					files = append(files, file)
+				}
+				return nil
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("ghost-lang: failed to read directory: \"%s\".", path))
+				continue
+			}
+		} else {
+			files = append(files, path)
+		}
+		for _, file := range files {
+			fileChanged, err := formatFile(file, write, list, out)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			changed = changed || fileChanged
+		}
+	}
+	return changed, errs
+}
+
+// formatFile 格式化单个.gh文件
+// 解析失败的文件不会被修改
+//
+// 参数:
+//
+//	fileName - 文件路径
+//	write - 是否将结果写回文件
+//	list - 是否列出格式会发生变化的文件
+//	out - 格式化结果和文件列表的输出位置
+//
+// 返回值:
+//
+//	bool - 文件的格式是否发生变化
+//	error - 读取、解析或写入过程中的错误
+func formatFile(fileName string, write, list bool, out io.Writer) (bool, error) {
+	absPath, code, err := readRawSourceFile(fileName)
+	if err != nil {
+		return false, err
+	}
+	formatted, err := formatter.Format(filepath.Base(absPath), code)
+	if err != nil {
+		return false, err
+	}
+	changed := formatted != code
+	if list && changed {
+		_, _ = fmt.Fprintln(out, fileName)
+	}
+	if write && changed {
+		if err := writeFileAtomic(absPath, formatted); err != nil {
+			return false, fmt.Errorf("ghost-lang: failed to write file: \"%s\".", fileName)
+		}
+	}
+	if !write && !list {
+		_, _ = fmt.Fprint(out, formatted)
+	}
+	return changed, nil
+}
+
+// writeFileAtomic 通过临时文件替换的方式写入文件，保留原文件的权限
+// 写入失败时原文件保持不变
+//
+// 参数:
+//
+//	fileName - 要写入的文件路径
+//	content - 新的文件内容
+//
+// 返回值:
+//
+//	error - 写入过程中的错误
+func writeFileAtomic(fileName, content string) error {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// 出错时清理临时文件，重命名成功后删除不会产生影响
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.WriteString(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fileName)
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/formatter"
+)
+
+const (
+	unformattedSource = "var x=1;\nfunc f(a){return a+x};\n"
+	formattedSource   = "var x = 1;\nfunc f(a) {\n    return a + x;\n};\n"
+)
+
+// writeTestFile 在临时目录中创建测试文件
+func writeTestFile(t *testing.T, dir, name, content string, perm os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatalf("failed to write test file: %+v", err)
+	}
+	return path
+}
+
+func TestCLI_FormatPaths_Stdout(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "a.gh", unformattedSource, 0644)
+	var out bytes.Buffer
+	changed, errs := formatPaths([]string{path}, false, false, &out)
+	if len(errs) != 0 {
+		t.Fatalf("errs = %+v, expected nil", errs)
+	}
+	if !changed {
+		t.Errorf("changed = false, expected true")
+	}
+	if out.String() != formattedSource {
+		t.Errorf("got:\n%s\nexpected:\n%s", out.String(), formattedSource)
+	}
+	// 不写回时文件内容保持不变
+	data, _ := os.ReadFile(path)
+	if string(data) != unformattedSource {
+		t.Errorf("file was modified without -w")
+	}
+}
+
+func TestCLI_FormatPaths_Write(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "a.gh", unformattedSource, 0600)
+	var out bytes.Buffer
+	changed, errs := formatPaths([]string{path}, true, false, &out)
+	if len(errs) != 0 {
+		t.Fatalf("errs = %+v, expected nil", errs)
+	}
+	if !changed {
+		t.Errorf("changed = false, expected true")
+	}
+	if out.Len() != 0 {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != formattedSource {
+		t.Errorf("got:\n%s\nexpected:\n%s", data, formattedSource)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("perm = %v, expected %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+	// 再次格式化时内容不变，文件不应被重写
+	before := info.ModTime()
+	changed, errs = formatPaths([]string{path}, true, false, &out)
+	if changed || len(errs) != 0 {
+		t.Errorf("changed = %v, errs = %+v, expected false and nil", changed, errs)
+	}
+	info, _ = os.Stat(path)
+	if !info.ModTime().Equal(before) {
+		t.Errorf("unchanged file was rewritten")
+	}
+}
+
+func TestCLI_FormatPaths_List(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "clean.gh", formattedSource, 0644)
+	dirty := writeTestFile(t, dir, "dirty.gh", unformattedSource, 0644)
+	writeTestFile(t, dir, "notes.txt", unformattedSource, 0644)
+	var out bytes.Buffer
+	changed, errs := formatPaths([]string{dir}, false, true, &out)
+	if len(errs) != 0 {
+		t.Fatalf("errs = %+v, expected nil", errs)
+	}
+	if !changed {
+		t.Errorf("changed = false, expected true")
+	}
+	if out.String() != dirty+"\n" {
+		t.Errorf("got %q, expected %q", out.String(), dirty+"\n")
+	}
+}
+
+func TestCLI_FormatPaths_SyntaxError(t *testing.T) {
+	dir := t.TempDir()
+	broken := writeTestFile(t, dir, "broken.gh", "var x = (1 + 2;\n", 0644)
+	dirty := writeTestFile(t, dir, "dirty.gh", unformattedSource, 0644)
+	var out bytes.Buffer
+	changed, errs := formatPaths([]string{broken, dirty}, true, false, &out)
+	if len(errs) != 1 {
+		t.Fatalf("len(errs) = %d, expected 1", len(errs))
+	}
+	if !changed {
+		t.Errorf("changed = false, expected true")
+	}
+	// 存在语法错误的文件被跳过且保持不变
+	data, _ := os.ReadFile(broken)
+	if string(data) != "var x = (1 + 2;\n" {
+		t.Errorf("broken file was modified: %q", data)
+	}
+	data, _ = os.ReadFile(dirty)
+	if string(data) != formattedSource {
+		t.Errorf("got:\n%s\nexpected:\n%s", data, formattedSource)
+	}
+}
+
+func TestCLI_FormatPaths_Equivalence(t *testing.T) {
+	// 对示例文件格式化后应能重新解析，且结果稳定
+	for _, name := range []string{"testdata/ast.gh", "testdata/check_clean.gh"} {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if _, errs := formatPaths([]string{name}, false, false, &out); len(errs) != 0 {
+				t.Fatalf("errs = %+v, expected nil", errs)
+			}
+			again, err := formatter.Format(filepath.Base(name), out.String())
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if again != out.String() {
+				t.Errorf("format is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
@@ -1,17 +1,82 @@
 package cli
 
+import (
+	"fmt"
+	"io"
+)
+
+// usageLines 命令行帮助信息的各行
+var usageLines = []string{
+	"Usage: ghost [global flags] <command> [arguments]",
+	"       ghost [global flags] [--] <file>... [script arguments]",
+	"       ghost [global flags] [- [script arguments]] < script",
+	"Global Flags:",
+	"  -h, --help             Show help",
+	"  -v, --version          Print version",
+	"  -r                     Start REPL",
+	"  --ast <file>           Print the AST of a .gh file without executing it",
+	"  --tokens <file>        Print the token stream of a .gh file",
+	"  --check <file>...      Check .gh files for errors without executing them",
+	"  --diagnostics=<fmt>    Error output format: text (default) or json",
+	"  --max-errors <n>       List at most n syntax errors (default: 20, 0: no limit)",
+	"  -W                     Report warnings such as unused variables before running",
+	"  -Werror                Treat warnings as errors (also applies to --check)",
+	"  --precheck             Report undefined names and other semantic errors before running",
+	"  --watch <file> [args]  Run a .gh file and re-run it whenever it or its imports change",
+	"  --strict               Raise a type error when an operator mixes int and float",
+	"  --sandbox              Disable OS-facing builtins (exit, args) and file imports",
+	"  --max-steps <n>        Abort after evaluating n syntax nodes (default: unlimited)",
+	"  --max-depth <n>        Abort when function calls nest deeper than n",
+	"  --max-alloc <bytes>    Abort when a single string or list grows beyond the given size",
+	"  --timeout <duration>   Abort when the script runs longer than the duration (e.g. 2s, 500ms)",
+	"  --cpuprofile <file>    Write a CPU profile of the interpreter to file (go tool pprof)",
+	"  --memprofile <file>    Write a memory allocation profile of the interpreter to file",
+	"  --cache                Cache the parsed script in <file>.ghostc to skip parsing on later runs",
+	"  --debug                Print the Go stack when the interpreter itself fails (internal error)",
+	"Commands:",
+	"  repl                   Start REPL",
+	"  run <file>... [args]   Execute .gh files in one environment, passing args to the script",
+	"  run --cover <out> <f>  Execute a .gh file and write a line coverage listing to out",
+	"  fmt [-w] [-l] <path>   Format .gh files (-w: rewrite, -l: list changed)",
+	"  test [-run re] [dir]   Run test functions in *_test.gh files (default dir: .)",
+	"  test -cover [dir]      Run tests and report line coverage (-coverprofile <out>: write listing)",
+	"  bench [-count n] <f>   Benchmark a .gh file (default: runs for about 1s)",
+	"  debug <file> [args...] Run a .gh file in the interactive debugger",
+	"  lsp                    Start the language server on stdio",
+	"Examples:",
+	"  ghost -r               # Start REPL with flag",
+	"  ghost repl             # Start REPL with command",
+	"  ghost run main.gh      # Run a file",
+	"  ghost main.gh a --b    # Run a file, args() returns [a, --b]",
+	"  ghost -- -main.gh      # Run a file whose name starts with a dash",
+	"  ghost lib.gh main.gh   # Run files in order, sharing definitions",
+	"  cat main.gh | ghost    # Run a script read from stdin",
+	"  ghost fmt -w main.gh   # Format a file in place",
+	"  ghost --ast main.gh    # Print the AST of a file",
+	"  ghost --tokens main.gh # Print the tokens of a file",
+	"  ghost --check main.gh  # Check a file for errors",
+	"  ghost --diagnostics=json --check main.gh # Report errors as JSON",
+	"  ghost -W main.gh       # Warn about unused variables, then run",
+	"  ghost --watch main.gh  # Re-run a file on every save",
+	"  ghost --strict main.gh # Run a file without implicit int/float promotion",
+	"  ghost --sandbox main.gh # Run an untrusted file",
+	"  ghost --cache main.gh  # Reuse main.gh.ghostc while main.gh is unchanged",
+	"  ghost test -run add    # Run tests whose names match \"add\"",
+	"  ghost debug main.gh    # Debug a file with breakpoints and stepping",
+}
+
 // PrintHelp 显示命令行帮助信息
-func PrintHelp() {
-	printInfo("Usage: ghost [global flags] <command> [arguments]")
-	printInfo("Global Flags:")
-	printInfo("  -h                     Show help")
-	printInfo("  -v                     Print version")
-	printInfo("  -r                     Start REPL")
-	printInfo("Commands:")
-	printInfo("  repl                   Start REPL")
-	printInfo("  run <file>             Execute a .gh file")
-	printInfo("Examples:")
-	printInfo("  ghost -r               # Start REPL with flag")
-	printInfo("  ghost repl             # Start REPL with command")
-	printInfo("  ghost run main.gh      # Run a file")
+func (s *session) PrintHelp() {
+	printUsage(s.stdout)
+}
+
+// printUsage 将命令行帮助信息输出到指定位置
+//
+// 参数:
+//
+//	w - 输出位置，请求帮助时为标准输出，参数非法时为标准错误
+func printUsage(w io.Writer) {
+	for _, line := range usageLines {
+		_, _ = fmt.Fprintf(w, "\033[34m%s\033[0m\n", line)
+	}
 }
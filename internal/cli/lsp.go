@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/lsp"
+)
+
+// StartLSP 启动语言服务器，通过标准输入输出与编辑器通信
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) StartLSP() int {
+	return lsp.NewServer(s.stdin, s.stdout).Run()
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// startProfiling 按 --cpuprofile 和 --memprofile 标志开始对解释器自身进行性能分析
+// 返回的函数停止CPU分析并写入内存分配分析，需要在命令执行结束后调用，脚本出错或调用exit()时同样如此
+//
+// 参数:
+//
+//	cpuFile - CPU分析的输出文件，为空时不进行CPU分析
+//	memFile - 内存分配分析的输出文件，为空时不进行内存分析
+//
+// 返回值:
+//
+//	func() error - 停止分析并写入文件的函数，返回写入失败时的错误
+//	error - CPU分析的输出文件无法创建时的错误
+func startProfiling(cpuFile, memFile string) (func() error, error) {
+	var cpu *os.File
+	if cpuFile != "" {
+		f, err := os.Create(cpuFile)
+		if err != nil {
+			return nil, profileError(cpuFile)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			_ = f.Close()
+			return nil, profileError(cpuFile)
+		}
+		cpu = f
+	}
+	return func() error {
+		var errs []error
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			if err := cpu.Close(); err != nil {
+				errs = append(errs, profileError(cpuFile))
+			}
+		}
+		if memFile != "" {
+			errs = append(errs, writeMemProfile(memFile))
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// writeMemProfile 执行垃圾回收后将内存分配分析写入文件
+//
+// 参数:
+//
+//	memFile - 输出文件
+//
+// 返回值:
+//
+//	error - 文件无法创建或写入时的错误
+func writeMemProfile(memFile string) error {
+	f, err := os.Create(memFile)
+	if err != nil {
+		return profileError(memFile)
+	}
+	// 垃圾回收后的统计才包含最近释放的对象
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		_ = f.Close()
+		return profileError(memFile)
+	}
+	if err := f.Close(); err != nil {
+		return profileError(memFile)
+	}
+	return nil
+}
+
+// profileError 创建分析文件无法写入的错误
+//
+// 参数:
+//
+//	fileName - 分析文件路径
+//
+// 返回值:
+//
+//	error - 文件错误
+func profileError(fileName string) error {
+	return &fileError{File: fileName, Message: fmt.Sprintf("ghost-lang: failed to write profile: \"%s\".", fileName)}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCLI_Profiling(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted int
+	}{
+		{
+			name:     "Success",
+			input:    "var s = 0; for var i = 0; i < 1000; i += 1 { s += i; }; println(s);",
+			excepted: exitOK,
+		},
+		{
+			name:     "Runtime Error",
+			input:    "var s = 0; for var i = 0; i < 1000; i += 1 { s += i; }; 1 / 0;",
+			excepted: exitFailure,
+		},
+		{
+			name:     "Exit",
+			input:    "var s = 0; for var i = 0; i < 1000; i += 1 { s += i; }; exit(3);",
+			excepted: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cpuFile := filepath.Join(dir, "cpu.pprof")
+			memFile := filepath.Join(dir, "mem.pprof")
+			_, _, code := runWithStdin(t, tt.input, []string{"--cpuprofile", cpuFile, "--memprofile", memFile, "-"})
+			if code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+			for _, file := range []string{cpuFile, memFile} {
+				assertProfile(t, file)
+			}
+		})
+	}
+}
+
+func TestCLI_ProfilingUnwritableFile(t *testing.T) {
+	cpuFile := filepath.Join(t.TempDir(), "missing", "cpu.pprof")
+	_, stderr, code := runWithStdin(t, "println(1);", []string{"--cpuprofile", cpuFile, "-"})
+	if code != exitFileError {
+		t.Errorf("excepted exit code %d, got %d", exitFileError, code)
+	}
+	if stderr == "" {
+		t.Errorf("excepted an error on stderr")
+	}
+}
+
+// assertProfile 检查文件是否为非空的pprof格式分析文件，即gzip压缩的protobuf数据
+func assertProfile(t *testing.T, file string) {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("failed to open profile: %+v", err)
+	}
+	defer func() { _ = f.Close() }()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("%s is not a gzip-compressed profile: %+v", filepath.Base(file), err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read %s: %+v", filepath.Base(file), err)
+	}
+	if len(data) == 0 {
+		t.Errorf("excepted %s to be non-empty", filepath.Base(file))
+	}
+}
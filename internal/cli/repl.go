@@ -17,10 +17,15 @@ import (
 )
 
 // StartREPL 启动repl，提供即时代码执行环境
-func StartREPL() {
+//
+// 返回值:
+//
+//	int - 进程退出码，输入exit(n)时为n
+func (s *session) StartREPL() int {
 	// 捕获中断信号 (Ctrl+C)，跨平台处理
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 	// 添加退出标志
 	var exitRequested bool
 	go func() {
@@ -29,27 +34,16 @@ func StartREPL() {
 		// 首先设置退出标志
 		exitRequested = true
 		// 打印退出信息
-		printInfo("\nBye!")
+		s.printInfo("\nBye!")
 		// 刷新标准输出缓冲区
-		_ = os.Stdout.Sync()
+		syncOutput(s.stdout)
 	}()
 	// 显示版本和欢迎信息
-	printInfo(fmt.Sprintf("ghost-lang %s | %s/%s | built %s.", Version, Platform, Arch, BuildTime))
-	printInfo("Welcome to the Ghost REPL.")
-	printInfo("Press Ctrl+C to exit.")
+	s.printInfo(fmt.Sprintf("ghost-lang %s | %s/%s | built %s.", Version, Platform, Arch, BuildTime))
+	s.printInfo("Welcome to the Ghost REPL.")
+	s.printInfo("Press Ctrl+C to exit.")
 	// 创建解释器环境
-	env := &object.Environment{
-		Store: make(map[string]*object.Symbol),
-		Outer: nil,
-	}
-	// 加载内置函数
-	for name, builtin := range object.Builtins {
-		env.Store[name] = &object.Symbol{
-			Name:    name,
-			Value:   builtin,
-			IsConst: true,
-		}
-	}
+	env := object.NewGlobalEnvironment(nil)
 	// 创建调用栈
 	f := &frame.Frame{
 		FuncName: "<stdin>",
@@ -57,32 +51,63 @@ func StartREPL() {
 		PosEnd:   nil,
 		Parent:   nil,
 	}
-	scanner := bufio.NewScanner(os.Stdin)
+	// 创建解释器，在多次输入之间共享已导入模块的缓存
+	e := evaluator.NewEvaluator(f)
+	e.Strict = s.strict
+	e.Stdout = s.stdout
+	e.Stderr = s.stderr
+	e.Sandbox = s.sandbox
+	e.Limits = s.limits
+	if s.sandbox {
+		env.Sandbox()
+	}
+	// 词法和语法分析器在每次输入之间复用，不必重新创建解析函数表
+	l := lexer.NewLexer("<stdin>", "")
+	p, _ := parser.NewParser(l)
+	scanner := bufio.NewScanner(s.stdin)
+	// 终端中输入的行会回显，续行减少缩进时重新绘制该行
+	interactive := s.stdinIsTerminal()
 	// 交互式输入循环
 	for !exitRequested {
-		fmt.Print(">>> ")
+		fmt.Fprint(s.stdout, ">>> ")
 		// 刷新标准输出缓冲区
-		_ = os.Stdout.Sync()
+		syncOutput(s.stdout)
 		// 多行输入处理
 		var lines []string
 		scannerOK := false
+		// 未闭合的括号数，决定续行的缩进
+		depth := 0
 		// 重复读取输入
 		for scanner.Scan() && !exitRequested {
-			lines = append(lines, scanner.Text())
+			line := scanner.Text()
+			if len(lines) > 0 {
+				line = indentLine(line, depth)
+				if interactive && !strings.HasPrefix(line, strings.Repeat(replIndent, depth)) {
+					fmt.Fprintf(s.stdout, "\033[A\r\033[K... %s\n", line)
+				}
+			}
+			lines = append(lines, line)
 			source := strings.ReplaceAll(strings.Join(lines, "\n"), "\t", "    ")
+			// 闭括号多于开括号或与开括号不匹配时，继续输入也无法修复，直接报错
+			open, err := scanBrackets("<stdin>", source)
+			if err != nil {
+				s.printError(err)
+				scannerOK = true
+				break
+			}
+			depth = len(open)
 			// 尝试解析，词法分析
-			l := lexer.NewLexer("<stdin>", source)
+			l.Reset("<stdin>", source)
 			// 语法分析
-			p, err := parser.NewParser(l)
-			if err != nil {
+			if err := p.Reset(l); err != nil {
 				if !shouldContinue(err) {
-					printError(err)
+					s.printError(err)
 					scannerOK = true
 					break
 				} else {
-					fmt.Print("... ")
+					fmt.Fprint(s.stdout, continuationPrompt(depth))
 					// 刷新标准输出缓冲区
-					_ = os.Stdout.Sync()
+					syncOutput(s.stdout)
 					continue
 				}
 			}
@@ -91,103 +116,143 @@ func StartREPL() {
 				if !shouldContinue(p.Err) {
 					var syntaxError *parser.SyntaxError
 					ok := errors.As(p.Err, &syntaxError)
-					if ok && syntaxError.Message == "expected \"SEMICOLON\", but got \"EOF\"." {
+					if ok && syntaxError.EOF && syntaxError.Expected == lexer.SEMICOLON {
 						// 重试解析为表达式
-						l2 := lexer.NewLexer("<stdin>", source)
-						p2, err2 := parser.NewParser(l2)
-						if err2 != nil {
-							if !shouldContinue(err2) {
-								printError(err2)
+						l.Reset("<stdin>", source)
+						if err := p.Reset(l); err != nil {
+							if !shouldContinue(err) {
+								s.printError(err)
 								scannerOK = true
 								break
 							} else {
-								fmt.Print("... ")
+								fmt.Fprint(s.stdout, continuationPrompt(depth))
 								// 刷新标准输出缓冲区
-								_ = os.Stdout.Sync()
+								syncOutput(s.stdout)
 								continue
 							}
 						}
-						expr := p2.ParseExpression(parser.LOWEST)
-						if p2.Err != nil {
-							if !shouldContinue(p2.Err) {
-								printError(p2.Err)
+						expr := p.ParseExpression(parser.LOWEST)
+						if p.Err != nil {
+							if !shouldContinue(p.Err) {
+								s.printError(p.Err)
 								scannerOK = true
 								break
 							} else {
-								fmt.Print("... ")
+								fmt.Fprint(s.stdout, continuationPrompt(depth))
 								// 刷新标准输出缓冲区
-								_ = os.Stdout.Sync()
+								syncOutput(s.stdout)
 								continue
 							}
 						}
 						// 执行表达式并输出结果
-						e := evaluator.NewEvaluator(f)
+						e.Frame, e.Err = f, nil
+						e.ResetSteps()
 						ret := e.Eval(expr, env)
 						if e.Err != nil {
-							printError(e.Err)
+							if code, ok := requestedExit(e.Err); ok {
+								return code
+							}
+							s.printError(e.Err)
 							scannerOK = true
 							break
 						}
 						if ret != nil {
-							fmt.Print("::: ")
-							fmt.Println(ret)
+							fmt.Fprint(s.stdout, "::: ")
+							fmt.Fprintln(s.stdout, ret)
 							// 刷新标准输出缓冲区
-							_ = os.Stdout.Sync()
+							syncOutput(s.stdout)
 						}
 						scannerOK = true
 						break
 					}
-					printError(p.Err)
+					s.printError(p.Err)
 					scannerOK = true
 					break
 				} else {
-					fmt.Print("... ")
+					fmt.Fprint(s.stdout, continuationPrompt(depth))
 					// 刷新标准输出缓冲区
-					_ = os.Stdout.Sync()
+					syncOutput(s.stdout)
 					continue
 				}
 			}
 			// 执行程序
-			e := evaluator.NewEvaluator(f)
+			e.Frame, e.Err = f, nil
+			e.ResetSteps()
 			res := e.Eval(program, env)
 			if e.Err != nil {
-				printError(e.Err)
+				if code, ok := requestedExit(e.Err); ok {
+					return code
+				}
+				s.printError(e.Err)
 				scannerOK = true
 				break
 			}
 			if res != nil {
-				fmt.Print("::: ")
-				fmt.Println(res)
+				fmt.Fprint(s.stdout, "::: ")
+				fmt.Fprintln(s.stdout, res)
 				// 刷新标准输出缓冲区
-				_ = os.Stdout.Sync()
+				syncOutput(s.stdout)
 			}
 			scannerOK = true
 			break
 		}
 		if !scannerOK && !exitRequested {
 			if err := scanner.Err(); err != nil {
-				printError("ghost-lang: failed to read input.")
+				s.printError("ghost-lang: failed to read input.")
 			} else {
 				// 打印退出信息
-				printInfo("\nBye!")
+				s.printInfo("\nBye!")
 				// 刷新标准输出缓冲区
-				_ = os.Stdout.Sync()
-				return
+				syncOutput(s.stdout)
+				return exitOK
 			}
 		}
 	}
 	// 确保退出前刷新缓冲区
-	_ = os.Stdout.Sync()
+	syncOutput(s.stdout)
+	return exitOK
+}
+
+// requestedExit 判断错误是否为exit函数产生的退出请求
+//
+// 参数:
+//
+//	err - 执行过程中产生的错误
+//
+// 返回值:
+//
+//	int - 请求的退出码
+//	bool - 是否为退出请求
+func requestedExit(err error) (int, bool) {
+	var exitErr *object.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code, true
+	}
+	return 0, false
+}
+
+// continuationPrompt 返回续行的提示符，按未闭合的括号数自动缩进
+//
+// 参数:
+//
+//	depth - 未闭合的括号数
+//
+// 返回值:
+//
+//	string - 提示符和缩进
+func continuationPrompt(depth int) string {
+	return "... " + strings.Repeat(replIndent, depth)
 }
 
 // 判断是否需要继续解析
 func shouldContinue(err error) bool {
-	msg := err.Error()
-	if strings.Contains(msg, "expected \"SEMICOLON\", but got \"EOF\".") {
-		return false
+	var syntaxError *parser.SyntaxError
+	if errors.As(err, &syntaxError) {
+		// 只缺少最后的分号时输入已经完整，按表达式处理
+		return syntaxError.EOF && syntaxError.Expected != lexer.SEMICOLON
 	}
+	msg := err.Error()
 	return strings.Contains(msg, "\"*/\" is expected.") ||
 		strings.Contains(msg, "unterminated string literal.") ||
-		strings.Contains(msg, "unexpected \"EOF\".") ||
-		strings.Contains(msg, "but got \"EOF\"")
+		strings.Contains(msg, "unterminated interpolation")
 }
@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -10,98 +13,195 @@ import (
 	"time"
 
 	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
-	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
-	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lint"
 	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+	"github.com/Ghost-Xiao/ghost-lang/pkg/ghost"
 )
 
+// scriptSource 待执行的一个脚本文件
+
+type scriptSource struct {
+	absPath string // 源文件的绝对路径
+	code    string // 源代码文本
+}
+
 // RunFile 执行指定的.gh文件
 //
 // 参数:
 //
 //	fileName - 要执行的文件路径
-func RunFile(fileName string) {
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	int - 进程退出码，脚本调用exit(n)时为n
+func (s *session) RunFile(fileName string, scriptArgs []string) int {
+	return s.RunFiles([]string{fileName}, scriptArgs)
+}
+
+// RunFiles 在同一个全局环境中依次执行多个.gh文件，前面的文件中定义的变量和函数在后面的文件中可见
+// 执行之前读取所有文件，某个文件出错时不再执行之后的文件，退出码为该文件的错误对应的退出码
+//
+// 参数:
+//
+//	fileNames - 要执行的文件路径，按执行顺序排列
+//	scriptArgs - 传递给脚本的命令行参数，每个文件中的args()都返回这些参数
+//
+// 返回值:
+//
+//	int - 进程退出码，脚本调用exit(n)时为n
+func (s *session) RunFiles(fileNames []string, scriptArgs []string) int {
+	sources := make([]scriptSource, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		// 读取源文件
+		absPath, code, err := readSourceFile(fileName)
+		if err != nil {
+			s.printError(err)
+			return exitCode(err)
+		}
+		sources = append(sources, scriptSource{absPath: absPath, code: code})
+	}
+	// JSON模式下标准输出只包含程序输出和诊断信息
+	return s.runSource(sources, scriptArgs, s.diagnostics != "json")
+}
+
+// RunStdin 读取标准输入的全部内容作为源代码执行，源名称为<stdin>
+// 相对导入路径基于当前工作目录解析，标准输出通常被其他程序读取，因此不输出版本信息和执行时间
+//
+// 参数:
+//
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	int - 进程退出码，脚本调用exit(n)时为n
+func (s *session) RunStdin(scriptArgs []string) int {
+	absPath, code, err := s.readStdin()
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	return s.runSource([]scriptSource{{absPath: absPath, code: code}}, scriptArgs, false)
+}
+
+// runCommand 执行run子命令，指定 --cover 标志时记录行覆盖率并写入指定的文件
+//
+// 参数:
+//
+//	args - run子命令的参数，包括 --cover 标志、脚本路径和传递给脚本的参数
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) runCommand(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	cover := flags.String("cover", "", "Cover")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return s.invalidArguments()
+	}
+	if *cover != "" {
+		return s.runWithCoverage(flags.Arg(0), flags.Args()[1:], *cover)
+	}
+	scripts, scriptArgs := splitScripts(flags.Args())
+	return s.runScript(scripts, scriptArgs)
+}
+
+// runScript 依次执行脚本，脚本路径为"-"时从标准输入读取源代码
+//
+// 参数:
+//
+//	scripts - 脚本路径，为"-"时只包含这一项
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) runScript(scripts []string, scriptArgs []string) int {
+	if scripts[0] == stdinFileName {
+		return s.RunStdin(scriptArgs)
+	}
+	return s.RunFiles(scripts, scriptArgs)
+}
+
+// splitScripts 将脚本之后的参数分为要执行的脚本和传递给脚本的参数
+// 第一个参数总是脚本，之后连续的.gh文件同样作为脚本依次执行，紧随其后的"--"表示脚本列表结束并被去掉
+// 第一个参数为"-"时从标准输入读取脚本，之后的参数全部传递给脚本
+//
+// 参数:
+//
+//	args - 以脚本开头的参数
+//
+// 返回值:
+//
+//	[]string - 脚本路径
+//	[]string - 传递给脚本的参数
+func splitScripts(args []string) ([]string, []string) {
+	n := 1
+	if args[0] != stdinFileName {
+		for n < len(args) && strings.HasSuffix(args[n], ".gh") {
+			n++
+		}
+	}
+	scriptArgs := args[n:]
+	if len(scriptArgs) > 0 && scriptArgs[0] == "--" {
+		scriptArgs = scriptArgs[1:]
+	}
+	return args[:n], scriptArgs
+}
+
+// runSource 在同一个解释器中依次执行源代码并输出错误
+//
+// 参数:
+//
+//	sources - 要执行的源代码，按执行顺序排列
+//	scriptArgs - 传递给脚本的命令行参数
+//	verbose - 是否输出版本、文件信息和执行时间
+//
+// 返回值:
+//
+//	int - 进程退出码，脚本调用exit(n)时为n
+func (s *session) runSource(sources []scriptSource, scriptArgs []string, verbose bool) int {
 	// 捕获中断信号 (Ctrl+C)，跨平台处理
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 	go func() {
 		// 等待中断信号
 		<-sigChan
 		// 打印退出信息
-		printInfo("\nExecution stopped by user.")
+		s.printInfo("\nExecution stopped by user.")
 		// 刷新标准输出缓冲区
-		_ = os.Stdout.Sync()
+		syncOutput(s.stdout)
 		// 退出
 		os.Exit(0)
 	}()
 
-	// 验证文件扩展名
-	slice := strings.Split(fileName, ".")
-	if (len(slice) > 1 && slice[len(slice)-1] != "gh") || len(slice) <= 1 {
-		printError(fmt.Sprintf("ghost-lang: invalid file extension: \"%s\".", fileName))
-		return
-	}
-
-	// 读取文件内容
-	data, err := os.ReadFile(fileName)
-	if err != nil {
-		printError(fmt.Sprintf("ghost-lang: file not found: \"%s\".", fileName))
-		return
-	}
-
-	// 获取绝对路径
-	absPath, err := filepath.Abs(fileName)
-	if err != nil {
-		printError(fmt.Sprintf("ghost-lang: failed to resolve absolute path: \"%s\".", fileName))
-		return
-	}
-
 	// 显示版本和文件信息
-	printInfo(fmt.Sprintf("ghost-lang %s | %s/%s | built %s.", Version, Platform, Arch, BuildTime))
-	printInfo(fmt.Sprintf("Running file \"%s\".", absPath))
+	if verbose {
+		s.printInfo(fmt.Sprintf("ghost-lang %s | %s/%s | built %s.", Version, Platform, Arch, BuildTime))
+		for _, source := range sources {
+			s.printInfo(fmt.Sprintf("Running file \"%s\".", source.absPath))
+		}
+	}
 
 	// 记录开始时间
 	startTime := time.Now()
 
 	// 执行文件内容
-	code := strings.ReplaceAll(string(data), "\t", "    ")
-	baseName := filepath.Base(absPath)
-	l := lexer.NewLexer(baseName, code)
-	p, err2 := parser.NewParser(l)
-	if err2 != nil {
-		printError(err2)
-		return
-	}
-	program := p.ParseProgram()
-	if p.Err != nil {
-		printError(p.Err)
-		return
-	}
-	// 创建解释器环境
-	env := &object.Environment{
-		Store: make(map[string]*object.Symbol),
-		Outer: nil,
-	}
-	// 加载内置函数
-	for name, builtin := range object.Builtins {
-		env.Store[name] = &object.Symbol{
-			Name:    name,
-			Value:   builtin,
-			IsConst: true,
+	if _, err := s.executeSources(sources, scriptArgs); err != nil {
+		if status, ok := requestedExit(err); ok {
+			return status
 		}
+		s.printError(err)
+		return exitCode(err)
 	}
-	f := &frame.Frame{
-		FuncName: baseName,
-		PosStart: nil,
-		PosEnd:   nil,
-		Parent:   nil,
-	}
-	e := evaluator.NewEvaluator(f)
-	e.Eval(program, env)
-	if e.Err != nil {
-		printError(e.Err)
-		return
+
+	if !verbose {
+		return exitOK
 	}
 
 	// 记录结束时间并计算执行时间
@@ -112,17 +212,262 @@ func RunFile(fileName string) {
 	// 根据时间长度决定是否显示组合格式
 	if executionTime.Nanoseconds() == 0 {
 		// 时间为0，直接显示0 ns
-		printInfo("Execution time: 0 ns")
+		s.printInfo("Execution time: 0 ns")
 	} else if executionTime < time.Second {
 		// 毫秒、微秒、纳秒级别，显示秒数和换算单位
-		printInfo(fmt.Sprintf("Execution time: %.9f s (%s)", executionTime.Seconds(), formatDuration(executionTime)))
+		s.printInfo(fmt.Sprintf("Execution time: %.9f s (%s)", executionTime.Seconds(), formatDuration(executionTime)))
 	} else if executionTime < 60*time.Second {
 		// 1-60秒之间，只显示秒数
-		printInfo(fmt.Sprintf("Execution time: %.9f s", executionTime.Seconds()))
+		s.printInfo(fmt.Sprintf("Execution time: %.9f s", executionTime.Seconds()))
 	} else {
 		// 60秒及以上，显示秒数和组合格式
-		printInfo(fmt.Sprintf("Execution time: %.9f s (%s)", executionTime.Seconds(), formatDuration(executionTime)))
+		s.printInfo(fmt.Sprintf("Execution time: %.9f s (%s)", executionTime.Seconds(), formatDuration(executionTime)))
+	}
+	return exitOK
+}
+
+// executeSource 在新的解释器中编译并执行源代码，指定 --cache 标志时使用缓存的语法树
+//
+// 参数:
+//
+//	absPath - 源文件的绝对路径
+//	code - 源代码文本
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	*ghost.Interpreter - 执行使用的解释器，发生词法或语法错误时为nil
+//	error - 词法、语法或运行时错误，脚本调用exit(n)时为*object.ExitError
+func (s *session) executeSource(absPath, code string, scriptArgs []string) (*ghost.Interpreter, error) {
+	return s.executeSources([]scriptSource{{absPath: absPath, code: code}}, scriptArgs)
+}
+
+// executeSources 在同一个新的解释器中依次编译并执行源代码，某个源代码出错时不再执行之后的源代码
+// 每个源代码分别编译，错误位置中显示各自的文件名
+//
+// 参数:
+//
+//	sources - 要执行的源代码，按执行顺序排列
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	*ghost.Interpreter - 执行使用的解释器，第一个源代码发生词法或语法错误时为nil
+//	error - 词法、语法或运行时错误，脚本调用exit(n)时为*object.ExitError
+func (s *session) executeSources(sources []scriptSource, scriptArgs []string) (*ghost.Interpreter, error) {
+	var it *ghost.Interpreter
+	// 之前的源代码在全局环境中声明的名称
+	var globals []string
+	for _, source := range sources {
+		if s.precheck {
+			names, err := precheck(source.absPath, source.code, globals)
+			if err != nil {
+				return it, err
+			}
+			globals = append(globals, names...)
+		}
+		if s.warnings {
+			if err := s.checkWarnings(source.absPath, source.code); err != nil {
+				return it, err
+			}
+		}
+		compile := ghost.Compile
+		// 标准输入没有对应的文件，不写入缓存
+		if s.cache && filepath.Base(source.absPath) != stdinSourceName {
+			compile = ghost.CompileCached
+		}
+		program, err := compile(source.absPath, source.code)
+		if err != nil {
+			// 编译只返回第一个语法错误，重新以恢复模式分析以报告所有语法错误
+			if exitCode(internalError(err)) == exitSyntaxError {
+				if _, errs := loadProgram(source.absPath, source.code); errs != nil {
+					err = errs
+				}
+			}
+			return it, internalError(err)
+		}
+		if it == nil {
+			it = s.newInterpreter(scriptArgs)
+		}
+		if _, err := it.Run(program); err != nil {
+			return it, internalError(err)
+		}
+	}
+	return it, nil
+}
+
+// checkWarnings 对源代码进行静态检查并输出警告，用于 -W 和 -Werror
+// 源代码有词法或语法错误时不检查，由之后的编译报告错误
+//
+// 参数:
+//
+//	absPath - 源文件的绝对路径
+//	code - 源代码文本
+//
+// 返回值:
+//
+//	error - 指定 -Werror 且有警告时为作为错误处理的警告，否则为nil
+func (s *session) checkWarnings(absPath, code string) error {
+	program, err := loadProgram(absPath, code)
+	if err != nil {
+		return nil
+	}
+	warnings := lint.Check(program)
+	if s.werror {
+		return warningErrors(warnings)
+	}
+	for _, warning := range warnings {
+		s.printError(warning)
+	}
+	return nil
+}
+
+// precheck 在执行之前对源代码进行语义检查，用于 --precheck
+// 源代码有词法或语法错误时不检查，由之后的编译报告错误
+//
+// 参数:
+//
+//	absPath - 源文件的绝对路径
+//	code - 源代码文本
+//	globals - 之前执行的源代码在全局环境中声明的名称
+//
+// 返回值:
+//
+//	[]string - 源代码在全局环境中声明的名称
+//	error - 语义检查发现的错误，有多个错误时为syntaxErrors
+func precheck(absPath, code string, globals []string) ([]string, error) {
+	program, err := loadProgram(absPath, code)
+	if err != nil {
+		return nil, nil
+	}
+	info, errs := sema.Check(program, semaOptions(absPath, globals))
+	if errs != nil {
+		return nil, semaErrors(errs)
+	}
+	var names []string
+	for _, sym := range info.Scopes[program].Symbols {
+		names = append(names, sym.Name)
+	}
+	return names, nil
+}
+
+// semaOptions 返回语义检查的选项，导入的模块按执行时的规则查找
+//
+// 参数:
+//
+//	absPath - 源文件的绝对路径
+//	globals - 之前执行的源代码在全局环境中声明的名称
+//
+// 返回值:
+//
+//	sema.Options - 语义检查的选项
+func semaOptions(absPath string, globals []string) sema.Options {
+	return sema.Options{
+		File:    absPath,
+		Globals: globals,
+		Load: func(importStatement *ast.ImportStatement, file string) (string, *ast.Program, bool) {
+			return evaluator.ParseModule(importStatement, file, absPath)
+		},
+	}
+}
+
+// loadProgram 以恢复模式对源代码进行词法和语法分析，得到程序的语法树
+//
+// 参数:
+//
+//	absPath - 源文件的绝对路径
+//	code - 源代码文本
+//
+// 返回值:
+//
+//	*ast.Program - 程序的语法树
+//	error - 词法或语法错误，有多个错误时为syntaxErrors
+func loadProgram(absPath, code string) (*ast.Program, error) {
+	l := lexer.NewLexer(filepath.Base(absPath), code)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		return nil, err
+	}
+	p.Recover = true
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return nil, parseErrors(p)
+	}
+	return program, nil
+}
+
+// parseErrors 返回语法分析器收集的错误
+//
+// 参数:
+//
+//	p - 已完成分析的语法分析器
+//
+// 返回值:
+//
+//	error - 只有一个错误时为该错误，有多个错误时为syntaxErrors，没有错误时为nil
+func parseErrors(p *parser.Parser) error {
+	errs := p.Errors()
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return syntaxErrors(errs)
+	}
+}
+
+// executeProgram 在新的解释器中执行编译后的程序
+//
+// 参数:
+//
+//	program - 编译后的程序
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	*ghost.Interpreter - 执行使用的解释器
+//	error - 运行时错误，脚本调用exit(n)时为*object.ExitError
+func (s *session) executeProgram(program *ghost.Program, scriptArgs []string) (*ghost.Interpreter, error) {
+	it := s.newInterpreter(scriptArgs)
+	_, err := it.Run(program)
+	return it, internalError(err)
+}
+
+// newInterpreter 按命令行标志创建执行脚本的解释器
+//
+// 参数:
+//
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	*ghost.Interpreter - 解释器
+func (s *session) newInterpreter(scriptArgs []string) *ghost.Interpreter {
+	return ghost.New(
+		ghost.WithArgs(scriptArgs...),
+		ghost.WithStrict(s.strict),
+		ghost.WithSandbox(s.sandbox),
+		ghost.WithLimits(ghost.Limits(s.limits)),
+		ghost.WithStdout(s.stdout),
+		ghost.WithStderr(s.stderr),
+	)
+}
+
+// internalError 取出嵌入API包装的解释器内部错误，以便按错误类型渲染诊断信息和确定退出码
+//
+// 参数:
+//
+//	err - 嵌入API返回的错误
+//
+// 返回值:
+//
+//	error - 解释器内部的错误，没有包装时原样返回
+func internalError(err error) error {
+	if inner := errors.Unwrap(err); inner != nil {
+		return inner
 	}
+	return err
 }
 
 // formatDuration 根据时间长短自动选择合适的单位格式化持续时间
@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// runWithStdin 以指定内容作为标准输入执行命令，返回标准输出、标准错误和退出码
+func runWithStdin(t *testing.T, input string, arguments []string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(arguments, strings.NewReader(input), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+// discardSession 创建没有输入并丢弃所有输出的session，用于直接调用命令的测试
+func discardSession() *session {
+	return newSession(strings.NewReader(""), io.Discard, io.Discard)
+}
+
+func TestCLI_RunStdin(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		arguments []string
+		stdout    string
+		stderr    string
+		excepted  int
+	}{
+		{
+			name:      "Piped Without Arguments",
+			input:     "var x = 1;\nprintln(x + 2);\n",
+			arguments: []string{},
+			stdout:    "3\n",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Missing Final Semicolon",
+			input:     "println(1)\n",
+			arguments: []string{"-"},
+			stdout:    "1\n",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Missing Semicolon Before Another Statement",
+			input:     "println(1)\nprintln(2);",
+			arguments: []string{"-"},
+			stderr:    "<stdin>:2:1: Syntax Error[E2001]:",
+			excepted:  exitSyntaxError,
+		},
+		{
+			name:      "Dash With Script Arguments",
+			input:     "println(len(args()));",
+			arguments: []string{"-", "a", "--b"},
+			stdout:    "2\n",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Run Command With Dash",
+			input:     "println(\"run\");",
+			arguments: []string{"run", "-"},
+			stdout:    "run\n",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Exit Builtin",
+			input:     "println(1);\nexit(4);\nprintln(2);",
+			arguments: []string{"-"},
+			stdout:    "1\n",
+			excepted:  4,
+		},
+		{
+			name:      "Runtime Error",
+			input:     "var x = 1 + \"a\";",
+			arguments: []string{},
+			stderr:    "<stdin>:1:9: Operation Error[E3002]: invalid operation \"+\".",
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Syntax Error",
+			input:     "var x = (1 + 2;",
+			arguments: []string{"-"},
+			stderr:    "<stdin>:1:9: Syntax Error[E2003]:",
+			excepted:  exitSyntaxError,
+		},
+		{
+			name:      "Implicit Promotion",
+			input:     "println(1 + 2.5);",
+			arguments: []string{"-"},
+			stdout:    "3.500000\n",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Strict Mode",
+			input:     "println(float(1) + 2.5);\nprintln(1 + 2.5);",
+			arguments: []string{"--strict", "-"},
+			stdout:    "3.500000\n",
+			stderr:    `<stdin>:2:9: Type Error[E3001]: mixed int and float operands for "+" in strict mode`,
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Sandbox Mode",
+			input:     "println(len(\"ab\"));\nexit(3);",
+			arguments: []string{"--sandbox", "-", "a"},
+			stdout:    "2\n",
+			stderr:    "<stdin>:2:1: Sandbox Error[E3014]: exit() is disabled in sandbox.",
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Watch Rejects Dash",
+			input:     "println(1);",
+			arguments: []string{"--watch", "-"},
+			stderr:    "ghost-lang: invalid command line arguments.",
+			excepted:  exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runWithStdin(t, tt.input, tt.arguments)
+			if code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+			if tt.stdout != "" && stdout != tt.stdout {
+				t.Errorf("excepted stdout %q, got %q", tt.stdout, stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("excepted stderr to contain %q, got %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestCLI_RunMultipleFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		args     []string
+		stdout   string
+		skipped  string
+		stderr   string
+		excepted int
+	}{
+		{
+			name: "Definitions Are Shared",
+			files: map[string]string{
+				"a.gh": "var greeting = \"hello \";\nfunc greet(name) { return greeting + name; };",
+				"b.gh": "println(greet(args()[0]));",
+			},
+			args:     []string{"a.gh", "b.gh", "x"},
+			stdout:   "hello x\n",
+			excepted: exitOK,
+		},
+		{
+			name: "Error Position In Second File",
+			files: map[string]string{
+				"a.gh": "func twice(n) { return n * 2; };",
+				"b.gh": "println(twice(2));\nprintln(twice(2) + \"x\");",
+			},
+			args:     []string{"a.gh", "b.gh"},
+			stdout:   "4\n",
+			stderr:   "b.gh:2:9: Operation Error",
+			excepted: exitFailure,
+		},
+		{
+			name: "Error Stops Later Files",
+			files: map[string]string{
+				"a.gh": "println(\"a\");\n1 / 0;",
+				"b.gh": "println(\"b\");",
+			},
+			args:     []string{"a.gh", "b.gh"},
+			stdout:   "a\n",
+			skipped:  "b\n",
+			stderr:   "a.gh:2:1: Math Error",
+			excepted: exitFailure,
+		},
+		{
+			name: "Syntax Error Stops Later Files",
+			files: map[string]string{
+				"a.gh": "println(\"a\");",
+				"b.gh": "var x = (1;",
+				"c.gh": "println(\"c\");",
+			},
+			args:     []string{"a.gh", "b.gh", "c.gh"},
+			stdout:   "a\n",
+			skipped:  "c\n",
+			stderr:   "b.gh:1:9: Syntax Error",
+			excepted: exitSyntaxError,
+		},
+		{
+			name: "Exit Code Of Earlier File",
+			files: map[string]string{
+				"a.gh": "exit(3);",
+				"b.gh": "println(\"b\");",
+			},
+			args:     []string{"a.gh", "b.gh"},
+			skipped:  "b\n",
+			excepted: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			var arguments []string
+			for _, arg := range tt.args {
+				if _, ok := tt.files[arg]; ok {
+					arg = filepath.Join(dir, arg)
+				}
+				arguments = append(arguments, arg)
+			}
+			stdout, stderr, code := runWithStdin(t, "", arguments)
+			if code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+			if !strings.Contains(stdout, tt.stdout) {
+				t.Errorf("excepted stdout to contain %q, got %q", tt.stdout, stdout)
+			}
+			if tt.skipped != "" && strings.Contains(stdout, tt.skipped) {
+				t.Errorf("excepted later files not to run, got %q", stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("excepted stderr to contain %q, got %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestCLI_ResourceLimits(t *testing.T) {
+	// 同一个脚本依次超出每一种限制
+	script := strings.Join([]string{
+		`var s = "ab" * 64;`,
+		"func f(n) { if n == 0 { return 0; }; return f(n - 1); };",
+		"f(100);",
+		"var total = 0;",
+		"for var i = 0; i < 20000; i += 1 { total += i; };",
+		"println(total);",
+	}, "\n")
+	tests := []struct {
+		name      string
+		arguments []string
+		stdout    string
+		stderr    string
+		excepted  int
+	}{
+		{
+			name:      "Unlimited",
+			arguments: []string{"-"},
+			stdout:    "199990000\n",
+			excepted:  exitOK,
+		},
+		{
+			name:      "Max Alloc",
+			arguments: []string{"--max-alloc", "64", "-"},
+			stderr:    "<stdin>:1:9: Alloc Limit Error[E4003]: allocation of 128 bytes exceeds the limit of 64 bytes.",
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Max Depth",
+			arguments: []string{"--max-depth", "10", "-"},
+			stderr:    "<stdin>:2:45: Depth Limit Error[E4002]: maximum call depth of 10 exceeded.",
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Max Steps",
+			arguments: []string{"--max-steps", "5000", "-"},
+			stderr:    "Step Limit Error[E4001]: maximum of 5000 steps exceeded.",
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Timeout",
+			arguments: []string{"--timeout", "1ns", "-"},
+			stderr:    "Timeout Error[E4004]: execution time limit of 1ns exceeded.",
+			excepted:  exitFailure,
+		},
+		{
+			name:      "Negative Timeout",
+			arguments: []string{"--timeout", "-1s", "-"},
+			stderr:    `ghost-lang: invalid value "-1s" for flag -timeout.`,
+			excepted:  exitUsage,
+		},
+		{
+			name:      "Negative Limit",
+			arguments: []string{"--max-steps", "-1", "-"},
+			stderr:    `ghost-lang: invalid value "-1" for flag -max-steps.`,
+			excepted:  exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runWithStdin(t, script, tt.arguments)
+			if code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+			if tt.stdout != "" && stdout != tt.stdout {
+				t.Errorf("excepted stdout %q, got %q", tt.stdout, stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("excepted stderr to contain %q, got %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestCLI_RunInProcess(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "main.gh")
+	writeFile(t, script, "println(\"file\");\nvar x = 1 / 0;")
+	tests := []struct {
+		name      string
+		input     string
+		arguments []string
+		stdout    string
+		stderr    string
+		excepted  int
+	}{
+		{
+			name:      "File",
+			arguments: []string{script},
+			stdout:    "file\n",
+			stderr:    "main.gh:2:9: Math Error[E3003]: division by zero.",
+			excepted:  exitFailure,
+		},
+		{
+			name:      "REPL",
+			input:     "var x = 2;\nx * 3\nx + \"a\"\n",
+			arguments: []string{"repl"},
+			stdout:    "::: 6\n",
+			stderr:    "<stdin>:1:1: Operation Error[E3002]: invalid operation \"+\".",
+			excepted:  exitOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runWithStdin(t, tt.input, tt.arguments)
+			if code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+			if !strings.Contains(stdout, tt.stdout) {
+				t.Errorf("excepted stdout to contain %q, got %q", tt.stdout, stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("excepted stderr to contain %q, got %q", tt.stderr, stderr)
+			}
+			// 写入缓冲区的诊断信息不带颜色
+			if strings.Contains(stderr, "\x1b[") {
+				t.Errorf("excepted no color codes in stderr, got %q", stderr)
+			}
+		})
+	}
+}
+
+func TestCLI_RunConcurrently(t *testing.T) {
+	// 同时执行的命令使用各自的流和标志，严格模式和JSON诊断信息不影响其他调用
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := fmt.Sprintf("println(%d + 0.5);", i)
+			if i%2 == 1 {
+				stdout, stderr, code := runWithStdin(t, input, []string{"--strict", "--diagnostics", "json", "-"})
+				if code != exitFailure || !strings.Contains(stdout, `"code":"E3001"`) || stderr != "" {
+					t.Errorf("run %d: excepted a JSON type error, got exit code %d, stdout %q, stderr %q", i, code, stdout, stderr)
+				}
+				return
+			}
+			excepted := fmt.Sprintf("%d.500000\n", i)
+			stdout, stderr, code := runWithStdin(t, input, []string{"-"})
+			if code != exitOK || stdout != excepted || stderr != "" {
+				t.Errorf("run %d: excepted %q, got exit code %d, stdout %q, stderr %q", i, excepted, code, stdout, stderr)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
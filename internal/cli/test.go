@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/coverage"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+// testFileSuffix 测试文件名的后缀
+const testFileSuffix = "_test.gh"
+
+// testFuncPrefix 测试函数名的前缀
+const testFuncPrefix = "test"
+
+// testResult 单个测试函数或无法加载的测试文件的执行结果
+
+type testResult struct {
+	File     string        // 测试文件路径
+	Name     string        // 测试函数名，测试文件本身无法加载时为空
+	Err      error         // 失败原因，通过时为nil
+	Duration time.Duration // 执行耗时
+}
+
+// RunTests 执行test子命令，运行目录中所有测试文件的测试函数
+// 测试文件为文件名以_test.gh结尾的文件，其中名称以test开头的顶层函数为测试函数
+// 每个测试文件在新的全局环境中执行，测试函数不带参数调用，返回且没有错误即为通过
+// 指定 -cover 或 -coverprofile 时输出测试文件及其导入的模块的行覆盖率
+//
+// 参数:
+//
+//	args - test子命令的参数，包括 -run、-cover、-coverprofile 标志和可选的目录路径
+//
+// 返回值:
+//
+//	int - 进程退出码，有测试失败时为非零
+func (s *session) RunTests(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	run := flags.String("run", "", "Run")
+	cover := flags.Bool("cover", false, "Cover")
+	coverProfile := flags.String("coverprofile", "", "Coverprofile")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		return s.invalidArguments()
+	}
+	pattern, err := regexp.Compile(*run)
+	if err != nil {
+		s.printError(fmt.Sprintf("ghost-lang: invalid -run pattern: \"%s\".", *run))
+		return exitFailure
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+	files, err := findTestFiles(dir)
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	if len(files) == 0 {
+		s.printInfo(fmt.Sprintf("No test files found in \"%s\".", dir))
+		return exitOK
+	}
+
+	var profile *coverage.Profile
+	if *cover || *coverProfile != "" {
+		profile = coverage.NewProfile()
+	}
+	passed, failed := 0, 0
+	for _, file := range files {
+		for _, result := range s.runTestFile(file, pattern, profile) {
+			name := result.File
+			if result.Name != "" {
+				name += ": " + result.Name
+			}
+			if result.Err == nil {
+				passed++
+				fmt.Fprintf(s.stdout, "PASS %s (%s)\n", name, formatDuration(result.Duration))
+				continue
+			}
+			failed++
+			fmt.Fprintf(s.stdout, "FAIL %s (%s)\n", name, formatDuration(result.Duration))
+			// 刷新标准输出缓冲区，保证失败信息紧跟在对应的测试之后
+			syncOutput(s.stdout)
+			s.printError(result.Err)
+		}
+	}
+	s.printInfo(fmt.Sprintf("Tests: %d passed, %d failed, %d total.", passed, failed, passed+failed))
+	if profile != nil {
+		if err := s.reportCoverage(profile, *coverProfile); err != nil {
+			s.printError(err)
+			return exitCode(err)
+		}
+	}
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// findTestFiles 递归查找目录中的测试文件，跳过子目录中的testdata和以.开头的目录
+//
+// 参数:
+//
+//	dir - 要查找的目录
+//
+// 返回值:
+//
+//	[]string - 按路径字典序排列的测试文件
+//	error - 目录无法读取时的错误
+func findTestFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), testFileSuffix) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &fileError{File: dir, Message: fmt.Sprintf("ghost-lang: failed to read directory: \"%s\".", dir)}
+	}
+	return files, nil
+}
+
+// runTestFile 在新的全局环境中执行测试文件，然后按声明顺序调用名称匹配的测试函数
+// 某个测试函数失败不影响之后的测试函数
+//
+// 参数:
+//
+//	fileName - 测试文件路径
+//	pattern - 测试函数名需要匹配的正则表达式
+//	profile - 记录覆盖率的数据，为nil时不记录
+//
+// 返回值:
+//
+//	[]testResult - 每个测试函数的结果，测试文件无法读取、解析或执行时只包含一个文件的结果
+func (s *session) runTestFile(fileName string, pattern *regexp.Regexp, profile *coverage.Profile) []testResult {
+	startTime := time.Now()
+	fileFailed := func(err error) []testResult {
+		return []testResult{{File: fileName, Err: err, Duration: time.Since(startTime)}}
+	}
+	absPath, code, err := readSourceFile(fileName)
+	if err != nil {
+		return fileFailed(err)
+	}
+	program, err := loadProgram(absPath, code)
+	if err != nil {
+		return fileFailed(err)
+	}
+	env := object.NewGlobalEnvironment(nil)
+	e := s.newScriptEvaluator(absPath, env)
+	root := e.Frame
+	if profile != nil {
+		e.Hooks = profile.Hooks(e)
+	}
+	e.Eval(program, env)
+	if e.Err != nil {
+		return fileFailed(testError(e.Err))
+	}
+
+	var results []testResult
+	for _, stat := range program.Statements {
+		decl, ok := stat.(*ast.FunctionDeclarationStatement)
+		if !ok {
+			continue
+		}
+		name, ok := decl.Name.(*ast.IdentifierExpression)
+		if !ok || !strings.HasPrefix(name.Name, testFuncPrefix) || !pattern.MatchString(name.Name) {
+			continue
+		}
+		symbol, ok := env.Get(name.Name)
+		if !ok {
+			continue
+		}
+		// 每个测试函数从文件的顶层调用栈开始执行
+		e.Frame = root
+		e.Err = nil
+		testStart := time.Now()
+		_, err := e.CallFunction(symbol.Value, nil, name.PosStart, name.PosEnd)
+		results = append(results, testResult{
+			File:     fileName,
+			Name:     name.Name,
+			Err:      testError(err),
+			Duration: time.Since(testStart),
+		})
+	}
+	return results
+}
+
+// testError 将测试中调用exit()产生的退出请求转换为测试失败
+//
+// 参数:
+//
+//	err - 执行测试时产生的错误
+//
+// 返回值:
+//
+//	error - 测试失败的原因，没有错误时为nil
+func testError(err error) error {
+	if code, ok := requestedExit(err); ok {
+		return fmt.Errorf("ghost-lang: test called exit(%d).", code)
+	}
+	return err
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+)
+
+// errorSummary 返回错误的类型和信息，不包含源代码摘录
+func errorSummary(err error) string {
+	if d, ok := diagnostic.FromError(err); ok {
+		return d.Kind + ": " + d.Message
+	}
+	return err.Error()
+}
+
+func TestCLI_RunTestFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		pattern  string
+		excepted []string
+	}{
+		{
+			name:    "Pass And Fail",
+			file:    "math_test.gh",
+			pattern: "",
+			excepted: []string{
+				"test_add: PASS",
+				"test_add_negative: PASS",
+				"test_sub: FAIL Assertion Error: sub(3, 1) should be 2.",
+				"test_add_string: FAIL Operation Error: invalid operation \"+\".",
+			},
+		},
+		{
+			name:    "Run Pattern",
+			file:    "math_test.gh",
+			pattern: "add$",
+			excepted: []string{
+				"test_add: PASS",
+			},
+		},
+		{
+			name:     "No Match",
+			file:     "math_test.gh",
+			pattern:  "mul",
+			excepted: nil,
+		},
+		{
+			name:    "Syntax Error",
+			file:    "broken_test.gh",
+			pattern: "",
+			excepted: []string{
+				": FAIL Syntax Error: unclosed \"(\" opened at line 2, column 11.",
+			},
+		},
+		{
+			name:    "Exit",
+			file:    "exit_test.gh",
+			pattern: "",
+			excepted: []string{
+				"test_exit: FAIL ghost-lang: test called exit(0).",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, result := range discardSession().runTestFile(filepath.Join("testdata", "tests", tt.file), regexp.MustCompile(tt.pattern), nil) {
+				status := "PASS"
+				if result.Err != nil {
+					status = "FAIL " + errorSummary(result.Err)
+				}
+				got = append(got, result.Name+": "+status)
+			}
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestCLI_RunTests(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		excepted int
+	}{
+		{
+			name:     "Failures",
+			args:     []string{"testdata/tests"},
+			excepted: exitFailure,
+		},
+		{
+			name:     "All Passed",
+			args:     []string{"-run", "^test_add(_negative)?$", "testdata/tests/math_test.gh"},
+			excepted: exitOK,
+		},
+		{
+			name:     "Invalid Pattern",
+			args:     []string{"-run", "(", "testdata/tests"},
+			excepted: exitFailure,
+		},
+		{
+			name:     "Missing Directory",
+			args:     []string{"testdata/missing"},
+			excepted: exitFileError,
+		},
+		{
+			name:     "Too Many Arguments",
+			args:     []string{"a", "b"},
+			excepted: exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := discardSession().RunTests(tt.args); code != tt.excepted {
+				t.Errorf("excepted exit code %d, got %d", tt.excepted, code)
+			}
+		})
+	}
+}
+
+func TestCLI_FindTestFiles(t *testing.T) {
+	files, err := findTestFiles(filepath.Join("testdata", "tests"))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	excepted := []string{
+		filepath.Join("testdata", "tests", "broken_test.gh"),
+		filepath.Join("testdata", "tests", "exit_test.gh"),
+		filepath.Join("testdata", "tests", "math_test.gh"),
+	}
+	if !reflect.DeepEqual(files, excepted) {
+		t.Errorf("excepted %v, got %v", excepted, files)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+)
+
+// DumpTokens 对指定的.gh文件进行词法分析并逐行输出令牌流，不执行程序
+// 遇到非法令牌时输出错误并返回非零退出码
+//
+// 参数:
+//
+//	fileName - 要分析的文件路径
+//
+// 返回值:
+//
+//	int - 进程退出码
+func (s *session) DumpTokens(fileName string) int {
+	tokens, err := dumpTokens(fileName)
+	fmt.Fprint(s.stdout, tokens)
+	// 刷新标准输出缓冲区
+	syncOutput(s.stdout)
+	if err != nil {
+		s.printError(err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// dumpTokens 对指定的.gh文件进行词法分析并返回令牌流的文本表示
+// 每个令牌一行，格式为：<类型> <带引号的字面量> <起始行:列>-<结束行:列>
+//
+// 参数:
+//
+//	fileName - 要分析的文件路径
+//
+// 返回值:
+//
+//	string - 遇到EOF或第一个错误之前的令牌流
+//	error - 读取文件或词法分析过程中的错误
+func dumpTokens(fileName string) (string, error) {
+	absPath, code, err := readSourceFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	l := lexer.NewLexer(filepath.Base(absPath), code)
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			return sb.String(), err
+		}
+		sb.WriteString(fmt.Sprintf("%-18s %q %d:%d-%d:%d\n", tok.Type, tok.Literal,
+			tok.PosStart.Row, tok.PosStart.Col, tok.PosEnd.Row, tok.PosEnd.Col))
+		if tok.Type == lexer.EOF {
+			return sb.String(), nil
+		}
+		l.NextChar()
+	}
+}
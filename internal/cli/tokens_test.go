@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCLI_DumpTokens(t *testing.T) {
+	got, err := dumpTokens("testdata/tokens.gh")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	expected, err := os.ReadFile("testdata/tokens.golden")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %+v", err)
+	}
+	if got != string(expected) {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCLI_DumpTokens_IllegalToken(t *testing.T) {
+	got, err := dumpTokens("testdata/tokens_error.gh")
+	if err == nil {
+		t.Fatalf("err = nil, expected error")
+	}
+	expected := "" +
+		"VAR                \"var\" 1:1-1:4\n" +
+		"IDENT              \"x\" 1:5-1:6\n" +
+		"EQUAL              \"=\" 1:7-1:8\n" +
+		"INT                \"1\" 1:9-1:10\n" +
+		"SEMICOLON          \";\" 1:10-1:11\n" +
+		"VAR                \"var\" 2:1-2:4\n" +
+		"IDENT              \"y\" 2:5-2:6\n" +
+		"EQUAL              \"=\" 2:7-2:8\n"
+	if got != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
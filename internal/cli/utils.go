@@ -1,19 +1,222 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lint"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
 )
 
-// printError 打印带红色高亮的错误信息并刷新标准输出缓冲区
+// fileError 源文件无法读取的错误，如扩展名非法、文件不存在或路径无法解析
+
+type fileError struct {
+	File    string // 文件路径
+	Message string // 错误信息
+}
+
+// Error 返回错误信息
+//
+// 返回值:
+//
+//	string - 错误信息
+func (e *fileError) Error() string {
+	return e.Message
+}
+
+// syntaxErrors 恢复模式下的语法分析收集的多个词法和语法错误、语义检查发现的多个错误，或指定 -Werror 时作为错误处理的多个警告，按出现顺序排列
+
+type syntaxErrors []error
+
+// Error 返回第一个错误的错误信息
+//
+// 返回值:
+//
+//	string - 错误信息
+func (e syntaxErrors) Error() string {
+	return e[0].Error()
+}
+
+// Unwrap 返回所有错误
+//
+// 返回值:
+//
+//	[]error - 收集的错误
+func (e syntaxErrors) Unwrap() []error {
+	return e
+}
+
+// 从标准输入读取脚本时使用的名称
+
+const (
+	stdinFileName   = "-"       // 表示从标准输入读取脚本的文件参数
+	stdinSourceName = "<stdin>" // 标准输入作为源代码时的源名称
+)
+
+// defaultMaxErrors 默认最多输出的语法错误数
+const defaultMaxErrors = 20
+
+// session 一次命令调用的输入输出流和全局标志确定的执行设置
+// 命令和输出函数都是它的方法，不依赖包级变量，Run可以在多个goroutine中同时调用
+
+type session struct {
+	stdin       io.Reader           // 标准输入，从中读取REPL、调试器和"-"脚本的输入
+	stdout      io.Writer           // 标准输出，脚本、REPL和各命令的输出
+	stderr      io.Writer           // 标准错误，错误信息和帮助信息的输出
+	diagnostics string              // 错误的输出格式，"text"为带源代码摘录的文本，"json"为JSON数组
+	strict      bool                // 是否以严格数值模式执行脚本，整数和浮点数混合运算时产生类型错误
+	sandbox     bool                // 是否以沙箱模式执行脚本，访问宿主系统的内置函数被禁用，只能导入标准库模块
+	cache       bool                // 是否将脚本的语法树缓存到<script>.ghostc文件中，源代码未修改时跳过词法和语法分析
+	warnings    bool                // 执行脚本之前是否进行静态检查并输出警告
+	werror      bool                // 是否将静态检查的警告作为错误处理，有警告时不执行脚本
+	precheck    bool                // 执行脚本之前是否进行语义检查，有错误时不执行脚本
+	goStack     bool                // 解释器内部错误时是否在错误信息之后输出Go调用栈，用于报告解释器的缺陷
+	maxErrors   int                 // 最多输出的语法错误数，超出的错误只输出数量，0表示不限制
+	limits      evaluator.Limits    // 执行脚本时的资源限制，默认不限制
+	pending     []diagnostic.Record // JSON模式下记录的诊断信息，在命令结束时统一输出
+}
+
+// newSession 创建使用给定输入输出流和默认设置的session
 //
 // 参数:
 //
-//	message - 错误文本内容
-func printError(message any) {
-	fmt.Printf("\033[31m%s\033[0m\n", message)
-	// 刷新标准输出缓冲区
-	_ = os.Stdout.Sync()
+//	in - 标准输入
+//	out - 标准输出
+//	errOut - 标准错误
+//
+// 返回值:
+//
+//	*session - 新的session
+func newSession(in io.Reader, out, errOut io.Writer) *session {
+	return &session{stdin: in, stdout: out, stderr: errOut, diagnostics: "text", maxErrors: defaultMaxErrors}
+}
+
+// flushDiagnostics 将记录的诊断信息以JSON数组的形式输出并清空记录
+//
+// 参数:
+//
+//	w - 输出位置
+func (s *session) flushDiagnostics(w io.Writer) {
+	_ = diagnostic.WriteJSON(w, s.pending)
+	s.pending = nil
+}
+
+// printError 打印错误信息到标准错误并刷新缓冲区
+// 带位置信息的错误会附带源代码摘录，标准错误为终端且未设置NO_COLOR时使用彩色输出
+// 诊断信息格式为JSON时只记录错误，由flushDiagnostics统一输出
+//
+// 参数:
+//
+//	message - 错误或错误文本内容
+func (s *session) printError(message any) {
+	err, ok := message.(error)
+	if !ok {
+		err = errors.New(fmt.Sprint(message))
+	}
+	var errs syntaxErrors
+	if errors.As(err, &errs) {
+		s.printErrors(errs)
+		return
+	}
+	// JSON模式下不输出文本，在命令结束时统一输出
+	if s.diagnostics == "json" {
+		record := diagnostic.NewRecord(err)
+		var fileErr *fileError
+		if errors.As(err, &fileErr) {
+			record.File = fileErr.File
+		}
+		s.pending = append(s.pending, record)
+		return
+	}
+	_, _ = fmt.Fprint(s.stderr, diagnostic.Render(err, diagnostic.ColorEnabled(s.stderr)))
+	var internalErr *evaluator.InternalError
+	if errors.As(err, &internalErr) {
+		if s.goStack && internalErr.Stack != "" {
+			_, _ = fmt.Fprintf(s.stderr, "Go stack:\n%s", internalErr.Stack)
+		} else {
+			_, _ = fmt.Fprintln(s.stderr, "run with --debug to print the Go stack.")
+		}
+	}
+	// 刷新标准错误缓冲区
+	syncOutput(s.stderr)
+}
+
+// warningErrors 将静态检查的警告转换为错误，用于 -Werror
+//
+// 参数:
+//
+//	warnings - 静态检查的警告
+//
+// 返回值:
+//
+//	error - 只有一个警告时为该警告，有多个警告时为syntaxErrors，没有警告时为nil
+func warningErrors(warnings []*lint.Warning) error {
+	switch len(warnings) {
+	case 0:
+		return nil
+	case 1:
+		return warnings[0]
+	default:
+		errs := make(syntaxErrors, 0, len(warnings))
+		for _, warning := range warnings {
+			errs = append(errs, warning)
+		}
+		return errs
+	}
+}
+
+// semaErrors 将语义检查发现的错误合并为一个错误
+//
+// 参数:
+//
+//	errs - 语义检查发现的错误
+//
+// 返回值:
+//
+//	error - 只有一个错误时为该错误，有多个错误时为syntaxErrors，没有错误时为nil
+func semaErrors(errs []*sema.Error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		merged := make(syntaxErrors, 0, len(errs))
+		for _, err := range errs {
+			merged = append(merged, err)
+		}
+		return merged
+	}
+}
+
+// printErrors 按源代码顺序打印多个错误，文本格式下最多输出maxErrors个，最后输出错误总数
+// 诊断信息格式为JSON时记录所有错误
+//
+// 参数:
+//
+//	errs - 要打印的错误
+func (s *session) printErrors(errs []error) {
+	if s.diagnostics == "json" {
+		sorted := append([]error(nil), errs...)
+		diagnostic.SortErrors(sorted)
+		for _, err := range sorted {
+			s.printError(err)
+		}
+		return
+	}
+	_, _ = fmt.Fprint(s.stderr, diagnostic.RenderList(errs, s.maxErrors, diagnostic.ColorEnabled(s.stderr)))
+	// 刷新标准错误缓冲区
+	syncOutput(s.stderr)
 }
 
 // printInfo 打印带蓝色高亮的信息文本并刷新标准输出缓冲区
@@ -21,8 +224,153 @@ func printError(message any) {
 // 参数:
 //
 //	message - 信息文本内容
-func printInfo(message string) {
-	fmt.Printf("\033[34m%s\033[0m\n", message)
+func (s *session) printInfo(message string) {
+	fmt.Fprintf(s.stdout, "\033[34m%s\033[0m\n", message)
 	// 刷新标准输出缓冲区
-	_ = os.Stdout.Sync()
+	syncOutput(s.stdout)
+}
+
+// syncOutput 输出流为文件时刷新其缓冲区，其他类型的输出流不需要刷新
+//
+// 参数:
+//
+//	w - 输出流
+func syncOutput(w io.Writer) {
+	if file, ok := w.(*os.File); ok {
+		_ = file.Sync()
+	}
+}
+
+// readSourceFile 读取.gh源文件，返回绝对路径和规范化后的源代码
+//
+// 参数:
+//
+//	fileName - 要读取的文件路径
+//
+// 返回值:
+//
+//	string - 文件的绝对路径
+//	string - 源代码文本（制表符已替换为4个空格）
+//	error - 文件扩展名非法、文件不存在或路径无法解析时的错误
+func readSourceFile(fileName string) (string, string, error) {
+	absPath, code, err := readRawSourceFile(fileName)
+	if err != nil {
+		return "", "", err
+	}
+	return absPath, strings.ReplaceAll(code, "\t", "    "), nil
+}
+
+// readRawSourceFile 读取.gh源文件，返回绝对路径和未经修改的源代码
+//
+// 参数:
+//
+//	fileName - 要读取的文件路径
+//
+// 返回值:
+//
+//	string - 文件的绝对路径
+//	string - 源代码文本
+//	error - 文件扩展名非法、文件不存在或路径无法解析时的错误
+func readRawSourceFile(fileName string) (string, string, error) {
+	// 验证文件扩展名
+	slice := strings.Split(fileName, ".")
+	if (len(slice) > 1 && slice[len(slice)-1] != "gh") || len(slice) <= 1 {
+		return "", "", &fileError{File: fileName, Message: fmt.Sprintf("ghost-lang: invalid file extension: \"%s\".", fileName)}
+	}
+	// 读取文件内容
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", "", &fileError{File: fileName, Message: fmt.Sprintf("ghost-lang: file not found: \"%s\".", fileName)}
+	}
+	// 获取绝对路径
+	absPath, err := filepath.Abs(fileName)
+	if err != nil {
+		return "", "", &fileError{File: fileName, Message: fmt.Sprintf("ghost-lang: failed to resolve absolute path: \"%s\".", fileName)}
+	}
+	return absPath, string(data), nil
+}
+
+// readStdin 读取标准输入的全部内容作为源代码
+// 返回的路径为当前工作目录下名为<stdin>的虚拟文件，使相对导入路径基于当前工作目录解析
+// 与eval相同，源代码只缺少最后一条语句的分号时自动补上，使echo 'println(1)' | ghost -可以直接执行
+//
+// 返回值:
+//
+//	string - 虚拟文件的绝对路径
+//	string - 源代码文本（制表符已替换为4个空格）
+//	error - 标准输入或当前工作目录无法读取时的错误
+func (s *session) readStdin() (string, string, error) {
+	data, err := io.ReadAll(s.stdin)
+	if err != nil {
+		return "", "", &fileError{File: stdinSourceName, Message: "ghost-lang: failed to read standard input."}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", "", &fileError{File: stdinSourceName, Message: "ghost-lang: failed to resolve working directory."}
+	}
+	return filepath.Join(wd, stdinSourceName), completeStatement(strings.ReplaceAll(string(data), "\t", "    ")), nil
+}
+
+// completeStatement 源代码在文件结尾处只缺少分号时返回补上分号的源代码，否则原样返回
+//
+// 参数:
+//
+//	code - 源代码文本
+//
+// 返回值:
+//
+//	string - 补全后的源代码文本
+func completeStatement(code string) string {
+	p, err := parser.NewParser(lexer.NewLexer(stdinSourceName, code))
+	if err != nil {
+		return code
+	}
+	p.ParseProgram()
+	var syntaxError *parser.SyntaxError
+	if errors.As(p.Err, &syntaxError) && syntaxError.EOF && syntaxError.Expected == lexer.SEMICOLON {
+		return code + ";"
+	}
+	return code
+}
+
+// stdinIsTerminal 判断标准输入是否为终端
+//
+// 返回值:
+//
+//	bool - 标准输入为终端时为true，为管道或重定向的文件时为false
+func (s *session) stdinIsTerminal() bool {
+	file, ok := s.stdin.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return true
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// newScriptEvaluator 按全局选项创建执行脚本文件的解释器，根栈帧以文件名命名
+// 沙箱模式下同时禁用全局环境中带有能力标记的内置函数
+//
+// 参数:
+//
+//	absPath - 脚本的绝对路径
+//	env - 脚本的全局环境
+//
+// 返回值:
+//
+//	*evaluator.Evaluator - 输出到标准输出和标准错误的解释器
+func (s *session) newScriptEvaluator(absPath string, env *object.Environment) *evaluator.Evaluator {
+	e := evaluator.NewEvaluator(&frame.Frame{FuncName: filepath.Base(absPath)})
+	e.File = absPath
+	e.Strict = s.strict
+	e.Stdout = s.stdout
+	e.Stderr = s.stderr
+	e.Sandbox = s.sandbox
+	e.Limits = s.limits
+	if s.sandbox {
+		env.Sandbox()
+	}
+	return e
 }
@@ -1,14 +1,21 @@
 package cli
 
-import "fmt"
+import (
+	"fmt"
+	"runtime"
+)
+
+// 构建信息，通过编译参数 -ldflags "-X github.com/Ghost-Xiao/ghost-lang/internal/cli.Version=..." 注入
+// 未注入时使用开发版本的默认值
 
 var (
-	Version   string // 版本号，通过编译参数注入
-	BuildTime string // 构建时间，通过编译参数注入
-	Platform  string // 目标平台，通过编译参数注入
-	Arch      string // 目标架构，通过编译参数注入
+	Version   = "dev"          // 版本号，通过编译参数注入
+	BuildTime = "unknown"      // 构建时间，通过编译参数注入
+	Platform  = runtime.GOOS   // 目标平台，通过编译参数注入
+	Arch      = runtime.GOARCH // 目标架构，通过编译参数注入
 )
 
-func PrintVersion() {
-	printInfo(fmt.Sprintf("ghost-lang: ghost %s.", Version))
+// PrintVersion 显示版本号、目标平台和构建时间
+func (s *session) PrintVersion() {
+	s.printInfo(fmt.Sprintf("ghost-lang: ghost %s (%s/%s, built %s).", Version, Platform, Arch, BuildTime))
 }
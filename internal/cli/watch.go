@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// 监视模式的默认轮询参数
+
+const (
+	watchInterval = 200 * time.Millisecond // 轮询文件状态的间隔
+	watchDebounce = 300 * time.Millisecond // 文件在此时间内不再变化时才重新执行，避免连续保存触发多次执行
+)
+
+// fileStamp 文件状态快照，用于判断文件是否被修改
+
+type fileStamp struct {
+	exists  bool      // 文件是否存在
+	size    int64     // 文件大小
+	modTime time.Time // 最后修改时间
+}
+
+// statFile 获取文件的状态快照
+//
+// 参数:
+//
+//	path - 文件路径
+//
+// 返回值:
+//
+//	fileStamp - 文件状态快照，文件不存在或无法访问时exists为false
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// watcher 通过轮询文件状态检测修改的监视器
+
+type watcher struct {
+	interval time.Duration        // 轮询间隔
+	debounce time.Duration        // 防抖时间
+	stamps   map[string]fileStamp // 监视的文件及其最近一次的状态
+}
+
+// newWatcher 创建文件监视器
+//
+// 参数:
+//
+//	interval - 轮询间隔
+//	debounce - 防抖时间，检测到修改后文件在此时间内不再变化才视为修改完成
+//
+// 返回值:
+//
+//	*watcher - 文件监视器
+func newWatcher(interval, debounce time.Duration) *watcher {
+	return &watcher{
+		interval: interval,
+		debounce: debounce,
+		stamps:   make(map[string]fileStamp),
+	}
+}
+
+// watch 记录要监视的文件的当前状态，替换之前监视的文件
+//
+// 参数:
+//
+//	files - 要监视的文件路径
+func (w *watcher) watch(files []string) {
+	w.stamps = make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		w.stamps[file] = statFile(file)
+	}
+}
+
+// changed 检查监视的文件自上次检查以来是否被修改、创建或删除，并更新记录的状态
+//
+// 返回值:
+//
+//	bool - 是否有文件发生变化
+func (w *watcher) changed() bool {
+	changed := false
+	for file, stamp := range w.stamps {
+		current := statFile(file)
+		if current != stamp {
+			w.stamps[file] = current
+			changed = true
+		}
+	}
+	return changed
+}
+
+// wait 阻塞直到监视的文件被修改且在防抖时间内不再变化，或上下文被取消
+//
+// 参数:
+//
+//	ctx - 上下文，取消时立即返回
+//
+// 返回值:
+//
+//	bool - 文件被修改时为true，上下文被取消时为false
+func (w *watcher) wait(ctx context.Context) bool {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	// 最近一次检测到修改的时间，零值表示尚未检测到修改
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case now := <-ticker.C:
+			if w.changed() {
+				last = now
+			} else if !last.IsZero() && now.Sub(last) >= w.debounce {
+				return true
+			}
+		}
+	}
+}
+
+// WatchFile 执行指定的.gh文件，并在该文件或其导入的模块被修改后清屏重新执行
+// 无论执行成功还是出错都会继续监视，按Ctrl+C退出
+//
+// 参数:
+//
+//	fileName - 要执行的文件路径
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	int - 进程退出码，正常退出监视时为0
+func (s *session) WatchFile(fileName string, scriptArgs []string) int {
+	// 捕获中断信号 (Ctrl+C)，通过取消上下文结束监视
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	w := newWatcher(watchInterval, watchDebounce)
+	return s.watchLoop(ctx, w, func() []string {
+		// 清屏并输出带时间戳的标题
+		fmt.Fprint(s.stdout, "\033[H\033[2J")
+		s.printInfo(fmt.Sprintf("[%s] Running file \"%s\".", time.Now().Format("15:04:05"), fileName))
+		files := s.runWatched(fileName, scriptArgs)
+		s.printInfo("Watching for changes, press Ctrl+C to exit.")
+		return files
+	})
+}
+
+// watchLoop 反复执行run，并等待其返回的文件被修改后再次执行，直到上下文被取消
+//
+// 参数:
+//
+//	ctx - 上下文，取消时结束监视
+//	w - 文件监视器
+//	run - 执行一次脚本，返回需要监视的文件
+//
+// 返回值:
+//
+//	int - 进程退出码，上下文被取消时为0
+func (s *session) watchLoop(ctx context.Context, w *watcher, run func() []string) int {
+	for {
+		done := make(chan []string, 1)
+		go func() {
+			done <- run()
+		}()
+		select {
+		case <-ctx.Done():
+			// 正在执行的脚本无法中断，随进程一起退出
+			s.printInfo("\nWatch stopped by user.")
+			return exitOK
+		case files := <-done:
+			w.watch(files)
+		}
+		if !w.wait(ctx) {
+			s.printInfo("\nWatch stopped by user.")
+			return exitOK
+		}
+	}
+}
+
+// runWatched 执行一次脚本并输出错误，返回需要监视的文件
+//
+// 参数:
+//
+//	fileName - 要执行的文件路径
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	[]string - 脚本文件和执行过程中读取过的模块文件的路径
+func (s *session) runWatched(fileName string, scriptArgs []string) []string {
+	files := []string{fileName}
+	if absPath, err := filepath.Abs(fileName); err == nil {
+		files[0] = absPath
+	}
+	absPath, code, err := readSourceFile(fileName)
+	if err != nil {
+		s.printError(err)
+		return files
+	}
+	e, err := s.executeSource(absPath, code, scriptArgs)
+	if e != nil {
+		files = append(files, e.Sources()...)
+	}
+	if err != nil {
+		if status, ok := requestedExit(err); ok {
+			s.printInfo(fmt.Sprintf("Script exited with code %d.", status))
+		} else {
+			s.printError(err)
+		}
+	}
+	return files
+}
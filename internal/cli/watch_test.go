@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeFile 写入测试文件，失败时终止测试
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %+v", path, err)
+	}
+}
+
+func TestCLI_WatcherDebounce(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.gh")
+	writeFile(t, file, "1;")
+	w := newWatcher(5*time.Millisecond, 50*time.Millisecond)
+	w.watch([]string{file})
+
+	// 连续多次保存只应触发一次
+	go func() {
+		for _, content := range []string{"12;", "123;", "1234;"} {
+			time.Sleep(10 * time.Millisecond)
+			writeFile(t, file, content)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if !w.wait(ctx) {
+		t.Fatalf("excepted change to be detected")
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("excepted wait to settle after the last save, returned after %v", elapsed)
+	}
+	if w.changed() {
+		t.Errorf("excepted no pending change after wait returned")
+	}
+}
+
+func TestCLI_WatcherCancel(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.gh")
+	writeFile(t, file, "1;")
+	w := newWatcher(5*time.Millisecond, 10*time.Millisecond)
+	w.watch([]string{file})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if w.wait(ctx) {
+		t.Errorf("excepted wait to return false after cancellation")
+	}
+}
+
+func TestCLI_RunWatched(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.gh")
+	lib := filepath.Join(dir, "lib.gh")
+	broken := filepath.Join(dir, "broken.gh")
+	writeFile(t, lib, "var x = 1;")
+	writeFile(t, broken, "var y = (1;")
+
+	tests := []struct {
+		name     string
+		source   string
+		excepted []string
+	}{
+		{
+			name:     "Imported Module",
+			source:   "import \"lib.gh\";\nx;",
+			excepted: []string{main, lib},
+		},
+		{
+			name:     "Runtime Error",
+			source:   "import \"lib.gh\";\nx + \"a\";",
+			excepted: []string{main, lib},
+		},
+		{
+			name:     "Module Syntax Error",
+			source:   "import \"broken.gh\";",
+			excepted: []string{main, broken},
+		},
+		{
+			name:     "Syntax Error",
+			source:   "import \"lib.gh\"",
+			excepted: []string{main},
+		},
+		{
+			name:     "Exit",
+			source:   "import \"lib.gh\";\nexit(3);",
+			excepted: []string{main, lib},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, main, tt.source)
+			got := discardSession().runWatched(main, nil)
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %v, got %v", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestCLI_WatchLoop(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.gh")
+	lib := filepath.Join(dir, "lib.gh")
+	writeFile(t, main, "import \"lib.gh\";")
+	writeFile(t, lib, "var x = 1;")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan int, 10)
+	count := 0
+	w := newWatcher(5*time.Millisecond, 20*time.Millisecond)
+	result := make(chan int, 1)
+	s := discardSession()
+	go func() {
+		result <- s.watchLoop(ctx, w, func() []string {
+			files := s.runWatched(main, nil)
+			count++
+			runs <- count
+			return files
+		})
+	}()
+
+	// 等待执行完成后修改导入的模块，应触发重新执行
+	waitRun := func(excepted int) {
+		t.Helper()
+		select {
+		case got := <-runs:
+			if got != excepted {
+				t.Fatalf("excepted run %d, got %d", excepted, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for run %d", excepted)
+		}
+	}
+	waitRun(1)
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, lib, "var x = 2; var y = 3;")
+	waitRun(2)
+	// 出错后继续监视
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, main, "import \"lib.gh\"; x + \"a\";")
+	waitRun(3)
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	select {
+	case code := <-result:
+		if code != exitOK {
+			t.Errorf("excepted exit code %d, got %d", exitOK, code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for watch loop to stop")
+	}
+}
@@ -0,0 +1,368 @@
+// Package coverage 通过解释器的回调记录脚本执行过的源代码行，生成行覆盖率报告
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// Profile 按源文件记录语句所在的行是否执行过的行覆盖率数据
+// 可执行行由语法树中语句的起始行确定，只有花括号或注释的行不计入
+
+type Profile struct {
+	files      map[string]*File        // 按文件路径索引的覆盖率数据
+	statements map[ast.Statement]*File // 语句到所属文件的映射，同一文件多次加载时每次的语句都会加入
+}
+
+// File 单个源文件的行覆盖率数据
+
+type File struct {
+	Name       string       // 报告中显示的文件名
+	Lines      []string     // 源代码的各行
+	executable map[int]bool // 可执行行的行号集合
+	executed   map[int]bool // 执行过的行号集合
+}
+
+// NewProfile 创建空的覆盖率数据
+//
+// 返回值:
+//
+//	*Profile - 不包含任何文件的覆盖率数据
+func NewProfile() *Profile {
+	return &Profile{
+		files:      make(map[string]*File),
+		statements: make(map[ast.Statement]*File),
+	}
+}
+
+// Add 加入源文件的语法树，从中确定可执行行
+// 同一路径再次加入时沿用已有的数据，只记录新语法树中的语句
+//
+// 参数:
+//
+//	path - 源文件路径，用作文件的标识
+//	name - 报告中显示的文件名
+//	program - 源文件的语法树
+func (p *Profile) Add(path, name string, program *ast.Program) {
+	if len(program.Statements) == 0 {
+		return
+	}
+	file, ok := p.files[path]
+	if !ok {
+		posStart, _ := ast.Span(program.Statements[0])
+		file = &File{
+			Name:       name,
+			Lines:      strings.Split(strings.TrimSuffix(posStart.Text(), "\n"), "\n"),
+			executable: make(map[int]bool),
+			executed:   make(map[int]bool),
+		}
+		p.files[path] = file
+	}
+	for _, statement := range program.Statements {
+		p.addStatement(file, statement)
+	}
+}
+
+// Hit 将语句的起始行标记为已执行，不属于已加入文件的语句被忽略
+//
+// 参数:
+//
+//	statement - 执行的语句
+func (p *Profile) Hit(statement ast.Statement) {
+	file, ok := p.statements[statement]
+	if !ok {
+		return
+	}
+	posStart, _ := ast.Span(statement)
+	file.executed[posStart.Row] = true
+}
+
+// Hooks 返回记录覆盖率的解释器回调
+// 解释器开始执行入口脚本或导入的模块时加入其语法树，eval执行的代码和标准库模块不计入
+//
+// 参数:
+//
+//	e - 使用回调的解释器，用于获取正在加载的文件路径
+//
+// 返回值:
+//
+//	evaluator.Hooks - 解释器回调
+func (p *Profile) Hooks(e *evaluator.Evaluator) evaluator.Hooks {
+	return evaluator.Hooks{
+		OnEnterNode: func(node ast.Node, _, _ *util.Pos) {
+			program, ok := node.(*ast.Program)
+			if !ok || len(program.Statements) == 0 || evaluator.IsStdlibModule(e.File) {
+				return
+			}
+			// eval执行的程序同样经过Eval，其源名称与当前文件不同
+			if posStart, _ := ast.Span(program.Statements[0]); posStart.File() != filepath.Base(e.File) {
+				return
+			}
+			p.Add(e.File, displayName(e.File), program)
+		},
+		OnStatement: func(statement ast.Statement, _ *object.Environment) error {
+			p.Hit(statement)
+			return nil
+		},
+	}
+}
+
+// Files 返回按文件名排列的所有文件的覆盖率数据
+//
+// 返回值:
+//
+//	[]*File - 文件的覆盖率数据
+func (p *Profile) Files() []*File {
+	files := make([]*File, 0, len(p.files))
+	for _, file := range p.files {
+		files = append(files, file)
+	}
+	slices.SortFunc(files, func(a, b *File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return files
+}
+
+// WriteListing 输出所有文件的带注释源代码清单
+// 每个文件先输出一行摘要，然后逐行输出源代码，执行过的可执行行标记为+，未执行的标记为-
+//
+// 参数:
+//
+//	w - 输出位置
+//
+// 返回值:
+//
+//	error - 写入失败时的错误
+func (p *Profile) WriteListing(w io.Writer) error {
+	for i, file := range p.Files() {
+		var sb strings.Builder
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(file.Summary() + "\n")
+		for j, line := range file.Lines {
+			row := j + 1
+			mark := " "
+			if file.executable[row] {
+				mark = "-"
+				if file.executed[row] {
+					mark = "+"
+				}
+			}
+			sb.WriteString(strings.TrimRight(fmt.Sprintf("%s %4d | %s", mark, row, line), " ") + "\n")
+		}
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summary 返回文件覆盖率的摘要
+//
+// 返回值:
+//
+//	string - 格式为"<文件名>: <百分比> of lines covered (<执行过的行数>/<可执行行数>)"
+func (f *File) Summary() string {
+	return fmt.Sprintf("%s: %.1f%% of lines covered (%d/%d)", f.Name, f.Percent(), f.Covered(), f.Total())
+}
+
+// Total 返回可执行行的数量
+//
+// 返回值:
+//
+//	int - 可执行行的数量
+func (f *File) Total() int {
+	return len(f.executable)
+}
+
+// Covered 返回执行过的可执行行的数量
+//
+// 返回值:
+//
+//	int - 执行过的可执行行的数量
+func (f *File) Covered() int {
+	return len(f.executed)
+}
+
+// Percent 返回执行过的可执行行所占的百分比
+//
+// 返回值:
+//
+//	float64 - 百分比，没有可执行行时为100
+func (f *File) Percent() float64 {
+	if f.Total() == 0 {
+		return 100
+	}
+	return float64(f.Covered()) * 100 / float64(f.Total())
+}
+
+// Missed 返回未执行的可执行行
+//
+// 返回值:
+//
+//	[]int - 按顺序排列的行号
+func (f *File) Missed() []int {
+	var rows []int
+	for row := range f.executable {
+		if !f.executed[row] {
+			rows = append(rows, row)
+		}
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+// addStatement 记录语句及其中嵌套的语句，语句的起始行为可执行行
+// 函数体、循环体等块本身不是可执行行，只记录块中的语句
+//
+// 参数:
+//
+//	file - 语句所属的文件
+//	statement - 语句
+func (p *Profile) addStatement(file *File, statement ast.Statement) {
+	if statement == nil {
+		return
+	}
+	if s, ok := statement.(*ast.ExpressionStatement); ok {
+		if block, ok := s.Expr.(*ast.BlockExpression); ok {
+			for _, inner := range block.Statements {
+				p.addStatement(file, inner)
+			}
+			return
+		}
+	}
+	if posStart, _ := ast.Span(statement); posStart != nil {
+		file.executable[posStart.Row] = true
+		p.statements[statement] = file
+	}
+	switch s := statement.(type) {
+	case *ast.ExpressionStatement:
+		p.addExpression(file, s.Expr)
+	case *ast.ReturnStatement:
+		p.addExpression(file, s.ReturnValue)
+	case *ast.ForStatement:
+		p.addStatement(file, s.Initialization)
+		p.addExpression(file, s.Condition)
+		p.addStatement(file, s.Update)
+		p.addStatement(file, s.Body)
+	case *ast.ForInStatement:
+		p.addExpression(file, s.Iterable)
+		p.addStatement(file, s.Body)
+	case *ast.WhileStatement:
+		p.addExpression(file, s.Condition)
+		p.addStatement(file, s.Body)
+	case *ast.TryStatement:
+		p.addStatement(file, s.Body)
+		p.addStatement(file, s.Catch.Body)
+	case *ast.ThrowStatement:
+		p.addExpression(file, s.Value)
+	case *ast.FunctionDeclarationStatement:
+		for _, param := range s.Parameter {
+			p.addExpression(file, param.DefaultValue)
+		}
+		p.addStatement(file, s.Body)
+	}
+}
+
+// addExpression 记录表达式中的块和if分支中的语句
+//
+// 参数:
+//
+//	file - 表达式所属的文件
+//	expression - 表达式
+func (p *Profile) addExpression(file *File, expression ast.Expression) {
+	switch e := expression.(type) {
+	case *ast.VarInitializationExpression:
+		p.addExpression(file, e.Value)
+	case *ast.VarAssignmentExpression:
+		p.addExpression(file, e.Name)
+		p.addExpression(file, e.Value)
+	case *ast.CompoundAssignmentExpression:
+		p.addExpression(file, e.Name)
+		p.addExpression(file, e.Right)
+	case *ast.PrefixExpression:
+		p.addExpression(file, e.Value)
+	case *ast.InfixExpression:
+		p.addExpression(file, e.Left)
+		p.addExpression(file, e.Right)
+	case *ast.PrefixUnaryIncDecExpression:
+		p.addExpression(file, e.Right)
+	case *ast.PostfixUnaryIncDecExpression:
+		p.addExpression(file, e.Left)
+	case *ast.GroupedExpression:
+		p.addExpression(file, e.Expr)
+	case *ast.ListExpression:
+		for _, element := range e.Value {
+			p.addExpression(file, element)
+		}
+	case *ast.InterpolatedStringExpression:
+		for _, part := range e.Parts {
+			p.addExpression(file, part)
+		}
+	case *ast.MapExpression:
+		for _, entry := range e.Entries {
+			p.addExpression(file, entry.Key)
+			p.addExpression(file, entry.Value)
+		}
+	case *ast.BlockExpression:
+		for _, statement := range e.Statements {
+			p.addStatement(file, statement)
+		}
+	case *ast.IfExpression:
+		p.addExpression(file, e.Condition)
+		p.addStatement(file, e.Consequence)
+		p.addStatement(file, e.Alternative)
+	case *ast.TernaryExpression:
+		p.addExpression(file, e.Condition)
+		p.addExpression(file, e.Consequence)
+		p.addExpression(file, e.Alternative)
+	case *ast.FunctionExpression:
+		for _, param := range e.Parameter {
+			p.addExpression(file, param.DefaultValue)
+		}
+		p.addStatement(file, e.Body)
+	case *ast.CallExpression:
+		p.addExpression(file, e.Function)
+		for _, arg := range e.Argument {
+			p.addExpression(file, arg)
+		}
+	case *ast.IndexExpression:
+		p.addExpression(file, e.Target)
+		p.addExpression(file, e.Index)
+	case *ast.SliceExpression:
+		p.addExpression(file, e.Target)
+		p.addExpression(file, e.Low)
+		p.addExpression(file, e.High)
+	}
+}
+
+// displayName 返回文件在报告中显示的名称，位于工作目录下的文件显示相对路径
+//
+// 参数:
+//
+//	path - 文件的绝对路径
+//
+// 返回值:
+//
+//	string - 显示的名称
+func displayName(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,80 @@
+package coverage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+)
+
+// runWithProfile 执行testdata中的脚本并返回记录的覆盖率
+func runWithProfile(t *testing.T, name string) *Profile {
+	t.Helper()
+	path, err := filepath.Abs(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %+v", name, err)
+	}
+	p, err := parser.NewParser(lexer.NewLexer(filepath.Base(path), string(data)))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("parse err = %+v", p.Err)
+	}
+	profile := NewProfile()
+	e := evaluator.NewEvaluator(&frame.Frame{FuncName: name})
+	e.File = path
+	e.Stdout = io.Discard
+	e.Hooks = profile.Hooks(e)
+	e.Eval(program, object.NewGlobalEnvironment(nil))
+	if e.Err != nil {
+		t.Fatalf("err = %+v, expected nil", e.Err)
+	}
+	return profile
+}
+
+func TestCoverage_Listing(t *testing.T) {
+	profile := runWithProfile(t, "else_branch.gh")
+	var buf bytes.Buffer
+	if err := profile.WriteListing(&buf); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	expected, err := os.ReadFile(filepath.Join("testdata", "else_branch.golden"))
+	if err != nil {
+		t.Fatalf("failed to read golden file: %+v", err)
+	}
+	if got := buf.String(); got != string(expected) {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCoverage_Files(t *testing.T) {
+	files := runWithProfile(t, "else_branch.gh").Files()
+	if len(files) != 1 {
+		t.Fatalf("excepted 1 file, got %d", len(files))
+	}
+	file := files[0]
+	if file.Total() != 8 || file.Covered() != 7 {
+		t.Errorf("excepted 7/8 lines, got %d/%d", file.Covered(), file.Total())
+	}
+	if excepted := []int{6}; !reflect.DeepEqual(file.Missed(), excepted) {
+		t.Errorf("excepted missed lines %v, got %v", excepted, file.Missed())
+	}
+	if !strings.HasPrefix(file.Summary(), filepath.Join("testdata", "else_branch.gh")+": 87.5% of lines covered") {
+		t.Errorf("unexpected summary %q", file.Summary())
+	}
+}
@@ -0,0 +1,613 @@
+// Package diagnostic 将词法、语法和运行时错误渲染为带源代码摘录的诊断信息
+// 每个位置输出 文件:行:列、对应的源代码行以及标记错误范围的 ^^^^，可选彩色输出
+
+package diagnostic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lint"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// ANSI颜色转义序列
+const (
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorReset  = "\033[0m"
+)
+
+// 诊断信息的严重程度
+const (
+	SeverityError   = "error"   // 错误，程序无法继续执行
+	SeverityWarning = "warning" // 警告，不影响执行，由静态检查产生
+)
+
+// maxExcerptLines 源代码摘录的最大行数，超出时省略中间的行
+const maxExcerptLines = 4
+
+// maxFrames 渲染调用栈时输出的最大层数，超出时只输出最内层和最外层各一半
+const maxFrames = 20
+
+// maxRepeatedFrames 连续相同的层（如递归调用）最多输出的层数，其余的层只输出数量
+const maxRepeatedFrames = 3
+
+// maxIndentDepth 调用栈按深度缩进的最大层数，更深的调用与该层对齐
+const maxIndentDepth = 10
+
+// Diagnostic 从错误中提取的诊断信息
+
+type Diagnostic struct {
+	Kind         string       // 错误类型，如"Syntax Error"
+	Code         string       // 稳定的错误代码，如"E2001"，见errcode包
+	Severity     string       // 严重程度，SeverityError或SeverityWarning
+	Message      string       // 错误描述文本
+	Frame        *frame.Frame // 错误发生时的调用栈，词法和语法错误为nil
+	PosStart     *util.Pos    // 错误起始位置
+	PosEnd       *util.Pos    // 错误结束位置
+	Note         string       // 附加说明，如未闭合的括号导致分析停止的位置，没有时为空
+	NotePosStart *util.Pos    // 附加说明的起始位置，没有时为nil
+	NotePosEnd   *util.Pos    // 附加说明的结束位置，没有时为nil
+}
+
+// StackEntry 调用栈中的一层
+
+type StackEntry struct {
+	FuncName string    // 函数名
+	Depth    int       // 调用深度，最外层为0
+	PosStart *util.Pos // 该层中正在执行的表达式的起始位置
+	PosEnd   *util.Pos // 该层中正在执行的表达式的结束位置
+}
+
+// Heading 返回诊断信息首行中的错误类型和错误代码，如"Syntax Error[E2001]"
+// 没有错误代码时只返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型和错误代码
+func (d *Diagnostic) Heading() string {
+	if d.Code == "" {
+		return d.Kind
+	}
+	return d.Kind + "[" + d.Code + "]"
+}
+
+// Stack 沿Frame.Parent链返回完整的调用栈，不受渲染时层数上限的影响
+// 每一层的位置为该层中正在执行的表达式，最内层为错误位置
+//
+// 返回值:
+//
+//	[]StackEntry - 从内到外排列的调用栈，词法和语法错误为空
+func (d *Diagnostic) Stack() []StackEntry {
+	var stack []StackEntry
+	posStart, posEnd := d.PosStart, d.PosEnd
+	for currFrame := d.Frame; currFrame != nil && posStart != nil && posEnd != nil; currFrame = currFrame.Parent {
+		stack = append(stack, StackEntry{FuncName: currFrame.FuncName, PosStart: posStart, PosEnd: posEnd})
+		posStart, posEnd = currFrame.PosStart, currFrame.PosEnd
+	}
+	for i := range stack {
+		stack[i].Depth = len(stack) - 1 - i
+	}
+	return stack
+}
+
+// Error 词法、语法、语义检查和运行时错误以及静态检查警告的公共接口
+// 各个包中的错误类型都实现了该接口，被fmt.Errorf("%w")等包装后仍可以通过errors.As获取
+
+type Error interface {
+	error
+
+	// ErrorKind 返回错误类型
+	//
+	// 返回值:
+	//
+	//	string - 错误类型，如"Syntax Error"
+	ErrorKind() string
+
+	// ErrorCode 返回错误代码
+	//
+	// 返回值:
+	//
+	//	string - 错误代码，见errcode包
+	ErrorCode() string
+
+	// ErrorMessage 返回不含位置信息的错误描述
+	//
+	// 返回值:
+	//
+	//	string - 错误描述文本
+	ErrorMessage() string
+
+	// Pos 返回错误的位置
+	//
+	// 返回值:
+	//
+	//	*util.Pos - 错误起始位置
+	//	*util.Pos - 错误结束位置
+	Pos() (*util.Pos, *util.Pos)
+
+	// Frames 返回错误发生时的调用栈
+	//
+	// 返回值:
+	//
+	//	*frame.Frame - 错误发生时的调用栈，词法和语法错误为nil
+	Frames() *frame.Frame
+}
+
+// FromError 从词法、语法或运行时错误中提取诊断信息
+// 错误可以被包装，通过errors.As查找其中实现Error接口的错误
+//
+// 参数:
+//
+//	err - 要转换的错误
+//
+// 返回值:
+//
+//	*Diagnostic - 诊断信息
+//	bool - 错误是否包含位置信息，其他错误返回false
+func FromError(err error) (*Diagnostic, bool) {
+	var e Error
+	if !errors.As(err, &e) {
+		return nil, false
+	}
+	posStart, posEnd := e.Pos()
+	if posStart == nil || posEnd == nil {
+		return nil, false
+	}
+	d := &Diagnostic{
+		Kind:     e.ErrorKind(),
+		Code:     e.ErrorCode(),
+		Severity: SeverityError,
+		Message:  e.ErrorMessage(),
+		Frame:    e.Frames(),
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+	switch e := e.(type) {
+	case *parser.SyntaxError:
+		d.Note, d.NotePosStart, d.NotePosEnd = e.Note, e.NotePosStart, e.NotePosEnd
+	case *lint.Warning:
+		d.Severity = SeverityWarning
+	}
+	return d, true
+}
+
+// ColorEnabled 判断是否应向指定输出流输出彩色文本
+// 设置了非空的NO_COLOR环境变量或输出流不是终端时返回false
+//
+// 参数:
+//
+//	w - 输出流，通常为os.Stderr
+//
+// 返回值:
+//
+//	bool - 是否启用彩色输出
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Render 将错误渲染为诊断文本，以换行符结尾
+// 首行为 文件:行:列: 错误类型[错误代码]: 错误信息，随后是错误位置的源代码摘录
+// 带有附加说明的错误随后输出 文件:行:列: note: 说明 及说明位置的源代码摘录
+// 运行时错误从最外层的<module>到错误位置依次渲染调用栈中的每一层及其调用位置的源代码摘录，最新的调用在最后
+// 无法提取位置信息的错误直接输出其Error()文本
+//
+// 参数:
+//
+//	err - 要渲染的错误
+//	color - 是否使用ANSI颜色（错误信息为红色，警告为黄色，位置为青色）
+//
+// 返回值:
+//
+//	string - 渲染后的诊断文本
+func Render(err error, color bool) string {
+	var sb strings.Builder
+	d, ok := FromError(err)
+	if !ok {
+		sb.WriteString(paint(err.Error(), colorRed, color))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	// 警告以黄色显示
+	accent := colorRed
+	if d.Severity == SeverityWarning {
+		accent = colorYellow
+	}
+	sb.WriteString(paint(formatPos(d.PosStart), colorCyan, color))
+	sb.WriteString(paint(": "+d.Heading(), accent, color))
+	if d.Message != "" {
+		sb.WriteString(paint(": "+d.Message, accent, color))
+	}
+	sb.WriteString("\n")
+	if d.Frame == nil {
+		writeExcerpt(&sb, "", d.PosStart, d.PosEnd, accent, color)
+		if d.Note != "" && d.NotePosStart != nil && d.NotePosEnd != nil {
+			sb.WriteString(paint(formatPos(d.NotePosStart), colorCyan, color))
+			sb.WriteString(": note: " + d.Note + "\n")
+			writeExcerpt(&sb, "", d.NotePosStart, d.NotePosEnd, accent, color)
+		}
+		return sb.String()
+	}
+	// 从最外层向错误位置渲染，最新的调用在最后，按调用深度缩进
+	// 连续相同的层（如递归）只保留前几层，层数仍然过多时省略中间的层
+	frames := collapseRepeated(d.Stack())
+	for i, item := range frames {
+		if len(frames) > maxFrames && i >= maxFrames/2 && i < len(frames)-maxFrames/2 {
+			if i == maxFrames/2 {
+				omitted := 0
+				for _, hidden := range frames[i : len(frames)-maxFrames/2] {
+					omitted += max(hidden.repeated, 1)
+				}
+				sb.WriteString(fmt.Sprintf("%s  ... %d frames omitted ...\n", frameIndent(item.entry.Depth), omitted))
+			}
+			continue
+		}
+		indent := frameIndent(item.entry.Depth)
+		if item.repeated > 0 {
+			sb.WriteString(fmt.Sprintf("%s  ... %d identical frames omitted ...\n", indent, item.repeated))
+			continue
+		}
+		funcName := item.entry.FuncName
+		if item.entry.Depth == 0 {
+			funcName = "<module>"
+		}
+		sb.WriteString(indent + "  in " + funcName + " at ")
+		sb.WriteString(paint(formatPos(item.entry.PosStart), colorCyan, color))
+		sb.WriteString("\n")
+		writeExcerpt(&sb, indent, item.entry.PosStart, item.entry.PosEnd, accent, color)
+	}
+	return sb.String()
+}
+
+// frameItem 渲染调用栈时的一项，为一层或连续相同的层被省略的部分
+
+type frameItem struct {
+	entry    StackEntry // 该层，省略部分为其中的第一层
+	repeated int        // 省略的相同层数，0表示该项为一层
+}
+
+// collapseRepeated 将调用栈转换为从最外层到最内层的渲染项
+// 函数名和调用位置都相同的连续层只保留前maxRepeatedFrames层，其余的层合并为一个省略项
+//
+// 参数:
+//
+//	stack - 从内到外排列的调用栈
+//
+// 返回值:
+//
+//	[]frameItem - 从外到内排列的渲染项
+func collapseRepeated(stack []StackEntry) []frameItem {
+	var items []frameItem
+	run := 0
+	for i := len(stack) - 1; i >= 0; i-- {
+		entry := stack[i]
+		if i < len(stack)-1 && sameFrame(entry, stack[i+1]) {
+			run++
+		} else {
+			run = 1
+		}
+		if run <= maxRepeatedFrames {
+			items = append(items, frameItem{entry: entry})
+			continue
+		}
+		if run == maxRepeatedFrames+1 {
+			items = append(items, frameItem{entry: entry})
+		}
+		items[len(items)-1].repeated++
+	}
+	return items
+}
+
+// sameFrame 判断两层的函数名和调用位置是否相同
+//
+// 参数:
+//
+//	a - 第一层
+//	b - 第二层
+//
+// 返回值:
+//
+//	bool - 是否相同
+func sameFrame(a, b StackEntry) bool {
+	return a.FuncName == b.FuncName && a.PosStart.File() == b.PosStart.File() &&
+		a.PosStart.Idx == b.PosStart.Idx && a.PosEnd.Idx == b.PosEnd.Idx
+}
+
+// SortErrors 按文件名和错误起始位置的偏移量对错误进行稳定排序
+// 无法提取位置信息的错误排在最后，保持原有顺序
+//
+// 参数:
+//
+//	errs - 要排序的错误，原地排序
+func SortErrors(errs []error) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		di, iok := FromError(errs[i])
+		dj, jok := FromError(errs[j])
+		if !iok || !jok {
+			return iok && !jok
+		}
+		if di.PosStart.File() != dj.PosStart.File() {
+			return di.PosStart.File() < dj.PosStart.File()
+		}
+		return di.PosStart.Idx < dj.PosStart.Idx
+	})
+}
+
+// RenderList 按源代码顺序渲染多个错误，每个错误带有各自的源代码摘录
+// 错误数超过上限时只渲染前limit个，随后输出 and N more errors；多于一个错误时最后输出 N errors found
+//
+// 参数:
+//
+//	errs - 要渲染的错误，不会被修改
+//	limit - 渲染的最大错误数，小于等于0时不限制
+//	color - 是否使用ANSI颜色
+//
+// 返回值:
+//
+//	string - 渲染后的诊断文本
+func RenderList(errs []error, limit int, color bool) string {
+	sorted := append([]error(nil), errs...)
+	SortErrors(sorted)
+	shown := len(sorted)
+	if limit > 0 && shown > limit {
+		shown = limit
+	}
+	var sb strings.Builder
+	for _, err := range sorted[:shown] {
+		sb.WriteString(Render(err, color))
+	}
+	for _, line := range Summary(len(sorted), shown) {
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// Summary 返回错误列表末尾的摘要行
+// 有错误未输出时为 and N more errors，多于一个错误时为 N errors found
+//
+// 参数:
+//
+//	total - 错误总数
+//	shown - 已输出的错误数
+//
+// 返回值:
+//
+//	[]string - 摘要行，不含换行符
+func Summary(total, shown int) []string {
+	var lines []string
+	if hidden := total - shown; hidden > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more %s", hidden, pluralErrors(hidden)))
+	}
+	if total > 1 {
+		lines = append(lines, fmt.Sprintf("%d %s found", total, pluralErrors(total)))
+	}
+	return lines
+}
+
+// pluralErrors 返回与数量对应的error的单复数形式
+//
+// 参数:
+//
+//	n - 错误数
+//
+// 返回值:
+//
+//	string - n为1时为"error"，否则为"errors"
+func pluralErrors(n int) string {
+	if n == 1 {
+		return "error"
+	}
+	return "errors"
+}
+
+// frameIndent 返回调用栈中指定深度的一层的缩进
+//
+// 参数:
+//
+//	depth - 调用深度，最外层为0
+//
+// 返回值:
+//
+//	string - 每层两个空格的缩进，最多maxIndentDepth层
+func frameIndent(depth int) string {
+	return strings.Repeat("  ", min(depth, maxIndentDepth))
+}
+
+// excerptLine 源代码摘录中的一行
+
+type excerptLine struct {
+	row    int    // 行号
+	text   string // 源代码行
+	offset int    // 错误范围在该行中的起始字节偏移
+	length int    // 错误范围在该行中的字节长度
+}
+
+// writeExcerpt 写入错误范围覆盖的源代码行，并在每行下方用 ^ 标记错误范围
+// 超过util.MaxSnippetWidth的行只显示错误范围附近的部分，位置中的列号不受影响
+//
+// 参数:
+//
+//	sb - 输出缓冲区
+//	indent - 每行前的缩进
+//	posStart - 错误起始位置
+//	posEnd - 错误结束位置
+//	accent - 标记错误范围的ANSI颜色
+//	color - 是否使用ANSI颜色
+func writeExcerpt(sb *strings.Builder, indent string, posStart, posEnd *util.Pos, accent string, color bool) {
+	lines := excerptLines(posStart, posEnd)
+	// 超出最大行数时只保留首尾各一半
+	elided := false
+	if len(lines) > maxExcerptLines {
+		lines = append(lines[:maxExcerptLines/2], lines[len(lines)-maxExcerptLines/2:]...)
+		elided = true
+	}
+	gutter := len(strconv.Itoa(lines[len(lines)-1].row))
+	for i, line := range lines {
+		if elided && i == maxExcerptLines/2 {
+			sb.WriteString(fmt.Sprintf("%s  %*s | ...\n", indent, gutter, ""))
+		}
+		// 过长的行只显示错误范围附近的部分
+		text, offset, length := util.ClipLine(line.text, line.offset, line.offset+line.length)
+		sb.WriteString(fmt.Sprintf("%s  %*d | %s\n", indent, gutter, line.row, text))
+		if line.length == 0 && i != 0 {
+			continue
+		}
+		padding := util.DisplayWidth(text[:offset])
+		carets := max(util.DisplayWidth(text[offset:offset+length]), 1)
+		sb.WriteString(fmt.Sprintf("%s  %*s | %s", indent, gutter, "", strings.Repeat(" ", padding)))
+		sb.WriteString(paint(strings.Repeat("^", carets), accent, color))
+		sb.WriteString("\n")
+	}
+}
+
+// excerptLines 计算错误范围覆盖的每一行及其中被标记的部分
+// 续行的标记从第一个非空白字符开始
+//
+// 参数:
+//
+//	posStart - 错误起始位置
+//	posEnd - 错误结束位置
+//
+// 返回值:
+//
+//	[]excerptLine - 错误范围覆盖的源代码行，至少包含一行
+func excerptLines(posStart, posEnd *util.Pos) []excerptLine {
+	text := posStart.Text()
+	startIdx := min(max(posStart.Idx, 0), len(text))
+	endIdx := min(max(posEnd.Idx, startIdx), len(text))
+	lineStart := strings.LastIndex(text[:startIdx], "\n") + 1
+	var lines []excerptLine
+	for row := posStart.Row; ; row++ {
+		lineEnd := len(text)
+		if i := strings.Index(text[lineStart:], "\n"); i >= 0 {
+			lineEnd = lineStart + i
+		}
+		line := strings.TrimRight(text[lineStart:lineEnd], "\r")
+		from := max(startIdx, lineStart) - lineStart
+		if row != posStart.Row {
+			from = max(from, len(line)-len(strings.TrimLeft(line, " ")))
+		}
+		from = min(from, len(line))
+		to := max(min(endIdx-lineStart, len(line)), from)
+		lines = append(lines, excerptLine{row: row, text: line, offset: from, length: to - from})
+		// 错误范围恰好结束于换行符时不再输出下一行
+		if lineEnd+1 >= endIdx || lineEnd == len(text) {
+			return lines
+		}
+		lineStart = lineEnd + 1
+	}
+}
+
+// formatPos 将位置格式化为 文件:行:列
+//
+// 参数:
+//
+//	pos - 源代码位置
+//
+// 返回值:
+//
+//	string - 格式化后的位置
+func formatPos(pos *util.Pos) string {
+	return pos.File() + ":" + strconv.Itoa(pos.Row) + ":" + strconv.Itoa(pos.Col)
+}
+
+// paint 在启用颜色时为文本添加ANSI颜色
+//
+// 参数:
+//
+//	text - 文本内容
+//	code - ANSI颜色转义序列
+//	color - 是否启用颜色
+//
+// 返回值:
+//
+//	string - 处理后的文本
+func paint(text, code string, color bool) string {
+	if !color {
+		return text
+	}
+	return code + text + colorReset
+}
+
+// Record 机器可读的诊断信息，用于JSON输出和编辑器集成
+
+type Record struct {
+	File      string `json:"file"`      // 文件名，未知时为空
+	StartLine int    `json:"startLine"` // 起始行号，从1开始计数，未知时为0
+	StartCol  int    `json:"startCol"`  // 起始列号，从1开始计数，未知时为0
+	EndLine   int    `json:"endLine"`   // 结束行号
+	EndCol    int    `json:"endCol"`    // 结束列号，指向错误范围之后的位置
+	Severity  string `json:"severity"`  // 严重程度，"error"或"warning"
+	Kind      string `json:"kind"`      // 错误类型，如"SyntaxError"
+	Code      string `json:"code"`      // 稳定的错误代码，如"E2001"，与语言服务器发布的code相同，未知时为空
+	Message   string `json:"message"`   // 错误描述文本
+}
+
+// NewRecord 将错误转换为机器可读的诊断信息
+// 运行时错误使用最内层的错误位置，无法提取位置信息的错误只包含错误信息
+//
+// 参数:
+//
+//	err - 要转换的错误
+//
+// 返回值:
+//
+//	Record - 诊断信息
+func NewRecord(err error) Record {
+	d, ok := FromError(err)
+	if !ok {
+		return Record{Severity: SeverityError, Kind: "Error", Message: err.Error()}
+	}
+	return Record{
+		File:      d.PosStart.File(),
+		StartLine: d.PosStart.Row,
+		StartCol:  d.PosStart.Col,
+		EndLine:   d.PosEnd.Row,
+		EndCol:    d.PosEnd.Col,
+		Severity:  d.Severity,
+		Kind:      strings.ReplaceAll(d.Kind, " ", ""),
+		Code:      d.Code,
+		Message:   d.Message,
+	}
+}
+
+// WriteJSON 将诊断信息以JSON数组的形式写入输出，以换行符结尾
+// 没有诊断信息时写入空数组
+//
+// 参数:
+//
+//	w - 输出位置
+//	records - 诊断信息列表
+//
+// 返回值:
+//
+//	error - 写入过程中的错误
+func WriteJSON(w io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(records)
+}
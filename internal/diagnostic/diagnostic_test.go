@@ -0,0 +1,366 @@
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lint"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// runFile 执行测试文件并返回产生的错误
+func runFile(t *testing.T, fileName string) error {
+	t.Helper()
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("failed to read test file: %+v", err)
+	}
+	baseName := filepath.Base(fileName)
+	l := lexer.NewLexer(baseName, string(data))
+	p, err := parser.NewParser(l)
+	if err != nil {
+		return err
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return p.Err
+	}
+	env := &object.Environment{
+		Store: make(map[string]*object.Symbol),
+		Outer: nil,
+	}
+	for name, builtin := range object.Builtins {
+		env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+	}
+	e := evaluator.NewEvaluator(&frame.Frame{
+		FuncName: baseName,
+		Parent:   nil,
+		PosStart: nil,
+		PosEnd:   nil,
+	})
+	e.Eval(program, env)
+	return e.Err
+}
+
+func TestDiagnostic_Render(t *testing.T) {
+	tests := []string{
+		"syntax_error",
+		"illegal_token",
+		"runtime_error",
+		"multiline_error",
+		"builtin_error",
+	}
+
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := runFile(t, filepath.Join("testdata", name+".gh"))
+			if err == nil {
+				t.Fatalf("err = nil, expected error")
+			}
+			got := Render(err, false)
+			expected, err := os.ReadFile(filepath.Join("testdata", name+".golden"))
+			if err != nil {
+				t.Fatalf("failed to read golden file: %+v", err)
+			}
+			if got != string(expected) {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+			}
+		})
+	}
+}
+
+func TestDiagnostic_RenderLongLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		prefix bool
+		suffix bool
+	}{
+		{name: "Start", offset: 0, prefix: false, suffix: true},
+		{name: "Middle", offset: 5000, prefix: true, suffix: true},
+		{name: "End", offset: 9999, prefix: true, suffix: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := []byte(strings.Repeat("x", 10000))
+			line[tt.offset] = '@'
+			text := string(line)
+			err := &lexer.IllegalTokenError{
+				Code:     "E1001",
+				Message:  `illegal token "@".`,
+				PosStart: util.NewPos(1, tt.offset+1, tt.offset, util.NewSourceFile("long.gh", text)),
+				PosEnd:   util.NewPos(1, tt.offset+2, tt.offset+1, util.NewSourceFile("long.gh", text)),
+			}
+			got := strings.Split(Render(err, false), "\n")
+			// 报告的列号仍然是完整行中的列号
+			if header := fmt.Sprintf("long.gh:1:%d: ", tt.offset+1); !strings.HasPrefix(got[0], header) {
+				t.Errorf("excepted header to start with %q, got %q", header, got[0])
+			}
+			snippet := []rune(strings.TrimPrefix(got[1], "  1 | "))
+			if len(snippet) > util.MaxSnippetWidth {
+				t.Errorf("excepted snippet of at most %d columns, got %d", util.MaxSnippetWidth, len(snippet))
+			}
+			if prefix := snippet[0] == '…'; prefix != tt.prefix {
+				t.Errorf("excepted leading ellipsis %v, got %q", tt.prefix, string(snippet))
+			}
+			if suffix := snippet[len(snippet)-1] == '…'; suffix != tt.suffix {
+				t.Errorf("excepted trailing ellipsis %v, got %q", tt.suffix, string(snippet))
+			}
+			carets := strings.TrimPrefix(got[2], "    | ")
+			column := strings.Index(carets, "^")
+			if strings.Count(carets, "^") != 1 || column < 0 || column >= len(snippet) || snippet[column] != '@' {
+				t.Errorf("excepted a single caret under the error, got %q under %q", carets, string(snippet))
+			}
+		})
+	}
+}
+
+func TestDiagnostic_RenderColor(t *testing.T) {
+	err := runFile(t, "testdata/syntax_error.gh")
+	got := Render(err, true)
+	excepted := "\033[36msyntax_error.gh:2:9\033[0m" +
+		"\033[31m: Syntax Error[E2003]\033[0m" +
+		"\033[31m: unclosed \"(\" opened at line 2, column 9.\033[0m\n" +
+		"  2 | var y = (x + 2;\n" +
+		"    |         \033[31m^\033[0m\n" +
+		"\033[36msyntax_error.gh:2:15\033[0m: note: parsing stopped here: expected \"RPAREN\", but got \"SEMICOLON\".\n" +
+		"  2 | var y = (x + 2;\n" +
+		"    |               \033[31m^\033[0m\n"
+	if got != excepted {
+		t.Errorf("excepted %q, got %q", excepted, got)
+	}
+}
+
+func TestDiagnostic_RenderPlainError(t *testing.T) {
+	got := Render(errors.New("ghost-lang: file not found."), false)
+	excepted := "ghost-lang: file not found.\n"
+	if got != excepted {
+		t.Errorf("excepted %q, got %q", excepted, got)
+	}
+}
+
+func TestDiagnostic_ColorEnabled(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %+v", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	t.Setenv("NO_COLOR", "")
+	if ColorEnabled(file) {
+		t.Errorf("excepted color disabled for a regular file")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(os.Stderr) {
+		t.Errorf("excepted color disabled when NO_COLOR is set")
+	}
+}
+
+func TestDiagnostic_RenderDeepStack(t *testing.T) {
+	const depth = 50
+	text := "f(n);"
+	pos := func() (*util.Pos, *util.Pos) {
+		return util.NewPos(1, 1, 0, util.NewSourceFile("deep.gh", text)), util.NewPos(1, 5, 4, util.NewSourceFile("deep.gh", text))
+	}
+	// 构造深度为depth的调用栈，最外层为文件本身
+	f := &frame.Frame{FuncName: "deep.gh"}
+	for i := 1; i < depth; i++ {
+		posStart, posEnd := pos()
+		f = &frame.Frame{FuncName: fmt.Sprintf("<function \"f%d\">", i), Parent: f, PosStart: posStart, PosEnd: posEnd}
+	}
+	posStart, posEnd := pos()
+	err := &object.ValueError{Frame: f, Message: "too deep.", PosStart: posStart, PosEnd: posEnd}
+
+	d, ok := FromError(err)
+	if !ok {
+		t.Fatalf("excepted diagnostic for %T", err)
+	}
+	stack := d.Stack()
+	if len(stack) != depth {
+		t.Fatalf("excepted full stack of %d frames, got %d", depth, len(stack))
+	}
+	if stack[0].FuncName != "<function \"f49\">" || stack[0].Depth != depth-1 || stack[depth-1].Depth != 0 {
+		t.Errorf("unexpected stack order: first %+v, last %+v", stack[0], stack[depth-1])
+	}
+
+	lines := strings.Split(Render(err, false), "\n")
+	var frames []string
+	for _, line := range lines {
+		if strings.Contains(line, " in ") {
+			frames = append(frames, line)
+		}
+	}
+	if len(frames) != maxFrames {
+		t.Fatalf("excepted %d rendered frames, got %d", maxFrames, len(frames))
+	}
+	marker := strings.Repeat("  ", maxIndentDepth) + "  ... 30 frames omitted ..."
+	if !slices.Contains(lines, marker) {
+		t.Errorf("excepted omission marker %q in:\n%s", marker, strings.Join(lines, "\n"))
+	}
+	excepted := []string{
+		"  in <module> at deep.gh:1:1",
+		strings.Repeat("  ", 9) + "  in <function \"f9\"> at deep.gh:1:1",
+		strings.Repeat("  ", maxIndentDepth) + "  in <function \"f40\"> at deep.gh:1:1",
+		strings.Repeat("  ", maxIndentDepth) + "  in <function \"f49\"> at deep.gh:1:1",
+	}
+	got := []string{frames[0], frames[9], frames[10], frames[19]}
+	if !reflect.DeepEqual(got, excepted) {
+		t.Errorf("excepted %q, got %q", excepted, got)
+	}
+}
+
+func TestDiagnostic_RenderRecursion(t *testing.T) {
+	const depth = 1000
+	text := "func f(n) { return f(n - 1) + x; };\nf(1);"
+	call := func() (*util.Pos, *util.Pos) {
+		return util.NewPos(1, 20, 19, util.NewSourceFile("rec.gh", text)), util.NewPos(1, 28, 27, util.NewSourceFile("rec.gh", text))
+	}
+	// 最外层在第2行调用f，之后每层都在同一位置递归调用f
+	f := &frame.Frame{FuncName: "rec.gh"}
+	f = &frame.Frame{FuncName: "<function \"f\">", Parent: f, PosStart: util.NewPos(2, 1, 36, util.NewSourceFile("rec.gh", text)), PosEnd: util.NewPos(2, 5, 40, util.NewSourceFile("rec.gh", text))}
+	for i := 2; i < depth; i++ {
+		posStart, posEnd := call()
+		f = &frame.Frame{FuncName: "<function \"f\">", Parent: f, PosStart: posStart, PosEnd: posEnd}
+	}
+	err := &evaluator.VariableError{Frame: f, Message: "undefined variable \"x\".", PosStart: util.NewPos(1, 31, 30, util.NewSourceFile("rec.gh", text)), PosEnd: util.NewPos(1, 32, 31, util.NewSourceFile("rec.gh", text))}
+
+	var got []string
+	for _, line := range strings.Split(Render(err, false), "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "in ") || strings.HasPrefix(trimmed, "...") {
+			got = append(got, trimmed)
+		}
+	}
+	excepted := []string{
+		"in <module> at rec.gh:2:1",
+		"in <function \"f\"> at rec.gh:1:20",
+		"in <function \"f\"> at rec.gh:1:20",
+		"in <function \"f\"> at rec.gh:1:20",
+		fmt.Sprintf("... %d identical frames omitted ...", depth-1-maxRepeatedFrames-1),
+		"in <function \"f\"> at rec.gh:1:31",
+	}
+	if !reflect.DeepEqual(got, excepted) {
+		t.Errorf("excepted %q, got %q", excepted, got)
+	}
+}
+
+func TestDiagnostic_RenderList(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "multiple_errors.gh"))
+	if err != nil {
+		t.Fatalf("failed to read test file: %+v", err)
+	}
+	p, err := parser.NewParser(lexer.NewLexer("multiple_errors.gh", string(data)))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	p.Recover = true
+	p.ParseProgram()
+	// 打乱顺序，渲染时应重新按位置排序
+	errs := slices.Clone(p.Errors())
+	slices.Reverse(errs)
+	errs = append([]error{errors.New("ghost-lang: internal error.")}, errs...)
+
+	tests := []struct {
+		name     string
+		limit    int
+		excepted string
+	}{
+		{name: "Unlimited", limit: 0, excepted: "multiple_errors"},
+		{name: "Capped", limit: 2, excepted: "multiple_errors_capped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderList(errs, tt.limit, false)
+			expected, err := os.ReadFile(filepath.Join("testdata", tt.excepted+".golden"))
+			if err != nil {
+				t.Fatalf("failed to read golden file: %+v", err)
+			}
+			if got != string(expected) {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+			}
+		})
+	}
+}
+
+func TestDiagnostic_RenderListSingleError(t *testing.T) {
+	err := runFile(t, "testdata/syntax_error.gh")
+	if got, excepted := RenderList([]error{err}, 20, false), Render(err, false); got != excepted {
+		t.Errorf("excepted %q, got %q", excepted, got)
+	}
+}
+
+func TestDiagnostic_ErrorInterface(t *testing.T) {
+	source := util.NewSourceFile("main.gh", "x;")
+	pos := &util.Pos{Row: 1, Col: 1, Source: source}
+	end := &util.Pos{Row: 1, Col: 2, Idx: 1, Source: source}
+	fr := &frame.Frame{FuncName: "main.gh"}
+	tests := []struct {
+		err      error
+		excepted string
+		frame    *frame.Frame
+	}{
+		{err: &lexer.IllegalTokenError{Code: errcode.IllegalToken, PosStart: pos, PosEnd: end}, excepted: "Illegal Token Error"},
+		{err: &lexer.SyntaxError{Code: errcode.UnterminatedComment, PosStart: pos, PosEnd: end}, excepted: "Syntax Error"},
+		{err: &parser.SyntaxError{Code: errcode.ExpectedToken, PosStart: pos, PosEnd: end}, excepted: "Syntax Error"},
+		{err: &sema.Error{Code: errcode.VariableError, Kind: "Variable Error", PosStart: pos, PosEnd: end}, excepted: "Variable Error"},
+		{err: &lint.Warning{Code: errcode.UnusedVariable, PosStart: pos, PosEnd: end}, excepted: "Warning"},
+		{err: &evaluator.VariableError{Code: errcode.VariableError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Variable Error", frame: fr},
+		{err: &evaluator.TypeError{Code: errcode.TypeError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Type Error", frame: fr},
+		{err: &evaluator.SyntaxError{Code: errcode.SyntaxError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Syntax Error", frame: fr},
+		{err: &evaluator.ArgumentError{Code: errcode.ArgumentError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Argument Error", frame: fr},
+		{err: &evaluator.RecursionError{Code: errcode.RecursionError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Recursion Error", frame: fr},
+		{err: &evaluator.ResourceError{Code: errcode.StepLimit, Frame: fr, PosStart: pos, PosEnd: end, Limit: evaluator.StepLimit}, excepted: "Step Limit Error", frame: fr},
+		{err: &evaluator.ImportError{Code: errcode.ImportError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Import Error", frame: fr},
+		{err: &object.OperationError{Code: errcode.OperationError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Operation Error", frame: fr},
+		{err: &object.MathError{Code: errcode.MathError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Math Error", frame: fr},
+		{err: &object.TypeError{Code: errcode.TypeError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Type Error", frame: fr},
+		{err: &object.IndexError{Code: errcode.IndexError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Index Error", frame: fr},
+		{err: &object.ValueError{Code: errcode.ValueError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Value Error", frame: fr},
+		{err: &object.AssertionError{Code: errcode.AssertionError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Assertion Error", frame: fr},
+		{err: &object.HostError{Code: errcode.HostError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Host Error", frame: fr},
+		{err: &object.ParseError{Code: errcode.ParseError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Parse Error", frame: fr},
+		{err: &object.SandboxError{Code: errcode.SandboxError, Frame: fr, PosStart: pos, PosEnd: end}, excepted: "Sandbox Error", frame: fr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.excepted, func(t *testing.T) {
+			// 包装之后仍然可以获取错误的类型、代码、位置和调用栈
+			wrapped := fmt.Errorf("running main.gh: %w", tt.err)
+			var e Error
+			if !errors.As(wrapped, &e) {
+				t.Fatalf("excepted %T to implement Error", tt.err)
+			}
+			if e.ErrorKind() != tt.excepted {
+				t.Errorf("excepted kind %q, got %q", tt.excepted, e.ErrorKind())
+			}
+			if code := reflect.ValueOf(tt.err).Elem().FieldByName("Code").String(); e.ErrorCode() != code {
+				t.Errorf("excepted code %q, got %q", code, e.ErrorCode())
+			}
+			if posStart, posEnd := e.Pos(); posStart != pos || posEnd != end {
+				t.Errorf("excepted position %v-%v, got %v-%v", pos, end, posStart, posEnd)
+			}
+			if e.Frames() != tt.frame {
+				t.Errorf("excepted frame %v, got %v", tt.frame, e.Frames())
+			}
+			d, ok := FromError(wrapped)
+			if !ok || d.Kind != tt.excepted {
+				t.Errorf("excepted diagnostic of kind %q, got %+v", tt.excepted, d)
+			}
+		})
+	}
+}
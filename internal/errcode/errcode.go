@@ -0,0 +1,122 @@
+// Package errcode 定义诊断信息的稳定错误代码，便于工具和文档在不匹配错误文本的情况下识别错误
+// E1xxx为词法错误，E2xxx为语法错误，E3xxx为运行时错误，E4xxx为超出资源限制的错误，W1xxx为静态检查的警告
+// 已发布的代码不能修改或复用，新增的错误种类使用同一区间中的下一个代码
+package errcode
+
+// 词法错误
+
+const (
+	IllegalToken              = "E1001" // 非法字符
+	UnterminatedComment       = "E1002" // 多行注释未闭合
+	IllegalFloatLiteral       = "E1003" // 浮点数字面量中有多个小数点
+	TrailingBackslash         = "E1004" // 字符串以反斜杠结尾
+	IllegalEscape             = "E1005" // 非法的转义字符
+	UnterminatedString        = "E1006" // 字符串未闭合
+	UnterminatedInterpolation = "E1007" // 字符串插值缺少闭合的"}"
+)
+
+// 语法错误
+
+const (
+	ExpectedToken       = "E2001" // 缺少预期的token
+	UnexpectedToken     = "E2002" // 无法开始表达式的token
+	UnclosedDelimiter   = "E2003" // 括号未闭合
+	UnmatchedDelimiter  = "E2004" // 多余的或不匹配的闭括号
+	DefaultParamOrder   = "E2005" // 无默认值的参数位于有默认值的参数之后
+	IllegalInteger      = "E2006" // 整数字面量超出范围
+	IllegalFloat        = "E2007" // 浮点数字面量无法解析
+	InvalidAssignTarget = "E2008" // 赋值或自增自减的操作数不是左值
+	DuplicateParameter  = "E2009" // 函数声明中重复的参数名
+	NestingTooDeep      = "E2010" // 表达式或语句的嵌套深度超出上限
+	DuplicateVariable   = "E2011" // for-in语句中重复的循环变量名
+)
+
+// 运行时错误
+
+const (
+	TypeError      = "E3001" // 类型错误
+	OperationError = "E3002" // 运算符不支持操作数的类型
+	MathError      = "E3003" // 除以零、溢出等数学错误
+	IndexError     = "E3004" // 索引越界
+	VariableError  = "E3005" // 变量未定义、重复定义或修改常量
+	ArgumentError  = "E3006" // 参数数量或参数名错误
+	ValueError     = "E3007" // 参数的值非法
+	AssertionError = "E3008" // 断言失败
+	RecursionError = "E3009" // 递归过深
+	ImportError    = "E3010" // 模块无法导入
+	SyntaxError    = "E3011" // 运行时发现的语法错误，如return位于函数之外
+	HostError      = "E3012" // 宿主程序注册的函数返回的错误
+	ParseError     = "E3013" // eval等内置函数分析源代码时的错误
+	SandboxError   = "E3014" // 沙箱模式下调用被禁用的内置函数
+	InternalError  = "E3015" // 解释器内部的错误，执行时发生了panic
+	ThrownError    = "E3016" // throw语句抛出的值未被捕获
+)
+
+// 超出资源限制的错误
+
+const (
+	StepLimit  = "E4001" // 执行的节点数超出限制
+	DepthLimit = "E4002" // 函数调用的嵌套深度超出限制
+	AllocLimit = "E4003" // 单个字符串或列表的大小超出限制
+	Timeout    = "E4004" // 执行时间超出限制
+)
+
+// 静态检查的警告
+
+const (
+	UnusedVariable   = "W1001" // 声明后从未读取的变量或参数
+	ShadowedVariable = "W1002" // 遮蔽外层作用域中同名声明的变量
+	UnreachableCode  = "W1003" // return之后永远不会执行的语句
+)
+
+// Entry 错误代码及其对应的错误种类
+
+type Entry struct {
+	Code string // 错误代码
+	Kind string // 错误种类的描述
+}
+
+// All 所有错误代码，按代码排列
+var All = []Entry{
+	{IllegalToken, "illegal token"},
+	{UnterminatedComment, "unterminated comment"},
+	{IllegalFloatLiteral, "illegal float literal"},
+	{TrailingBackslash, "trailing backslash"},
+	{IllegalEscape, "illegal escape character"},
+	{UnterminatedString, "unterminated string literal"},
+	{UnterminatedInterpolation, "unterminated interpolation"},
+	{ExpectedToken, "expected token"},
+	{UnexpectedToken, "unexpected token"},
+	{UnclosedDelimiter, "unclosed delimiter"},
+	{UnmatchedDelimiter, "unmatched delimiter"},
+	{DefaultParamOrder, "non-default parameter after default parameter"},
+	{IllegalInteger, "illegal integer"},
+	{IllegalFloat, "illegal float"},
+	{InvalidAssignTarget, "invalid assignment target"},
+	{DuplicateParameter, "duplicate parameter name"},
+	{NestingTooDeep, "nesting too deep"},
+	{DuplicateVariable, "duplicate loop variable"},
+	{TypeError, "type error"},
+	{OperationError, "operation error"},
+	{MathError, "math error"},
+	{IndexError, "index error"},
+	{VariableError, "variable error"},
+	{ArgumentError, "argument error"},
+	{ValueError, "value error"},
+	{AssertionError, "assertion error"},
+	{RecursionError, "recursion error"},
+	{ImportError, "import error"},
+	{SyntaxError, "runtime syntax error"},
+	{HostError, "host error"},
+	{ParseError, "parse error"},
+	{SandboxError, "sandbox error"},
+	{InternalError, "internal error"},
+	{ThrownError, "thrown error"},
+	{StepLimit, "step limit exceeded"},
+	{DepthLimit, "depth limit exceeded"},
+	{AllocLimit, "alloc limit exceeded"},
+	{Timeout, "timeout"},
+	{UnusedVariable, "unused variable"},
+	{ShadowedVariable, "shadowed variable"},
+	{UnreachableCode, "unreachable code"},
+}
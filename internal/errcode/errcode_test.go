@@ -0,0 +1,24 @@
+package errcode
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestErrcode_Unique(t *testing.T) {
+	format := regexp.MustCompile(`^(E[1-4]|W1)\d{3}$`)
+	codes := map[string]bool{}
+	kinds := map[string]bool{}
+	for _, entry := range All {
+		if !format.MatchString(entry.Code) {
+			t.Errorf("excepted code %q to match %s", entry.Code, format)
+		}
+		if codes[entry.Code] {
+			t.Errorf("duplicate code %q", entry.Code)
+		}
+		if kinds[entry.Kind] {
+			t.Errorf("duplicate kind %q", entry.Kind)
+		}
+		codes[entry.Code], kinds[entry.Kind] = true, true
+	}
+}
@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/benchprog"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+)
+
+// evalProgram 用树遍历解释器分析并执行脚本，丢弃脚本的输出
+func evalProgram(file, source string) error {
+	p, err := parser.NewParser(lexer.NewLexer(file, source))
+	if err != nil {
+		return err
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return p.Err
+	}
+	sema.Resolve(program, sema.Options{File: file})
+	e := NewEvaluator(&frame.Frame{FuncName: "<module>"})
+	e.Stdout = io.Discard
+	e.Eval(program, object.NewGlobalEnvironment(nil))
+	return e.Err
+}
+
+// 基准测试不随go test运行，这里确保所有脚本都能正确执行
+func TestEvaluator_BenchPrograms(t *testing.T) {
+	for _, program := range benchprog.Load(t) {
+		t.Run(program.Name, func(t *testing.T) {
+			if err := evalProgram(program.File, program.Source); err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+		})
+	}
+}
+
+func BenchmarkEvalFib25(b *testing.B) {
+	benchprog.Run(b, []*benchprog.Program{benchprog.Get(b, "fib")}, evalProgram)
+}
+
+func BenchmarkEvalPrograms(b *testing.B) {
+	benchprog.Run(b, benchprog.Load(b), evalProgram)
+}
@@ -3,7 +3,9 @@ package evaluator
 import (
 	"strconv"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
 
@@ -12,6 +14,7 @@ import (
 // 拥有完整的错误跟踪和格式化能力
 
 type VariableError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -29,17 +32,17 @@ func (e *VariableError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -53,11 +56,58 @@ func (e *VariableError) Error() string {
 	return res
 }
 
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Variable Error"
+func (e *VariableError) ErrorKind() string {
+	return "Variable Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *VariableError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *VariableError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *VariableError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *VariableError) Frames() *frame.Frame {
+	return e.Frame
+}
+
 // TypeError 类型错误类型，表示类型相关的运行时错误
 // 例如访问类型不匹配等
 // 拥有完整的错误跟踪和格式化能力
 
 type TypeError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -75,17 +125,17 @@ func (e *TypeError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -99,11 +149,58 @@ func (e *TypeError) Error() string {
 	return res
 }
 
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Type Error"
+func (e *TypeError) ErrorKind() string {
+	return "Type Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *TypeError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *TypeError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *TypeError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *TypeError) Frames() *frame.Frame {
+	return e.Frame
+}
+
 // SyntaxError 语法错误类型，表示语法相关的运行时错误
 // 例如缺少括号等
 // 拥有完整的错误跟踪和格式化能力
 
 type SyntaxError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -121,17 +218,17 @@ func (e *SyntaxError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -145,11 +242,58 @@ func (e *SyntaxError) Error() string {
 	return res
 }
 
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Syntax Error"
+func (e *SyntaxError) ErrorKind() string {
+	return "Syntax Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *SyntaxError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *SyntaxError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *SyntaxError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *SyntaxError) Frames() *frame.Frame {
+	return e.Frame
+}
+
 // ArgumentError 参数错误类型，表示参数相关的运行时错误
 // 例如参数数量不匹配等
 // 拥有完整的错误跟踪和格式化能力
 
 type ArgumentError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -167,17 +311,17 @@ func (e *ArgumentError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -190,3 +334,557 @@ func (e *ArgumentError) Error() string {
 	}
 	return res
 }
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Argument Error"
+func (e *ArgumentError) ErrorKind() string {
+	return "Argument Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *ArgumentError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *ArgumentError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *ArgumentError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *ArgumentError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// RecursionError 递归错误类型，表示嵌套深度超出限制的运行时错误
+// 例如eval中无限嵌套调用eval等
+// 拥有完整的错误跟踪和格式化能力
+
+type RecursionError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本
+	PosStart *util.Pos    // 错误起始位置
+	PosEnd   *util.Pos    // 错误结束位置
+}
+
+// Error 生成格式化的递归错误信息字符串
+// 前缀为"Recursion Error"
+//
+// 返回值:
+//
+//	string - 格式化的递归错误信息，格式同基础Error但错误类型为"Recursion Error"
+func (e *RecursionError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Recursion Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Recursion Error"
+func (e *RecursionError) ErrorKind() string {
+	return "Recursion Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *RecursionError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *RecursionError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *RecursionError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *RecursionError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// ResourceLimit 资源限制的种类
+
+type ResourceLimit int
+
+// 资源限制的种类
+
+const (
+	StepLimit  ResourceLimit = iota // 执行的节点数
+	DepthLimit                      // 函数调用的嵌套深度
+	AllocLimit                      // 单个字符串或列表的大小
+	TimeLimit                       // 执行时间
+)
+
+// resourceCodes 资源限制的种类对应的错误代码
+var resourceCodes = map[ResourceLimit]string{
+	StepLimit:  errcode.StepLimit,
+	DepthLimit: errcode.DepthLimit,
+	AllocLimit: errcode.AllocLimit,
+	TimeLimit:  errcode.Timeout,
+}
+
+// ResourceError 资源错误类型，表示执行超出了Limits设置的资源限制
+// 按超出的限制种类，错误类型分别为"Step Limit Error"、"Depth Limit Error"、"Alloc Limit Error"和"Timeout Error"
+// 拥有完整的错误跟踪和格式化能力
+
+type ResourceError struct {
+	Code     string        // 错误代码，见errcode包
+	Frame    *frame.Frame  // 错误发生时的调用栈
+	Message  string        // 错误描述文本
+	PosStart *util.Pos     // 错误起始位置
+	PosEnd   *util.Pos     // 错误结束位置
+	Limit    ResourceLimit // 超出的限制种类
+}
+
+// Kind 返回超出的限制种类对应的错误类型
+//
+// 返回值:
+//
+//	string - 错误类型
+func (e *ResourceError) Kind() string {
+	switch e.Limit {
+	case StepLimit:
+		return "Step Limit Error"
+	case DepthLimit:
+		return "Depth Limit Error"
+	case TimeLimit:
+		return "Timeout Error"
+	default:
+		return "Alloc Limit Error"
+	}
+}
+
+// Error 生成格式化的资源错误信息字符串
+// 前缀为Kind返回的错误类型
+//
+// 返回值:
+//
+//	string - 格式化的资源错误信息，格式同基础Error但错误类型为Kind的返回值
+func (e *ResourceError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += e.Kind()
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，见Kind
+func (e *ResourceError) ErrorKind() string {
+	return e.Kind()
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *ResourceError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *ResourceError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *ResourceError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *ResourceError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// ImportError 导入错误类型，表示导入其他文件时发生的运行时错误
+// 例如文件不存在、循环导入、导入的名称冲突等
+// 拥有完整的错误跟踪和格式化能力
+
+type ImportError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本
+	PosStart *util.Pos    // 错误起始位置
+	PosEnd   *util.Pos    // 错误结束位置
+}
+
+// Error 生成格式化的导入错误信息字符串
+// 前缀为"Import Error"
+//
+// 返回值:
+//
+//	string - 格式化的导入错误信息，格式同基础Error但错误类型为"Import Error"
+func (e *ImportError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Import Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Import Error"
+func (e *ImportError) ErrorKind() string {
+	return "Import Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *ImportError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *ImportError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *ImportError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *ImportError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// ThrownError 抛出错误类型，表示throw语句抛出的值
+// 可以被try语句捕获，未被捕获时与其他运行时错误一样中止程序
+// 拥有完整的错误跟踪和格式化能力
+
+type ThrownError struct {
+	Code     string        // 错误代码，见errcode包
+	Frame    *frame.Frame  // 错误发生时的调用栈
+	Message  string        // 错误描述文本，为抛出的值的字符串表示
+	PosStart *util.Pos     // 错误起始位置
+	PosEnd   *util.Pos     // 错误结束位置
+	Value    object.Object // 抛出的值
+}
+
+// Error 生成格式化的抛出错误信息字符串
+// 前缀为"Thrown Error"
+//
+// 返回值:
+//
+//	string - 格式化的抛出错误信息，格式同基础Error但错误类型为"Thrown Error"
+func (e *ThrownError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Thrown Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Thrown Error"
+func (e *ThrownError) ErrorKind() string {
+	return "Thrown Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *ThrownError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *ThrownError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *ThrownError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *ThrownError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// InternalError 内部错误类型，表示执行时解释器自身发生了panic
+// 通常意味着解释器的缺陷，panic被恢复后转换为该错误，而不是使进程崩溃
+// 拥有完整的错误跟踪和格式化能力
+
+type InternalError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本，包含panic的值
+	PosStart *util.Pos    // 发生panic时正在执行的节点的起始位置，未知时为nil
+	PosEnd   *util.Pos    // 发生panic时正在执行的节点的结束位置，未知时为nil
+	Value    any          // panic的值
+	Stack    string       // 发生panic时的Go调用栈，用于报告解释器的缺陷
+}
+
+// Error 生成格式化的内部错误信息字符串
+// 前缀为"Internal Error"，不包含Go调用栈
+//
+// 返回值:
+//
+//	string - 格式化的内部错误信息，格式同基础Error但错误类型为"Internal Error"
+func (e *InternalError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Internal Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Internal Error"
+func (e *InternalError) ErrorKind() string {
+	return "Internal Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *InternalError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *InternalError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *InternalError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *InternalError) Frames() *frame.Frame {
+	return e.Frame
+}
@@ -1,11 +1,22 @@
 package evaluator
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
 	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
 	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -33,10 +44,51 @@ type indexable interface {
 // 包含一个错误字段用于捕获和传递运行时错误
 
 type Evaluator struct {
-	Frame *frame.Frame // 调用栈帧
-	Err   error        // 运行时错误信息
+	Frame     *frame.Frame                   // 调用栈帧
+	Err       error                          // 运行时错误信息
+	File      string                         // 当前执行文件的绝对路径，用于解析相对导入路径，为空时相对于工作目录
+	Strict    bool                           // 严格数值模式，运算符的两个操作数分别为整数和浮点数时产生类型错误而不是提升为浮点数
+	Hooks     Hooks                          // 跟踪执行过程的回调，用于构建调试器、性能分析和覆盖率工具
+	Stdout    io.Writer                      // 内置函数的标准输出，为nil时使用平台默认的标准输出
+	Stderr    io.Writer                      // 内置函数的标准错误输出，为nil时使用平台默认的标准错误输出
+	Sandbox   bool                           // 沙箱模式，只能导入标准库模块，带有能力标记的内置函数需通过object.Environment.Sandbox另行禁用
+	Limits    Limits                         // 执行资源限制，默认不限制
+	steps     int64                          // 已执行的节点数，只在设置了Limits.MaxSteps或Limits.Timeout时计数
+	deadline  time.Time                      // 超出Limits.Timeout的时刻，在第一次计数时确定
+	callDepth int                            // 当前用户定义函数调用的嵌套深度
+	evalDepth int                            // 当前eval的嵌套深度
+	loopDepth int                            // 当前函数、模块或eval中正在执行的循环体的嵌套深度，为0时不能执行break和continue语句
+	current   ast.Node                       // 最近开始执行的节点，用于定位panic
+	guarded   bool                           // 是否在最外层的Eval中，panic会被恢复为InternalError
+	modules   map[string]*object.Environment // 已导入模块的顶层环境，按绝对路径缓存
+	importing map[string]bool                // 正在导入的模块，用于检测循环导入
+	sources   map[string]bool                // 执行过程中读取过的模块文件，包括加载失败的文件和找不到模块时尝试过的文件
+	entry     string                         // 入口脚本的路径，按名称导入时在其所在目录的ghost_modules中查找
 }
 
+// Hooks 跟踪执行过程的回调，未设置的回调不会被调用，也不产生额外开销
+
+type Hooks struct {
+	OnEnterNode func(node ast.Node, posStart, posEnd *util.Pos)              // 执行每个节点之前调用
+	OnExitCall  func(call *ast.CallExpression, posStart, posEnd *util.Pos)   // 函数调用表达式执行结束后调用，调用出错时同样会调用
+	OnStatement func(statement ast.Statement, env *object.Environment) error // 执行每条语句之前调用，env为语句的执行环境，返回错误时中止执行并将其作为运行时错误
+}
+
+// Limits 执行资源限制，为0的字段表示不限制
+
+type Limits struct {
+	MaxSteps int64         // 最多执行的节点数，累计到ResetSteps被调用为止
+	MaxDepth int           // 用户定义函数调用的最大嵌套深度
+	MaxAlloc int64         // 单个字符串或列表的最大字节数，字符串按UTF-8字节数计算，列表按元素个数乘以object.ListElementSize计算
+	Timeout  time.Duration // 最长执行时间，从执行第一个节点开始计算，累计到ResetSteps被调用为止
+}
+
+// maxEvalDepth eval允许的最大嵌套深度
+const maxEvalDepth = 100
+
+// timeoutCheckInterval 每执行多少个节点检查一次是否超出Limits.Timeout，避免每个节点都读取时钟
+const timeoutCheckInterval = 1024
+
 // NewEvaluator 创建一个新的解释器实例
 //
 // 参数：
@@ -48,12 +100,32 @@ type Evaluator struct {
 //	*Evaluator - 初始化后的解释器指针
 func NewEvaluator(frame *frame.Frame) *Evaluator {
 	return &Evaluator{
-		Frame: frame,
-		Err:   nil,
+		Frame:     frame,
+		Err:       nil,
+		modules:   make(map[string]*object.Environment),
+		importing: make(map[string]bool),
+		sources:   make(map[string]bool),
 	}
 }
 
+// Sources 返回执行过程中读取过的所有模块文件的绝对路径
+// 包括加载失败的文件和找不到模块时尝试过的文件，不包括标准库模块，按字典序排列
+//
+// 返回值:
+//
+//	[]string - 模块文件的绝对路径
+func (e *Evaluator) Sources() []string {
+	return slices.Sorted(maps.Keys(e.sources))
+}
+
+// ResetSteps 将已执行的节点数清零并重新开始计算执行时间，每次执行新的程序或REPL输入之前调用
+func (e *Evaluator) ResetSteps() {
+	e.steps = 0
+	e.deadline = time.Time{}
+}
+
 // Eval 根据节点类型调用相应的访问方法
+// 最外层的Eval将执行过程中的panic转换为InternalError
 //
 // 参数:
 //
@@ -64,16 +136,40 @@ func NewEvaluator(frame *frame.Frame) *Evaluator {
 //
 //	object.Object - 节点执行结果值，发生错误时为nil
 func (e *Evaluator) Eval(nodes ast.Node, env *object.Environment) object.Object {
+	if !e.guarded {
+		return e.guardedEval(nodes, env)
+	}
+	e.current = nodes
+	if (e.Limits.MaxSteps > 0 || e.Limits.Timeout > 0) && !e.step(nodes) {
+		return nil
+	}
+	if !e.enterNode(nodes, env) {
+		return nil
+	}
 	// 根据节点类型分发到对应的处理方法
 	switch n := nodes.(type) {
 	case *ast.Program:
 		return e.evalProgram(n, env)
 	case *ast.ForStatement:
 		return e.evalForStatement(n, env)
+	case *ast.ForInStatement:
+		return e.evalForInStatement(n, env)
+	case *ast.WhileStatement:
+		return e.evalWhileStatement(n, env)
 	case *ast.FunctionDeclarationStatement:
 		return e.evalFunctionDeclarationStatement(n, env)
 	case *ast.ReturnStatement:
 		return e.evalReturnStatement(n, env)
+	case *ast.BreakStatement:
+		return e.evalBreakStatement(n, env)
+	case *ast.ContinueStatement:
+		return e.evalContinueStatement(n, env)
+	case *ast.TryStatement:
+		return e.evalTryStatement(n, env)
+	case *ast.ThrowStatement:
+		return e.evalThrowStatement(n, env)
+	case *ast.ImportStatement:
+		return e.evalImportStatement(n, env)
 	case *ast.ExpressionStatement:
 		return e.evalExpressionStatement(n, env)
 	case *ast.PrefixExpression:
@@ -90,8 +186,12 @@ func (e *Evaluator) Eval(nodes ast.Node, env *object.Environment) object.Object
 		return e.evalNullExpression(n, env)
 	case *ast.StringExpression:
 		return e.evalStringExpression(n, env)
+	case *ast.InterpolatedStringExpression:
+		return e.evalInterpolatedStringExpression(n, env)
 	case *ast.ListExpression:
 		return e.evalListExpression(n, env)
+	case *ast.MapExpression:
+		return e.evalMapExpression(n, env)
 	case *ast.IdentifierExpression:
 		return e.evalIdentifierExpression(n, env)
 	case *ast.GroupedExpression:
@@ -110,13 +210,171 @@ func (e *Evaluator) Eval(nodes ast.Node, env *object.Environment) object.Object
 		return e.evalBlockExpression(n, env)
 	case *ast.IfExpression:
 		return e.evalIfExpression(n, env)
+	case *ast.TernaryExpression:
+		return e.evalTernaryExpression(n, env)
+	case *ast.FunctionExpression:
+		return e.evalFunctionExpression(n, env)
 	case *ast.CallExpression:
-		return e.evalCallExpression(n, env)
+		result := e.evalCallExpression(n, env)
+		if e.Hooks.OnExitCall != nil {
+			e.Hooks.OnExitCall(n, n.PosStart, n.PosEnd)
+		}
+		return result
 	case *ast.IndexExpression:
 		return e.evalIndexExpression(n, env)
+	case *ast.SliceExpression:
+		return e.evalSliceExpression(n, env)
 	default:
-		panic(fmt.Sprintf("unknown node type: %T", n))
+		posStart, posEnd := nodeSpan(n)
+		e.Err = &InternalError{
+			Code:     errcode.InternalError,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("unknown node type %T.", n),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+		return nil
+	}
+}
+
+// enterNode 在执行节点之前调用OnEnterNode回调，节点为语句时再调用OnStatement回调，未设置回调时直接返回
+//
+// 参数:
+//
+//	node - 即将执行的节点
+//	env - 节点的执行环境
+//
+// 返回值:
+//
+//	bool - 是否可以继续执行，OnStatement返回错误时为false
+func (e *Evaluator) enterNode(node ast.Node, env *object.Environment) bool {
+	if e.Hooks.OnEnterNode != nil {
+		posStart, posEnd := ast.Span(node)
+		e.Hooks.OnEnterNode(node, posStart, posEnd)
+	}
+	if e.Hooks.OnStatement != nil {
+		if statement, ok := node.(ast.Statement); ok {
+			if err := e.Hooks.OnStatement(statement, env); err != nil {
+				e.Err = err
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// step 累计执行的节点数，超出Limits.MaxSteps或Limits.Timeout时设置资源错误
+//
+// 参数:
+//
+//	node - 即将执行的节点
+//
+// 返回值:
+//
+//	bool - 是否可以继续执行
+func (e *Evaluator) step(node ast.Node) bool {
+	e.steps++
+	if e.Limits.MaxSteps > 0 && e.steps > e.Limits.MaxSteps {
+		e.Err = e.resourceError(node, fmt.Sprintf("maximum of %d steps exceeded.", e.Limits.MaxSteps), StepLimit)
+		return false
+	}
+	if e.Limits.Timeout > 0 {
+		if e.deadline.IsZero() {
+			e.deadline = time.Now().Add(e.Limits.Timeout)
+		} else if e.steps%timeoutCheckInterval == 0 && time.Now().After(e.deadline) {
+			e.Err = e.resourceError(node, fmt.Sprintf("execution time limit of %s exceeded.", e.Limits.Timeout), TimeLimit)
+			return false
+		}
+	}
+	return true
+}
+
+// resourceError 创建在节点处超出资源限制的错误
+//
+// 参数:
+//
+//	node - 超出限制时即将执行的节点
+//	message - 错误描述
+//	limit - 超出的限制种类
+//
+// 返回值:
+//
+//	*ResourceError - 资源错误
+func (e *Evaluator) resourceError(node ast.Node, message string, limit ResourceLimit) *ResourceError {
+	posStart, posEnd := ast.Span(node)
+	return &ResourceError{
+		Code:     resourceCodes[limit],
+		Frame:    e.Frame,
+		Message:  message,
+		PosStart: posStart,
+		PosEnd:   posEnd,
+		Limit:    limit,
+	}
+}
+
+// allocError 检查即将创建的字符串或列表的大小是否超出Limits.MaxAlloc
+//
+// 参数:
+//
+//	size - 字节数
+//	posStart - 创建位置的起始位置
+//	posEnd - 创建位置的结束位置
+//
+// 返回值:
+//
+//	error - 超出限制时的资源错误，否则为nil
+func (e *Evaluator) allocError(size int64, posStart, posEnd *util.Pos) error {
+	if e.Limits.MaxAlloc <= 0 || size <= e.Limits.MaxAlloc {
+		return nil
+	}
+	return &ResourceError{
+		Code:     errcode.AllocLimit,
+		Frame:    e.Frame,
+		Message:  fmt.Sprintf("allocation of %d bytes exceeds the limit of %d bytes.", size, e.Limits.MaxAlloc),
+		PosStart: posStart,
+		PosEnd:   posEnd,
+		Limit:    AllocLimit,
+	}
+}
+
+// operatorAllocSize 估算拼接或重复运算结果的字节数，在执行运算之前检查大小限制
+//
+// 参数:
+//
+//	operator - 运算符类型
+//	left - 左操作数
+//	right - 右操作数
+//
+// 返回值:
+//
+//	int64 - 结果的字节数，溢出时为math.MaxInt64，不产生字符串或列表的运算为0
+func operatorAllocSize(operator lexer.TokenType, left, right object.Object) int64 {
+	switch operator {
+	case lexer.PLUS:
+		_, isLeftString := left.(*object.String)
+		_, isRightString := right.(*object.String)
+		_, isLeftList := left.(*object.List)
+		_, isRightList := right.(*object.List)
+		if isLeftString && isRightString || isLeftList && isRightList {
+			return object.AllocSize(left) + object.AllocSize(right)
+		}
+	case lexer.ASTERISK:
+		// 重复次数可以在运算符的任意一侧
+		times, ok := right.(*object.Int)
+		if !ok {
+			times, ok = left.(*object.Int)
+			left = right
+		}
+		if !ok || times.Value <= 0 {
+			return 0
+		}
+		size := object.AllocSize(left)
+		if size > 0 && times.Value > math.MaxInt64/size {
+			return math.MaxInt64
+		}
+		return size * times.Value
 	}
+	return 0
 }
 
 // evalProgram 处理程序节点，依次执行所有语句
@@ -153,13 +411,14 @@ func (e *Evaluator) evalProgram(program *ast.Program, env *object.Environment) o
 //
 // 返回值:
 //
-//	object.Object - 始终返回nil
+//	object.Object - 循环体中return语句的返回值，否则返回nil；break语句结束循环时同样返回nil
+//
+// 特殊处理：
+//
+// - continue语句跳过循环体中剩余的语句，之后仍然执行更新语句并重新评估条件
 func (e *Evaluator) evalForStatement(forStatement *ast.ForStatement, env *object.Environment) object.Object {
 	// 创建新环境
-	forEnv := &object.Environment{
-		Store: make(map[string]*object.Symbol),
-		Outer: env,
-	}
+	forEnv := object.NewEnvironment(env, forStatement.Layout)
 	// 执行初始化语句
 	e.Eval(forStatement.Initialization, forEnv)
 	if e.Err != nil {
@@ -173,6 +432,7 @@ func (e *Evaluator) evalForStatement(forStatement *ast.ForStatement, env *object
 	// 判断是不是布尔值
 	if _, ok := condition.(*object.Bool); !ok {
 		e.Err = &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    e.Frame,
 			Message:  "non-bool condition in for loop.",
 			PosStart: forStatement.PosStart,
@@ -183,13 +443,19 @@ func (e *Evaluator) evalForStatement(forStatement *ast.ForStatement, env *object
 	// 执行循环体
 	for condition.(*object.Bool).Value {
 		// 执行循环体
+		e.loopDepth++
 		ret := e.Eval(forStatement.Body, forEnv)
+		e.loopDepth--
 		if e.Err != nil {
 			return nil
 		}
 		if returnValue, ok := ret.(*object.ReturnValue); ok {
 			return returnValue
 		}
+		// continue信号不需要处理，继续执行更新语句
+		if ret == object.BreakObj {
+			return nil
+		}
 		// 执行更新语句
 		e.Eval(forStatement.Update, forEnv)
 		if e.Err != nil {
@@ -203,6 +469,7 @@ func (e *Evaluator) evalForStatement(forStatement *ast.ForStatement, env *object
 		// 判断是不是布尔值
 		if _, ok := condition.(*object.Bool); !ok {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "non-bool condition in for loop.",
 				PosStart: forStatement.PosStart,
@@ -214,6 +481,136 @@ func (e *Evaluator) evalForStatement(forStatement *ast.ForStatement, env *object
 	return nil
 }
 
+// evalForInStatement 处理for-in语句节点
+// 依次取出列表的元素、字符串的字符、映射的键或迭代器的值，每次迭代在新环境中绑定循环变量并执行循环体
+// 有两个循环变量时被遍历的值必须是映射，每次迭代同时绑定键和对应的值
+//
+// 参数:
+//
+//	forInStatement - for-in语句节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 循环体中return语句的返回值，否则返回nil；break语句结束循环时同样返回nil
+func (e *Evaluator) evalForInStatement(forInStatement *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := e.Eval(forInStatement.Iterable, env)
+	if e.Err != nil {
+		return nil
+	}
+	m, isMap := iterable.(*object.Map)
+	if forInStatement.ValueVariable != nil && !isMap {
+		posStart, posEnd := ast.Span(forInStatement.Iterable)
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("two loop variables require a Map, got %s.", iterable.Type()),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+		return nil
+	}
+	iter, ok := object.Iterate(iterable)
+	if !ok {
+		posStart, posEnd := ast.Span(forInStatement.Iterable)
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("cannot iterate over %s.", iterable.Type()),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+		return nil
+	}
+	for {
+		value, ok, err := iter.Next()
+		if err != nil {
+			e.Err = err
+			return nil
+		}
+		if !ok {
+			return nil
+		}
+		// 创建本次迭代的环境并绑定循环变量
+		loopEnv := object.NewEnvironment(env, forInStatement.Layout)
+		loopEnv.Define(forInStatement.Variable, &object.Symbol{
+			Name:  forInStatement.Variable.Name,
+			Value: value,
+		})
+		if forInStatement.ValueVariable != nil {
+			mapValue, _ := m.Get(value)
+			loopEnv.Define(forInStatement.ValueVariable, &object.Symbol{
+				Name:  forInStatement.ValueVariable.Name,
+				Value: mapValue,
+			})
+		}
+		// 执行循环体
+		e.loopDepth++
+		ret := e.Eval(forInStatement.Body, loopEnv)
+		e.loopDepth--
+		if e.Err != nil {
+			return nil
+		}
+		if returnValue, ok := ret.(*object.ReturnValue); ok {
+			return returnValue
+		}
+		if ret == object.BreakObj {
+			return nil
+		}
+	}
+}
+
+// evalWhileStatement 处理while语句节点
+// 每次迭代在新环境中重新评估条件并执行循环体，循环体中声明的变量不会保留到下一次迭代
+//
+// 参数:
+//
+//	whileStatement - while语句节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 循环体中return语句的返回值，否则返回nil；break语句结束循环时同样返回nil
+func (e *Evaluator) evalWhileStatement(whileStatement *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		// 创建本次迭代的环境
+		loopEnv := object.NewEnvironment(env, whileStatement.Layout)
+		// 评估条件表达式
+		condition := e.Eval(whileStatement.Condition, loopEnv)
+		if e.Err != nil {
+			return nil
+		}
+		// 判断是不是布尔值
+		b, ok := condition.(*object.Bool)
+		if !ok {
+			e.Err = &TypeError{
+				Code:     errcode.TypeError,
+				Frame:    e.Frame,
+				Message:  "non-bool condition in while loop.",
+				PosStart: whileStatement.PosStart,
+				PosEnd:   whileStatement.PosEnd,
+			}
+			return nil
+		}
+		if !b.Value {
+			return nil
+		}
+		// 执行循环体
+		e.loopDepth++
+		ret := e.Eval(whileStatement.Body, loopEnv)
+		e.loopDepth--
+		if e.Err != nil {
+			return nil
+		}
+		if returnValue, ok := ret.(*object.ReturnValue); ok {
+			return returnValue
+		}
+		if ret == object.BreakObj {
+			return nil
+		}
+	}
+}
+
 // evalFunctionDeclarationStatement 处理函数声明语句节点
 // 解释函数表达式
 //
@@ -227,10 +624,12 @@ func (e *Evaluator) evalForStatement(forStatement *ast.ForStatement, env *object
 //	object.Object - 函数表达式的结果，发生错误时返回nil
 func (e *Evaluator) evalFunctionDeclarationStatement(functionDeclarationStatement *ast.FunctionDeclarationStatement, env *object.Environment) object.Object {
 	// 函数名字
-	funcName := functionDeclarationStatement.Name.(*ast.IdentifierExpression).Name
+	name := functionDeclarationStatement.Name.(*ast.IdentifierExpression)
+	funcName := name.Name
 	// 是否已定义过函数
 	if _, ok := env.Get(funcName); ok {
 		e.Err = &VariableError{
+			Code:     errcode.VariableError,
 			Frame:    e.Frame,
 			Message:  fmt.Sprintf("function \"%s\" already defined.", funcName),
 			PosStart: functionDeclarationStatement.PosStart,
@@ -243,10 +642,11 @@ func (e *Evaluator) evalFunctionDeclarationStatement(functionDeclarationStatemen
 		Name:      funcName,
 		Parameter: functionDeclarationStatement.Parameter,
 		Body:      functionDeclarationStatement.Body,
+		Layout:    functionDeclarationStatement.Layout,
 		Env:       env,
 	}
 	// 绑定函数
-	env.Set(funcName, &object.Symbol{
+	env.Define(name, &object.Symbol{
 		Name:    funcName,
 		Value:   fn,
 		IsConst: true,
@@ -254,6 +654,26 @@ func (e *Evaluator) evalFunctionDeclarationStatement(functionDeclarationStatemen
 	return nil
 }
 
+// evalFunctionExpression 处理匿名函数表达式节点
+// 创建没有函数名的函数，函数捕获当前环境
+//
+// 参数:
+//
+//	functionExpression - 函数表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 新的函数
+func (e *Evaluator) evalFunctionExpression(functionExpression *ast.FunctionExpression, env *object.Environment) object.Object {
+	return &object.Function{
+		Parameter: functionExpression.Parameter,
+		Body:      functionExpression.Body,
+		Layout:    functionExpression.Layout,
+		Env:       env,
+	}
+}
+
 // evalReturnStatement 处理return语句节点
 // 执行return语句，返回值
 //
@@ -268,6 +688,7 @@ func (e *Evaluator) evalFunctionDeclarationStatement(functionDeclarationStatemen
 func (e *Evaluator) evalReturnStatement(returnStatement *ast.ReturnStatement, env *object.Environment) object.Object {
 	if e.Frame.Parent == nil {
 		e.Err = &SyntaxError{
+			Code:     errcode.SyntaxError,
 			Frame:    e.Frame,
 			Message:  "return statement is only allowed inside functions.",
 			PosStart: returnStatement.PosStart,
@@ -285,142 +706,588 @@ func (e *Evaluator) evalReturnStatement(returnStatement *ast.ReturnStatement, en
 	}
 }
 
-// evalIndexExpression 处理索引表达式节点
-// 执行索引表达式
+// evalBreakStatement 处理break语句节点
+// 返回break信号，由块和if表达式向外传递，直到最近的循环语句结束循环
 //
 // 参数:
 //
-//	indexExpression - 索引表达式节点
+//	breakStatement - break语句节点
 //	env - 执行环境
 //
 // 返回值:
 //
-//	object.Object
-func (e *Evaluator) evalIndexExpression(indexExpression *ast.IndexExpression, env *object.Environment) object.Object {
-	target := e.Eval(indexExpression.Target, env)
-	if e.Err != nil {
-		return nil
-	}
-	idxObj := e.Eval(indexExpression.Index, env)
-	if e.Err != nil {
-		return nil
-	}
-	// 判断索引是否是整数
-	intIdx, ok := idxObj.(*object.Int)
-	if !ok {
-		e.Err = &TypeError{
+//	object.Object - break信号，不在循环体中时返回nil
+func (e *Evaluator) evalBreakStatement(breakStatement *ast.BreakStatement, _ *object.Environment) object.Object {
+	if e.loopDepth == 0 {
+		e.Err = &SyntaxError{
+			Code:     errcode.SyntaxError,
 			Frame:    e.Frame,
-			Message:  "index must be integer.",
-			PosStart: indexExpression.PosStart,
-			PosEnd:   indexExpression.PosEnd,
+			Message:  "break statement is only allowed inside loops.",
+			PosStart: breakStatement.PosStart,
+			PosEnd:   breakStatement.PosEnd,
 		}
 		return nil
 	}
-	ret, err := target.Index(intIdx, indexExpression.PosStart, indexExpression.PosEnd, e.Frame)
-	if err != nil {
-		e.Err = err
-		return nil
-	}
-	return ret
+	return object.BreakObj
 }
 
-// evalExpressionStatement 处理表达式语句节点
-// 执行表达式并忽略其返回值
+// evalContinueStatement 处理continue语句节点
+// 返回continue信号，由块和if表达式向外传递，直到最近的循环语句开始下一次迭代
 //
 // 参数:
 //
-//	expressionStatement - 表达式语句节点
+//	continueStatement - continue语句节点
 //	env - 执行环境
 //
 // 返回值:
 //
-//	object.Object
-func (e *Evaluator) evalExpressionStatement(expressionStatement *ast.ExpressionStatement, env *object.Environment) object.Object {
-	ret := e.Eval(expressionStatement.Expr, env)
-	if e.Err != nil {
+//	object.Object - continue信号，不在循环体中时返回nil
+func (e *Evaluator) evalContinueStatement(continueStatement *ast.ContinueStatement, _ *object.Environment) object.Object {
+	if e.loopDepth == 0 {
+		e.Err = &SyntaxError{
+			Code:     errcode.SyntaxError,
+			Frame:    e.Frame,
+			Message:  "continue statement is only allowed inside loops.",
+			PosStart: continueStatement.PosStart,
+			PosEnd:   continueStatement.PosEnd,
+		}
 		return nil
 	}
-	if returnValue, ok := ret.(*object.ReturnValue); ok {
-		return returnValue
-	}
-	return nil
+	return object.ContinueObj
 }
 
-// evalIntExpression 处理整数表达式节点
-// 将AST整数节点转换为运行时整数值
+// isLoopSignal 判断语句的结果是否为需要传递给最近的循环语句的break或continue信号
 //
 // 参数:
 //
-//	numberExpression - 整数表达式节点
-//	env - 执行环境
+//	ret - 语句的结果
 //
 // 返回值:
 //
-//	object.Object - 包含整数值的value.Int实例
-func (e *Evaluator) evalIntExpression(numberExpression *ast.IntExpression, _ *object.Environment) object.Object {
-	return &object.Int{Value: numberExpression.Value}
+//	bool - 是否为break或continue信号
+func isLoopSignal(ret object.Object) bool {
+	return ret == object.BreakObj || ret == object.ContinueObj
 }
 
-// evalFloatExpression 处理浮点数表达式节点
-// 将AST浮点数节点转换为运行时浮点值
+// evalTryStatement 处理try语句节点
+// 在新环境中执行主体，主体中发生可捕获的错误时清除错误，恢复调用栈，
+// 在另一个新环境中将描述错误的映射绑定到catch子句的变量并执行处理语句
 //
 // 参数:
 //
-//	numberExpression - 浮点数表达式节点
+//	tryStatement - try语句节点
 //	env - 执行环境
 //
 // 返回值:
 //
-//	object.Object - 包含浮点值的value.Float实例
-func (e *Evaluator) evalFloatExpression(numberExpression *ast.FloatExpression, _ *object.Environment) object.Object {
-	return &object.Float{Value: numberExpression.Value}
+//	object.Object - 主体或处理语句中return语句的返回值或break、continue信号，否则返回nil
+func (e *Evaluator) evalTryStatement(tryStatement *ast.TryStatement, env *object.Environment) object.Object {
+	currFrame := e.Frame
+	ret := e.Eval(tryStatement.Body, object.NewEnvironment(env, tryStatement.Layout))
+	if e.Err != nil {
+		if !catchable(e.Err) {
+			return nil
+		}
+		caught := errorObject(e.Err)
+		e.Err = nil
+		// 出错时调用栈停留在出错的函数中，回到try语句所在的函数
+		e.Frame = currFrame
+		catch := tryStatement.Catch
+		catchEnv := object.NewEnvironment(env, catch.Layout)
+		catchEnv.Define(catch.Variable, &object.Symbol{
+			Name:  catch.Variable.Name,
+			Value: caught,
+		})
+		ret = e.Eval(catch.Body, catchEnv)
+		if e.Err != nil {
+			return nil
+		}
+	}
+	if _, ok := ret.(*object.ReturnValue); ok || isLoopSignal(ret) {
+		return ret
+	}
+	return nil
 }
 
-// evalBooleanExpression 处理布尔表达式节点
-// 将AST布尔节点转换为运行时布尔值
+// catchable 判断运行时错误能否被try语句捕获
+// 超出资源限制、调用exit、沙箱禁止的操作、解释器内部错误和运行时发现的语法错误不能被捕获，
+// 调试器等回调返回的错误同样不能被捕获
 //
 // 参数:
 //
-//	booleanExpression - 布尔表达式节点
-//	env - 执行环境
+//	err - 运行时错误
 //
 // 返回值:
 //
-//	object.Object - 包含布尔值的value.Bool实例
-func (e *Evaluator) evalBooleanExpression(booleanExpression *ast.BoolExpression, _ *object.Environment) object.Object {
-	return &object.Bool{Value: booleanExpression.Value}
+//	bool - 能否被捕获
+func catchable(err error) bool {
+	switch err.(type) {
+	case *VariableError, *TypeError, *ArgumentError, *RecursionError, *ImportError, *ThrownError:
+		return true
+	case *object.OperationError, *object.MathError, *object.TypeError, *object.IndexError,
+		*object.ValueError, *object.AssertionError, *object.HostError, *object.ParseError:
+		return true
+	default:
+		return false
+	}
 }
 
-// evalNullExpression 处理空值表达式节点
-// 返回运行时空值
+// errorObject 创建描述被捕获的错误的映射，绑定到catch子句的变量
+// 映射的键依次为kind、code、message和value，value为throw语句抛出的值，其他错误为null
 //
 // 参数:
 //
-//	_ - 空值表达式节点(未使用)
-//	env - 执行环境
+//	err - 可捕获的运行时错误
 //
 // 返回值:
 //
-//	object.Object - 空值value.Null实例
-func (e *Evaluator) evalNullExpression(_ *ast.NullExpression, _ *object.Environment) object.Object {
-	return &object.Null{}
+//	*object.Map - 描述错误的映射
+func errorObject(err error) *object.Map {
+	described := err.(interface {
+		ErrorKind() string
+		ErrorCode() string
+		ErrorMessage() string
+	})
+	var value object.Object = object.NullObj
+	if thrown, ok := err.(*ThrownError); ok {
+		value = thrown.Value
+	}
+	m := object.NewMap()
+	// 键都是字符串，设置不会失败
+	_ = m.Set(&object.String{Value: "kind"}, &object.String{Value: described.ErrorKind()}, nil, nil, nil)
+	_ = m.Set(&object.String{Value: "code"}, &object.String{Value: described.ErrorCode()}, nil, nil, nil)
+	_ = m.Set(&object.String{Value: "message"}, &object.String{Value: described.ErrorMessage()}, nil, nil, nil)
+	_ = m.Set(&object.String{Value: "value"}, value, nil, nil, nil)
+	return m
 }
 
-// evalStringExpression 处理字符串表达式节点
-// 将AST字符串节点转换为运行时字符串值
+// evalThrowStatement 处理throw语句节点
+// 计算表达式的值并将其作为ThrownError抛出，错误描述为值的字符串表示
 //
 // 参数:
 //
-//	stringExpression - 字符串表达式节点
+//	throwStatement - throw语句节点
 //	env - 执行环境
 //
 // 返回值:
 //
-//	object.Object - 包含字符串值的value.String实例
-func (e *Evaluator) evalStringExpression(stringExpression *ast.StringExpression, _ *object.Environment) object.Object {
-	return &object.String{Value: stringExpression.Value}
-}
-
+//	object.Object - 总是返回nil
+func (e *Evaluator) evalThrowStatement(throwStatement *ast.ThrowStatement, env *object.Environment) object.Object {
+	value := e.Eval(throwStatement.Value, env)
+	if e.Err != nil {
+		return nil
+	}
+	e.Err = &ThrownError{
+		Code:     errcode.ThrownError,
+		Frame:    e.Frame,
+		Message:  value.String(),
+		PosStart: throwStatement.PosStart,
+		PosEnd:   throwStatement.PosEnd,
+		Value:    value,
+	}
+	return nil
+}
+
+// evalIndexExpression 处理索引表达式节点
+// 执行索引表达式
+//
+// 参数:
+//
+//	indexExpression - 索引表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object
+func (e *Evaluator) evalIndexExpression(indexExpression *ast.IndexExpression, env *object.Environment) object.Object {
+	target := e.Eval(indexExpression.Target, env)
+	if e.Err != nil {
+		return nil
+	}
+	idxObj := e.Eval(indexExpression.Index, env)
+	if e.Err != nil {
+		return nil
+	}
+	// 判断索引是否是整数，列表还可以使用整数列表作为索引，映射的键由映射检查
+	_, isInt := idxObj.(*object.Int)
+	_, isIndexList := idxObj.(*object.List)
+	_, isTargetList := target.(*object.List)
+	if !isInt && !(isIndexList && isTargetList) && intIndexRequired(target) {
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  "index must be integer.",
+			PosStart: indexExpression.PosStart,
+			PosEnd:   indexExpression.PosEnd,
+		}
+		return nil
+	}
+	ret, err := target.Index(idxObj, indexExpression.PosStart, indexExpression.PosEnd, e.Frame)
+	if err != nil {
+		e.Err = err
+		return nil
+	}
+	return ret
+}
+
+// evalSliceExpression 处理切片表达式节点
+// 截取列表或字符串中[low, high)范围内的元素，省略的边界分别为0和长度，负数索引从末尾开始计数，越界的索引被截断
+//
+// 参数:
+//
+//	sliceExpression - 切片表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 新的列表或字符串
+func (e *Evaluator) evalSliceExpression(sliceExpression *ast.SliceExpression, env *object.Environment) object.Object {
+	target := e.Eval(sliceExpression.Target, env)
+	if e.Err != nil {
+		return nil
+	}
+	var length int64
+	switch t := target.(type) {
+	case *object.List:
+		length = int64(len(t.Elements))
+	case *object.String:
+		length = int64(utf8.RuneCountInString(t.Value))
+	default:
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  "slice expression not supported for this type.",
+			PosStart: sliceExpression.PosStart,
+			PosEnd:   sliceExpression.PosEnd,
+		}
+		return nil
+	}
+	low := e.evalSliceBound(sliceExpression.Low, 0, env)
+	if e.Err != nil {
+		return nil
+	}
+	high := e.evalSliceBound(sliceExpression.High, length, env)
+	if e.Err != nil {
+		return nil
+	}
+	if list, ok := target.(*object.List); ok {
+		return list.Slice(low, high)
+	}
+	return target.(*object.String).Slice(low, high)
+}
+
+// evalSliceBound 计算切片表达式的一个边界
+//
+// 参数:
+//
+//	bound - 边界表达式，省略时为nil
+//	omitted - 省略边界时使用的值
+//	env - 执行环境
+//
+// 返回值:
+//
+//	int64 - 边界的值
+func (e *Evaluator) evalSliceBound(bound ast.Expression, omitted int64, env *object.Environment) int64 {
+	if bound == nil {
+		return omitted
+	}
+	value := e.Eval(bound, env)
+	if e.Err != nil {
+		return 0
+	}
+	i, ok := value.(*object.Int)
+	if !ok {
+		posStart, posEnd := ast.Span(bound)
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  "slice index must be integer.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+		return 0
+	}
+	return i.Value
+}
+
+// intIndexRequired 判断索引目标是否只接受整数索引，映射的键可以是其他可哈希的值
+//
+// 参数:
+//
+//	target - 索引目标
+//
+// 返回值:
+//
+//	bool - 目标不是映射时为true
+func intIndexRequired(target object.Object) bool {
+	_, isMap := target.(*object.Map)
+	return !isMap
+}
+
+// evalImportStatement 处理import语句节点
+// 按ResolveModule的规则查找模块，执行被导入的文件并将其顶层声明导入当前环境，同一文件只执行一次
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 始终返回nil
+func (e *Evaluator) evalImportStatement(importStatement *ast.ImportStatement, env *object.Environment) object.Object {
+	// 不在导入模块的过程中时，当前文件即为入口脚本
+	if len(e.importing) == 0 {
+		e.entry = e.File
+	}
+	var absPath string
+	if e.Sandbox {
+		// 沙箱模式下只能导入标准库模块，不访问文件系统
+		absPath = resolveStdlibModule(importStatement)
+		if absPath == "" {
+			e.Err = &object.SandboxError{
+				Code:     errcode.SandboxError,
+				Frame:    e.Frame,
+				Message:  fmt.Sprintf("importing \"%s\" is disabled in sandbox, only standard library modules are available.", importStatement.Spec()),
+				PosStart: importStatement.PosStart,
+				PosEnd:   importStatement.PosEnd,
+			}
+			return nil
+		}
+	} else {
+		var tried []string
+		absPath, tried = ResolveModule(importStatement, e.File, e.entry)
+		if absPath == "" {
+			// 记录尝试过的文件，监视模式下这些文件被创建后会重新执行
+			for _, path := range tried {
+				if !IsStdlibModule(path) {
+					e.sources[path] = true
+				}
+			}
+			e.Err = e.moduleNotFound(importStatement, tried)
+			return nil
+		}
+	}
+	if e.importing[absPath] {
+		e.Err = &ImportError{
+			Code:     errcode.ImportError,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("circular import of \"%s\".", importStatement.Spec()),
+			PosStart: importStatement.PosStart,
+			PosEnd:   importStatement.PosEnd,
+		}
+		return nil
+	}
+	moduleEnv, ok := e.modules[absPath]
+	if !ok {
+		moduleEnv = e.loadModule(absPath, importStatement, env)
+		if e.Err != nil {
+			return nil
+		}
+	}
+	// 按名称顺序导入顶层声明，保证冲突时的错误信息稳定
+	for _, name := range slices.Sorted(maps.Keys(moduleEnv.Store)) {
+		sym := moduleEnv.Store[name]
+		if existing, ok := env.Store[name]; ok && existing != sym {
+			e.Err = &ImportError{
+				Code:     errcode.ImportError,
+				Frame:    e.Frame,
+				Message:  fmt.Sprintf("\"%s\" imported from \"%s\" is already defined.", name, importStatement.Spec()),
+				PosStart: importStatement.PosStart,
+				PosEnd:   importStatement.PosEnd,
+			}
+			return nil
+		}
+		env.Set(name, sym)
+	}
+	return nil
+}
+
+// loadModule 读取、解析并执行被导入的文件，返回其顶层环境并加入缓存
+//
+// 参数:
+//
+//	absPath - 被导入文件的绝对路径
+//	importStatement - import语句节点
+//	env - 执行import语句的环境
+//
+// 返回值:
+//
+//	*object.Environment - 模块的顶层环境，发生错误时返回nil
+func (e *Evaluator) loadModule(absPath string, importStatement *ast.ImportStatement, env *object.Environment) *object.Environment {
+	if !IsStdlibModule(absPath) {
+		e.sources[absPath] = true
+	}
+	data, err := readModule(absPath)
+	if err != nil {
+		e.Err = &ImportError{
+			Code:     errcode.ImportError,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("cannot read module \"%s\".", importStatement.Spec()),
+			PosStart: importStatement.PosStart,
+			PosEnd:   importStatement.PosEnd,
+		}
+		return nil
+	}
+	e.Frame = &frame.Frame{
+		FuncName: fmt.Sprintf("<module \"%s\">", filepath.Base(absPath)),
+		Parent:   e.Frame,
+		PosStart: importStatement.PosStart,
+		PosEnd:   importStatement.PosEnd,
+	}
+	program, err := parseModule(absPath, data)
+	if err != nil {
+		e.Err = e.toSyntaxError(err)
+		return nil
+	}
+	// 模块的顶层环境位于全局环境之下，可以访问内置函数
+	moduleEnv := object.NewEnvironment(env.Global(), nil)
+	prevFile := e.File
+	prevLoopDepth := e.loopDepth
+	e.File = absPath
+	e.loopDepth = 0
+	e.importing[absPath] = true
+	e.Eval(program, moduleEnv)
+	delete(e.importing, absPath)
+	e.File = prevFile
+	e.loopDepth = prevLoopDepth
+	if e.Err != nil {
+		return nil
+	}
+	e.Frame = e.Frame.Parent
+	e.modules[absPath] = moduleEnv
+	return moduleEnv
+}
+
+// evalExpressionStatement 处理表达式语句节点
+// 执行表达式并忽略其返回值
+//
+// 参数:
+//
+//	expressionStatement - 表达式语句节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object
+func (e *Evaluator) evalExpressionStatement(expressionStatement *ast.ExpressionStatement, env *object.Environment) object.Object {
+	ret := e.Eval(expressionStatement.Expr, env)
+	if e.Err != nil {
+		return nil
+	}
+	if returnValue, ok := ret.(*object.ReturnValue); ok {
+		return returnValue
+	}
+	if isLoopSignal(ret) {
+		return ret
+	}
+	return nil
+}
+
+// evalIntExpression 处理整数表达式节点
+// 将AST整数节点转换为运行时整数值
+//
+// 参数:
+//
+//	numberExpression - 整数表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 包含整数值的value.Int实例
+func (e *Evaluator) evalIntExpression(numberExpression *ast.IntExpression, _ *object.Environment) object.Object {
+	return &object.Int{Value: numberExpression.Value}
+}
+
+// evalFloatExpression 处理浮点数表达式节点
+// 将AST浮点数节点转换为运行时浮点值
+//
+// 参数:
+//
+//	numberExpression - 浮点数表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 包含浮点值的value.Float实例
+func (e *Evaluator) evalFloatExpression(numberExpression *ast.FloatExpression, _ *object.Environment) object.Object {
+	return &object.Float{Value: numberExpression.Value}
+}
+
+// evalBooleanExpression 处理布尔表达式节点
+// 将AST布尔节点转换为运行时布尔值
+//
+// 参数:
+//
+//	booleanExpression - 布尔表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 包含布尔值的value.Bool实例
+func (e *Evaluator) evalBooleanExpression(booleanExpression *ast.BoolExpression, _ *object.Environment) object.Object {
+	return object.BoolOf(booleanExpression.Value)
+}
+
+// evalNullExpression 处理空值表达式节点
+// 返回运行时空值
+//
+// 参数:
+//
+//	_ - 空值表达式节点(未使用)
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 空值value.Null实例
+func (e *Evaluator) evalNullExpression(_ *ast.NullExpression, _ *object.Environment) object.Object {
+	return object.NullObj
+}
+
+// evalStringExpression 处理字符串表达式节点
+// 将AST字符串节点转换为运行时字符串值
+//
+// 参数:
+//
+//	stringExpression - 字符串表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 包含字符串值的value.String实例
+func (e *Evaluator) evalStringExpression(stringExpression *ast.StringExpression, _ *object.Environment) object.Object {
+	return &object.String{Value: stringExpression.Value}
+}
+
+// evalInterpolatedStringExpression 处理插值字符串表达式节点
+// 按顺序解释每个插值表达式，将它们的字符串表示与字符串片段依次拼接
+//
+// 参数:
+//
+//	interpolatedStringExpression - 插值字符串表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 拼接得到的字符串，错误时返回nil
+func (e *Evaluator) evalInterpolatedStringExpression(interpolatedStringExpression *ast.InterpolatedStringExpression, env *object.Environment) object.Object {
+	parts := make([]string, len(interpolatedStringExpression.Parts))
+	var size int64
+	for i, part := range interpolatedStringExpression.Parts {
+		val := e.Eval(part, env)
+		if e.Err != nil {
+			return nil
+		}
+		parts[i] = val.String()
+		size += int64(len(parts[i]))
+	}
+	if err := e.allocError(size, interpolatedStringExpression.PosStart, interpolatedStringExpression.PosEnd); err != nil {
+		e.Err = err
+		return nil
+	}
+	return &object.String{Value: strings.Join(parts, "")}
+}
+
 // evalListExpression 处理列表表达式节点
 // 将AST列表节点转换为运行时列表值，并验证元素类型一致性
 //
@@ -437,32 +1304,62 @@ func (e *Evaluator) evalStringExpression(stringExpression *ast.StringExpression,
 //
 //	若列表元素类型不一致，设置TypeError并返回nil
 func (e *Evaluator) evalListExpression(listExpression *ast.ListExpression, env *object.Environment) object.Object {
-	elements := make([]object.Object, 0, len(listExpression.Value))
-	var firstType string
+	list := &object.List{Elements: make([]object.Object, 0, len(listExpression.Value))}
 	// 解释每个列表元素
-	for i, elementExpr := range listExpression.Value {
+	for _, elementExpr := range listExpression.Value {
 		element := e.Eval(elementExpr, env)
 		if e.Err != nil {
 			return nil
 		}
-		// 第一个元素确定列表的类型
-		if i == 0 {
-			firstType = element.Type()
-		} else {
-			// 检查后续元素类型是否与第一个元素一致
-			if element.Type() != firstType {
-				e.Err = &TypeError{
-					Frame:    e.Frame,
-					Message:  "list elements must have consistent types.",
-					PosStart: listExpression.PosStart,
-					PosEnd:   listExpression.PosEnd,
-				}
-				return nil
+		// 第一个非空元素确定列表的类型，空值与任意类型兼容
+		if !list.Accepts(element) {
+			e.Err = &TypeError{
+				Code:     errcode.TypeError,
+				Frame:    e.Frame,
+				Message:  "list elements must have consistent types.",
+				PosStart: listExpression.PosStart,
+				PosEnd:   listExpression.PosEnd,
 			}
+			return nil
+		}
+		list.Elements = append(list.Elements, element)
+	}
+	return list
+}
+
+// evalMapExpression 处理映射表达式节点
+// 按源代码中的顺序解释每个键值对，重复的键保留最后一个值
+//
+// 参数:
+//
+//	mapExpression - 映射表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 包含所有键值对的object.Map实例，错误时返回nil
+//
+// 错误处理:
+//
+//	若键不是整数、浮点数、字符串或布尔值，在键的位置设置TypeError并返回nil
+func (e *Evaluator) evalMapExpression(mapExpression *ast.MapExpression, env *object.Environment) object.Object {
+	m := object.NewMap()
+	for _, entry := range mapExpression.Entries {
+		key := e.Eval(entry.Key, env)
+		if e.Err != nil {
+			return nil
+		}
+		value := e.Eval(entry.Value, env)
+		if e.Err != nil {
+			return nil
+		}
+		posStart, posEnd := ast.Span(entry.Key)
+		if err := m.Set(key, value, posStart, posEnd, e.Frame); err != nil {
+			e.Err = err
+			return nil
 		}
-		elements = append(elements, element)
 	}
-	return &object.List{Elements: elements}
+	return m
 }
 
 // evalIdentifierExpression 处理标识符表达式节点
@@ -482,14 +1379,9 @@ func (e *Evaluator) evalListExpression(listExpression *ast.ListExpression, env *
 //	若标识符未定义，设置VariableError并返回nil
 func (e *Evaluator) evalIdentifierExpression(identifierExpression *ast.IdentifierExpression, env *object.Environment) object.Object {
 	varName := identifierExpression.Name
-	val, ok := env.Get(varName)
+	val, ok := env.Lookup(identifierExpression)
 	if !ok {
-		e.Err = &VariableError{
-			Frame:    e.Frame,
-			Message:  fmt.Sprintf("undefined variable \"%s\".", varName),
-			PosStart: identifierExpression.PosStart,
-			PosEnd:   identifierExpression.PosEnd,
-		}
+		e.Err = e.undefinedVariableError(varName, env, identifierExpression.PosStart, identifierExpression.PosEnd)
 		return nil
 	}
 	return val.Value
@@ -512,10 +1404,12 @@ func (e *Evaluator) evalIdentifierExpression(identifierExpression *ast.Identifie
 //   - 尝试重定义常量时返回错误
 //   - 尝试将变量重新声明为常量时返回错误
 func (e *Evaluator) evalVarInitializationExpression(varInitialization *ast.VarInitializationExpression, env *object.Environment) object.Object {
-	varName := varInitialization.Name.(*ast.IdentifierExpression).Name
+	name := varInitialization.Name.(*ast.IdentifierExpression)
+	varName := name.Name
 	// 检查变量是否已定义
 	if env.Exists(varName) {
 		e.Err = &VariableError{
+			Code:     errcode.VariableError,
 			Frame:    e.Frame,
 			Message:  fmt.Sprintf("variable \"%s\" already defined.", varName),
 			PosStart: varInitialization.PosStart,
@@ -534,7 +1428,7 @@ func (e *Evaluator) evalVarInitializationExpression(varInitialization *ast.VarIn
 		Value:   val,
 		IsConst: varInitialization.IsConst,
 	}
-	env.Set(varName, sym)
+	env.Define(name, sym)
 	return val
 }
 
@@ -554,17 +1448,13 @@ func (e *Evaluator) checkIndexTargetConst(target ast.Expression, env *object.Env
 	switch t := target.(type) {
 	case *ast.IdentifierExpression:
 		// 检查标识符是否为常量
-		sym, ok := env.Get(t.Name)
+		sym, ok := env.Lookup(t)
 		if !ok {
-			return &VariableError{
-				Frame:    e.Frame,
-				Message:  fmt.Sprintf("undefined variable \"%s\".", t.Name),
-				PosStart: posStart,
-				PosEnd:   posEnd,
-			}
+			return e.undefinedVariableError(t.Name, env, posStart, posEnd)
 		}
 		if sym.IsConst {
 			return &VariableError{
+				Code:     errcode.VariableError,
 				Frame:    e.Frame,
 				Message:  fmt.Sprintf("cannot redefine constant \"%s\".", t.Name),
 				PosStart: posStart,
@@ -597,21 +1487,18 @@ func (e *Evaluator) checkIndexTargetConst(target ast.Expression, env *object.Env
 func (e *Evaluator) evalVarAssignmentExpression(varAssignment *ast.VarAssignmentExpression, env *object.Environment) object.Object {
 	switch varAssignment.Name.(type) {
 	case *ast.IdentifierExpression:
-		varName := varAssignment.Name.(*ast.IdentifierExpression).Name
+		ident := varAssignment.Name.(*ast.IdentifierExpression)
+		varName := ident.Name
 		// 检查变量是否已定义
-		sym, ok := env.Get(varName)
+		sym, ok := env.Lookup(ident)
 		if !ok {
-			e.Err = &VariableError{
-				Frame:    e.Frame,
-				Message:  fmt.Sprintf("undefined variable \"%s\".", varName),
-				PosStart: varAssignment.PosStart,
-				PosEnd:   varAssignment.PosEnd,
-			}
+			e.Err = e.undeclaredAssignmentError(varName, env, varAssignment.PosStart, varAssignment.PosEnd)
 			return nil
 		}
 		// 检查是否是常量
 		if sym.IsConst {
 			e.Err = &VariableError{
+				Code:     errcode.VariableError,
 				Frame:    e.Frame,
 				Message:  fmt.Sprintf("cannot redefine constant \"%s\".", varName),
 				PosStart: varAssignment.PosStart,
@@ -628,7 +1515,7 @@ func (e *Evaluator) evalVarAssignmentExpression(varAssignment *ast.VarAssignment
 			Value:   value,
 			IsConst: false,
 		}
-		env.Assign(varName, newSym)
+		env.Update(ident, newSym)
 		return value
 	case *ast.IndexExpression:
 		indexExpr := varAssignment.Name.(*ast.IndexExpression)
@@ -646,8 +1533,9 @@ func (e *Evaluator) evalVarAssignmentExpression(varAssignment *ast.VarAssignment
 			return nil
 		}
 		// 判断索引是否是整数
-		if _, ok := index.(*object.Int); !ok {
+		if _, ok := index.(*object.Int); !ok && intIndexRequired(target) {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index must be integer.",
 				PosStart: varAssignment.PosStart,
@@ -659,6 +1547,7 @@ func (e *Evaluator) evalVarAssignmentExpression(varAssignment *ast.VarAssignment
 		idxable, ok := target.(indexable)
 		if !ok {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index expression not supported for this type.",
 				PosStart: varAssignment.PosStart,
@@ -680,6 +1569,7 @@ func (e *Evaluator) evalVarAssignmentExpression(varAssignment *ast.VarAssignment
 		return value
 	default:
 		e.Err = &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    e.Frame,
 			Message:  "invalid variable name type.",
 			PosStart: varAssignment.PosStart,
@@ -709,21 +1599,18 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 	switch compoundAssignmentExpression.Name.(type) {
 	case *ast.IdentifierExpression:
 		// 获取变量名
-		varName := compoundAssignmentExpression.Name.(*ast.IdentifierExpression).Name
+		ident := compoundAssignmentExpression.Name.(*ast.IdentifierExpression)
+		varName := ident.Name
 		// 检查变量是否已定义
-		sym, ok := env.Get(varName)
+		sym, ok := env.Lookup(ident)
 		if !ok {
-			e.Err = &VariableError{
-				Frame:    e.Frame,
-				Message:  fmt.Sprintf("undefined variable \"%s\".", varName),
-				PosStart: compoundAssignmentExpression.PosStart,
-				PosEnd:   compoundAssignmentExpression.PosEnd,
-			}
+			e.Err = e.undeclaredAssignmentError(varName, env, compoundAssignmentExpression.PosStart, compoundAssignmentExpression.PosEnd)
 			return nil
 		}
 		// 检查是否是常量
 		if sym.IsConst {
 			e.Err = &VariableError{
+				Code:     errcode.VariableError,
 				Frame:    e.Frame,
 				Message:  fmt.Sprintf("cannot redefine constant \"%s\".", varName),
 				PosStart: compoundAssignmentExpression.PosStart,
@@ -736,6 +1623,30 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 		if e.Err != nil {
 			return nil
 		}
+		// 字符串的 += 在变量的缓冲区末尾追加，避免循环拼接的总时间随长度平方增长
+		if compoundAssignmentExpression.Operator.Type == lexer.PLUS_EQUAL {
+			left, isLeftString := sym.Value.(*object.String)
+			other, isRightString := right.(*object.String)
+			if isLeftString && isRightString {
+				size := int64(len(left.Value) + len(other.Value))
+				if err := e.allocError(size, compoundAssignmentExpression.PosStart, compoundAssignmentExpression.PosEnd); err != nil {
+					e.Err = err
+					return nil
+				}
+				buffer := sym.Buffer
+				if buffer == nil {
+					buffer = &object.StringBuffer{}
+				}
+				value := buffer.Append(left, other)
+				env.Update(ident, &object.Symbol{
+					Name:    varName,
+					Value:   value,
+					IsConst: false,
+					Buffer:  buffer,
+				})
+				return value
+			}
+		}
 		// 获取运算符字面量
 		literal := compoundAssignmentExpression.Operator.Literal[:len(compoundAssignmentExpression.Operator.Literal)-1]
 		// 获取并创建基础运算符令牌
@@ -744,13 +1655,17 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 			Literal: literal,
 		}
 		// 执行复合赋值
-		value := e.evalInfixOperator(&ast.InfixExpression{
+		infixExpression := &ast.InfixExpression{
 			Left:     compoundAssignmentExpression.Name,
 			Operator: baseOperator,
 			Right:    compoundAssignmentExpression.Right,
 			PosStart: compoundAssignmentExpression.PosStart,
 			PosEnd:   compoundAssignmentExpression.PosEnd,
-		}, sym.Value, right)
+		}
+		if !e.checkNumericOperands(infixExpression, sym.Value, right) {
+			return nil
+		}
+		value := e.evalInfixOperator(infixExpression, sym.Value, right)
 		if e.Err != nil {
 			return nil
 		}
@@ -760,7 +1675,7 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 			Value:   value,
 			IsConst: false,
 		}
-		env.Assign(varName, newSym)
+		env.Update(ident, newSym)
 		return value
 	case *ast.IndexExpression:
 		indexExpr := compoundAssignmentExpression.Name.(*ast.IndexExpression)
@@ -778,8 +1693,9 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 			return nil
 		}
 		// 判断索引是否是整数
-		if _, ok := index.(*object.Int); !ok {
+		if _, ok := index.(*object.Int); !ok && intIndexRequired(target) {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index must be integer.",
 				PosStart: compoundAssignmentExpression.PosStart,
@@ -791,6 +1707,7 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 		idxable, ok := target.(indexable)
 		if !ok {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index expression not supported for this type.",
 				PosStart: compoundAssignmentExpression.PosStart,
@@ -816,13 +1733,17 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 			return nil
 		}
 		// 执行复合赋值
-		value := e.evalInfixOperator(&ast.InfixExpression{
+		infixExpression := &ast.InfixExpression{
 			Left:     compoundAssignmentExpression.Name,
 			Operator: baseOperator,
 			Right:    compoundAssignmentExpression.Right,
 			PosStart: compoundAssignmentExpression.PosStart,
 			PosEnd:   compoundAssignmentExpression.PosEnd,
-		}, idxValue, right)
+		}
+		if !e.checkNumericOperands(infixExpression, idxValue, right) {
+			return nil
+		}
+		value := e.evalInfixOperator(infixExpression, idxValue, right)
 		if e.Err != nil {
 			return nil
 		}
@@ -835,6 +1756,7 @@ func (e *Evaluator) evalCompoundAssignmentExpression(compoundAssignmentExpressio
 		return value
 	default:
 		e.Err = &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    e.Frame,
 			Message:  "invalid variable name type.",
 			PosStart: compoundAssignmentExpression.PosStart,
@@ -896,6 +1818,7 @@ func (e *Evaluator) evalPrefixOperator(prefixExpression *ast.PrefixExpression, r
 		return val
 	default:
 		e.Err = &object.OperationError{
+			Code:     errcode.OperationError,
 			Message:  fmt.Sprintf("invalid operation \"%s\".", prefixExpression.Operator.Type),
 			PosStart: prefixExpression.PosStart,
 			PosEnd:   prefixExpression.PosEnd,
@@ -922,20 +1845,17 @@ func (e *Evaluator) evalPrefixOperator(prefixExpression *ast.PrefixExpression, r
 func (e *Evaluator) evalPrefixUnaryIncDecExpression(prefixUnaryIncDecExpression *ast.PrefixUnaryIncDecExpression, env *object.Environment) object.Object {
 	switch prefixUnaryIncDecExpression.Right.(type) {
 	case *ast.IdentifierExpression:
-		name := prefixUnaryIncDecExpression.Right.(*ast.IdentifierExpression).Name
-		sym, ok := env.Get(name)
+		ident := prefixUnaryIncDecExpression.Right.(*ast.IdentifierExpression)
+		name := ident.Name
+		sym, ok := env.Lookup(ident)
 		if !ok {
-			e.Err = &VariableError{
-				Frame:    e.Frame,
-				Message:  fmt.Sprintf("undefined variable \"%s\".", name),
-				PosStart: prefixUnaryIncDecExpression.PosStart,
-				PosEnd:   prefixUnaryIncDecExpression.PosEnd,
-			}
+			e.Err = e.undeclaredAssignmentError(name, env, prefixUnaryIncDecExpression.PosStart, prefixUnaryIncDecExpression.PosEnd)
 			return nil
 		}
 		// 检查是否是常量
 		if sym.IsConst {
 			e.Err = &VariableError{
+				Code:     errcode.VariableError,
 				Frame:    e.Frame,
 				Message:  fmt.Sprintf("cannot redefine constant \"%s\".", name),
 				PosStart: prefixUnaryIncDecExpression.PosStart,
@@ -981,8 +1901,8 @@ func (e *Evaluator) evalPrefixUnaryIncDecExpression(prefixUnaryIncDecExpression
 			Value:   val,
 			IsConst: false,
 		}
-		// 更新变量值
-		env.Set(name, newSym)
+		// 更新变量值，变量可能声明在外层作用域中
+		env.Update(ident, newSym)
 		return val
 	case *ast.IndexExpression:
 		indexExpr := prefixUnaryIncDecExpression.Right.(*ast.IndexExpression)
@@ -1000,8 +1920,9 @@ func (e *Evaluator) evalPrefixUnaryIncDecExpression(prefixUnaryIncDecExpression
 			return nil
 		}
 		// 判断索引是否是整数
-		if _, ok := index.(*object.Int); !ok {
+		if _, ok := index.(*object.Int); !ok && intIndexRequired(target) {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index must be integer.",
 				PosStart: prefixUnaryIncDecExpression.PosStart,
@@ -1013,6 +1934,7 @@ func (e *Evaluator) evalPrefixUnaryIncDecExpression(prefixUnaryIncDecExpression
 		idxable, ok := target.(indexable)
 		if !ok {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index expression not supported for this type.",
 				PosStart: prefixUnaryIncDecExpression.PosStart,
@@ -1061,6 +1983,7 @@ func (e *Evaluator) evalPrefixUnaryIncDecExpression(prefixUnaryIncDecExpression
 		return val
 	default:
 		e.Err = &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    e.Frame,
 			Message:  "invalid variable name type.",
 			PosStart: prefixUnaryIncDecExpression.PosStart,
@@ -1088,20 +2011,17 @@ func (e *Evaluator) evalPrefixUnaryIncDecExpression(prefixUnaryIncDecExpression
 func (e *Evaluator) evalPostfixUnaryIncDecExpression(postfixUnaryIncDecExpression *ast.PostfixUnaryIncDecExpression, env *object.Environment) object.Object {
 	switch postfixUnaryIncDecExpression.Left.(type) {
 	case *ast.IdentifierExpression:
-		name := postfixUnaryIncDecExpression.Left.(*ast.IdentifierExpression).Name
-		sym, ok := env.Get(name)
+		ident := postfixUnaryIncDecExpression.Left.(*ast.IdentifierExpression)
+		name := ident.Name
+		sym, ok := env.Lookup(ident)
 		if !ok {
-			e.Err = &VariableError{
-				Frame:    e.Frame,
-				Message:  fmt.Sprintf("undefined variable \"%s\".", name),
-				PosStart: postfixUnaryIncDecExpression.PosStart,
-				PosEnd:   postfixUnaryIncDecExpression.PosEnd,
-			}
+			e.Err = e.undeclaredAssignmentError(name, env, postfixUnaryIncDecExpression.PosStart, postfixUnaryIncDecExpression.PosEnd)
 			return nil
 		}
 		// 检查是否是常量
 		if sym.IsConst {
 			e.Err = &VariableError{
+				Code:     errcode.VariableError,
 				Frame:    e.Frame,
 				Message:  fmt.Sprintf("cannot redefine constant \"%s\".", name),
 				PosStart: postfixUnaryIncDecExpression.PosStart,
@@ -1147,8 +2067,8 @@ func (e *Evaluator) evalPostfixUnaryIncDecExpression(postfixUnaryIncDecExpressio
 			Value:   val,
 			IsConst: false,
 		}
-		// 更新变量值
-		env.Set(name, newSym)
+		// 更新变量值，变量可能声明在外层作用域中
+		env.Update(ident, newSym)
 		return left
 	case *ast.IndexExpression:
 		indexExpr := postfixUnaryIncDecExpression.Left.(*ast.IndexExpression)
@@ -1166,8 +2086,9 @@ func (e *Evaluator) evalPostfixUnaryIncDecExpression(postfixUnaryIncDecExpressio
 			return nil
 		}
 		// 判断索引是否是整数
-		if _, ok := index.(*object.Int); !ok {
+		if _, ok := index.(*object.Int); !ok && intIndexRequired(target) {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index must be integer.",
 				PosStart: postfixUnaryIncDecExpression.PosStart,
@@ -1179,6 +2100,7 @@ func (e *Evaluator) evalPostfixUnaryIncDecExpression(postfixUnaryIncDecExpressio
 		idxable, ok := target.(indexable)
 		if !ok {
 			e.Err = &TypeError{
+				Code:     errcode.TypeError,
 				Frame:    e.Frame,
 				Message:  "index expression not supported for this type.",
 				PosStart: postfixUnaryIncDecExpression.PosStart,
@@ -1227,6 +2149,7 @@ func (e *Evaluator) evalPostfixUnaryIncDecExpression(postfixUnaryIncDecExpressio
 		return left
 	default:
 		e.Err = &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    e.Frame,
 			Message:  "invalid variable name type.",
 			PosStart: postfixUnaryIncDecExpression.PosStart,
@@ -1264,10 +2187,11 @@ func (e *Evaluator) evalInfixExpression(infixExpression *ast.InfixExpression, en
 	if infixExpression.Operator.Type == lexer.LOGICAL_AND {
 		if leftValue, ok := left.(*object.Bool); ok {
 			if !leftValue.Value {
-				return &object.Bool{Value: false}
+				return object.FalseObj
 			}
 		} else {
 			e.Err = &object.OperationError{
+				Code:     errcode.OperationError,
 				Frame:    e.Frame,
 				Message:  "invalid operation \"&&\".",
 				PosStart: infixExpression.PosStart,
@@ -1280,10 +2204,11 @@ func (e *Evaluator) evalInfixExpression(infixExpression *ast.InfixExpression, en
 	if infixExpression.Operator.Type == lexer.LOGICAL_OR {
 		if leftValue, ok := left.(*object.Bool); ok {
 			if leftValue.Value {
-				return &object.Bool{Value: true}
+				return object.TrueObj
 			}
 		} else {
 			e.Err = &object.OperationError{
+				Code:     errcode.OperationError,
 				Frame:    e.Frame,
 				Message:  "invalid operation \"||\".",
 				PosStart: infixExpression.PosStart,
@@ -1297,6 +2222,9 @@ func (e *Evaluator) evalInfixExpression(infixExpression *ast.InfixExpression, en
 	if e.Err != nil {
 		return nil
 	}
+	if !e.checkNumericOperands(infixExpression, left, right) {
+		return nil
+	}
 	val := e.evalInfixOperator(infixExpression, left, right)
 	if e.Err != nil {
 		return nil
@@ -1304,7 +2232,47 @@ func (e *Evaluator) evalInfixExpression(infixExpression *ast.InfixExpression, en
 	return val
 }
 
+// checkNumericOperands 严格数值模式下检查运算符的两个操作数是否混用了整数和浮点数
+// 自增和自减不经过此检查，浮点数变量可以直接自增
+//
+// 参数:
+//
+//	infixExpression - 中缀表达式节点，复合赋值时为由其构造的中缀表达式
+//	left - 左操作数
+//	right - 右操作数
+//
+// 返回值:
+//
+//	bool - 检查通过时为true，否则设置类型错误并返回false
+func (e *Evaluator) checkNumericOperands(infixExpression *ast.InfixExpression, left, right object.Object) bool {
+	if !e.Strict {
+		return true
+	}
+	_, isLeftInt := left.(*object.Int)
+	_, isLeftFloat := left.(*object.Float)
+	_, isRightInt := right.(*object.Int)
+	_, isRightFloat := right.(*object.Float)
+	if (isLeftInt && isRightFloat) || (isLeftFloat && isRightInt) {
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("mixed int and float operands for \"%s\" in strict mode, convert with float() or int().", infixExpression.Operator.Literal),
+			PosStart: infixExpression.PosStart,
+			PosEnd:   infixExpression.PosEnd,
+		}
+		return false
+	}
+	return true
+}
+
 func (e *Evaluator) evalInfixOperator(infixExpression *ast.InfixExpression, left, right object.Object) object.Object {
+	if e.Limits.MaxAlloc > 0 {
+		size := operatorAllocSize(infixExpression.Operator.Type, left, right)
+		if err := e.allocError(size, infixExpression.PosStart, infixExpression.PosEnd); err != nil {
+			e.Err = err
+			return nil
+		}
+	}
 	switch infixExpression.Operator.Type {
 	case lexer.PLUS:
 		val, err := left.Add(right, infixExpression.PosStart, infixExpression.PosEnd, e.Frame)
@@ -1434,6 +2402,7 @@ func (e *Evaluator) evalInfixOperator(infixExpression *ast.InfixExpression, left
 		return val
 	default:
 		e.Err = &object.OperationError{
+			Code:     errcode.OperationError,
 			Message:  fmt.Sprintf("invalid operation \"%s\".", infixExpression.Operator.Type),
 			PosStart: infixExpression.PosStart,
 			PosEnd:   infixExpression.PosEnd,
@@ -1446,16 +2415,20 @@ func (e *Evaluator) evalWithReturnValue(node ast.Node, env *object.Environment)
 	var ret object.Object
 	switch n := node.(type) {
 	case *ast.ExpressionStatement:
+		// 表达式语句不经过Eval分发，单独调用回调
+		if !e.enterNode(n, env) {
+			return nil
+		}
 		ret = e.Eval(n.Expr, env)
 		if e.Err != nil {
 			return nil
 		}
 	case *ast.ReturnStatement:
-		ret = e.Eval(n.ReturnValue, env)
-		if e.Err != nil {
+		if !e.enterNode(n, env) {
 			return nil
 		}
-		return &object.ReturnValue{Value: ret}
+		// 由evalReturnStatement检查return是否位于函数内，块或if中的return同样不能出现在顶层
+		return e.evalReturnStatement(n, env)
 	case ast.Statement:
 		ret = e.Eval(n, env)
 		if e.Err != nil {
@@ -1464,7 +2437,10 @@ func (e *Evaluator) evalWithReturnValue(node ast.Node, env *object.Environment)
 		if returnValue, ok := ret.(*object.ReturnValue); ok {
 			return returnValue
 		}
-		ret = &object.Null{}
+		if isLoopSignal(ret) {
+			return ret
+		}
+		ret = object.NullObj
 	case ast.Expression:
 		ret = e.Eval(n, env)
 		if e.Err != nil {
@@ -1486,18 +2462,22 @@ func (e *Evaluator) evalWithReturnValue(node ast.Node, env *object.Environment)
 //
 //	object.Object - 块表达式的结果，发生错误时返回nil
 func (e *Evaluator) evalBlockExpression(blockExpression *ast.BlockExpression, env *object.Environment) object.Object {
-	var ret object.Object
+	// 空的块表达式的值为null
+	var ret object.Object = object.NullObj
 	// 创建新环境
-	blockEnv := &object.Environment{
-		Store: make(map[string]*object.Symbol),
-		Outer: env,
-	}
+	blockEnv := object.NewEnvironment(env, blockExpression.Layout)
 	for _, statement := range blockExpression.Statements {
 		// 获取返回值
 		ret = e.evalWithReturnValue(statement, blockEnv)
+		if e.Err != nil {
+			return nil
+		}
 		if returnValue, ok := ret.(*object.ReturnValue); ok {
 			return returnValue
 		}
+		if isLoopSignal(ret) {
+			return ret
+		}
 	}
 	return ret
 }
@@ -1524,6 +2504,7 @@ func (e *Evaluator) evalIfExpression(ifExpression *ast.IfExpression, env *object
 	}
 	if _, ok := condition.(*object.Bool); !ok {
 		e.Err = &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    e.Frame,
 			Message:  "non-bool condition in if expression.",
 			PosStart: ifExpression.PosStart,
@@ -1532,17 +2513,47 @@ func (e *Evaluator) evalIfExpression(ifExpression *ast.IfExpression, env *object
 		return nil
 	}
 	// 创建新环境
-	ifEnv := &object.Environment{
-		Store: make(map[string]*object.Symbol),
-		Outer: env,
-	}
+	ifEnv := object.NewEnvironment(env, ifExpression.Layout)
 	if condition.(*object.Bool).Value {
 		return e.evalWithReturnValue(ifExpression.Consequence, ifEnv)
 	} else if ifExpression.Alternative != nil {
 		return e.evalWithReturnValue(ifExpression.Alternative, ifEnv)
 	} else {
-		return &object.Null{}
+		return object.NullObj
+	}
+}
+
+// evalTernaryExpression 处理条件运算符表达式节点
+// 只计算条件所选的分支，分支在当前环境中计算
+//
+// 参数:
+//
+//	ternaryExpression - 条件运算符表达式节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 所选分支的值，发生错误时返回nil
+func (e *Evaluator) evalTernaryExpression(ternaryExpression *ast.TernaryExpression, env *object.Environment) object.Object {
+	condition := e.Eval(ternaryExpression.Condition, env)
+	if e.Err != nil {
+		return nil
+	}
+	b, ok := condition.(*object.Bool)
+	if !ok {
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  "non-bool condition in ternary expression.",
+			PosStart: ternaryExpression.PosStart,
+			PosEnd:   ternaryExpression.PosEnd,
+		}
+		return nil
+	}
+	if b.Value {
+		return e.Eval(ternaryExpression.Consequence, env)
 	}
+	return e.Eval(ternaryExpression.Alternative, env)
 }
 
 // evalCallExpression 处理函数调用表达式节点
@@ -1561,6 +2572,13 @@ func (e *Evaluator) evalCallExpression(callExpression *ast.CallExpression, env *
 	if e.Err != nil {
 		return nil
 	}
+	// 计算传入参数数量
+	argLen := 0
+	for _, arg := range callExpression.Argument {
+		if arg != nil {
+			argLen++
+		}
+	}
 	switch fn := function.(type) {
 	// 函数
 	case *object.Function:
@@ -1571,44 +2589,19 @@ func (e *Evaluator) evalCallExpression(callExpression *ast.CallExpression, env *
 				defaultLen++
 			}
 		}
-		// 计算传入参数数量
-		argLen := 0
-		for _, arg := range callExpression.Argument {
-			if arg != nil {
-				argLen++
-			}
-		}
 		// 参数数量不匹配
-		least := len(fn.Parameter) - defaultLen
-		if !(least <= argLen && argLen <= len(fn.Parameter)) {
-			if defaultLen == 0 {
-				e.Err = &ArgumentError{
-					Frame:    e.Frame,
-					Message:  fmt.Sprintf("expected %d parameters, got %d.", len(fn.Parameter), argLen),
-					PosStart: callExpression.PosStart,
-					PosEnd:   callExpression.PosEnd,
-				}
-			} else if least == 1 {
-				e.Err = &ArgumentError{
-					Frame:    e.Frame,
-					Message:  fmt.Sprintf("expected between 1 parameter and %d parameters, got %d.", len(fn.Parameter), argLen),
-					PosStart: callExpression.PosStart,
-					PosEnd:   callExpression.PosEnd,
-				}
-			} else {
-				e.Err = &ArgumentError{
-					Frame:    e.Frame,
-					Message:  fmt.Sprintf("expected between %d and %d parameters, got %d.", least, len(fn.Parameter), argLen),
-					PosStart: callExpression.PosStart,
-					PosEnd:   callExpression.PosEnd,
-				}
-			}
+		if err := e.checkArgumentCount(len(fn.Parameter), defaultLen, argLen, callExpression.PosStart, callExpression.PosEnd); err != nil {
+			e.Err = err
 			return nil
 		}
 		var argument []object.Object
 		for _, arg := range callExpression.Argument {
-			// 如果参数为nil，用默认值填充
+			// 如果参数为nil，用默认值填充，默认值在调用处的环境中计算
 			if arg == nil {
+				if fn.Parameter[len(argument)].DefaultValue == nil {
+					e.Err = e.omittedArgumentError(len(argument), callExpression.PosStart, callExpression.PosEnd)
+					return nil
+				}
 				defaultValue := e.Eval(fn.Parameter[len(argument)].DefaultValue, env)
 				if e.Err != nil {
 					return nil
@@ -1630,36 +2623,7 @@ func (e *Evaluator) evalCallExpression(callExpression *ast.CallExpression, env *
 			}
 			argument = append(argument, defaultValue)
 		}
-		// 创建函数环境
-		funcEnv := &object.Environment{
-			Store: make(map[string]*object.Symbol),
-			Outer: fn.Env,
-		}
-		e.Frame = &frame.Frame{
-			FuncName: fmt.Sprintf("<function \"%s\">", fn.Name),
-			Parent:   e.Frame,
-			PosStart: callExpression.PosStart,
-			PosEnd:   callExpression.PosEnd,
-		}
-		// 创建参数
-		for i, param := range fn.Parameter {
-			funcEnv.Set(param.Name.Name, &object.Symbol{
-				Name:    param.Name.Name,
-				Value:   argument[i],
-				IsConst: false,
-			})
-		}
-		// 执行函数体
-		var returnValue = e.evalWithReturnValue(fn.Body, funcEnv)
-		if e.Err != nil {
-			return nil
-		}
-		e.Frame = e.Frame.Parent
-		if ret, ok := returnValue.(*object.ReturnValue); ok {
-			return ret.Value
-		} else {
-			return returnValue
-		}
+		return e.invokeFunction(fn, argument, callExpression.PosStart, callExpression.PosEnd)
 	// 内置函数
 	case *object.BuiltinFunction:
 		// 计算默认参数数量
@@ -1669,50 +2633,20 @@ func (e *Evaluator) evalCallExpression(callExpression *ast.CallExpression, env *
 				defaultLen++
 			}
 		}
-		// 计算传入参数数量
-		argLen := 0
-		for _, arg := range callExpression.Argument {
-			if arg != nil {
-				argLen++
-			}
-		}
 		// 参数数量不匹配
-		least := len(fn.Parameter) - defaultLen
-		if !(least <= argLen && argLen <= len(fn.Parameter)) {
-			if defaultLen == 0 {
-				e.Err = &ArgumentError{
-					Frame:    e.Frame,
-					Message:  fmt.Sprintf("expected %d parameters, got %d.", len(fn.Parameter), argLen),
-					PosStart: callExpression.PosStart,
-					PosEnd:   callExpression.PosEnd,
-				}
-			} else if least == 1 {
-				e.Err = &ArgumentError{
-					Frame:    e.Frame,
-					Message:  fmt.Sprintf("expected between 1 parameter and %d parameters, got %d.", len(fn.Parameter), argLen),
-					PosStart: callExpression.PosStart,
-					PosEnd:   callExpression.PosEnd,
-				}
-			} else {
-				e.Err = &ArgumentError{
-					Frame:    e.Frame,
-					Message:  fmt.Sprintf("expected between %d and %d parameters, got %d.", least, len(fn.Parameter), argLen),
-					PosStart: callExpression.PosStart,
-					PosEnd:   callExpression.PosEnd,
-				}
-			}
+		if err := e.checkArgumentCount(len(fn.Parameter), defaultLen, argLen, callExpression.PosStart, callExpression.PosEnd); err != nil {
+			e.Err = err
 			return nil
 		}
-		// 调用内置函数
 		var argument []object.Object
 		for _, arg := range callExpression.Argument {
 			// 如果参数为nil，用默认值填充
 			if arg == nil {
-				defaultValue := e.Eval(fn.DefaultValue[len(argument)], env)
-				if e.Err != nil {
+				if len(argument) >= len(fn.DefaultValue) || fn.DefaultValue[len(argument)] == nil {
+					e.Err = e.omittedArgumentError(len(argument), callExpression.PosStart, callExpression.PosEnd)
 					return nil
 				}
-				argument = append(argument, defaultValue)
+				argument = append(argument, fn.DefaultValue[len(argument)])
 				continue
 			}
 			a := e.Eval(arg, env)
@@ -1723,33 +2657,497 @@ func (e *Evaluator) evalCallExpression(callExpression *ast.CallExpression, env *
 		}
 		// 有默认参数未被赋值时，用默认值填充
 		for i := len(argument); i < len(fn.Parameter); i++ {
-			defaultValue := e.Eval(fn.DefaultValue[i], env)
+			argument = append(argument, fn.DefaultValue[i])
+		}
+		return e.invokeBuiltin(fn, argument, env, callExpression.PosStart, callExpression.PosEnd)
+	default:
+		// 调用非函数
+		e.Err = &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    e.Frame,
+			Message:  "the value is not a function and cannot be called.",
+			PosStart: callExpression.PosStart,
+			PosEnd:   callExpression.PosEnd,
+		}
+		return nil
+	}
+}
+
+// CallFunction 使用已求值的参数调用函数对象，实现object.Interpreter接口
+// 供内置函数回调用户定义的函数或其他内置函数
+//
+// 参数:
+//
+//	function - 要调用的函数对象
+//	args - 按位置传入的参数，缺少的参数使用默认值填充
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	object.Object - 函数的返回值
+//	error - 调用过程中产生的错误
+func (e *Evaluator) CallFunction(function object.Object, args []object.Object, posStart, posEnd *util.Pos) (object.Object, error) {
+	return e.callFunction(function, args, nil, posStart, posEnd)
+}
+
+// callFunction 使用已求值的参数调用函数对象
+//
+// 参数:
+//
+//	function - 要调用的函数对象
+//	args - 按位置传入的参数，缺少的参数使用默认值填充
+//	env - 调用者的环境，供内置函数使用，未知时为nil
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	object.Object - 函数的返回值
+//	error - 调用过程中产生的错误
+func (e *Evaluator) callFunction(function object.Object, args []object.Object, env *object.Environment, posStart, posEnd *util.Pos) (object.Object, error) {
+	switch fn := function.(type) {
+	case *object.Function:
+		// 计算默认参数数量
+		defaultLen := 0
+		for _, param := range fn.Parameter {
+			if param.DefaultValue != nil {
+				defaultLen++
+			}
+		}
+		if err := e.checkArgumentCount(len(fn.Parameter), defaultLen, len(args), posStart, posEnd); err != nil {
+			return nil, err
+		}
+		argument := append([]object.Object{}, args...)
+		// 有默认参数未被赋值时，用默认值填充，默认值在调用者的环境中计算，调用者未知时使用声明函数的环境
+		defaultEnv := env
+		if defaultEnv == nil {
+			defaultEnv = fn.Env
+		}
+		for i := len(argument); i < len(fn.Parameter); i++ {
+			defaultValue := e.Eval(fn.Parameter[i].DefaultValue, defaultEnv)
 			if e.Err != nil {
-				return nil
+				return nil, e.Err
 			}
 			argument = append(argument, defaultValue)
 		}
-		e.Frame = &frame.Frame{
-			FuncName: fmt.Sprintf("<builtin \"%s\">", fn.Name),
-			Parent:   e.Frame,
-			PosStart: callExpression.PosStart,
-			PosEnd:   callExpression.PosEnd,
+		ret := e.invokeFunction(fn, argument, posStart, posEnd)
+		return ret, e.Err
+	case *object.BuiltinFunction:
+		// 计算默认参数数量
+		defaultLen := 0
+		for _, defaultValue := range fn.DefaultValue {
+			if defaultValue != nil {
+				defaultLen++
+			}
 		}
-		val, err := fn.Fn(e.Frame, callExpression.PosStart, callExpression.PosEnd, argument...)
-		if err != nil {
-			e.Err = err
-			return nil
+		if err := e.checkArgumentCount(len(fn.Parameter), defaultLen, len(args), posStart, posEnd); err != nil {
+			return nil, err
 		}
-		e.Frame = e.Frame.Parent
-		return val
+		argument := append([]object.Object{}, args...)
+		// 有默认参数未被赋值时，用默认值填充
+		for i := len(argument); i < len(fn.Parameter); i++ {
+			argument = append(argument, fn.DefaultValue[i])
+		}
+		ret := e.invokeBuiltin(fn, argument, env, posStart, posEnd)
+		return ret, e.Err
 	default:
-		// 调用非函数
-		e.Err = &TypeError{
+		return nil, &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    e.Frame,
 			Message:  "the value is not a function and cannot be called.",
-			PosStart: callExpression.PosStart,
-			PosEnd:   callExpression.PosEnd,
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+}
+
+// checkArgumentCount 检查传入参数数量是否与函数定义匹配
+//
+// 参数:
+//
+//	paramLen - 参数总数
+//	defaultLen - 有默认值的参数数量
+//	argLen - 传入参数数量
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	error - 参数数量不匹配时返回ArgumentError，否则返回nil
+func (e *Evaluator) checkArgumentCount(paramLen, defaultLen, argLen int, posStart, posEnd *util.Pos) error {
+	message := object.ArgumentCountMessage(paramLen, defaultLen, argLen)
+	if message == "" {
+		return nil
+	}
+	return &ArgumentError{
+		Code:     errcode.ArgumentError,
+		Frame:    e.Frame,
+		Message:  message,
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// omittedArgumentError 创建省略了没有默认值的参数的错误
+//
+// 参数:
+//
+//	index - 被省略的参数的序号，从0开始计数
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	*ArgumentError - 参数错误
+func (e *Evaluator) omittedArgumentError(index int, posStart, posEnd *util.Pos) *ArgumentError {
+	return &ArgumentError{
+		Code:     errcode.ArgumentError,
+		Frame:    e.Frame,
+		Message:  fmt.Sprintf("argument %d cannot be omitted because it has no default value.", index+1),
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// invokeFunction 在新的调用栈帧中执行用户定义的函数
+//
+// 参数:
+//
+//	fn - 要执行的函数
+//	argument - 已填充默认值的参数列表
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	object.Object - 函数的返回值，发生错误时返回nil
+func (e *Evaluator) invokeFunction(fn *object.Function, argument []object.Object, posStart, posEnd *util.Pos) object.Object {
+	// 创建函数环境
+	funcEnv := object.NewEnvironment(fn.Env, fn.Layout)
+	if e.Limits.MaxDepth > 0 && e.callDepth >= e.Limits.MaxDepth {
+		e.Err = &ResourceError{
+			Code:     errcode.DepthLimit,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("maximum call depth of %d exceeded.", e.Limits.MaxDepth),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+			Limit:    DepthLimit,
 		}
 		return nil
 	}
+	e.callDepth++
+	// 函数体中的break和continue不能作用于调用处的循环
+	loopDepth := e.loopDepth
+	e.loopDepth = 0
+	defer func() {
+		e.callDepth--
+		e.loopDepth = loopDepth
+	}()
+	e.Frame = &frame.Frame{
+		FuncName: functionFrameName(fn),
+		Parent:   e.Frame,
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+	// 创建参数
+	for i, param := range fn.Parameter {
+		funcEnv.Define(param.Name, &object.Symbol{
+			Name:    param.Name.Name,
+			Value:   argument[i],
+			IsConst: false,
+		})
+	}
+	// 执行函数体
+	var returnValue = e.evalWithReturnValue(fn.Body, funcEnv)
+	if e.Err != nil {
+		return nil
+	}
+	e.Frame = e.Frame.Parent
+	if ret, ok := returnValue.(*object.ReturnValue); ok {
+		return ret.Value
+	}
+	return returnValue
+}
+
+// functionFrameName 返回用户定义的函数在调用栈中显示的名称
+//
+// 参数:
+//
+//	fn - 用户定义的函数
+//
+// 返回值:
+//
+//	string - 调用栈帧的名称，匿名函数为<anonymous function>
+func functionFrameName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "<anonymous function>"
+	}
+	return fmt.Sprintf("<function \"%s\">", fn.Name)
+}
+
+// invokeBuiltin 在新的调用栈帧中执行内置函数
+//
+// 参数:
+//
+//	fn - 要执行的内置函数
+//	argument - 已填充默认值的参数列表
+//	env - 调用者的环境，未知时为nil
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	object.Object - 函数的返回值，发生错误时返回nil
+func (e *Evaluator) invokeBuiltin(fn *object.BuiltinFunction, argument []object.Object, env *object.Environment, posStart, posEnd *util.Pos) (result object.Object) {
+	e.Frame = &frame.Frame{
+		FuncName: fmt.Sprintf("<builtin \"%s\">", fn.Name),
+		Parent:   e.Frame,
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+	// 内置函数中的panic报告在调用位置
+	defer e.recoverPanic(&result, posStart, posEnd)
+	val, err := fn.Fn(&builtinContext{evaluator: e, env: env}, e.Frame, posStart, posEnd, argument...)
+	if err != nil {
+		e.Err = err
+		return nil
+	}
+	// 内置函数创建的字符串和列表同样受大小限制
+	if err := e.allocError(object.AllocSize(val), posStart, posEnd); err != nil {
+		e.Err = err
+		return nil
+	}
+	e.Frame = e.Frame.Parent
+	return val
+}
+
+// builtinContext 内置函数的调用上下文，实现object.Interpreter接口
+// 在解释器之外记录调用者的环境，使内置函数可以在该环境中执行代码
+
+type builtinContext struct {
+	evaluator *Evaluator          // 解释器
+	env       *object.Environment // 调用者的环境
+}
+
+// CallFunction 使用已求值的参数调用函数对象
+//
+// 参数:
+//
+//	function - 要调用的函数对象
+//	args - 按位置传入的参数
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	object.Object - 函数的返回值
+//	error - 调用过程中产生的错误
+func (c *builtinContext) CallFunction(function object.Object, args []object.Object, posStart, posEnd *util.Pos) (object.Object, error) {
+	return c.evaluator.callFunction(function, args, c.env, posStart, posEnd)
+}
+
+// EvalSource 在调用者环境的子环境中解析并执行一段源代码
+//
+// 参数:
+//
+//	source - 要执行的源代码
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	object.Object - 最后一条表达式语句的值，没有时为null
+//	error - 解析或执行过程中的错误
+func (c *builtinContext) EvalSource(source string, posStart, posEnd *util.Pos) (object.Object, error) {
+	if c.env == nil {
+		return nil, &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    c.evaluator.Frame,
+			Message:  "eval() is not available in this context.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	return c.evaluator.evalSource(source, c.env, posStart, posEnd)
+}
+
+// Stdout 返回解释器的标准输出，未设置时返回平台默认的标准输出
+//
+// 返回值:
+//
+//	io.Writer - 标准输出
+func (c *builtinContext) Stdout() io.Writer {
+	if c.evaluator.Stdout != nil {
+		return c.evaluator.Stdout
+	}
+	return defaultStdout()
+}
+
+// Stderr 返回解释器的标准错误输出，未设置时返回平台默认的标准错误输出
+//
+// 返回值:
+//
+//	io.Writer - 标准错误输出
+func (c *builtinContext) Stderr() io.Writer {
+	if c.evaluator.Stderr != nil {
+		return c.evaluator.Stderr
+	}
+	return defaultStderr()
+}
+
+// CheckAlloc 检查内置函数即将创建的字符串或列表的大小是否超出限制
+//
+// 参数:
+//
+//	size - 字节数
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	error - 超出限制时的资源错误
+func (c *builtinContext) CheckAlloc(size int64, posStart, posEnd *util.Pos) error {
+	return c.evaluator.allocError(size, posStart, posEnd)
+}
+
+// evalSource 解析并在给定环境的子环境中执行一段源代码
+// 最后一条语句可以省略分号，嵌套深度超过maxEvalDepth时返回RecursionError
+//
+// 参数:
+//
+//	source - 要执行的源代码
+//	env - 外层环境
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	object.Object - 最后一条表达式语句的值，没有时为null
+//	error - 解析或执行过程中的错误
+func (e *Evaluator) evalSource(source string, env *object.Environment, posStart, posEnd *util.Pos) (object.Object, error) {
+	if e.evalDepth >= maxEvalDepth {
+		return nil, &RecursionError{
+			Code:     errcode.RecursionError,
+			Frame:    e.Frame,
+			Message:  fmt.Sprintf("maximum eval depth of %d exceeded.", maxEvalDepth),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	e.evalDepth++
+	loopDepth := e.loopDepth
+	e.loopDepth = 0
+	defer func() {
+		e.evalDepth--
+		e.loopDepth = loopDepth
+	}()
+	e.Frame = &frame.Frame{
+		FuncName: "<eval>",
+		Parent:   e.Frame,
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+	program, err := parseSource(source)
+	if err != nil {
+		return nil, e.toSyntaxError(err)
+	}
+	// 创建eval环境
+	evalEnv := object.NewEnvironment(env, nil)
+	var result object.Object = object.NullObj
+	for _, statement := range program.Statements {
+		// 表达式语句保留其值作为结果
+		if expressionStatement, ok := statement.(*ast.ExpressionStatement); ok {
+			result = e.Eval(expressionStatement.Expr, evalEnv)
+		} else {
+			result = e.Eval(statement, evalEnv)
+		}
+		if e.Err != nil {
+			return nil, e.Err
+		}
+		if ret, ok := result.(*object.ReturnValue); ok {
+			result = ret.Value
+			break
+		}
+		if result == nil {
+			result = object.NullObj
+		}
+	}
+	e.Frame = e.Frame.Parent
+	return result, nil
+}
+
+// parseSource 将源代码解析为程序，最后一条语句缺少分号时自动补全
+//
+// 参数:
+//
+//	source - 要解析的源代码
+//
+// 返回值:
+//
+//	*ast.Program - 解析得到的程序
+//	error - 词法或语法错误
+func parseSource(source string) (*ast.Program, error) {
+	program, err := parseProgram("<eval>", source)
+	var syntaxError *parser.SyntaxError
+	if errors.As(err, &syntaxError) && syntaxError.EOF && syntaxError.Expected == lexer.SEMICOLON {
+		return parseProgram("<eval>", source+";")
+	}
+	return program, err
+}
+
+// parseProgram 对源代码进行词法和语法分析
+//
+// 参数:
+//
+//	fileName - 源代码文件名，用于错误报告
+//	source - 要解析的源代码
+//
+// 返回值:
+//
+//	*ast.Program - 解析得到的程序
+//	error - 词法或语法错误
+func parseProgram(fileName, source string) (*ast.Program, error) {
+	l := lexer.NewLexer(fileName, source)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		return nil, err
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return program, nil
+}
+
+// toSyntaxError 将词法或语法错误转换为带调用栈的运行时语法错误
+//
+// 参数:
+//
+//	err - 词法或语法错误
+//
+// 返回值:
+//
+//	error - 带调用栈的语法错误，无法识别的错误原样返回
+func (e *Evaluator) toSyntaxError(err error) error {
+	switch parseError := err.(type) {
+	case *lexer.IllegalTokenError:
+		return &SyntaxError{
+			Code:     errcode.SyntaxError,
+			Frame:    e.Frame,
+			Message:  parseError.Message,
+			PosStart: parseError.PosStart,
+			PosEnd:   parseError.PosEnd,
+		}
+	case *parser.SyntaxError:
+		return &SyntaxError{
+			Code:     errcode.SyntaxError,
+			Frame:    e.Frame,
+			Message:  parseError.Message,
+			PosStart: parseError.PosStart,
+			PosEnd:   parseError.PosEnd,
+		}
+	default:
+		return err
+	}
 }
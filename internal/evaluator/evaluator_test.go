@@ -1,15 +1,28 @@
 package evaluator
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
 	"github.com/Ghost-Xiao/ghost-lang/internal/object"
 	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
 	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+	"github.com/Ghost-Xiao/ghost-lang/internal/testutil"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
 
@@ -45,9 +58,7 @@ true;`,
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.Eval(program, env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -84,9 +95,7 @@ true;`,
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalProgram(program, env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -124,9 +133,7 @@ func TestEvaluator_VisitIntExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalIntExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.IntExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -164,9 +171,7 @@ func TestEvaluator_VisitFloatExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalFloatExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.FloatExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -211,9 +216,7 @@ func TestEvaluator_VisitBooleanExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalBooleanExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.BoolExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -249,8 +252,42 @@ func TestEvaluator_VisitNullExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalNullExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.NullExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_SharedBoolAndNull(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "True Equals True",
+			input:    "print(true == true); print(1 < 2 == 3 < 4); print(null == null);",
+			excepted: "truetruetrue",
+		},
+		{
+			name:     "Reassigning A Variable Does Not Change Others",
+			input:    "var a = true; var b = a; b = !b; var c = 1 < 2; c = false; print(a); print(b); print(1 < 2);",
+			excepted: "truefalsetrue",
+		},
+		{
+			name:     "List Elements Are Independent",
+			input:    "var l = [true, true]; l[0] = false; print(l[1]); var n = [null, null]; n[0] = null; print(n[1]);",
+			excepted: "truenull",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runScoped(t, tt.input, true)
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if got != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
 			}
 		})
 	}
@@ -296,9 +333,7 @@ func TestEvaluator_VisitStringExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalStringExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.StringExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -389,6 +424,27 @@ func TestEvaluator_VisitListExpression(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "Null Elements In Integer List",
+			input: `[1, null, 3];`,
+			excepted: &object.List{
+				Elements: []object.Object{
+					&object.Int{Value: 1},
+					&object.Null{},
+					&object.Int{Value: 3},
+				},
+			},
+		},
+		{
+			name:  "Leading Null Element",
+			input: `[null, "a"];`,
+			excepted: &object.List{
+				Elements: []object.Object{
+					&object.Null{},
+					&object.String{Value: "a"},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -398,9 +454,99 @@ func TestEvaluator_VisitListExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalListExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.ListExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_NullableList(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Index Returns Null",
+			input:    `var xs = [1, null, 3]; xs[1];`,
+			excepted: &object.Null{},
+		},
+		{
+			name:     "Len Counts Null",
+			input:    `len([1, null, 3]);`,
+			excepted: &object.Int{Value: 3},
+		},
+		{
+			name:  "Assign Null Into Typed List",
+			input: `var xs = [1, 2]; xs[0] = null; xs;`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.Null{},
+				&object.Int{Value: 2},
+			}},
+		},
+		{
+			name:  "Assign Value Into Null List",
+			input: `var xs = [null, null]; xs[1] = "b"; xs;`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.Null{},
+				&object.String{Value: "b"},
+			}},
+		},
+		{
+			name:  "Concatenate Nullable Lists",
+			input: `[1, null] + [null, 2];`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.Int{Value: 1},
+				&object.Null{},
+				&object.Null{},
+				&object.Int{Value: 2},
+			}},
+		},
+		{
+			name:  "Inconsistent Type After Null",
+			input: `[1, null, "a"];`,
+			err:   "list elements must have consistent types.",
+		},
+		{
+			name:  "Assign Inconsistent Type",
+			input: `var xs = [null, 1]; xs[0] = "a";`,
+			err:   "list elements must have consistent types.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
 			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			var val object.Object
+			for _, statement := range program.Statements {
+				val = e.Eval(statement.(*ast.ExpressionStatement).Expr, env)
+				if e.Err != nil {
+					break
+				}
+			}
+			if tt.err != "" {
+				if e.Err == nil || !strings.Contains(e.Err.Error(), tt.err) {
+					t.Errorf("err = %+v, expected %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -462,9 +608,7 @@ func TestEvaluator_VisitIdentifierExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalIdentifierExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.IdentifierExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -502,9 +646,7 @@ func TestEvaluator_VisitVarInitializationExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalVarInitializationExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.VarInitializationExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -566,9 +708,7 @@ func TestEvaluator_VisitVarAssignmentExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalVarAssignmentExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.VarAssignmentExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
@@ -628,224 +768,559 @@ func TestEvaluator_VisitCompoundAssignmentExpression(t *testing.T) {
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
 			val := e.evalCompoundAssignmentExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.CompoundAssignmentExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
 
-func TestEvaluator_VisitPrefixUnaryIncDecExpression(t *testing.T) {
+// runProgram 在新的环境中执行程序，返回执行后的环境
+func runProgram(tb testing.TB, input string) *object.Environment {
+	tb.Helper()
 	env := &object.Environment{
-		Store: map[string]*object.Symbol{
-			"a": {
-				Name: "a",
-				Value: &object.Int{
-					Value: 1,
-				},
-			},
-			"l": {
-				Name: "l",
-				Value: &object.List{
-					Elements: []object.Object{
-						&object.Int{Value: 1},
-						&object.Int{Value: 2},
-						&object.Int{Value: 3},
-					},
-				},
-			},
-		},
+		Store: make(map[string]*object.Symbol),
 		Outer: nil,
 	}
-	f := &frame.Frame{
-		FuncName: "<test>",
-		Parent:   nil,
-		PosStart: nil,
-		PosEnd:   nil,
+	l := lexer.NewLexer("<test>", input)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		tb.Fatalf("err = %+v, expected nil", err)
 	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		tb.Fatalf("err = %+v, expected nil", p.Err)
+	}
+	e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+	e.Eval(program, env)
+	if e.Err != nil {
+		tb.Fatalf("err = %+v, expected nil", e.Err)
+	}
+	return env
+}
 
+func TestEvaluator_StringAppend(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
-		excepted object.Object
+		excepted map[string]string
 	}{
 		{
-			name:  "Prefix Unary Increment Expression",
-			input: "++a;",
-			excepted: &object.Int{
-				Value: 2,
-			},
+			name:     "Loop",
+			input:    `var s = ""; for var i = 0; i < 5; i += 1 { s += "ab"; };`,
+			excepted: map[string]string{"s": "ababababab"},
 		},
 		{
-			name:  "Prefix Unary Decrement Expression",
-			input: "--l[0];",
-			excepted: &object.Int{
-				Value: 0,
-			},
+			name:     "Copy Before Append",
+			input:    `var s = "a"; var t = s; s += "b"; t += "c";`,
+			excepted: map[string]string{"s": "ab", "t": "ac"},
+		},
+		{
+			name:     "Alias Of Appended String",
+			input:    `var s = "ab"; s += "c"; var t = s; s += "d"; t += "e"; s += "f";`,
+			excepted: map[string]string{"s": "abcdf", "t": "abce"},
+		},
+		{
+			name:     "Reassigned Between Appends",
+			input:    `var s = "ab"; s += "c"; var t = s; s = "x"; s += "y"; t += "z";`,
+			excepted: map[string]string{"s": "xy", "t": "abcz"},
+		},
+		{
+			name:     "Index Assignment Between Appends",
+			input:    `var s = "ab"; s += "c"; s[0] = "z"; s += "d";`,
+			excepted: map[string]string{"s": "zbcd"},
+		},
+		{
+			name:     "Empty Strings",
+			input:    `var s = ""; s += ""; s += "a";`,
+			excepted: map[string]string{"s": "a"},
+		},
+		{
+			name:     "Rune Indexing After Appends",
+			input:    `var s = "幽"; s += "灵"; s += "语言"; var c = s[3]; s[0] = "魂"; s += "!";`,
+			excepted: map[string]string{"s": "魂灵语言!", "c": "言"},
+		},
+		{
+			name:     "List Appends Keep Aliases Independent",
+			input:    `var l = [1]; l += [2]; var t = l; l += [3]; t[0] = 9; t += [4];`,
+			excepted: map[string]string{"l": "[1, 2, 3]", "t": "[9, 2, 4]"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			l := lexer.NewLexer("<test>", tt.input)
-			p, _ := parser.NewParser(l)
-			program := p.ParseProgram()
-			e := NewEvaluator(f)
-			val := e.evalPrefixUnaryIncDecExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.PrefixUnaryIncDecExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
+			env := runProgram(t, tt.input)
+			for name, excepted := range tt.excepted {
+				sym, ok := env.Get(name)
+				if !ok {
+					t.Fatalf("variable %s not found", name)
+				}
+				if got := sym.Value.String(); got != excepted {
+					t.Errorf("%s: excepted %q, got %q", name, excepted, got)
+				}
 			}
 		})
 	}
 }
 
-func TestEvaluator_VisitPostfixUnaryIncDecExpression(t *testing.T) {
-	env := &object.Environment{
-		Store: map[string]*object.Symbol{
-			"a": {
-				Name: "a",
-				Value: &object.Int{
-					Value: 1,
-				},
-			},
-			"l": {
-				Name: "l",
-				Value: &object.List{
-					Elements: []object.Object{
-						&object.Int{Value: 1},
-						&object.Int{Value: 2},
-						&object.Int{Value: 3},
-					},
-				},
-			},
-		},
-		Outer: nil,
-	}
-	f := &frame.Frame{
-		FuncName: "<test>",
-		Parent:   nil,
-		PosStart: nil,
-		PosEnd:   nil,
+func TestEvaluator_StringAppendIsLinear(t *testing.T) {
+	const n = 20000
+	input := fmt.Sprintf(`var s = ""; for var i = 0; i < %d; i += 1 { s += "x"; };`, n)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	runProgram(t, input)
+	runtime.ReadMemStats(&after)
+	allocated := after.TotalAlloc - before.TotalAlloc
+	// 逐次复制的拼接共需分配约 n*n/2 字节
+	if allocated >= n*n/8 {
+		t.Errorf("excepted linear allocation, got %d bytes for %d appends", allocated, n)
 	}
+}
 
+func TestEvaluator_InterpolatedString(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
-		excepted object.Object
+		excepted string
+		err      string
 	}{
 		{
-			name:  "Prefix Unary Increment Expression",
-			input: "a++;",
-			excepted: &object.Int{
-				Value: 1,
-			},
+			name:     "Variable",
+			input:    `var name = "Ghost"; print("Hello ${name}!");`,
+			excepted: "Hello Ghost!",
 		},
 		{
-			name:  "Prefix Unary Decrement Expression",
-			input: "l[0]--;",
-			excepted: &object.Int{
-				Value: 1,
-			},
+			name:     "Non-String Values",
+			input:    `print("${1 + 2} ${[1, 2]} ${null} ${true}");`,
+			excepted: "3 [1, 2] null true",
+		},
+		{
+			name:     "Multiple Interpolations",
+			input:    `var a = 1; var b = 2; print("${a}+${b}=${a + b}");`,
+			excepted: "1+2=3",
+		},
+		{
+			name:     "Nested Interpolated String",
+			input:    `func f(s) { return s + "!"; }; var x = "hi"; print("<${f("${x}?")}>");`,
+			excepted: "<hi?!>",
+		},
+		{
+			name:     "Braces In Expression",
+			input:    `var m = {"k": 1}; print("${ {"k": 2}["k"] + m["k"] }");`,
+			excepted: "3",
+		},
+		{
+			name:     "Newlines In Expression",
+			input:    "var x = 1; print(\"[${\nx\n}]\");",
+			excepted: "[1]",
+		},
+		{
+			name:     "Local Variables",
+			input:    `func greet(name) { var n = len(name); return "${name} has ${n} letters"; }; print(greet("ghost"));`,
+			excepted: "ghost has 5 letters",
+		},
+		{
+			name:     "Escaped Interpolation",
+			input:    `var x = 1; print("\${x} = ${x}");`,
+			excepted: "${x} = 1",
+		},
+		{
+			name:     "Single-Quoted String",
+			input:    `var x = 1; print('${x}');`,
+			excepted: "${x}",
+		},
+		{
+			name:  "Error In Expression",
+			input: `print("${1 / 0}");`,
+			err:   "division by zero.",
+		},
+		{
+			name:  "Undefined Name",
+			input: `print("${missing}");`,
+			err:   `undefined variable "missing".`,
 		},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			l := lexer.NewLexer("<test>", tt.input)
-			p, _ := parser.NewParser(l)
-			program := p.ParseProgram()
-			e := NewEvaluator(f)
-			val := e.evalPostfixUnaryIncDecExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.PostfixUnaryIncDecExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
-		})
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
 	}
 }
 
-func TestEvaluator_VisitPrefixExpression(t *testing.T) {
-	env := &object.Environment{
-		Store: make(map[string]*object.Symbol),
-		Outer: nil,
-	}
-	f := &frame.Frame{
-		FuncName: "<test>",
-		Parent:   nil,
-		PosStart: nil,
-		PosEnd:   nil,
-	}
-
+func TestEvaluator_TryCatch(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
-		excepted object.Object
+		excepted string
+		err      string
 	}{
 		{
-			name:  "Negative Int",
-			input: `-1;`,
-			excepted: &object.Int{
-				Value: -1,
-			},
+			name:     "Index Error",
+			input:    `try print([1][5]) catch (e) print(e["kind"] + " " + e["code"] + " " + e["message"]);`,
+			excepted: "Index Error E3004 index out of range.",
+		},
+		{
+			name:     "No Error",
+			input:    `try print("ok") catch (e) print("caught");`,
+			excepted: "ok",
+		},
+		{
+			name:     "Thrown String",
+			input:    `try throw "bad" catch (e) print(e["kind"] + ": " + e["message"]);`,
+			excepted: "Thrown Error: bad",
+		},
+		{
+			name:     "Thrown Value",
+			input:    `try throw {"code": 42} catch (e) print(e["value"]["code"]);`,
+			excepted: "42",
+		},
+		{
+			name:     "Runtime Error Has Null Value",
+			input:    `try 1 / 0 catch (e) print(e["value"]);`,
+			excepted: "null",
+		},
+		{
+			name:     "Error In Called Function",
+			input:    `func f(n) { if n == 0 { throw "zero"; }; return f(n - 1); }; try f(3) catch (e) print(e["message"]);`,
+			excepted: "zero",
+		},
+		{
+			name:     "Error In Builtin Callback",
+			input:    `try map(func(x) 1 / x, [1, 0]) catch (e) print(e["message"]);`,
+			excepted: "division by zero.",
+		},
+		{
+			name:     "Return From Body And Handler",
+			input:    `func f(x) { try return 10 / x catch (e) return -1.0; }; print(f(2)); print(f(0));`,
+			excepted: "5.000000-1.000000",
+		},
+		{
+			name:     "Break And Continue",
+			input:    `for var i = 0; i < 5; i++ { try { if i == 1 continue; if i == 3 break; throw i; } catch (e) print(e["value"]); };`,
+			excepted: "02",
+		},
+		{
+			name:     "Rethrow",
+			input:    `try { try throw "inner" catch (e) throw "outer: " + e["message"]; } catch (e) print(e["message"]);`,
+			excepted: "outer: inner",
+		},
+		{
+			name:     "Body Has Its Own Scope",
+			input:    `var x = 1; try { var x = 2; throw x; } catch (e) print(x); print(x);`,
+			excepted: "11",
+		},
+		{
+			name:     "Catch Variable Is Local",
+			input:    `var e = "outer"; try throw "inner" catch (e) print(e["message"]); print(e);`,
+			excepted: "innerouter",
+		},
+		{
+			name:     "Statements After Error Are Skipped",
+			input:    `try { print("a"); [][0]; print("b"); } catch (e) print("c");`,
+			excepted: "ac",
+		},
+		{
+			name:  "Uncaught Throw",
+			input: `throw "fatal";`,
+			err:   "fatal",
+		},
+		{
+			name:  "Error In Handler",
+			input: `try throw 1 catch (e) [][0];`,
+			err:   "index out of range.",
+		},
+		{
+			name:  "Break Outside Loop",
+			input: `try break catch (e) print("caught");`,
+			err:   "break statement is only allowed inside loops.",
 		},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			l := lexer.NewLexer("<test>", tt.input)
-			p, _ := parser.NewParser(l)
-			program := p.ParseProgram()
-			e := NewEvaluator(f)
-			val := e.evalPrefixExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.PrefixExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
-		})
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
 	}
 }
 
-func TestEvaluator_VisitBlockExpression(t *testing.T) {
-	env := &object.Environment{
-		Store: make(map[string]*object.Symbol),
-		Outer: nil,
+func TestEvaluator_TryDoesNotCatchExit(t *testing.T) {
+	got, err := runScoped(t, `try exit(3) catch (e) print("caught");`, false)
+	var exitErr *object.ExitError
+	if !errors.As(err, &exitErr) || got != "" {
+		t.Errorf("excepted an exit error and no output, got %q and error %+v", got, err)
 	}
-	f := &frame.Frame{
-		FuncName: "<test>",
-		Parent:   nil,
-		PosStart: nil,
-		PosEnd:   nil,
+}
+
+func TestEvaluator_UncaughtThrowFrame(t *testing.T) {
+	_, err := runScoped(t, "func f() { try [][0] catch (e) {}; throw \"bad\"; };\nf();", false)
+	thrown, ok := err.(*ThrownError)
+	if !ok {
+		t.Fatalf("err = %+v, expected *ThrownError", err)
+	}
+	if thrown.Code != errcode.ThrownError || thrown.Frame.FuncName != `<function "f">` || thrown.Frame.Parent.FuncName != "<test>" {
+		t.Errorf("excepted a thrown error in f called from <test>, got %q", thrown.Error())
 	}
+}
 
+func TestEvaluator_StringComparison(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
-		excepted object.Object
+		excepted string
+		err      string
 	}{
 		{
-			name:  "Block Expression",
-			input: `{ 1 };`,
-			excepted: &object.Int{
-				Value: 1,
-			},
+			name:     "Differing Byte",
+			input:    `print("abc" < "abd"); print("abc" > "abd"); print("abd" >= "abc"); print("abd" <= "abc");`,
+			excepted: "truefalsetruefalse",
+		},
+		{
+			name:     "Equal Prefix",
+			input:    `print("ab" < "abc"); print("abc" > "ab"); print("abc" <= "ab");`,
+			excepted: "truetruefalse",
+		},
+		{
+			name:     "Equal Strings",
+			input:    `print("ab" < "ab"); print("ab" <= "ab"); print("ab" >= "ab"); print("ab" > "ab");`,
+			excepted: "falsetruetruefalse",
+		},
+		{
+			name:     "Empty String",
+			input:    `print("" < "a"); print("" <= ""); print("" > ""); print("a" > "");`,
+			excepted: "truetruefalsetrue",
+		},
+		{
+			name:     "Byte Order",
+			input:    `print("Z" < "a"); print("é" > "z");`,
+			excepted: "truetrue",
+		},
+		{
+			name:     "Sort",
+			input:    `print(sort(["pear", "apple", "fig"]));`,
+			excepted: "[apple, fig, pear]",
+		},
+		{
+			name:  "String And Int",
+			input: `"1" < 2;`,
+			err:   `invalid operation "<".`,
+		},
+		{
+			name:  "Int And String",
+			input: `1 >= "2";`,
+			err:   `invalid operation ">=".`,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_AddHints(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "List Plus Int",
+			input:    `[1, 2] + 3;`,
+			excepted: "cannot add Int to List; concatenate a list instead, e.g. list + [element].",
+		},
+		{
+			name:     "List Plus String",
+			input:    `[1, 2] + "a";`,
+			excepted: "cannot add String to List; concatenate a list instead, e.g. list + [element].",
+		},
+		{
+			name:     "String Plus Int",
+			input:    `"a" + 1;`,
+			excepted: "cannot add Int to String; use str() to concatenate non-strings.",
+		},
+		{
+			name:     "String Plus List",
+			input:    `"a" + [1];`,
+			excepted: "cannot add List to String; use str() to concatenate non-strings.",
+		},
+		{
+			name:     "String Plus Map",
+			input:    `"a" + {"k": 1};`,
+			excepted: "cannot add Map to String; use str() to concatenate non-strings.",
+		},
+		{
+			name:     "List Plus Map",
+			input:    `[1] + {:};`,
+			excepted: "cannot add Map to List; concatenate a list instead, e.g. list + [element].",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
 			l := lexer.NewLexer("<test>", tt.input)
 			p, _ := parser.NewParser(l)
 			program := p.ParseProgram()
-			e := NewEvaluator(f)
-			val := e.evalBlockExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.BlockExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Eval(program, env)
+			var opErr *object.OperationError
+			if !errors.As(e.Err, &opErr) || opErr.Message != tt.excepted {
+				t.Errorf("err = %+v, expected %q", e.Err, tt.excepted)
 			}
 		})
 	}
 }
 
-func TestEvaluator_VisitIfExpression(t *testing.T) {
+func TestEvaluator_UndefinedVariableSuggestion(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Near Miss Of Builtin",
+			input:    `pritnln(1);`,
+			excepted: `undefined variable "pritnln". did you mean "println"?`,
+		},
+		{
+			name:     "Near Miss Of Local",
+			input:    `func f() { var length = 3; return lenght; }; f();`,
+			excepted: `undefined variable "lenght". did you mean "length"?`,
+		},
+		{
+			name:     "Near Miss Of Global In Function",
+			input:    `var counter = 0; func f() { conuter += 1; }; f();`,
+			excepted: `undefined variable "conuter". did you mean "counter"? declare it first with "var conuter = ..."`,
+		},
+		{
+			name:     "Closest Candidate",
+			input:    `var total = 1; var totals = 2; totl;`,
+			excepted: `undefined variable "totl". did you mean "total"?`,
+		},
+		{
+			name:     "No Close Match",
+			input:    `var apple = 1; zebra;`,
+			excepted: `undefined variable "zebra".`,
+		},
+		{
+			name:     "Short Name",
+			input:    `var ab = 1; ac;`,
+			excepted: `undefined variable "ac".`,
+		},
+		{
+			name:     "Out Of Scope Local",
+			input:    `func f() { var length = 3; }; f(); lenght;`,
+			excepted: `undefined variable "lenght".`,
+		},
+		{
+			name:     "Assignment To Undeclared",
+			input:    `x = 5;`,
+			excepted: `undefined variable "x". declare it first with "var x = ..."`,
+		},
+		{
+			name:     "Compound Assignment To Undeclared",
+			input:    `var apple = 1; total += 1;`,
+			excepted: `undefined variable "total". declare it first with "var total = ..."`,
+		},
+		{
+			name:     "Increment Of Undeclared",
+			input:    `count++;`,
+			excepted: `undefined variable "count". declare it first with "var count = ..."`,
+		},
+		{
+			name:     "Prefix Decrement Of Undeclared",
+			input:    `--count;`,
+			excepted: `undefined variable "count". declare it first with "var count = ..."`,
+		},
+		{
+			name:     "Index Assignment Reads The List",
+			input:    `xs[0] = 1;`,
+			excepted: `undefined variable "xs".`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Eval(program, env)
+			var varErr *VariableError
+			if !errors.As(e.Err, &varErr) || varErr.Message != tt.excepted {
+				t.Errorf("err = %+v, expected %q", e.Err, tt.excepted)
+			}
+		})
+	}
+}
+
+func BenchmarkEvaluator_StringAppend(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		input := fmt.Sprintf(`var s = ""; for var i = 0; i < %d; i += 1 { s += "x"; };`, n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				runProgram(b, input)
+			}
+		})
+	}
+}
+
+func TestEvaluator_VisitPrefixUnaryIncDecExpression(t *testing.T) {
 	env := &object.Environment{
-		Store: make(map[string]*object.Symbol),
+		Store: map[string]*object.Symbol{
+			"a": {
+				Name: "a",
+				Value: &object.Int{
+					Value: 1,
+				},
+			},
+			"l": {
+				Name: "l",
+				Value: &object.List{
+					Elements: []object.Object{
+						&object.Int{Value: 1},
+						&object.Int{Value: 2},
+						&object.Int{Value: 3},
+					},
+				},
+			},
+		},
 		Outer: nil,
 	}
 	f := &frame.Frame{
@@ -861,17 +1336,17 @@ func TestEvaluator_VisitIfExpression(t *testing.T) {
 		excepted object.Object
 	}{
 		{
-			name:  "If Expression",
-			input: `if true 1;`,
+			name:  "Prefix Unary Increment Expression",
+			input: "++a;",
 			excepted: &object.Int{
-				Value: 1,
+				Value: 2,
 			},
 		},
 		{
-			name:  "If-Else Expression",
-			input: `if false 1 else 2;`,
+			name:  "Prefix Unary Decrement Expression",
+			input: "--l[0];",
 			excepted: &object.Int{
-				Value: 2,
+				Value: 0,
 			},
 		},
 	}
@@ -882,109 +1357,40 @@ func TestEvaluator_VisitIfExpression(t *testing.T) {
 			p, _ := parser.NewParser(l)
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
-			val := e.evalIfExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.IfExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			val := e.evalPrefixUnaryIncDecExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.PrefixUnaryIncDecExpression), env)
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
 
-func TestEvaluator_VisitCallExpression(t *testing.T) {
-	f := &frame.Frame{
-		FuncName: "<test>",
-		Parent:   nil,
-		PosStart: nil,
-		PosEnd:   nil,
-	}
-
+func TestEvaluator_VisitPostfixUnaryIncDecExpression(t *testing.T) {
 	env := &object.Environment{
 		Store: map[string]*object.Symbol{
-			"f": {
-				Name: "f",
-				Value: &object.Function{
-					Name:      "f",
-					Parameter: make([]*ast.Parameter, 0),
-					Body: &ast.ExpressionStatement{
-						Expr: &ast.IntExpression{
-							Value:    1,
-							PosStart: util.NewPos(1, 10, 9, "<test>", "func f() 1;"),
-							PosEnd:   util.NewPos(1, 11, 10, "<test>", "func f() 1;"),
-						},
-					},
-					Env: &object.Environment{
-						Store: make(map[string]*object.Symbol),
-						Outer: nil,
-					},
-				},
-				IsConst: true,
-			},
-			"g": {
-				Name: "g",
-				Value: &object.Function{
-					Name: "g",
-					Parameter: []*ast.Parameter{
-						{
-							Name: &ast.IdentifierExpression{
-								Name:     "a",
-								PosStart: util.NewPos(1, 8, 7, "<test>", "func g(a=1) 1;"),
-								PosEnd:   util.NewPos(1, 9, 8, "<test>", "func g(a=1) 1;"),
-							},
-							DefaultValue: &ast.IntExpression{
-								Value:    1,
-								PosStart: util.NewPos(1, 10, 9, "<test>", "func g(a=1) 1;"),
-								PosEnd:   util.NewPos(1, 11, 10, "<test>", "func g(a=1) 1;"),
-							},
-							PosStart: util.NewPos(1, 8, 7, "<test>", "func g(a=1) 1;"),
-							PosEnd:   util.NewPos(1, 11, 10, "<test>", "func g(a=1) 1;"),
-						},
-					},
-					Body: &ast.ExpressionStatement{
-						Expr: &ast.IntExpression{
-							Value:    1,
-							PosStart: util.NewPos(1, 13, 12, "<test>", "func g(a=1) 1;"),
-							PosEnd:   util.NewPos(1, 14, 13, "<test>", "func g(a=1) 1;"),
-						},
-					},
-					Env: &object.Environment{
-						Store: make(map[string]*object.Symbol),
-						Outer: nil,
-					},
+			"a": {
+				Name: "a",
+				Value: &object.Int{
+					Value: 1,
 				},
-				IsConst: true,
 			},
-			"len": {
-				Name: "len",
-				Value: &object.BuiltinFunction{
-					Name:      "len",
-					Parameter: []string{"a"},
-					Fn: func(f *frame.Frame, posStart, posEnd *util.Pos, args ...object.Object) (object.Object, error) {
-						switch args[0].(type) {
-						case *object.String:
-							return &object.Int{
-								Value: int64(len(args[0].(*object.String).Value)),
-							}, nil
-						default:
-							return nil, &TypeError{
-								Frame: &frame.Frame{
-									FuncName: "<builtin \"len\">",
-									Parent:   f,
-									PosStart: posStart,
-									PosEnd:   posEnd,
-								},
-								Message:  fmt.Sprintf("invalid argument type %s for len()", args[0].Type()),
-								PosStart: posStart,
-								PosEnd:   posEnd,
-							}
-						}
+			"l": {
+				Name: "l",
+				Value: &object.List{
+					Elements: []object.Object{
+						&object.Int{Value: 1},
+						&object.Int{Value: 2},
+						&object.Int{Value: 3},
 					},
 				},
-				IsConst: true,
 			},
 		},
-
 		Outer: nil,
 	}
+	f := &frame.Frame{
+		FuncName: "<test>",
+		Parent:   nil,
+		PosStart: nil,
+		PosEnd:   nil,
+	}
 
 	tests := []struct {
 		name     string
@@ -992,26 +1398,19 @@ func TestEvaluator_VisitCallExpression(t *testing.T) {
 		excepted object.Object
 	}{
 		{
-			name:  "Call Expression",
-			input: `f();`,
+			name:  "Prefix Unary Increment Expression",
+			input: "a++;",
 			excepted: &object.Int{
 				Value: 1,
 			},
 		},
 		{
-			name:  "Call Function with Default Argument",
-			input: `g();`,
+			name:  "Prefix Unary Decrement Expression",
+			input: "l[0]--;",
 			excepted: &object.Int{
 				Value: 1,
 			},
 		},
-		{
-			name:  "Call Builtin Function",
-			input: `len("hello");`,
-			excepted: &object.Int{
-				Value: 5,
-			},
-		},
 	}
 
 	for _, tt := range tests {
@@ -1020,44 +1419,383 @@ func TestEvaluator_VisitCallExpression(t *testing.T) {
 			p, _ := parser.NewParser(l)
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
-			val := e.evalCallExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.CallExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
-			}
+			val := e.evalPostfixUnaryIncDecExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.PostfixUnaryIncDecExpression), env)
+			testutil.AssertObject(t, tt.excepted, val)
 		})
 	}
 }
 
-func TestEvaluator_VisitIndexExpression(t *testing.T) {
-	f := &frame.Frame{
-		FuncName: "<test>",
-		Parent:   nil,
-		PosStart: nil,
-		PosEnd:   nil,
+func TestEvaluator_IncDecOuterVariable(t *testing.T) {
+	env := runProgram(t, "var a = 0;\nvar b = 0;\nfor var i = 0; i < 3; i++ { if true { a++; ++a; }; };\nfunc f() { b--; --b; };\nf();")
+	for name, excepted := range map[string]int64{"a": 6, "b": -2} {
+		sym, ok := env.Get(name)
+		if !ok || !object.DeepEquals(sym.Value, &object.Int{Value: excepted}) {
+			t.Errorf("excepted %s = %d, got %+v", name, excepted, sym)
+		}
 	}
+}
+
+func TestEvaluator_IncDecInNestedScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Postfix Increment From Block",
+			input:    "var x = 1;\n{ print(x++); };\nprint(x);",
+			excepted: "12",
+		},
+		{
+			name:     "Prefix Increment From Block",
+			input:    "var x = 1;\nif true { print(++x); };\nprint(x);",
+			excepted: "22",
+		},
+		{
+			name:     "Postfix Decrement From Block",
+			input:    "var x = 1;\nfor var i = 0; i < 2; i++ { x--; };\nprint(x);",
+			excepted: "-1",
+		},
+		{
+			name:     "Prefix Decrement From Block",
+			input:    "var x = 1;\n{ { print(--x); }; };\nprint(x);",
+			excepted: "00",
+		},
+		{
+			name:     "Increment From Function",
+			input:    "var n = 0;\nfunc inc() { n++; return ++n; };\nprint(inc()); print(inc());",
+			excepted: "24",
+		},
+		{
+			name:     "Decrement From Nested Function",
+			input:    "var n = 5;\nfunc f() { func g() { n--; --n; }; g(); };\nf(); f();\nprint(n);",
+			excepted: "1",
+		},
+		{
+			name:     "Does Not Touch A Shadowing Local",
+			input:    "var x = 1;\n{ var x = 10; x++; print(x); };\nprint(x);",
+			excepted: "111",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				if err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+				if got != tt.excepted {
+					t.Errorf("excepted %q, got %q", tt.excepted, got)
+				}
+			})
+		}
+	}
+}
+
+// runScoped 执行程序并返回输出和错误，resolve为true时先进行作用域解析，局部变量按槽位存取
+func runScoped(t *testing.T, input string, resolve bool) (string, error) {
+	t.Helper()
+	p, err := parser.NewParser(lexer.NewLexer("<test>", input))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("parse err = %+v", p.Err)
+	}
+	if resolve {
+		sema.Resolve(program, sema.Options{File: "<test>"})
+	}
+	var stdout bytes.Buffer
+	e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+	e.Stdout = &stdout
+	e.Eval(program, object.NewGlobalEnvironment(nil))
+	return stdout.String(), e.Err
+}
+
+func TestEvaluator_ResolvedScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Shadowing In Blocks",
+			input:    "var x = 1;\n{ var x = 2; { var x = 3; print(x); }; print(x); };\nprint(x);",
+			excepted: "321",
+		},
+		{
+			name:     "Parameters And Locals",
+			input:    "func f(a, b = 10) { var c = a + b; { var d = c * 2; c = d; }; return c; };\nprint(f(1));\nprint(f(1, 2));",
+			excepted: "226",
+		},
+		{
+			name:     "Recursion",
+			input:    "func fib(n) { if n < 2 { return n; }; return fib(n - 1) + fib(n - 2); };\nprint(fib(15));",
+			excepted: "610",
+		},
+		{
+			name:     "Closure Over Loop Variable",
+			input:    "var total = 0;\nfor var i = 0; i < 4; i++ { func add() { total += i; }; add(); };\nprint(total);",
+			excepted: "6",
+		},
+		{
+			name:     "Updates Of Outer Variables",
+			input:    "func f() { var n = 0; var s = \"\"; for var i = 0; i < 3; i++ { if true { n++; ++n; s += \"ab\"; }; }; print(n); print(s); };\nf();",
+			excepted: "6ababab",
+		},
+		{
+			name:     "Local Shadowing A Builtin",
+			input:    "func f(s) { var n = len(s); { var len = 10; n += len; }; return n; };\nfunc g(len) { return len; };\nprint(f(\"ab\")); print(g(3)); print(len(\"abc\"));",
+			excepted: "1233",
+		},
+		{
+			name:     "Builtin Before Shadowing Declaration",
+			input:    "{ func f() { return len(\"ab\"); }; print(f()); var len = 5; print(f()); };",
+			excepted: "2",
+			err:      "the value is not a function and cannot be called.",
+		},
+		{
+			name:     "Outer Variable Before Shadowing Declaration",
+			input:    "var x = 1;\n{ func f() { return x; }; print(f()); var x = 2; print(f()); };",
+			excepted: "12",
+		},
+		{
+			name:     "Declaration In If Branch",
+			input:    "func f(c) { var x = 0; if c var x = 1; return x; };\nprint(f(true));\nprint(f(false));",
+			excepted: "00",
+		},
+		{
+			name:     "Eval Reads And Writes Locals",
+			input:    "func f() { var x = 1; eval(\"x = x + 41\"); return eval(\"x\"); };\nprint(f());",
+			excepted: "42",
+		},
+		{
+			name:  "Undefined Variable",
+			input: "func f() { var count = 1; return cuont; };\nf();",
+			err:   `undefined variable "cuont". did you mean "count"?`,
+		},
+		{
+			name:  "Variable Already Defined",
+			input: "func f() { var x = 1; var x = 2; };\nf();",
+			err:   `variable "x" already defined.`,
+		},
+		{
+			name:  "Assignment To Constant",
+			input: "func f() { const c = 1; c += 1; };\nf();",
+			err:   `cannot redefine constant "c".`,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
 
+func TestEvaluator_VisitPrefixExpression(t *testing.T) {
 	env := &object.Environment{
-		Store: map[string]*object.Symbol{
-			"lst": {
-				Name: "lst",
-				Value: &object.List{
-					Elements: []object.Object{
-						&object.Int{
-							Value: 1,
-						},
-						&object.Int{
-							Value: 2,
-						},
-						&object.Int{
-							Value: 3,
-						},
-					},
-				},
-				IsConst: true,
+		Store: make(map[string]*object.Symbol),
+		Outer: nil,
+	}
+	f := &frame.Frame{
+		FuncName: "<test>",
+		Parent:   nil,
+		PosStart: nil,
+		PosEnd:   nil,
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+	}{
+		{
+			name:  "Negative Int",
+			input: `-1;`,
+			excepted: &object.Int{
+				Value: -1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.evalPrefixExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.PrefixExpression), env)
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_NegativeEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		value    object.Object
+		excepted object.Object
+		err      error
+	}{
+		{
+			name:     "Max Int",
+			input:    `-x;`,
+			value:    &object.Int{Value: math.MaxInt64},
+			excepted: &object.Int{Value: -math.MaxInt64},
+		},
+		{
+			name:  "Min Int Overflow",
+			input: `-x;`,
+			value: &object.Int{Value: math.MinInt64},
+			err:   &object.MathError{},
+		},
+		{
+			name:  "Min Int From Arithmetic",
+			input: `-(-9223372036854775807 - 1);`,
+			err:   &object.MathError{},
+		},
+		{
+			name:     "Positive Zero Float",
+			input:    `-x;`,
+			value:    &object.Float{Value: 0},
+			excepted: &object.Float{Value: math.Copysign(0, -1)},
+		},
+		{
+			name:     "Negative Zero Float",
+			input:    `-x;`,
+			value:    &object.Float{Value: math.Copysign(0, -1)},
+			excepted: &object.Float{Value: 0},
+		},
+		{
+			name:     "Infinity",
+			input:    `-x;`,
+			value:    &object.Float{Value: math.Inf(1)},
+			excepted: &object.Float{Value: math.Inf(-1)},
+		},
+		{
+			name:     "NaN",
+			input:    `-x;`,
+			value:    &object.Float{Value: math.NaN()},
+			excepted: &object.Float{Value: math.NaN()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			if tt.value != nil {
+				env.Store["x"] = &object.Symbol{Name: "x", Value: tt.value}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("unexpected parse error: %v", p.Err)
+			}
+			e := NewEvaluator(f)
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != nil {
+				if reflect.TypeOf(e.Err) != reflect.TypeOf(tt.err) {
+					t.Fatalf("excepted %T, got %v", tt.err, e.Err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("unexpected error: %v", e.Err)
+			}
+			switch excepted := tt.excepted.(type) {
+			case *object.Float:
+				got, ok := val.(*object.Float)
+				if !ok {
+					t.Fatalf("excepted %+v, got %+v", tt.excepted, val)
+				}
+				// NaN与任何值都不相等，-0.0与0.0相等，因此需要分别比较
+				if math.IsNaN(excepted.Value) {
+					if !math.IsNaN(got.Value) {
+						t.Errorf("excepted %v, got %v", excepted.Value, got.Value)
+					}
+				} else if got.Value != excepted.Value || math.Signbit(got.Value) != math.Signbit(excepted.Value) {
+					t.Errorf("excepted %v, got %v", excepted.Value, got.Value)
+				}
+			default:
+				testutil.AssertObject(t, tt.excepted, val)
+			}
+		})
+	}
+}
+
+func TestEvaluator_VisitBlockExpression(t *testing.T) {
+	env := &object.Environment{
+		Store: make(map[string]*object.Symbol),
+		Outer: nil,
+	}
+	f := &frame.Frame{
+		FuncName: "<test>",
+		Parent:   nil,
+		PosStart: nil,
+		PosEnd:   nil,
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+	}{
+		{
+			name:  "Block Expression",
+			input: `{ 1 };`,
+			excepted: &object.Int{
+				Value: 1,
 			},
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.evalBlockExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.BlockExpression), env)
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_VisitIfExpression(t *testing.T) {
+	env := &object.Environment{
+		Store: make(map[string]*object.Symbol),
 		Outer: nil,
 	}
+	f := &frame.Frame{
+		FuncName: "<test>",
+		Parent:   nil,
+		PosStart: nil,
+		PosEnd:   nil,
+	}
 
 	tests := []struct {
 		name     string
@@ -1065,8 +1803,15 @@ func TestEvaluator_VisitIndexExpression(t *testing.T) {
 		excepted object.Object
 	}{
 		{
-			name:  "Index Expression",
-			input: `lst[1];`,
+			name:  "If Expression",
+			input: `if true 1;`,
+			excepted: &object.Int{
+				Value: 1,
+			},
+		},
+		{
+			name:  "If-Else Expression",
+			input: `if false 1 else 2;`,
 			excepted: &object.Int{
 				Value: 2,
 			},
@@ -1079,9 +1824,3758 @@ func TestEvaluator_VisitIndexExpression(t *testing.T) {
 			p, _ := parser.NewParser(l)
 			program := p.ParseProgram()
 			e := NewEvaluator(f)
-			val := e.evalIndexExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.IndexExpression), env)
-			if !reflect.DeepEqual(val, tt.excepted) {
-				t.Errorf("excepted %+v, got %+v", tt.excepted, val)
+			val := e.evalIfExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.IfExpression), env)
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_IfStatementReturn(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted map[string]object.Object
+	}{
+		{
+			name:  "Both Branches Return",
+			input: `func f(x) { if x > 0 { return 1; } else { return 2; }; return 3; }; var a = f(1); var b = f(-1);`,
+			excepted: map[string]object.Object{
+				"a": &object.Int{Value: 1},
+				"b": &object.Int{Value: 2},
+			},
+		},
+		{
+			name:  "Branches Of Different Types",
+			input: `func f(x) { if x { return "yes"; } else { return 2.5; }; }; var a = f(true); var b = f(false);`,
+			excepted: map[string]object.Object{
+				"a": &object.String{Value: "yes"},
+				"b": &object.Float{Value: 2.5},
+			},
+		},
+		{
+			name:  "Value Discarded Without Return",
+			input: `func f(x) { if x { 1; } else { "a"; }; return 3; }; var a = f(true); var b = f(false);`,
+			excepted: map[string]object.Object{
+				"a": &object.Int{Value: 3},
+				"b": &object.Int{Value: 3},
+			},
+		},
+		{
+			name:  "Nested Else If",
+			input: `func f(x) { if x == 1 { return 1; } else if x == 2 { if true { return 2; }; }; return 0; }; var a = f(2); var b = f(3);`,
+			excepted: map[string]object.Object{
+				"a": &object.Int{Value: 2},
+				"b": &object.Int{Value: 0},
+			},
+		},
+		{
+			name:  "Return From Loop",
+			input: `func f(n) { for var i = 0; i < 10; i += 1 { if i == n { return i * 10; }; }; return -1; }; var a = f(3); var b = f(20);`,
+			excepted: map[string]object.Object{
+				"a": &object.Int{Value: 30},
+				"b": &object.Int{Value: -1},
+			},
+		},
+		{
+			name:  "Return From Nested Block",
+			input: `func f(x) { { if x { return 1; }; }; return 2; }; var a = f(true); var b = f(false);`,
+			excepted: map[string]object.Object{
+				"a": &object.Int{Value: 1},
+				"b": &object.Int{Value: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := runProgram(t, tt.input)
+			for name, excepted := range tt.excepted {
+				sym, ok := env.Get(name)
+				if !ok {
+					t.Fatalf("variable %s not found", name)
+				}
+				if !object.DeepEquals(sym.Value, excepted) {
+					t.Errorf("%s: excepted %+v, got %+v", name, excepted, sym.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluator_TopLevelReturnInIf(t *testing.T) {
+	l := lexer.NewLexer("<test>", `var a = 1; if true { return 5; }; a = 2;`)
+	p, _ := parser.NewParser(l)
+	program := p.ParseProgram()
+	env := &object.Environment{
+		Store: make(map[string]*object.Symbol),
+		Outer: nil,
+	}
+	e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+	e.Eval(program, env)
+	err, ok := e.Err.(*SyntaxError)
+	if !ok || err.Message != "return statement is only allowed inside functions." {
+		t.Fatalf("err = %+v, expected return outside function error", e.Err)
+	}
+	if sym, _ := env.Get("a"); !object.DeepEquals(sym.Value, &object.Int{Value: 1}) {
+		t.Errorf("excepted execution to stop at the return, got a = %+v", sym.Value)
+	}
+}
+
+func TestEvaluator_TopLevelBreakInIf(t *testing.T) {
+	l := lexer.NewLexer("<test>", `var a = 1; if true { break; }; a = 2;`)
+	p, _ := parser.NewParser(l)
+	program := p.ParseProgram()
+	env := &object.Environment{
+		Store: make(map[string]*object.Symbol),
+		Outer: nil,
+	}
+	e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+	e.Eval(program, env)
+	err, ok := e.Err.(*SyntaxError)
+	if !ok || err.Message != "break statement is only allowed inside loops." {
+		t.Fatalf("err = %+v, expected break outside loop error", e.Err)
+	}
+	if err.PosStart.Col != 22 || err.PosEnd.Col != 27 {
+		t.Errorf("excepted the error at columns 22-27, got %d-%d", err.PosStart.Col, err.PosEnd.Col)
+	}
+	if sym, _ := env.Get("a"); !object.DeepEquals(sym.Value, &object.Int{Value: 1}) {
+		t.Errorf("excepted execution to stop at the break, got a = %+v", sym.Value)
+	}
+}
+
+func TestEvaluator_VisitCallExpression(t *testing.T) {
+	f := &frame.Frame{
+		FuncName: "<test>",
+		Parent:   nil,
+		PosStart: nil,
+		PosEnd:   nil,
+	}
+
+	env := &object.Environment{
+		Store: map[string]*object.Symbol{
+			"f": {
+				Name: "f",
+				Value: &object.Function{
+					Name:      "f",
+					Parameter: make([]*ast.Parameter, 0),
+					Body: &ast.ExpressionStatement{
+						Expr: &ast.IntExpression{
+							Value:    1,
+							PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "func f() 1;")),
+							PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func f() 1;")),
+						},
+					},
+					Env: &object.Environment{
+						Store: make(map[string]*object.Symbol),
+						Outer: nil,
+					},
+				},
+				IsConst: true,
+			},
+			"g": {
+				Name: "g",
+				Value: &object.Function{
+					Name: "g",
+					Parameter: []*ast.Parameter{
+						{
+							Name: &ast.IdentifierExpression{
+								Name:     "a",
+								PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+								PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+							},
+							DefaultValue: &ast.IntExpression{
+								Value:    1,
+								PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+								PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+							},
+							PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+							PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+						},
+					},
+					Body: &ast.ExpressionStatement{
+						Expr: &ast.IntExpression{
+							Value:    1,
+							PosStart: util.NewPos(1, 13, 12, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+							PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "func g(a=1) 1;")),
+						},
+					},
+					Env: &object.Environment{
+						Store: make(map[string]*object.Symbol),
+						Outer: nil,
+					},
+				},
+				IsConst: true,
+			},
+			"len": {
+				Name: "len",
+				Value: &object.BuiltinFunction{
+					Name:      "len",
+					Parameter: []string{"a"},
+					Fn: func(_ object.Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...object.Object) (object.Object, error) {
+						switch args[0].(type) {
+						case *object.String:
+							return &object.Int{
+								Value: int64(len(args[0].(*object.String).Value)),
+							}, nil
+						default:
+							return nil, &TypeError{
+								Frame: &frame.Frame{
+									FuncName: "<builtin \"len\">",
+									Parent:   f,
+									PosStart: posStart,
+									PosEnd:   posEnd,
+								},
+								Message:  fmt.Sprintf("invalid argument type %s for len()", args[0].Type()),
+								PosStart: posStart,
+								PosEnd:   posEnd,
+							}
+						}
+					},
+				},
+				IsConst: true,
+			},
+		},
+
+		Outer: nil,
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+	}{
+		{
+			name:  "Call Expression",
+			input: `f();`,
+			excepted: &object.Int{
+				Value: 1,
+			},
+		},
+		{
+			name:  "Call Function with Default Argument",
+			input: `g();`,
+			excepted: &object.Int{
+				Value: 1,
+			},
+		},
+		{
+			name:  "Call Builtin Function",
+			input: `len("hello");`,
+			excepted: &object.Int{
+				Value: 5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.evalCallExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.CallExpression), env)
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_ForUpdateClause(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Compound Assignment",
+			input:    "for var i = 0; i < 10; i += 2 println(i);",
+			excepted: "0\n2\n4\n6\n8\n",
+		},
+		{
+			name:     "Assignment",
+			input:    "for var i = 1; i < 10; i = i * 2 { println(i); };",
+			excepted: "1\n2\n4\n8\n",
+		},
+		{
+			name:     "Function Call",
+			input:    "var n = 0;\nfunc update() { n += 3; };\nfor var i = 0; n < 9; update() println(n);\nprintln(\"done\");",
+			excepted: "0\n3\n6\ndone\n",
+		},
+		{
+			name:     "Update Sees Body Changes",
+			input:    "for var i = 0; i < 6; i *= 2 { println(i); i += 1; };",
+			excepted: "0\n2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			var out bytes.Buffer
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Stdout = &out
+			e.Eval(program, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			if out.String() != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, out.String())
+			}
+		})
+	}
+}
+
+func TestEvaluator_WhileStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Counting",
+			input:    "var i = 0;\nwhile i < 3 { print(i); i++; };",
+			excepted: "012",
+		},
+		{
+			name:     "False Condition",
+			input:    "while false print(1);\nprint(\"done\");",
+			excepted: "done",
+		},
+		{
+			name:     "Empty Body",
+			input:    "var n = 0;\nfunc next() { n++; return n < 3; };\nwhile next() {};\nprint(n);",
+			excepted: "3",
+		},
+		{
+			name:     "Return From Body",
+			input:    "func f() { var n = 0; while true { n += 1; if n == 4 { return n; }; }; };\nprint(f());",
+			excepted: "4",
+		},
+		{
+			name:     "Declaration In Body Per Iteration",
+			input:    "func f() { var i = 0; while i < 3 var x = i++; return i; };\nprint(f());",
+			excepted: "3",
+		},
+		{
+			name:     "Closure Over Body Variable",
+			input:    "var fs = [];\nvar i = 0;\nwhile i < 3 { var j = i; func get() { return j; }; fs += [get]; i++; };\nprint(fs[0]()); print(fs[2]());",
+			excepted: "02",
+		},
+		{
+			name:     "Non-Bool Condition",
+			input:    "var i = 0;\nwhile i < 2 { print(i); i++; };\nwhile i print(i);",
+			excepted: "01",
+			err:      "non-bool condition in while loop.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_MapExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Index",
+			input:    "var m = {\"a\": 1, 2: \"b\", true: [3], 1.5: null};\nprint(m[\"a\"]); print(m[2]); print(m[true]); print(m[1.5]);",
+			excepted: "1b[3]null",
+		},
+		{
+			name:     "Int And Float Keys Are Distinct",
+			input:    "var m = {1: \"int\", 1.0: \"float\"};\nprint(m[1]); print(m[1.0]); print(len(m));",
+			excepted: "intfloat2",
+		},
+		{
+			name:     "Duplicate Keys Keep The Last Value",
+			input:    "var m = {\"a\": 1, \"b\": 2, \"a\": 3};\nprint(m);",
+			excepted: "{a: 3, b: 2}",
+		},
+		{
+			name:     "Assignment",
+			input:    "var m = {:};\nm[\"x\"] = 1; m[\"y\"] = 2; m[\"x\"] += 10; m[\"y\"]++;\nprint(m); print(len(m));",
+			excepted: "{x: 11, y: 3}2",
+		},
+		{
+			name:     "Equality Ignores Order",
+			input:    "print({\"a\": 1, \"b\": [2]} == {\"b\": [2], \"a\": 1}); print({\"a\": 1} != {\"a\": 2});",
+			excepted: "truetrue",
+		},
+		{
+			name:     "Missing Key",
+			input:    "var m = {\"a\": 1};\nprint(m[\"a\"]); print(m[\"b\"]);",
+			excepted: "1",
+			err:      "key \"b\" not found in map.",
+		},
+		{
+			name:  "Unhashable Key",
+			input: "var m = {[1]: 2};",
+			err:   "map key must be Int, Float, String or Bool, not List.",
+		},
+		{
+			name:     "Self Referential Maps",
+			input:    "var m = {:}; m[\"self\"] = m;\nvar n = {:}; n[\"self\"] = n;\nprint(m); print(m == n); print(m != n);",
+			excepted: "{self: {...}}truefalse",
+		},
+		{
+			name:     "Empty Braces",
+			input:    "var m = {};\nprintln(m); print(len(m)); m[\"a\"] = 1; print(m);",
+			excepted: "{:}\n0{a: 1}",
+		},
+		{
+			name:     "Empty Braces Comparison",
+			input:    "var a = {:};\nprint({:} == {}); print(a == {}); print(a != {}); print({} == [{}][0]);",
+			excepted: "truetruefalsetrue",
+		},
+		{
+			name:     "Empty Block Statement",
+			input:    "var r = {};\n{};\nfunc f() {};\nprint(f()); print(if true {} else { 1 }); print(r == {});",
+			excepted: "nullnulltrue",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_WhileStatementEnvironment(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "False Condition", input: "var x = 1; while false var y = x;"},
+		{name: "Empty Body", input: "var x = 1; while x < 1 {};"},
+		{name: "Declarations In Body", input: "var x = 1; while x < 3 { var y = x; x++; };"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := parser.NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			env := &object.Environment{Store: make(map[string]*object.Symbol)}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Eval(program, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			if len(env.Store) != 1 || env.Store["x"] == nil {
+				t.Errorf("excepted only x in the environment, got %v", env.Names())
+			}
+		})
+	}
+}
+
+func TestEvaluator_ForInStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "List",
+			input:    "var s = 0;\nfor x in [1, 2, 3] s += x;\nprint(s);",
+			excepted: "6",
+		},
+		{
+			name:     "String Runes",
+			input:    "for ch in \"幽灵a\" { print(ch); print(\",\"); };",
+			excepted: "幽,灵,a,",
+		},
+		{
+			name:     "Empty List",
+			input:    "for x in [] print(x);\nprint(\"done\");",
+			excepted: "done",
+		},
+		{
+			name:     "Return From Body",
+			input:    "func find(l, t) { for x in l { if x == t return \"found\"; }; return \"missing\"; };\nprint(find([1, 2], 2)); print(find([1, 2], 5));",
+			excepted: "foundmissing",
+		},
+		{
+			name:     "Break And Continue",
+			input:    "for x in [1, 2, 3, 4, 5] { if x == 2 continue; if x == 4 break; print(x); };",
+			excepted: "13",
+		},
+		{
+			name:     "Nested Loops",
+			input:    "for a in [1, 2] for b in \"xy\" { if b == \"y\" break; print(a); print(b); };",
+			excepted: "1x2x",
+		},
+		{
+			name:     "Fresh Variable Per Iteration",
+			input:    "var fs = [];\nfor x in [1, 2] fs = fs + [func() x];\nprint(fs[0]()); print(fs[1]());",
+			excepted: "12",
+		},
+		{
+			name:  "Variable Is Local To The Loop",
+			input: "for x in [1] {};\nprint(x);",
+			err:   "undefined variable \"x\".",
+		},
+		{
+			name:     "Body Can Assign Outer Variables",
+			input:    "var last = null;\nfor x in [1, 2, 3] last = x;\nprint(last);",
+			excepted: "3",
+		},
+		{
+			name:     "Iterator",
+			input:    "for x in range(3) print(x);",
+			excepted: "012",
+		},
+		{
+			name:     "Not Iterable",
+			input:    "print(1);\nfor x in 5 {};",
+			excepted: "1",
+			err:      "cannot iterate over Int.",
+		},
+		{
+			name:     "Var Keyword",
+			input:    "for var x in [1, 2] print(x);",
+			excepted: "12",
+		},
+		{
+			name:     "Map Keys In Insertion Order",
+			input:    "for k in {\"b\": 1, \"a\": 2, 3: 4} print(k);",
+			excepted: "ba3",
+		},
+		{
+			name:     "Map Keys And Values",
+			input:    "for var k, v in {\"a\": 1, \"b\": 2} { print(k); print(v); };",
+			excepted: "a1b2",
+		},
+		{
+			name:     "Keys Added While Iterating",
+			input:    "var m = {1: 1, 2: 2};\nfor k, v in m m[k * 10] = v;\nprint(len(m));",
+			excepted: "4",
+		},
+		{
+			name:     "Break And Continue Over Map",
+			input:    "for k, v in {1: 1, 2: 2, 3: 3, 4: 4} { if k == 2 continue; if v == 4 break; print(k); };",
+			excepted: "13",
+		},
+		{
+			name:     "Two Variables Over List",
+			input:    "print(1);\nfor k, v in [1, 2] {};",
+			excepted: "1",
+			err:      "two loop variables require a Map, got List.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_BreakStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Break From For",
+			input:    "var last = -1;\nfor var i = 0; i < 10; i++ { last = i; if i == 3 { break; }; };\nprint(last);",
+			excepted: "3",
+		},
+		{
+			name:     "Break From Nested Block",
+			input:    "var n = 0;\nwhile true { n++; { { if n >= 5 break; }; }; print(n); };\nprint(n);",
+			excepted: "12345",
+		},
+		{
+			name:     "Statements After Break Are Skipped",
+			input:    "var a = 0;\nvar b = 0;\nwhile a < 3 { a++; break; b++; };\nprint(a); print(b);",
+			excepted: "10",
+		},
+		{
+			name:     "Break Ends Only The Innermost Loop",
+			input:    "var count = 0;\nfor var i = 0; i < 3; i++ { for var j = 0; j < 3; j++ { if j == 1 break; count++; }; count += 10; };\nprint(count);",
+			excepted: "33",
+		},
+		{
+			name:     "Return After Break In Function",
+			input:    "func f() { var i = 0; while true { if i == 2 break; i++; }; return i * 10; };\nprint(f());",
+			excepted: "20",
+		},
+		{
+			name:     "Top Level Break",
+			input:    "print(1);\nbreak;\nprint(2);",
+			excepted: "1",
+			err:      "break statement is only allowed inside loops.",
+		},
+		{
+			name:     "Break In Function Called From Loop",
+			input:    "func stop() { break; };\nwhile true { print(1); stop(); };",
+			excepted: "1",
+			err:      "break statement is only allowed inside loops.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_ContinueStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Continue In For Runs Update",
+			input:    "var sum = 0;\nfor var i = 0; i < 10; i++ { if i % 2 == 0 { continue; }; sum += i; };\nprint(sum);",
+			excepted: "25",
+		},
+		{
+			name:     "Continue In While From Nested Block",
+			input:    "var n = 0;\nwhile n < 6 { n++; { if n % 3 != 0 continue; }; print(n); };",
+			excepted: "36",
+		},
+		{
+			name:     "Continue Skips Only The Innermost Loop",
+			input:    "for var i = 0; i < 2; i++ { for var j = 0; j < 3; j++ { if j == 1 continue; print(j); }; print(\"|\"); };",
+			excepted: "02|02|",
+		},
+		{
+			name:     "Continue And Break",
+			input:    "var i = 0;\nwhile true { i++; if i < 3 continue; if i == 5 break; print(i); };",
+			excepted: "34",
+		},
+		{
+			name:     "Mutation Before Continue Is Seen By Update And Condition",
+			input:    "for var i = 0; i < 10; i++ { if i == 2 { i += 5; continue; }; print(i); };",
+			excepted: "0189",
+		},
+		{
+			name:     "Continue In Nested If",
+			input:    "for var i = 0; i < 6; i++ { if i > 1 { if i < 4 { continue; }; }; print(i); };",
+			excepted: "0145",
+		},
+		{
+			name:     "Continue And Break In For",
+			input:    "var last = 0;\nfor var i = 0; i < 100; i++ { if i % 2 == 1 continue; if i > 6 break; last = i; print(i); };\nprint(last);",
+			excepted: "02466",
+		},
+		{
+			name:     "Top Level Continue",
+			input:    "print(1);\ncontinue;\nprint(2);",
+			excepted: "1",
+			err:      "continue statement is only allowed inside loops.",
+		},
+		{
+			name:     "Continue In Function Called From Loop",
+			input:    "func skip() { continue; };\nfor var i = 0; i < 3; i++ { print(i); skip(); };",
+			excepted: "0",
+			err:      "continue statement is only allowed inside loops.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_TernaryExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Chooses A Branch",
+			input:    "var a = 5;\nprint(a > 3 ? \"big\" : \"small\"); print(a > 9 ? \"big\" : \"small\");",
+			excepted: "bigsmall",
+		},
+		{
+			name:     "Nested",
+			input:    "func sign(n) { return n > 0 ? 1 : n < 0 ? -1 : 0; };\nprint(sign(3)); print(sign(-2)); print(sign(0));",
+			excepted: "1-10",
+		},
+		{
+			name:     "Only The Chosen Branch Is Evaluated",
+			input:    "func f(x) { print(x); return x; };\nvar r = true ? f(\"a\") : f(\"b\");\nprint(r);",
+			excepted: "aa",
+		},
+		{
+			name:     "Branches Use The Current Environment",
+			input:    "var n = 0;\ntrue ? n += 2 : n++;\nfalse ? n += 2 : n++;\nprint(n);",
+			excepted: "3",
+		},
+		{
+			name:     "Non-Bool Condition",
+			input:    "print(1);\nprint(1 ? 2 : 3);",
+			excepted: "1",
+			err:      "non-bool condition in ternary expression.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_FunctionExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Stored In A Variable",
+			input:    "var add = func(a, b=1) a + b;\nprint(add(2)); print(add(2, 3));",
+			excepted: "35",
+		},
+		{
+			name:     "Captures The Environment",
+			input:    "func counter() { var n = 0; return func() { n++; return n; }; };\nvar c = counter();\nc(); print(c());",
+			excepted: "2",
+		},
+		{
+			name:     "Immediately Invoked",
+			input:    "print((func(x) x * 2)(21));",
+			excepted: "42",
+		},
+		{
+			name:     "Passed To A Builtin",
+			input:    "print(reduce(func(acc, x) acc + x, [1, 2, 3], 10));",
+			excepted: "16",
+		},
+		{
+			name:     "String",
+			input:    "print(func(a, b=2) a);",
+			excepted: "func(a, b=2) {...}",
+		},
+		{
+			name:     "Reads Variables Declared Later",
+			input:    "var f = func() x;\nvar x = 7;\nprint(f());",
+			excepted: "7",
+		},
+		{
+			name:     "Error Inside",
+			input:    "var f = func() 1 / 0;\nprint(1);\nf();",
+			excepted: "1",
+			err:      "division by zero.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_AnonymousFunctionFrame(t *testing.T) {
+	_, err := runScoped(t, "var f = func() 1 / 0;\nf();", false)
+	if err == nil {
+		t.Fatalf("excepted an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "in <anonymous function>") {
+		t.Errorf("excepted the anonymous function in the traceback, got %q", err.Error())
+	}
+}
+
+func TestEvaluator_SliceExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "List Bounds",
+			input:    "var l = [1, 2, 3, 4, 5];\nprint(l[1:3]); print(l[1:]); print(l[:3]); print(l[:]);",
+			excepted: "[2, 3][2, 3, 4, 5][1, 2, 3][1, 2, 3, 4, 5]",
+		},
+		{
+			name:     "Negative Indices",
+			input:    "var l = [1, 2, 3, 4, 5];\nprint(l[-2:]); print(l[:-1]); print(l[-3:-1]);",
+			excepted: "[4, 5][1, 2, 3, 4][3, 4]",
+		},
+		{
+			name:     "Out Of Range Bounds Are Clamped",
+			input:    "var l = [1, 2, 3];\nprint(l[-10:10]); print(l[5:]); print(l[2:1]);",
+			excepted: "[1, 2, 3][][]",
+		},
+		{
+			name:     "String",
+			input:    "var s = \"幽灵语言\";\nprint(s[1:3]); print(s[-1:]); print(s[:-1]); print(len(s[9:]));",
+			excepted: "灵语言幽灵语0",
+		},
+		{
+			name:     "Slice Is A Copy",
+			input:    "var l = [1, 2, 3];\nvar t = l[:];\nt[0] = 9;\nprint(l); print(t);",
+			excepted: "[1, 2, 3][9, 2, 3]",
+		},
+		{
+			name:     "Bounds Are Expressions",
+			input:    "var l = [1, 2, 3, 4];\nvar i = 1;\nprint(l[i:len(l) - i]);",
+			excepted: "[2, 3]",
+		},
+		{
+			name:     "Non-Integer Bound",
+			input:    "var l = [1, 2];\nprint(1);\nl[1:\"a\"];",
+			excepted: "1",
+			err:      "slice index must be integer.",
+		},
+		{
+			name:  "Unsupported Target",
+			input: "var m = {\"a\": 1};\nm[0:1];",
+			err:   "slice expression not supported for this type.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_MapMissingKeyPosition(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		start int
+		end   int
+	}{
+		{name: "Read", input: `var m = {"a": 1}; m["b"];`, start: 19, end: 25},
+		{name: "Compound Assignment", input: `var m = {"a": 1}; m["b"] += 1;`, start: 19, end: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := parser.NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Eval(program, object.NewGlobalEnvironment(nil))
+			err, ok := e.Err.(*object.IndexError)
+			if !ok {
+				t.Fatalf("err = %+v, expected an index error", e.Err)
+			}
+			if err.PosStart.Col != tt.start || err.PosEnd.Col != tt.end {
+				t.Errorf("excepted the error at columns %d-%d, got %d-%d", tt.start, tt.end, err.PosStart.Col, err.PosEnd.Col)
+			}
+		})
+	}
+}
+
+func TestEvaluator_DistinctParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Similar Names",
+			input:    "func f(a, ab, b) { return a * 100 + ab * 10 + b; };\nprintln(f(1, 2, 3));",
+			excepted: "123\n",
+		},
+		{
+			name:     "Default Values",
+			input:    "func f(a, b = 2, c = 3) { return a + b + c; };\nprintln(f(1));\nprintln(f(1, 10));",
+			excepted: "6\n14\n",
+		},
+		{
+			name:     "Same Names In Different Functions",
+			input:    "func f(a) { return a; };\nfunc g(a) { return f(a + 1); };\nprintln(g(1));",
+			excepted: "2\n",
+		},
+		{
+			name:     "Parameter Shadows Global",
+			input:    "var a = 1;\nfunc f(a) { return a; };\nprintln(f(5));\nprintln(a);",
+			excepted: "5\n1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			var out bytes.Buffer
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Stdout = &out
+			e.Eval(program, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			if out.String() != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, out.String())
+			}
+		})
+	}
+}
+
+func TestEvaluator_InternalError(t *testing.T) {
+	run := func(t *testing.T, input string) (*bytes.Buffer, error) {
+		t.Helper()
+		env := object.NewGlobalEnvironment(nil)
+		env.Set("boom", &object.Symbol{Name: "boom", Value: &object.BuiltinFunction{
+			Name: "boom",
+			Fn: func(in object.Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...object.Object) (object.Object, error) {
+				var xs []object.Object
+				return xs[len(args)], nil
+			},
+		}, IsConst: true})
+		l := lexer.NewLexer("<test>", input)
+		p, _ := parser.NewParser(l)
+		program := p.ParseProgram()
+		if p.Err != nil {
+			t.Fatalf("parse err = %+v", p.Err)
+		}
+		var out bytes.Buffer
+		e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+		e.Stdout = &out
+		e.Eval(program, env)
+		return &out, e.Err
+	}
+
+	t.Run("Builtin Panic", func(t *testing.T) {
+		out, err := run(t, "println(1);\nfunc f() { return boom(); };\nf();\nprintln(2);")
+		var internalErr *InternalError
+		if !errors.As(err, &internalErr) {
+			t.Fatalf("excepted InternalError, got %+v", err)
+		}
+		if internalErr.PosStart == nil || internalErr.PosStart.Row != 2 || internalErr.PosStart.Col != 19 {
+			t.Errorf("excepted error at the call of boom, got %+v", internalErr.PosStart)
+		}
+		if !strings.Contains(internalErr.Message, "index out of range") || !strings.Contains(internalErr.Stack, "runtime/debug.Stack") {
+			t.Errorf("excepted panic value and Go stack, got %q", internalErr.Message)
+		}
+		if !strings.Contains(err.Error(), `in <function "f">`) {
+			t.Errorf("excepted ghost traceback, got %q", err.Error())
+		}
+		if out.String() != "1\n" {
+			t.Errorf("excepted execution to stop, got %q", out.String())
+		}
+	})
+
+	t.Run("Evaluator Is Reusable", func(t *testing.T) {
+		env := object.NewGlobalEnvironment(nil)
+		e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+		e.Eval(nil, env)
+		var internalErr *InternalError
+		if !errors.As(e.Err, &internalErr) {
+			t.Fatalf("excepted InternalError, got %+v", e.Err)
+		}
+		e.Err = nil
+		if val := e.Eval(&ast.IntExpression{Value: 1}, env); e.Err != nil || !object.DeepEquals(val, &object.Int{Value: 1}) {
+			t.Errorf("excepted 1, got %+v, err = %+v", val, e.Err)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Omitted Argument",
+			input:    "func f(a, b = 1) { return b; };\nf(, 2);",
+			excepted: "argument 1 cannot be omitted because it has no default value.",
+		},
+		{
+			name:     "Omitted Builtin Argument",
+			input:    `len(, "a");`,
+			excepted: "argument 1 cannot be omitted because it has no default value.",
+		},
+		{
+			name:     "Non Integer List Index",
+			input:    "var xs = [1, 2];\nxs[1.5] = 3;",
+			excepted: "index must be integer.",
+		},
+		{
+			name:     "Non Integer String Index",
+			input:    `"ab"[true];`,
+			excepted: "index must be integer.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, tt.input)
+			var internalErr *InternalError
+			if err == nil || errors.As(err, &internalErr) {
+				t.Fatalf("excepted a runtime error, got %+v", err)
+			}
+			if !strings.Contains(err.Error(), tt.excepted) {
+				t.Errorf("excepted %q in %q", tt.excepted, err.Error())
+			}
+		})
+	}
+}
+
+func TestEvaluator_Hooks(t *testing.T) {
+	input := "var x = 1;\nfunc f(a) {\n    var y = a + 1;\n    return y;\n};\nprintln(f(x));"
+	tests := []struct {
+		name     string
+		hooks    func(rows *[]int) Hooks
+		excepted []int
+	}{
+		{
+			name: "Enter Statements Including Function Body",
+			hooks: func(rows *[]int) Hooks {
+				return Hooks{OnEnterNode: func(node ast.Node, posStart, _ *util.Pos) {
+					if _, ok := node.(ast.Statement); ok {
+						*rows = append(*rows, posStart.Row)
+					}
+				}}
+			},
+			excepted: []int{1, 2, 6, 2, 3, 4},
+		},
+		{
+			name: "Exit Calls",
+			hooks: func(rows *[]int) Hooks {
+				return Hooks{OnExitCall: func(call *ast.CallExpression, posStart, _ *util.Pos) {
+					*rows = append(*rows, posStart.Col)
+				}}
+			},
+			excepted: []int{9, 1},
+		},
+		{
+			name: "Statements With Parameter In Scope",
+			hooks: func(rows *[]int) Hooks {
+				return Hooks{OnStatement: func(statement ast.Statement, env *object.Environment) error {
+					if _, ok := env.Get("a"); ok {
+						posStart, _ := ast.Span(statement)
+						*rows = append(*rows, posStart.Row)
+					}
+					return nil
+				}}
+			},
+			excepted: []int{2, 3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			var rows []int
+			var out bytes.Buffer
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Hooks = tt.hooks(&rows)
+			e.Stdout = &out
+			e.Eval(program, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			if !reflect.DeepEqual(rows, tt.excepted) {
+				t.Errorf("excepted %v, got %v", tt.excepted, rows)
+			}
+			if out.String() != "2\n" {
+				t.Errorf("excepted output %q, got %q", "2\n", out.String())
+			}
+		})
+	}
+}
+
+func TestEvaluator_StatementHookAbort(t *testing.T) {
+	env := object.NewGlobalEnvironment(nil)
+	l := lexer.NewLexer("<test>", "println(1);\nfunc f() {\n    println(2);\n    println(3);\n};\nf();\nprintln(4);")
+	p, _ := parser.NewParser(l)
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("parse err = %+v", p.Err)
+	}
+	abort := errors.New("abort")
+	var out bytes.Buffer
+	e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+	e.Stdout = &out
+	e.Hooks = Hooks{OnStatement: func(statement ast.Statement, _ *object.Environment) error {
+		if posStart, _ := ast.Span(statement); posStart.Row == 4 {
+			return abort
+		}
+		return nil
+	}}
+	e.Eval(program, env)
+	if e.Err != abort {
+		t.Fatalf("err = %+v, expected %+v", e.Err, abort)
+	}
+	if out.String() != "1\n2\n" {
+		t.Errorf("excepted output %q, got %q", "1\n2\n", out.String())
+	}
+}
+
+func TestEvaluator_Output(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Print",
+			input:    `print("a"); print(1);`,
+			excepted: "a1",
+		},
+		{
+			name:     "Println",
+			input:    `println("héllo"); println([1, 2]);`,
+			excepted: "héllo\n[1, 2]\n",
+		},
+		{
+			name:     "Print Inside Function",
+			input:    `func f(x) { println(x * 2); }; f(21);`,
+			excepted: "42\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			var stdout, stderr bytes.Buffer
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Stdout = &stdout
+			e.Stderr = &stderr
+			e.Eval(program, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			if stdout.String() != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, stdout.String())
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("excepted empty stderr, got %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestEvaluator_VisitIndexExpression(t *testing.T) {
+	f := &frame.Frame{
+		FuncName: "<test>",
+		Parent:   nil,
+		PosStart: nil,
+		PosEnd:   nil,
+	}
+
+	m := object.NewMap()
+	m.Set(&object.String{Value: "k"}, &object.Int{Value: 7}, nil, nil, nil)
+
+	env := &object.Environment{
+		Store: map[string]*object.Symbol{
+			"lst": {
+				Name: "lst",
+				Value: &object.List{
+					Elements: []object.Object{
+						&object.Int{
+							Value: 1,
+						},
+						&object.Int{
+							Value: 2,
+						},
+						&object.Int{
+							Value: 3,
+						},
+					},
+				},
+				IsConst: true,
+			},
+			"s": {
+				Name:    "s",
+				Value:   &object.String{Value: "abc"},
+				IsConst: true,
+			},
+			"m": {
+				Name:    "m",
+				Value:   m,
+				IsConst: true,
+			},
+		},
+		Outer: nil,
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      error
+	}{
+		{
+			name:  "Index Expression",
+			input: `lst[1];`,
+			excepted: &object.Int{
+				Value: 2,
+			},
+		},
+		{
+			name:  "Fancy Index",
+			input: `lst[[0, 2]];`,
+			excepted: &object.List{
+				Elements: []object.Object{&object.Int{Value: 1}, &object.Int{Value: 3}},
+			},
+		},
+		{
+			name:  "Fancy Index Negative And Repeated",
+			input: `lst[[-1, 0, 0]];`,
+			excepted: &object.List{
+				Elements: []object.Object{&object.Int{Value: 3}, &object.Int{Value: 1}, &object.Int{Value: 1}},
+			},
+		},
+		{
+			name:     "Fancy Index Empty",
+			input:    `lst[[]];`,
+			excepted: &object.List{Elements: []object.Object{}},
+		},
+		{
+			name:  "Fancy Index Out Of Range",
+			input: `lst[[0, 3]];`,
+			err:   &object.IndexError{Message: "index out of range."},
+		},
+		{
+			name:  "Fancy Index Non Int Member",
+			input: `lst[[1.0, 2.0]];`,
+			err:   &object.TypeError{Message: "index list members must be integers."},
+		},
+		{
+			name:     "Map Index",
+			input:    `m["k"];`,
+			excepted: &object.Int{Value: 7},
+		},
+		{
+			name:  "Map Missing Key",
+			input: `m["x"];`,
+			err:   &object.IndexError{Message: "key \"x\" not found in map."},
+		},
+		{
+			name:  "Fancy Index On String",
+			input: `s[[0]];`,
+			err:   &TypeError{Message: "index must be integer."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.evalIndexExpression(program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.IndexExpression), env)
+			if tt.err != nil {
+				if reflect.TypeOf(e.Err) != reflect.TypeOf(tt.err) || !strings.HasSuffix(e.Err.Error(), strings.TrimPrefix(tt.err.Error(), "Traceback:\n")) {
+					t.Errorf("err = %+v, expected %+v", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinReduce(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      bool
+	}{
+		{
+			name:     "Reduce Without Initial Value",
+			input:    `reduce(add, [1, 2, 3]);`,
+			excepted: &object.Int{Value: 6},
+		},
+		{
+			name:     "Reduce With Initial Value",
+			input:    `reduce(add, [1, 2, 3], 10);`,
+			excepted: &object.Int{Value: 16},
+		},
+		{
+			name:     "Reduce Empty List With Initial Value",
+			input:    `reduce(add, [], 0);`,
+			excepted: &object.Int{Value: 0},
+		},
+		{
+			name:     "Reduce Visits Elements From Left To Right",
+			input:    `reduce(add, ["a", "b", "c"], ">");`,
+			excepted: &object.String{Value: ">abc"},
+		},
+		{
+			name:  "Reduce Empty List Without Initial Value",
+			input: `reduce(add, []);`,
+			err:   true,
+		},
+		{
+			name:  "Reduce Non-List",
+			input: `reduce(add, 1);`,
+			err:   true,
+		},
+		{
+			name:     "Reduce Iterator",
+			input:    `reduce(add, range(1, 101));`,
+			excepted: &object.Int{Value: 5050},
+		},
+		{
+			name:     "Reduce String",
+			input:    `reduce(add, "abc", "");`,
+			excepted: &object.String{Value: "abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", "func add(a, b) { return a + b; };\n"+tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			e.Eval(program.Statements[0], env)
+			val := e.Eval(program.Statements[1].(*ast.ExpressionStatement).Expr, env)
+			if tt.err {
+				if e.Err == nil {
+					t.Errorf("err = nil, expected error")
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_DefaultValueScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Caller Locals Shadow Globals",
+			input:    "var k = \"global\";\nfunc f(a = k) a;\nfunc g() { var k = \"caller\"; return f(); };\nprint(g());",
+			excepted: "caller",
+		},
+		{
+			name:     "Omitted Argument Uses Caller Locals",
+			input:    "var k = 1;\nfunc f(a = k, b = 0) a + b;\nfunc g() { var k = 2; return f(, 3); };\nprint(g());",
+			excepted: "5",
+		},
+		{
+			name:     "Declaring Function Locals Are Not Visible",
+			input:    "func make() { var k = \"inner\"; return func(a = k) a; };\nvar k = \"global\";\nprint(make()());",
+			excepted: "global",
+		},
+		{
+			name:  "Declaring Function Locals Are Not Visible Without A Global",
+			input: "func make() { var j = 1; return func(a = j) a; };\nmake()();",
+			err:   "undefined variable \"j\".",
+		},
+		{
+			name:     "Caller Block Locals",
+			input:    "func f(a = k) a;\nfunc g() { { var k = 7; return f(); }; };\nprint(g());",
+			excepted: "7",
+		},
+		{
+			name:     "Callback From Builtin",
+			input:    "func f(x, s = k) x + s;\nfunc g() { var k = 10; return map(f, [1, 2]); };\nprint(g());",
+			excepted: "[11, 12]",
+		},
+		{
+			name:     "Evaluated At Each Call",
+			input:    "var n = 0;\nfunc f(x = n) x;\nprint(f()); n = 5; print(f());",
+			excepted: "05",
+		},
+		{
+			name:     "Other Parameters Are Not Visible",
+			input:    "var a = \"outer\";\nfunc f(a, b = a) b;\nprint(f(\"param\"));",
+			excepted: "outer",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_BuiltinMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "User Function",
+			input:    "func double(x) { return x * 2; };\nprint(map(double, [1, 2, 3]));",
+			excepted: "[2, 4, 6]",
+		},
+		{
+			name:     "Function Expression",
+			input:    "var offset = 10;\nprint(map(func(x) x + offset, [1, 2]));",
+			excepted: "[11, 12]",
+		},
+		{
+			name:     "Builtin Function",
+			input:    "print(map(len, [\"a\", \"bc\", \"\"]));",
+			excepted: "[1, 2, 0]",
+		},
+		{
+			name:     "Empty List",
+			input:    "print(map(func(x) x, []));",
+			excepted: "[]",
+		},
+		{
+			name:     "Input Is Not Modified",
+			input:    "var l = [1, 2];\nvar m = map(func(x) -x, l);\nprint(l); print(m);",
+			excepted: "[1, 2][-1, -2]",
+		},
+		{
+			name:  "Not A Function",
+			input: "map(1, [1]);",
+			err:   "map() argument 1 must be a function.",
+		},
+		{
+			name:     "Range",
+			input:    "print(map(func(x) x * x, range(4)));",
+			excepted: "[0, 1, 4, 9]",
+		},
+		{
+			name:     "Map Keys",
+			input:    "print(map(func(k) k + \"!\", {\"a\": 1, \"b\": 2}));",
+			excepted: "[a!, b!]",
+		},
+		{
+			name:     "String",
+			input:    "print(map(func(ch) ch + ch, \"ab\"));",
+			excepted: "[aa, bb]",
+		},
+		{
+			name:     "Callback Modifying The List",
+			input:    "var l = [1, 2];\nprint(map(func(x) { l[1] = 10; return x; }, l)); print(l);",
+			excepted: "[1, 2][1, 10]",
+		},
+		{
+			name:  "Not Iterable",
+			input: "map(len, 1);",
+			err:   "map() argument 2 must be iterable, got Int.",
+		},
+		{
+			name:  "Inconsistent Results",
+			input: "map(func(x) x > 1 ? \"big\" : x, [1, 2]);",
+			err:   "list elements must have consistent types.",
+		},
+		{
+			name:     "Error In Callback",
+			input:    "print(1);\nmap(func(x) 1 / x, [1, 0]);",
+			excepted: "1",
+			err:      "division by zero.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_BuiltinMapCallbackFrame(t *testing.T) {
+	_, err := runScoped(t, "func inverse(x) { return 1 / x; };\nmap(inverse, [1, 0]);", false)
+	if err == nil {
+		t.Fatalf("excepted an error, got nil")
+	}
+	traceback := err.Error()
+	if !strings.Contains(traceback, `in <function "inverse">`) || !strings.Contains(traceback, `in <builtin "map">`) {
+		t.Errorf("excepted the callback and map in the traceback, got %q", traceback)
+	}
+}
+
+func TestEvaluator_BuiltinFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Keeps Order",
+			input:    "print(filter(func(x) x % 2 == 1, [5, 2, 3, 8, 1]));",
+			excepted: "[5, 3, 1]",
+		},
+		{
+			name:     "User Function",
+			input:    "func long(s) { return len(s) > 1; };\nprint(filter(long, [\"a\", \"bc\", \"def\"]));",
+			excepted: "[bc, def]",
+		},
+		{
+			name:     "Nothing Kept",
+			input:    "print(filter(func(x) false, [1, 2]));",
+			excepted: "[]",
+		},
+		{
+			name:     "Input Is Not Modified",
+			input:    "var l = [1, 2, 3];\nvar m = filter(func(x) x > 1, l);\nprint(l); print(m);",
+			excepted: "[1, 2, 3][2, 3]",
+		},
+		{
+			name:  "Not A Function",
+			input: "filter(1, [1]);",
+			err:   "filter() argument 1 must be a function.",
+		},
+		{
+			name:     "Range",
+			input:    "print(filter(func(x) x % 3 == 0, range(10)));",
+			excepted: "[0, 3, 6, 9]",
+		},
+		{
+			name:     "Map Keys",
+			input:    "var m = {\"a\": 1, \"b\": 2, \"c\": 3};\nprint(filter(func(k) m[k] != 2, m));",
+			excepted: "[a, c]",
+		},
+		{
+			name:     "String",
+			input:    "print(filter(func(ch) ch != \"-\", \"a-b\"));",
+			excepted: "[a, b]",
+		},
+		{
+			name:  "Mixed Elements",
+			input: "filter(func(x) true, list(zip([1], [\"a\"]))[0]);",
+			err:   "list elements must have consistent types.",
+		},
+		{
+			name:  "Not Iterable",
+			input: "filter(len, 1);",
+			err:   "filter() argument 2 must be iterable, got Int.",
+		},
+		{
+			name:     "Predicate Returns Non Bool",
+			input:    "print(1);\nfilter(len, [\"a\"]);",
+			excepted: "1",
+			err:      "filter() function must return a Bool, got Int.",
+		},
+		{
+			name:     "Error In Callback",
+			input:    "print(1);\nfilter(func(x) 1 / x > 0, [1, 0]);",
+			excepted: "1",
+			err:      "division by zero.",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_BuiltinIterators(t *testing.T) {
+	pair := func(a, b object.Object) object.Object {
+		return &object.List{Elements: []object.Object{a, b}}
+	}
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Range Stop",
+			input:    `list(range(4));`,
+			excepted: &object.List{Elements: []object.Object{&object.Int{Value: 0}, &object.Int{Value: 1}, &object.Int{Value: 2}, &object.Int{Value: 3}}},
+		},
+		{
+			name:     "Range Start Stop Step",
+			input:    `list(range(2, 10, 3));`,
+			excepted: &object.List{Elements: []object.Object{&object.Int{Value: 2}, &object.Int{Value: 5}, &object.Int{Value: 8}}},
+		},
+		{
+			name:     "Range Negative Step",
+			input:    `list(range(5, 0, -2));`,
+			excepted: &object.List{Elements: []object.Object{&object.Int{Value: 5}, &object.Int{Value: 3}, &object.Int{Value: 1}}},
+		},
+		{
+			name:     "Empty Range",
+			input:    `list(range(3, 3));`,
+			excepted: &object.List{Elements: []object.Object{}},
+		},
+		{
+			name:     "Range Stops Before Overflow",
+			input:    `list(range(9223372036854775805, 9223372036854775807, 5));`,
+			excepted: &object.List{Elements: []object.Object{&object.Int{Value: 9223372036854775805}}},
+		},
+		{
+			name:  "Zip Stops At Shorter",
+			input: `list(zip([1, 2, 3], ["a", "b"]));`,
+			excepted: &object.List{Elements: []object.Object{
+				pair(&object.Int{Value: 1}, &object.String{Value: "a"}),
+				pair(&object.Int{Value: 2}, &object.String{Value: "b"}),
+			}},
+		},
+		{
+			name:  "Enumerate String",
+			input: `list(enumerate("hé", 1));`,
+			excepted: &object.List{Elements: []object.Object{
+				pair(&object.Int{Value: 1}, &object.String{Value: "h"}),
+				pair(&object.Int{Value: 2}, &object.String{Value: "é"}),
+			}},
+		},
+		{
+			name:  "Map Yields Keys",
+			input: `list({"b": 1, "a": 2});`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.String{Value: "b"},
+				&object.String{Value: "a"},
+			}},
+		},
+		{
+			name:     "Iterator Is Consumed Once",
+			input:    `var it = range(3); list(it); list(it);`,
+			excepted: &object.List{Elements: []object.Object{}},
+		},
+		{
+			name:     "Iterator String",
+			input:    `range(3);`,
+			excepted: nil,
+		},
+		{
+			name:  "Range Zero Step",
+			input: `range(1, 5, 0);`,
+			err:   "range() step must not be zero.",
+		},
+		{
+			name:  "Range Non-Integer",
+			input: `range("a");`,
+			err:   "range() arguments must be integers, got String.",
+		},
+		{
+			name:  "Zip Non-Iterable",
+			input: `zip([1], 2);`,
+			err:   "zip() argument 2 must be iterable, got Int.",
+		},
+		{
+			name:  "List Non-Iterable",
+			input: `list(true);`,
+			err:   "list() argument must be iterable, got Bool.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			var val object.Object
+			for _, statement := range program.Statements {
+				val = e.Eval(statement.(*ast.ExpressionStatement).Expr, env)
+				if e.Err != nil {
+					break
+				}
+			}
+			if tt.err != "" {
+				if e.Err == nil || !strings.Contains(e.Err.Error(), tt.err) {
+					t.Errorf("err = %+v, expected %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			if tt.excepted == nil {
+				if val.String() != "<iterator range>" {
+					t.Errorf("excepted <iterator range>, got %s", val)
+				}
+				return
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_RangeIsLazy(t *testing.T) {
+	env := object.NewGlobalEnvironment(nil)
+	l := lexer.NewLexer("<test>", "range(1000000);")
+	p, _ := parser.NewParser(l)
+	program := p.ParseProgram()
+	expr := program.Statements[0].(*ast.ExpressionStatement).Expr
+	e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+	// 创建迭代器的分配次数与范围大小无关
+	allocs := testing.AllocsPerRun(10, func() {
+		e.Eval(expr, env)
+	})
+	if e.Err != nil {
+		t.Fatalf("err = %+v, expected nil", e.Err)
+	}
+	if allocs > 50 {
+		t.Errorf("excepted range(1000000) to allocate lazily, got %.0f allocs", allocs)
+	}
+	// 值在消耗时逐个产生
+	iter := e.Eval(expr, env).(*object.Iterator)
+	count := 0
+	for {
+		_, ok, err := iter.Next()
+		if err != nil {
+			t.Fatalf("err = %+v, expected nil", err)
+		}
+		if !ok {
+			break
+		}
+		count++
+	}
+	if count != 1000000 {
+		t.Errorf("excepted 1000000 values, got %d", count)
+	}
+}
+
+func TestEvaluator_BuiltinSort(t *testing.T) {
+	// 40个键只有0、1、2三种取值的元素，用于验证排序的稳定性
+	var pairs []string
+	var excepted []object.Object
+	for i := range 40 {
+		pairs = append(pairs, fmt.Sprintf("[%d, %d]", i%3, i))
+	}
+	for key := range 3 {
+		for i := key; i < 40; i += 3 {
+			excepted = append(excepted, &object.List{Elements: []object.Object{
+				&object.Int{Value: int64(key)},
+				&object.Int{Value: int64(i)},
+			}})
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      bool
+	}{
+		{
+			name:  "Sort Ints",
+			input: `sort([3, 1, 2, 1]);`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.Int{Value: 1},
+				&object.Int{Value: 1},
+				&object.Int{Value: 2},
+				&object.Int{Value: 3},
+			}},
+		},
+		{
+			name:     "Sort Empty List",
+			input:    `sort([]);`,
+			excepted: &object.List{Elements: []object.Object{}},
+		},
+		{
+			name:  "Sort Is Stable For Equal Keys",
+			input: `sort([[2, 0], [1, 1], [2, 2], [1, 3]], first);`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.List{Elements: []object.Object{&object.Int{Value: 1}, &object.Int{Value: 1}}},
+				&object.List{Elements: []object.Object{&object.Int{Value: 1}, &object.Int{Value: 3}}},
+				&object.List{Elements: []object.Object{&object.Int{Value: 2}, &object.Int{Value: 0}}},
+				&object.List{Elements: []object.Object{&object.Int{Value: 2}, &object.Int{Value: 2}}},
+			}},
+		},
+		{
+			name:     "Sort Is Stable For Many Equal Keys",
+			input:    fmt.Sprintf("sort([%s], first);", strings.Join(pairs, ", ")),
+			excepted: &object.List{Elements: excepted},
+		},
+		{
+			name:  "Sort Does Not Modify Argument",
+			input: `{ var l = [2, 1]; sort(l); l; };`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.Int{Value: 2},
+				&object.Int{Value: 1},
+			}},
+		},
+		{
+			name:  "Sort Incomparable Elements",
+			input: `sort([[1], [2]]);`,
+			err:   true,
+		},
+		{
+			name:  "Sort Non-List",
+			input: `sort(1);`,
+			err:   true,
+		},
+		{
+			name:  "Sort Non-Function Key",
+			input: `sort([1], 1);`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", "func first(p) { return p[0]; };\n"+tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("unexpected parse error: %v", p.Err)
+			}
+			e := NewEvaluator(f)
+			e.Eval(program.Statements[0], env)
+			val := e.Eval(program.Statements[1].(*ast.ExpressionStatement).Expr, env)
+			if tt.err {
+				if e.Err == nil {
+					t.Errorf("err = nil, expected error")
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      bool
+	}{
+		{
+			name:     "True",
+			input:    `int(true);`,
+			excepted: &object.Int{Value: 1},
+		},
+		{
+			name:     "False",
+			input:    `int(false);`,
+			excepted: &object.Int{Value: 0},
+		},
+		{
+			name:     "Explicit Bool Arithmetic",
+			input:    `int(true) + 1;`,
+			excepted: &object.Int{Value: 2},
+		},
+		{
+			name:  "Implicit Bool Arithmetic",
+			input: `true + 1;`,
+			err:   true,
+		},
+		{
+			name:  "Implicit Bool Arithmetic On Right",
+			input: `1 + true;`,
+			err:   true,
+		},
+		{
+			name:     "Int",
+			input:    `int(42);`,
+			excepted: &object.Int{Value: 42},
+		},
+		{
+			name:     "Float Truncates Toward Zero",
+			input:    `int(-2.7);`,
+			excepted: &object.Int{Value: -2},
+		},
+		{
+			name:     "Positive Float Truncates",
+			input:    `int(3.9);`,
+			excepted: &object.Int{Value: 3},
+		},
+		{
+			name:     "Negative Float Truncates",
+			input:    `int(-3.9);`,
+			excepted: &object.Int{Value: -3},
+		},
+		{
+			name:     "Integral Float",
+			input:    `int(3.0);`,
+			excepted: &object.Int{Value: 3},
+		},
+		{
+			name:     "Is Integer Int",
+			input:    `is_integer(3);`,
+			excepted: &object.Bool{Value: true},
+		},
+		{
+			name:     "Is Integer Integral Float",
+			input:    `is_integer(3.0);`,
+			excepted: &object.Bool{Value: true},
+		},
+		{
+			name:     "Is Integer Negative Zero",
+			input:    `is_integer(-0.0);`,
+			excepted: &object.Bool{Value: true},
+		},
+		{
+			name:     "Is Integer Fractional Float",
+			input:    `is_integer(3.5);`,
+			excepted: &object.Bool{Value: false},
+		},
+		{
+			name:     "Is Integer Infinity",
+			input:    `is_integer(float("inf"));`,
+			excepted: &object.Bool{Value: false},
+		},
+		{
+			name:     "Is Integer String",
+			input:    `is_integer("3");`,
+			excepted: &object.Bool{Value: false},
+		},
+		{
+			name:     "String",
+			input:    `int(" -12 ");`,
+			excepted: &object.Int{Value: -12},
+		},
+		{
+			name:  "Invalid String",
+			input: `int("1.5");`,
+			err:   true,
+		},
+		{
+			name:  "Null",
+			input: `int(null);`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err {
+				if e.Err == nil {
+					t.Errorf("err = nil, expected error")
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinAssert(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      error
+	}{
+		{
+			name:     "Passing",
+			input:    `assert(1 + 1 == 2);`,
+			excepted: &object.Null{},
+		},
+		{
+			name:  "Failing With Default Message",
+			input: `assert(1 > 2);`,
+			err:   &object.AssertionError{Message: "assertion failed."},
+		},
+		{
+			name:  "Failing With Message",
+			input: `assert(false, "expected a positive number.");`,
+			err:   &object.AssertionError{Message: "expected a positive number."},
+		},
+		{
+			name:  "Non Bool Condition",
+			input: `assert(1);`,
+			err:   &object.TypeError{Message: "assert() argument 1 must be a bool."},
+		},
+		{
+			name:  "Non String Message",
+			input: `assert(false, 1);`,
+			err:   &object.TypeError{Message: "assert() argument 2 must be a string."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != nil {
+				switch err := e.Err.(type) {
+				case *object.AssertionError:
+					if excepted, ok := tt.err.(*object.AssertionError); !ok || err.Message != excepted.Message {
+						t.Errorf("err = %+v, expected %+v", e.Err, tt.err)
+					}
+				case *object.TypeError:
+					if excepted, ok := tt.err.(*object.TypeError); !ok || err.Message != excepted.Message {
+						t.Errorf("err = %+v, expected %+v", e.Err, tt.err)
+					}
+				default:
+					t.Errorf("err = %+v, expected %+v", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinBaseConversion(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      error
+	}{
+		{
+			name:     "To Binary",
+			input:    `to_base(10, 2);`,
+			excepted: &object.String{Value: "1010"},
+		},
+		{
+			name:     "To Hex",
+			input:    `to_base(-255, 16);`,
+			excepted: &object.String{Value: "-ff"},
+		},
+		{
+			name:     "To Base 36",
+			input:    `to_base(35, 36);`,
+			excepted: &object.String{Value: "z"},
+		},
+		{
+			name:     "Binary Round Trip",
+			input:    `parse_int(to_base(-9223372036854775807 - 1, 2), 2);`,
+			excepted: &object.Int{Value: math.MinInt64},
+		},
+		{
+			name:     "Hex Round Trip",
+			input:    `parse_int(to_base(9223372036854775807, 16), 16);`,
+			excepted: &object.Int{Value: math.MaxInt64},
+		},
+		{
+			name:     "Default Base",
+			input:    `parse_int(" 42 ");`,
+			excepted: &object.Int{Value: 42},
+		},
+		{
+			name:     "Upper Case Digits",
+			input:    `parse_int("FF", 16);`,
+			excepted: &object.Int{Value: 255},
+		},
+		{
+			name:     "Auto Hex Prefix",
+			input:    `parse_int("-0xFF", 0);`,
+			excepted: &object.Int{Value: -255},
+		},
+		{
+			name:     "Auto Binary Prefix",
+			input:    `parse_int("0b1010", 0);`,
+			excepted: &object.Int{Value: 10},
+		},
+		{
+			name:     "Auto Octal Prefix",
+			input:    `parse_int("0o17", 0);`,
+			excepted: &object.Int{Value: 15},
+		},
+		{
+			name:     "Auto Leading Zero Is Decimal",
+			input:    `parse_int("017", 0);`,
+			excepted: &object.Int{Value: 17},
+		},
+		{
+			name:  "Invalid Digit",
+			input: `parse_int("102", 2);`,
+			err:   &object.ValueError{Message: `invalid literal for parse_int() with base 2: "102".`},
+		},
+		{
+			name:  "Sign After Prefix",
+			input: `parse_int("0x-1", 0);`,
+			err:   &object.ValueError{Message: `invalid literal for parse_int() with base 0: "0x-1".`},
+		},
+		{
+			name:  "Out Of Range",
+			input: `parse_int("8000000000000000", 16);`,
+			err:   &object.ValueError{Message: `parse_int() value out of range: "8000000000000000".`},
+		},
+		{
+			name:  "To Base Out Of Range",
+			input: `to_base(10, 37);`,
+			err:   &object.ValueError{Message: "to_base() base must be between 2 and 36, got 37."},
+		},
+		{
+			name:  "Parse Int Base Out Of Range",
+			input: `parse_int("10", 1);`,
+			err:   &object.ValueError{Message: "parse_int() base must be 0 or between 2 and 36, got 1."},
+		},
+		{
+			name:  "Non Integer Argument",
+			input: `to_base(1.5, 2);`,
+			err:   &object.TypeError{Message: "to_base() argument 1 must be an integer."},
+		},
+		{
+			name:  "Non String Argument",
+			input: `parse_int(10, 2);`,
+			err:   &object.TypeError{Message: "parse_int() argument 1 must be a string."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != nil {
+				switch err := e.Err.(type) {
+				case *object.ValueError:
+					if excepted, ok := tt.err.(*object.ValueError); !ok || err.Message != excepted.Message {
+						t.Errorf("err = %+v, expected %+v", e.Err, tt.err)
+					}
+				case *object.TypeError:
+					if excepted, ok := tt.err.(*object.TypeError); !ok || err.Message != excepted.Message {
+						t.Errorf("err = %+v, expected %+v", e.Err, tt.err)
+					}
+				default:
+					t.Errorf("err = %+v, expected %+v", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_StrictMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		strict   bool
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Promotes By Default",
+			input:    `1 + 2.0;`,
+			excepted: &object.Float{Value: 3},
+		},
+		{
+			name:   "Mixed Addition",
+			input:  `1 + 2.0;`,
+			strict: true,
+			err:    `mixed int and float operands for "+" in strict mode, convert with float() or int().`,
+		},
+		{
+			name:   "Mixed Comparison",
+			input:  `1.5 < 2;`,
+			strict: true,
+			err:    `mixed int and float operands for "<" in strict mode, convert with float() or int().`,
+		},
+		{
+			name:   "Mixed Compound Assignment",
+			input:  `x *= 2;`,
+			strict: true,
+			err:    `mixed int and float operands for "*" in strict mode, convert with float() or int().`,
+		},
+		{
+			name:     "Explicit Conversion",
+			input:    `float(1) + 2.0;`,
+			strict:   true,
+			excepted: &object.Float{Value: 3},
+		},
+		{
+			name:     "Integer Division Yields Float",
+			input:    `7 / 2 + 1.0;`,
+			strict:   true,
+			excepted: &object.Float{Value: 4.5},
+		},
+		{
+			name:     "Float Increment",
+			input:    `++x;`,
+			strict:   true,
+			excepted: &object.Float{Value: 2.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			env.Store["x"] = &object.Symbol{Name: "x", Value: &object.Float{Value: 1.5}}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Strict = tt.strict
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != "" {
+				err, ok := e.Err.(*TypeError)
+				if !ok || err.Message != tt.err {
+					t.Errorf("err = %+v, expected TypeError %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      bool
+	}{
+		{
+			name:     "Int",
+			input:    `float(3);`,
+			excepted: &object.Float{Value: 3},
+		},
+		{
+			name:     "Float",
+			input:    `float(2.5);`,
+			excepted: &object.Float{Value: 2.5},
+		},
+		{
+			name:     "Bool",
+			input:    `float(true);`,
+			excepted: &object.Float{Value: 1},
+		},
+		{
+			name:     "String",
+			input:    `float(" -1.25 ");`,
+			excepted: &object.Float{Value: -1.25},
+		},
+		{
+			name:  "Invalid String",
+			input: `float("abc");`,
+			err:   true,
+		},
+		{
+			name:  "List",
+			input: `float([1]);`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err {
+				if e.Err == nil {
+					t.Errorf("err = nil, expected error")
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinSubstring(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      bool
+	}{
+		{
+			name:     "Multi-Byte Runes",
+			input:    `substring("héllo", 1, 3);`,
+			excepted: &object.String{Value: "él"},
+		},
+		{
+			name:     "Chinese Runes",
+			input:    `substring("你好世界", 1, 3);`,
+			excepted: &object.String{Value: "好世"},
+		},
+		{
+			name:     "Without End",
+			input:    `substring("héllo", 1);`,
+			excepted: &object.String{Value: "éllo"},
+		},
+		{
+			name:     "Negative Indices",
+			input:    `substring("héllo", -4, -1);`,
+			excepted: &object.String{Value: "éll"},
+		},
+		{
+			name:     "Clamped Bounds",
+			input:    `substring("héllo", -10, 10);`,
+			excepted: &object.String{Value: "héllo"},
+		},
+		{
+			name:     "Start After End",
+			input:    `substring("héllo", 3, 1);`,
+			excepted: &object.String{Value: ""},
+		},
+		{
+			name:  "Non-String Argument",
+			input: `substring(1, 0);`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err {
+				if e.Err == nil {
+					t.Errorf("err = nil, expected error")
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinSplit(t *testing.T) {
+	strs := func(values ...string) object.Object {
+		elements := make([]object.Object, len(values))
+		for i, value := range values {
+			elements[i] = &object.String{Value: value}
+		}
+		return &object.List{Elements: elements}
+	}
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Separator",
+			input:    `split("a,b,,c", ",");`,
+			excepted: strs("a", "b", "", "c"),
+		},
+		{
+			name:     "Limit",
+			input:    `split("a,b,c,d", ",", 2);`,
+			excepted: strs("a", "b", "c,d"),
+		},
+		{
+			name:     "Zero Limit",
+			input:    `split("a,b", ",", 0);`,
+			excepted: strs("a,b"),
+		},
+		{
+			name:     "Negative Limit",
+			input:    `split("a,b,c", ",", -1);`,
+			excepted: strs("a", "b", "c"),
+		},
+		{
+			name:     "Empty Separator",
+			input:    `split("hé世", "");`,
+			excepted: strs("h", "é", "世"),
+		},
+		{
+			name:     "Empty Separator With Limit",
+			input:    `split("hé世", "", 1);`,
+			excepted: strs("h", "é世"),
+		},
+		{
+			name:     "Separator Not Found",
+			input:    `split("abc", ";");`,
+			excepted: strs("abc"),
+		},
+		{
+			name:     "Regex",
+			input:    `split_regex("a1b22c333d", "[0-9]+");`,
+			excepted: strs("a", "b", "c", "d"),
+		},
+		{
+			name:     "Regex Limit",
+			input:    `split_regex("a  b \tc", "\\s+", 1);`,
+			excepted: strs("a", "b \tc"),
+		},
+		{
+			name:  "Invalid Pattern",
+			input: `split_regex("abc", "(a");`,
+			err:   "Parse Error: invalid pattern \"(a\"",
+		},
+		{
+			name:  "Non-Integer Limit",
+			input: `split("a,b", ",", "1");`,
+			err:   "split() argument 3 must be an integer.",
+		},
+		{
+			name:  "Non-String Separator",
+			input: `split("a,b", 1);`,
+			err:   "split() argument 2 must be a string.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != "" {
+				if e.Err == nil || !strings.Contains(e.Err.Error(), tt.err) {
+					t.Errorf("err = %+v, expected %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinStringUtilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Replace All",
+			input:    `replace("a-b-c", "-", "+");`,
+			excepted: &object.String{Value: "a+b+c"},
+		},
+		{
+			name:     "Replace Count",
+			input:    `replace("a-b-c", "-", "", 1);`,
+			excepted: &object.String{Value: "ab-c"},
+		},
+		{
+			name:     "Replace Zero Count",
+			input:    `replace("a-b", "-", "+", 0);`,
+			excepted: &object.String{Value: "a-b"},
+		},
+		{
+			name:     "Replace No Match",
+			input:    `replace("héllo", "x", "y");`,
+			excepted: &object.String{Value: "héllo"},
+		},
+		{
+			name:     "Replace Empty String",
+			input:    `replace("", "a", "b");`,
+			excepted: &object.String{Value: ""},
+		},
+		{
+			name:     "Replace Empty Old",
+			input:    `replace("ab", "", "-");`,
+			excepted: &object.String{Value: "-a-b-"},
+		},
+		{
+			name:     "Startswith",
+			input:    `startswith("ghost-lang", "ghost");`,
+			excepted: &object.Bool{Value: true},
+		},
+		{
+			name:     "Startswith No Match",
+			input:    `startswith("ghost", "lang");`,
+			excepted: &object.Bool{Value: false},
+		},
+		{
+			name:     "Startswith Empty Prefix",
+			input:    `startswith("", "");`,
+			excepted: &object.Bool{Value: true},
+		},
+		{
+			name:     "Endswith",
+			input:    `endswith("main.gh", ".gh");`,
+			excepted: &object.Bool{Value: true},
+		},
+		{
+			name:     "Endswith Longer Suffix",
+			input:    `endswith("gh", "main.gh");`,
+			excepted: &object.Bool{Value: false},
+		},
+		{
+			name:  "Replace Non-String",
+			input: `replace("a", 1, "b");`,
+			err:   "replace() argument 2 must be a string, got Int.",
+		},
+		{
+			name:  "Replace Non-Integer Count",
+			input: `replace("a", "a", "b", "1");`,
+			err:   "replace() argument 4 must be an integer.",
+		},
+		{
+			name:  "Endswith Non-String",
+			input: `endswith(null, "a");`,
+			err:   "endswith() argument 1 must be a string, got Null.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != "" {
+				var typeErr *object.TypeError
+				if !errors.As(e.Err, &typeErr) || typeErr.Message != tt.err {
+					t.Errorf("err = %+v, expected %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinRepr(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Control Characters",
+			input:    `repr("a\nb\tc");`,
+			excepted: &object.String{Value: `"a\nb\tc"`},
+		},
+		{
+			name:     "Printable Unicode Kept",
+			input:    `repr("snow ☃ 👻\n");`,
+			excepted: &object.String{Value: `"snow ☃ 👻\n"`},
+		},
+		{
+			name:     "ASCII Only",
+			input:    `repr("snow ☃ 👻\n", true);`,
+			excepted: &object.String{Value: `"snow \u2603 \U0001f47b\n"`},
+		},
+		{
+			name:     "Quotes And Backslashes",
+			input:    `repr("say \"hi\" \\");`,
+			excepted: &object.String{Value: `"say \"hi\" \\"`},
+		},
+		{
+			name:     "List Elements",
+			input:    `repr(["a\tb", "☃"], true);`,
+			excepted: &object.String{Value: `["a\tb", "\u2603"]`},
+		},
+		{
+			name:     "Non-String",
+			input:    `repr(1.5);`,
+			excepted: &object.String{Value: "1.500000"},
+		},
+		{
+			name:  "Non-Bool ASCII",
+			input: `repr("a", 1);`,
+			err:   "repr() argument 2 must be a bool.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != "" {
+				var typeErr *object.TypeError
+				if !errors.As(e.Err, &typeErr) || typeErr.Message != tt.err {
+					t.Errorf("err = %+v, expected %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinStr(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+	}{
+		{
+			name:     "Int",
+			input:    `"n = " + str(42);`,
+			excepted: &object.String{Value: "n = 42"},
+		},
+		{
+			name:     "Float",
+			input:    `str(1.5);`,
+			excepted: &object.String{Value: "1.500000"},
+		},
+		{
+			name:     "String Unchanged",
+			input:    `str("a\tb");`,
+			excepted: &object.String{Value: "a\tb"},
+		},
+		{
+			name:     "Bool And Null",
+			input:    `str(true) + str(null);`,
+			excepted: &object.String{Value: "truenull"},
+		},
+		{
+			name:     "List",
+			input:    `"items: " + str(["a", "b"]);`,
+			excepted: &object.String{Value: "items: [a, b]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_BuiltinRegex(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Simple Match",
+			input:    `match("^[a-z]+$", "ghost") != null;`,
+			excepted: &object.Bool{Value: true},
+		},
+		{
+			name:     "No Match",
+			input:    `match("^[0-9]+$", "ghost");`,
+			excepted: &object.Null{},
+		},
+		{
+			name:  "Capture Groups",
+			input: `match("(\\w+)@(\\w+)\\.com", "mail alice@example.com now");`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.String{Value: "alice@example.com"},
+				&object.String{Value: "alice"},
+				&object.String{Value: "example"},
+			}},
+		},
+		{
+			name:  "Unmatched Optional Group",
+			input: `match("a(b)?", "a");`,
+			excepted: &object.List{Elements: []object.Object{
+				&object.String{Value: "a"},
+				&object.Null{},
+			}},
+		},
+		{
+			name:     "Replace With Backreferences",
+			input:    `replace_regex("2024-01-31", "(\\d+)-(\\d+)-(\\d+)", "$3/$2/$1");`,
+			excepted: &object.String{Value: "31/01/2024"},
+		},
+		{
+			name:     "Replace All",
+			input:    `replace_regex("a1b22c", "[0-9]+", "#");`,
+			excepted: &object.String{Value: "a#b#c"},
+		},
+		{
+			name:     "Cached Pattern In Loop",
+			input:    `var n = 0; for var i = 0; i < 100; i += 1 { if match("^x+$", "xxx") != null { n += 1; }; }; n;`,
+			excepted: &object.Int{Value: 100},
+		},
+		{
+			name:  "Invalid Pattern",
+			input: `match("[a-", "a");`,
+			err:   "Parse Error: invalid pattern \"[a-\"",
+		},
+		{
+			name:  "Invalid Replace Pattern",
+			input: `replace_regex("a", "*", "b");`,
+			err:   "Parse Error: invalid pattern \"*\"",
+		},
+		{
+			name:  "Non-String Pattern",
+			input: `match(1, "a");`,
+			err:   "match() pattern must be a string.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			var val object.Object
+			for _, statement := range program.Statements {
+				if expressionStatement, ok := statement.(*ast.ExpressionStatement); ok {
+					val = e.Eval(expressionStatement.Expr, env)
+				} else {
+					e.Eval(statement, env)
+				}
+				if e.Err != nil {
+					break
+				}
+			}
+			if tt.err != "" {
+				if e.Err == nil || !strings.Contains(e.Err.Error(), tt.err) {
+					t.Errorf("err = %+v, expected %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_FunctionString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Function Without Parameters",
+			input:    "func f() { return 1; };\nf;",
+			excepted: "func f() {...}",
+		},
+		{
+			name:     "Function With Defaults",
+			input:    "func add(a, b = 1, c = \"x\") { return a; };\nadd;",
+			excepted: "func add(a, b=1, c=\"x\") {...}",
+		},
+		{
+			name:     "Builtin Function",
+			input:    "var x = 0;\nlen;",
+			excepted: "builtin func len(a) {...}",
+		},
+		{
+			name:     "Builtin Function With Optional Parameter",
+			input:    "var x = 0;\nreduce;",
+			excepted: "builtin func reduce(fn, list, init?) {...}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			e.Eval(program.Statements[0], env)
+			val := e.Eval(program.Statements[1].(*ast.ExpressionStatement).Expr, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			if val.String() != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, val.String())
+			}
+		})
+	}
+}
+
+func TestEvaluator_BuiltinEval(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      error
+	}{
+		{
+			name:     "Expression",
+			input:    `eval("1 + 2");`,
+			excepted: &object.Int{Value: 3},
+		},
+		{
+			name:     "Current Environment",
+			input:    `eval("x * 2");`,
+			excepted: &object.Int{Value: 20},
+		},
+		{
+			name:     "Multiple Statements",
+			input:    `eval("var y = x + 1; y * 2;");`,
+			excepted: &object.Int{Value: 22},
+		},
+		{
+			name:     "Nested Eval",
+			input:    `eval("eval(\"x + 1\")");`,
+			excepted: &object.Int{Value: 11},
+		},
+		{
+			name:     "Empty Source",
+			input:    `eval("");`,
+			excepted: &object.Null{},
+		},
+		{
+			name:  "Syntax Error",
+			input: `eval("1 +");`,
+			err:   &SyntaxError{},
+		},
+		{
+			name:  "Runtime Error",
+			input: `eval("undefined + 1");`,
+			err:   &VariableError{},
+		},
+		{
+			name:  "Unbounded Recursion",
+			input: `eval("func f() { return eval(\"f()\"); }; f()");`,
+			err:   &RecursionError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", "var x = 10;\n"+tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			e := NewEvaluator(f)
+			e.Eval(program.Statements[0], env)
+			val := e.Eval(program.Statements[1].(*ast.ExpressionStatement).Expr, env)
+			if tt.err != nil {
+				if reflect.TypeOf(e.Err) != reflect.TypeOf(tt.err) {
+					t.Errorf("err = %T, expected %T", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_EvalScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		err      string
+	}{
+		{
+			name:     "Reads Caller Locals",
+			input:    "func f() { var x = 20; return eval(\"x + 1\"); };\nprint(f());",
+			excepted: "21",
+		},
+		{
+			name:     "Assigns Caller Locals",
+			input:    "func f() { var x = 1; eval(\"x = x + 41;\"); return x; };\nprint(f());",
+			excepted: "42",
+		},
+		{
+			name:     "Assigns Module Variables",
+			input:    "var x = 1;\neval(\"x += 1;\");\nprint(x);",
+			excepted: "2",
+		},
+		{
+			name:     "Declarations Visible Within The Same Source",
+			input:    "print(eval(\"var y = 2; y * 3;\"));",
+			excepted: "6",
+		},
+		{
+			name:  "Declarations Do Not Reach The Next Eval",
+			input: "func f() { eval(\"var z = 1;\"); return eval(\"z\"); };\nf();",
+			err:   "undefined variable \"z\".",
+		},
+		{
+			name:  "Declarations Do Not Reach The Caller",
+			input: "eval(\"var z = 1;\");\nprint(z);",
+			err:   "undefined variable \"z\".",
+		},
+		{
+			name:     "Declarations Shadow Caller Variables",
+			input:    "var x = 1;\nprint(eval(\"var x = 5; x;\"));\nprint(x);",
+			excepted: "51",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, resolve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/resolve=%t", tt.name, resolve), func(t *testing.T) {
+				got, err := runScoped(t, tt.input, resolve)
+				var message string
+				if err != nil {
+					message = err.(interface{ ErrorMessage() string }).ErrorMessage()
+				}
+				if got != tt.excepted || message != tt.err {
+					t.Errorf("excepted %q and error %q, got %q and error %q", tt.excepted, tt.err, got, message)
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluator_SandboxBuiltins(t *testing.T) {
+	run := func(input string, sandbox bool) (object.Object, error) {
+		env := object.NewGlobalEnvironment([]string{"a"})
+		if sandbox {
+			env.Sandbox()
+		}
+		l := lexer.NewLexer("<test>", input)
+		p, _ := parser.NewParser(l)
+		program := p.ParseProgram()
+		if p.Err != nil {
+			t.Fatalf("parse err = %+v", p.Err)
+		}
+		e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+		e.Sandbox = sandbox
+		val := e.Eval(program.Statements[0].(*ast.ExpressionStatement).Expr, env)
+		return val, e.Err
+	}
+
+	// 按能力标记找出需要在沙箱中禁用的内置函数
+	var tagged []string
+	for name, sym := range object.NewGlobalEnvironment(nil).Store {
+		if builtin, ok := sym.Value.(*object.BuiltinFunction); ok && builtin.Capability != 0 {
+			tagged = append(tagged, name)
+		}
+	}
+	slices.Sort(tagged)
+	if excepted := []string{"args", "exit"}; !reflect.DeepEqual(tagged, excepted) {
+		t.Fatalf("excepted tagged builtins %v, got %v", excepted, tagged)
+	}
+
+	for _, name := range tagged {
+		t.Run(name, func(t *testing.T) {
+			_, err := run(name+"();", true)
+			var sandboxErr *object.SandboxError
+			if !errors.As(err, &sandboxErr) {
+				t.Fatalf("excepted SandboxError, got %+v", err)
+			}
+			if excepted := name + "() is disabled in sandbox."; sandboxErr.Message != excepted {
+				t.Errorf("excepted message %q, got %q", excepted, sandboxErr.Message)
+			}
+			if sandboxErr.PosStart == nil || sandboxErr.PosStart.Col != 1 {
+				t.Errorf("excepted error at call position, got %+v", sandboxErr.PosStart)
+			}
+			if _, err := run(name+"();", false); errors.As(err, &sandboxErr) {
+				t.Errorf("excepted %s() to work outside sandbox, got %+v", name, err)
+			}
+		})
+	}
+
+	t.Run("Untagged Builtins Still Work", func(t *testing.T) {
+		val, err := run(`len(split("a,b", ","));`, true)
+		if err != nil {
+			t.Fatalf("err = %+v, expected nil", err)
+		}
+		if !object.DeepEquals(val, &object.Int{Value: 2}) {
+			t.Errorf("excepted 2, got %+v", val)
+		}
+	})
+}
+
+func TestEvaluator_SandboxImports(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "module.gh")
+	if err := os.WriteFile(module, []byte("const source = \"file\";\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %+v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "ghost_modules"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ghost_modules", "shadow.gh"), []byte("const source = \"ghost_modules\";\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %+v", err)
+	}
+	stdlib := Stdlib
+	Stdlib = fstest.MapFS{
+		"shadow.gh": {Data: []byte("const source = \"stdlib\";\n")},
+	}
+	defer func() {
+		Stdlib = stdlib
+	}()
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      string
+	}{
+		{
+			name:     "Stdlib Only",
+			input:    "import shadow;\nsource;",
+			excepted: &object.String{Value: "stdlib"},
+		},
+		{
+			name:  "File Path",
+			input: "import \"module.gh\";\nsource;",
+			err:   `importing "module.gh" is disabled in sandbox`,
+		},
+		{
+			name:  "Missing Name",
+			input: "import module;\nsource;",
+			err:   `importing "module" is disabled in sandbox`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			env.Sandbox()
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.File = filepath.Join(dir, "main.gh")
+			e.Sandbox = true
+			e.Eval(program.Statements[0], env)
+			if tt.err != "" {
+				var sandboxErr *object.SandboxError
+				if !errors.As(e.Err, &sandboxErr) || !strings.Contains(sandboxErr.Message, tt.err) {
+					t.Errorf("err = %+v, expected %q", e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			val := e.Eval(program.Statements[1].(*ast.ExpressionStatement).Expr, env)
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+			if len(e.Sources()) != 0 {
+				t.Errorf("excepted no files to be read, got %v", e.Sources())
+			}
+		})
+	}
+}
+
+func TestEvaluator_ModuleCache(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "cached.gh")
+	if err := os.WriteFile(module, []byte("const source = 1;\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %+v", err)
+	}
+	parses := 0
+	ModulePrograms.OnParse = func(path string) {
+		if path == module {
+			parses++
+		}
+	}
+	defer func() {
+		ModulePrograms.OnParse = nil
+	}()
+	// 每次导入都使用新的解释器，模块不会命中解释器自身的模块缓存
+	importModule := func() object.Object {
+		t.Helper()
+		l := lexer.NewLexer("<test>", "import \"cached.gh\";\nsource;")
+		p, _ := parser.NewParser(l)
+		program := p.ParseProgram()
+		if p.Err != nil {
+			t.Fatalf("parse err = %+v", p.Err)
+		}
+		e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+		e.File = filepath.Join(dir, "main.gh")
+		env := object.NewGlobalEnvironment(nil)
+		e.Eval(program.Statements[0], env)
+		if e.Err != nil {
+			t.Fatalf("err = %+v, expected nil", e.Err)
+		}
+		val := e.Eval(program.Statements[1].(*ast.ExpressionStatement).Expr, env)
+		if e.Err != nil {
+			t.Fatalf("err = %+v, expected nil", e.Err)
+		}
+		return val
+	}
+
+	testutil.AssertObject(t, &object.Int{Value: 1}, importModule())
+	testutil.AssertObject(t, &object.Int{Value: 1}, importModule())
+	if parses != 1 {
+		t.Errorf("excepted the module to be parsed once, got %d", parses)
+	}
+	if err := os.WriteFile(module, []byte("const source = 2;\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %+v", err)
+	}
+	testutil.AssertObject(t, &object.Int{Value: 2}, importModule())
+	if parses != 2 {
+		t.Errorf("excepted the modified module to be parsed again, got %d parses", parses)
+	}
+}
+
+func TestEvaluator_ResourceLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		input    string
+		excepted string
+		limit    ResourceLimit
+	}{
+		{
+			name:     "Infinite Loop",
+			limits:   Limits{MaxSteps: 1000},
+			input:    "for var i = 0; i < 1; i += 0 { };",
+			excepted: "maximum of 1000 steps exceeded.",
+			limit:    StepLimit,
+		},
+		{
+			name:     "Infinite Loop In Try",
+			limits:   Limits{MaxSteps: 1000},
+			input:    "try { while true {}; } catch (e) {};",
+			excepted: "maximum of 1000 steps exceeded.",
+			limit:    StepLimit,
+		},
+		{
+			name:     "Infinite Loop With Timeout",
+			limits:   Limits{Timeout: 10 * time.Millisecond},
+			input:    "for var i = 0; i < 1; i += 0 { };",
+			excepted: "execution time limit of 10ms exceeded.",
+			limit:    TimeLimit,
+		},
+		{
+			name:     "Unbounded Recursion",
+			limits:   Limits{MaxDepth: 50},
+			input:    "func f(n) { return f(n + 1); };\nf(0);",
+			excepted: "maximum call depth of 50 exceeded.",
+			limit:    DepthLimit,
+		},
+		{
+			name:     "Unbounded Recursion In Try",
+			limits:   Limits{MaxDepth: 50},
+			input:    "func f(n) { try return f(n + 1) catch (e) return 0; };\nf(0);",
+			excepted: "maximum call depth of 50 exceeded.",
+			limit:    DepthLimit,
+		},
+		{
+			name:     "String Repetition",
+			limits:   Limits{MaxAlloc: 100},
+			input:    `var s = "ab" * 1000;`,
+			excepted: "allocation of 2000 bytes exceeds the limit of 100 bytes.",
+			limit:    AllocLimit,
+		},
+		{
+			name:     "Repetition Count On Left",
+			limits:   Limits{MaxAlloc: 100},
+			input:    `var s = 1000 * "ab";`,
+			excepted: "allocation of 2000 bytes exceeds the limit of 100 bytes.",
+			limit:    AllocLimit,
+		},
+		{
+			name:     "Repetition Overflow",
+			limits:   Limits{MaxAlloc: 100},
+			input:    `var s = "ab" * 9223372036854775807;`,
+			excepted: "allocation of 9223372036854775807 bytes exceeds the limit of 100 bytes.",
+			limit:    AllocLimit,
+		},
+		{
+			name:     "List Repetition",
+			limits:   Limits{MaxAlloc: 100},
+			input:    "var l = [1] * 7;",
+			excepted: "allocation of 112 bytes exceeds the limit of 100 bytes.",
+			limit:    AllocLimit,
+		},
+		{
+			name:     "Concatenation In Loop",
+			limits:   Limits{MaxAlloc: 100},
+			input:    `var s = ""; for var i = 0; i < 1000; i += 1 { s += "abcd"; };`,
+			excepted: "allocation of 104 bytes exceeds the limit of 100 bytes.",
+			limit:    AllocLimit,
+		},
+		{
+			name:     "List Growth From Iterator",
+			limits:   Limits{MaxAlloc: 1024},
+			input:    "var l = list(range(1000000000000));",
+			excepted: "allocation of 1040 bytes exceeds the limit of 1024 bytes.",
+			limit:    AllocLimit,
+		},
+		{
+			name:     "Builtin Result",
+			limits:   Limits{MaxAlloc: 100},
+			input:    `var s = replace("a" * 60, "a", "bb");`,
+			excepted: "allocation of 120 bytes exceeds the limit of 100 bytes.",
+			limit:    AllocLimit,
+		},
+		{
+			name:   "Within Limits",
+			limits: Limits{MaxSteps: 10000, MaxDepth: 20, MaxAlloc: 100},
+			input:  "func f(n) { if n == 0 { return \"\"; }; return f(n - 1) + \"ab\"; };\nvar s = f(10) * 2;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := object.NewGlobalEnvironment(nil)
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.Limits = tt.limits
+			e.Eval(program, env)
+			if tt.excepted == "" {
+				if e.Err != nil {
+					t.Fatalf("err = %+v, expected nil", e.Err)
+				}
+				return
+			}
+			var resourceErr *ResourceError
+			if !errors.As(e.Err, &resourceErr) {
+				t.Fatalf("excepted ResourceError, got %+v", e.Err)
+			}
+			if resourceErr.Limit != tt.limit || resourceErr.Message != tt.excepted {
+				t.Errorf("excepted %d %q, got %d %q", tt.limit, tt.excepted, resourceErr.Limit, resourceErr.Message)
+			}
+			if resourceErr.PosStart == nil {
+				t.Errorf("excepted error position, got nil")
+			}
+		})
+	}
+}
+
+func TestEvaluator_VisitImportStatement(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"helper.gh":              "calls = calls + 1;\nfunc add(a, b) { return a + b; };\nconst answer = 42;\n",
+		"lib/nested.gh":          "import \"util.gh\";\nfunc twice(x) { return double(x) * 2; };\n",
+		"lib/util.gh":            "func double(x) { return x * 2; };\n",
+		"cycle_a.gh":             "import \"cycle_b.gh\";\n",
+		"cycle_b.gh":             "import \"cycle_a.gh\";\n",
+		"broken.gh":              "var x = (1 + 2;\n",
+		"conflict.gh":            "var value = 1;\n",
+		"runtime_err.gh":         "undefined + 1;\n",
+		"uses_builtin.gh":        "var size = len([1, 2, 3]);\n",
+		"ghost_modules/strs.gh":  "calls = calls + 1;\nfunc shout(s) { return s + \"!\"; };\n",
+		"ghost_modules/local.gh": "const source = \"ghost_modules\";\n",
+		"gopath/local.gh":        "const source = \"GHOSTPATH\";\n",
+		"gopath/pathonly.gh":     "const source = \"GHOSTPATH\";\n",
+		"lib/by_name.gh":         "import strs;\nfunc greet() { return shout(\"hi\"); };\n",
+	}
+	t.Setenv("GHOSTPATH", filepath.Join(dir, "gopath"))
+	stdlib := Stdlib
+	Stdlib = fstest.MapFS{
+		"local.gh":   {Data: []byte("const source = \"stdlib\";\n")},
+		"stdonly.gh": {Data: []byte("const source = \"stdlib\";\n")},
+	}
+	defer func() {
+		Stdlib = stdlib
+	}()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %+v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write fixture: %+v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted object.Object
+		err      error
+	}{
+		{
+			name:     "Imported Function",
+			input:    "import \"helper.gh\";\nadd(1, 2);",
+			excepted: &object.Int{Value: 3},
+		},
+		{
+			name:     "Imported Constant",
+			input:    "import \"helper.gh\";\nanswer;",
+			excepted: &object.Int{Value: 42},
+		},
+		{
+			name:     "Repeated Import Runs Once",
+			input:    "import \"helper.gh\";\nimport \"helper.gh\";\n{ import \"helper.gh\"; };\ncalls;",
+			excepted: &object.Int{Value: 1},
+		},
+		{
+			name:     "Relative To Importing File",
+			input:    "import \"lib/nested.gh\";\ntwice(3);",
+			excepted: &object.Int{Value: 12},
+		},
+		{
+			name:     "Builtins Available In Module",
+			input:    "import \"uses_builtin.gh\";\nsize;",
+			excepted: &object.Int{Value: 3},
+		},
+		{
+			name:     "Import By Name From Ghost Modules",
+			input:    "import strs;\nshout(\"a\");",
+			excepted: &object.String{Value: "a!"},
+		},
+		{
+			name:     "Ghost Modules Before GHOSTPATH And Stdlib",
+			input:    "import local;\nsource;",
+			excepted: &object.String{Value: "ghost_modules"},
+		},
+		{
+			name:     "GHOSTPATH Before Stdlib",
+			input:    "import pathonly;\nsource;",
+			excepted: &object.String{Value: "GHOSTPATH"},
+		},
+		{
+			name:     "Stdlib",
+			input:    "import stdonly;\nsource;",
+			excepted: &object.String{Value: "stdlib"},
+		},
+		{
+			name:     "Nested Module Uses Entry Ghost Modules",
+			input:    "import \"lib/by_name.gh\";\ngreet();",
+			excepted: &object.String{Value: "hi!"},
+		},
+		{
+			name:     "Same File By Name And Path Runs Once",
+			input:    "import strs;\nimport \"ghost_modules/strs.gh\";\ncalls;",
+			excepted: &object.Int{Value: 1},
+		},
+		{
+			name:  "Missing Module Name",
+			input: "import missing;\n0;",
+			err:   &ImportError{},
+		},
+		{
+			name:  "Circular Import",
+			input: "import \"cycle_a.gh\";\n0;",
+			err:   &ImportError{},
+		},
+		{
+			name:  "Missing File",
+			input: "import \"missing.gh\";\n0;",
+			err:   &ImportError{},
+		},
+		{
+			name:  "Syntax Error In Module",
+			input: "import \"broken.gh\";\n0;",
+			err:   &SyntaxError{},
+		},
+		{
+			name:  "Runtime Error In Module",
+			input: "import \"runtime_err.gh\";\n0;",
+			err:   &VariableError{},
+		},
+		{
+			name:  "Name Conflict",
+			input: "var value = 0;\nimport \"conflict.gh\";\n0;",
+			err:   &ImportError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &object.Environment{
+				Store: make(map[string]*object.Symbol),
+				Outer: nil,
+			}
+			for name, builtin := range object.Builtins {
+				env.Store[name] = &object.Symbol{Name: name, Value: builtin, IsConst: true}
+			}
+			env.Store["calls"] = &object.Symbol{Name: "calls", Value: &object.Int{Value: 0}}
+			f := &frame.Frame{
+				FuncName: "<test>",
+				Parent:   nil,
+				PosStart: nil,
+				PosEnd:   nil,
+			}
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(f)
+			e.File = filepath.Join(dir, "main.gh")
+			var val object.Object
+			for _, statement := range program.Statements {
+				if expressionStatement, ok := statement.(*ast.ExpressionStatement); ok {
+					val = e.Eval(expressionStatement.Expr, env)
+				} else {
+					val = e.Eval(statement, env)
+				}
+				if e.Err != nil {
+					break
+				}
+			}
+			if tt.err != nil {
+				if reflect.TypeOf(e.Err) != reflect.TypeOf(tt.err) {
+					t.Errorf("err = %T (%v), expected %T", e.Err, e.Err, tt.err)
+				}
+				return
+			}
+			if e.Err != nil {
+				t.Fatalf("err = %+v, expected nil", e.Err)
+			}
+			testutil.AssertObject(t, tt.excepted, val)
+		})
+	}
+}
+
+func TestEvaluator_ResolveModule(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	t.Setenv("GHOSTPATH", first+string(filepath.ListSeparator)+second)
+	stdlib := Stdlib
+	Stdlib = fstest.MapFS{}
+	defer func() {
+		Stdlib = stdlib
+	}()
+	entry := filepath.Join(dir, "main.gh")
+
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:  "Missing Path",
+			input: `import "lib/missing.gh";`,
+			excepted: fmt.Sprintf(`cannot find module "lib/missing.gh" (tried "%s").`,
+				filepath.Join(dir, "lib", "missing.gh")),
+		},
+		{
+			name:  "Missing Name",
+			input: `import missing;`,
+			excepted: fmt.Sprintf(`cannot find module "missing" (tried "%s", "%s", "%s", "<stdlib>/missing.gh").`,
+				filepath.Join(dir, "ghost_modules", "missing.gh"),
+				filepath.Join(first, "missing.gh"),
+				filepath.Join(second, "missing.gh")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := parser.NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("parse err = %+v", p.Err)
+			}
+			e := NewEvaluator(&frame.Frame{FuncName: "<test>"})
+			e.File = entry
+			err, ok := e.ResolveImports(program).(*ImportError)
+			if !ok {
+				t.Fatalf("err = %+v, expected *ImportError", err)
+			}
+			if err.Message != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, err.Message)
 			}
 		})
 	}
@@ -0,0 +1,260 @@
+package evaluator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parsecache"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+	"github.com/Ghost-Xiao/ghost-lang/internal/stdlib"
+)
+
+// 模块解析使用的名称
+
+const (
+	moduleExtension = ".gh"           // 模块文件的扩展名
+	modulesDirName  = "ghost_modules" // 入口脚本旁存放按名称导入的模块的目录
+	ghostPathEnv    = "GHOSTPATH"     // 按名称导入时额外查找的目录列表，以系统路径列表分隔符分隔
+	stdlibPrefix    = "<stdlib>/"     // 标准库模块路径的前缀，标准库模块没有真实的文件路径
+)
+
+// Stdlib 按名称导入时最后查找的标准库模块，根目录下的<name>.gh为名为name的模块
+var Stdlib = stdlib.FS()
+
+// ModulePrograms 导入的模块的语法树，同一进程中的所有解释器共享，
+// 监视模式重新执行或测试运行器执行多个测试文件时，未修改的模块不会重复分析
+var ModulePrograms = &parsecache.Programs{}
+
+// ResolveModule 解析import语句导入的模块文件，不读取也不执行模块
+// 按路径导入时相对路径基于导入文件所在的目录解析；按名称导入时依次查找
+// 入口脚本所在目录下的ghost_modules目录、GHOSTPATH中的各个目录和内嵌的标准库
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//	file - 导入模块的文件路径，为空时相对于工作目录解析
+//	entry - 入口脚本的路径，为空时在工作目录下查找ghost_modules
+//
+// 返回值:
+//
+//	string - 模块的绝对路径，标准库模块为<stdlib>/<name>.gh，找不到模块时为空
+//	[]string - 按顺序尝试过的所有路径
+func ResolveModule(importStatement *ast.ImportStatement, file, entry string) (string, []string) {
+	if importStatement.Name == nil {
+		path := importStatement.Path.Value
+		if !filepath.IsAbs(path) && file != "" {
+			path = filepath.Join(filepath.Dir(file), path)
+		}
+		path = absolutePath(path)
+		if !isFile(path) {
+			return "", []string{path}
+		}
+		return path, []string{path}
+	}
+
+	fileName := importStatement.Name.Name + moduleExtension
+	dirs := []string{filepath.Join(filepath.Dir(entry), modulesDirName)}
+	for _, dir := range filepath.SplitList(os.Getenv(ghostPathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	var tried []string
+	for _, dir := range dirs {
+		path := absolutePath(filepath.Join(dir, fileName))
+		tried = append(tried, path)
+		if isFile(path) {
+			return path, tried
+		}
+	}
+	tried = append(tried, stdlibPrefix+fileName)
+	if info, err := fs.Stat(Stdlib, fileName); err == nil && !info.IsDir() {
+		return stdlibPrefix + fileName, tried
+	}
+	return "", tried
+}
+
+// IsStdlibModule 判断模块路径是否为ResolveModule返回的内嵌标准库模块路径
+//
+// 参数:
+//
+//	path - 模块路径
+//
+// 返回值:
+//
+//	bool - 是否为标准库模块
+func IsStdlibModule(path string) bool {
+	return strings.HasPrefix(path, stdlibPrefix)
+}
+
+// resolveStdlibModule 只在内嵌的标准库中查找按名称导入的模块，用于沙箱模式
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//
+// 返回值:
+//
+//	string - 标准库模块的路径<stdlib>/<name>.gh，按路径导入或找不到模块时为空
+func resolveStdlibModule(importStatement *ast.ImportStatement) string {
+	if importStatement.Name == nil {
+		return ""
+	}
+	fileName := importStatement.Name.Name + moduleExtension
+	if info, err := fs.Stat(Stdlib, fileName); err != nil || info.IsDir() {
+		return ""
+	}
+	return stdlibPrefix + fileName
+}
+
+// ResolveImports 解析程序顶层的import语句导入的模块但不执行，用于检查导入是否有效
+// 当前文件为File，同时将其视为入口脚本
+//
+// 参数:
+//
+//	program - 要检查的程序
+//
+// 返回值:
+//
+//	error - 第一个找不到的模块对应的导入错误，所有模块都能找到时为nil
+func (e *Evaluator) ResolveImports(program *ast.Program) error {
+	for _, statement := range program.Statements {
+		importStatement, ok := statement.(*ast.ImportStatement)
+		if !ok {
+			continue
+		}
+		if absPath, tried := ResolveModule(importStatement, e.File, e.File); absPath == "" {
+			return e.moduleNotFound(importStatement, tried)
+		}
+	}
+	return nil
+}
+
+// ParseModule 查找并分析import语句导入的模块，不执行模块，用于执行之前的静态检查
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//	file - 导入模块的文件路径
+//	entry - 入口脚本的路径
+//
+// 返回值:
+//
+//	string - 模块的绝对路径
+//	*ast.Program - 模块的语法树
+//	bool - 是否找到并成功分析了模块
+func ParseModule(importStatement *ast.ImportStatement, file, entry string) (string, *ast.Program, bool) {
+	absPath, _ := ResolveModule(importStatement, file, entry)
+	if absPath == "" {
+		return "", nil, false
+	}
+	data, err := readModule(absPath)
+	if err != nil {
+		return "", nil, false
+	}
+	program, err := parseModule(absPath, data)
+	if err != nil {
+		return "", nil, false
+	}
+	return absPath, program, true
+}
+
+// parseModule 对模块的源代码进行词法和语法分析并解析名称，模块未修改时返回ModulePrograms中缓存的语法树
+//
+// 参数:
+//
+//	absPath - 模块的绝对路径
+//	data - 模块的源代码
+//
+// 返回值:
+//
+//	*ast.Program - 模块的语法树
+//	error - 词法或语法错误
+func parseModule(absPath string, data []byte) (*ast.Program, error) {
+	source := strings.ReplaceAll(string(data), "\t", "    ")
+	return ModulePrograms.Get(absPath, source, func() (*ast.Program, error) {
+		program, err := parseProgram(filepath.Base(absPath), source)
+		if err != nil {
+			return nil, err
+		}
+		// 模块中的局部变量按槽位存取，解析结果随语法树一起缓存
+		sema.Resolve(program, sema.Options{File: absPath})
+		return program, nil
+	})
+}
+
+// moduleNotFound 创建找不到模块的导入错误，错误信息列出所有尝试过的路径
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//	tried - 尝试过的路径
+//
+// 返回值:
+//
+//	*ImportError - 导入错误
+func (e *Evaluator) moduleNotFound(importStatement *ast.ImportStatement, tried []string) *ImportError {
+	quoted := make([]string, len(tried))
+	for i, path := range tried {
+		quoted[i] = fmt.Sprintf("\"%s\"", path)
+	}
+	return &ImportError{
+		Code:     errcode.ImportError,
+		Frame:    e.Frame,
+		Message:  fmt.Sprintf("cannot find module \"%s\" (tried %s).", importStatement.Spec(), strings.Join(quoted, ", ")),
+		PosStart: importStatement.PosStart,
+		PosEnd:   importStatement.PosEnd,
+	}
+}
+
+// readModule 读取模块的源代码，标准库模块从内嵌的文件系统中读取
+//
+// 参数:
+//
+//	absPath - ResolveModule返回的模块路径
+//
+// 返回值:
+//
+//	[]byte - 模块的源代码
+//	error - 读取失败时的错误
+func readModule(absPath string) ([]byte, error) {
+	if name, ok := strings.CutPrefix(absPath, stdlibPrefix); ok {
+		return fs.ReadFile(Stdlib, name)
+	}
+	return os.ReadFile(absPath)
+}
+
+// absolutePath 返回路径的绝对形式，无法解析时原样返回
+//
+// 参数:
+//
+//	path - 文件路径
+//
+// 返回值:
+//
+//	string - 绝对路径
+func absolutePath(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// isFile 判断路径是否为存在的普通文件
+//
+// 参数:
+//
+//	path - 文件路径
+//
+// 返回值:
+//
+//	bool - 是否为存在的普通文件
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,28 @@
+//go:build !(js && wasm)
+
+package evaluator
+
+import (
+	"io"
+	"os"
+)
+
+// defaultStdout 返回平台默认的标准输出
+// 每次调用时读取os.Stdout，替换os.Stdout后的输出同样生效
+//
+// 返回值:
+//
+//	io.Writer - 进程的标准输出
+func defaultStdout() io.Writer {
+	return os.Stdout
+}
+
+// defaultStderr 返回平台默认的标准错误输出
+// 每次调用时读取os.Stderr，替换os.Stderr后的输出同样生效
+//
+// 返回值:
+//
+//	io.Writer - 进程的标准错误输出
+func defaultStderr() io.Writer {
+	return os.Stderr
+}
@@ -0,0 +1,84 @@
+//go:build js && wasm
+
+package evaluator
+
+import (
+	"bytes"
+	"io"
+	"syscall/js"
+)
+
+// outputCallback 宿主页面中接收输出的全局函数名
+// 以流名称("stdout"或"stderr")和不含换行符的一行文本调用，未定义时输出到浏览器控制台
+const outputCallback = "ghostOutput"
+
+var (
+	stdoutWriter = &jsLineWriter{stream: "stdout"} // 标准输出
+	stderrWriter = &jsLineWriter{stream: "stderr"} // 标准错误输出
+)
+
+// defaultStdout 返回平台默认的标准输出
+//
+// 返回值:
+//
+//	io.Writer - 按行调用JS回调函数的标准输出
+func defaultStdout() io.Writer {
+	return stdoutWriter
+}
+
+// defaultStderr 返回平台默认的标准错误输出
+//
+// 返回值:
+//
+//	io.Writer - 按行调用JS回调函数的标准错误输出
+func defaultStderr() io.Writer {
+	return stderrWriter
+}
+
+// jsLineWriter 按行将输出传递给JS回调函数的输出，未以换行符结束的内容缓存到下次写入
+
+type jsLineWriter struct {
+	stream string // 流名称，作为回调函数的第一个参数
+	buf    []byte // 尚未遇到换行符的内容
+}
+
+// Write 写入输出内容，每遇到一个换行符调用一次回调函数
+//
+// 参数:
+//
+//	p - 输出内容
+//
+// 返回值:
+//
+//	int - 写入的字节数，总是等于len(p)
+//	error - 总是为nil
+func (w *jsLineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.post(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// post 将一行输出传递给JS回调函数，回调函数未定义时输出到浏览器控制台
+//
+// 参数:
+//
+//	line - 不含换行符的一行文本
+func (w *jsLineWriter) post(line string) {
+	callback := js.Global().Get(outputCallback)
+	if callback.Type() == js.TypeFunction {
+		callback.Invoke(w.stream, line)
+		return
+	}
+	method := "log"
+	if w.stream == "stderr" {
+		method = "error"
+	}
+	js.Global().Get("console").Call(method, line)
+}
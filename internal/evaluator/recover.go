@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// guardedEval 在最外层的Eval中执行节点，将执行过程中的panic转换为InternalError
+// 嵌套的Eval不再设置恢复，避免每个节点都产生额外开销
+//
+// 参数:
+//
+//	node - 要执行的节点
+//	env - 执行环境
+//
+// 返回值:
+//
+//	object.Object - 节点执行结果值，发生错误或panic时为nil
+func (e *Evaluator) guardedEval(node ast.Node, env *object.Environment) (result object.Object) {
+	e.guarded = true
+	defer func() {
+		e.guarded = false
+		// 发生panic时循环体可能没有正常退出
+		e.loopDepth = 0
+	}()
+	defer e.recoverPanic(&result, nil, nil)
+	return e.Eval(node, env)
+}
+
+// recoverPanic 恢复执行过程中的panic并设置InternalError，必须由defer直接调用
+//
+// 参数:
+//
+//	result - 被恢复的函数的返回值，发生panic时被设置为nil
+//	posStart - 错误起始位置，为nil时使用发生panic时正在执行的节点的位置
+//	posEnd - 错误结束位置，为nil时使用发生panic时正在执行的节点的位置
+func (e *Evaluator) recoverPanic(result *object.Object, posStart, posEnd *util.Pos) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	if posStart == nil || posEnd == nil {
+		posStart, posEnd = nodeSpan(e.current)
+	}
+	*result = nil
+	e.Err = &InternalError{
+		Code:     errcode.InternalError,
+		Frame:    e.Frame,
+		Message:  fmt.Sprintf("interpreter panic: %v. this is a bug in ghost-lang.", value),
+		PosStart: posStart,
+		PosEnd:   posEnd,
+		Value:    value,
+		Stack:    string(debug.Stack()),
+	}
+}
+
+// nodeSpan 返回节点的位置，节点为nil指针等无法取得位置时返回nil
+//
+// 参数:
+//
+//	node - 节点
+//
+// 返回值:
+//
+//	*util.Pos - 节点的起始位置
+//	*util.Pos - 节点的结束位置
+func nodeSpan(node ast.Node) (posStart, posEnd *util.Pos) {
+	// 节点本身可能就是引起panic的原因
+	defer func() {
+		if recover() != nil {
+			posStart, posEnd = nil, nil
+		}
+	}()
+	return ast.Span(node)
+}
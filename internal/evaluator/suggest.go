@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// undefinedVariableError 创建未定义变量的错误，存在相近的可见名称时在错误信息中给出建议
+//
+// 参数:
+//
+//	name - 未定义的变量名
+//	env - 执行环境
+//	posStart - 错误起始位置
+//	posEnd - 错误结束位置
+//
+// 返回值:
+//
+//	*VariableError - 变量错误
+func (e *Evaluator) undefinedVariableError(name string, env *object.Environment, posStart, posEnd *util.Pos) *VariableError {
+	message := fmt.Sprintf("undefined variable \"%s\".", name)
+	if suggestion, ok := suggestName(name, env); ok {
+		message += fmt.Sprintf(" did you mean \"%s\"?", suggestion)
+	}
+	return &VariableError{
+		Code:     errcode.VariableError,
+		Frame:    e.Frame,
+		Message:  message,
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// undeclaredAssignmentError 创建对未定义变量赋值的错误，除相近名称的建议外，提示先用var声明变量
+//
+// 参数:
+//
+//	name - 未定义的变量名
+//	env - 执行环境
+//	posStart - 错误起始位置
+//	posEnd - 错误结束位置
+//
+// 返回值:
+//
+//	*VariableError - 变量错误
+func (e *Evaluator) undeclaredAssignmentError(name string, env *object.Environment, posStart, posEnd *util.Pos) *VariableError {
+	err := e.undefinedVariableError(name, env, posStart, posEnd)
+	err.Message += fmt.Sprintf(" declare it first with \"var %s = ...\"", name)
+	return err
+}
+
+// suggestName 在作用域链中可见的名称和内置函数名中查找与name最相近的名称
+//
+// 参数:
+//
+//	name - 未定义的变量名
+//	env - 执行环境
+//
+// 返回值:
+//
+//	string - 最相近的名称
+//	bool - 是否找到足够相近的名称
+func suggestName(name string, env *object.Environment) (string, bool) {
+	candidates := env.Names()
+	for builtin := range object.Builtins {
+		candidates = append(candidates, builtin)
+	}
+	return util.SuggestName(name, candidates)
+}
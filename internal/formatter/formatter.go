@@ -0,0 +1,486 @@
+// 实现GoGhost语言的代码格式化工具，将源代码输出为统一的规范形式
+// 格式化基于语法树进行，保留注释、字面量的原始写法以及语句之间的单个空行
+
+package formatter
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// indentUnit 每一级缩进使用的字符串
+const indentUnit = "    "
+
+// posPattern 匹配AST输出中的位置范围，用于比较两棵语法树的结构
+var posPattern = regexp.MustCompile(` \[\d+:\d+-\d+:\d+\]`)
+
+// Format 将源代码格式化为规范形式
+// 格式化结果会被重新解析，语法树与原程序不一致时返回错误而不是输出错误的代码
+//
+// 参数:
+//
+//	fileName - 源代码文件名，用于错误报告
+//	code - 要格式化的源代码
+//
+// 返回值:
+//
+//	string - 格式化后的源代码
+//	error - 词法或语法错误，以及格式化改变程序结构时的错误
+func Format(fileName, code string) (string, error) {
+	program, comments, err := parse(fileName, code)
+	if err != nil {
+		return "", err
+	}
+	p := &printer{source: code, comments: comments}
+	p.statementList(program.Statements, len(code))
+	formatted := p.sb.String()
+	// 校验格式化前后的语法树一致
+	formattedProgram, _, err := parse(fileName, formatted)
+	if err != nil || structure(formattedProgram) != structure(program) {
+		return "", errors.New("ghost-lang: formatting would change the program structure.")
+	}
+	return formatted, nil
+}
+
+// parse 对源代码进行词法和语法分析，同时收集注释
+//
+// 参数:
+//
+//	fileName - 源代码文件名
+//	code - 源代码
+//
+// 返回值:
+//
+//	*ast.Program - 解析得到的程序
+//	[]*lexer.Comment - 源代码中的注释
+//	error - 词法或语法错误
+func parse(fileName, code string) (*ast.Program, []*lexer.Comment, error) {
+	l := lexer.NewLexer(fileName, code)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		return nil, nil, err
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return nil, nil, p.Err
+	}
+	return program, l.Comments, nil
+}
+
+// structure 返回去除位置信息后的语法树表示，用于判断两个程序是否等价
+//
+// 参数:
+//
+//	program - 程序节点
+//
+// 返回值:
+//
+//	string - 语法树的结构表示
+func structure(program *ast.Program) string {
+	return posPattern.ReplaceAllString(ast.Dump(program), "")
+}
+
+// printer 格式化输出器，维护输出缓冲区、缩进层级和待输出的注释
+
+type printer struct {
+	sb       strings.Builder  // 输出缓冲区
+	source   string           // 原始源代码，用于保留字面量和注释的原始写法
+	comments []*lexer.Comment // 源代码中的全部注释
+	next     int              // 下一条待输出注释的下标
+	depth    int              // 当前缩进层级
+	lastRow  int              // 最近输出的内容在源代码中的结束行，用于保留空行
+}
+
+// statementList 按行输出语句列表，并在语句之间穿插注释
+//
+// 参数:
+//
+//	statements - 要输出的语句
+//	endIdx - 列表结束位置的字节索引，之前剩余的注释在列表末尾输出
+func (p *printer) statementList(statements []ast.Statement, endIdx int) {
+	first := true
+	for _, statement := range statements {
+		posStart, posEnd := statementPos(statement)
+		p.leadingComments(posStart.Idx, &first)
+		p.blankLine(posStart.Row, first)
+		first = false
+		p.sb.WriteString(strings.Repeat(indentUnit, p.depth))
+		p.statement(statement)
+		p.sb.WriteString(";")
+		p.trailingComments(posEnd)
+		p.sb.WriteString("\n")
+		p.lastRow = posEnd.Row
+	}
+	p.leadingComments(endIdx, &first)
+}
+
+// leadingComments 在独立的行上输出指定位置之前的注释
+//
+// 参数:
+//
+//	idx - 字节索引，在此之前开始的注释会被输出
+//	first - 是否尚未输出任何内容，输出注释后置为false
+func (p *printer) leadingComments(idx int, first *bool) {
+	for p.next < len(p.comments) && p.comments[p.next].PosStart.Idx < idx {
+		comment := p.comments[p.next]
+		p.blankLine(comment.PosStart.Row, *first)
+		*first = false
+		p.sb.WriteString(strings.Repeat(indentUnit, p.depth))
+		p.sb.WriteString(comment.Text)
+		p.sb.WriteString("\n")
+		p.lastRow = comment.PosEnd.Row
+		p.next++
+	}
+}
+
+// trailingComments 在语句末尾输出语句内部剩余的注释和同一行的注释
+//
+// 参数:
+//
+//	posEnd - 语句的结束位置
+func (p *printer) trailingComments(posEnd *util.Pos) {
+	for p.next < len(p.comments) {
+		comment := p.comments[p.next]
+		if comment.PosStart.Idx >= posEnd.Idx && comment.PosStart.Row != posEnd.Row {
+			return
+		}
+		p.sb.WriteString(" ")
+		p.sb.WriteString(comment.Text)
+		p.next++
+	}
+}
+
+// blankLine 源代码中与上一项内容之间存在空行时输出一个空行
+//
+// 参数:
+//
+//	row - 下一项内容的起始行
+//	first - 是否为列表中的第一项，第一项之前不输出空行
+func (p *printer) blankLine(row int, first bool) {
+	if !first && row > p.lastRow+1 {
+		p.sb.WriteString("\n")
+	}
+}
+
+// statement 输出单个语句，不包含结尾的分号
+//
+// 参数:
+//
+//	statement - 要输出的语句
+func (p *printer) statement(statement ast.Statement) {
+	switch s := statement.(type) {
+	case *ast.ExpressionStatement:
+		p.expression(s.Expr)
+	case *ast.ForStatement:
+		p.sb.WriteString("for ")
+		p.statement(s.Initialization)
+		p.sb.WriteString("; ")
+		p.expression(s.Condition)
+		p.sb.WriteString("; ")
+		p.statement(s.Update)
+		p.sb.WriteString(" ")
+		p.statement(s.Body)
+	case *ast.ForInStatement:
+		p.sb.WriteString("for ")
+		p.expression(s.Variable)
+		if s.ValueVariable != nil {
+			p.sb.WriteString(", ")
+			p.expression(s.ValueVariable)
+		}
+		p.sb.WriteString(" in ")
+		p.expression(s.Iterable)
+		p.sb.WriteString(" ")
+		p.statement(s.Body)
+	case *ast.WhileStatement:
+		p.sb.WriteString("while ")
+		p.expression(s.Condition)
+		p.sb.WriteString(" ")
+		p.statement(s.Body)
+	case *ast.FunctionDeclarationStatement:
+		p.sb.WriteString("func ")
+		p.expression(s.Name)
+		p.parameters(s.Parameter)
+		p.sb.WriteString(" ")
+		p.statement(s.Body)
+	case *ast.ReturnStatement:
+		p.sb.WriteString("return ")
+		p.expression(s.ReturnValue)
+	case *ast.BreakStatement:
+		p.sb.WriteString("break")
+	case *ast.ContinueStatement:
+		p.sb.WriteString("continue")
+	case *ast.TryStatement:
+		p.sb.WriteString("try ")
+		p.statement(s.Body)
+		p.sb.WriteString(" catch (")
+		p.expression(s.Catch.Variable)
+		p.sb.WriteString(") ")
+		p.statement(s.Catch.Body)
+	case *ast.ThrowStatement:
+		p.sb.WriteString("throw ")
+		p.expression(s.Value)
+	case *ast.ImportStatement:
+		p.sb.WriteString("import ")
+		if s.Name != nil {
+			p.expression(s.Name)
+		} else {
+			p.expression(s.Path)
+		}
+	}
+}
+
+// expression 输出单个表达式
+//
+// 参数:
+//
+//	expression - 要输出的表达式
+func (p *printer) expression(expression ast.Expression) {
+	switch e := expression.(type) {
+	case *ast.IntExpression:
+		p.sb.WriteString(p.source[e.PosStart.Idx:e.PosEnd.Idx])
+	case *ast.FloatExpression:
+		p.sb.WriteString(p.source[e.PosStart.Idx:e.PosEnd.Idx])
+	case *ast.StringExpression:
+		p.sb.WriteString(p.source[e.PosStart.Idx:e.PosEnd.Idx])
+	case *ast.InterpolatedStringExpression:
+		// 字符串片段连同两侧的引号和${、}原样输出，插值表达式正常格式化
+		for i, part := range e.Parts {
+			if i%2 == 1 {
+				p.expression(part)
+				continue
+			}
+			literal := part.(*ast.StringExpression)
+			p.sb.WriteString(p.source[literal.PosStart.Idx:literal.PosEnd.Idx])
+		}
+	case *ast.BoolExpression:
+		p.sb.WriteString(e.String())
+	case *ast.NullExpression:
+		p.sb.WriteString(e.String())
+	case *ast.IdentifierExpression:
+		p.sb.WriteString(e.Name)
+	case *ast.ListExpression:
+		p.sb.WriteString("[")
+		for i, elem := range e.Value {
+			if i > 0 {
+				p.sb.WriteString(", ")
+			}
+			p.expression(elem)
+		}
+		p.sb.WriteString("]")
+	case *ast.MapExpression:
+		if len(e.Entries) == 0 {
+			p.sb.WriteString("{:}")
+			break
+		}
+		p.sb.WriteString("{")
+		for i, entry := range e.Entries {
+			if i > 0 {
+				p.sb.WriteString(", ")
+			}
+			p.expression(entry.Key)
+			p.sb.WriteString(": ")
+			p.expression(entry.Value)
+		}
+		p.sb.WriteString("}")
+	case *ast.GroupedExpression:
+		p.sb.WriteString("(")
+		p.expression(e.Expr)
+		p.sb.WriteString(")")
+	case *ast.PrefixExpression:
+		p.sb.WriteString(e.Operator.Literal)
+		// 避免"- -x"被输出为自减运算符"--x"
+		if value := p.render(e.Value); needsSpace(e.Operator.Literal, value) {
+			p.sb.WriteString(" " + value)
+		} else {
+			p.sb.WriteString(value)
+		}
+	case *ast.InfixExpression:
+		p.expression(e.Left)
+		p.sb.WriteString(" " + e.Operator.Literal + " ")
+		p.expression(e.Right)
+	case *ast.VarInitializationExpression:
+		if e.IsConst {
+			p.sb.WriteString("const ")
+		} else {
+			p.sb.WriteString("var ")
+		}
+		p.expression(e.Name)
+		p.sb.WriteString(" = ")
+		p.expression(e.Value)
+	case *ast.VarAssignmentExpression:
+		p.expression(e.Name)
+		p.sb.WriteString(" = ")
+		p.expression(e.Value)
+	case *ast.CompoundAssignmentExpression:
+		p.expression(e.Name)
+		p.sb.WriteString(" " + e.Operator.Literal + " ")
+		p.expression(e.Right)
+	case *ast.PrefixUnaryIncDecExpression:
+		p.sb.WriteString(e.Operator.Literal)
+		p.expression(e.Right)
+	case *ast.PostfixUnaryIncDecExpression:
+		p.expression(e.Left)
+		p.sb.WriteString(e.Operator.Literal)
+	case *ast.BlockExpression:
+		p.block(e)
+	case *ast.IfExpression:
+		p.sb.WriteString("if ")
+		p.expression(e.Condition)
+		p.sb.WriteString(" ")
+		p.statement(e.Consequence)
+		if e.Alternative != nil {
+			p.sb.WriteString(" else ")
+			p.statement(e.Alternative)
+		}
+	case *ast.TernaryExpression:
+		p.expression(e.Condition)
+		p.sb.WriteString(" ? ")
+		p.expression(e.Consequence)
+		p.sb.WriteString(" : ")
+		p.expression(e.Alternative)
+	case *ast.FunctionExpression:
+		p.sb.WriteString("func")
+		p.parameters(e.Parameter)
+		p.sb.WriteString(" ")
+		p.statement(e.Body)
+	case *ast.CallExpression:
+		p.expression(e.Function)
+		p.sb.WriteString("(")
+		for i, arg := range e.Argument {
+			if i > 0 {
+				p.sb.WriteString(", ")
+			}
+			// 空参数表示使用默认值
+			if arg != nil {
+				p.expression(arg)
+			}
+		}
+		p.sb.WriteString(")")
+	case *ast.IndexExpression:
+		p.expression(e.Target)
+		p.sb.WriteString("[")
+		p.expression(e.Index)
+		p.sb.WriteString("]")
+	case *ast.SliceExpression:
+		p.expression(e.Target)
+		p.sb.WriteString("[")
+		p.expression(e.Low)
+		p.sb.WriteString(":")
+		p.expression(e.High)
+		p.sb.WriteString("]")
+	}
+}
+
+// parameters 输出括号中的函数参数列表，带默认值的参数输出为"参数名=默认值"
+//
+// 参数:
+//
+//	params - 函数的参数
+func (p *printer) parameters(params []*ast.Parameter) {
+	p.sb.WriteString("(")
+	for i, param := range params {
+		if i > 0 {
+			p.sb.WriteString(", ")
+		}
+		p.expression(param.Name)
+		if param.DefaultValue != nil {
+			p.sb.WriteString("=")
+			p.expression(param.DefaultValue)
+		}
+	}
+	p.sb.WriteString(")")
+}
+
+// block 输出块表达式，块内语句缩进一级
+//
+// 参数:
+//
+//	block - 要输出的块表达式
+func (p *printer) block(block *ast.BlockExpression) {
+	hasComment := p.next < len(p.comments) && p.comments[p.next].PosStart.Idx < block.PosEnd.Idx
+	if len(block.Statements) == 0 && !hasComment {
+		p.sb.WriteString("{}")
+		return
+	}
+	p.sb.WriteString("{\n")
+	p.depth++
+	p.statementList(block.Statements, block.PosEnd.Idx-1)
+	p.depth--
+	p.sb.WriteString(strings.Repeat(indentUnit, p.depth) + "}")
+}
+
+// render 将表达式输出为独立的字符串
+//
+// 参数:
+//
+//	expression - 要输出的表达式
+//
+// 返回值:
+//
+//	string - 表达式的格式化结果
+func (p *printer) render(expression ast.Expression) string {
+	sub := &printer{source: p.source, comments: p.comments, next: p.next, depth: p.depth, lastRow: p.lastRow}
+	sub.expression(expression)
+	p.next = sub.next
+	p.lastRow = sub.lastRow
+	return sub.sb.String()
+}
+
+// needsSpace 判断前缀运算符与操作数之间是否需要空格，以免相邻的符号被合并为其他运算符
+//
+// 参数:
+//
+//	operator - 前缀运算符
+//	operand - 格式化后的操作数
+//
+// 返回值:
+//
+//	bool - 是否需要空格
+func needsSpace(operator, operand string) bool {
+	return (operator == "-" || operator == "+") && (strings.HasPrefix(operand, "-") || strings.HasPrefix(operand, "+"))
+}
+
+// statementPos 返回语句的起止位置
+//
+// 参数:
+//
+//	statement - 语句节点
+//
+// 返回值:
+//
+//	*util.Pos - 语句的起始位置
+//	*util.Pos - 语句的结束位置
+func statementPos(statement ast.Statement) (*util.Pos, *util.Pos) {
+	switch s := statement.(type) {
+	case *ast.ExpressionStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.ForStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.ForInStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.WhileStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.FunctionDeclarationStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.ReturnStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.BreakStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.ContinueStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.TryStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.ThrowStatement:
+		return s.PosStart, s.PosEnd
+	case *ast.ImportStatement:
+		return s.PosStart, s.PosEnd
+	default:
+		panic("formatter: unknown statement type")
+	}
+}
@@ -0,0 +1,189 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{
+			name:     "Empty Input",
+			input:    "",
+			excepted: "",
+		},
+		{
+			name:     "Spacing",
+			input:    "var x=1+2*3;const   y = -x ;",
+			excepted: "var x = 1 + 2 * 3;\nconst y = -x;\n",
+		},
+		{
+			name:     "Literals Keep Source Form",
+			input:    "var a = 'single'; var b = `raw\\n`; var c = 1.; var d = [1,2.50,\"x\"];",
+			excepted: "var a = 'single';\nvar b = `raw\\n`;\nvar c = 1.;\nvar d = [1, 2.50, \"x\"];\n",
+		},
+		{
+			name:     "Nested Blocks",
+			input:    "func f(a,b=2){if a>b {return a} else {return b}};",
+			excepted: "func f(a, b=2) {\n    if a > b {\n        return a;\n    } else {\n        return b;\n    };\n};\n",
+		},
+		{
+			name:     "For Statement",
+			input:    "for var i=0;i<3;i++ {println(i);};",
+			excepted: "for var i = 0; i < 3; i++ {\n    println(i);\n};\n",
+		},
+		{
+			name:     "While Statement",
+			input:    "while i<3 {i+=1;};",
+			excepted: "while i < 3 {\n    i += 1;\n};\n",
+		},
+		{
+			name:     "Break Statement",
+			input:    "while true {if done {break};};",
+			excepted: "while true {\n    if done {\n        break;\n    };\n};\n",
+		},
+		{
+			name:     "Continue Statement",
+			input:    "for var i=0;i<3;i++ {if i==1 {continue};};",
+			excepted: "for var i = 0; i < 3; i++ {\n    if i == 1 {\n        continue;\n    };\n};\n",
+		},
+		{
+			name:     "Map Literals",
+			input:    "var m={ \"a\" :1,2:[3],};var e={ : };",
+			excepted: "var m = {\"a\": 1, 2: [3]};\nvar e = {:};\n",
+		},
+		{
+			name:     "Ternary Expression",
+			input:    "var s=a>0?\"pos\":a<0?\"neg\":\"zero\";",
+			excepted: "var s = a > 0 ? \"pos\" : a < 0 ? \"neg\" : \"zero\";\n",
+		},
+		{
+			name:     "Function Expression",
+			input:    "var f=func(a,b=1){return a+b;};\nvar g=func(x)x*2;",
+			excepted: "var f = func(a, b=1) {\n    return a + b;\n};\nvar g = func(x) x * 2;\n",
+		},
+		{
+			name:     "For In Statement",
+			input:    "for x in [1,2]{println(x);};for ch in s println(ch);",
+			excepted: "for x in [1, 2] {\n    println(x);\n};\nfor ch in s println(ch);\n",
+		},
+		{
+			name:     "For In Statement Over Map",
+			input:    "for var k,v in m{println(k,v);};",
+			excepted: "for k, v in m {\n    println(k, v);\n};\n",
+		},
+		{
+			name:     "Try Statement",
+			input:    "try{f();}catch(e){println(e[\"message\"]);};try g()catch (err) throw err;",
+			excepted: "try {\n    f();\n} catch (e) {\n    println(e[\"message\"]);\n};\ntry g() catch (err) throw err;\n",
+		},
+		{
+			name:     "Slice Expression",
+			input:    "var a=l[1:n-1];var b=l[:2];var c=s[-1:];var d=l[:];",
+			excepted: "var a = l[1:n - 1];\nvar b = l[:2];\nvar c = s[-1:];\nvar d = l[:];\n",
+		},
+		{
+			name:     "Interpolated String",
+			input:    "var s=\"a\\t${ x+1 }b${f(\"${y}\")}\";",
+			excepted: "var s = \"a\\t${x + 1}b${f(\"${y}\")}\";\n",
+		},
+		{
+			name:     "Empty Block",
+			input:    "{  };\nif x {  };",
+			excepted: "{};\nif x {};\n",
+		},
+		{
+			name:     "Empty Braces In Expression",
+			input:    "var x = {  };",
+			excepted: "var x = {:};\n",
+		},
+		{
+			name:     "Call With Default Arguments",
+			input:    "add(1,,x[0]);",
+			excepted: "add(1, , x[0]);\n",
+		},
+		{
+			name:     "Prefix Operators Stay Separate",
+			input:    "var x = - -1; var y = -(-1); var z = !true;",
+			excepted: "var x = - -1;\nvar y = -(-1);\nvar z = !true;\n",
+		},
+		{
+			name:     "Compound Assignment And Inc Dec",
+			input:    "x+=1;++x;x--;",
+			excepted: "x += 1;\n++x;\nx--;\n",
+		},
+		{
+			name:     "Comments",
+			input:    "// header\nvar x = 1; // trailing\n\n\n/* block */\nfunc f() {\n// inside\nreturn x;\n// end\n};\n// tail\n",
+			excepted: "// header\nvar x = 1; // trailing\n\n/* block */\nfunc f() {\n    // inside\n    return x;\n    // end\n};\n// tail\n",
+		},
+		{
+			name:     "Comment In Empty Block",
+			input:    "func f() { /* todo */ };",
+			excepted: "func f() {\n    /* todo */\n};\n",
+		},
+		{
+			name:     "Import Statement",
+			input:    "import   'lib/a.gh' ;",
+			excepted: "import 'lib/a.gh';\n",
+		},
+		{
+			name:     "Blank Lines Collapsed",
+			input:    "var x = 1;\n\n\n\nvar y = 2;",
+			excepted: "var x = 1;\n\nvar y = 2;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format("<test>", tt.input)
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if got != tt.excepted {
+				t.Errorf("excepted:\n%s\ngot:\n%s", tt.excepted, got)
+			}
+			// 格式化结果应当稳定
+			again, err := Format("<test>", got)
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if again != got {
+				t.Errorf("format is not idempotent:\n%s", again)
+			}
+			// 格式化前后的语法树应当一致
+			program, _, _ := parse("<test>", tt.input)
+			formattedProgram, _, _ := parse("<test>", got)
+			if structure(program) != structure(formattedProgram) {
+				t.Errorf("format changed the program structure")
+			}
+		})
+	}
+}
+
+func TestFormatter_FormatErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Syntax Error",
+			input: "var x = (1 + 2;",
+		},
+		{
+			name:  "Illegal Token",
+			input: "var x = @;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Format("<test>", tt.input); err == nil {
+				t.Errorf("err = nil, expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/benchprog"
+)
+
+func BenchmarkLexUnicodeHeavy(b *testing.B) {
+	source := strings.Repeat(benchprog.Get(b, "unicode").Source, 100)
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	for range b.N {
+		l := NewLexer("unicode.gh", source)
+		for {
+			tok, err := l.NextToken()
+			if err != nil {
+				b.Fatalf("err = %+v, expected nil", err)
+			}
+			if tok.Type == EOF {
+				break
+			}
+			l.NextChar()
+		}
+	}
+}
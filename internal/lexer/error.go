@@ -5,6 +5,7 @@ package lexer
 import (
 	"strconv"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
 
@@ -12,6 +13,7 @@ import (
 // 实现 error 接口
 
 type IllegalTokenError struct {
+	Code     string    // 错误代码，见errcode包
 	Message  string    // 错误描述文本
 	PosStart *util.Pos // 错误起始位置
 	PosEnd   *util.Pos // 错误结束位置
@@ -30,8 +32,8 @@ func (e *IllegalTokenError) Error() string {
 	} else {
 		linePos = "lines " + strconv.Itoa(e.PosStart.Row) + "-" + strconv.Itoa(e.PosEnd.Row)
 	}
-	result := "File " + e.PosStart.File + ", " + linePos + "\n"
-	result += util.StringsWithArrows(e.PosStart.Text, e.PosStart, e.PosEnd, false)
+	result := "File " + e.PosStart.File() + ", " + linePos + "\n"
+	result += util.StringsWithArrows(e.PosStart.Text(), e.PosStart, e.PosEnd, false)
 	result += "\nIllegal Token Error"
 	if e.Message != "" {
 		result += ": " + e.Message
@@ -39,10 +41,57 @@ func (e *IllegalTokenError) Error() string {
 	return result
 }
 
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Illegal Token Error"
+func (e *IllegalTokenError) ErrorKind() string {
+	return "Illegal Token Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *IllegalTokenError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *IllegalTokenError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *IllegalTokenError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 词法错误没有调用栈，总是为nil
+func (e *IllegalTokenError) Frames() *frame.Frame {
+	return nil
+}
+
 // SyntaxError 语法错误，表示遇到非法语法
 // 实现 error 接口
 
 type SyntaxError struct {
+	Code     string    // 错误代码，见errcode包
 	Message  string    // 错误描述文本
 	PosStart *util.Pos // 错误起始位置
 	PosEnd   *util.Pos // 错误结束位置
@@ -61,11 +110,57 @@ func (e *SyntaxError) Error() string {
 	} else {
 		linePos = "lines " + strconv.Itoa(e.PosStart.Row) + "-" + strconv.Itoa(e.PosEnd.Row)
 	}
-	result := "File " + e.PosStart.File + ", " + linePos + "\n"
-	result += util.StringsWithArrows(e.PosStart.Text, e.PosStart, e.PosEnd, false)
+	result := "File " + e.PosStart.File() + ", " + linePos + "\n"
+	result += util.StringsWithArrows(e.PosStart.Text(), e.PosStart, e.PosEnd, false)
 	result += "\nSyntax Error"
 	if e.Message != "" {
 		result += ": " + e.Message
 	}
 	return result
 }
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Syntax Error"
+func (e *SyntaxError) ErrorKind() string {
+	return "Syntax Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *SyntaxError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *SyntaxError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *SyntaxError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 语法错误没有调用栈，总是为nil
+func (e *SyntaxError) Frames() *frame.Frame {
+	return nil
+}
@@ -0,0 +1,58 @@
+package lexer
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// FuzzLexer 检查任意输入下词法分析器不会panic、总能读到EOF，且标记和错误都带有位置
+// 种子语料位于testdata/fuzz/FuzzLexer
+func FuzzLexer(f *testing.F) {
+	f.Add("var x = 1;")
+	f.Fuzz(func(t *testing.T, input string) {
+		l := NewLexer("<fuzz>", input)
+		// 除EOF外每个标记至少占一个字节，出错之后继续读取同样如此
+		for i := 0; ; i++ {
+			if i > len(input)+1 {
+				t.Fatalf("lexer did not reach EOF after %d tokens on %q", i, input)
+			}
+			tok, err := l.NextToken()
+			if tok.PosStart == nil || tok.PosEnd == nil {
+				t.Fatalf("token %+v without position on %q", tok, input)
+			}
+			if tok.PosStart.Idx < 0 || tok.PosStart.Idx > tok.PosEnd.Idx {
+				t.Fatalf("token %+v has invalid span [%d, %d) on %q", tok, tok.PosStart.Idx, tok.PosEnd.Idx, input)
+			}
+			if err != nil {
+				checkError(t, err, input)
+			} else if tok.Type == IDENT && !utf8.ValidString(tok.Literal) {
+				t.Fatalf("identifier %q is not valid UTF-8", tok.Literal)
+			}
+			if tok.Type == EOF {
+				break
+			}
+			l.NextChar()
+		}
+	})
+}
+
+// checkError 检查词法错误带有位置，且可以渲染出源代码摘录
+//
+// 参数:
+//
+//	t - 测试
+//	err - 词法错误
+//	input - 源代码
+func checkError(t *testing.T, err error, input string) {
+	t.Helper()
+	positioned, ok := err.(interface{ Pos() (*util.Pos, *util.Pos) })
+	if !ok {
+		t.Fatalf("error %T has no position on %q", err, input)
+	}
+	if posStart, posEnd := positioned.Pos(); posStart == nil || posEnd == nil {
+		t.Fatalf("error %q has nil position on %q", err, input)
+	}
+	_ = err.Error()
+}
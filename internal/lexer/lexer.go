@@ -6,16 +6,29 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
 
 // Lexer 词法分析器结构体，维护词法分析过程中的状态信息
 // 负责读取源代码字符并生成对应的标记(token)
 type Lexer struct {
-	File    string    // 当前处理的文件名
-	Input   string    // 待分析的源代码字符串
-	CurrPos *util.Pos // 当前字符的位置信息
-	NextPos *util.Pos // 下一个字符的位置信息
+	File     string           // 当前处理的文件名
+	Input    string           // 待分析的源代码字符串
+	Source   *util.SourceFile // 源代码文件，所有标记的位置共享
+	CurrPos  util.Pos         // 当前字符的位置信息
+	NextPos  util.Pos         // 下一个字符的位置信息
+	Comments []*Comment       // 已跳过的注释，按出现顺序排列
+	eofPos   *util.Pos        // 源代码结尾的位置，到达结尾之后的EOF标记都位于此处
+	interps  []interpolation  // 正在分析的字符串插值，最后一个为最内层
+}
+
+// interpolation 一个正在分析其中表达式的字符串插值
+
+type interpolation struct {
+	braces   int       // 插值表达式中尚未闭合的花括号数
+	posStart *util.Pos // 字符串的起始位置，用于报告未闭合的字符串
+	posOpen  *util.Pos // 当前"${"的位置，用于报告未闭合的插值
 }
 
 // NewLexer 创建一个新的词法分析器实例
@@ -29,27 +42,41 @@ type Lexer struct {
 //
 //	初始化后的Lexer指针
 func NewLexer(file string, input string) *Lexer {
-	l := &Lexer{
+	l := &Lexer{}
+	l.Reset(file, input)
+	return l
+}
+
+// Reset 让词法分析器从头分析新的源代码，之前的状态和收集的注释全部丢弃
+// 用于REPL等需要反复分析输入的场景，避免每次创建新的实例
+//
+// 参数:
+//
+//	file - 源代码文件名，用于错误报告
+//	input - 要分析的源代码字符串
+func (l *Lexer) Reset(file string, input string) {
+	source := util.NewSourceFile(file, input)
+	*l = Lexer{
 		File:    file,
 		Input:   input,
-		CurrPos: util.NewPos(1, 0, -1, file, input),
-		NextPos: util.NewPos(1, 1, 0, file, input),
+		Source:  source,
+		CurrPos: *util.NewPos(1, 0, -1, source),
+		NextPos: *util.NewPos(1, 1, 0, source),
 	}
 	l.NextChar() // 初始化时移动到第一个字符
-	return l
 }
 
 // NextChar 移动到下一个字符位置
-// 更新CurrPos和NextPos指针，实现字符流的顺序读取
+// 更新CurrPos和NextPos，实现字符流的顺序读取
 func (l *Lexer) NextChar() {
-	l.CurrPos = l.NextPos.Copy()
+	l.CurrPos = l.NextPos
 	l.NextPos.Advance()
 }
 
 // Backup 回退一个字符位置
 // 在读取到不需要的字符时使用，将位置指针向后移动一位
 func (l *Lexer) Backup() {
-	l.NextPos = l.CurrPos.Copy()
+	l.NextPos = l.CurrPos
 	l.CurrPos.Backup()
 }
 
@@ -58,42 +85,64 @@ func (l *Lexer) Backup() {
 //
 // 返回值:
 //
-//	解析出的Token实例和可能的静态错误
-func (l *Lexer) NextToken() (*Token, error) {
+//	解析出的Token和可能的静态错误，Token按值返回，不需要为每个标记分配内存
+func (l *Lexer) NextToken() (Token, error) {
 	for {
 		// 根据当前字符类型进行不同处理
 		switch l.CurrPos.Char {
 		case 0:
-			// 到达文件末尾，返回EOF标记
-			return &Token{Type: EOF, Literal: "EOF", PosStart: l.CurrPos.Copy(), PosEnd: l.NextPos.Copy()}, nil
+			// NUL字节和非法的UTF-8编码同样读取为0，不能当作文件末尾而忽略之后的源代码
+			if l.CurrPos.Idx < len(l.Input) {
+				return l.illegalToken(fmt.Sprintf("illegal byte 0x%02x.", l.Input[l.CurrPos.Idx]))
+			}
+			// 到达文件末尾，返回不占宽度的EOF标记，之后继续读取时位置不再前进
+			if l.eofPos == nil {
+				l.eofPos = l.CurrPos.Copy()
+			}
+			if len(l.interps) > 0 {
+				return l.unterminatedInterpolation()
+			}
+			return Token{Type: EOF, Literal: "EOF", PosStart: l.eofPos, PosEnd: l.eofPos}, nil
 		case ' ', '\t', '\r', '\n':
 			// 跳过空白字符（空格、制表符、回车、换行）
 			l.eatWhitespace()
 		default:
 			// 处理数字字面量（整数或浮点数）
 			if isNumber(l.CurrPos.Char) {
-				posStart := l.CurrPos.Copy()
+				posStart := l.CurrPos
 				num, err := l.scanNumber()
 				// 单独的点号作为DOT标记处理
 				if num == "." {
-					return &Token{Type: DOT, Literal: ".", PosStart: posStart, PosEnd: l.NextPos.Copy()}, nil
+					return l.token(DOT, ".", posStart), nil
 				}
 				if err != nil {
-					return &Token{Type: ILLEGAL, Literal: "ILLEGAL", PosStart: posStart, PosEnd: l.NextPos.Copy()}, err
+					return l.token(ILLEGAL, "ILLEGAL", posStart), err
 				}
 				// 根据是否包含小数点判断是整数还是浮点数
 				if strings.Contains(num, ".") {
-					return &Token{Type: FLOAT, Literal: num, PosStart: posStart, PosEnd: l.NextPos.Copy()}, nil
+					return l.token(FLOAT, num, posStart), nil
 				}
-				return &Token{Type: INT, Literal: num, PosStart: posStart, PosEnd: l.NextPos.Copy()}, nil
+				return l.token(INT, num, posStart), nil
 				// 处理标识符或关键字（变量名、函数名等）
 			} else if isLetter(l.CurrPos.Char) {
-				posStart := l.CurrPos.Copy()
+				posStart := l.CurrPos
 				id := l.scanIdentifier()
-				return &Token{Type: LookupIdent(id), Literal: id, PosStart: posStart, PosEnd: l.NextPos.Copy()}, nil
+				return l.token(LookupIdent(id), id, posStart), nil
 				// 处理运算符
 			} else if isOperator(l.CurrPos.Char) {
-				posStart := l.CurrPos.Copy()
+				posStart := l.CurrPos
+				// 字符串插值中与"${"匹配的"}"之后继续扫描字符串
+				if n := len(l.interps); n > 0 {
+					switch l.CurrPos.Char {
+					case '{':
+						l.interps[n-1].braces++
+					case '}':
+						if l.interps[n-1].braces == 0 {
+							return l.scanInterpolationRest(posStart)
+						}
+						l.interps[n-1].braces--
+					}
+				}
 				// 如果是'/'
 				if l.CurrPos.Char == '/' {
 					// 如果下一个字符是'/'，说明是单行注释
@@ -104,34 +153,93 @@ func (l *Lexer) NextToken() (*Token, error) {
 					} else if l.NextPos.Char == '*' {
 						err := l.skipMultilineComment()
 						if err != nil {
-							return &Token{Type: ILLEGAL, Literal: "ILLEGAL", PosStart: posStart, PosEnd: l.NextPos.Copy()}, err
+							return l.token(ILLEGAL, "ILLEGAL", posStart), err
 						}
 						continue
 					}
 				}
 				op := l.scanOperator()
-				return &Token{Type: Operators[op], Literal: op, PosStart: posStart, PosEnd: l.NextPos.Copy()}, nil
+				return l.token(Operators[op], op, posStart), nil
 				// 处理字符串字面量（支持单引号、双引号和反引号）
 			} else if l.CurrPos.Char == '"' || l.CurrPos.Char == '\'' || l.CurrPos.Char == '`' {
-				posStart := l.CurrPos.Copy()
-				str, err := l.scanString()
+				posStart := l.CurrPos
+				str, posOpen, err := l.scanString()
 				if err != nil {
-					return &Token{Type: ILLEGAL, Literal: "ILLEGAL", PosStart: posStart, PosEnd: l.NextPos.Copy()}, err
+					// 插值表达式中未闭合的字符串通常是缺少"}"，其引号实际是外层字符串的结束引号
+					if illegal, ok := err.(*IllegalTokenError); ok && illegal.Code == errcode.UnterminatedString && len(l.interps) > 0 {
+						return l.unterminatedInterpolation()
+					}
+					return l.token(ILLEGAL, "ILLEGAL", posStart), err
 				}
-				return &Token{Type: STRING, Literal: str, PosStart: posStart, PosEnd: l.NextPos.Copy()}, nil
+				if posOpen != nil {
+					l.interps = append(l.interps, interpolation{posStart: posStart.Copy(), posOpen: posOpen})
+					return l.token(INTERP_START, str, posStart), nil
+				}
+				return l.token(STRING, str, posStart), nil
 				// 非法字符处理
 			} else {
-				return &Token{Type: ILLEGAL, Literal: "ILLEGAL"}, &IllegalTokenError{
-					Message:  fmt.Sprintf("illegal token \"%c\".", l.CurrPos.Char),
-					PosStart: l.CurrPos.Copy(),
-					PosEnd:   l.NextPos.Copy(),
-				}
+				return l.illegalToken(fmt.Sprintf("illegal token \"%c\".", l.CurrPos.Char))
 			}
 		}
 		l.NextChar()
 	}
 }
 
+// illegalToken 创建当前字符处的非法标记和非法字符错误
+//
+// 参数:
+//
+//	message - 错误描述
+//
+// 返回值:
+//
+//	Token - 位于当前字符处的ILLEGAL标记
+//	error - 非法字符错误
+func (l *Lexer) illegalToken(message string) (Token, error) {
+	tok := l.token(ILLEGAL, "ILLEGAL", l.CurrPos)
+	return tok, &IllegalTokenError{
+		Code:     errcode.IllegalToken,
+		Message:  message,
+		PosStart: tok.PosStart,
+		PosEnd:   tok.PosEnd,
+	}
+}
+
+// unterminatedInterpolation 在到达文件末尾时创建最内层未闭合的插值处的非法标记和错误
+// 错误从"${"开始，之后不再视为处于插值中，继续读取时返回EOF标记
+//
+// 返回值:
+//
+//	Token - 非法标记
+//	error - 未闭合插值的错误
+func (l *Lexer) unterminatedInterpolation() (Token, error) {
+	tok := l.token(ILLEGAL, "ILLEGAL", *l.interps[len(l.interps)-1].posOpen)
+	l.interps = l.interps[:0]
+	return tok, &IllegalTokenError{
+		Code:     errcode.UnterminatedInterpolation,
+		Message:  `unterminated interpolation "${".`,
+		PosStart: tok.PosStart,
+		PosEnd:   tok.PosEnd,
+	}
+}
+
+// token 创建从起始位置到当前字符之后的标记
+// 起止位置在同一次内存分配中创建，标记创建之后位置不再修改，语法树节点可以直接引用
+//
+// 参数:
+//
+//	tokenType - 标记类型
+//	literal - 标记的字面值
+//	posStart - 标记的起始位置
+//
+// 返回值:
+//
+//	Token - 新的标记
+func (l *Lexer) token(tokenType TokenType, literal string, posStart util.Pos) Token {
+	span := &[2]util.Pos{posStart, l.NextPos}
+	return Token{Type: tokenType, Literal: literal, PosStart: &span[0], PosEnd: &span[1]}
+}
+
 // isNumber 判断字符是否为数字(0-9)
 //
 // 参数:
@@ -185,16 +293,34 @@ func (l *Lexer) eatWhitespace() {
 // skipComment 跳过单行注释
 // 从当前'/'字符开始，直到行尾
 func (l *Lexer) skipComment() {
+	posStart := l.CurrPos.Copy()
 	l.NextChar()
 	l.NextChar()
-	for l.CurrPos.Char != '\n' && l.CurrPos.Char != 0 {
+	// 注释中可以出现任意字节，包括NUL字节和非法的UTF-8编码
+	for l.CurrPos.Char != '\n' && l.CurrPos.Idx < len(l.Input) {
 		l.NextChar()
 	}
+	l.recordComment(posStart, l.CurrPos.Copy())
 	if l.CurrPos.Char == '\n' {
 		l.NextChar()
 	}
 }
 
+// recordComment 记录一条已跳过的注释
+//
+// 参数:
+//
+//	posStart - 注释的起始位置
+//	posEnd - 注释的结束位置(不包含)
+func (l *Lexer) recordComment(posStart, posEnd *util.Pos) {
+	end := min(posEnd.Idx, len(l.Input))
+	l.Comments = append(l.Comments, &Comment{
+		Text:     l.Input[posStart.Idx:end],
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	})
+}
+
 // skipMultilineComment 跳过多行注释
 // 从当前'/*'字符开始，直到找到闭合的'*/'
 //
@@ -202,11 +328,12 @@ func (l *Lexer) skipComment() {
 //
 //	如果注释未正确闭合则返回语法错误
 func (l *Lexer) skipMultilineComment() error {
+	posStart := l.CurrPos.Copy()
 	// 跳过起始的/*
 	l.NextChar()
 	l.NextChar()
 	// 寻找结束的*/
-	for (l.CurrPos.Char != '*' || l.NextPos.Char != '/') && l.CurrPos.Char != 0 {
+	for (l.CurrPos.Char != '*' || l.NextPos.Char != '/') && l.CurrPos.Idx < len(l.Input) {
 		l.NextChar()
 	}
 	// 如果没有找到结束的*/，返回错误
@@ -214,6 +341,7 @@ func (l *Lexer) skipMultilineComment() error {
 		posEnd := l.NextPos.Copy()
 		posEnd.Advance()
 		return &SyntaxError{
+			Code:     errcode.UnterminatedComment,
 			Message:  "\"*/\" is expected.",
 			PosStart: l.CurrPos.Copy(),
 			PosEnd:   posEnd,
@@ -221,6 +349,7 @@ func (l *Lexer) skipMultilineComment() error {
 	}
 	l.NextChar()
 	l.NextChar()
+	l.recordComment(posStart, l.CurrPos.Copy())
 	return nil
 }
 
@@ -231,7 +360,7 @@ func (l *Lexer) skipMultilineComment() error {
 //
 //	解析出的数字字符串和可能的错误
 func (l *Lexer) scanNumber() (string, error) {
-	var num string
+	start := l.CurrPos.Idx
 	var dotCount int // 小数点计数器，用于检查是否有多个小数点
 	// 扫描数字字符和小数点
 	for isNumber(l.CurrPos.Char) || l.CurrPos.Char == '.' {
@@ -240,15 +369,17 @@ func (l *Lexer) scanNumber() (string, error) {
 			// 检查是否有多个小数点，浮点数只能有一个小数点
 			if dotCount > 1 {
 				return "", &IllegalTokenError{
+					Code:     errcode.IllegalFloatLiteral,
 					Message:  "illegal float literal.",
 					PosStart: l.CurrPos.Copy(),
 					PosEnd:   l.NextPos.Copy(),
 				}
 			}
 		}
-		num += string(l.CurrPos.Char)
 		l.NextChar()
 	}
+	// 数字只包含ASCII字符，字面量直接取源代码的子串
+	num := l.Input[start:l.CurrPos.Idx]
 	l.Backup()
 	return num, nil
 }
@@ -260,17 +391,18 @@ func (l *Lexer) scanNumber() (string, error) {
 //
 //	解析出的标识符字符串
 func (l *Lexer) scanIdentifier() string {
-	var runes []rune
+	start := l.CurrPos.Idx
 	for {
-		runes = append(runes, l.CurrPos.Char)
 		l.NextChar()
 		// 标识符由字母、数字和下划线组成
 		if !isLetter(l.CurrPos.Char) && !isNumber(l.CurrPos.Char) {
 			break
 		}
 	}
+	// 非法的UTF-8编码读取为0，不会出现在标识符中，字面量直接取源代码的子串
+	id := l.Input[start:l.CurrPos.Idx]
 	l.Backup()
-	return string(runes)
+	return id
 }
 
 // scanOperator 扫描运算符
@@ -280,45 +412,104 @@ func (l *Lexer) scanIdentifier() string {
 //
 //	解析出的运算符字符串
 func (l *Lexer) scanOperator() string {
-	var op string
+	// 运算符只包含ASCII字符，以源代码的子串表示
+	start, end := l.CurrPos.Idx, l.CurrPos.Idx
 	for {
-		op += string(l.CurrPos.Char)
+		op := l.Input[start:l.NextPos.Idx]
 		l.NextChar()
-		// 检查当前运算符是否有效
+		// 检查加上当前字符的运算符是否有效
 		if _, ok := Operators[op]; !ok {
-			op = op[:len(op)-1] // 回退最后一个字符
-			l.Backup()
+			l.Backup() // 回退最后一个字符
 			break
 		}
+		end = l.CurrPos.Idx
 		// 如果下一个字符不是运算符，停止扫描
 		if !isOperator(l.CurrPos.Char) {
 			break
 		}
 	}
 	l.Backup()
-	return op
+	return l.Input[start:end]
 }
 
 // scanString 扫描字符串字面量
-// 支持单引号、双引号和反引号字符串，以及转义字符
+// 支持单引号、双引号和反引号字符串，以及转义字符；双引号字符串遇到"${"时停止，之后是插值表达式
 //
 // 返回值:
 //
-//	解析出的字符串内容和可能的错误
-func (l *Lexer) scanString() (string, error) {
+//	解析出的字符串内容，遇到"${"时为其之前的内容
+//	"${"的位置，遇到结束引号时为nil
+//	可能的错误
+func (l *Lexer) scanString() (string, *util.Pos, error) {
 	posStart := l.CurrPos.Copy()
-	var runes []rune
 	quote := l.CurrPos.Char // 记录字符串开始的引号类型
 	l.NextChar()
+	return l.scanStringContent(quote, posStart)
+}
+
+// scanInterpolationRest 扫描字符串插值的"}"之后的字符串内容，直到下一个"${"或结束引号
+//
+// 参数:
+//
+//	posStart - "}"的位置
+//
+// 返回值:
+//
+//	Token - 遇到"${"时为INTERP_MID标记，遇到结束引号时为INTERP_END标记
+//	error - 可能的错误
+func (l *Lexer) scanInterpolationRest(posStart util.Pos) (Token, error) {
+	n := len(l.interps)
+	l.NextChar()
+	str, posOpen, err := l.scanStringContent('"', l.interps[n-1].posStart)
+	if err != nil {
+		return l.token(ILLEGAL, "ILLEGAL", posStart), err
+	}
+	if posOpen != nil {
+		l.interps[n-1].posOpen = posOpen
+		return l.token(INTERP_MID, str, posStart), nil
+	}
+	l.interps = l.interps[:n-1]
+	return l.token(INTERP_END, str, posStart), nil
+}
+
+// scanStringContent 从当前字符开始扫描字符串内容，直到结束引号，双引号字符串还会在"${"处停止
+// 停止时当前字符为结束引号或"${"中的"{"
+//
+// 参数:
+//
+//	quote - 字符串的引号
+//	posStart - 字符串的起始位置，用于报告未闭合的字符串
+//
+// 返回值:
+//
+//	解析出的字符串内容
+//	"${"的位置，遇到结束引号时为nil
+//	可能的错误
+func (l *Lexer) scanStringContent(quote rune, posStart *util.Pos) (string, *util.Pos, error) {
+	// 没有转义字符时字面量就是源代码的子串，遇到第一个转义字符后才逐个写入内容
+	var sb strings.Builder
+	escaped := false
+	start := l.CurrPos.Idx
 	// 扫描直到找到匹配的结束引号
 	for l.CurrPos.Char != quote && l.CurrPos.Char != 0 {
+		// 双引号字符串中的插值
+		if l.CurrPos.Char == '$' && l.NextPos.Char == '{' && quote == '"' {
+			str := l.Input[start:l.CurrPos.Idx]
+			if escaped {
+				str = sb.String()
+			}
+			posOpen := l.CurrPos.Copy()
+			l.NextChar()
+			return str, posOpen, nil
+		}
 		// 处理转义字符(仅在非反引号字符串中支持)
 		if l.CurrPos.Char == '\\' && quote != '`' {
 			slashPos := l.CurrPos.Copy()
 			l.NextChar()
 			// 检查转义字符后的字符是否存在
-			if l.CurrPos.Char == 0 {
-				return "", &IllegalTokenError{
+			if l.CurrPos.Idx >= len(l.Input) {
+				return "", nil, &IllegalTokenError{
+					Code:     errcode.TrailingBackslash,
 					Message:  "trailing backslash.",
 					PosStart: slashPos,
 					PosEnd:   l.NextPos.Copy(),
@@ -327,25 +518,43 @@ func (l *Lexer) scanString() (string, error) {
 			// 查找有效的转义字符
 			escapeChar, ok := Escape[l.CurrPos.Char]
 			if !ok {
-				return "", &IllegalTokenError{
+				return "", nil, &IllegalTokenError{
+					Code:     errcode.IllegalEscape,
 					Message:  "illegal escape character.",
 					PosStart: slashPos,
 					PosEnd:   l.NextPos.Copy(),
 				}
 			}
-			runes = append(runes, escapeChar)
-		} else {
-			runes = append(runes, l.CurrPos.Char)
+			if !escaped {
+				escaped = true
+				sb.WriteString(l.Input[start:slashPos.Idx])
+			}
+			sb.WriteRune(escapeChar)
+		} else if escaped {
+			sb.WriteRune(l.CurrPos.Char)
 		}
 		l.NextChar()
 	}
+	// 字符串中的NUL字节和非法的UTF-8编码
+	if l.CurrPos.Char == 0 && l.CurrPos.Idx < len(l.Input) {
+		return "", nil, &IllegalTokenError{
+			Code:     errcode.IllegalToken,
+			Message:  fmt.Sprintf("illegal byte 0x%02x in string literal.", l.Input[l.CurrPos.Idx]),
+			PosStart: l.CurrPos.Copy(),
+			PosEnd:   l.NextPos.Copy(),
+		}
+	}
 	// 检查字符串是否正确闭合
 	if l.CurrPos.Char != quote {
-		return "", &IllegalTokenError{
+		return "", nil, &IllegalTokenError{
+			Code:     errcode.UnterminatedString,
 			Message:  "unterminated string literal.",
 			PosStart: posStart,
 			PosEnd:   l.NextPos.Copy(),
 		}
 	}
-	return string(runes), nil
+	if escaped {
+		return sb.String(), nil, nil
+	}
+	return l.Input[start:l.CurrPos.Idx], nil, nil
 }
@@ -1,9 +1,11 @@
 package lexer
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
 
@@ -11,36 +13,36 @@ func TestLexer_Comments(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  string
-		expect *Token
+		expect Token
 	}{
 		{
 			name:  "Empty Input",
 			input: "",
-			expect: &Token{
+			expect: Token{
 				Type:     EOF,
 				Literal:  "EOF",
-				PosStart: util.NewPos(1, 1, 0, "<test>", ""),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", ""),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "")),
+				PosEnd:   util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "")),
 			},
 		},
 		{
 			name:  "Single Line Comment",
 			input: "// This is a comment\n5",
-			expect: &Token{
+			expect: Token{
 				Type:     INT,
 				Literal:  "5",
-				PosStart: util.NewPos(2, 1, 21, "<test>", "// This is a comment\n5"),
-				PosEnd:   util.NewPos(2, 2, 22, "<test>", "// This is a comment\n5"),
+				PosStart: util.NewPos(2, 1, 21, util.NewSourceFile("<test>", "// This is a comment\n5")),
+				PosEnd:   util.NewPos(2, 2, 22, util.NewSourceFile("<test>", "// This is a comment\n5")),
 			},
 		},
 		{
 			name:  "Multi-Line Comment",
 			input: "/* This is a multiline comment */",
-			expect: &Token{
+			expect: Token{
 				Type:     EOF,
 				Literal:  "EOF",
-				PosStart: util.NewPos(1, 34, 33, "<test>", "/* This is a multiline comment */"),
-				PosEnd:   util.NewPos(1, 35, 34, "<test>", "/* This is a multiline comment */"),
+				PosStart: util.NewPos(1, 34, 33, util.NewSourceFile("<test>", "/* This is a multiline comment */")),
+				PosEnd:   util.NewPos(1, 34, 33, util.NewSourceFile("<test>", "/* This is a multiline comment */")),
 			},
 		},
 	}
@@ -63,36 +65,36 @@ func TestLexer_Numbers(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  string
-		expect *Token
+		expect Token
 	}{
 		{
 			name:  "Single Digit Int",
 			input: "5",
-			expect: &Token{
+			expect: Token{
 				Type:     INT,
 				Literal:  "5",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "5"),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", "5"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "5")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "5")),
 			},
 		},
 		{
 			name:  "Multi-Digit Int",
 			input: "123",
-			expect: &Token{
+			expect: Token{
 				Type:     INT,
 				Literal:  "123",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "123"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "123"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "123")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "123")),
 			},
 		},
 		{
 			name:  "Float",
 			input: "12.34",
-			expect: &Token{
+			expect: Token{
 				Type:     FLOAT,
 				Literal:  "12.34",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "12.34"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "12.34"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "12.34")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "12.34")),
 			},
 		},
 	}
@@ -115,36 +117,86 @@ func TestLexer_Identifiers(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  string
-		expect *Token
+		expect Token
 	}{
 		{
 			name:  "Single Character Identifier",
 			input: "a",
-			expect: &Token{
+			expect: Token{
 				Type:     IDENT,
 				Literal:  "a",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "a"),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", "a"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "a")),
 			},
 		},
 		{
 			name:  "Multi-Character Identifier",
 			input: "abc123",
-			expect: &Token{
+			expect: Token{
 				Type:     IDENT,
 				Literal:  "abc123",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "abc123"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "abc123"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "abc123")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "abc123")),
 			},
 		},
 		{
 			name:  "Chinese Characters Identifier",
 			input: "你好世界",
-			expect: &Token{
+			expect: Token{
 				Type:     IDENT,
 				Literal:  "你好世界",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "你好世界"),
-				PosEnd:   util.NewPos(1, 5, 12, "<test>", "你好世界"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "你好世界")),
+				PosEnd:   util.NewPos(1, 5, 12, util.NewSourceFile("<test>", "你好世界")),
+			},
+		},
+		{
+			name:  "Keyword In",
+			input: "in",
+			expect: Token{
+				Type:     IN,
+				Literal:  "in",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "in")),
+				PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "in")),
+			},
+		},
+		{
+			name:  "Keyword Try",
+			input: "try",
+			expect: Token{
+				Type:     TRY,
+				Literal:  "try",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "try")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "try")),
+			},
+		},
+		{
+			name:  "Keyword Catch",
+			input: "catch",
+			expect: Token{
+				Type:     CATCH,
+				Literal:  "catch",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "catch")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "catch")),
+			},
+		},
+		{
+			name:  "Keyword Throw",
+			input: "throw",
+			expect: Token{
+				Type:     THROW,
+				Literal:  "throw",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "throw")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "throw")),
+			},
+		},
+		{
+			name:  "Identifier Starting With In",
+			input: "index",
+			expect: Token{
+				Type:     IDENT,
+				Literal:  "index",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "index")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "index")),
 			},
 		},
 	}
@@ -167,36 +219,46 @@ func TestLexer_Operators(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  string
-		expect *Token
+		expect Token
 	}{
 		{
 			name:  "Single Character Operator",
 			input: "+",
-			expect: &Token{
+			expect: Token{
 				Type:     PLUS,
 				Literal:  "+",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "+"),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", "+"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "+")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "+")),
+			},
+		},
+		{
+			name:  "Question Mark",
+			input: "?",
+			expect: Token{
+				Type:     QUESTION,
+				Literal:  "?",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "?")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "?")),
 			},
 		},
 		{
 			name:  "Multi-Character Operator",
 			input: ">=",
-			expect: &Token{
+			expect: Token{
 				Type:     GTE,
 				Literal:  ">=",
-				PosStart: util.NewPos(1, 1, 0, "<test>", ">="),
-				PosEnd:   util.NewPos(1, 3, 2, "<test>", ">="),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", ">=")),
+				PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", ">=")),
 			},
 		},
 		{
 			name:  "Multi-Character Input but Single Operator",
 			input: "=>",
-			expect: &Token{
+			expect: Token{
 				Type:     EQUAL,
 				Literal:  "=",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "=>"),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", "=>"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "=>")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "=>")),
 			},
 		},
 	}
@@ -219,66 +281,96 @@ func TestLexer_Strings(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  string
-		expect *Token
+		expect Token
 	}{
 		{
 			name:  "Single Character String",
 			input: "\"a\"",
-			expect: &Token{
+			expect: Token{
 				Type:     STRING,
 				Literal:  "a",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"a\""),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "\"a\""),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"a\"")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "\"a\"")),
 			},
 		},
 		{
 			name:  "Multi-Character String",
 			input: "\"hello world\"",
-			expect: &Token{
+			expect: Token{
 				Type:     STRING,
 				Literal:  "hello world",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"hello world\""),
-				PosEnd:   util.NewPos(1, 14, 13, "<test>", "\"hello world\""),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"hello world\"")),
+				PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "\"hello world\"")),
 			},
 		},
 		{
 			name:  "String with Escaped Characters",
 			input: "\"hello \\\"world\\\"\"",
-			expect: &Token{
+			expect: Token{
 				Type:     STRING,
 				Literal:  "hello \"world\"",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"hello \\\"world\\\"\""),
-				PosEnd:   util.NewPos(1, 18, 17, "<test>", "\"hello \\\"world\\\"\""),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"hello \\\"world\\\"\"")),
+				PosEnd:   util.NewPos(1, 18, 17, util.NewSourceFile("<test>", "\"hello \\\"world\\\"\"")),
 			},
 		},
 		{
 			name:  "String with Single Chinese Character",
 			input: "\"你\"",
-			expect: &Token{
+			expect: Token{
 				Type:     STRING,
 				Literal:  "你",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"你\""),
-				PosEnd:   util.NewPos(1, 4, 5, "<test>", "\"你\""),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"你\"")),
+				PosEnd:   util.NewPos(1, 4, 5, util.NewSourceFile("<test>", "\"你\"")),
 			},
 		},
 		{
 			name:  "String with Multi-Chinese-Character",
 			input: "\"你好世界\"",
-			expect: &Token{
+			expect: Token{
 				Type:     STRING,
 				Literal:  "你好世界",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"你好世界\""),
-				PosEnd:   util.NewPos(1, 7, 14, "<test>", "\"你好世界\""),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"你好世界\"")),
+				PosEnd:   util.NewPos(1, 7, 14, util.NewSourceFile("<test>", "\"你好世界\"")),
 			},
 		},
 		{
 			name:  "String with Multi-Chinese-Character with Escaped Characters",
 			input: "\"你好 \\\"世界\\\"\"",
-			expect: &Token{
+			expect: Token{
 				Type:     STRING,
 				Literal:  "你好 \"世界\"",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"你好 \\\"世界\\\"\""),
-				PosEnd:   util.NewPos(1, 12, 19, "<test>", "\"你好 \\\"世界\\\"\""),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"你好 \\\"世界\\\"\"")),
+				PosEnd:   util.NewPos(1, 12, 19, util.NewSourceFile("<test>", "\"你好 \\\"世界\\\"\"")),
+			},
+		},
+		{
+			name:  "String with Escaped Interpolation",
+			input: "\"\\${x}\"",
+			expect: Token{
+				Type:     STRING,
+				Literal:  "${x}",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"\\${x}\"")),
+				PosEnd:   util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "\"\\${x}\"")),
+			},
+		},
+		{
+			name:  "Single-Quoted String Without Interpolation",
+			input: "'${x}'",
+			expect: Token{
+				Type:     STRING,
+				Literal:  "${x}",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "'${x}'")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "'${x}'")),
+			},
+		},
+		{
+			name:  "Multi-Line Raw String with Chinese Characters",
+			input: "`幽\n灵\\n`",
+			expect: Token{
+				Type:     STRING,
+				Literal:  "幽\n灵\\n",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "`幽\n灵\\n`")),
+				PosEnd:   util.NewPos(2, 5, 11, util.NewSourceFile("<test>", "`幽\n灵\\n`")),
 			},
 		},
 	}
@@ -297,6 +389,134 @@ func TestLexer_Strings(t *testing.T) {
 	}
 }
 
+func TestLexer_InterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted []string
+	}{
+		{
+			name:     "Single Interpolation",
+			input:    `"Hello ${name}!"`,
+			excepted: []string{`INTERP_START "Hello "`, `IDENT "name"`, `INTERP_END "!"`},
+		},
+		{
+			name:  "Multiple Interpolations",
+			input: `"${a}+${b}"`,
+			excepted: []string{
+				`INTERP_START ""`, `IDENT "a"`, `INTERP_MID "+"`, `IDENT "b"`, `INTERP_END ""`,
+			},
+		},
+		{
+			name:  "Braces Inside Interpolation",
+			input: `"${ {"k": 1}["k"] }"`,
+			excepted: []string{
+				`INTERP_START ""`, `LBRACE "{"`, `STRING "k"`, `COLON ":"`, `INT "1"`, `RBRACE "}"`,
+				`LBRACKET "["`, `STRING "k"`, `RBRACKET "]"`, `INTERP_END ""`,
+			},
+		},
+		{
+			name:  "Nested Interpolated String",
+			input: `"a${f("b${x}c")}d"`,
+			excepted: []string{
+				`INTERP_START "a"`, `IDENT "f"`, `LPAREN "("`,
+				`INTERP_START "b"`, `IDENT "x"`, `INTERP_END "c"`,
+				`RPAREN ")"`, `INTERP_END "d"`,
+			},
+		},
+		{
+			name:     "Escapes In Fragments",
+			input:    `"\t${x}\n"`,
+			excepted: []string{`INTERP_START "\t"`, `IDENT "x"`, `INTERP_END "\n"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLexer("<test>", tt.input)
+			var got []string
+			for {
+				tok, err := l.NextToken()
+				if err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+				if tok.Type == EOF {
+					break
+				}
+				got = append(got, fmt.Sprintf("%s %q", tok.Type, tok.Literal))
+				l.NextChar()
+			}
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("tokens = %q, expected %q", got, tt.excepted)
+			}
+		})
+	}
+}
+
+func TestLexer_UnclosedInterpolatedString(t *testing.T) {
+	l := NewLexer("<test>", `"a${x}`)
+	for _, excepted := range []TokenType{INTERP_START, IDENT} {
+		tok, err := l.NextToken()
+		if err != nil || tok.Type != excepted {
+			t.Fatalf("tok = %+v, err = %+v, expected %s", tok, err, excepted)
+		}
+		l.NextChar()
+	}
+	_, err := l.NextToken()
+	illegal, ok := err.(*IllegalTokenError)
+	if !ok || illegal.Message != "unterminated string literal." || illegal.PosStart.Col != 1 {
+		t.Errorf("err = %+v, expected unterminated string literal at column 1", err)
+	}
+}
+
+func TestLexer_UnterminatedInterpolation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{name: "Empty Interpolation", input: `"${`, excepted: "1:2-1:5"},
+		{name: "Closing Quote Inside Interpolation", input: `var s = "${";`, excepted: "1:10-1:15"},
+		{name: "After Expression", input: `"a ${x"`, excepted: "1:4-1:9"},
+		{name: "After Braces", input: `"${ {x} `, excepted: "1:2-1:10"},
+		{name: "Later Interpolation", input: `"${a} ${b`, excepted: "1:7-1:11"},
+		{name: "Outer Interpolation", input: `"${f("${x}")`, excepted: "1:2-1:14"},
+		{name: "Innermost Interpolation", input: `"${f("${x`, excepted: "1:7-1:11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLexer("<test>", tt.input)
+			for {
+				tok, err := l.NextToken()
+				if tok.Type == EOF {
+					t.Fatalf("excepted unterminated interpolation error, got EOF")
+				}
+				if err == nil {
+					l.NextChar()
+					continue
+				}
+				illegal, ok := err.(*IllegalTokenError)
+				if !ok || illegal.Code != errcode.UnterminatedInterpolation || illegal.Message != `unterminated interpolation "${".` {
+					t.Fatalf("err = %+v, expected unterminated interpolation", err)
+				}
+				if tok.Type != ILLEGAL {
+					t.Errorf("tok = %+v, expected ILLEGAL", tok)
+				}
+				pos := fmt.Sprintf("%d:%d-%d:%d", illegal.PosStart.Row, illegal.PosStart.Col, illegal.PosEnd.Row, illegal.PosEnd.Col)
+				if pos != tt.excepted {
+					t.Errorf("excepted position %s, got %s", tt.excepted, pos)
+				}
+				break
+			}
+			// 报告错误之后不再处于插值中
+			if tok, err := l.NextToken(); err != nil || tok.Type != EOF {
+				t.Errorf("tok = %+v, err = %+v, expected EOF", tok, err)
+			}
+		})
+	}
+}
+
 func TestLexer_Errors(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -308,8 +528,8 @@ func TestLexer_Errors(t *testing.T) {
 			input: "/* This is an unclosed multiline comment",
 			err: &SyntaxError{
 				Message:  `"*/" is expected.`,
-				PosStart: util.NewPos(1, 41, 40, "<test>", "/* This is an unclosed multiline comment"),
-				PosEnd:   util.NewPos(1, 43, 42, "<test>", "/* This is an unclosed multiline comment"),
+				PosStart: util.NewPos(1, 41, 40, util.NewSourceFile("<test>", "/* This is an unclosed multiline comment")),
+				PosEnd:   util.NewPos(1, 43, 42, util.NewSourceFile("<test>", "/* This is an unclosed multiline comment")),
 			},
 		},
 		{
@@ -317,8 +537,8 @@ func TestLexer_Errors(t *testing.T) {
 			input: "12.34.56",
 			err: &IllegalTokenError{
 				Message:  "illegal float literal.",
-				PosStart: util.NewPos(1, 6, 5, "<test>", "12.34.56"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "12.34.56"),
+				PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "12.34.56")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "12.34.56")),
 			},
 		},
 		{
@@ -326,8 +546,44 @@ func TestLexer_Errors(t *testing.T) {
 			input: "\"hello \\zworld\"",
 			err: &IllegalTokenError{
 				Message:  "illegal escape character.",
-				PosStart: util.NewPos(1, 8, 7, "<test>", "\"hello \\zworld\""),
-				PosEnd:   util.NewPos(1, 10, 9, "<test>", "\"hello \\zworld\""),
+				PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "\"hello \\zworld\"")),
+				PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "\"hello \\zworld\"")),
+			},
+		},
+		{
+			name:  "Illegal Character",
+			input: "#",
+			err: &IllegalTokenError{
+				Message:  `illegal token "#".`,
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "#")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "#")),
+			},
+		},
+		{
+			name:  "NUL Byte",
+			input: "\x00var x;",
+			err: &IllegalTokenError{
+				Message:  "illegal byte 0x00.",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\x00var x;")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "\x00var x;")),
+			},
+		},
+		{
+			name:  "Invalid UTF-8",
+			input: "\xed\xa0\x80",
+			err: &IllegalTokenError{
+				Message:  "illegal byte 0xed.",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\xed\xa0\x80")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "\xed\xa0\x80")),
+			},
+		},
+		{
+			name:  "Invalid UTF-8 In String",
+			input: "\"ab\xff\"",
+			err: &IllegalTokenError{
+				Message:  "illegal byte 0xff in string literal.",
+				PosStart: util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "\"ab\xff\"")),
+				PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "\"ab\xff\"")),
 			},
 		},
 		{
@@ -335,8 +591,8 @@ func TestLexer_Errors(t *testing.T) {
 			input: "\"hello world",
 			err: &IllegalTokenError{
 				Message:  "unterminated string literal.",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"hello world"),
-				PosEnd:   util.NewPos(1, 14, 13, "<test>", "\"hello world"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"hello world")),
+				PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "\"hello world")),
 			},
 		},
 	}
@@ -344,9 +600,12 @@ func TestLexer_Errors(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			l := NewLexer("<test>", tt.input)
-			_, err := l.NextToken()
+			tok, err := l.NextToken()
 			if err == nil {
-				t.Errorf("err = %+v, expected %+v", err, tt.err)
+				t.Fatalf("err = %+v, expected %+v", err, tt.err)
+			}
+			if tok.Type != ILLEGAL || tok.PosStart == nil || tok.PosEnd == nil {
+				t.Errorf("tok = %+v, expected ILLEGAL token with position", tok)
 			}
 			if err.Error() != tt.err.Error() {
 				t.Errorf("err = %+v, expected %+v", err, tt.err)
@@ -354,3 +613,30 @@ func TestLexer_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestLexer_RecordComments(t *testing.T) {
+	input := "// line\nvar x = 1; /* block\n comment */\n"
+	l := NewLexer("<test>", input)
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			t.Fatalf("err = %+v, expected nil", err)
+		}
+		if tok.Type == EOF {
+			break
+		}
+		l.NextChar()
+	}
+	excepted := []string{"// line", "/* block\n comment */"}
+	if len(l.Comments) != len(excepted) {
+		t.Fatalf("len(Comments) = %d, expected %d", len(l.Comments), len(excepted))
+	}
+	for i, comment := range l.Comments {
+		if comment.Text != excepted[i] {
+			t.Errorf("Comments[%d] = %q, expected %q", i, comment.Text, excepted[i])
+		}
+	}
+	if l.Comments[1].PosStart.Row != 2 || l.Comments[1].PosEnd.Row != 3 {
+		t.Errorf("block comment rows = %d-%d, expected 2-3", l.Comments[1].PosStart.Row, l.Comments[1].PosEnd.Row)
+	}
+}
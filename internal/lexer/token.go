@@ -10,18 +10,19 @@ import "github.com/Ghost-Xiao/ghost-lang/internal/util"
 // 包含令牌类型、字面量值和位置信息，用于语法分析和错误报告
 
 type Token struct {
-	Type     string    // 令牌类型，如INT、PLUS、IDENT等
+	Type     TokenType // 令牌类型，如INT、PLUS、IDENT等
 	Literal  string    // 令牌的字面量值，如数字内容、标识符名称
 	PosStart *util.Pos // 令牌在源代码中的起始位置
 	PosEnd   *util.Pos // 令牌在源代码中的结束位置
 }
 
-// Copy 创建当前Token的深拷贝
+// Copy 创建当前Token的副本
+// 语法分析器按值持有Token，只有语法树节点需要保存Token时才使用Copy
 // 返回值:
 //
-//	*Token - 与原Token内容完全相同的新实例
-func (t *Token) Copy() *Token {
-	return &Token{Type: t.Type, Literal: t.Literal, PosStart: t.PosStart, PosEnd: t.PosEnd}
+//	*Token - 与原Token内容完全相同的新实例，位置与原Token共享
+func (t Token) Copy() *Token {
+	return &t
 }
 
 // String 将Token转换为字符串表示形式
@@ -29,98 +30,226 @@ func (t *Token) Copy() *Token {
 // 返回值:
 //
 //	string - 格式化的令牌字符串
-func (t *Token) String() string {
-	return t.Type + ": " + t.Literal
+func (t Token) String() string {
+	return t.Type.String() + ": " + t.Literal
 }
 
+// Comment 表示源代码中的一条注释
+// 词法分析器跳过注释时记录，供格式化等需要保留注释的工具使用
+
+type Comment struct {
+	Text     string    // 注释的原始文本，包含注释符号
+	PosStart *util.Pos // 注释的起始位置
+	PosEnd   *util.Pos // 注释的结束位置(不包含)
+}
+
+// TokenType 令牌类型
+// 用从1开始的连续整数表示，语法分析器以它为下标查找分派表；零值表示没有令牌
+
+type TokenType uint8
+
 // 以下为预定义的令牌类型常量
 // 基础类型令牌
 const (
-	ILLEGAL = "ILLEGAL" // 非法令牌，表示无法识别的字符
-	EOF     = "EOF"     // 结束符，表示源代码结束
-	INT     = "INT"     // 整数类型令牌
-	FLOAT   = "FLOAT"   // 浮点数类型令牌
-	STRING  = "STRING"  // 字符串类型令牌
-	IDENT   = "IDENT"   // 标识符令牌，如变量名、函数名
+	ILLEGAL TokenType = iota + 1 // 非法令牌，表示无法识别的字符
+	EOF                          // 结束符，表示源代码结束
+	INT                          // 整数类型令牌
+	FLOAT                        // 浮点数类型令牌
+	STRING                       // 字符串类型令牌
+	IDENT                        // 标识符令牌，如变量名、函数名
+
+	// 字符串插值令牌，插值表达式的令牌位于它们之间
+	INTERP_START // 带插值的字符串从开始引号到第一个"${"的部分
+	INTERP_MID   // 带插值的字符串从"}"到下一个"${"的部分
+	INTERP_END   // 带插值的字符串从最后一个"}"到结束引号的部分
 
 	// 关键字令牌
-	VAR    = "VAR"    // var关键字，用于变量声明
-	CONST  = "CONST"  // const关键字，用于常量声明
-	FUNC   = "FUNC"   // func关键字，用于函数定义
-	IF     = "IF"     // if关键字，条件语句
-	ELSE   = "ELSE"   // else关键字，条件语句的分支
-	FOR    = "FOR"    // for关键字，循环语句
-	RETURN = "RETURN" // return关键字，函数返回
-	TRUE   = "TRUE"   // true关键字，布尔值
-	FALSE  = "FALSE"  // false关键字，布尔值
-	NULL   = "NULL"   // null关键字，表示空值
+	VAR      // var关键字，用于变量声明
+	CONST    // const关键字，用于常量声明
+	FUNC     // func关键字，用于函数定义
+	IF       // if关键字，条件语句
+	ELSE     // else关键字，条件语句的分支
+	FOR      // for关键字，循环语句
+	WHILE    // while关键字，循环语句
+	IN       // in关键字，for-in循环语句
+	BREAK    // break关键字，结束循环
+	CONTINUE // continue关键字，开始循环的下一次迭代
+	RETURN   // return关键字，函数返回
+	IMPORT   // import关键字，导入其他文件
+	TRY      // try关键字，捕获错误的语句
+	CATCH    // catch关键字，try语句中处理错误的子句
+	THROW    // throw关键字，抛出错误
+	TRUE     // true关键字，布尔值
+	FALSE    // false关键字，布尔值
+	NULL     // null关键字，表示空值
 
 	// 运算符令牌
-	PLUS        = "PLUS"        // 加号运算符(+)
-	MINUS       = "MINUS"       // 减号运算符(-)
-	ASTERISK    = "ASTERISK"    // 乘号运算符(*)
-	SLASH       = "SLASH"       // 除号运算符(/)
-	PERCENT     = "PERCENT"     // 取模运算符(%)
-	GT          = "GT"          // 大于运算符(>)
-	LT          = "LT"          // 小于运算符(<)
-	DOT         = "DOT"         // 点运算符(.)
-	COMMA       = "COMMA"       // 逗号(,)
-	EQUAL       = "EQUAL"       // 等号(=)
-	LBRACKET    = "LBRACKET"    // 左中括号([)
-	RBRACKET    = "RBRACKET"    // 右中括号(])
-	LPAREN      = "LPAREN"      // 左圆括号(()
-	RPAREN      = "RPAREN"      // 右圆括号())
-	LBRACE      = "LBRACE"      // 左花括号({)
-	RBRACE      = "RBRACE"      // 右花括号(})
-	BANG        = "BANG"        // 感叹号(!)
-	BITWISE_AND = "BITWISE_AND" // 按位与(&)
-	BITWISE_OR  = "BITWISE_OR"  // 按位或(|)
-	BITWISE_XOR = "BITWISE_XOR" // 按位异或(^)
-	BITWISE_NOT = "BITWISE_NOT" // 按位非(~)
-	LEFT_SHIFT  = "LEFT_SHIFT"  // 左移运算符(<<)
-	RIGHT_SHIFT = "RIGHT_SHIFT" // 右移运算符(>>)
-	EQUALS      = "EQUALS"      // 等于比较运算符(==)
-	NOT_EQUALS  = "NOT_EQUALS"  // 不等于比较运算符(!=)
-	LTE         = "LTE"         // 小于等于运算符(<=)
-	GTE         = "GTE"         // 大于等于运算符(>=)
-	LOGICAL_AND = "LOGICAL_AND" // 逻辑与(&&)
-	LOGICAL_OR  = "LOGICAL_OR"  // 逻辑或(||)
-	INCREMENT   = "INCREMENT"   // 自增运算符(++)
-	DECREMENT   = "DECREMENT"   // 自减运算符(--)
-	ARROW       = "ARROW"       // 箭头运算符(->)，用于函数返回类型
-	SEMICOLON   = "SEMICOLON"   // 分号(;)
+	PLUS        // 加号运算符(+)
+	MINUS       // 减号运算符(-)
+	ASTERISK    // 乘号运算符(*)
+	SLASH       // 除号运算符(/)
+	PERCENT     // 取模运算符(%)
+	GT          // 大于运算符(>)
+	LT          // 小于运算符(<)
+	DOT         // 点运算符(.)
+	COMMA       // 逗号(,)
+	COLON       // 冒号(:)
+	QUESTION    // 问号(?)
+	EQUAL       // 等号(=)
+	LBRACKET    // 左中括号([)
+	RBRACKET    // 右中括号(])
+	LPAREN      // 左圆括号(()
+	RPAREN      // 右圆括号())
+	LBRACE      // 左花括号({)
+	RBRACE      // 右花括号(})
+	BANG        // 感叹号(!)
+	BITWISE_AND // 按位与(&)
+	BITWISE_OR  // 按位或(|)
+	BITWISE_XOR // 按位异或(^)
+	BITWISE_NOT // 按位非(~)
+	LEFT_SHIFT  // 左移运算符(<<)
+	RIGHT_SHIFT // 右移运算符(>>)
+	EQUALS      // 等于比较运算符(==)
+	NOT_EQUALS  // 不等于比较运算符(!=)
+	LTE         // 小于等于运算符(<=)
+	GTE         // 大于等于运算符(>=)
+	LOGICAL_AND // 逻辑与(&&)
+	LOGICAL_OR  // 逻辑或(||)
+	INCREMENT   // 自增运算符(++)
+	DECREMENT   // 自减运算符(--)
+	ARROW       // 箭头运算符(->)，用于函数返回类型
+	SEMICOLON   // 分号(;)
 
 	// 复合赋值运算符令牌
-	PLUS_EQUAL        = "PLUS_EQUAL"        // 加法赋值运算符(+=)
-	MINUS_EQUAL       = "MINUS_EQUAL"       // 减法赋值运算符(-=)
-	ASTERISK_EQUAL    = "ASTERISK_EQUAL"    // 乘法赋值运算符(*=)
-	SLASH_EQUAL       = "SLASH_EQUAL"       // 除法赋值运算符(/=)
-	PERCENT_EQUAL     = "PERCENT_EQUAL"     // 取模赋值运算符(%=)
-	BITWISE_AND_EQUAL = "BITWISE_AND_EQUAL" // 按位与赋值运算符(&=)
-	BITWISE_OR_EQUAL  = "BITWISE_OR_EQUAL"  // 按位或赋值运算符(|=)
-	BITWISE_XOR_EQUAL = "BITWISE_XOR_EQUAL" // 按位异或赋值运算符(^=)
-	LEFT_SHIFT_EQUAL  = "LEFT_SHIFT_EQUAL"  // 左移赋值运算符(<<=)
-	RIGHT_SHIFT_EQUAL = "RIGHT_SHIFT_EQUAL" // 右移赋值运算符(>>=)
+	PLUS_EQUAL        // 加法赋值运算符(+=)
+	MINUS_EQUAL       // 减法赋值运算符(-=)
+	ASTERISK_EQUAL    // 乘法赋值运算符(*=)
+	SLASH_EQUAL       // 除法赋值运算符(/=)
+	PERCENT_EQUAL     // 取模赋值运算符(%=)
+	BITWISE_AND_EQUAL // 按位与赋值运算符(&=)
+	BITWISE_OR_EQUAL  // 按位或赋值运算符(|=)
+	BITWISE_XOR_EQUAL // 按位异或赋值运算符(^=)
+	LEFT_SHIFT_EQUAL  // 左移赋值运算符(<<=)
+	RIGHT_SHIFT_EQUAL // 右移赋值运算符(>>=)
+
+	// TokenTypeCount 令牌类型的数量加一，以令牌类型为下标的数组使用它作为长度
+	TokenTypeCount
 )
 
+// typeNames 令牌类型的名称，用于调试输出和未知类型的描述
+var typeNames = [TokenTypeCount]string{
+	ILLEGAL:           "ILLEGAL",
+	EOF:               "EOF",
+	INT:               "INT",
+	FLOAT:             "FLOAT",
+	STRING:            "STRING",
+	IDENT:             "IDENT",
+	INTERP_START:      "INTERP_START",
+	INTERP_MID:        "INTERP_MID",
+	INTERP_END:        "INTERP_END",
+	VAR:               "VAR",
+	CONST:             "CONST",
+	FUNC:              "FUNC",
+	IF:                "IF",
+	ELSE:              "ELSE",
+	FOR:               "FOR",
+	WHILE:             "WHILE",
+	IN:                "IN",
+	BREAK:             "BREAK",
+	CONTINUE:          "CONTINUE",
+	RETURN:            "RETURN",
+	IMPORT:            "IMPORT",
+	TRY:               "TRY",
+	CATCH:             "CATCH",
+	THROW:             "THROW",
+	TRUE:              "TRUE",
+	FALSE:             "FALSE",
+	NULL:              "NULL",
+	PLUS:              "PLUS",
+	MINUS:             "MINUS",
+	ASTERISK:          "ASTERISK",
+	SLASH:             "SLASH",
+	PERCENT:           "PERCENT",
+	GT:                "GT",
+	LT:                "LT",
+	DOT:               "DOT",
+	COMMA:             "COMMA",
+	COLON:             "COLON",
+	QUESTION:          "QUESTION",
+	EQUAL:             "EQUAL",
+	LBRACKET:          "LBRACKET",
+	RBRACKET:          "RBRACKET",
+	LPAREN:            "LPAREN",
+	RPAREN:            "RPAREN",
+	LBRACE:            "LBRACE",
+	RBRACE:            "RBRACE",
+	BANG:              "BANG",
+	BITWISE_AND:       "BITWISE_AND",
+	BITWISE_OR:        "BITWISE_OR",
+	BITWISE_XOR:       "BITWISE_XOR",
+	BITWISE_NOT:       "BITWISE_NOT",
+	LEFT_SHIFT:        "LEFT_SHIFT",
+	RIGHT_SHIFT:       "RIGHT_SHIFT",
+	EQUALS:            "EQUALS",
+	NOT_EQUALS:        "NOT_EQUALS",
+	LTE:               "LTE",
+	GTE:               "GTE",
+	LOGICAL_AND:       "LOGICAL_AND",
+	LOGICAL_OR:        "LOGICAL_OR",
+	INCREMENT:         "INCREMENT",
+	DECREMENT:         "DECREMENT",
+	ARROW:             "ARROW",
+	SEMICOLON:         "SEMICOLON",
+	PLUS_EQUAL:        "PLUS_EQUAL",
+	MINUS_EQUAL:       "MINUS_EQUAL",
+	ASTERISK_EQUAL:    "ASTERISK_EQUAL",
+	SLASH_EQUAL:       "SLASH_EQUAL",
+	PERCENT_EQUAL:     "PERCENT_EQUAL",
+	BITWISE_AND_EQUAL: "BITWISE_AND_EQUAL",
+	BITWISE_OR_EQUAL:  "BITWISE_OR_EQUAL",
+	BITWISE_XOR_EQUAL: "BITWISE_XOR_EQUAL",
+	LEFT_SHIFT_EQUAL:  "LEFT_SHIFT_EQUAL",
+	RIGHT_SHIFT_EQUAL: "RIGHT_SHIFT_EQUAL",
+}
+
+// String 返回令牌类型的名称，如INT、PLUS
+//
+// 返回值:
+//
+//	string - 令牌类型的名称，零值为空字符串
+func (t TokenType) String() string {
+	if t < TokenTypeCount {
+		return typeNames[t]
+	}
+	return ""
+}
+
 // Keywords 关键字映射表，将字符串标识符映射到对应的令牌类型
 // 用于词法分析时识别保留关键字
-var Keywords = map[string]string{
-	"var":    VAR,    // 变量声明关键字
-	"const":  CONST,  // 常量声明关键字
-	"func":   FUNC,   // 函数定义关键字
-	"if":     IF,     // 条件语句关键字
-	"else":   ELSE,   // 条件语句分支关键字
-	"for":    FOR,    // 循环语句关键字
-	"return": RETURN, // 函数返回关键字
-	"true":   TRUE,   // 布尔值true
-	"false":  FALSE,  // 布尔值false
-	"null":   NULL,   // 空值关键字
+var Keywords = map[string]TokenType{
+	"var":      VAR,      // 变量声明关键字
+	"const":    CONST,    // 常量声明关键字
+	"func":     FUNC,     // 函数定义关键字
+	"if":       IF,       // 条件语句关键字
+	"else":     ELSE,     // 条件语句分支关键字
+	"for":      FOR,      // 循环语句关键字
+	"while":    WHILE,    // 条件循环语句关键字
+	"in":       IN,       // 遍历循环语句关键字
+	"break":    BREAK,    // 结束循环关键字
+	"continue": CONTINUE, // 开始下一次迭代关键字
+	"return":   RETURN,   // 函数返回关键字
+	"import":   IMPORT,   // 导入关键字
+	"try":      TRY,      // 捕获错误关键字
+	"catch":    CATCH,    // 处理错误关键字
+	"throw":    THROW,    // 抛出错误关键字
+	"true":     TRUE,     // 布尔值true
+	"false":    FALSE,    // 布尔值false
+	"null":     NULL,     // 空值关键字
 }
 
 // Operators 操作符映射表，将字符串操作符映射到对应的令牌类型
 // 用于词法分析时识别各种运算符
-var Operators = map[string]string{
+var Operators = map[string]TokenType{
 	"+":   PLUS,              // 加法运算符
 	"-":   MINUS,             // 减法运算符
 	"*":   ASTERISK,          // 乘法运算符
@@ -130,6 +259,8 @@ var Operators = map[string]string{
 	"<":   LT,                // 小于比较运算符
 	".":   DOT,               // 点运算符
 	",":   COMMA,             // 逗号分隔符
+	":":   COLON,             // 冒号，分隔映射的键和值或条件运算符的两个分支
+	"?":   QUESTION,          // 问号，条件运算符
 	"=":   EQUAL,             // 赋值运算符
 	"[":   LBRACKET,          // 左中括号
 	"]":   RBRACKET,          // 右中括号
@@ -166,6 +297,45 @@ var Operators = map[string]string{
 	">>=": RIGHT_SHIFT_EQUAL, // 右移赋值运算符
 }
 
+// tokenNames 没有固定源代码文本的令牌类型在错误信息中的名称
+var tokenNames = map[TokenType]string{
+	EOF:          "end of file",
+	INT:          "integer",
+	FLOAT:        "float",
+	STRING:       "string",
+	INTERP_START: "interpolated string",
+	INTERP_MID:   "\"}\"",
+	INTERP_END:   "\"}\"",
+	IDENT:        "identifier",
+}
+
+// Describe 返回令牌类型在错误信息中的描述
+// 运算符和关键字为带引号的源代码文本，如";"，其他令牌为名称，如identifier
+//
+// 参数:
+//
+//	tokenType - 令牌类型
+//
+// 返回值:
+//
+//	string - 令牌类型的描述，未知类型原样返回
+func Describe(tokenType TokenType) string {
+	if name, ok := tokenNames[tokenType]; ok {
+		return name
+	}
+	for text, t := range Operators {
+		if t == tokenType {
+			return "\"" + text + "\""
+		}
+	}
+	for text, t := range Keywords {
+		if t == tokenType {
+			return "\"" + text + "\""
+		}
+	}
+	return tokenType.String()
+}
+
 // LookupIdent 检查标识符是否为关键字，并返回对应的令牌类型
 // 参数:
 //
@@ -173,8 +343,8 @@ var Operators = map[string]string{
 //
 // 返回值:
 //
-//	string - 如果是关键字则返回对应的令牌类型，否则返回IDENT
-func LookupIdent(ident string) string {
+//	TokenType - 如果是关键字则返回对应的令牌类型，否则返回IDENT
+func LookupIdent(ident string) TokenType {
 	if keyword, ok := Keywords[ident]; ok {
 		return keyword
 	}
@@ -192,10 +362,11 @@ var Escape = map[rune]rune{
 	'\'': '\'', // 单引号
 	'"':  '"',  // 双引号
 	'`':  '`',  // 反引号
+	'$':  '$',  // 美元符号，"\${"表示字面的"${"
 }
 
 // CompoundAssignmentOperators 包含复合赋值运算符到基础运算符的映射关系
-var CompoundAssignmentOperators = map[string]string{
+var CompoundAssignmentOperators = map[TokenType]TokenType{
 	PLUS_EQUAL:        PLUS,        // 加法运算符，对应+=
 	MINUS_EQUAL:       MINUS,       // 减法运算符，对应-=
 	ASTERISK_EQUAL:    ASTERISK,    // 乘法运算符，对应*=
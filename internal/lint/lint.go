@@ -0,0 +1,420 @@
+// Package lint 对语法树进行静态检查，报告不影响执行但通常意味着错误的代码，如声明后从未读取的变量、遮蔽外层同名变量的声明和永远不会执行的语句
+// 检查不执行程序，只产生警告
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// Warning 静态检查产生的警告
+// 实现 error 接口，便于与词法和语法错误一起渲染和输出
+
+type Warning struct {
+	Code     string    // 警告代码，见errcode包
+	Message  string    // 警告描述文本
+	PosStart *util.Pos // 警告起始位置
+	PosEnd   *util.Pos // 警告结束位置
+}
+
+// Error 返回警告的位置和描述
+//
+// 返回值:
+//
+//	string - 格式为<文件>:<行>:<列>: Warning: <描述>的警告信息
+func (w *Warning) Error() string {
+	return fmt.Sprintf("%s:%d:%d: Warning: %s", w.PosStart.File(), w.PosStart.Row, w.PosStart.Col, w.Message)
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Warning"
+func (w *Warning) ErrorKind() string {
+	return "Warning"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (w *Warning) ErrorCode() string {
+	return w.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (w *Warning) ErrorMessage() string {
+	return w.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (w *Warning) Pos() (*util.Pos, *util.Pos) {
+	return w.PosStart, w.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 静态检查在执行之前进行，没有调用栈，总是为nil
+func (w *Warning) Frames() *frame.Frame {
+	return nil
+}
+
+// binding 作用域中的一个名称
+
+type binding struct {
+	name     string    // 名称
+	kind     string    // 名称的种类，"variable"、"parameter"、"function"或"module"
+	used     bool      // 是否被读取过
+	posStart *util.Pos // 声明的起始位置
+	posEnd   *util.Pos // 声明的结束位置
+}
+
+// scope 词法作用域，与解释器执行时创建的环境一一对应
+
+type scope struct {
+	outer    *scope              // 外层作用域，模块作用域为nil
+	names    map[string]*binding // 作用域中当前可见的名称
+	bindings []*binding          // 按声明顺序排列的所有名称，包括被同名声明覆盖的名称
+	pending  []ast.Node          // 在作用域结束时检查的函数声明语句和函数表达式
+}
+
+// checker 遍历语法树并收集警告
+
+type checker struct {
+	warnings []*Warning // 收集的警告
+}
+
+// Check 对程序进行静态检查
+// 报告函数和语句块中声明后从未读取的局部变量和函数参数，以下划线开头的名称不报告
+// 模块顶层的变量可能被导入该模块的程序使用，不报告
+// 报告遮蔽外层作用域中同名声明的变量，函数参数遮蔽模块顶层的名称很常见，不报告
+// 报告语句块中return等总是转移控制的语句之后永远不会执行的语句
+//
+// 参数:
+//
+//	program - 程序的语法树
+//
+// 返回值:
+//
+//	[]*Warning - 按源代码位置排列的警告，没有警告时为nil
+func Check(program *ast.Program) []*Warning {
+	c := &checker{}
+	module := newScope(nil)
+	c.statements(program.Statements, module)
+	c.close(module, false)
+	sort.SliceStable(c.warnings, func(i, j int) bool {
+		return c.warnings[i].PosStart.Idx < c.warnings[j].PosStart.Idx
+	})
+	return c.warnings
+}
+
+// newScope 创建作用域
+//
+// 参数:
+//
+//	outer - 外层作用域
+//
+// 返回值:
+//
+//	*scope - 新的作用域
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, names: make(map[string]*binding)}
+}
+
+// declare 在作用域中声明名称
+//
+// 参数:
+//
+//	name - 名称
+//	kind - 名称的种类
+//	posStart - 声明的起始位置
+//	posEnd - 声明的结束位置
+func (s *scope) declare(name, kind string, posStart, posEnd *util.Pos) {
+	b := &binding{name: name, kind: kind, posStart: posStart, posEnd: posEnd}
+	s.names[name] = b
+	s.bindings = append(s.bindings, b)
+}
+
+// lookup 从外层作用域开始由内到外查找名称，不包括作用域自身
+//
+// 参数:
+//
+//	name - 名称
+//
+// 返回值:
+//
+//	*binding - 找到的名称，不存在时为nil
+//	*scope - 名称所在的作用域
+func (s *scope) lookup(name string) (*binding, *scope) {
+	for outer := s.outer; outer != nil; outer = outer.outer {
+		if b, ok := outer.names[name]; ok {
+			return b, outer
+		}
+	}
+	return nil, nil
+}
+
+// resolve 从内到外查找名称，并标记为已读取
+//
+// 参数:
+//
+//	name - 名称
+func (s *scope) resolve(name string) {
+	for ; s != nil; s = s.outer {
+		if b, ok := s.names[name]; ok {
+			b.used = true
+			return
+		}
+	}
+}
+
+// close 结束作用域，先检查在其中声明的函数体，再报告从未读取的名称
+// 函数体在作用域结束时才检查，函数中可以读取在函数声明之后才声明的变量
+//
+// 参数:
+//
+//	s - 要结束的作用域
+//	report - 是否报告从未读取的名称
+func (c *checker) close(s *scope, report bool) {
+	for len(s.pending) > 0 {
+		fn := s.pending[0]
+		s.pending = s.pending[1:]
+		c.function(fn, s)
+	}
+	if !report {
+		return
+	}
+	for _, b := range s.bindings {
+		if b.kind != "variable" && b.kind != "parameter" || b.used || strings.HasPrefix(b.name, "_") {
+			continue
+		}
+		c.warnings = append(c.warnings, &Warning{
+			Code:     errcode.UnusedVariable,
+			Message:  fmt.Sprintf("%s \"%s\" is declared but never used.", b.kind, b.name),
+			PosStart: b.posStart,
+			PosEnd:   b.posEnd,
+		})
+	}
+}
+
+// shadow 检查在作用域中声明的名称是否遮蔽外层作用域中的同名声明
+//
+// 参数:
+//
+//	s - 声明所在的作用域
+//	kind - 名称的种类
+//	name - 声明的名称
+//	allowModule - 是否允许遮蔽模块顶层的名称
+func (c *checker) shadow(s *scope, kind string, name *ast.IdentifierExpression, allowModule bool) {
+	shadowed, owner := s.lookup(name.Name)
+	if shadowed == nil || allowModule && owner.outer == nil {
+		return
+	}
+	c.warnings = append(c.warnings, &Warning{
+		Code:     errcode.ShadowedVariable,
+		Message:  fmt.Sprintf("%s \"%s\" shadows the %s declared at %d:%d.", kind, name.Name, shadowed.kind, shadowed.posStart.Row, shadowed.posStart.Col),
+		PosStart: name.PosStart,
+		PosEnd:   name.PosEnd,
+	})
+}
+
+// function 检查函数的默认值和函数体，参数位于函数自己的作用域中
+//
+// 参数:
+//
+//	fn - 函数声明语句或函数表达式
+//	outer - 声明函数的作用域
+func (c *checker) function(fn ast.Node, outer *scope) {
+	parameter, body := ast.FunctionParts(fn)
+	params := newScope(outer)
+	for _, param := range parameter {
+		c.expression(param.DefaultValue, outer)
+		c.shadow(params, "parameter", param.Name, true)
+		params.declare(param.Name.Name, "parameter", param.PosStart, param.PosEnd)
+	}
+	c.statement(body, params)
+	c.close(params, true)
+}
+
+// statements 依次检查语句
+//
+// 参数:
+//
+//	statements - 语句列表
+//	s - 当前作用域
+func (c *checker) statements(statements []ast.Statement, s *scope) {
+	for _, statement := range statements {
+		c.statement(statement, s)
+	}
+}
+
+// statement 检查语句
+//
+// 参数:
+//
+//	statement - 语句，可以为nil
+//	s - 当前作用域
+func (c *checker) statement(statement ast.Statement, s *scope) {
+	switch n := statement.(type) {
+	case *ast.ExpressionStatement:
+		c.expression(n.Expr, s)
+	case *ast.ReturnStatement:
+		c.expression(n.ReturnValue, s)
+	case *ast.ForStatement:
+		// for语句的初始化、条件和更新语句位于自己的作用域中
+		loop := newScope(s)
+		c.statement(n.Initialization, loop)
+		c.expression(n.Condition, loop)
+		c.statement(n.Update, loop)
+		c.statement(n.Body, loop)
+		c.close(loop, true)
+	case *ast.ForInStatement:
+		// for-in语句的循环变量位于自己的作用域中，被遍历的表达式在此之前计算
+		c.expression(n.Iterable, s)
+		loop := newScope(s)
+		c.shadow(loop, "variable", n.Variable, false)
+		loop.declare(n.Variable.Name, "variable", n.Variable.PosStart, n.Variable.PosEnd)
+		if n.ValueVariable != nil {
+			c.shadow(loop, "variable", n.ValueVariable, false)
+			loop.declare(n.ValueVariable.Name, "variable", n.ValueVariable.PosStart, n.ValueVariable.PosEnd)
+		}
+		c.statement(n.Body, loop)
+		c.close(loop, true)
+	case *ast.WhileStatement:
+		// while语句的条件和循环体位于自己的作用域中
+		loop := newScope(s)
+		c.expression(n.Condition, loop)
+		c.statement(n.Body, loop)
+		c.close(loop, true)
+	case *ast.TryStatement:
+		// try语句的主体位于自己的作用域中，catch子句的变量位于处理语句的作用域中
+		body := newScope(s)
+		c.statement(n.Body, body)
+		c.close(body, true)
+		catch := newScope(s)
+		c.shadow(catch, "variable", n.Catch.Variable, false)
+		catch.declare(n.Catch.Variable.Name, "variable", n.Catch.Variable.PosStart, n.Catch.Variable.PosEnd)
+		c.statement(n.Catch.Body, catch)
+		c.close(catch, true)
+	case *ast.ThrowStatement:
+		c.expression(n.Value, s)
+	case *ast.FunctionDeclarationStatement:
+		name := n.Name.(*ast.IdentifierExpression)
+		s.declare(name.Name, "function", name.PosStart, name.PosEnd)
+		s.pending = append(s.pending, n)
+	case *ast.ImportStatement:
+		if n.Name != nil {
+			s.declare(n.Name.Name, "module", n.Name.PosStart, n.Name.PosEnd)
+		}
+	}
+}
+
+// expression 检查表达式，读取的变量被标记为已使用
+// 赋值、复合赋值和自增自减的目标变量不算作读取
+//
+// 参数:
+//
+//	expression - 表达式，可以为nil
+//	s - 当前作用域
+func (c *checker) expression(expression ast.Expression, s *scope) {
+	switch n := expression.(type) {
+	case *ast.IdentifierExpression:
+		s.resolve(n.Name)
+	case *ast.PrefixExpression:
+		c.expression(n.Value, s)
+	case *ast.InfixExpression:
+		c.expression(n.Left, s)
+		c.expression(n.Right, s)
+	case *ast.ListExpression:
+		for _, value := range n.Value {
+			c.expression(value, s)
+		}
+	case *ast.InterpolatedStringExpression:
+		for _, part := range n.Parts {
+			c.expression(part, s)
+		}
+	case *ast.MapExpression:
+		for _, entry := range n.Entries {
+			c.expression(entry.Key, s)
+			c.expression(entry.Value, s)
+		}
+	case *ast.GroupedExpression:
+		c.expression(n.Expr, s)
+	case *ast.VarInitializationExpression:
+		// 初始值在声明之前计算，其中的同名变量指向外层作用域
+		c.expression(n.Value, s)
+		if name, ok := n.Name.(*ast.IdentifierExpression); ok {
+			c.shadow(s, "variable", name, false)
+			s.declare(name.Name, "variable", name.PosStart, name.PosEnd)
+		}
+	case *ast.VarAssignmentExpression:
+		c.target(n.Name, s)
+		c.expression(n.Value, s)
+	case *ast.CompoundAssignmentExpression:
+		c.target(n.Name, s)
+		c.expression(n.Right, s)
+	case *ast.PrefixUnaryIncDecExpression:
+		c.target(n.Right, s)
+	case *ast.PostfixUnaryIncDecExpression:
+		c.target(n.Left, s)
+	case *ast.BlockExpression:
+		block := newScope(s)
+		c.statements(n.Statements, block)
+		c.unreachable(n.Statements)
+		c.close(block, true)
+	case *ast.IfExpression:
+		c.expression(n.Condition, s)
+		c.statement(n.Consequence, s)
+		c.statement(n.Alternative, s)
+	case *ast.TernaryExpression:
+		c.expression(n.Condition, s)
+		c.expression(n.Consequence, s)
+		c.expression(n.Alternative, s)
+	case *ast.FunctionExpression:
+		s.pending = append(s.pending, n)
+	case *ast.CallExpression:
+		c.expression(n.Function, s)
+		for _, arg := range n.Argument {
+			c.expression(arg, s)
+		}
+	case *ast.IndexExpression:
+		c.expression(n.Target, s)
+		c.expression(n.Index, s)
+	case *ast.SliceExpression:
+		c.expression(n.Target, s)
+		c.expression(n.Low, s)
+		c.expression(n.High, s)
+	}
+}
+
+// target 检查赋值的目标，变量本身不算作读取，索引表达式中的列表和索引算作读取
+//
+// 参数:
+//
+//	target - 赋值的目标
+//	s - 当前作用域
+func (c *checker) target(target ast.Expression, s *scope) {
+	if _, ok := target.(*ast.IdentifierExpression); ok {
+		return
+	}
+	c.expression(target, s)
+}
@@ -0,0 +1,288 @@
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+)
+
+// check 分析源代码并返回指定代码的警告的位置和描述
+func check(t *testing.T, src, code string) []string {
+	t.Helper()
+	p, err := parser.NewParser(lexer.NewLexer("main.gh", src))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("parse err = %+v", p.Err)
+	}
+	var got []string
+	for _, w := range Check(program) {
+		if w.Code != code {
+			continue
+		}
+		got = append(got, fmt.Sprintf("%d:%d %s", w.PosStart.Row, w.PosStart.Col, w.Message))
+	}
+	return got
+}
+
+func TestLint_UnusedVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted []string
+	}{
+		{
+			name:     "Top Level Is Exempt",
+			input:    "var x = 1;",
+			excepted: nil,
+		},
+		{
+			name:     "Unused Local",
+			input:    "func f() {\n    var reslut = 1;\n    return 2;\n};",
+			excepted: []string{`2:9 variable "reslut" is declared but never used.`},
+		},
+		{
+			name:     "Used Local",
+			input:    "func f() { var x = 1; return x; };",
+			excepted: nil,
+		},
+		{
+			name:     "Assignment Is Not A Read",
+			input:    "func f() { var x = 1; x = 2; x += 1; x++; --x; };",
+			excepted: []string{`1:16 variable "x" is declared but never used.`},
+		},
+		{
+			name:     "Indexed Assignment Reads The List",
+			input:    "func f() { var xs = [1]; xs[0] = 2; };",
+			excepted: nil,
+		},
+		{
+			name:     "Unused Parameter",
+			input:    "func f(a, b) { return a; };",
+			excepted: []string{`1:11 parameter "b" is declared but never used.`},
+		},
+		{
+			name:     "Unused Loop Variable",
+			input:    "for x in [1, 2] println(0);\nfor _x in [1, 2] println(0);\nfor y in [1, 2] println(y);",
+			excepted: []string{`1:5 variable "x" is declared but never used.`},
+		},
+		{
+			name:     "Unused Map Key Or Value Variable",
+			input:    "for k, v in {:} println(k);\nfor _k, v in {:} println(v);\nfor var k, v in {:} println(0);",
+			excepted: []string{`1:8 variable "v" is declared but never used.`, `3:9 variable "k" is declared but never used.`, `3:12 variable "v" is declared but never used.`},
+		},
+		{
+			name:     "Unused Catch Variable",
+			input:    "try f() catch (e) println(0);\ntry f() catch (_e) println(0);\ntry f() catch (err) println(err);",
+			excepted: []string{`1:16 variable "e" is declared but never used.`},
+		},
+		{
+			name:     "Unused Parameter Of Function Expression",
+			input:    "println(func(a, b) a);",
+			excepted: []string{`1:17 parameter "b" is declared but never used.`},
+		},
+		{
+			name:     "Underscore Is Exempt",
+			input:    "func f(_a, b = 1) { var _tmp = b; };",
+			excepted: nil,
+		},
+		{
+			name:     "Shadowed Inner Unused",
+			input:    "func f() {\n    var x = 1;\n    if true { var x = 2; };\n    return x;\n};",
+			excepted: []string{`3:19 variable "x" is declared but never used.`},
+		},
+		{
+			name:     "Shadowed Outer Unused",
+			input:    "func f() {\n    var x = 1;\n    if true { var x = 2; println(x); };\n};",
+			excepted: []string{`2:9 variable "x" is declared but never used.`},
+		},
+		{
+			name:     "Initializer Reads Outer Variable",
+			input:    "func f() { var x = 1; { var x = x + 1; println(x); }; };",
+			excepted: nil,
+		},
+		{
+			name:     "For Loop Variable",
+			input:    "func f() { for var i = 0; i < 3; i++ { var sq = i * i; }; };",
+			excepted: []string{`1:44 variable "sq" is declared but never used.`},
+		},
+		{
+			name:     "While Loop Variable",
+			input:    "func f(n) { while n > 0 var m = n--; };",
+			excepted: []string{`1:29 variable "m" is declared but never used.`},
+		},
+		{
+			name:     "Closure Reads Later Variable",
+			input:    "func f() {\n    func g() { return limit; };\n    var limit = 3;\n    return g();\n};",
+			excepted: nil,
+		},
+		{
+			name:     "Top Level Block",
+			input:    "if true { var y = 1; };",
+			excepted: []string{`1:15 variable "y" is declared but never used.`},
+		},
+		{
+			name:     "Sorted By Position",
+			input:    "func f(a) { var b = 1; };\nfunc g() { var c = 2; };",
+			excepted: []string{`1:8 parameter "a" is declared but never used.`, `1:17 variable "b" is declared but never used.`, `2:16 variable "c" is declared but never used.`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(t, tt.input, errcode.UnusedVariable); !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestLint_ShadowedVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted []string
+	}{
+		{
+			name:     "Nested Blocks",
+			input:    "func f() {\n    var x = 1;\n    {\n        {\n            var x = 2;\n        };\n    };\n};",
+			excepted: []string{`5:17 variable "x" shadows the variable declared at 2:9.`},
+		},
+		{
+			name:     "Loop Body Hides Loop Counter",
+			input:    "for var i = 0; i < 3; i++ {\n    var i = 10;\n};",
+			excepted: []string{`2:9 variable "i" shadows the variable declared at 1:9.`},
+		},
+		{
+			name:     "For Init Shadows Local",
+			input:    "func f() {\n    var i = 0;\n    for var i = 0; i < 3; i++ {};\n};",
+			excepted: []string{`3:13 variable "i" shadows the variable declared at 2:9.`},
+		},
+		{
+			name:     "Variable Shadows Parameter",
+			input:    "func f(n) {\n    if n > 0 { var n = 0; };\n};",
+			excepted: []string{`2:20 variable "n" shadows the parameter declared at 1:8.`},
+		},
+		{
+			name:     "Variable Shadows Global",
+			input:    "var total = 0;\nfunc f() { var total = 1; };",
+			excepted: []string{`2:16 variable "total" shadows the variable declared at 1:5.`},
+		},
+		{
+			name:     "Parameter Shadowing Global Is Excluded",
+			input:    "var x = 0;\nfunc add(x, y) { return x + y; };",
+			excepted: nil,
+		},
+		{
+			name:     "Parameter Shadows Local",
+			input:    "func outer() {\n    var x = 1;\n    func inner(x) { return x; };\n};",
+			excepted: []string{`3:16 parameter "x" shadows the variable declared at 2:9.`},
+		},
+		{
+			name:     "Sibling Scopes",
+			input:    "func f() { { var x = 1; }; { var x = 2; }; };",
+			excepted: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(t, tt.input, errcode.ShadowedVariable); !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestLint_UnreachableCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted []string
+	}{
+		{
+			name:     "After Return",
+			input:    "func f() {\n    return 1;\n    println(2);\n    println(3);\n};",
+			excepted: []string{`3:5 unreachable code.`},
+		},
+		{
+			name:     "After Throw",
+			input:    "func f() {\n    throw \"bad\";\n    println(2);\n};",
+			excepted: []string{`3:5 unreachable code.`},
+		},
+		{
+			name:     "Try And Catch Both Return",
+			input:    "func f() {\n    try return g() catch (e) return e;\n    println(3);\n};",
+			excepted: []string{`3:5 unreachable code.`},
+		},
+		{
+			name:     "Only Try Returns",
+			input:    "func f() {\n    try return g() catch (e) println(e);\n    println(3);\n};",
+			excepted: nil,
+		},
+		{
+			name:     "Return Last",
+			input:    "func f() { println(1); return 1; };",
+			excepted: nil,
+		},
+		{
+			name:     "Both Branches Return",
+			input:    "func f(a) {\n    if a { return 1; } else { return 2; };\n    println(3);\n};",
+			excepted: []string{`3:5 unreachable code.`},
+		},
+		{
+			name:     "Else If Chain Returns",
+			input:    "func f(a) {\n    if a > 0 { return 1; } else if a < 0 { return 2; } else { return 0; };\n    println(3);\n};",
+			excepted: []string{`3:5 unreachable code.`},
+		},
+		{
+			name:     "One Branch Returns",
+			input:    "func f(a) {\n    if a { return 1; };\n    println(3);\n};",
+			excepted: nil,
+		},
+		{
+			name:     "Conditional Return In Loop",
+			input:    "func f(xs) {\n    for var i = 0; i < len(xs); i++ {\n        if xs[i] == 0 { return i; };\n        println(xs[i]);\n    };\n    return -1;\n};",
+			excepted: nil,
+		},
+		{
+			name:     "Loop Does Not Terminate",
+			input:    "func f() {\n    for var i = 0; i < 3; i++ { return i; };\n    return -1;\n};",
+			excepted: nil,
+		},
+		{
+			name:     "While Loop Does Not Terminate",
+			input:    "func f(c) {\n    while c { return 1; };\n    return -1;\n};",
+			excepted: nil,
+		},
+		{
+			name:     "Code After Break",
+			input:    "func f(c) {\n    while c {\n        break;\n        println(c);\n    };\n};",
+			excepted: []string{`4:9 unreachable code.`},
+		},
+		{
+			name:     "Code After Continue",
+			input:    "func f(c) {\n    while c {\n        { continue; };\n        println(c);\n    };\n};",
+			excepted: []string{`4:9 unreachable code.`},
+		},
+		{
+			name:     "Nested Block Returns",
+			input:    "func f() {\n    { return 1; };\n    println(2);\n};",
+			excepted: []string{`3:5 unreachable code.`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(t, tt.input, errcode.UnreachableCode); !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package lint
+
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+// unreachable 检查语句块中总是转移控制的语句之后的语句，报告第一条永远不会执行的语句
+//
+// 参数:
+//
+//	statements - 语句块中的语句
+func (c *checker) unreachable(statements []ast.Statement) {
+	for i, statement := range statements[:max(len(statements)-1, 0)] {
+		if !terminates(statement) {
+			continue
+		}
+		posStart, posEnd := ast.Span(statements[i+1])
+		if posStart == nil || posEnd == nil {
+			return
+		}
+		c.warnings = append(c.warnings, &Warning{
+			Code:     errcode.UnreachableCode,
+			Message:  "unreachable code.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		})
+		return
+	}
+}
+
+// terminates 判断语句是否总是转移控制，即执行后不会继续执行同一语句块中之后的语句
+// return、break、continue和throw语句总是转移控制；语句块中有总是转移控制的语句时语句块总是转移控制；
+// if表达式的两个分支都总是转移控制时if表达式总是转移控制，try语句的主体和处理语句都总是转移控制时try语句总是转移控制；
+// for语句的循环体可能一次也不执行，不算作转移控制
+//
+// 参数:
+//
+//	statement - 语句，可以为nil
+//
+// 返回值:
+//
+//	bool - 是否总是转移控制
+func terminates(statement ast.Statement) bool {
+	switch n := statement.(type) {
+	case *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement, *ast.ThrowStatement:
+		return true
+	case *ast.TryStatement:
+		return terminates(n.Body) && terminates(n.Catch.Body)
+	case *ast.ExpressionStatement:
+		switch expr := n.Expr.(type) {
+		case *ast.BlockExpression:
+			for _, inner := range expr.Statements {
+				if terminates(inner) {
+					return true
+				}
+			}
+		case *ast.IfExpression:
+			return expr.Alternative != nil && terminates(expr.Consequence) && terminates(expr.Alternative)
+		}
+	}
+	return false
+}
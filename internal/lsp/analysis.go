@@ -0,0 +1,587 @@
+package lsp
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// document 已打开的文档及其分析结果
+
+type document struct {
+	uri      string           // 文档URI
+	lines    []string         // 按行拆分的文档文本
+	program  *ast.Program     // 语法树，存在语法错误时为恢复模式下解析成功的语句
+	comments []*lexer.Comment // 文档中的注释
+	errs     []error          // 词法和语法错误，按出现顺序排列
+}
+
+// declaration 作用域中的一个声明
+
+type declaration struct {
+	kind     string                    // 声明类型："func"、"var"、"const"或"param"
+	name     *ast.IdentifierExpression // 声明的名称
+	function ast.Node                  // 函数声明，或参数所属的函数声明或函数表达式
+	posStart *util.Pos                 // 声明的起始位置
+}
+
+// parseDocument 以恢复模式解析文档文本，存在语法错误时保留其余解析成功的语句，用于悬停提示和文档符号
+//
+// 参数:
+//
+//	uri - 文档URI
+//	text - 文档的完整文本
+//
+// 返回值:
+//
+//	*document - 文档及其分析结果
+func parseDocument(uri, text string) *document {
+	doc := &document{uri: uri, lines: strings.Split(text, "\n")}
+	l := lexer.NewLexer(fileName(uri), text)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		doc.errs = []error{err}
+		doc.program = &ast.Program{}
+		return doc
+	}
+	p.Recover = true
+	doc.program = p.ParseProgram()
+	if doc.program == nil {
+		doc.program = p.PartialProgram()
+	}
+	doc.errs = p.Errors()
+	doc.comments = l.Comments
+	return doc
+}
+
+// fileName 从文档URI中取出文件名，用于错误信息
+//
+// 参数:
+//
+//	uri - 文档URI
+//
+// 返回值:
+//
+//	string - 文件名
+func fileName(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Path != "" {
+		return path.Base(u.Path)
+	}
+	return uri
+}
+
+// diagnostics 将词法或语法错误转换为编辑器诊断信息
+//
+// 返回值:
+//
+//	[]Diagnostic - 诊断信息列表，按出现顺序排列，没有错误时为空
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, err := range d.errs {
+		record := diagnostic.NewRecord(err)
+		diagnostics = append(diagnostics, Diagnostic{
+			Range: Range{
+				Start: d.position(record.StartLine, record.StartCol),
+				End:   d.position(record.EndLine, record.EndCol),
+			},
+			Severity: severityError,
+			Code:     record.Code,
+			Source:   "ghost",
+			Message:  record.Message,
+		})
+	}
+	return diagnostics
+}
+
+// hover 返回指定位置的标识符的签名和文档注释
+//
+// 参数:
+//
+//	pos - 编辑器中的位置
+//
+// 返回值:
+//
+//	*Hover - 悬停提示，位置上没有可解析的标识符时为nil
+func (d *document) hover(pos Position) *Hover {
+	row, col := d.sourcePosition(pos)
+	r := &resolver{row: row, col: col, scopes: []map[string]*declaration{{}}}
+	r.walkStatements(d.program.Statements)
+	if r.target == nil {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("```ghost\n")
+	if r.result == nil {
+		// 未在作用域中找到时查找内置函数
+		builtin, ok := object.Builtins[r.target.Name]
+		if !ok {
+			return nil
+		}
+		sb.WriteString(strings.TrimSuffix(builtin.String(), " {...}"))
+		sb.WriteString("\n```")
+	} else {
+		sb.WriteString(r.result.signature())
+		sb.WriteString("\n```")
+		if doc := d.docComment(r.result.posStart); doc != "" {
+			sb.WriteString("\n\n")
+			sb.WriteString(doc)
+		}
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: sb.String()},
+		Range:    &Range{Start: d.toPosition(r.target.PosStart), End: d.toPosition(r.target.PosEnd)},
+	}
+}
+
+// symbols 列出顶层的函数声明和变量声明
+//
+// 返回值:
+//
+//	[]DocumentSymbol - 文档符号列表，按在文档中出现的顺序排列
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, statement := range d.program.Statements {
+		switch s := statement.(type) {
+		case *ast.FunctionDeclarationStatement:
+			name, ok := s.Name.(*ast.IdentifierExpression)
+			if !ok {
+				continue
+			}
+			symbols = append(symbols, DocumentSymbol{
+				Name:           name.Name,
+				Detail:         functionSignature(s),
+				Kind:           symbolKindFunction,
+				Range:          Range{Start: d.toPosition(s.PosStart), End: d.toPosition(s.PosEnd)},
+				SelectionRange: Range{Start: d.toPosition(name.PosStart), End: d.toPosition(name.PosEnd)},
+			})
+		case *ast.ExpressionStatement:
+			init, ok := s.Expr.(*ast.VarInitializationExpression)
+			if !ok {
+				continue
+			}
+			name, ok := init.Name.(*ast.IdentifierExpression)
+			if !ok {
+				continue
+			}
+			kind := symbolKindVariable
+			if init.IsConst {
+				kind = symbolKindConstant
+			}
+			symbols = append(symbols, DocumentSymbol{
+				Name:           name.Name,
+				Kind:           kind,
+				Range:          Range{Start: d.toPosition(init.PosStart), End: d.toPosition(init.PosEnd)},
+				SelectionRange: Range{Start: d.toPosition(name.PosStart), End: d.toPosition(name.PosEnd)},
+			})
+		}
+	}
+	return symbols
+}
+
+// docComment 返回紧邻声明上方的连续注释，去除注释符号
+//
+// 参数:
+//
+//	posStart - 声明的起始位置
+//
+// 返回值:
+//
+//	string - 文档注释，没有时为空字符串
+func (d *document) docComment(posStart *util.Pos) string {
+	var lines []string
+	row := posStart.Row
+	// 注释按出现顺序记录，从后向前查找结束于上一行的注释
+	for i := len(d.comments) - 1; i >= 0; i-- {
+		comment := d.comments[i]
+		if comment.PosStart.Row >= posStart.Row {
+			continue
+		}
+		if commentEndRow(comment) != row-1 {
+			break
+		}
+		lines = append([]string{stripComment(comment.Text)}, lines...)
+		row = comment.PosStart.Row
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// commentEndRow 返回注释最后一个字符所在的行
+//
+// 参数:
+//
+//	comment - 注释
+//
+// 返回值:
+//
+//	int - 行号
+func commentEndRow(comment *lexer.Comment) int {
+	return comment.PosStart.Row + strings.Count(strings.TrimRight(comment.Text, "\n"), "\n")
+}
+
+// stripComment 去除注释符号和每行开头的空白
+//
+// 参数:
+//
+//	text - 注释的原始文本
+//
+// 返回值:
+//
+//	string - 注释内容
+func stripComment(text string) string {
+	text = strings.TrimRight(text, "\r\n")
+	if strings.HasPrefix(text, "//") {
+		return strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	}
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimSpace(line), "* ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// toPosition 将源代码位置转换为编辑器位置
+//
+// 参数:
+//
+//	pos - 源代码位置
+//
+// 返回值:
+//
+//	Position - 编辑器位置
+func (d *document) toPosition(pos *util.Pos) Position {
+	if pos == nil {
+		return Position{}
+	}
+	return d.position(pos.Row, pos.Col)
+}
+
+// position 将从1开始、以字符计数的行列号转换为编辑器位置
+//
+// 参数:
+//
+//	row - 行号
+//	col - 列号，以Unicode字符计数
+//
+// 返回值:
+//
+//	Position - 编辑器位置
+func (d *document) position(row, col int) Position {
+	line := max(row-1, 0)
+	if line >= len(d.lines) {
+		return Position{Line: line}
+	}
+	character := 0
+	count := 0
+	for _, r := range d.lines[line] {
+		if count >= col-1 {
+			break
+		}
+		character += utf16.RuneLen(r)
+		count++
+	}
+	return Position{Line: line, Character: character}
+}
+
+// sourcePosition 将编辑器位置转换为从1开始、以字符计数的行列号
+//
+// 参数:
+//
+//	pos - 编辑器位置
+//
+// 返回值:
+//
+//	int - 行号
+//	int - 列号
+func (d *document) sourcePosition(pos Position) (int, int) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return pos.Line + 1, 1
+	}
+	character := 0
+	col := 1
+	for _, r := range d.lines[pos.Line] {
+		if character >= pos.Character {
+			break
+		}
+		character += utf16.RuneLen(r)
+		col++
+	}
+	return pos.Line + 1, col
+}
+
+// signature 返回声明的签名
+//
+// 返回值:
+//
+//	string - 签名文本
+func (decl *declaration) signature() string {
+	switch decl.kind {
+	case "func":
+		return functionSignature(decl.function)
+	case "param":
+		return "param " + decl.name.Name + " // " + functionSignature(decl.function)
+	default:
+		return decl.kind + " " + decl.name.Name
+	}
+}
+
+// functionSignature 返回函数的签名，不包含函数体
+//
+// 参数:
+//
+//	fn - 函数声明或函数表达式
+//
+// 返回值:
+//
+//	string - 格式为func <name>(<params>)的签名，函数表达式没有函数名
+func functionSignature(fn ast.Node) string {
+	parameter, _ := ast.FunctionParts(fn)
+	params := make([]string, 0, len(parameter))
+	for _, param := range parameter {
+		params = append(params, param.String())
+	}
+	if declaration, ok := fn.(*ast.FunctionDeclarationStatement); ok {
+		return "func " + declaration.Name.String() + "(" + strings.Join(params, ", ") + ")"
+	}
+	return "func(" + strings.Join(params, ", ") + ")"
+}
+
+// resolver 按作用域遍历语法树，查找指定位置的标识符及其声明
+
+type resolver struct {
+	row    int                       // 目标行号
+	col    int                       // 目标列号
+	scopes []map[string]*declaration // 作用域栈，最后一个为最内层
+	target *ast.IdentifierExpression // 目标位置的标识符
+	result *declaration              // 标识符对应的声明，未找到时为nil
+}
+
+// declare 在最内层作用域中添加声明
+//
+// 参数:
+//
+//	decl - 声明
+func (r *resolver) declare(decl *declaration) {
+	r.scopes[len(r.scopes)-1][decl.name.Name] = decl
+}
+
+// lookup 从内向外查找名称对应的声明
+//
+// 参数:
+//
+//	name - 名称
+//
+// 返回值:
+//
+//	*declaration - 声明，未找到时为nil
+func (r *resolver) lookup(name string) *declaration {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if decl, ok := r.scopes[i][name]; ok {
+			return decl
+		}
+	}
+	return nil
+}
+
+// push 进入新的作用域
+func (r *resolver) push() {
+	r.scopes = append(r.scopes, map[string]*declaration{})
+}
+
+// pop 离开最内层作用域
+func (r *resolver) pop() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// visitIdentifier 检查标识符是否位于目标位置，是则解析其声明
+//
+// 参数:
+//
+//	ident - 标识符
+func (r *resolver) visitIdentifier(ident *ast.IdentifierExpression) {
+	if r.target != nil || ident == nil || ident.PosStart == nil || ident.PosEnd == nil {
+		return
+	}
+	if ident.PosStart.Row == r.row && ident.PosStart.Col <= r.col && r.col < ident.PosEnd.Col {
+		r.target = ident
+		r.result = r.lookup(ident.Name)
+	}
+}
+
+// walkStatements 依次遍历语句
+//
+// 参数:
+//
+//	statements - 语句列表
+func (r *resolver) walkStatements(statements []ast.Statement) {
+	for _, statement := range statements {
+		if r.target != nil {
+			return
+		}
+		r.walkStatement(statement)
+	}
+}
+
+// walkStatement 遍历语句
+//
+// 参数:
+//
+//	statement - 语句
+func (r *resolver) walkStatement(statement ast.Statement) {
+	switch s := statement.(type) {
+	case *ast.ExpressionStatement:
+		r.walkExpression(s.Expr)
+	case *ast.ReturnStatement:
+		r.walkExpression(s.ReturnValue)
+	case *ast.ForStatement:
+		r.push()
+		r.walkStatement(s.Initialization)
+		r.walkExpression(s.Condition)
+		r.walkStatement(s.Update)
+		r.walkStatement(s.Body)
+		r.pop()
+	case *ast.ForInStatement:
+		r.walkExpression(s.Iterable)
+		r.push()
+		r.declare(&declaration{kind: "var", name: s.Variable, posStart: s.PosStart})
+		r.visitIdentifier(s.Variable)
+		if s.ValueVariable != nil {
+			r.declare(&declaration{kind: "var", name: s.ValueVariable, posStart: s.PosStart})
+			r.visitIdentifier(s.ValueVariable)
+		}
+		r.walkStatement(s.Body)
+		r.pop()
+	case *ast.WhileStatement:
+		r.push()
+		r.walkExpression(s.Condition)
+		r.walkStatement(s.Body)
+		r.pop()
+	case *ast.TryStatement:
+		r.push()
+		r.walkStatement(s.Body)
+		r.pop()
+		r.push()
+		r.declare(&declaration{kind: "var", name: s.Catch.Variable, posStart: s.Catch.PosStart})
+		r.visitIdentifier(s.Catch.Variable)
+		r.walkStatement(s.Catch.Body)
+		r.pop()
+	case *ast.ThrowStatement:
+		r.walkExpression(s.Value)
+	case *ast.FunctionDeclarationStatement:
+		name, ok := s.Name.(*ast.IdentifierExpression)
+		if !ok {
+			return
+		}
+		// 先声明函数名，使函数体中的递归调用可以被解析
+		r.declare(&declaration{kind: "func", name: name, function: s, posStart: s.PosStart})
+		r.visitIdentifier(name)
+		r.walkFunction(s, s.PosStart)
+	}
+}
+
+// walkFunction 在函数自己的作用域中遍历函数的参数和函数体
+//
+// 参数:
+//
+//	fn - 函数声明或函数表达式
+//	posStart - 函数的起始位置
+func (r *resolver) walkFunction(fn ast.Node, posStart *util.Pos) {
+	parameter, body := ast.FunctionParts(fn)
+	r.push()
+	for _, param := range parameter {
+		r.walkExpression(param.DefaultValue)
+		r.declare(&declaration{kind: "param", name: param.Name, function: fn, posStart: posStart})
+		r.visitIdentifier(param.Name)
+	}
+	r.walkStatement(body)
+	r.pop()
+}
+
+// walkExpression 遍历表达式
+//
+// 参数:
+//
+//	expression - 表达式
+func (r *resolver) walkExpression(expression ast.Expression) {
+	if r.target != nil {
+		return
+	}
+	switch e := expression.(type) {
+	case *ast.IdentifierExpression:
+		r.visitIdentifier(e)
+	case *ast.VarInitializationExpression:
+		r.walkExpression(e.Value)
+		if name, ok := e.Name.(*ast.IdentifierExpression); ok {
+			kind := "var"
+			if e.IsConst {
+				kind = "const"
+			}
+			r.declare(&declaration{kind: kind, name: name, posStart: e.PosStart})
+			r.visitIdentifier(name)
+		}
+	case *ast.VarAssignmentExpression:
+		r.walkExpression(e.Name)
+		r.walkExpression(e.Value)
+	case *ast.CompoundAssignmentExpression:
+		r.walkExpression(e.Name)
+		r.walkExpression(e.Right)
+	case *ast.PrefixExpression:
+		r.walkExpression(e.Value)
+	case *ast.InfixExpression:
+		r.walkExpression(e.Left)
+		r.walkExpression(e.Right)
+	case *ast.PrefixUnaryIncDecExpression:
+		r.walkExpression(e.Right)
+	case *ast.PostfixUnaryIncDecExpression:
+		r.walkExpression(e.Left)
+	case *ast.GroupedExpression:
+		r.walkExpression(e.Expr)
+	case *ast.ListExpression:
+		for _, element := range e.Value {
+			r.walkExpression(element)
+		}
+	case *ast.InterpolatedStringExpression:
+		for _, part := range e.Parts {
+			r.walkExpression(part)
+		}
+	case *ast.MapExpression:
+		for _, entry := range e.Entries {
+			r.walkExpression(entry.Key)
+			r.walkExpression(entry.Value)
+		}
+	case *ast.BlockExpression:
+		r.push()
+		r.walkStatements(e.Statements)
+		r.pop()
+	case *ast.IfExpression:
+		r.walkExpression(e.Condition)
+		r.walkStatement(e.Consequence)
+		r.walkStatement(e.Alternative)
+	case *ast.TernaryExpression:
+		r.walkExpression(e.Condition)
+		r.walkExpression(e.Consequence)
+		r.walkExpression(e.Alternative)
+	case *ast.FunctionExpression:
+		r.walkFunction(e, e.PosStart)
+	case *ast.CallExpression:
+		r.walkExpression(e.Function)
+		for _, arg := range e.Argument {
+			r.walkExpression(arg)
+		}
+	case *ast.IndexExpression:
+		r.walkExpression(e.Target)
+		r.walkExpression(e.Index)
+	case *ast.SliceExpression:
+		r.walkExpression(e.Target)
+		r.walkExpression(e.Low)
+		r.walkExpression(e.High)
+	}
+}
@@ -0,0 +1,112 @@
+package lsp
+
+import (
+	"testing"
+)
+
+func TestDocument_Hover(t *testing.T) {
+	source := "var x = 1;\n" +
+		"func f(x) {\n" +
+		"    var y = x;\n" +
+		"    {\n" +
+		"        const x = \"内\";\n" +
+		"        x;\n" +
+		"    };\n" +
+		"};\n" +
+		"var s = \"😀\"; x;\n" +
+		"unknown;\n" +
+		"var g = func(z=1) z;\n"
+
+	tests := []struct {
+		name     string
+		position Position
+		excepted string
+	}{
+		{
+			name:     "Global Variable",
+			position: Position{Line: 0, Character: 4},
+			excepted: "```ghost\nvar x\n```",
+		},
+		{
+			name:     "Parameter Shadows Global",
+			position: Position{Line: 2, Character: 12},
+			excepted: "```ghost\nparam x // func f(x)\n```",
+		},
+		{
+			name:     "Block Constant Shadows Parameter",
+			position: Position{Line: 5, Character: 8},
+			excepted: "```ghost\nconst x\n```",
+		},
+		{
+			name:     "Scope Ends With Block",
+			position: Position{Line: 8, Character: 14},
+			excepted: "```ghost\nvar x\n```",
+		},
+		{
+			name:     "Parameter Of Function Expression",
+			position: Position{Line: 10, Character: 18},
+			excepted: "```ghost\nparam z // func(z=1)\n```",
+		},
+		{
+			name:     "Unknown Identifier",
+			position: Position{Line: 9, Character: 0},
+		},
+		{
+			name:     "Not An Identifier",
+			position: Position{Line: 0, Character: 8},
+		},
+	}
+
+	doc := parseDocument("file:///tmp/hover.gh", source)
+	if len(doc.errs) != 0 {
+		t.Fatalf("unexpected parse errors: %v", doc.errs)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hover := doc.hover(tt.position)
+			got := ""
+			if hover != nil {
+				got = hover.Contents.Value
+			}
+			if got != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestDocument_Position(t *testing.T) {
+	doc := parseDocument("file:///tmp/position.gh", "var s = \"😀\"; var t = 1;\n")
+	tests := []struct {
+		name     string
+		row      int
+		col      int
+		excepted Position
+	}{
+		{
+			name:     "Before Surrogate Pair",
+			row:      1,
+			col:      10,
+			excepted: Position{Line: 0, Character: 9},
+		},
+		{
+			name:     "After Surrogate Pair",
+			row:      1,
+			col:      11,
+			excepted: Position{Line: 0, Character: 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := doc.position(tt.row, tt.col)
+			if got != tt.excepted {
+				t.Errorf("excepted %+v, got %+v", tt.excepted, got)
+			}
+			row, col := doc.sourcePosition(got)
+			if row != tt.row || col != tt.col {
+				t.Errorf("excepted %d:%d, got %d:%d", tt.row, tt.col, row, col)
+			}
+		})
+	}
+}
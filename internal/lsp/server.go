@@ -0,0 +1,231 @@
+// Package lsp 实现Ghost语言的语言服务器，通过标准输入输出与编辑器通信
+// 支持诊断信息、悬停提示和文档符号
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// Server 语言服务器，保存已打开文档的分析结果
+
+type Server struct {
+	reader    *bufio.Reader        // 消息输入
+	writer    io.Writer            // 消息输出
+	documents map[string]*document // 已打开的文档，键为文档URI
+	shutdown  bool                 // 是否已收到shutdown请求
+}
+
+// NewServer 创建语言服务器
+//
+// 参数:
+//
+//	in - 消息输入，通常为标准输入
+//	out - 消息输出，通常为标准输出
+//
+// 返回值:
+//
+//	*Server - 语言服务器
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		documents: make(map[string]*document),
+	}
+}
+
+// Run 循环读取并处理消息，直到收到exit通知或输入结束
+//
+// 返回值:
+//
+//	int - 进程退出码，收到shutdown请求后退出时为0，否则为1
+func (s *Server) Run() int {
+	for {
+		body, err := s.readMessage()
+		if err != nil {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.replyError(nil, codeParseError, "invalid JSON message.")
+			continue
+		}
+		if req.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		s.handle(&req)
+	}
+}
+
+// handle 分发单个请求或通知
+//
+// 参数:
+//
+//	req - 请求或通知
+func (s *Server) handle(req *request) {
+	switch req.Method {
+	case "initialize":
+		s.reply(req.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":       textDocumentSyncFull,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+			},
+			"serverInfo": map[string]any{
+				"name": "ghost-lang",
+			},
+		})
+	case "initialized":
+	case "shutdown":
+		s.shutdown = true
+		s.reply(req.ID, nil)
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.update(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if json.Unmarshal(req.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			// 完整文本同步，最后一次变更即为文档的最新内容
+			s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			delete(s.documents, params.TextDocument.URI)
+			s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+				URI:         params.TextDocument.URI,
+				Diagnostics: []Diagnostic{},
+			})
+		}
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.replyError(req.ID, codeInvalidParams, "invalid hover params.")
+			return
+		}
+		doc, ok := s.documents[params.TextDocument.URI]
+		if !ok {
+			s.reply(req.ID, nil)
+			return
+		}
+		if hover := doc.hover(params.Position); hover != nil {
+			s.reply(req.ID, hover)
+		} else {
+			s.reply(req.ID, nil)
+		}
+	case "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.replyError(req.ID, codeInvalidParams, "invalid documentSymbol params.")
+			return
+		}
+		symbols := []DocumentSymbol{}
+		if doc, ok := s.documents[params.TextDocument.URI]; ok {
+			symbols = doc.symbols()
+		}
+		s.reply(req.ID, symbols)
+	default:
+		// 未知的通知直接忽略，未知的请求返回错误
+		if req.ID != nil {
+			s.replyError(req.ID, codeMethodNotFound, "method not found: "+req.Method+".")
+		}
+	}
+}
+
+// update 更新文档内容，重新分析并发布诊断信息
+//
+// 参数:
+//
+//	uri - 文档URI
+//	text - 文档的完整文本
+func (s *Server) update(uri, text string) {
+	doc := parseDocument(uri, text)
+	s.documents[uri] = doc
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: doc.diagnostics(),
+	})
+}
+
+// readMessage 读取一条带Content-Length头的消息
+//
+// 返回值:
+//
+//	[]byte - 消息体
+//	error - 读取失败或输入结束时的错误
+func (s *Server) readMessage() ([]byte, error) {
+	header, err := textproto.NewReader(s.reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// write 以Content-Length头加JSON消息体的形式写出一条消息
+//
+// 参数:
+//
+//	message - 要写出的消息
+func (s *Server) write(message any) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// reply 发送成功响应
+//
+// 参数:
+//
+//	id - 请求ID
+//	result - 响应结果，nil时发送null
+func (s *Server) reply(id json.RawMessage, result any) {
+	s.write(&response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// replyError 发送错误响应
+//
+// 参数:
+//
+//	id - 请求ID，无法确定时为nil
+//	code - 错误码
+//	message - 错误信息
+func (s *Server) replyError(id json.RawMessage, code int, message string) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.write(&errorResponse{JSONRPC: "2.0", ID: id, Error: &responseError{Code: code, Message: message}})
+}
+
+// notify 发送通知
+//
+// 参数:
+//
+//	method - 通知方法名
+//	params - 通知参数
+func (s *Server) notify(method string, params any) {
+	s.write(&notification{JSONRPC: "2.0", Method: method, Params: params})
+}
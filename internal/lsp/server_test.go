@@ -0,0 +1,257 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// encodeMessages 将消息编码为带Content-Length头的输入流
+func encodeMessages(t *testing.T, messages ...map[string]any) io.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, message := range messages {
+		message["jsonrpc"] = "2.0"
+		body, err := json.Marshal(message)
+		if err != nil {
+			t.Fatalf("failed to encode message: %+v", err)
+		}
+		_, _ = fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	return &buf
+}
+
+// decodeMessages 解析服务器输出的所有消息
+func decodeMessages(t *testing.T, output []byte) []map[string]any {
+	t.Helper()
+	var messages []map[string]any
+	reader := bufio.NewReader(bytes.NewReader(output))
+	for {
+		header, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatalf("failed to read header: %+v", err)
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			t.Fatalf("invalid Content-Length: %+v", err)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			t.Fatalf("failed to read body: %+v", err)
+		}
+		var message map[string]any
+		if err := json.Unmarshal(body, &message); err != nil {
+			t.Fatalf("failed to decode body %s: %+v", body, err)
+		}
+		messages = append(messages, message)
+	}
+}
+
+// findResponse 查找指定ID的响应
+func findResponse(t *testing.T, messages []map[string]any, id float64) map[string]any {
+	t.Helper()
+	for _, message := range messages {
+		if message["id"] == id {
+			return message
+		}
+	}
+	t.Fatalf("response %v not found in %+v", id, messages)
+	return nil
+}
+
+// findDiagnostics 按顺序返回所有publishDiagnostics通知中的诊断信息
+func findDiagnostics(messages []map[string]any) [][]any {
+	var published [][]any
+	for _, message := range messages {
+		if message["method"] == "textDocument/publishDiagnostics" {
+			params := message["params"].(map[string]any)
+			published = append(published, params["diagnostics"].([]any))
+		}
+	}
+	return published
+}
+
+func TestServer_Session(t *testing.T) {
+	const uri = "file:///tmp/main.gh"
+	source := "// add 返回两数之和\nfunc add(a, b=1) {\n    return a + b;\n};\n\nconst LIMIT = 10;\nvar total = add(LIMIT, 2);\nprintln(total);\n"
+	input := encodeMessages(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "ghost", "version": 1, "text": "var x = (1 + 2;\n"},
+		}},
+		map[string]any{"method": "textDocument/didChange", "params": map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": 2},
+			"contentChanges": []any{map[string]any{"text": source}},
+		}},
+		map[string]any{"id": 2, "method": "textDocument/hover", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 6, "character": 13},
+		}},
+		map[string]any{"id": 3, "method": "textDocument/hover", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 2, "character": 15},
+		}},
+		map[string]any{"id": 4, "method": "textDocument/hover", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 7, "character": 2},
+		}},
+		map[string]any{"id": 5, "method": "textDocument/documentSymbol", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+		}},
+		map[string]any{"id": 6, "method": "textDocument/unknown", "params": map[string]any{}},
+		map[string]any{"id": 7, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+	var output bytes.Buffer
+	if code := NewServer(input, &output).Run(); code != 0 {
+		t.Errorf("excepted exit code 0, got %d", code)
+	}
+	messages := decodeMessages(t, output.Bytes())
+
+	capabilities := findResponse(t, messages, 1)["result"].(map[string]any)["capabilities"].(map[string]any)
+	if capabilities["hoverProvider"] != true || capabilities["documentSymbolProvider"] != true {
+		t.Errorf("unexpected capabilities %+v", capabilities)
+	}
+
+	published := findDiagnostics(messages)
+	if len(published) != 2 {
+		t.Fatalf("excepted 2 publishDiagnostics notifications, got %d", len(published))
+	}
+	exceptedDiagnostic := map[string]any{
+		"range": map[string]any{
+			"start": map[string]any{"line": 0.0, "character": 8.0},
+			"end":   map[string]any{"line": 0.0, "character": 9.0},
+		},
+		"severity": 1.0,
+		"code":     "E2003",
+		"source":   "ghost",
+		"message":  `unclosed "(" opened at line 1, column 9.`,
+	}
+	if !reflect.DeepEqual(published[0], []any{exceptedDiagnostic}) {
+		t.Errorf("excepted %+v, got %+v", []any{exceptedDiagnostic}, published[0])
+	}
+	if len(published[1]) != 0 {
+		t.Errorf("excepted diagnostics to be cleared, got %+v", published[1])
+	}
+
+	hovers := []struct {
+		id       float64
+		excepted string
+	}{
+		{id: 2, excepted: "```ghost\nfunc add(a, b=1)\n```\n\nadd 返回两数之和"},
+		{id: 3, excepted: "```ghost\nparam b // func add(a, b=1)\n```\n\nadd 返回两数之和"},
+		{id: 4, excepted: "```ghost\nbuiltin func println(a)\n```"},
+	}
+	for _, hover := range hovers {
+		result := findResponse(t, messages, hover.id)["result"].(map[string]any)
+		got := result["contents"].(map[string]any)["value"]
+		if got != hover.excepted {
+			t.Errorf("hover %v: excepted %q, got %q", hover.id, hover.excepted, got)
+		}
+	}
+
+	symbols := findResponse(t, messages, 5)["result"].([]any)
+	var names []string
+	for _, symbol := range symbols {
+		s := symbol.(map[string]any)
+		names = append(names, fmt.Sprintf("%s:%v", s["name"], s["kind"]))
+	}
+	if !reflect.DeepEqual(names, []string{"add:12", "LIMIT:14", "total:13"}) {
+		t.Errorf("unexpected symbols %v", names)
+	}
+
+	if _, ok := findResponse(t, messages, 6)["error"]; !ok {
+		t.Errorf("excepted error response for unknown method")
+	}
+	if result, ok := findResponse(t, messages, 7)["result"]; !ok || result != nil {
+		t.Errorf("excepted null result for shutdown, got %+v", result)
+	}
+}
+
+func TestServer_SyntaxErrors(t *testing.T) {
+	const uri = "file:///tmp/broken.gh"
+	source := "// add 返回两数之和\nfunc add(a, b=1) {\n    return a + b;\n};\n\nvar total = add(1, 2);\nvar broken = ;\nconst LIMIT = 10;\nprintln(total +"
+	input := encodeMessages(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "ghost", "version": 1, "text": source},
+		}},
+		map[string]any{"id": 2, "method": "textDocument/hover", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 5, "character": 13},
+		}},
+		map[string]any{"id": 3, "method": "textDocument/hover", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 7, "character": 6},
+		}},
+		map[string]any{"id": 4, "method": "textDocument/documentSymbol", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+		}},
+		map[string]any{"id": 5, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+	var output bytes.Buffer
+	if code := NewServer(input, &output).Run(); code != 0 {
+		t.Errorf("excepted exit code 0, got %d", code)
+	}
+	messages := decodeMessages(t, output.Bytes())
+
+	published := findDiagnostics(messages)
+	if len(published) != 1 {
+		t.Fatalf("excepted 1 publishDiagnostics notification, got %d", len(published))
+	}
+	var lines []float64
+	for _, d := range published[0] {
+		start := d.(map[string]any)["range"].(map[string]any)["start"].(map[string]any)
+		lines = append(lines, start["line"].(float64))
+	}
+	if !reflect.DeepEqual(lines, []float64{6, 8}) {
+		t.Errorf("excepted diagnostics on lines [6 8], got %v", lines)
+	}
+
+	hovers := []struct {
+		id       float64
+		excepted string
+	}{
+		{id: 2, excepted: "```ghost\nfunc add(a, b=1)\n```\n\nadd 返回两数之和"},
+		{id: 3, excepted: "```ghost\nconst LIMIT\n```"},
+	}
+	for _, hover := range hovers {
+		result, ok := findResponse(t, messages, hover.id)["result"].(map[string]any)
+		if !ok {
+			t.Errorf("hover %v: excepted a result, got null", hover.id)
+			continue
+		}
+		got := result["contents"].(map[string]any)["value"]
+		if got != hover.excepted {
+			t.Errorf("hover %v: excepted %q, got %q", hover.id, hover.excepted, got)
+		}
+	}
+
+	symbols := findResponse(t, messages, 4)["result"].([]any)
+	var names []string
+	for _, symbol := range symbols {
+		s := symbol.(map[string]any)
+		names = append(names, fmt.Sprintf("%s:%v", s["name"], s["kind"]))
+	}
+	if !reflect.DeepEqual(names, []string{"add:12", "total:13", "LIMIT:14"}) {
+		t.Errorf("unexpected symbols %v", names)
+	}
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	input := encodeMessages(t, map[string]any{"method": "exit"})
+	if code := NewServer(input, io.Discard).Run(); code != 1 {
+		t.Errorf("excepted exit code 1, got %d", code)
+	}
+}
@@ -3,6 +3,7 @@ package object
 import (
 	"fmt"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -14,6 +15,30 @@ type Bool struct {
 	Value bool // 布尔值的实际值
 }
 
+// TrueObj 和 FalseObj 是所有布尔结果共用的实例
+// 布尔值不会被原地修改，比较和逻辑运算直接返回这两个实例，不再为每次结果分配内存
+
+var (
+	TrueObj  = &Bool{Value: true}
+	FalseObj = &Bool{Value: false}
+)
+
+// BoolOf 返回与Go布尔值对应的共享实例
+//
+// 参数:
+//
+//	value - Go布尔值
+//
+// 返回值:
+//
+//	*Bool - TrueObj或FalseObj
+func BoolOf(value bool) *Bool {
+	if value {
+		return TrueObj
+	}
+	return FalseObj
+}
+
 // Type 返回值的类型
 //
 // 返回值:
@@ -46,6 +71,7 @@ func (b *Bool) String() string {
 //	error - 可能出现的错误
 func (b *Bool) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -67,6 +93,7 @@ func (b *Bool) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object,
 //	error - 可能出现的错误
 func (b *Bool) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -84,10 +111,10 @@ func (b *Bool) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, e
 //
 // 返回值:
 //
-//	包含否定值的新Bool实例；无错误
+//	否定值对应的共享Bool实例；无错误
 func (b *Bool) Not(*util.Pos, *util.Pos, *frame.Frame) (Object, error) {
 	// 逻辑非运算: 返回当前布尔值的否定
-	return &Bool{Value: !b.Value}, nil
+	return BoolOf(!b.Value), nil
 }
 
 // Add 对值进行加法运算
@@ -105,6 +132,7 @@ func (b *Bool) Not(*util.Pos, *util.Pos, *frame.Frame) (Object, error) {
 //	error - 可能出现的错误
 func (b *Bool) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"+\".",
 		PosStart: posStart,
@@ -127,6 +155,7 @@ func (b *Bool) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (b *Bool) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -149,6 +178,7 @@ func (b *Bool) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	error - 可能出现的错误
 func (b *Bool) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"*\".",
 		PosStart: posStart,
@@ -171,6 +201,7 @@ func (b *Bool) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	error - 可能出现的错误
 func (b *Bool) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"/\".",
 		PosStart: posStart,
@@ -193,6 +224,7 @@ func (b *Bool) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (b *Bool) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"%\".",
 		PosStart: posStart,
@@ -216,10 +248,10 @@ func (b *Bool) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, erro
 	switch o := other.(type) {
 	case *Bool:
 		// 布尔值 == 布尔值: 直接比较值
-		return &Bool{Value: b.Value == o.Value}, nil
+		return BoolOf(b.Value == o.Value), nil
 	default:
 		// 与其他类型比较：返回false
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	}
 }
 
@@ -239,10 +271,10 @@ func (b *Bool) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, e
 	switch o := other.(type) {
 	case *Bool:
 		// 布尔值 != 布尔值: 直接比较值
-		return &Bool{Value: b.Value != o.Value}, nil
+		return BoolOf(b.Value != o.Value), nil
 	default:
 		// 与其他类型比较：返回true
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	}
 }
 
@@ -260,6 +292,7 @@ func (b *Bool) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, e
 //	Object - 比较结果
 func (b *Bool) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<\".",
 		PosStart: posStart,
@@ -281,6 +314,7 @@ func (b *Bool) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	Object - 比较结果
 func (b *Bool) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">\".",
 		PosStart: posStart,
@@ -302,6 +336,7 @@ func (b *Bool) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	Object - 比较结果
 func (b *Bool) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<=\".",
 		PosStart: posStart,
@@ -323,6 +358,7 @@ func (b *Bool) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *fram
 //	Object - 比较结果
 func (b *Bool) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">=\".",
 		PosStart: posStart,
@@ -345,6 +381,7 @@ func (b *Bool) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *f
 //	error - 可能出现的错误
 func (b *Bool) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -367,6 +404,7 @@ func (b *Bool) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (b *Bool) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -389,6 +427,7 @@ func (b *Bool) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (
 //	error - 可能出现的错误
 func (b *Bool) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -411,6 +450,7 @@ func (b *Bool) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (b *Bool) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -433,6 +473,7 @@ func (b *Bool) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fram
 //	error - 可能出现的错误
 func (b *Bool) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -456,10 +497,11 @@ func (b *Bool) And(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 	// 逻辑与运算: 仅支持布尔值与布尔值的运算
 	if o, ok := other.(*Bool); ok {
 		// 两个布尔值都为true时结果为true，否则为false
-		return &Bool{Value: b.Value && o.Value}, nil
+		return BoolOf(b.Value && o.Value), nil
 	} else {
 		// 不支持的操作数类型
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"&&\".",
 			PosStart: posStart,
@@ -484,10 +526,11 @@ func (b *Bool) Or(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 	// 逻辑或运算: 仅支持布尔值与布尔值的运算
 	if o, ok := other.(*Bool); ok {
 		// 两个布尔值任意一个为true时结果为true，否则为false
-		return &Bool{Value: b.Value || o.Value}, nil
+		return BoolOf(b.Value || o.Value), nil
 	} else {
 		// 不支持的操作数类型
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"||\".",
 			PosStart: posStart,
@@ -510,9 +553,19 @@ func (b *Bool) Or(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (b *Bool) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "index expression not supported for this type.",
 		PosStart: posStart,
 		PosEnd:   posEnd,
 	}
 }
+
+// HashKey 返回值作为映射键时的可哈希表示
+//
+// 返回值:
+//
+//	HashKey - 可哈希表示
+func (b *Bool) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Value: b.Value}
+}
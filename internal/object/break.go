@@ -0,0 +1,530 @@
+package object
+
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// BreakSignal break语句的执行结果，沿块和if表达式向外传递，由最近的循环语句接收后结束循环
+// 与ReturnValue类似，只在解释器内部传递，不会成为变量的值
+
+type BreakSignal struct{}
+
+// BreakObj 是所有break语句共用的实例，break信号没有状态，不需要分别分配
+
+var BreakObj = &BreakSignal{}
+
+// Type 返回值的类型
+//
+// 返回值:
+//
+//	string - 值的类型
+func (bs *BreakSignal) Type() string {
+	return "BreakSignal"
+}
+
+// String 返回值的字符串表示
+//
+// 返回值:
+//
+//	string - 格式化的字符串表示
+func (bs *BreakSignal) String() string {
+	return "break"
+}
+
+// Negative 对值进行负运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitNot 对值进行按位非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"~\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Not 对值进行逻辑非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"!\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Add 对值进行加法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"+\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Subtract 对值进行减法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Multiply 对值进行乘法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"*\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Divide 对值进行除法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"/\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Mod 对值进行取模运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"%\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Equal 判断当前空值与另一个值是否相等
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Equal(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"==\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// NotEqual 判断当前空值与另一个值是否不相等
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) NotEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"!=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LessThan 对值进行小于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (bs *BreakSignal) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThan 对值进行大于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (bs *BreakSignal) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LessThanOrEqual 对值进行小于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (bs *BreakSignal) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThanOrEqual 对值进行大于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (bs *BreakSignal) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitAnd 对值进行按位与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitOr 对值进行按位或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"|\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Xor 对值进行异或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"^\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LeftShift 对值进行左移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// RightShift 对值进行右移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">>\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// And 对值进行逻辑与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Or 对值进行逻辑或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"||\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Index 执行索引运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (bs *BreakSignal) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &TypeError{
+		Code:     errcode.TypeError,
+		Frame:    frame,
+		Message:  "index expression not supported for this type.",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
@@ -1,25 +1,113 @@
 package object
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"unicode/utf8"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
 
+// Interpreter 解释器接口，供内置函数回调函数对象
+// 由evaluator.Evaluator实现
+
+type Interpreter interface {
+	// CallFunction 使用已求值的参数调用函数对象
+	//
+	// 参数:
+	//
+	//  function - 要调用的函数对象
+	//  args - 按位置传入的参数
+	//  posStart - 调用的起始位置
+	//  posEnd - 调用的结束位置
+	//
+	// 返回值:
+	//
+	//  Object - 函数的返回值
+	//  error - 可能出现的错误
+	CallFunction(function Object, args []Object, posStart, posEnd *util.Pos) (Object, error)
+
+	// EvalSource 在调用者的环境中解析并执行一段源代码
+	//
+	// 参数:
+	//
+	//  source - 要执行的源代码
+	//  posStart - 调用的起始位置
+	//  posEnd - 调用的结束位置
+	//
+	// 返回值:
+	//
+	//  Object - 最后一条表达式语句的值，没有时为null
+	//  error - 解析或执行过程中的错误
+	EvalSource(source string, posStart, posEnd *util.Pos) (Object, error)
+
+	// Stdout 返回print、println等内置函数使用的标准输出
+	//
+	// 返回值:
+	//
+	//  io.Writer - 标准输出
+	Stdout() io.Writer
+
+	// CheckAlloc 检查内置函数即将创建的字符串或列表的大小是否超出解释器的限制
+	//
+	// 参数:
+	//
+	//  size - 字节数，按AllocSize的方式计算
+	//  posStart - 调用的起始位置
+	//  posEnd - 调用的结束位置
+	//
+	// 返回值:
+	//
+	//  error - 超出限制时的错误
+	CheckAlloc(size int64, posStart, posEnd *util.Pos) error
+
+	// Stderr 返回内置函数使用的标准错误输出
+	//
+	// 返回值:
+	//
+	//  io.Writer - 标准错误输出
+	Stderr() io.Writer
+}
+
 // BuiltinFunction 表示内建函数类型，实现了Object接口
 // 支持的操作包括调用函数等
 
 type BuiltinFunction struct {
-	Name         string                                                                           // 函数名
-	Parameter    []string                                                                         // 参数名
-	DefaultValue []Object                                                                         // 默认参数值
-	Fn           func(f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) // 函数体
+	Name         string                                                                                           // 函数名
+	Parameter    []string                                                                                         // 参数名
+	DefaultValue []Object                                                                                         // 默认参数值
+	Capability   Capability                                                                                       // 访问宿主系统的能力，为0时在沙箱中可用
+	Fn           func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) // 函数体
 }
 
+// Capability 内置函数访问宿主系统的能力标记，可以按位组合
+// 沙箱模式下带有任意能力标记的内置函数被替换为产生沙箱错误的桩函数
+
+type Capability uint
+
+// 内置函数的能力标记
+
+const (
+	CapProcess Capability = 1 << iota // 控制当前进程，如exit
+	CapArgs                           // 读取命令行参数，如args
+	CapEnv                            // 读取或修改环境变量
+	CapFile                           // 读写文件系统
+	CapNet                            // 访问网络
+	CapExec                           // 执行外部命令
+)
+
+// noValue 表示未传入的可选参数，通过指针比较判断
+var noValue = &Null{}
+
 // Type 返回值的类型
 //
 // 返回值:
@@ -30,27 +118,27 @@ func (bf *BuiltinFunction) Type() string {
 }
 
 // String 返回值的字符串表示
+// 格式为：builtin func <函数名>(<参数>...) {...}，可选参数以"?"标记
 //
 // 返回值:
 //
 //	string - 格式化的字符串表示
 func (bf *BuiltinFunction) String() string {
-	var sb strings.Builder
-	sb.WriteString("func ")
-	sb.WriteString(bf.Name)
-	sb.WriteString("(")
+	params := make([]string, 0, len(bf.Parameter))
 	for i, param := range bf.Parameter {
-		sb.WriteString(param)
-		if bf.DefaultValue[i] != nil {
-			sb.WriteString("=")
-			sb.WriteString(bf.DefaultValue[i].String())
+		// 未声明默认值的参数只输出参数名
+		if i >= len(bf.DefaultValue) || bf.DefaultValue[i] == nil {
+			params = append(params, param)
+			continue
 		}
-		if i < len(bf.Parameter)-1 {
-			sb.WriteString(", ")
+		// 可选参数没有可展示的默认值
+		if bf.DefaultValue[i] == noValue {
+			params = append(params, param+"?")
+			continue
 		}
+		params = append(params, param+"="+bf.DefaultValue[i].String())
 	}
-	sb.WriteString(") { [builtin code] }")
-	return sb.String()
+	return "builtin " + formatSignature(bf.Name, params) + " {...}"
 }
 
 // Negative 对值进行负运算
@@ -67,6 +155,7 @@ func (bf *BuiltinFunction) String() string {
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -88,6 +177,7 @@ func (bf *BuiltinFunction) Negative(posStart, posEnd *util.Pos, frame *frame.Fra
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -109,6 +199,7 @@ func (bf *BuiltinFunction) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -131,6 +222,7 @@ func (bf *BuiltinFunction) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"+\".",
 		PosStart: posStart,
@@ -153,6 +245,7 @@ func (bf *BuiltinFunction) Add(_ Object, posStart, posEnd *util.Pos, frame *fram
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -175,6 +268,7 @@ func (bf *BuiltinFunction) Subtract(_ Object, posStart, posEnd *util.Pos, frame
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"*\".",
 		PosStart: posStart,
@@ -197,6 +291,7 @@ func (bf *BuiltinFunction) Multiply(_ Object, posStart, posEnd *util.Pos, frame
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"/\".",
 		PosStart: posStart,
@@ -219,6 +314,7 @@ func (bf *BuiltinFunction) Divide(_ Object, posStart, posEnd *util.Pos, frame *f
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"%\".",
 		PosStart: posStart,
@@ -245,9 +341,9 @@ func (bf *BuiltinFunction) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (
 	// 函数相等比较规则: 比较引用是否相等
 	otherFunc, ok := other.(*BuiltinFunction)
 	if !ok {
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	}
-	return &Bool{Value: bf == otherFunc}, nil
+	return BoolOf(bf == otherFunc), nil
 }
 
 // NotEqual 判断当前函数与另一个值是否不相等
@@ -269,9 +365,9 @@ func (bf *BuiltinFunction) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame
 	// 函数不等比较规则: 比较引用是否不等
 	otherFunc, ok := other.(*BuiltinFunction)
 	if !ok {
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	}
-	return &Bool{Value: bf != otherFunc}, nil
+	return BoolOf(bf != otherFunc), nil
 }
 
 // LessThan 对值进行小于比较
@@ -288,6 +384,7 @@ func (bf *BuiltinFunction) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame
 //	Object - 比较结果
 func (bf *BuiltinFunction) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<\".",
 		PosStart: posStart,
@@ -309,6 +406,7 @@ func (bf *BuiltinFunction) LessThan(_ Object, posStart, posEnd *util.Pos, frame
 //	Object - 比较结果
 func (bf *BuiltinFunction) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">\".",
 		PosStart: posStart,
@@ -330,6 +428,7 @@ func (bf *BuiltinFunction) GreaterThan(_ Object, posStart, posEnd *util.Pos, fra
 //	Object - 比较结果
 func (bf *BuiltinFunction) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<=\".",
 		PosStart: posStart,
@@ -351,6 +450,7 @@ func (bf *BuiltinFunction) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos,
 //	Object - 比较结果
 func (bf *BuiltinFunction) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">=\".",
 		PosStart: posStart,
@@ -373,6 +473,7 @@ func (bf *BuiltinFunction) GreaterThanOrEqual(_ Object, posStart, posEnd *util.P
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -395,6 +496,7 @@ func (bf *BuiltinFunction) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *f
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -417,6 +519,7 @@ func (bf *BuiltinFunction) BitOr(_ Object, posStart, posEnd *util.Pos, frame *fr
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -439,6 +542,7 @@ func (bf *BuiltinFunction) Xor(_ Object, posStart, posEnd *util.Pos, frame *fram
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -461,6 +565,7 @@ func (bf *BuiltinFunction) LeftShift(_ Object, posStart, posEnd *util.Pos, frame
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -483,6 +588,7 @@ func (bf *BuiltinFunction) RightShift(_ Object, posStart, posEnd *util.Pos, fram
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -505,6 +611,7 @@ func (bf *BuiltinFunction) And(_ Object, posStart, posEnd *util.Pos, frame *fram
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -526,6 +633,7 @@ func (bf *BuiltinFunction) Or(_ Object, posStart, posEnd *util.Pos, frame *frame
 //	error - 可能出现的错误
 func (bf *BuiltinFunction) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "index expression not supported for this type.",
 		PosStart: posStart,
@@ -538,37 +646,42 @@ var Builtins = map[string]*BuiltinFunction{
 	"print": {
 		Name:      "print",
 		Parameter: []string{"a"},
-		Fn: func(f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
-			fmt.Print(args[0].String())
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			w := in.Stdout()
+			_, _ = fmt.Fprint(w, args[0].String())
 			// 刷新缓冲区
-			_ = os.Stdout.Sync()
-			return &Null{}, nil
+			syncWriter(w)
+			return NullObj, nil
 		},
 	},
 	// println函数
 	"println": {
 		Name:      "println",
 		Parameter: []string{"a"},
-		Fn: func(f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
-			fmt.Println(args[0].String())
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			w := in.Stdout()
+			_, _ = fmt.Fprintln(w, args[0].String())
 			// 刷新缓冲区
-			_ = os.Stdout.Sync()
-			return &Null{}, nil
+			syncWriter(w)
+			return NullObj, nil
 		},
 	},
 	// len函数
 	"len": {
 		Name:      "len",
 		Parameter: []string{"a"},
-		Fn: func(f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
 			a := args[0]
 			switch a := a.(type) {
 			case *String:
 				return &Int{Value: int64(utf8.RuneCountInString(a.Value))}, nil
 			case *List:
 				return &Int{Value: int64(len(a.Elements))}, nil
+			case *Map:
+				return &Int{Value: int64(a.Len())}, nil
 			default:
 				return nil, &TypeError{
+					Code:     errcode.TypeError,
 					Frame:    f,
 					Message:  "len() argument must be a sequence or collection.",
 					PosStart: posStart,
@@ -577,4 +690,1174 @@ var Builtins = map[string]*BuiltinFunction{
 			}
 		},
 	},
+	// int函数，将值显式转换为整数，布尔值true和false分别转换为1和0，浮点数向零取整
+	// 运算符不会隐式转换布尔值，需要时应写作int(flag) + 1
+	"int": {
+		Name:      "int",
+		Parameter: []string{"a"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			switch a := args[0].(type) {
+			case *Int:
+				return &Int{Value: a.Value}, nil
+			case *Bool:
+				if a.Value {
+					return &Int{Value: 1}, nil
+				}
+				return &Int{Value: 0}, nil
+			case *Float:
+				// 向零取整，NaN、无穷大和超出整数范围的值无法转换
+				if math.IsNaN(a.Value) || a.Value >= math.MaxInt64 || a.Value < math.MinInt64 {
+					return nil, &ValueError{
+						Code:     errcode.ValueError,
+						Frame:    f,
+						Message:  fmt.Sprintf("cannot convert float %s to int.", a.String()),
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				return &Int{Value: int64(a.Value)}, nil
+			case *String:
+				value, err := strconv.ParseInt(strings.TrimSpace(a.Value), 10, 64)
+				if err != nil {
+					return nil, &ValueError{
+						Code:     errcode.ValueError,
+						Frame:    f,
+						Message:  fmt.Sprintf("invalid literal for int(): %q.", a.Value),
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				return &Int{Value: value}, nil
+			default:
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "int() argument must be an int, float, bool or string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+		},
+	},
+	// is_integer函数，判断值是否为整数或没有小数部分的浮点数，其他类型的值返回false
+	"is_integer": {
+		Name:      "is_integer",
+		Parameter: []string{"x"},
+		Fn: func(_ Interpreter, _ *frame.Frame, _, _ *util.Pos, args ...Object) (Object, error) {
+			switch x := args[0].(type) {
+			case *Int:
+				return TrueObj, nil
+			case *Float:
+				return BoolOf(x.IsInteger()), nil
+			default:
+				return FalseObj, nil
+			}
+		},
+	},
+	// repr函数，返回值的调试表示，字符串带双引号并转义不可打印字符，ascii为true时同样转义非ASCII字符
+	"repr": {
+		Name:         "repr",
+		Parameter:    []string{"value", "ascii"},
+		DefaultValue: []Object{nil, FalseObj},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			ascii, ok := args[1].(*Bool)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "repr() argument 2 must be a bool.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			return &String{Value: Repr(args[0], ascii.Value)}, nil
+		},
+	},
+	// str函数，将值转换为字符串，结果与print输出的内容相同，字符串原样返回
+	// 字符串只能与字符串相加，拼接其他类型的值时应写作"n = " + str(n)
+	"str": {
+		Name:      "str",
+		Parameter: []string{"a"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			if s, ok := args[0].(*String); ok {
+				return s, nil
+			}
+			return &String{Value: args[0].String()}, nil
+		},
+	},
+	// float函数，将值显式转换为浮点数，布尔值true和false分别转换为1.0和0.0
+	// 严格模式下整数和浮点数不能直接运算，需要先通过float()转换
+	"float": {
+		Name:      "float",
+		Parameter: []string{"a"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			switch a := args[0].(type) {
+			case *Float:
+				return &Float{Value: a.Value}, nil
+			case *Int:
+				return &Float{Value: float64(a.Value)}, nil
+			case *Bool:
+				if a.Value {
+					return &Float{Value: 1}, nil
+				}
+				return &Float{Value: 0}, nil
+			case *String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(a.Value), 64)
+				if err != nil {
+					return nil, &ValueError{
+						Code:     errcode.ValueError,
+						Frame:    f,
+						Message:  fmt.Sprintf("invalid literal for float(): %q.", a.Value),
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				return &Float{Value: value}, nil
+			default:
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "float() argument must be an int, float, bool or string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+		},
+	},
+	// to_base函数，返回整数在2到36进制下的字符串表示，大于9的数位使用小写字母
+	"to_base": {
+		Name:      "to_base",
+		Parameter: []string{"n", "base"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			n, ok := args[0].(*Int)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "to_base() argument 1 must be an integer.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			base, ok := args[1].(*Int)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "to_base() argument 2 must be an integer.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			if base.Value < 2 || base.Value > 36 {
+				return nil, &ValueError{
+					Code:     errcode.ValueError,
+					Frame:    f,
+					Message:  fmt.Sprintf("to_base() base must be between 2 and 36, got %d.", base.Value),
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			return &String{Value: strconv.FormatInt(n.Value, int(base.Value))}, nil
+		},
+	},
+	// parse_int函数，将指定进制的字符串解析为整数，进制为0时根据0x、0b、0o前缀自动判断，无前缀时为十进制
+	"parse_int": {
+		Name:         "parse_int",
+		Parameter:    []string{"s", "base"},
+		DefaultValue: []Object{nil, &Int{Value: 10}},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			s, ok := args[0].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "parse_int() argument 1 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			base, ok := args[1].(*Int)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "parse_int() argument 2 must be an integer.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			if base.Value != 0 && (base.Value < 2 || base.Value > 36) {
+				return nil, &ValueError{
+					Code:     errcode.ValueError,
+					Frame:    f,
+					Message:  fmt.Sprintf("parse_int() base must be 0 or between 2 and 36, got %d.", base.Value),
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			value, err := parseIntWithBase(strings.TrimSpace(s.Value), int(base.Value))
+			if err != nil {
+				message := fmt.Sprintf("invalid literal for parse_int() with base %d: %q.", base.Value, s.Value)
+				if errors.Is(err, strconv.ErrRange) {
+					message = fmt.Sprintf("parse_int() value out of range: %q.", s.Value)
+				}
+				return nil, &ValueError{
+					Code:     errcode.ValueError,
+					Frame:    f,
+					Message:  message,
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			return &Int{Value: value}, nil
+		},
+	},
+	// map函数，对列表、字符串、映射或迭代器的每个元素调用函数并将返回值收集为新列表
+	"map": {
+		Name:      "map",
+		Parameter: []string{"fn", "iterable"},
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			// 检查第一个参数是否可调用
+			switch args[0].(type) {
+			case *Function, *BuiltinFunction:
+			default:
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "map() argument 1 must be a function.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			iter, ok := iterateSnapshot(args[1])
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  fmt.Sprintf("map() argument 2 must be iterable, got %s.", args[1].Type()),
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			result := &List{Elements: []Object{}}
+			for {
+				elem, ok, err := iter.Next()
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return result, nil
+				}
+				ret, err := in.CallFunction(args[0], []Object{elem}, posStart, posEnd)
+				if err != nil {
+					return nil, err
+				}
+				if !result.Accepts(ret) {
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  "list elements must have consistent types.",
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				// 逐个元素检查大小限制，避免无限迭代器耗尽内存
+				if err := in.CheckAlloc(int64(len(result.Elements)+1)*ListElementSize, posStart, posEnd); err != nil {
+					return nil, err
+				}
+				result.Elements = append(result.Elements, ret)
+			}
+		},
+	},
+	// filter函数，对列表、字符串、映射或迭代器的每个元素调用函数，按原来的顺序将返回true的元素收集为新列表
+	"filter": {
+		Name:      "filter",
+		Parameter: []string{"fn", "iterable"},
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			// 检查第一个参数是否可调用
+			switch args[0].(type) {
+			case *Function, *BuiltinFunction:
+			default:
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "filter() argument 1 must be a function.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			iter, ok := iterateSnapshot(args[1])
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  fmt.Sprintf("filter() argument 2 must be iterable, got %s.", args[1].Type()),
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			result := &List{Elements: []Object{}}
+			for {
+				elem, ok, err := iter.Next()
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return result, nil
+				}
+				ret, err := in.CallFunction(args[0], []Object{elem}, posStart, posEnd)
+				if err != nil {
+					return nil, err
+				}
+				keep, ok := ret.(*Bool)
+				if !ok {
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  fmt.Sprintf("filter() function must return a Bool, got %s.", ret.Type()),
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				if !keep.Value {
+					continue
+				}
+				// 迭代器的元素类型可能不一致，与list()同样检查
+				if !result.Accepts(elem) {
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  "list elements must have consistent types.",
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				// 逐个元素检查大小限制，避免无限迭代器耗尽内存
+				if err := in.CheckAlloc(int64(len(result.Elements)+1)*ListElementSize, posStart, posEnd); err != nil {
+					return nil, err
+				}
+				result.Elements = append(result.Elements, elem)
+			}
+		},
+	},
+	// reduce函数，按从左到右的顺序依次合并列表或迭代器的元素
+	"reduce": {
+		Name:         "reduce",
+		Parameter:    []string{"fn", "list", "init"},
+		DefaultValue: []Object{nil, nil, noValue},
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			// 检查第一个参数是否可调用
+			switch args[0].(type) {
+			case *Function, *BuiltinFunction:
+			default:
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "reduce() argument 1 must be a function.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			iter, ok := Iterate(args[1])
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "reduce() argument 2 must be iterable.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			acc := args[2]
+			// 未传入初始值时，以第一个元素作为初始值
+			if acc == noValue {
+				first, ok, err := iter.Next()
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return nil, &ValueError{
+						Code:     errcode.ValueError,
+						Frame:    f,
+						Message:  "reduce() of empty list with no initial value.",
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				acc = first
+			}
+			for {
+				elem, ok, err := iter.Next()
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return acc, nil
+				}
+				ret, err := in.CallFunction(args[0], []Object{acc, elem}, posStart, posEnd)
+				if err != nil {
+					return nil, err
+				}
+				acc = ret
+			}
+		},
+	},
+	// sort函数，稳定排序：比较结果相等的元素保持原有的相对顺序
+	"sort": {
+		Name:         "sort",
+		Parameter:    []string{"list", "key"},
+		DefaultValue: []Object{nil, noValue},
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			list, ok := args[0].(*List)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "sort() argument 1 must be a list.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			if args[1] != noValue {
+				switch args[1].(type) {
+				case *Function, *BuiltinFunction:
+				default:
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  "sort() argument 2 must be a function.",
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+			}
+			// 按原有顺序计算每个元素的排序键，未传入key时以元素本身作为排序键
+			keys := make([]Object, len(list.Elements))
+			for i, elem := range list.Elements {
+				if args[1] == noValue {
+					keys[i] = elem
+					continue
+				}
+				key, err := in.CallFunction(args[1], []Object{elem}, posStart, posEnd)
+				if err != nil {
+					return nil, err
+				}
+				keys[i] = key
+			}
+			indices := make([]int, len(list.Elements))
+			for i := range indices {
+				indices[i] = i
+			}
+			// 比较函数无法返回错误，记录第一个错误后将其余元素视为相等
+			var sortErr error
+			slices.SortStableFunc(indices, func(a, b int) int {
+				if sortErr != nil {
+					return 0
+				}
+				less, err := lessThan(keys[a], keys[b], f, posStart, posEnd)
+				if err != nil {
+					sortErr = err
+					return 0
+				}
+				if less {
+					return -1
+				}
+				greater, err := lessThan(keys[b], keys[a], f, posStart, posEnd)
+				if err != nil {
+					sortErr = err
+					return 0
+				}
+				if greater {
+					return 1
+				}
+				return 0
+			})
+			if sortErr != nil {
+				return nil, sortErr
+			}
+			elements := make([]Object, len(indices))
+			for i, index := range indices {
+				elements[i] = list.Elements[index]
+			}
+			return &List{Elements: elements}, nil
+		},
+	},
+	// range函数，惰性产生等差整数序列，range(stop)从0开始，不包含stop
+	"range": {
+		Name:         "range",
+		Parameter:    []string{"start", "stop", "step"},
+		DefaultValue: []Object{nil, noValue, &Int{Value: 1}},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			if args[1] == noValue {
+				args = []Object{&Int{Value: 0}, args[0], args[2]}
+			}
+			bounds := make([]int64, len(args))
+			for i, arg := range args {
+				n, ok := arg.(*Int)
+				if !ok {
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  fmt.Sprintf("range() arguments must be integers, got %s.", arg.Type()),
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				bounds[i] = n.Value
+			}
+			current, stop, step := bounds[0], bounds[1], bounds[2]
+			if step == 0 {
+				return nil, &ValueError{
+					Code:     errcode.ValueError,
+					Frame:    f,
+					Message:  "range() step must not be zero.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			exhausted := false
+			return NewIterator("range", func() (Object, bool, error) {
+				if exhausted || (step > 0 && current >= stop) || (step < 0 && current <= stop) {
+					return nil, false, nil
+				}
+				value := &Int{Value: current}
+				// 下一个值溢出时结束迭代
+				next := current + step
+				exhausted = (step > 0 && next < current) || (step < 0 && next > current)
+				current = next
+				return value, true, nil
+			}), nil
+		},
+	},
+	// zip函数，惰性地将两个可迭代值的元素按位置配对，较短的一方结束时停止
+	"zip": {
+		Name:      "zip",
+		Parameter: []string{"a", "b"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			iters := make([]*Iterator, len(args))
+			for i, arg := range args {
+				iter, ok := Iterate(arg)
+				if !ok {
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  fmt.Sprintf("zip() argument %d must be iterable, got %s.", i+1, arg.Type()),
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				iters[i] = iter
+			}
+			return NewIterator("zip", func() (Object, bool, error) {
+				pair := make([]Object, len(iters))
+				for i, iter := range iters {
+					value, ok, err := iter.Next()
+					if !ok || err != nil {
+						return nil, false, err
+					}
+					pair[i] = value
+				}
+				// 配对的两个元素类型可以不同
+				return &List{Elements: pair}, true, nil
+			}), nil
+		},
+	},
+	// enumerate函数，惰性地产生[索引, 元素]对，索引从start开始
+	"enumerate": {
+		Name:         "enumerate",
+		Parameter:    []string{"iterable", "start"},
+		DefaultValue: []Object{nil, &Int{Value: 0}},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			iter, ok := Iterate(args[0])
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  fmt.Sprintf("enumerate() argument 1 must be iterable, got %s.", args[0].Type()),
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			start, ok := args[1].(*Int)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "enumerate() argument 2 must be an integer.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			index := start.Value
+			return NewIterator("enumerate", func() (Object, bool, error) {
+				value, ok, err := iter.Next()
+				if !ok || err != nil {
+					return nil, false, err
+				}
+				index++
+				// 索引和元素的类型可以不同
+				return &List{Elements: []Object{&Int{Value: index - 1}, value}}, true, nil
+			}), nil
+		},
+	},
+	// list函数，消耗可迭代值并将其全部元素收集为新列表
+	"list": {
+		Name:      "list",
+		Parameter: []string{"iterable"},
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			iter, ok := Iterate(args[0])
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  fmt.Sprintf("list() argument must be iterable, got %s.", args[0].Type()),
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			list := &List{Elements: []Object{}}
+			for {
+				value, ok, err := iter.Next()
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return list, nil
+				}
+				if !list.Accepts(value) {
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  "list elements must have consistent types.",
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				// 逐个元素检查大小限制，避免无限迭代器耗尽内存
+				if err := in.CheckAlloc(int64(len(list.Elements)+1)*ListElementSize, posStart, posEnd); err != nil {
+					return nil, err
+				}
+				list.Elements = append(list.Elements, value)
+			}
+		},
+	},
+	// substring函数
+	"substring": {
+		Name:         "substring",
+		Parameter:    []string{"s", "start", "end"},
+		DefaultValue: []Object{nil, nil, noValue},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			str, ok := args[0].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "substring() argument 1 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			start, ok := args[1].(*Int)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "substring() argument 2 must be an integer.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			// 未传入结束位置时截取到末尾
+			end := int64(utf8.RuneCountInString(str.Value))
+			if args[2] != noValue {
+				endObj, ok := args[2].(*Int)
+				if !ok {
+					return nil, &TypeError{
+						Code:     errcode.TypeError,
+						Frame:    f,
+						Message:  "substring() argument 3 must be an integer.",
+						PosStart: posStart,
+						PosEnd:   posEnd,
+					}
+				}
+				end = endObj.Value
+			}
+			return str.Slice(start.Value, end), nil
+		},
+	},
+	// split函数，按分隔符拆分字符串，分隔符为空时拆分为单个字符
+	"split": {
+		Name:         "split",
+		Parameter:    []string{"s", "sep", "limit"},
+		DefaultValue: []Object{nil, nil, &Int{Value: -1}},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			str, ok := args[0].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "split() argument 1 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			sep, ok := args[1].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "split() argument 2 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			n, err := splitCount("split", args[2], f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			return stringList(strings.SplitN(str.Value, sep.Value, n)), nil
+		},
+	},
+	// split_regex函数，按正则表达式拆分字符串
+	"split_regex": {
+		Name:         "split_regex",
+		Parameter:    []string{"s", "pattern", "limit"},
+		DefaultValue: []Object{nil, nil, &Int{Value: -1}},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			str, ok := args[0].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "split_regex() argument 1 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			re, err := compilePattern("split_regex", args[1], f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			n, err := splitCount("split_regex", args[2], f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			return stringList(re.Split(str.Value, n)), nil
+		},
+	},
+	// replace函数，将字符串中的old替换为new，count为负数时替换所有匹配，否则最多替换count次
+	"replace": {
+		Name:         "replace",
+		Parameter:    []string{"s", "old", "new", "count"},
+		DefaultValue: []Object{nil, nil, nil, &Int{Value: -1}},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			strs, err := stringArgs("replace", args[:3], f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			count, ok := args[3].(*Int)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "replace() argument 4 must be an integer.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			n := -1
+			if count.Value >= 0 && count.Value < math.MaxInt32 {
+				n = int(count.Value)
+			}
+			return &String{Value: strings.Replace(strs[0], strs[1], strs[2], n)}, nil
+		},
+	},
+	// startswith函数，判断字符串是否以prefix开头
+	"startswith": {
+		Name:      "startswith",
+		Parameter: []string{"s", "prefix"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			strs, err := stringArgs("startswith", args, f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			return BoolOf(strings.HasPrefix(strs[0], strs[1])), nil
+		},
+	},
+	// endswith函数，判断字符串是否以suffix结尾
+	"endswith": {
+		Name:      "endswith",
+		Parameter: []string{"s", "suffix"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			strs, err := stringArgs("endswith", args, f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			return BoolOf(strings.HasSuffix(strs[0], strs[1])), nil
+		},
+	},
+	// match函数，在字符串中查找正则表达式的第一个匹配
+	// 返回由完整匹配和各捕获组组成的列表，未参与匹配的捕获组为null，没有匹配时返回null
+	"match": {
+		Name:      "match",
+		Parameter: []string{"pattern", "s"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			re, err := compilePattern("match", args[0], f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			str, ok := args[1].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "match() argument 2 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			loc := re.FindStringSubmatchIndex(str.Value)
+			if loc == nil {
+				return NullObj, nil
+			}
+			groups := make([]Object, len(loc)/2)
+			for i := range groups {
+				if loc[2*i] < 0 {
+					groups[i] = NullObj
+					continue
+				}
+				groups[i] = &String{Value: str.Value[loc[2*i]:loc[2*i+1]]}
+			}
+			return &List{Elements: groups}, nil
+		},
+	},
+	// replace_regex函数，替换字符串中正则表达式的所有匹配，替换文本中可以使用$1、${name}引用捕获组
+	"replace_regex": {
+		Name:      "replace_regex",
+		Parameter: []string{"s", "pattern", "repl"},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			str, ok := args[0].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "replace_regex() argument 1 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			re, err := compilePattern("replace_regex", args[1], f, posStart, posEnd)
+			if err != nil {
+				return nil, err
+			}
+			repl, ok := args[2].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "replace_regex() argument 3 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			return &String{Value: re.ReplaceAllString(str.Value, repl.Value)}, nil
+		},
+	},
+	// eval函数
+	"eval": {
+		Name:      "eval",
+		Parameter: []string{"source"},
+		Fn: func(in Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			source, ok := args[0].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "eval() argument must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			return in.EvalSource(source.Value, posStart, posEnd)
+		},
+	},
+	// assert函数，条件不成立时产生断言错误
+	"assert": {
+		Name:         "assert",
+		Parameter:    []string{"condition", "message"},
+		DefaultValue: []Object{nil, &String{Value: "assertion failed."}},
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			condition, ok := args[0].(*Bool)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "assert() argument 1 must be a bool.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			message, ok := args[1].(*String)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "assert() argument 2 must be a string.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			if !condition.Value {
+				return nil, &AssertionError{
+					Code:     errcode.AssertionError,
+					Frame:    f,
+					Message:  message.Value,
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			return NullObj, nil
+		},
+	},
+	// exit函数
+	"exit": {
+		Name:         "exit",
+		Parameter:    []string{"code"},
+		DefaultValue: []Object{&Int{Value: 0}},
+		Capability:   CapProcess,
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...Object) (Object, error) {
+			code, ok := args[0].(*Int)
+			if !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    f,
+					Message:  "exit() argument must be an integer.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			return nil, &ExitError{Code: int(code.Value)}
+		},
+	},
+}
+
+// lessThan 使用值的小于比较判断a是否小于b
+//
+// 参数:
+//
+//	a - 左侧值
+//	b - 右侧值
+//	f - 当前调用栈
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	bool - a是否小于b
+//	error - 值之间无法比较时的错误
+func lessThan(a, b Object, f *frame.Frame, posStart, posEnd *util.Pos) (bool, error) {
+	ret, err := a.LessThan(b, posStart, posEnd, f)
+	if err != nil {
+		return false, err
+	}
+	less, ok := ret.(*Bool)
+	if !ok {
+		return false, &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    f,
+			Message:  "sort() comparison must return a bool.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	return less.Value, nil
+}
+
+// parseIntWithBase 将指定进制的字符串解析为整数
+// 进制为0时根据符号之后的0x、0b、0o前缀（不区分大小写）确定进制，无前缀时为十进制
+// 与strconv.ParseInt的进制0不同，不会将以0开头的数字视为八进制，也不允许数字中的下划线
+//
+// 参数:
+//
+//	s - 要解析的字符串
+//	base - 进制，为0或2到36之间的整数
+//
+// 返回值:
+//
+//	int64 - 解析结果
+//	error - 字符串不合法或超出整数范围时的错误
+func parseIntWithBase(s string, base int) (int64, error) {
+	if base != 0 {
+		return strconv.ParseInt(s, base, 64)
+	}
+	sign, digits := "", s
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	base = 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base, digits = 16, digits[2:]
+		case 'b', 'B':
+			base, digits = 2, digits[2:]
+		case 'o', 'O':
+			base, digits = 8, digits[2:]
+		}
+	}
+	// 前缀之后必须直接是数字
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseInt(sign+digits, base, 64)
+}
+
+// splitCount 将拆分次数上限转换为strings.SplitN和regexp.Split使用的最大片段数
+//
+// 参数:
+//
+//	name - 内置函数名，用于错误信息
+//	limit - 拆分次数上限，负数表示不限制
+//	f - 当前调用栈
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	int - 最大片段数，-1表示不限制
+//	error - 上限不是整数时的错误
+func splitCount(name string, limit Object, f *frame.Frame, posStart, posEnd *util.Pos) (int, error) {
+	n, ok := limit.(*Int)
+	if !ok {
+		return 0, &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    f,
+			Message:  name + "() argument 3 must be an integer.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	if n.Value < 0 || n.Value >= math.MaxInt32 {
+		return -1, nil
+	}
+	return int(n.Value) + 1, nil
+}
+
+// maxCachedPatterns 正则表达式缓存的最大条目数
+const maxCachedPatterns = 256
+
+// patternCache 已编译的正则表达式缓存，在循环中反复使用同一正则表达式时避免重复编译
+// 内置函数在所有解释器之间共享，因此需要加锁
+var patternCache = struct {
+	sync.Mutex
+	patterns map[string]*regexp.Regexp
+}{patterns: make(map[string]*regexp.Regexp)}
+
+// compilePattern 编译内置函数的正则表达式参数，优先使用缓存中已编译的结果
+//
+// 参数:
+//
+//	name - 内置函数名，用于错误信息
+//	pattern - 正则表达式参数
+//	f - 当前调用栈
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	*regexp.Regexp - 编译后的正则表达式
+//	error - 参数不是字符串或正则表达式不合法时的错误
+func compilePattern(name string, pattern Object, f *frame.Frame, posStart, posEnd *util.Pos) (*regexp.Regexp, error) {
+	str, ok := pattern.(*String)
+	if !ok {
+		return nil, &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    f,
+			Message:  name + "() pattern must be a string.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	patternCache.Lock()
+	defer patternCache.Unlock()
+	if re, ok := patternCache.patterns[str.Value]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(str.Value)
+	if err != nil {
+		return nil, &ParseError{
+			Code:     errcode.ParseError,
+			Frame:    f,
+			Message:  fmt.Sprintf("invalid pattern %q: %s.", str.Value, strings.TrimPrefix(err.Error(), "error parsing regexp: ")),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	// 缓存已满时整体清空，避免动态生成的正则表达式使缓存无限增长
+	if len(patternCache.patterns) >= maxCachedPatterns {
+		clear(patternCache.patterns)
+	}
+	patternCache.patterns[str.Value] = re
+	return re, nil
+}
+
+// stringArgs 检查内置函数的参数都是字符串并取出其值
+//
+// 参数:
+//
+//	name - 内置函数名，用于错误信息
+//	args - 要检查的参数
+//	f - 当前调用栈
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	[]string - 参数的字符串值
+//	error - 某个参数不是字符串时的错误
+func stringArgs(name string, args []Object, f *frame.Frame, posStart, posEnd *util.Pos) ([]string, error) {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		str, ok := arg.(*String)
+		if !ok {
+			return nil, &TypeError{
+				Code:     errcode.TypeError,
+				Frame:    f,
+				Message:  fmt.Sprintf("%s() argument %d must be a string, got %s.", name, i+1, arg.Type()),
+				PosStart: posStart,
+				PosEnd:   posEnd,
+			}
+		}
+		strs[i] = str.Value
+	}
+	return strs, nil
+}
+
+// stringList 将字符串切片转换为字符串列表
+//
+// 参数:
+//
+//	parts - 字符串切片
+//
+// 返回值:
+//
+//	*List - 元素为String的列表
+func stringList(parts []string) *List {
+	elements := make([]Object, len(parts))
+	for i, part := range parts {
+		elements[i] = &String{Value: part}
+	}
+	return &List{Elements: elements}
+}
+
+// syncWriter 输出支持同步时将缓冲的内容写入底层设备，如*os.File
+//
+// 参数:
+//
+//	w - 输出
+func syncWriter(w io.Writer) {
+	if syncer, ok := w.(interface{ Sync() error }); ok {
+		_ = syncer.Sync()
+	}
 }
@@ -0,0 +1,530 @@
+package object
+
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// ContinueSignal continue语句的执行结果，沿块和if表达式向外传递，由最近的循环语句接收后开始下一次迭代
+// 与BreakSignal类似，只在解释器内部传递，不会成为变量的值
+
+type ContinueSignal struct{}
+
+// ContinueObj 是所有continue语句共用的实例，continue信号没有状态，不需要分别分配
+
+var ContinueObj = &ContinueSignal{}
+
+// Type 返回值的类型
+//
+// 返回值:
+//
+//	string - 值的类型
+func (cs *ContinueSignal) Type() string {
+	return "ContinueSignal"
+}
+
+// String 返回值的字符串表示
+//
+// 返回值:
+//
+//	string - 格式化的字符串表示
+func (cs *ContinueSignal) String() string {
+	return "continue"
+}
+
+// Negative 对值进行负运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitNot 对值进行按位非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"~\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Not 对值进行逻辑非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"!\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Add 对值进行加法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"+\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Subtract 对值进行减法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Multiply 对值进行乘法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"*\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Divide 对值进行除法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"/\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Mod 对值进行取模运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"%\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Equal 判断当前空值与另一个值是否相等
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Equal(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"==\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// NotEqual 判断当前空值与另一个值是否不相等
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) NotEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"!=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LessThan 对值进行小于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (cs *ContinueSignal) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThan 对值进行大于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (cs *ContinueSignal) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LessThanOrEqual 对值进行小于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (cs *ContinueSignal) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThanOrEqual 对值进行大于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (cs *ContinueSignal) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitAnd 对值进行按位与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitOr 对值进行按位或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"|\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Xor 对值进行异或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"^\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LeftShift 对值进行左移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// RightShift 对值进行右移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">>\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// And 对值进行逻辑与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Or 对值进行逻辑或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"||\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Index 执行索引运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (cs *ContinueSignal) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &TypeError{
+		Code:     errcode.TypeError,
+		Frame:    frame,
+		Message:  "index expression not supported for this type.",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
@@ -1,11 +1,86 @@
 package object
 
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
 // Environment 表示程序运行时的上下文环境，用于管理符号表和上下文嵌套关系
 // 在函数调用、作用域切换等场景中使用，实现变量的作用域隔离和查找
+// 环境有两种形式：未经作用域解析的代码按名称在Store中存放符号；
+// 经过解析的局部作用域按槽位在Slots中存放符号，标识符通过解析得到的槽位直接存取，不需要按名称查找
+// 两种形式都支持按名称查找，eval等动态执行的代码可以读写经过解析的环境中的变量
 
 type Environment struct {
-	Store map[string]*Symbol // 变量名到值的映射
-	Outer *Environment       // 外部环境
+	Store  map[string]*Symbol // 变量名到值的映射，按槽位存放时为nil
+	Slots  []*Symbol          // 按槽位存放的符号，尚未声明的槽位为nil
+	Outer  *Environment       // 外部环境
+	global *Environment       // 作用域链最外层的全局环境，创建时确定，查找内置函数时不需要逐层向外
+}
+
+// NewEnvironment 创建外部环境之下的新环境
+//
+// 参数:
+//
+//	outer - 外部环境
+//	layout - 作用域解析得到的环境布局，为nil时按名称存放符号
+//
+// 返回值:
+//
+//	*Environment - 新的环境
+func NewEnvironment(outer *Environment, layout *ast.Layout) *Environment {
+	env := &Environment{Outer: outer}
+	if layout == nil {
+		env.Store = make(map[string]*Symbol)
+	} else {
+		env.Slots = make([]*Symbol, layout.Slots)
+	}
+	env.global = env
+	if outer != nil {
+		env.global = outer.Global()
+	}
+	return env
+}
+
+// Global 返回作用域链最外层的全局环境
+//
+// 返回值:
+//
+//	*Environment - 全局环境，没有外部环境时为当前环境
+func (e *Environment) Global() *Environment {
+	if e.global != nil {
+		return e.global
+	}
+	// 直接构造的环境没有记录全局环境
+	env := e
+	for env.Outer != nil {
+		env = env.Outer
+	}
+	return env
+}
+
+// local 在当前环境中按名称查找符号，不查找父环境
+//
+// 参数:
+//
+//	name - 要查找的符号名称
+//
+// 返回值:
+//
+//	int - 按槽位存放时符号的槽位，按名称存放或未找到时为-1
+//	*Symbol - 符号，若未找到则为nil
+func (e *Environment) local(name string) (int, *Symbol) {
+	if e.Store != nil {
+		return -1, e.Store[name]
+	}
+	for i, sym := range e.Slots {
+		if sym != nil && sym.Name == name {
+			return i, sym
+		}
+	}
+	return -1, nil
 }
 
 // Get 查找符号的值，支持作用域链向上查找
@@ -20,26 +95,32 @@ type Environment struct {
 //	Symbol - 符号，若未找到则为nil
 //	bool - 查找结果，true表示找到，false表示未找到
 func (e *Environment) Get(name string) (*Symbol, bool) {
-	val, ok := e.Store[name]
-	if ok {
-		return val, ok
-	}
-	// 若当前表未找到，尝试在父环境中查找
-	if e.Outer != nil {
-		return e.Outer.Get(name)
+	for env := e; env != nil; env = env.Outer {
+		if _, sym := env.local(name); sym != nil {
+			return sym, true
+		}
 	}
 	return nil, false
 }
 
 // Set 设置符号的值到当前环境
 // 仅在当前作用域中添加或修改变量，不影响父环境
+// 按槽位存放的环境中没有该名称时追加新的槽位，只能按名称访问
 //
 // 参数:
 //
 //	name - 要设置的符号名称
 //	sym - 符号
 func (e *Environment) Set(name string, sym *Symbol) {
-	e.Store[name] = sym
+	if e.Store != nil {
+		e.Store[name] = sym
+		return
+	}
+	if i, old := e.local(name); old != nil {
+		e.Slots[i] = sym
+		return
+	}
+	e.Slots = append(e.Slots, sym)
 }
 
 // Assign 设置符号的值到当前环境
@@ -51,14 +132,17 @@ func (e *Environment) Set(name string, sym *Symbol) {
 //	name - 要设置的符号名称
 //	sym - 符号
 func (e *Environment) Assign(name string, sym *Symbol) {
-	// 先在当前作用域查找
-	if _, ok := e.Store[name]; ok {
-		e.Store[name] = sym
-	} else {
-		// 若当前作用域未定义，递归查找父作用域
-		if e.Outer != nil {
-			e.Outer.Assign(name, sym)
+	for env := e; env != nil; env = env.Outer {
+		i, old := env.local(name)
+		if old == nil {
+			continue
 		}
+		if env.Store != nil {
+			env.Store[name] = sym
+		} else {
+			env.Slots[i] = sym
+		}
+		return
 	}
 }
 
@@ -73,6 +157,235 @@ func (e *Environment) Assign(name string, sym *Symbol) {
 //
 //	bool - 存在性结果，true表示存在，false表示不存在
 func (e *Environment) Exists(name string) bool {
-	_, ok := e.Store[name]
-	return ok
+	_, sym := e.local(name)
+	return sym != nil
+}
+
+// slot 返回标识符经过解析后所在的环境和槽位
+//
+// 参数:
+//
+//	ident - 标识符
+//
+// 返回值:
+//
+//	*Environment - 符号所在的环境，标识符未经解析时为nil
+//	int - 符号的槽位，为-1时在该环境中按名称查找
+func (e *Environment) slot(ident *ast.IdentifierExpression) (*Environment, int) {
+	if ident.Binding == nil {
+		return nil, -1
+	}
+	env := e
+	for range ident.Binding.Depth {
+		env = env.Outer
+	}
+	if ident.Binding.Slot >= len(env.Slots) {
+		return env, -1
+	}
+	return env, ident.Binding.Slot
+}
+
+// Lookup 查找标识符引用的符号
+// 经过解析的标识符直接读取槽位，槽位尚未声明时与未经解析的标识符一样沿作用域链按名称查找，
+// 如函数读取外层作用域中在之后才声明的同名变量之前，仍然读到更外层的变量
+//
+// 参数:
+//
+//	ident - 标识符
+//
+// 返回值:
+//
+//	*Symbol - 符号，若未找到则为nil
+//	bool - 是否找到
+func (e *Environment) Lookup(ident *ast.IdentifierExpression) (*Symbol, bool) {
+	// 不会被遮蔽的内置函数直接在全局环境中查找，嵌入方未提供该内置函数时仍沿作用域链查找
+	if ident.Binding != nil && ident.Binding.Builtin {
+		if global := e.Global(); global.Store != nil {
+			if sym := global.Store[ident.Name]; sym != nil {
+				return sym, true
+			}
+		}
+	}
+	env, slot := e.slot(ident)
+	if env == nil {
+		return e.Get(ident.Name)
+	}
+	if slot < 0 {
+		return env.Get(ident.Name)
+	}
+	if sym := env.Slots[slot]; sym != nil {
+		return sym, true
+	}
+	return e.Get(ident.Name)
+}
+
+// Update 对标识符引用的变量赋值，查找规则与Lookup相同
+//
+// 参数:
+//
+//	ident - 标识符
+//	sym - 新的符号
+func (e *Environment) Update(ident *ast.IdentifierExpression, sym *Symbol) {
+	env, slot := e.slot(ident)
+	if env == nil {
+		e.Assign(ident.Name, sym)
+		return
+	}
+	if slot < 0 {
+		env.Assign(ident.Name, sym)
+		return
+	}
+	if env.Slots[slot] != nil {
+		env.Slots[slot] = sym
+		return
+	}
+	e.Assign(ident.Name, sym)
+}
+
+// Define 在当前环境中声明标识符，经过解析的标识符存入其槽位，否则按名称存放
+//
+// 参数:
+//
+//	ident - 声明的标识符
+//	sym - 符号
+func (e *Environment) Define(ident *ast.IdentifierExpression, sym *Symbol) {
+	if env, slot := e.slot(ident); env == e && slot >= 0 {
+		e.Slots[slot] = sym
+		return
+	}
+	e.Set(ident.Name, sym)
+}
+
+// Locals 返回当前环境中（不含父环境）已声明的符号
+//
+// 返回值:
+//
+//	[]*Symbol - 符号，顺序不固定
+func (e *Environment) Locals() []*Symbol {
+	var symbols []*Symbol
+	if e.Store != nil {
+		for _, sym := range e.Store {
+			symbols = append(symbols, sym)
+		}
+		return symbols
+	}
+	for _, sym := range e.Slots {
+		if sym != nil {
+			symbols = append(symbols, sym)
+		}
+	}
+	return symbols
+}
+
+// Names 返回作用域链中所有可见的符号名称，被内层作用域遮蔽的名称只出现一次
+//
+// 返回值:
+//
+//	[]string - 符号名称，顺序不固定
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for env := e; env != nil; env = env.Outer {
+		for _, sym := range env.Locals() {
+			if !seen[sym.Name] {
+				seen[sym.Name] = true
+				names = append(names, sym.Name)
+			}
+		}
+	}
+	return names
+}
+
+// NewGlobalEnvironment 创建加载了内置函数的全局环境
+//
+// 参数:
+//
+//	scriptArgs - 传递给脚本的命令行参数，通过args()获取
+//
+// 返回值:
+//
+//	*Environment - 全局环境
+func NewGlobalEnvironment(scriptArgs []string) *Environment {
+	env := NewEnvironment(nil, nil)
+	// 加载内置函数
+	for name, builtin := range Builtins {
+		env.Store[name] = &Symbol{
+			Name:    name,
+			Value:   builtin,
+			IsConst: true,
+		}
+	}
+	env.Store["args"] = &Symbol{
+		Name:    "args",
+		Value:   newArgsBuiltin(scriptArgs),
+		IsConst: true,
+	}
+	return env
+}
+
+// newArgsBuiltin 创建返回脚本命令行参数的args内置函数
+//
+// 参数:
+//
+//	scriptArgs - 传递给脚本的命令行参数
+//
+// 返回值:
+//
+//	*BuiltinFunction - args内置函数，每次调用返回参数字符串组成的新列表
+func newArgsBuiltin(scriptArgs []string) *BuiltinFunction {
+	return &BuiltinFunction{
+		Name:       "args",
+		Parameter:  []string{},
+		Capability: CapArgs,
+		Fn: func(_ Interpreter, _ *frame.Frame, _, _ *util.Pos, _ ...Object) (Object, error) {
+			elements := make([]Object, 0, len(scriptArgs))
+			for _, arg := range scriptArgs {
+				elements = append(elements, &String{Value: arg})
+			}
+			return &List{Elements: elements}, nil
+		},
+	}
+}
+
+// Sandbox 将环境中带有能力标记的内置函数替换为调用时产生沙箱错误的桩函数
+// 桩函数保留原函数的名称和参数，没有能力标记的内置函数和其他符号不受影响
+func (e *Environment) Sandbox() {
+	for name, sym := range e.Store {
+		builtin, ok := sym.Value.(*BuiltinFunction)
+		if !ok || builtin.Capability == 0 {
+			continue
+		}
+		e.Store[name] = &Symbol{
+			Name:    sym.Name,
+			Value:   newSandboxStub(builtin),
+			IsConst: sym.IsConst,
+		}
+	}
+}
+
+// newSandboxStub 创建替换内置函数的沙箱桩函数
+//
+// 参数:
+//
+//	builtin - 被替换的内置函数
+//
+// 返回值:
+//
+//	*BuiltinFunction - 调用时产生沙箱错误的桩函数
+func newSandboxStub(builtin *BuiltinFunction) *BuiltinFunction {
+	return &BuiltinFunction{
+		Name:         builtin.Name,
+		Parameter:    builtin.Parameter,
+		DefaultValue: builtin.DefaultValue,
+		Capability:   builtin.Capability,
+		Fn: func(_ Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, _ ...Object) (Object, error) {
+			return nil, &SandboxError{
+				Code:     errcode.SandboxError,
+				Frame:    f,
+				Message:  builtin.Name + "() is disabled in sandbox.",
+				PosStart: posStart,
+				PosEnd:   posEnd,
+			}
+		},
+	}
 }
@@ -0,0 +1,173 @@
+package object
+
+import (
+	"math"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// DeepEquals 判断两个值在结构上是否相等，供测试和嵌入方比较运行结果
+// 与 == 运算不同，两个值的类型必须相同，整数1与浮点数1.0不相等，两个NaN相等；
+// 函数、内置函数和迭代器带有环境等内部状态，只有是同一个对象时才相等；
+// 列表逐个比较元素；映射的键相同且对应的值相等时相等，与插入顺序无关；包含自身的列表和映射不会导致无限递归
+//
+// 参数:
+//
+//	a - 第一个值
+//	b - 第二个值
+//
+// 返回值:
+//
+//	bool - 两个值是否相等
+func DeepEquals(a, b Object) bool {
+	return deepEquals(a, b, make(map[[2]Object]bool))
+}
+
+// deepEquals 递归比较两个值
+//
+// 参数:
+//
+//	a - 第一个值
+//	b - 第二个值
+//	visiting - 正在比较的列表对和映射对，再次遇到时视为相等
+//
+// 返回值:
+//
+//	bool - 两个值是否相等
+func deepEquals(a, b Object, visiting map[[2]Object]bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch x := a.(type) {
+	case *Int:
+		y, ok := b.(*Int)
+		return ok && x.Value == y.Value
+	case *Float:
+		y, ok := b.(*Float)
+		return ok && (x.Value == y.Value || math.IsNaN(x.Value) && math.IsNaN(y.Value))
+	case *String:
+		y, ok := b.(*String)
+		return ok && x.Value == y.Value
+	case *Bool:
+		y, ok := b.(*Bool)
+		return ok && x.Value == y.Value
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+	case *ReturnValue:
+		y, ok := b.(*ReturnValue)
+		return ok && deepEquals(x.Value, y.Value, visiting)
+	case *List:
+		y, ok := b.(*List)
+		if !ok || len(x.Elements) != len(y.Elements) {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		pair := [2]Object{x, y}
+		if visiting[pair] {
+			return true
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+		for i := range x.Elements {
+			if !deepEquals(x.Elements[i], y.Elements[i], visiting) {
+				return false
+			}
+		}
+		return true
+	case *Map:
+		y, ok := b.(*Map)
+		if !ok || x.Len() != y.Len() {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		pair := [2]Object{x, y}
+		if visiting[pair] {
+			return true
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+		for hashKey, entry := range x.Entries {
+			other, ok := y.Entries[hashKey]
+			if !ok || !deepEquals(entry.Value, other.Value, visiting) {
+				return false
+			}
+		}
+		return true
+	default:
+		// 函数、内置函数和迭代器按对象比较
+		return a == b
+	}
+}
+
+// equals 递归比较列表或映射，供==和!=运算使用
+// 元素和值按各自的Equal方法比较，再次遇到正在比较的列表对或映射对时视为相等，包含自身的值不会导致无限递归
+//
+// 参数:
+//
+//	a - 第一个值
+//	b - 第二个值
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//	visiting - 正在比较的列表对和映射对
+//
+// 返回值:
+//
+//	bool - 两个值是否相等
+//	error - 比较元素时可能出现的错误
+func equals(a, b Object, posStart, posEnd *util.Pos, frame *frame.Frame, visiting map[[2]Object]bool) (bool, error) {
+	switch x := a.(type) {
+	case *List:
+		y, ok := b.(*List)
+		if !ok || len(x.Elements) != len(y.Elements) {
+			return false, nil
+		}
+		pair := [2]Object{x, y}
+		if visiting[pair] {
+			return true, nil
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+		for i := range x.Elements {
+			equal, err := equals(x.Elements[i], y.Elements[i], posStart, posEnd, frame, visiting)
+			if !equal || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case *Map:
+		y, ok := b.(*Map)
+		if !ok || x.Len() != y.Len() {
+			return false, nil
+		}
+		pair := [2]Object{x, y}
+		if visiting[pair] {
+			return true, nil
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+		for hashKey, entry := range x.Entries {
+			other, ok := y.Entries[hashKey]
+			if !ok {
+				return false, nil
+			}
+			equal, err := equals(entry.Value, other.Value, posStart, posEnd, frame, visiting)
+			if !equal || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		equal, err := a.Equal(b, posStart, posEnd, frame)
+		if err != nil {
+			return false, err
+		}
+		return equal.(*Bool).Value, nil
+	}
+}
@@ -0,0 +1,170 @@
+package object
+
+import (
+	"math"
+	"testing"
+)
+
+// mapOf 按键、值交替给出的参数创建映射
+func mapOf(pairs ...Object) *Map {
+	m := NewMap()
+	for i := 0; i < len(pairs); i += 2 {
+		m.Set(pairs[i], pairs[i+1], nil, nil, nil)
+	}
+	return m
+}
+
+func TestDeepEquals(t *testing.T) {
+	fn := &Function{Name: "f"}
+	cyclic := &List{}
+	cyclic.Elements = []Object{&Int{Value: 1}, cyclic}
+	other := &List{}
+	other.Elements = []Object{&Int{Value: 1}, other}
+	cyclicMap := mapOf(&String{Value: "a"}, &Int{Value: 1})
+	cyclicMap.Set(&String{Value: "self"}, cyclicMap, nil, nil, nil)
+	otherMap := mapOf(&String{Value: "a"}, &Int{Value: 1})
+	otherMap.Set(&String{Value: "self"}, otherMap, nil, nil, nil)
+	tests := []struct {
+		name     string
+		a        Object
+		b        Object
+		excepted bool
+	}{
+		{name: "Same Int", a: &Int{Value: 1}, b: &Int{Value: 1}, excepted: true},
+		{name: "Int And Float", a: &Int{Value: 1}, b: &Float{Value: 1}, excepted: false},
+		{name: "NaN", a: &Float{Value: math.NaN()}, b: &Float{Value: math.NaN()}, excepted: true},
+		{name: "Shared Bool", a: TrueObj, b: &Bool{Value: true}, excepted: true},
+		{name: "Different Bool", a: TrueObj, b: FalseObj, excepted: false},
+		{name: "Null", a: NullObj, b: &Null{}, excepted: true},
+		{name: "Null And Nil", a: NullObj, b: nil, excepted: false},
+		{name: "Nil", a: nil, b: nil, excepted: true},
+		{name: "String", a: &String{Value: "幽灵"}, b: &String{Value: "幽灵"}, excepted: true},
+		{
+			name:     "Nested Lists",
+			a:        &List{Elements: []Object{&List{Elements: []Object{&Int{Value: 1}, NullObj}}}},
+			b:        &List{Elements: []Object{&List{Elements: []Object{&Int{Value: 1}, &Null{}}}}},
+			excepted: true,
+		},
+		{
+			name:     "Lists Of Different Length",
+			a:        &List{Elements: []Object{&Int{Value: 1}}},
+			b:        &List{Elements: []Object{&Int{Value: 1}, &Int{Value: 2}}},
+			excepted: false,
+		},
+		{name: "Cyclic Lists", a: cyclic, b: other, excepted: true},
+		{name: "Cyclic Maps", a: cyclicMap, b: otherMap, excepted: true},
+		{name: "Same Function", a: fn, b: fn, excepted: true},
+		{name: "Identical Functions", a: fn, b: &Function{Name: "f"}, excepted: false},
+		{
+			name:     "Maps In Different Order",
+			a:        mapOf(&String{Value: "a"}, &Int{Value: 1}, &String{Value: "b"}, &List{}),
+			b:        mapOf(&String{Value: "b"}, &List{}, &String{Value: "a"}, &Int{Value: 1}),
+			excepted: true,
+		},
+		{
+			name:     "Maps With Different Values",
+			a:        mapOf(&String{Value: "a"}, &Int{Value: 1}),
+			b:        mapOf(&String{Value: "a"}, &Float{Value: 1}),
+			excepted: false,
+		},
+		{
+			name:     "Maps With Int And Float Keys",
+			a:        mapOf(&Int{Value: 1}, NullObj),
+			b:        mapOf(&Float{Value: 1}, NullObj),
+			excepted: false,
+		},
+		{
+			name:     "Return Value",
+			a:        &ReturnValue{Value: &Int{Value: 2}},
+			b:        &ReturnValue{Value: &Int{Value: 2}},
+			excepted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeepEquals(tt.a, tt.b); got != tt.excepted {
+				t.Errorf("excepted %v, got %v", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestEqual_Cyclic(t *testing.T) {
+	list := &List{}
+	list.Elements = []Object{list}
+	otherList := &List{}
+	otherList.Elements = []Object{otherList}
+	m := NewMap()
+	m.Set(&String{Value: "self"}, m, nil, nil, nil)
+	n := NewMap()
+	n.Set(&String{Value: "self"}, n, nil, nil, nil)
+	different := NewMap()
+	different.Set(&String{Value: "self"}, mapOf(&String{Value: "self"}, &Int{Value: 1}), nil, nil, nil)
+	tests := []struct {
+		name     string
+		a        Object
+		b        Object
+		excepted bool
+	}{
+		{name: "Cyclic Lists", a: list, b: otherList, excepted: true},
+		{name: "Cyclic Maps", a: m, b: n, excepted: true},
+		{name: "Cyclic And Acyclic Maps", a: m, b: different, excepted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Equal(tt.b, nil, nil, nil)
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if got.(*Bool).Value != tt.excepted {
+				t.Errorf("excepted %v, got %v", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestString_Cyclic(t *testing.T) {
+	list := &List{}
+	list.Elements = []Object{&Int{Value: 1}, list}
+	m := mapOf(&String{Value: "a"}, &String{Value: "b"})
+	m.Set(&String{Value: "self"}, m, nil, nil, nil)
+	m.Set(&String{Value: "list"}, &List{Elements: []Object{m}}, nil, nil, nil)
+	tests := []struct {
+		name     string
+		got      string
+		excepted string
+	}{
+		{name: "List String", got: list.String(), excepted: "[1, [...]]"},
+		{name: "Map String", got: m.String(), excepted: "{a: b, self: {...}, list: [{...}]}"},
+		{name: "Map Repr", got: Repr(m, false), excepted: `{"a": "b", "self": {...}, "list": [{...}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, tt.got)
+			}
+		})
+	}
+}
+
+func TestHashKey_Float(t *testing.T) {
+	tests := []struct {
+		name string
+		a    *Float
+		b    *Float
+	}{
+		{name: "Signed Zeros", a: &Float{Value: 0}, b: &Float{Value: math.Copysign(0, -1)}},
+		{name: "NaNs", a: &Float{Value: math.NaN()}, b: &Float{Value: -math.NaN()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.a.HashKey() != tt.b.HashKey() {
+				t.Errorf("excepted equal hash keys, got %+v and %+v", tt.a.HashKey(), tt.b.HashKey())
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 // 拥有完整的错误跟踪和格式化能力
 
 type OperationError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -29,17 +30,17 @@ func (e *OperationError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -53,11 +54,58 @@ func (e *OperationError) Error() string {
 	return res
 }
 
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Operation Error"
+func (e *OperationError) ErrorKind() string {
+	return "Operation Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *OperationError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *OperationError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *OperationError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *OperationError) Frames() *frame.Frame {
+	return e.Frame
+}
+
 // MathError 数学错误类型，表示数学运算相关的错误
 // 例如除以零、数值溢出、无效的数学函数参数等
 // 拥有完整的错误跟踪和格式化能力
 
 type MathError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -75,17 +123,17 @@ func (e *MathError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -99,11 +147,58 @@ func (e *MathError) Error() string {
 	return res
 }
 
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Math Error"
+func (e *MathError) ErrorKind() string {
+	return "Math Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *MathError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *MathError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *MathError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *MathError) Frames() *frame.Frame {
+	return e.Frame
+}
+
 // TypeError 类型错误类型，表示类型相关的运行时错误
 // 例如访问类型不匹配等
 // 拥有完整的错误跟踪和格式化能力
 
 type TypeError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -121,17 +216,17 @@ func (e *TypeError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -145,10 +240,57 @@ func (e *TypeError) Error() string {
 	return res
 }
 
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Type Error"
+func (e *TypeError) ErrorKind() string {
+	return "Type Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *TypeError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *TypeError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *TypeError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *TypeError) Frames() *frame.Frame {
+	return e.Frame
+}
+
 // IndexError 索引错误类型，表示索引越界等相关的运行时错误
 // 拥有完整的错误跟踪和格式化能力
 
 type IndexError struct {
+	Code     string       // 错误代码，见errcode包
 	Frame    *frame.Frame // 错误发生时的调用栈
 	Message  string       // 错误描述文本
 	PosStart *util.Pos    // 错误起始位置
@@ -162,17 +304,17 @@ func (e *IndexError) Error() string {
 	posStart := e.PosStart
 	posEnd := e.PosEnd
 	currFrame := e.Frame
-	// 构建调用栈跟踪信息
-	for currFrame != nil {
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
 		var linePos string
 		if posStart.Row == posEnd.Row {
 			linePos = "line " + strconv.Itoa(posStart.Row)
 		} else {
 			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
 		}
-		str := "    File " + posStart.File + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
 		// 添加代码位置指示箭头
-		str += util.StringsWithArrows(e.PosStart.Text, posStart, posEnd, true)
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
 		res = str + "\n" + res
 		posStart = currFrame.PosStart
 		posEnd = currFrame.PosEnd
@@ -185,3 +327,506 @@ func (e *IndexError) Error() string {
 	}
 	return res
 }
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Index Error"
+func (e *IndexError) ErrorKind() string {
+	return "Index Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *IndexError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *IndexError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *IndexError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *IndexError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// ValueError 值错误类型，表示参数类型正确但取值不合法的运行时错误
+// 例如对空列表进行无初始值的归约等
+// 拥有完整的错误跟踪和格式化能力
+
+type ValueError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本
+	PosStart *util.Pos    // 错误起始位置
+	PosEnd   *util.Pos    // 错误结束位置
+}
+
+// Error 生成格式化的值错误信息字符串
+// 前缀为"Value Error"
+func (e *ValueError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Value Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Value Error"
+func (e *ValueError) ErrorKind() string {
+	return "Value Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *ValueError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *ValueError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *ValueError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *ValueError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// AssertionError 断言错误类型，表示assert()的条件不成立
+// 拥有完整的错误跟踪和格式化能力
+
+type AssertionError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本
+	PosStart *util.Pos    // 错误起始位置
+	PosEnd   *util.Pos    // 错误结束位置
+}
+
+// Error 生成格式化的断言错误信息字符串
+// 前缀为"Assertion Error"
+func (e *AssertionError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Assertion Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Assertion Error"
+func (e *AssertionError) ErrorKind() string {
+	return "Assertion Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *AssertionError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *AssertionError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *AssertionError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *AssertionError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// HostError 宿主错误类型，表示宿主程序注册的Go函数返回的错误
+// 拥有完整的错误跟踪和格式化能力
+
+type HostError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本
+	PosStart *util.Pos    // 错误起始位置
+	PosEnd   *util.Pos    // 错误结束位置
+}
+
+// Error 生成格式化的宿主错误信息字符串
+// 前缀为"Host Error"
+func (e *HostError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Host Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Host Error"
+func (e *HostError) ErrorKind() string {
+	return "Host Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *HostError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *HostError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *HostError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *HostError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// ParseError 解析错误类型，表示正则表达式等运行时解析的文本不合法
+// 拥有完整的错误跟踪和格式化能力
+
+type ParseError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本
+	PosStart *util.Pos    // 错误起始位置
+	PosEnd   *util.Pos    // 错误结束位置
+}
+
+// Error 生成格式化的解析错误信息字符串
+// 前缀为"Parse Error"
+func (e *ParseError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Parse Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Parse Error"
+func (e *ParseError) ErrorKind() string {
+	return "Parse Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *ParseError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *ParseError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *ParseError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *ParseError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// SandboxError 沙箱错误类型，表示沙箱模式下调用了被禁用的内置函数或导入了文件模块
+// 拥有完整的错误跟踪和格式化能力
+
+type SandboxError struct {
+	Code     string       // 错误代码，见errcode包
+	Frame    *frame.Frame // 错误发生时的调用栈
+	Message  string       // 错误描述文本
+	PosStart *util.Pos    // 错误起始位置
+	PosEnd   *util.Pos    // 错误结束位置
+}
+
+// Error 生成格式化的沙箱错误信息字符串
+// 前缀为"Sandbox Error"
+func (e *SandboxError) Error() string {
+	res := ""
+	posStart := e.PosStart
+	posEnd := e.PosEnd
+	currFrame := e.Frame
+	// 构建调用栈跟踪信息，没有调用位置的栈帧（如宿主程序发起的调用）之后不再输出
+	for currFrame != nil && posStart != nil {
+		var linePos string
+		if posStart.Row == posEnd.Row {
+			linePos = "line " + strconv.Itoa(posStart.Row)
+		} else {
+			linePos = "lines " + strconv.Itoa(posStart.Row) + "-" + strconv.Itoa(posEnd.Row)
+		}
+		str := "    File " + posStart.File() + ", " + linePos + ", in " + currFrame.FuncName + "\n"
+		// 添加代码位置指示箭头
+		str += util.StringsWithArrows(posStart.Text(), posStart, posEnd, true)
+		res = str + "\n" + res
+		posStart = currFrame.PosStart
+		posEnd = currFrame.PosEnd
+		currFrame = currFrame.Parent
+	}
+	res = "Traceback:\n" + res
+	res += "Sandbox Error"
+	if e.Message != "" {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Sandbox Error"
+func (e *SandboxError) ErrorKind() string {
+	return "Sandbox Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *SandboxError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *SandboxError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *SandboxError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 错误发生时的调用栈
+func (e *SandboxError) Frames() *frame.Frame {
+	return e.Frame
+}
+
+// ExitError 退出请求，由exit函数产生
+// 沿调用栈向上传递并终止程序，不属于运行时错误，不输出错误信息
+
+type ExitError struct {
+	Code int // 进程退出码
+}
+
+// Error 生成退出请求的描述字符串
+//
+// 返回值:
+//
+//	string - 包含退出码的描述
+func (e *ExitError) Error() string {
+	return "exit status " + strconv.Itoa(e.Code)
+}
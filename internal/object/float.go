@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -33,7 +34,17 @@ func (f *Float) String() string {
 	return fmt.Sprintf("%f", f.Value)
 }
 
+// IsInteger 判断浮点数是否没有小数部分，NaN和无穷大不是整数
+//
+// 返回值:
+//
+//	bool - 是否为有限的整数值
+func (f *Float) IsInteger() bool {
+	return !math.IsInf(f.Value, 0) && f.Value == math.Trunc(f.Value)
+}
+
 // Negative 对值进行负运算
+// 遵循IEEE 754，0.0取负得到-0.0，NaN取负仍为NaN
 //
 // 参数:
 //
@@ -63,6 +74,7 @@ func (f *Float) Negative(*util.Pos, *util.Pos, *frame.Frame) (Object, error) {
 //	error - 可能出现的错误
 func (f *Float) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -84,6 +96,7 @@ func (f *Float) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object,
 //	error - 可能出现的错误
 func (f *Float) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -112,6 +125,7 @@ func (f *Float) Add(other Object, posStart, posEnd *util.Pos, frame *frame.Frame
 		return &Float{Value: f.Value + o.Value}, nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"+\".",
 			PosStart: posStart,
@@ -141,6 +155,7 @@ func (f *Float) Subtract(other Object, posStart, posEnd *util.Pos, frame *frame.
 		return &Float{Value: f.Value - o.Value}, nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"-\".",
 			PosStart: posStart,
@@ -172,6 +187,7 @@ func (f *Float) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame.
 		return &Float{Value: f.Value * o.Value}, nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"*\".",
 			PosStart: posStart,
@@ -199,6 +215,7 @@ func (f *Float) Divide(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 		// 浮点数 / 整数: 检查除数是否为0，然后将整数转换为浮点数后相除
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -210,6 +227,7 @@ func (f *Float) Divide(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 		// 浮点数 / 浮点数: 检查除数是否为0，然后直接相除
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -219,6 +237,7 @@ func (f *Float) Divide(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 		return &Float{Value: f.Value / o.Value}, nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"/\".",
 			PosStart: posStart,
@@ -246,6 +265,7 @@ func (f *Float) Mod(other Object, posStart, posEnd *util.Pos, frame *frame.Frame
 		// 浮点数 % 整数: 检查除数是否为0，然后将整数转换为浮点数后取模
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -257,6 +277,7 @@ func (f *Float) Mod(other Object, posStart, posEnd *util.Pos, frame *frame.Frame
 		// 浮点数 % 浮点数: 检查除数是否为0，然后直接取模
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -266,6 +287,7 @@ func (f *Float) Mod(other Object, posStart, posEnd *util.Pos, frame *frame.Frame
 		return &Float{Value: math.Mod(f.Value, o.Value)}, nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"%\".",
 			PosStart: posStart,
@@ -291,16 +313,16 @@ func (f *Float) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, err
 	switch o := other.(type) {
 	case *Int:
 		// 浮点数 == 整数: 将整数转换为浮点数后比较
-		return &Bool{Value: f.Value == float64(o.Value)}, nil
+		return BoolOf(f.Value == float64(o.Value)), nil
 	case *Float:
 		// 浮点数 == 浮点数: 直接比较
-		return &Bool{Value: f.Value == o.Value}, nil
+		return BoolOf(f.Value == o.Value), nil
 	case *Null:
 		// 浮点数 == null: 始终返回false
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	default:
 		// 与其他类型比较：返回false
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	}
 }
 
@@ -321,16 +343,16 @@ func (f *Float) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object,
 	switch o := other.(type) {
 	case *Int:
 		// 浮点数 != 整数: 将整数转换为浮点数后比较
-		return &Bool{Value: f.Value != float64(o.Value)}, nil
+		return BoolOf(f.Value != float64(o.Value)), nil
 	case *Float:
 		// 浮点数 != 浮点数: 直接比较
-		return &Bool{Value: f.Value != o.Value}, nil
+		return BoolOf(f.Value != o.Value), nil
 	case *Null:
 		// 浮点数 != null: 始终返回true
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	default:
 		// 与其他类型比较：返回true
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	}
 }
 
@@ -351,12 +373,13 @@ func (f *Float) LessThan(other Object, posStart, posEnd *util.Pos, frame *frame.
 	switch o := other.(type) {
 	case *Int:
 		// 浮点数 < 整数: 将整数转换为浮点数后比较
-		return &Bool{Value: f.Value < float64(o.Value)}, nil
+		return BoolOf(f.Value < float64(o.Value)), nil
 	case *Float:
 		// 浮点数 < 浮点数: 直接比较
-		return &Bool{Value: f.Value < o.Value}, nil
+		return BoolOf(f.Value < o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"<\".",
 			PosStart: posStart,
@@ -382,12 +405,13 @@ func (f *Float) GreaterThan(other Object, posStart, posEnd *util.Pos, frame *fra
 	switch o := other.(type) {
 	case *Int:
 		// 浮点数 > 整数: 将整数转换为浮点数后比较
-		return &Bool{Value: f.Value > float64(o.Value)}, nil
+		return BoolOf(f.Value > float64(o.Value)), nil
 	case *Float:
 		// 浮点数 > 浮点数: 直接比较
-		return &Bool{Value: f.Value > o.Value}, nil
+		return BoolOf(f.Value > o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \">\".",
 			PosStart: posStart,
@@ -413,12 +437,13 @@ func (f *Float) LessThanOrEqual(other Object, posStart, posEnd *util.Pos, frame
 	switch o := other.(type) {
 	case *Int:
 		// 浮点数 <= 整数: 将整数转换为浮点数后比较
-		return &Bool{Value: f.Value <= float64(o.Value)}, nil
+		return BoolOf(f.Value <= float64(o.Value)), nil
 	case *Float:
 		// 浮点数 <= 浮点数: 直接比较
-		return &Bool{Value: f.Value <= o.Value}, nil
+		return BoolOf(f.Value <= o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"<=\".",
 			PosStart: posStart,
@@ -444,12 +469,13 @@ func (f *Float) GreaterThanOrEqual(other Object, posStart, posEnd *util.Pos, fra
 	switch o := other.(type) {
 	case *Int:
 		// 浮点数 >= 整数: 将整数转换为浮点数后比较
-		return &Bool{Value: f.Value >= float64(o.Value)}, nil
+		return BoolOf(f.Value >= float64(o.Value)), nil
 	case *Float:
 		// 浮点数 >= 浮点数: 直接比较
-		return &Bool{Value: f.Value >= o.Value}, nil
+		return BoolOf(f.Value >= o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \">=\".",
 			PosStart: posStart,
@@ -473,6 +499,7 @@ func (f *Float) GreaterThanOrEqual(other Object, posStart, posEnd *util.Pos, fra
 //	error - 可能出现的错误
 func (f *Float) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -495,6 +522,7 @@ func (f *Float) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (f *Float) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -517,6 +545,7 @@ func (f *Float) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (f *Float) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -539,6 +568,7 @@ func (f *Float) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (O
 //	error - 可能出现的错误
 func (f *Float) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -561,6 +591,7 @@ func (f *Float) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fra
 //	error - 可能出现的错误
 func (f *Float) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -583,6 +614,7 @@ func (f *Float) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	error - 可能出现的错误
 func (f *Float) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -605,6 +637,7 @@ func (f *Float) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (O
 //	error - 可能出现的错误
 func (f *Float) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -626,9 +659,27 @@ func (f *Float) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (f *Float) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "index expression not supported for this type.",
 		PosStart: posStart,
 		PosEnd:   posEnd,
 	}
 }
+
+// HashKey 返回值作为映射键时的可哈希表示
+// 使用浮点数的位表示，0.0和-0.0是同一个键，所有NaN也是同一个键
+//
+// 返回值:
+//
+//	HashKey - 可哈希表示
+func (f *Float) HashKey() HashKey {
+	value := f.Value
+	switch {
+	case value == 0:
+		value = 0
+	case math.IsNaN(value):
+		value = math.NaN()
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(value)}
+}
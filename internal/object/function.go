@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
@@ -16,6 +17,7 @@ type Function struct {
 	Name      string           // 函数名
 	Parameter []*ast.Parameter // 参数
 	Body      ast.Statement    // 函数体
+	Layout    *ast.Layout      // 函数作用域的环境布局，函数声明未经作用域解析时为nil
 	Env       *Environment     // 环境
 }
 
@@ -29,16 +31,35 @@ func (f *Function) Type() string {
 }
 
 // String 返回值的字符串表示
+// 格式为：func <函数名>(<参数>...) {...}，带默认值的参数输出为"参数名=默认值"，匿名函数没有函数名
 //
 // 返回值:
 //
 //	string - 格式化的字符串表示
 func (f *Function) String() string {
-	var params []string
+	params := make([]string, 0, len(f.Parameter))
 	for _, param := range f.Parameter {
 		params = append(params, param.String())
 	}
-	return fmt.Sprintf("func %s(%s) {...}", f.Name, strings.Join(params, ", "))
+	return formatSignature(f.Name, params) + " {...}"
+}
+
+// formatSignature 生成函数签名的字符串表示，供用户函数与内建函数共用
+// 格式为：func <函数名>(<参数1>, <参数2>, ...)，函数名为空时为func(<参数1>, <参数2>, ...)
+//
+// 参数:
+//
+//	name - 函数名
+//	params - 已格式化的参数列表
+//
+// 返回值:
+//
+//	string - 函数签名
+func formatSignature(name string, params []string) string {
+	if name == "" {
+		return fmt.Sprintf("func(%s)", strings.Join(params, ", "))
+	}
+	return fmt.Sprintf("func %s(%s)", name, strings.Join(params, ", "))
 }
 
 // Negative 对值进行负运算
@@ -55,6 +76,7 @@ func (f *Function) String() string {
 //	error - 可能出现的错误
 func (f *Function) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -76,6 +98,7 @@ func (f *Function) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Obj
 //	error - 可能出现的错误
 func (f *Function) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -97,6 +120,7 @@ func (f *Function) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Objec
 //	error - 可能出现的错误
 func (f *Function) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -119,6 +143,7 @@ func (f *Function) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object,
 //	error - 可能出现的错误
 func (f *Function) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"+\".",
 		PosStart: posStart,
@@ -141,6 +166,7 @@ func (f *Function) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (f *Function) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -163,6 +189,7 @@ func (f *Function) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.F
 //	error - 可能出现的错误
 func (f *Function) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"*\".",
 		PosStart: posStart,
@@ -185,6 +212,7 @@ func (f *Function) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.F
 //	error - 可能出现的错误
 func (f *Function) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"/\".",
 		PosStart: posStart,
@@ -207,6 +235,7 @@ func (f *Function) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Fra
 //	error - 可能出现的错误
 func (f *Function) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"%\".",
 		PosStart: posStart,
@@ -233,9 +262,9 @@ func (f *Function) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (Object,
 	// 函数相等比较规则: 比较引用是否相等
 	otherFunc, ok := other.(*Function)
 	if !ok {
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	}
-	return &Bool{Value: f == otherFunc}, nil
+	return BoolOf(f == otherFunc), nil
 }
 
 // NotEqual 判断当前函数与另一个值是否不相等
@@ -257,9 +286,9 @@ func (f *Function) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Objec
 	// 函数不等比较规则: 比较引用是否不等
 	otherFunc, ok := other.(*Function)
 	if !ok {
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	}
-	return &Bool{Value: f != otherFunc}, nil
+	return BoolOf(f != otherFunc), nil
 }
 
 // LessThan 对值进行小于比较
@@ -276,6 +305,7 @@ func (f *Function) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Objec
 //	Object - 比较结果
 func (f *Function) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<\".",
 		PosStart: posStart,
@@ -297,6 +327,7 @@ func (f *Function) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.F
 //	Object - 比较结果
 func (f *Function) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">\".",
 		PosStart: posStart,
@@ -318,6 +349,7 @@ func (f *Function) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *fram
 //	Object - 比较结果
 func (f *Function) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<=\".",
 		PosStart: posStart,
@@ -339,6 +371,7 @@ func (f *Function) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *
 //	Object - 比较结果
 func (f *Function) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">=\".",
 		PosStart: posStart,
@@ -361,6 +394,7 @@ func (f *Function) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, fram
 //	error - 可能出现的错误
 func (f *Function) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -383,6 +417,7 @@ func (f *Function) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Fra
 //	error - 可能出现的错误
 func (f *Function) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -405,6 +440,7 @@ func (f *Function) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Fram
 //	error - 可能出现的错误
 func (f *Function) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -427,6 +463,7 @@ func (f *Function) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (f *Function) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -449,6 +486,7 @@ func (f *Function) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.
 //	error - 可能出现的错误
 func (f *Function) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -471,6 +509,7 @@ func (f *Function) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame
 //	error - 可能出现的错误
 func (f *Function) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -493,6 +532,7 @@ func (f *Function) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (f *Function) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -514,9 +554,35 @@ func (f *Function) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (f *Function) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "index expression not supported for this type.",
 		PosStart: posStart,
 		PosEnd:   posEnd,
 	}
 }
+
+// ArgumentCountMessage 检查传入参数数量是否与函数定义匹配，返回参数数量错误的描述
+//
+// 参数:
+//
+//	paramLen - 参数总数
+//	defaultLen - 有默认值的参数数量
+//	argLen - 传入参数数量
+//
+// 返回值:
+//
+//	string - 参数数量不匹配时的错误描述，匹配时为空
+func ArgumentCountMessage(paramLen, defaultLen, argLen int) string {
+	least := paramLen - defaultLen
+	if least <= argLen && argLen <= paramLen {
+		return ""
+	}
+	if defaultLen == 0 {
+		return fmt.Sprintf("expected %d parameters, got %d.", paramLen, argLen)
+	}
+	if least == 1 {
+		return fmt.Sprintf("expected between 1 parameter and %d parameters, got %d.", paramLen, argLen)
+	}
+	return fmt.Sprintf("expected between %d and %d parameters, got %d.", least, paramLen, argLen)
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"strings"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -35,6 +36,7 @@ func (i *Int) String() string {
 }
 
 // Negative 对值进行负运算
+// 最小的整数取负会溢出，此时返回数学错误
 //
 // 参数:
 //
@@ -46,7 +48,16 @@ func (i *Int) String() string {
 //
 //	Object - 运算结果
 //	error - 可能出现的错误
-func (i *Int) Negative(*util.Pos, *util.Pos, *frame.Frame) (Object, error) {
+func (i *Int) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	if i.Value == math.MinInt64 {
+		return nil, &MathError{
+			Code:     errcode.MathError,
+			Frame:    frame,
+			Message:  "integer overflow.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
 	return &Int{Value: -i.Value}, nil
 }
 
@@ -80,6 +91,7 @@ func (i *Int) BitNot(*util.Pos, *util.Pos, *frame.Frame) (Object, error) {
 //	error - 可能出现的错误
 func (i *Int) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -112,6 +124,7 @@ func (i *Int) Add(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 	default:
 		// 不支持的操作数类型
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"+\".",
 			PosStart: posStart,
@@ -145,6 +158,7 @@ func (i *Int) Subtract(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 	default:
 		// 不支持的操作数类型
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"-\".",
 			PosStart: posStart,
@@ -179,6 +193,7 @@ func (i *Int) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 		// 整数*字符串=重复字符串(仅支持非负整数)
 		if i.Value < 0 {
 			return nil, &OperationError{
+				Code:     errcode.OperationError,
 				Frame:    frame,
 				Message:  "invalid operation \"*\".",
 				PosStart: posStart,
@@ -188,6 +203,7 @@ func (i *Int) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 		// 检查整数是否超出最大可表示范围
 		if i.Value > math.MaxInt {
 			return nil, &OperationError{
+				Code:     errcode.OperationError,
 				Frame:    frame,
 				Message:  "invalid operation \"*\".",
 				PosStart: posStart,
@@ -199,6 +215,7 @@ func (i *Int) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 	default:
 		// 不支持的操作数类型
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"*\".",
 			PosStart: posStart,
@@ -227,6 +244,7 @@ func (i *Int) Divide(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 		// 整数除法，除数为0时返回错误
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -239,6 +257,7 @@ func (i *Int) Divide(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 		// 浮点数除法，除数为0时返回错误
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -250,6 +269,7 @@ func (i *Int) Divide(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 	default:
 		// 不支持的操作数类型
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"/\".",
 			PosStart: posStart,
@@ -278,6 +298,7 @@ func (i *Int) Mod(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 		// 整数取模，除数为0时返回错误
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -290,6 +311,7 @@ func (i *Int) Mod(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 		// 浮点数取模，除数为0时返回错误
 		if o.Value == 0 {
 			return nil, &MathError{
+				Code:     errcode.MathError,
 				Frame:    frame,
 				Message:  "division by zero.",
 				PosStart: posStart,
@@ -301,6 +323,7 @@ func (i *Int) Mod(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 	default:
 		// 不支持的操作数类型
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"%\".",
 			PosStart: posStart,
@@ -326,13 +349,13 @@ func (i *Int) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, error
 	switch o := other.(type) {
 	case *Int:
 		// 与整数比较：直接比较整数值
-		return &Bool{Value: i.Value == o.Value}, nil
+		return BoolOf(i.Value == o.Value), nil
 	case *Float:
 		// 与浮点数比较：将整数转换为浮点数后比较
-		return &Bool{Value: float64(i.Value) == o.Value}, nil
+		return BoolOf(float64(i.Value) == o.Value), nil
 	default:
 		// 与其他类型比较：返回false
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	}
 }
 
@@ -353,13 +376,13 @@ func (i *Int) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, er
 	switch o := other.(type) {
 	case *Int:
 		// 与整数比较：直接比较整数值
-		return &Bool{Value: i.Value != o.Value}, nil
+		return BoolOf(i.Value != o.Value), nil
 	case *Float:
 		// 与浮点数比较：将整数转换为浮点数后比较
-		return &Bool{Value: float64(i.Value) != o.Value}, nil
+		return BoolOf(float64(i.Value) != o.Value), nil
 	default:
 		// 与其他类型比较：返回true
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	}
 }
 
@@ -380,12 +403,13 @@ func (i *Int) LessThan(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 	switch o := other.(type) {
 	case *Int:
 		// 与整数比较：直接比较整数值
-		return &Bool{Value: i.Value < o.Value}, nil
+		return BoolOf(i.Value < o.Value), nil
 	case *Float:
 		// 与浮点数比较：将整数转换为浮点数后比较
-		return &Bool{Value: float64(i.Value) < o.Value}, nil
+		return BoolOf(float64(i.Value) < o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"<\".",
 			PosStart: posStart,
@@ -411,12 +435,13 @@ func (i *Int) GreaterThan(other Object, posStart, posEnd *util.Pos, frame *frame
 	switch o := other.(type) {
 	case *Int:
 		// 与整数比较：直接比较整数值
-		return &Bool{Value: i.Value > o.Value}, nil
+		return BoolOf(i.Value > o.Value), nil
 	case *Float:
 		// 与浮点数比较：将整数转换为浮点数后比较
-		return &Bool{Value: float64(i.Value) > o.Value}, nil
+		return BoolOf(float64(i.Value) > o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \">\".",
 			PosStart: posStart,
@@ -442,12 +467,13 @@ func (i *Int) LessThanOrEqual(other Object, posStart, posEnd *util.Pos, frame *f
 	switch o := other.(type) {
 	case *Int:
 		// 与整数比较：直接比较整数值
-		return &Bool{Value: i.Value <= o.Value}, nil
+		return BoolOf(i.Value <= o.Value), nil
 	case *Float:
 		// 与浮点数比较：将整数转换为浮点数后比较
-		return &Bool{Value: float64(i.Value) <= o.Value}, nil
+		return BoolOf(float64(i.Value) <= o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"<=\".",
 			PosStart: posStart,
@@ -473,12 +499,13 @@ func (i *Int) GreaterThanOrEqual(other Object, posStart, posEnd *util.Pos, frame
 	switch o := other.(type) {
 	case *Int:
 		// 与整数比较：直接比较整数值
-		return &Bool{Value: i.Value >= o.Value}, nil
+		return BoolOf(i.Value >= o.Value), nil
 	case *Float:
 		// 与浮点数比较：将整数转换为浮点数后比较
-		return &Bool{Value: float64(i.Value) >= o.Value}, nil
+		return BoolOf(float64(i.Value) >= o.Value), nil
 	default:
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \">=\".",
 			PosStart: posStart,
@@ -512,6 +539,7 @@ func (i *Int) BitAnd(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 	} else {
 		// 类型不支持，返回操作错误
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"&\".",
 			PosStart: posStart,
@@ -545,6 +573,7 @@ func (i *Int) BitOr(other Object, posStart, posEnd *util.Pos, frame *frame.Frame
 	} else {
 		// 类型不支持，返回操作错误
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"|\".",
 			PosStart: posStart,
@@ -578,6 +607,7 @@ func (i *Int) Xor(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 	} else {
 		// 类型不支持，返回操作错误
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"^\".",
 			PosStart: posStart,
@@ -612,6 +642,7 @@ func (i *Int) LeftShift(other Object, posStart, posEnd *util.Pos, frame *frame.F
 		// 检查右操作数是否为负数
 		if o.Value < 0 {
 			return nil, &OperationError{
+				Code:     errcode.OperationError,
 				Frame:    frame,
 				Message:  "invalid operation \"<<\".",
 				PosStart: posStart,
@@ -623,6 +654,7 @@ func (i *Int) LeftShift(other Object, posStart, posEnd *util.Pos, frame *frame.F
 	} else {
 		// 类型不支持，返回操作错误
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"<<\".",
 			PosStart: posStart,
@@ -655,6 +687,7 @@ func (i *Int) RightShift(other Object, posStart, posEnd *util.Pos, frame *frame.
 		// 检查右操作数是否为负数
 		if o.Value < 0 {
 			return nil, &OperationError{
+				Code:     errcode.OperationError,
 				Frame:    frame,
 				Message:  "invalid operation \">>\".",
 				PosStart: posStart,
@@ -666,6 +699,7 @@ func (i *Int) RightShift(other Object, posStart, posEnd *util.Pos, frame *frame.
 	} else {
 		// 类型不支持，返回操作错误
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \">>\".",
 			PosStart: posStart,
@@ -689,6 +723,7 @@ func (i *Int) RightShift(other Object, posStart, posEnd *util.Pos, frame *frame.
 //	error - 可能出现的错误
 func (i *Int) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -711,6 +746,7 @@ func (i *Int) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Obj
 //	error - 可能出现的错误
 func (i *Int) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -732,9 +768,19 @@ func (i *Int) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Obje
 //	error - 可能出现的错误
 func (i *Int) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "index expression not supported for this type.",
 		PosStart: posStart,
 		PosEnd:   posEnd,
 	}
 }
+
+// HashKey 返回值作为映射键时的可哈希表示
+//
+// 返回值:
+//
+//	HashKey - 可哈希表示
+func (i *Int) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: i.Value}
+}
@@ -0,0 +1,638 @@
+package object
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// Iterator 表示惰性迭代器类型，实现了Object接口
+// 每次调用Next时才计算下一个值，不需要预先构造完整的列表，只能遍历一次
+
+type Iterator struct {
+	Name string                       // 产生迭代器的内置函数名
+	next func() (Object, bool, error) // 计算下一个值的函数
+	done bool                         // 迭代是否已经结束
+}
+
+// NewIterator 创建惰性迭代器
+//
+// 参数:
+//
+//	name - 产生迭代器的内置函数名，用于字符串表示
+//	next - 计算下一个值的函数，没有更多值时第二个返回值为false
+//
+// 返回值:
+//
+//	*Iterator - 迭代器
+func NewIterator(name string, next func() (Object, bool, error)) *Iterator {
+	return &Iterator{Name: name, next: next}
+}
+
+// Next 计算迭代器的下一个值，迭代结束或出错后不再调用next
+//
+// 返回值:
+//
+//	Object - 下一个值，迭代结束时为nil
+//	bool - 是否产生了值，迭代结束时为false
+//	error - 计算下一个值时的错误
+func (it *Iterator) Next() (Object, bool, error) {
+	if it.done {
+		return nil, false, nil
+	}
+	value, ok, err := it.next()
+	if !ok || err != nil {
+		it.done = true
+	}
+	return value, ok, err
+}
+
+// Type 返回值的类型
+//
+// 返回值:
+//
+//	string - 值的类型
+func (it *Iterator) Type() string {
+	return "Iterator"
+}
+
+// String 返回值的字符串表示
+// 格式为：<iterator 内置函数名>，不会消耗迭代器中的值
+//
+// 返回值:
+//
+//	string - 格式化的字符串表示
+func (it *Iterator) String() string {
+	return fmt.Sprintf("<iterator %s>", it.Name)
+}
+
+// Negative 对值进行负运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitNot 对值进行按位非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"~\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Not 对值进行逻辑非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"!\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Add 对值进行加法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"+\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Subtract 对值进行减法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Multiply 对值进行乘法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"*\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Divide 对值进行除法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"/\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Mod 对值进行取模运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"%\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Equal 判断当前迭代器与另一个值是否相等
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	布尔值，表示比较结果；无错误
+//
+// 比较规则:
+//
+//	引用性比较
+func (it *Iterator) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, error) {
+	// 迭代器相等比较规则: 比较引用是否相等
+	otherIter, ok := other.(*Iterator)
+	if !ok {
+		return FalseObj, nil
+	}
+	return BoolOf(it == otherIter), nil
+}
+
+// NotEqual 判断当前迭代器与另一个值是否不相等
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	布尔值，表示比较结果；无错误
+//
+// 比较规则:
+//
+//	引用性比较
+func (it *Iterator) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, error) {
+	// 迭代器不等比较规则: 比较引用是否不等
+	otherIter, ok := other.(*Iterator)
+	if !ok {
+		return TrueObj, nil
+	}
+	return BoolOf(it != otherIter), nil
+}
+
+// LessThan 对值进行小于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (it *Iterator) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThan 对值进行大于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (it *Iterator) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LessThanOrEqual 对值进行小于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (it *Iterator) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThanOrEqual 对值进行大于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+func (it *Iterator) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitAnd 对值进行按位与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitOr 对值进行按位或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"|\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Xor 对值进行异或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"^\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LeftShift 对值进行左移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// RightShift 对值进行右移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">>\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// And 对值进行逻辑与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Or 对值进行逻辑或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"||\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Index 执行索引运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (it *Iterator) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &TypeError{
+		Code:     errcode.TypeError,
+		Frame:    frame,
+		Message:  "index expression not supported for this type.",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Iterate 返回遍历可迭代值的迭代器
+// 列表按顺序产生元素，字符串按顺序产生每个字符组成的字符串，映射按插入顺序产生键，迭代器返回其本身
+// 遍历映射时只产生开始遍历时已有的键
+//
+// 参数:
+//
+//	obj - 要遍历的值
+//
+// 返回值:
+//
+//	*Iterator - 迭代器
+//	bool - 值是否可迭代
+func Iterate(obj Object) (*Iterator, bool) {
+	switch v := obj.(type) {
+	case *Iterator:
+		return v, true
+	case *List:
+		index := 0
+		return NewIterator("list", func() (Object, bool, error) {
+			if index >= len(v.Elements) {
+				return nil, false, nil
+			}
+			index++
+			return v.Elements[index-1], true, nil
+		}), true
+	case *String:
+		offset := 0
+		return NewIterator("string", func() (Object, bool, error) {
+			if offset >= len(v.Value) {
+				return nil, false, nil
+			}
+			_, size := utf8.DecodeRuneInString(v.Value[offset:])
+			offset += size
+			return &String{Value: v.Value[offset-size : offset]}, true, nil
+		}), true
+	case *Map:
+		keys := v.Keys
+		index := 0
+		return NewIterator("map", func() (Object, bool, error) {
+			if index >= len(keys) {
+				return nil, false, nil
+			}
+			index++
+			return v.Entries[keys[index-1]].Key, true, nil
+		}), true
+	default:
+		return nil, false
+	}
+}
+
+// iterateSnapshot 与Iterate相同，但遍历列表时先复制元素，遍历期间调用的函数修改原列表时不影响遍历
+//
+// 参数:
+//
+//	obj - 要遍历的值
+//
+// 返回值:
+//
+//	*Iterator - 迭代器
+//	bool - 值是否可迭代
+func iterateSnapshot(obj Object) (*Iterator, bool) {
+	if list, ok := obj.(*List); ok {
+		return Iterate(&List{Elements: slices.Clone(list.Elements)})
+	}
+	return Iterate(obj)
+}
@@ -1,8 +1,9 @@
 package object
 
 import (
-	"strings"
+	"fmt"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -20,20 +21,48 @@ type List struct {
 //
 //	string - 值的类型
 func (l *List) Type() string {
-	return "LIST"
+	return "List"
 }
 
-// String 返回值的字符串表示
+// ElementType 返回列表的元素类型，即第一个非空元素的类型
+// 空值表示缺失的值，可以出现在任意类型的列表中，不参与确定元素类型
 //
 // 返回值:
 //
-//	string - 格式化的字符串表示
-func (l *List) String() string {
-	var elements []string
+//	string - 元素类型，列表为空或只包含空值时为空字符串
+func (l *List) ElementType() string {
 	for _, elem := range l.Elements {
-		elements = append(elements, elem.String())
+		if _, ok := elem.(*Null); !ok {
+			return elem.Type()
+		}
 	}
-	return "[" + strings.Join(elements, ", ") + "]"
+	return ""
+}
+
+// Accepts 判断值能否作为列表的元素，空值与任意元素类型兼容
+//
+// 参数:
+//
+//	value - 要放入列表的值
+//
+// 返回值:
+//
+//	bool - 值为空值、列表没有确定的元素类型或值的类型与元素类型相同时为true
+func (l *List) Accepts(value Object) bool {
+	if _, ok := value.(*Null); ok {
+		return true
+	}
+	elementType := l.ElementType()
+	return elementType == "" || value.Type() == elementType
+}
+
+// String 返回值的字符串表示
+//
+// 返回值:
+//
+//	string - 格式化的字符串表示，包含自身的列表中内层的自身输出为[...]
+func (l *List) String() string {
+	return format(l, false, false, make(map[Object]bool))
 }
 
 // Negative 对值进行负运算
@@ -50,6 +79,7 @@ func (l *List) String() string {
 //	error - 可能出现的错误
 func (l *List) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -71,6 +101,7 @@ func (l *List) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object,
 //	error - 可能出现的错误
 func (l *List) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -92,6 +123,7 @@ func (l *List) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, e
 //	error - 可能出现的错误
 func (l *List) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -114,10 +146,12 @@ func (l *List) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, erro
 //	error - 可能出现的错误
 func (l *List) Add(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	if otherList, ok := other.(*List); ok {
-		// 检查列表元素类型一致性
-		if len(l.Elements) > 0 && len(otherList.Elements) > 0 {
-			if l.Elements[0].Type() != otherList.Elements[0].Type() {
+		// 检查列表元素类型一致性，空值不参与比较
+		leftType, rightType := l.ElementType(), otherList.ElementType()
+		if leftType != "" && rightType != "" {
+			if leftType != rightType {
 				return nil, &OperationError{
+					Code:     errcode.OperationError,
 					Frame:    frame,
 					Message:  "cannot concatenate lists with different element types.",
 					PosStart: posStart,
@@ -126,14 +160,17 @@ func (l *List) Add(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 			}
 		}
 		// 创建新列表
+		// 列表元素可以通过索引赋值原地修改，与字符串不同，不能让 += 的结果与之前的列表共享底层数组
 		newElements := make([]Object, 0, len(l.Elements)+len(otherList.Elements))
 		newElements = append(newElements, l.Elements...)
 		newElements = append(newElements, otherList.Elements...)
 		return &List{Elements: newElements}, nil
 	}
+	// 列表只能与列表拼接，提示将单个元素放入列表中
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
-		Message:  "invalid operation \"+\".",
+		Message:  fmt.Sprintf("cannot add %s to %s; concatenate a list instead, e.g. list + [element].", other.Type(), l.Type()),
 		PosStart: posStart,
 		PosEnd:   posEnd,
 	}
@@ -154,6 +191,7 @@ func (l *List) Add(other Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (l *List) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -180,6 +218,7 @@ func (l *List) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame.F
 		// 负数或零次重复返回错误
 		if times <= 0 {
 			return nil, &OperationError{
+				Code:     errcode.OperationError,
 				Frame:    frame,
 				Message:  "invalid operation \"*\".",
 				PosStart: posStart,
@@ -199,6 +238,7 @@ func (l *List) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame.F
 		return &List{Elements: newElements}, nil
 	}
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"*\".",
 		PosStart: posStart,
@@ -221,6 +261,7 @@ func (l *List) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame.F
 //	error - 可能出现的错误
 func (l *List) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"/\".",
 		PosStart: posStart,
@@ -243,6 +284,7 @@ func (l *List) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (l *List) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"%\".",
 		PosStart: posStart,
@@ -269,20 +311,15 @@ func (l *List) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 func (l *List) Equal(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	if otherList, ok := other.(*List); ok {
 		if len(l.Elements) != len(otherList.Elements) {
-			return &Bool{Value: false}, nil
+			return FalseObj, nil
 		}
-		for i := range l.Elements {
-			equal, err := l.Elements[i].Equal(otherList.Elements[i], posStart, posEnd, frame)
-			if err != nil {
-				return nil, err
-			}
-			if !equal.(*Bool).Value {
-				return &Bool{Value: false}, nil
-			}
+		equal, err := equals(l, otherList, posStart, posEnd, frame, make(map[[2]Object]bool))
+		if err != nil {
+			return nil, err
 		}
-		return &Bool{Value: true}, nil
+		return BoolOf(equal), nil
 	}
-	return &Bool{Value: false}, nil
+	return FalseObj, nil
 }
 
 // NotEqual 判断当前值与另一个值是否不相等
@@ -306,7 +343,7 @@ func (l *List) NotEqual(other Object, posStart, posEnd *util.Pos, frame *frame.F
 	if err != nil {
 		return nil, err
 	}
-	return &Bool{Value: !equal.(*Bool).Value}, nil
+	return BoolOf(!equal.(*Bool).Value), nil
 }
 
 // LessThan 对值进行小于比较
@@ -323,6 +360,7 @@ func (l *List) NotEqual(other Object, posStart, posEnd *util.Pos, frame *frame.F
 //	Object - 比较结果
 func (l *List) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<\".",
 		PosStart: posStart,
@@ -344,6 +382,7 @@ func (l *List) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	Object - 比较结果
 func (l *List) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">\".",
 		PosStart: posStart,
@@ -365,6 +404,7 @@ func (l *List) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	Object - 比较结果
 func (l *List) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<=\".",
 		PosStart: posStart,
@@ -386,6 +426,7 @@ func (l *List) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *fram
 //	Object - 比较结果
 func (l *List) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">=\".",
 		PosStart: posStart,
@@ -408,6 +449,7 @@ func (l *List) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *f
 //	error - 可能出现的错误
 func (l *List) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -430,6 +472,7 @@ func (l *List) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (l *List) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -452,6 +495,7 @@ func (l *List) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (
 //	error - 可能出现的错误
 func (l *List) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -474,6 +518,7 @@ func (l *List) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (l *List) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -496,6 +541,7 @@ func (l *List) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fram
 //	error - 可能出现的错误
 func (l *List) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -518,6 +564,7 @@ func (l *List) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fra
 //	error - 可能出现的错误
 func (l *List) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -540,6 +587,7 @@ func (l *List) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (l *List) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -548,9 +596,11 @@ func (l *List) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Obj
 }
 
 // Index 执行索引运算
+// 索引为整数列表时按顺序选取对应的元素，返回新的列表
 //
 // 参数:
 //
+//	other - 整数索引或整数列表
 //	posStart - 表达式起始位置
 //	posEnd - 表达式结束位置
 //	frame - 当前调用栈
@@ -560,13 +610,37 @@ func (l *List) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Obj
 //	Object - 运算结果
 //	error - 可能出现的错误
 func (l *List) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	if indices, ok := other.(*List); ok {
+		elements := make([]Object, 0, len(indices.Elements))
+		for _, index := range indices.Elements {
+			if _, ok := index.(*Int); !ok {
+				return nil, &TypeError{
+					Code:     errcode.TypeError,
+					Frame:    frame,
+					Message:  "index list members must be integers.",
+					PosStart: posStart,
+					PosEnd:   posEnd,
+				}
+			}
+			elem, err := l.Index(index, posStart, posEnd, frame)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, elem)
+		}
+		return &List{Elements: elements}, nil
+	}
 	length := int64(len(l.Elements))
-	real := other.(*Int).Value
+	real, err := intIndex(other, posStart, posEnd, frame)
+	if err != nil {
+		return nil, err
+	}
 	if real < 0 {
 		real = length + real
 	}
 	if real < 0 || real >= length {
 		return nil, &IndexError{
+			Code:     errcode.IndexError,
 			Frame:    frame,
 			Message:  "index out of range.",
 			PosStart: posStart,
@@ -576,6 +650,28 @@ func (l *List) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 	return l.Elements[int(real)], nil
 }
 
+// Slice 截取列表中的元素并返回新的列表，与String.Slice一致地处理负数和越界的索引
+//
+// 参数:
+//
+//	start - 起始索引(包含)
+//	end - 结束索引(不包含)
+//
+// 返回值:
+//
+//	*List - 包含截取元素的新列表，不与原列表共用存储
+func (l *List) Slice(start, end int64) *List {
+	length := int64(len(l.Elements))
+	start = clampSliceIndex(start, length)
+	end = clampSliceIndex(end, length)
+	if start >= end {
+		return &List{Elements: make([]Object, 0)}
+	}
+	elements := make([]Object, end-start)
+	copy(elements, l.Elements[start:end])
+	return &List{Elements: elements}
+}
+
 // Set 设置索引位置的值
 //
 // 参数:
@@ -591,20 +687,25 @@ func (l *List) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 //	error - 可能出现的错误
 func (l *List) Set(index Object, value Object, posStart, posEnd *util.Pos, frame *frame.Frame) error {
 	length := int64(len(l.Elements))
-	real := index.(*Int).Value
+	real, err := intIndex(index, posStart, posEnd, frame)
+	if err != nil {
+		return err
+	}
 	if real < 0 {
 		real = length + real
 	}
 	if real < 0 || real >= length {
 		return &IndexError{
+			Code:     errcode.IndexError,
 			Frame:    frame,
 			Message:  "index out of range.",
 			PosStart: posStart,
 			PosEnd:   posEnd,
 		}
 	}
-	if value.Type() != l.Elements[0].Type() {
+	if !l.Accepts(value) {
 		return &TypeError{
+			Code:     errcode.TypeError,
 			Frame:    frame,
 			Message:  "list elements must have consistent types.",
 			PosStart: posStart,
@@ -614,3 +715,56 @@ func (l *List) Set(index Object, value Object, posStart, posEnd *util.Pos, frame
 	l.Elements[int(real)] = value
 	return nil
 }
+
+// intIndex 取得整数索引的值，索引不是整数时返回类型错误
+//
+// 参数:
+//
+//	index - 索引值
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	int64 - 索引的值
+//	error - 索引不是整数时的类型错误
+func intIndex(index Object, posStart, posEnd *util.Pos, frame *frame.Frame) (int64, error) {
+	i, ok := index.(*Int)
+	if !ok {
+		return 0, &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    frame,
+			Message:  "index must be integer.",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	return i.Value, nil
+}
+
+// ListElementSize 计算列表大小时每个元素占用的字节数，即一个接口值的大小
+const ListElementSize = 16
+
+// AllocSize 计算字符串、列表或映射自身占用的字节数，用于检查解释器的大小限制
+// 列表和映射只计算元素本身，不递归计算元素指向的值
+//
+// 参数:
+//
+//	obj - 要计算的值
+//
+// 返回值:
+//
+//	int64 - 字符串的UTF-8字节数，列表元素个数乘以ListElementSize，映射键值对个数乘以两倍的ListElementSize，其他值为0
+func AllocSize(obj Object) int64 {
+	switch o := obj.(type) {
+	case *String:
+		return int64(len(o.Value))
+	case *List:
+		return int64(len(o.Elements)) * ListElementSize
+	case *Map:
+		return int64(o.Len()) * 2 * ListElementSize
+	default:
+		return 0
+	}
+}
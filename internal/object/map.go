@@ -0,0 +1,682 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// HashKey 映射中键的可哈希表示，类型不同的键互不相等，整数1和浮点数1.0是两个不同的键
+
+type HashKey struct {
+	Type  string // 键的类型
+	Value any    // 键的值，为int64、uint64(浮点数的位表示)、string或bool
+}
+
+// Hashable 可以作为映射键的值，整数、浮点数、字符串和布尔值实现此接口
+
+type Hashable interface {
+	Object
+
+	// HashKey 返回值作为映射键时的可哈希表示
+	//
+	// 返回值:
+	//
+	//	HashKey - 可哈希表示，相等的值返回相等的表示
+	HashKey() HashKey
+}
+
+// MapEntry 映射中的一个键值对
+
+type MapEntry struct {
+	Key   Object // 键
+	Value Object // 值
+}
+
+// Map 映射类型结构体，表示运行时的键值对集合
+// 键按插入顺序排列，决定字符串表示和遍历的顺序；值的类型可以各不相同
+// 实现Object接口
+
+type Map struct {
+	Entries map[HashKey]*MapEntry // 按键的可哈希表示存放的键值对
+	Keys    []HashKey             // 键的插入顺序
+}
+
+// NewMap 创建一个空映射
+//
+// 返回值:
+//
+//	*Map - 空映射
+func NewMap() *Map {
+	return &Map{Entries: make(map[HashKey]*MapEntry)}
+}
+
+// Type 返回值的类型
+//
+// 返回值:
+//
+//	string - 值的类型
+func (m *Map) Type() string {
+	return "Map"
+}
+
+// Len 返回映射中键值对的数量
+//
+// 返回值:
+//
+//	int - 键值对的数量
+func (m *Map) Len() int {
+	return len(m.Keys)
+}
+
+// Get 按键查找值
+//
+// 参数:
+//
+//	key - 键
+//
+// 返回值:
+//
+//	Object - 键对应的值
+//	bool - 键是否存在，不可哈希的键始终不存在
+func (m *Map) Get(key Object) (Object, bool) {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := m.Entries[hashable.HashKey()]
+	if !ok {
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+// Pairs 按插入顺序返回所有键值对
+//
+// 返回值:
+//
+//	[]*MapEntry - 键值对，修改其中的值会影响映射
+func (m *Map) Pairs() []*MapEntry {
+	pairs := make([]*MapEntry, len(m.Keys))
+	for i, hashKey := range m.Keys {
+		pairs[i] = m.Entries[hashKey]
+	}
+	return pairs
+}
+
+// String 返回值的字符串表示
+//
+// 返回值:
+//
+//	string - 格式化的字符串表示，空映射为{:}，包含自身的映射中内层的自身输出为{...}
+func (m *Map) String() string {
+	return format(m, false, false, make(map[Object]bool))
+}
+
+// Negative 对值进行负运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitNot 对值进行按位非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"~\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Not 对值进行逻辑非运算
+//
+// 参数:
+//
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"!\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Add 对值进行加法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"+\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Subtract 对值进行减法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"-\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Multiply 对值进行乘法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"*\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Divide 对值进行除法运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"/\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Mod 对值进行取模运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"%\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Equal 判断当前值与另一个值是否相等
+// 两个映射的键相同且每个键对应的值相等时相等，与插入顺序无关
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+//	error - 比较值时可能出现的错误
+func (m *Map) Equal(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	otherMap, ok := other.(*Map)
+	if !ok {
+		return FalseObj, nil
+	}
+	equal, err := equals(m, otherMap, posStart, posEnd, frame, make(map[[2]Object]bool))
+	if err != nil {
+		return nil, err
+	}
+	return BoolOf(equal), nil
+}
+
+// NotEqual 判断当前值与另一个值是否不相等
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+//	error - 比较值时可能出现的错误
+func (m *Map) NotEqual(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	equal, err := m.Equal(other, posStart, posEnd, frame)
+	if err != nil {
+		return nil, err
+	}
+	return BoolOf(!equal.(*Bool).Value), nil
+}
+
+// LessThan 对值进行小于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+//	error - 可能出现的错误
+func (m *Map) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThan 对值进行大于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+//	error - 可能出现的错误
+func (m *Map) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LessThanOrEqual 对值进行小于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+//	error - 可能出现的错误
+func (m *Map) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// GreaterThanOrEqual 对值进行大于等于比较
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 比较结果
+//	error - 可能出现的错误
+func (m *Map) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">=\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitAnd 对值进行按位与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// BitOr 对值进行按位或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"|\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Xor 对值进行异或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"^\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// LeftShift 对值进行左移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"<<\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// RightShift 对值进行右移运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \">>\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// And 对值进行逻辑与运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"&&\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Or 对值进行逻辑或运算
+//
+// 参数:
+//
+//	other - 另一个操作数
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 运算结果
+//	error - 可能出现的错误
+func (m *Map) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	return nil, &OperationError{
+		Code:     errcode.OperationError,
+		Frame:    frame,
+		Message:  "invalid operation \"||\".",
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
+
+// Index 执行索引运算，返回键对应的值
+//
+// 参数:
+//
+//	other - 键
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	Object - 键对应的值
+//	error - 键不可哈希时的类型错误，键不存在时的索引错误
+func (m *Map) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	hashKey, err := mapKey(other, posStart, posEnd, frame)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := m.Entries[hashKey]
+	if !ok {
+		return nil, &IndexError{
+			Code:     errcode.IndexError,
+			Frame:    frame,
+			Message:  fmt.Sprintf("key %s not found in map.", Repr(other, false)),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	return entry.Value, nil
+}
+
+// Set 设置键对应的值，键不存在时添加到映射的末尾，已存在时替换原来的值并保持其位置
+//
+// 参数:
+//
+//	index - 键
+//	value - 要设置的值
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	error - 键不可哈希时的类型错误
+func (m *Map) Set(index Object, value Object, posStart, posEnd *util.Pos, frame *frame.Frame) error {
+	hashKey, err := mapKey(index, posStart, posEnd, frame)
+	if err != nil {
+		return err
+	}
+	if entry, ok := m.Entries[hashKey]; ok {
+		entry.Value = value
+		return nil
+	}
+	m.Entries[hashKey] = &MapEntry{Key: index, Value: value}
+	m.Keys = append(m.Keys, hashKey)
+	return nil
+}
+
+// mapKey 取得映射键的可哈希表示，键不可哈希时返回类型错误
+//
+// 参数:
+//
+//	key - 键
+//	posStart - 表达式起始位置
+//	posEnd - 表达式结束位置
+//	frame - 当前调用栈
+//
+// 返回值:
+//
+//	HashKey - 键的可哈希表示
+//	error - 键不是整数、浮点数、字符串或布尔值时的类型错误
+func mapKey(key Object, posStart, posEnd *util.Pos, frame *frame.Frame) (HashKey, error) {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return HashKey{}, &TypeError{
+			Code:     errcode.TypeError,
+			Frame:    frame,
+			Message:  fmt.Sprintf("map key must be Int, Float, String or Bool, not %s.", key.Type()),
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
+	}
+	return hashable.HashKey(), nil
+}
@@ -1,6 +1,7 @@
 package object
 
 import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -10,6 +11,10 @@ import (
 
 type Null struct{}
 
+// NullObj 是所有空值结果共用的实例，空值没有状态，不需要分别分配
+
+var NullObj = &Null{}
+
 // Type 返回值的类型
 //
 // 返回值:
@@ -42,6 +47,7 @@ func (n *Null) String() string {
 //	error - 可能出现的错误
 func (n *Null) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -63,6 +69,7 @@ func (n *Null) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object,
 //	error - 可能出现的错误
 func (n *Null) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -84,6 +91,7 @@ func (n *Null) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, e
 //	error - 可能出现的错误
 func (n *Null) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -106,6 +114,7 @@ func (n *Null) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, erro
 //	error - 可能出现的错误
 func (n *Null) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"+\".",
 		PosStart: posStart,
@@ -128,6 +137,7 @@ func (n *Null) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (n *Null) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -150,6 +160,7 @@ func (n *Null) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	error - 可能出现的错误
 func (n *Null) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"*\".",
 		PosStart: posStart,
@@ -172,6 +183,7 @@ func (n *Null) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	error - 可能出现的错误
 func (n *Null) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"/\".",
 		PosStart: posStart,
@@ -194,6 +206,7 @@ func (n *Null) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (n *Null) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"%\".",
 		PosStart: posStart,
@@ -222,10 +235,10 @@ func (n *Null) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, erro
 	switch other.(type) {
 	case *Null:
 		// 与Null类型比较: 返回true
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	default:
 		// 与非Null类型比较: 返回false
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	}
 }
 
@@ -250,10 +263,10 @@ func (n *Null) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, e
 	switch other.(type) {
 	case *Null:
 		// 与Null类型比较: 返回false
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	default:
 		// 与非Null类型比较: 返回true
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	}
 }
 
@@ -271,6 +284,7 @@ func (n *Null) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, e
 //	Object - 比较结果
 func (n *Null) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<\".",
 		PosStart: posStart,
@@ -292,6 +306,7 @@ func (n *Null) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	Object - 比较结果
 func (n *Null) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">\".",
 		PosStart: posStart,
@@ -313,6 +328,7 @@ func (n *Null) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	Object - 比较结果
 func (n *Null) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<=\".",
 		PosStart: posStart,
@@ -334,6 +350,7 @@ func (n *Null) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *fram
 //	Object - 比较结果
 func (n *Null) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">=\".",
 		PosStart: posStart,
@@ -356,6 +373,7 @@ func (n *Null) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *f
 //	error - 可能出现的错误
 func (n *Null) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -378,6 +396,7 @@ func (n *Null) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (n *Null) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -400,6 +419,7 @@ func (n *Null) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (
 //	error - 可能出现的错误
 func (n *Null) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -422,6 +442,7 @@ func (n *Null) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (n *Null) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -444,6 +465,7 @@ func (n *Null) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fram
 //	error - 可能出现的错误
 func (n *Null) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -466,6 +488,7 @@ func (n *Null) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fra
 //	error - 可能出现的错误
 func (n *Null) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -488,6 +511,7 @@ func (n *Null) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Ob
 //	error - 可能出现的错误
 func (n *Null) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -509,6 +533,7 @@ func (n *Null) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Obj
 //	error - 可能出现的错误
 func (n *Null) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "index expression not supported for this type.",
 		PosStart: posStart,
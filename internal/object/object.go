@@ -1,6 +1,8 @@
 package object
 
 import (
+	"strings"
+
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -344,3 +346,70 @@ type Object interface {
 	//  error - 可能出现的错误
 	Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error)
 }
+
+// Repr 返回值的调试表示，字符串及列表和映射中的字符串带双引号并转义不可打印字符，其他值与String相同
+// 列表和映射包含自身时，内层的自身输出为[...]或{...}
+//
+// 参数:
+//
+//	obj - 值
+//	ascii - 是否将字符串中的非ASCII字符同样转义
+//
+// 返回值:
+//
+//	string - 值的调试表示
+func Repr(obj Object, ascii bool) string {
+	return format(obj, true, ascii, make(map[Object]bool))
+}
+
+// format 返回值的字符串表示或调试表示，供String和Repr使用
+//
+// 参数:
+//
+//	obj - 值
+//	repr - 是否返回调试表示
+//	ascii - 调试表示中是否将字符串中的非ASCII字符同样转义
+//	visiting - 正在输出的列表和映射，再次遇到时输出[...]或{...}
+//
+// 返回值:
+//
+//	string - 值的表示
+func format(obj Object, repr, ascii bool, visiting map[Object]bool) string {
+	switch o := obj.(type) {
+	case *String:
+		if !repr {
+			return o.Value
+		}
+		if ascii {
+			return o.ReprASCII()
+		}
+		return o.Repr()
+	case *List:
+		if visiting[o] {
+			return "[...]"
+		}
+		visiting[o] = true
+		defer delete(visiting, o)
+		elements := make([]string, len(o.Elements))
+		for i, element := range o.Elements {
+			elements[i] = format(element, repr, ascii, visiting)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *Map:
+		if o.Len() == 0 {
+			return "{:}"
+		}
+		if visiting[o] {
+			return "{...}"
+		}
+		visiting[o] = true
+		defer delete(visiting, o)
+		pairs := make([]string, o.Len())
+		for i, entry := range o.Pairs() {
+			pairs[i] = format(entry.Key, repr, ascii, visiting) + ": " + format(entry.Value, repr, ascii, visiting)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	default:
+		return obj.String()
+	}
+}
@@ -1,6 +1,7 @@
 package object
 
 import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -43,6 +44,7 @@ func (rv *ReturnValue) String() string {
 //	error - 可能出现的错误
 func (rv *ReturnValue) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -64,6 +66,7 @@ func (rv *ReturnValue) Negative(posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (rv *ReturnValue) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -85,6 +88,7 @@ func (rv *ReturnValue) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (O
 //	error - 可能出现的错误
 func (rv *ReturnValue) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -107,6 +111,7 @@ func (rv *ReturnValue) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Obje
 //	error - 可能出现的错误
 func (rv *ReturnValue) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"+\".",
 		PosStart: posStart,
@@ -129,6 +134,7 @@ func (rv *ReturnValue) Add(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	error - 可能出现的错误
 func (rv *ReturnValue) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -151,6 +157,7 @@ func (rv *ReturnValue) Subtract(_ Object, posStart, posEnd *util.Pos, frame *fra
 //	error - 可能出现的错误
 func (rv *ReturnValue) Multiply(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"*\".",
 		PosStart: posStart,
@@ -173,6 +180,7 @@ func (rv *ReturnValue) Multiply(_ Object, posStart, posEnd *util.Pos, frame *fra
 //	error - 可能出现的错误
 func (rv *ReturnValue) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"/\".",
 		PosStart: posStart,
@@ -195,6 +203,7 @@ func (rv *ReturnValue) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame
 //	error - 可能出现的错误
 func (rv *ReturnValue) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"%\".",
 		PosStart: posStart,
@@ -216,6 +225,7 @@ func (rv *ReturnValue) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	error - 可能出现的错误
 func (rv *ReturnValue) Equal(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"==\".",
 		PosStart: posStart,
@@ -237,6 +247,7 @@ func (rv *ReturnValue) Equal(_ Object, posStart, posEnd *util.Pos, frame *frame.
 //	error - 可能出现的错误
 func (rv *ReturnValue) NotEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!=\".",
 		PosStart: posStart,
@@ -258,6 +269,7 @@ func (rv *ReturnValue) NotEqual(_ Object, posStart, posEnd *util.Pos, frame *fra
 //	Object - 比较结果
 func (rv *ReturnValue) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<\".",
 		PosStart: posStart,
@@ -279,6 +291,7 @@ func (rv *ReturnValue) LessThan(_ Object, posStart, posEnd *util.Pos, frame *fra
 //	Object - 比较结果
 func (rv *ReturnValue) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">\".",
 		PosStart: posStart,
@@ -300,6 +313,7 @@ func (rv *ReturnValue) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *
 //	Object - 比较结果
 func (rv *ReturnValue) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<=\".",
 		PosStart: posStart,
@@ -321,6 +335,7 @@ func (rv *ReturnValue) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, fra
 //	Object - 比较结果
 func (rv *ReturnValue) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">=\".",
 		PosStart: posStart,
@@ -343,6 +358,7 @@ func (rv *ReturnValue) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos,
 //	error - 可能出现的错误
 func (rv *ReturnValue) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -365,6 +381,7 @@ func (rv *ReturnValue) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame
 //	error - 可能出现的错误
 func (rv *ReturnValue) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -387,6 +404,7 @@ func (rv *ReturnValue) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.
 //	error - 可能出现的错误
 func (rv *ReturnValue) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -409,6 +427,7 @@ func (rv *ReturnValue) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	error - 可能出现的错误
 func (rv *ReturnValue) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -431,6 +450,7 @@ func (rv *ReturnValue) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *fr
 //	error - 可能出现的错误
 func (rv *ReturnValue) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -453,6 +473,7 @@ func (rv *ReturnValue) RightShift(_ Object, posStart, posEnd *util.Pos, frame *f
 //	error - 可能出现的错误
 func (rv *ReturnValue) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -475,6 +496,7 @@ func (rv *ReturnValue) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	error - 可能出现的错误
 func (rv *ReturnValue) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -496,6 +518,7 @@ func (rv *ReturnValue) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Fra
 //	error - 可能出现的错误
 func (rv *ReturnValue) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "index expression not supported for this type.",
 		PosStart: posStart,
@@ -1,9 +1,13 @@
 package object
 
 import (
+	"fmt"
 	"math"
+	"strconv"
 	"strings"
+	"unsafe"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
@@ -33,6 +37,24 @@ func (s *String) String() string {
 	return s.Value
 }
 
+// Repr 返回带双引号的字符串字面量，换行符、制表符等不可打印字符转义为\n、\t、\x00或\u形式，可打印的非ASCII字符原样保留
+//
+// 返回值:
+//
+//	string - 转义后的字符串字面量
+func (s *String) Repr() string {
+	return strconv.Quote(s.Value)
+}
+
+// ReprASCII 返回只包含ASCII字符的字符串字面量，在Repr的基础上将所有非ASCII字符转义为\u或\U形式
+//
+// 返回值:
+//
+//	string - 转义后的字符串字面量
+func (s *String) ReprASCII() string {
+	return strconv.QuoteToASCII(s.Value)
+}
+
 // Negative 对值进行负运算
 //
 // 参数:
@@ -47,6 +69,7 @@ func (s *String) String() string {
 //	error - 可能出现的错误
 func (s *String) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -68,6 +91,7 @@ func (s *String) Negative(posStart, posEnd *util.Pos, frame *frame.Frame) (Objec
 //	error - 可能出现的错误
 func (s *String) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"~\".",
 		PosStart: posStart,
@@ -89,6 +113,7 @@ func (s *String) BitNot(posStart, posEnd *util.Pos, frame *frame.Frame) (Object,
 //	error - 可能出现的错误
 func (s *String) Not(posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"!\".",
 		PosStart: posStart,
@@ -119,10 +144,11 @@ func (s *String) Add(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 		// 与字符串类型相加: 返回连接后的新字符串
 		return &String{Value: s.Value + o.Value}, nil
 	default:
-		// 与非字符串类型相加: 返回操作错误
+		// 与非字符串类型相加: 返回操作错误，提示先转换为字符串
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
-			Message:  "invalid operation \"+\".",
+			Message:  fmt.Sprintf("cannot add %s to %s; use str() to concatenate non-strings.", other.Type(), s.Type()),
 			PosStart: posStart,
 			PosEnd:   posEnd,
 		}
@@ -144,6 +170,7 @@ func (s *String) Add(other Object, posStart, posEnd *util.Pos, frame *frame.Fram
 //	error - 可能出现的错误
 func (s *String) Subtract(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"-\".",
 		PosStart: posStart,
@@ -175,6 +202,7 @@ func (s *String) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame
 		// 检查乘数是否为负数
 		if o.Value < 0 {
 			return nil, &OperationError{
+				Code:     errcode.OperationError,
 				Frame:    frame,
 				Message:  "invalid operation \"*\".",
 				PosStart: posStart,
@@ -184,6 +212,7 @@ func (s *String) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame
 		// 检查乘数是否超出最大整数限制
 		if o.Value > math.MaxInt {
 			return nil, &OperationError{
+				Code:     errcode.OperationError,
 				Frame:    frame,
 				Message:  "invalid operation \"*\".",
 				PosStart: posStart,
@@ -195,6 +224,7 @@ func (s *String) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame
 	default:
 		// 与非整数类型相乘: 返回操作错误
 		return nil, &OperationError{
+			Code:     errcode.OperationError,
 			Frame:    frame,
 			Message:  "invalid operation \"*\".",
 			PosStart: posStart,
@@ -218,6 +248,7 @@ func (s *String) Multiply(other Object, posStart, posEnd *util.Pos, frame *frame
 //	error - 可能出现的错误
 func (s *String) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"/\".",
 		PosStart: posStart,
@@ -240,6 +271,7 @@ func (s *String) Divide(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	error - 可能出现的错误
 func (s *String) Mod(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"%\".",
 		PosStart: posStart,
@@ -269,10 +301,10 @@ func (s *String) Equal(other Object, _, _ *util.Pos, _ *frame.Frame) (Object, er
 	switch o := other.(type) {
 	case *String:
 		// 与字符串类型比较: 比较内容是否相同
-		return &Bool{Value: s.Value == o.Value}, nil
+		return BoolOf(s.Value == o.Value), nil
 	default:
 		// 与其他类型比较：返回false
-		return &Bool{Value: false}, nil
+		return FalseObj, nil
 	}
 }
 
@@ -298,14 +330,14 @@ func (s *String) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object,
 	switch o := other.(type) {
 	case *String:
 		// 与字符串类型比较: 比较内容是否不同
-		return &Bool{Value: s.Value != o.Value}, nil
+		return BoolOf(s.Value != o.Value), nil
 	default:
 		// 与其他类型比较：返回true
-		return &Bool{Value: true}, nil
+		return TrueObj, nil
 	}
 }
 
-// LessThan 对值进行小于比较
+// LessThan 对值进行小于比较，字符串之间按字节的字典序比较
 //
 // 参数:
 //
@@ -317,16 +349,24 @@ func (s *String) NotEqual(other Object, _, _ *util.Pos, _ *frame.Frame) (Object,
 // 返回值:
 //
 //	Object - 比较结果
-func (s *String) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
-	return nil, &OperationError{
-		Frame:    frame,
-		Message:  "invalid operation \"<\".",
-		PosStart: posStart,
-		PosEnd:   posEnd,
+//	error - 另一个操作数不是字符串时的运算错误
+func (s *String) LessThan(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	switch o := other.(type) {
+	case *String:
+		// 与字符串比较：按字节逐个比较
+		return BoolOf(s.Value < o.Value), nil
+	default:
+		return nil, &OperationError{
+			Code:     errcode.OperationError,
+			Frame:    frame,
+			Message:  "invalid operation \"<\".",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
 	}
 }
 
-// GreaterThan 对值进行大于比较
+// GreaterThan 对值进行大于比较，字符串之间按字节的字典序比较
 //
 // 参数:
 //
@@ -338,16 +378,24 @@ func (s *String) LessThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Fra
 // 返回值:
 //
 //	Object - 比较结果
-func (s *String) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
-	return nil, &OperationError{
-		Frame:    frame,
-		Message:  "invalid operation \">\".",
-		PosStart: posStart,
-		PosEnd:   posEnd,
+//	error - 另一个操作数不是字符串时的运算错误
+func (s *String) GreaterThan(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	switch o := other.(type) {
+	case *String:
+		// 与字符串比较：按字节逐个比较
+		return BoolOf(s.Value > o.Value), nil
+	default:
+		return nil, &OperationError{
+			Code:     errcode.OperationError,
+			Frame:    frame,
+			Message:  "invalid operation \">\".",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
 	}
 }
 
-// LessThanOrEqual 对值进行小于等于比较
+// LessThanOrEqual 对值进行小于等于比较，字符串之间按字节的字典序比较
 //
 // 参数:
 //
@@ -359,16 +407,24 @@ func (s *String) GreaterThan(_ Object, posStart, posEnd *util.Pos, frame *frame.
 // 返回值:
 //
 //	Object - 比较结果
-func (s *String) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
-	return nil, &OperationError{
-		Frame:    frame,
-		Message:  "invalid operation \"<=\".",
-		PosStart: posStart,
-		PosEnd:   posEnd,
+//	error - 另一个操作数不是字符串时的运算错误
+func (s *String) LessThanOrEqual(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	switch o := other.(type) {
+	case *String:
+		// 与字符串比较：按字节逐个比较
+		return BoolOf(s.Value <= o.Value), nil
+	default:
+		return nil, &OperationError{
+			Code:     errcode.OperationError,
+			Frame:    frame,
+			Message:  "invalid operation \"<=\".",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
 	}
 }
 
-// GreaterThanOrEqual 对值进行大于等于比较
+// GreaterThanOrEqual 对值进行大于等于比较，字符串之间按字节的字典序比较
 //
 // 参数:
 //
@@ -380,12 +436,20 @@ func (s *String) LessThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *fr
 // 返回值:
 //
 //	Object - 比较结果
-func (s *String) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
-	return nil, &OperationError{
-		Frame:    frame,
-		Message:  "invalid operation \">=\".",
-		PosStart: posStart,
-		PosEnd:   posEnd,
+//	error - 另一个操作数不是字符串时的运算错误
+func (s *String) GreaterThanOrEqual(other Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
+	switch o := other.(type) {
+	case *String:
+		// 与字符串比较：按字节逐个比较
+		return BoolOf(s.Value >= o.Value), nil
+	default:
+		return nil, &OperationError{
+			Code:     errcode.OperationError,
+			Frame:    frame,
+			Message:  "invalid operation \">=\".",
+			PosStart: posStart,
+			PosEnd:   posEnd,
+		}
 	}
 }
 
@@ -404,6 +468,7 @@ func (s *String) GreaterThanOrEqual(_ Object, posStart, posEnd *util.Pos, frame
 //	error - 可能出现的错误
 func (s *String) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&\".",
 		PosStart: posStart,
@@ -426,6 +491,7 @@ func (s *String) BitAnd(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame
 //	error - 可能出现的错误
 func (s *String) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"|\".",
 		PosStart: posStart,
@@ -448,6 +514,7 @@ func (s *String) BitOr(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame)
 //	error - 可能出现的错误
 func (s *String) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"^\".",
 		PosStart: posStart,
@@ -470,6 +537,7 @@ func (s *String) Xor(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (
 //	error - 可能出现的错误
 func (s *String) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"<<\".",
 		PosStart: posStart,
@@ -492,6 +560,7 @@ func (s *String) LeftShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //	error - 可能出现的错误
 func (s *String) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \">>\".",
 		PosStart: posStart,
@@ -514,6 +583,7 @@ func (s *String) RightShift(_ Object, posStart, posEnd *util.Pos, frame *frame.F
 //	error - 可能出现的错误
 func (s *String) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"&&\".",
 		PosStart: posStart,
@@ -536,6 +606,7 @@ func (s *String) And(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (
 //	error - 可能出现的错误
 func (s *String) Or(_ Object, posStart, posEnd *util.Pos, frame *frame.Frame) (Object, error) {
 	return nil, &OperationError{
+		Code:     errcode.OperationError,
 		Frame:    frame,
 		Message:  "invalid operation \"||\".",
 		PosStart: posStart,
@@ -559,12 +630,16 @@ func (s *String) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 	// 以 rune 为单位的索引，支持 Unicode
 	runes := []rune(s.Value)
 	length := int64(len(runes))
-	real := other.(*Int).Value
+	real, err := intIndex(other, posStart, posEnd, frame)
+	if err != nil {
+		return nil, err
+	}
 	if real < 0 {
 		real = length + real
 	}
 	if real < 0 || real >= length {
 		return nil, &IndexError{
+			Code:     errcode.IndexError,
 			Frame:    frame,
 			Message:  "index out of range.",
 			PosStart: posStart,
@@ -574,6 +649,45 @@ func (s *String) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 	return &String{Value: string(runes[int(real)])}, nil
 }
 
+// Slice 以rune为单位截取子串，与Index一致地处理负数索引
+// 负数索引从末尾开始计数，越界的索引被截断到[0, len]范围内，起始位置不小于结束位置时返回空串
+//
+// 参数:
+//
+//	start - 起始索引(包含)
+//	end - 结束索引(不包含)
+//
+// 返回值:
+//
+//	*String - 截取得到的新字符串
+func (s *String) Slice(start, end int64) *String {
+	runes := []rune(s.Value)
+	length := int64(len(runes))
+	start = clampSliceIndex(start, length)
+	end = clampSliceIndex(end, length)
+	if start >= end {
+		return &String{Value: ""}
+	}
+	return &String{Value: string(runes[start:end])}
+}
+
+// clampSliceIndex 将切片索引换算为非负索引并截断到[0, length]范围内
+//
+// 参数:
+//
+//	index - 原始索引，负数表示从末尾开始计数
+//	length - 序列长度
+//
+// 返回值:
+//
+//	int64 - 换算后的索引
+func clampSliceIndex(index, length int64) int64 {
+	if index < 0 {
+		index += length
+	}
+	return max(0, min(index, length))
+}
+
 // Set 设置索引位置的值
 //
 // 参数:
@@ -588,13 +702,19 @@ func (s *String) Index(other Object, posStart, posEnd *util.Pos, frame *frame.Fr
 //
 //	error - 可能出现的错误
 func (s *String) Set(index Object, value Object, posStart, posEnd *util.Pos, frame *frame.Frame) error {
-	length := int64(len(s.Value))
-	real := index.(*Int).Value
+	// 与Index一致，以 rune 为单位的索引
+	runes := []rune(s.Value)
+	length := int64(len(runes))
+	real, err := intIndex(index, posStart, posEnd, frame)
+	if err != nil {
+		return err
+	}
 	if real < 0 {
 		real = length + real
 	}
 	if real < 0 || real >= length {
 		return &IndexError{
+			Code:     errcode.IndexError,
 			Frame:    frame,
 			Message:  "index out of range.",
 			PosStart: posStart,
@@ -602,13 +722,52 @@ func (s *String) Set(index Object, value Object, posStart, posEnd *util.Pos, fra
 		}
 	}
 	if str, ok := value.(*String); ok {
-		s.Value = s.Value[:int(real)] + str.Value + s.Value[int(real)+1:]
+		s.Value = string(runes[:int(real)]) + str.Value + string(runes[int(real)+1:])
 		return nil
 	}
 	return &TypeError{
+		Code:     errcode.TypeError,
 		Frame:    frame,
 		Message:  "invalid assignment.",
 		PosStart: posStart,
 		PosEnd:   posEnd,
 	}
 }
+
+// StringBuffer 字符串追加缓冲区，使对同一变量连续进行的 += 拼接总体为线性时间
+// 每次追加得到的字符串与缓冲区已使用的部分共享内存，之后的追加只写入已使用部分之后的空间，
+// 因此已经产生的字符串不会被修改
+
+type StringBuffer struct {
+	data []byte // 缓冲区，data[:len(data)]为最近一次追加得到的字符串的内容
+}
+
+// Append 返回s与other拼接得到的新字符串
+// s为该缓冲区最近一次追加的结果时直接在缓冲区末尾追加，否则将s复制到新的缓冲区后再追加
+//
+// 参数:
+//
+//	s - 左侧字符串
+//	other - 追加的字符串
+//
+// 返回值:
+//
+//	*String - 拼接后的新字符串
+func (b *StringBuffer) Append(s, other *String) *String {
+	// 字符串可能已通过索引赋值被替换，因此比较内容所在的内存而不是字符串对象
+	if len(b.data) == 0 || len(s.Value) != len(b.data) || unsafe.StringData(s.Value) != unsafe.SliceData(b.data) {
+		b.data = make([]byte, 0, 2*(len(s.Value)+len(other.Value)))
+		b.data = append(b.data, s.Value...)
+	}
+	b.data = append(b.data, other.Value...)
+	return &String{Value: unsafe.String(unsafe.SliceData(b.data), len(b.data))}
+}
+
+// HashKey 返回值作为映射键时的可哈希表示
+//
+// 返回值:
+//
+//	HashKey - 可哈希表示
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Value: s.Value}
+}
@@ -3,7 +3,8 @@ package object
 // Symbol 表示一个标识符的完整信息
 
 type Symbol struct {
-	Name    string // 符号名称
-	Value   Object // 值
-	IsConst bool   // 是否是常量
+	Name    string        // 符号名称
+	Value   Object        // 值
+	IsConst bool          // 是否是常量
+	Buffer  *StringBuffer // 对变量进行字符串 += 拼接时使用的缓冲区，未拼接过时为nil
 }
@@ -0,0 +1,63 @@
+package parsecache
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+// Programs 进程内的语法树缓存，按文件的绝对路径保存最近一次成功分析得到的语法树
+// 源代码改变后摘要不同，再次获取时重新分析并替换原来的语法树；可以被多个goroutine同时使用
+// 缓存的语法树会被多个解释器共享，取出后不能再修改
+
+type Programs struct {
+	OnParse func(path string) // 每次实际分析源代码之前调用，用于测试和统计，为nil时忽略
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// memoryEntry 进程内缓存中的一项
+
+type memoryEntry struct {
+	hash    [sha256.Size]byte // 源代码的SHA-256摘要
+	program *ast.Program      // 分析源代码得到的语法树
+}
+
+// Get 返回文件的语法树，缓存中没有该文件或源代码已改变时调用parse分析并缓存结果
+// 分析出错时不缓存，下次获取时重新分析；不同goroutine同时获取未缓存的文件时可能各分析一次
+//
+// 参数:
+//
+//	path - 文件的绝对路径
+//	src - 当前的源代码文本
+//	parse - 分析源代码的函数
+//
+// 返回值:
+//
+//	*ast.Program - 语法树
+//	error - parse返回的错误
+func (c *Programs) Get(path, src string, parse func() (*ast.Program, error)) (*ast.Program, error) {
+	hash := sha256.Sum256([]byte(src))
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.hash == hash {
+		return entry.program, nil
+	}
+	if c.OnParse != nil {
+		c.OnParse(path)
+	}
+	program, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]memoryEntry)
+	}
+	c.entries[path] = memoryEntry{hash: hash, program: program}
+	c.mu.Unlock()
+	return program, nil
+}
@@ -0,0 +1,184 @@
+// Package parsecache 将脚本的语法树缓存到脚本旁的.ghostc文件中，源代码未修改时跳过词法和语法分析
+package parsecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// Ext 缓存文件的扩展名，追加在脚本文件名之后
+const Ext = ".ghostc"
+
+// formatVersion 缓存文件的格式版本，语法树节点的结构改变时需要增加，旧版本的缓存文件会被忽略
+const formatVersion = 13
+
+// header 缓存文件开头的元数据，在解码语法树之前检查
+
+type header struct {
+	Version int               // 缓存文件的格式版本
+	Hash    [sha256.Size]byte // 源代码的SHA-256摘要
+}
+
+// posType 位置类型，解码后需要重新设置其中的文件路径和源代码文本
+var posType = reflect.TypeOf(util.Pos{})
+
+func init() {
+	// 接口类型的字段需要注册所有可能的具体节点类型
+	for _, node := range []ast.Node{
+		&ast.PrefixExpression{},
+		&ast.IntExpression{},
+		&ast.FloatExpression{},
+		&ast.IdentifierExpression{},
+		&ast.BoolExpression{},
+		&ast.NullExpression{},
+		&ast.StringExpression{},
+		&ast.InterpolatedStringExpression{},
+		&ast.ListExpression{},
+		&ast.MapExpression{},
+		&ast.GroupedExpression{},
+		&ast.VarInitializationExpression{},
+		&ast.VarAssignmentExpression{},
+		&ast.CompoundAssignmentExpression{},
+		&ast.InfixExpression{},
+		&ast.PrefixUnaryIncDecExpression{},
+		&ast.PostfixUnaryIncDecExpression{},
+		&ast.BlockExpression{},
+		&ast.IfExpression{},
+		&ast.TernaryExpression{},
+		&ast.FunctionExpression{},
+		&ast.CallExpression{},
+		&ast.IndexExpression{},
+		&ast.SliceExpression{},
+		&ast.ForStatement{},
+		&ast.ForInStatement{},
+		&ast.WhileStatement{},
+		&ast.ExpressionStatement{},
+		&ast.FunctionDeclarationStatement{},
+		&ast.ReturnStatement{},
+		&ast.BreakStatement{},
+		&ast.ContinueStatement{},
+		&ast.TryStatement{},
+		&ast.ThrowStatement{},
+		&ast.ImportStatement{},
+	} {
+		gob.Register(node)
+	}
+}
+
+// Path 返回脚本对应的缓存文件路径
+//
+// 参数:
+//
+//	script - 脚本路径
+//
+// 返回值:
+//
+//	string - 缓存文件路径，为脚本路径加上.ghostc扩展名
+func Path(script string) string {
+	return script + Ext
+}
+
+// Load 读取缓存文件中的语法树，缓存文件不存在、已损坏、格式版本不同或与源代码不匹配时返回false
+// 语法树中的位置会重新设置文件名和源代码文本，与直接分析源代码得到的语法树相同
+//
+// 参数:
+//
+//	cacheFile - 缓存文件路径
+//	name - 源名称，错误位置中显示的文件名
+//	src - 当前的源代码文本
+//
+// 返回值:
+//
+//	*ast.Program - 缓存的语法树
+//	bool - 是否可以使用缓存
+func Load(cacheFile, name, src string) (*ast.Program, bool) {
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	var h header
+	if err := dec.Decode(&h); err != nil || h.Version != formatVersion || h.Hash != sha256.Sum256([]byte(src)) {
+		return nil, false
+	}
+	var program *ast.Program
+	if err := dec.Decode(&program); err != nil || program == nil {
+		return nil, false
+	}
+	restorePositions(reflect.ValueOf(program), util.NewSourceFile(name, src))
+	return program, true
+}
+
+// Store 将语法树和源代码的摘要写入缓存文件
+// 先写入同一目录下的临时文件再重命名，并发运行的进程不会读到写入一半的缓存
+//
+// 参数:
+//
+//	cacheFile - 缓存文件路径
+//	src - 语法树对应的源代码文本
+//	program - 分析源代码得到的语法树
+//
+// 返回值:
+//
+//	error - 编码或写入失败时的错误
+func Store(cacheFile, src string, program *ast.Program) error {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(header{Version: formatVersion, Hash: sha256.Sum256([]byte(src))}); err != nil {
+		return err
+	}
+	if err := enc.Encode(program); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cacheFile), filepath.Base(cacheFile)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), cacheFile)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// restorePositions 遍历语法树，让所有位置共享同一个源文件
+//
+// 参数:
+//
+//	v - 语法树中的值
+//	source - 源文件，包含源名称和源代码文本
+func restorePositions(v reflect.Value, source *util.SourceFile) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Pointer && v.Elem().Type() == posType {
+			pos := v.Interface().(*util.Pos)
+			pos.Source = source
+			return
+		}
+		restorePositions(v.Elem(), source)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			restorePositions(v.Field(i), source)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			restorePositions(v.Index(i), source)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package parsecache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+// source 包含所有语法树节点类型的脚本
+const source = `import math;
+import "lib.gh";
+const limit = 3;
+var xs = [1, 2.5, "三", true, null];
+func add(a, b = -1) {
+    return (a + b) * 2;
+};
+for var i = 0; i < limit; i++ {
+    xs[0] += add(i);
+    --i;
+    i = i + 1;
+};
+if !xs[3] { println("no"); } else { println(xs); };
+`
+
+// parse 对源代码进行词法和语法分析
+func parse(tb testing.TB, name, src string) *ast.Program {
+	tb.Helper()
+	p, err := parser.NewParser(lexer.NewLexer(name, src))
+	if err != nil {
+		tb.Fatalf("err = %+v, expected nil", err)
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		tb.Fatalf("parse err = %+v", p.Err)
+	}
+	return program
+}
+
+func TestParseCache_RoundTrip(t *testing.T) {
+	cacheFile := Path(filepath.Join(t.TempDir(), "main.gh"))
+	program := parse(t, "main.gh", source)
+	if err := Store(cacheFile, source, program); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	got, ok := Load(cacheFile, "main.gh", source)
+	if !ok {
+		t.Fatalf("excepted cache hit")
+	}
+	if !reflect.DeepEqual(got, program) {
+		t.Errorf("excepted %s, got %s", ast.Dump(program), ast.Dump(got))
+	}
+}
+
+func TestParseCache_Miss(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, cacheFile string)
+		src   string
+	}{
+		{
+			name:  "Missing File",
+			setup: func(t *testing.T, cacheFile string) {},
+			src:   source,
+		},
+		{
+			name: "Stale Source",
+			setup: func(t *testing.T, cacheFile string) {
+				if err := Store(cacheFile, source, parse(t, "main.gh", source)); err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+			},
+			src: source + "println(1);\n",
+		},
+		{
+			name: "Corrupt File",
+			setup: func(t *testing.T, cacheFile string) {
+				if err := os.WriteFile(cacheFile, []byte("not a cache"), 0o644); err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+			},
+			src: source,
+		},
+		{
+			name: "Truncated File",
+			setup: func(t *testing.T, cacheFile string) {
+				if err := Store(cacheFile, source, parse(t, "main.gh", source)); err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+				data, err := os.ReadFile(cacheFile)
+				if err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+				if err := os.WriteFile(cacheFile, data[:len(data)/2], 0o644); err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+			},
+			src: source,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheFile := Path(filepath.Join(t.TempDir(), "main.gh"))
+			tt.setup(t, cacheFile)
+			if program, ok := Load(cacheFile, "main.gh", tt.src); ok || program != nil {
+				t.Errorf("excepted cache miss, got %v", program)
+			}
+		})
+	}
+}
+
+// generatedSource 生成指定行数的脚本
+func generatedSource(lines int) string {
+	var sb strings.Builder
+	sb.WriteString("var total = 0;\n")
+	for i := 1; i < lines; i++ {
+		fmt.Fprintf(&sb, "total = total + %d * (%d - 1) + len([%d, \"s%d\"]);\n", i, i, i, i)
+	}
+	return sb.String()
+}
+
+func BenchmarkParseCache_Startup(b *testing.B) {
+	src := generatedSource(10000)
+	cacheFile := Path(filepath.Join(b.TempDir(), "main.gh"))
+	if err := Store(cacheFile, src, parse(b, "main.gh", src)); err != nil {
+		b.Fatalf("err = %+v, expected nil", err)
+	}
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			parse(b, "main.gh", src)
+		}
+	})
+	b.Run("Cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, ok := Load(cacheFile, "main.gh", src); !ok {
+				b.Fatalf("excepted cache hit")
+			}
+		}
+	})
+}
+
+func TestParseCache_Programs(t *testing.T) {
+	var parses atomic.Int32
+	cache := &Programs{OnParse: func(string) { parses.Add(1) }}
+	get := func(src string) (*ast.Program, error) {
+		return cache.Get("/main.gh", src, func() (*ast.Program, error) {
+			p, err := parser.NewParser(lexer.NewLexer("main.gh", src))
+			if err != nil {
+				return nil, err
+			}
+			program := p.ParseProgram()
+			if p.Err != nil {
+				return nil, p.Err
+			}
+			return program, nil
+		})
+	}
+
+	first, err := get(source)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if program, err := get(source); err != nil || program != first {
+				t.Errorf("excepted the cached program, got %p, %+v", program, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := parses.Load(); n != 1 {
+		t.Errorf("excepted 1 parse, got %d", n)
+	}
+
+	// 出错的源代码不缓存，每次都重新分析
+	for i := 0; i < 2; i++ {
+		if _, err := get("var x = ;"); err == nil {
+			t.Fatalf("excepted a syntax error")
+		}
+	}
+	if n := parses.Load(); n != 3 {
+		t.Errorf("excepted failed parses to be retried, got %d parses", n)
+	}
+
+	changed, err := get(source + "var y = 1;\n")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if changed == first || parses.Load() != 4 {
+		t.Errorf("excepted the changed source to be parsed again, got %d parses", parses.Load())
+	}
+}
@@ -0,0 +1,103 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// Dump 以缩进树的形式输出AST节点，用于调试语法分析器
+// 每个节点输出一行，格式为：<节点类型> [<起始行:列>-<结束行:列>]
+// 节点的标量字段和运算符输出为"字段名: 值"，子节点缩进一级输出
+//
+// 参数:
+//
+//	node - 要输出的AST节点
+//
+// 返回值:
+//
+//	string - 缩进树的字符串表示
+func Dump(node Node) string {
+	var sb strings.Builder
+	dumpNode(&sb, reflect.ValueOf(node), 0)
+	return sb.String()
+}
+
+// dumpNode 递归输出单个节点
+//
+// 参数:
+//
+//	sb - 输出缓冲区
+//	v - 节点的反射值
+//	depth - 当前缩进层级
+func dumpNode(sb *strings.Builder, v reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if !v.IsValid() || v.IsNil() {
+		sb.WriteString(indent + "<nil>\n")
+		return
+	}
+	// 接口值需先取出其中的节点指针
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	elem := v.Elem()
+	sb.WriteString(indent + elem.Type().Name())
+	// 输出位置范围，映射的键值对等辅助结构没有位置
+	var posStart, posEnd *util.Pos
+	if field := elem.FieldByName("PosStart"); field.IsValid() {
+		posStart, _ = field.Interface().(*util.Pos)
+	}
+	if field := elem.FieldByName("PosEnd"); field.IsValid() {
+		posEnd, _ = field.Interface().(*util.Pos)
+	}
+	if posStart != nil && posEnd != nil {
+		sb.WriteString(fmt.Sprintf(" [%d:%d-%d:%d]", posStart.Row, posStart.Col, posEnd.Row, posEnd.Col))
+	}
+	sb.WriteString("\n")
+	for i := 0; i < elem.NumField(); i++ {
+		name := elem.Type().Field(i).Name
+		// 作用域解析的结果不属于语法结构，不输出
+		if name == "PosStart" || name == "PosEnd" || name == "Binding" || name == "Layout" {
+			continue
+		}
+		dumpField(sb, name, elem.Field(i), depth+1)
+	}
+}
+
+// dumpField 输出节点的单个字段
+//
+// 参数:
+//
+//	sb - 输出缓冲区
+//	name - 字段名
+//	field - 字段的反射值
+//	depth - 当前缩进层级
+func dumpField(sb *strings.Builder, name string, field reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch field.Kind() {
+	case reflect.String:
+		sb.WriteString(fmt.Sprintf("%s%s: %q\n", indent, name, field.String()))
+	case reflect.Int, reflect.Int64, reflect.Float64, reflect.Bool:
+		sb.WriteString(fmt.Sprintf("%s%s: %v\n", indent, name, field.Interface()))
+	case reflect.Slice:
+		sb.WriteString(fmt.Sprintf("%s%s: (%d)\n", indent, name, field.Len()))
+		for i := 0; i < field.Len(); i++ {
+			dumpNode(sb, field.Index(i), depth+1)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if field.IsNil() {
+			sb.WriteString(indent + name + ": <nil>\n")
+			return
+		}
+		// 运算符令牌只输出字面量
+		if tok, ok := field.Interface().(*lexer.Token); ok {
+			sb.WriteString(fmt.Sprintf("%s%s: %q\n", indent, name, tok.Literal))
+			return
+		}
+		sb.WriteString(indent + name + ":\n")
+		dumpNode(sb, field, depth+1)
+	}
+}
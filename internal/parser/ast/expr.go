@@ -101,6 +101,7 @@ func (fe *FloatExpression) IsLvalue() bool {
 
 type IdentifierExpression struct {
 	Name     string    // 标识符名称
+	Binding  *Binding  // 作用域解析得到的存储位置，未经解析时为nil，执行时按名称查找
 	PosStart *util.Pos // 表达式的起始位置
 	PosEnd   *util.Pos // 表达式的结束位置
 }
@@ -210,6 +211,46 @@ func (se *StringExpression) IsLvalue() bool {
 	return false
 }
 
+// InterpolatedStringExpression 是插值字符串表达式节点
+// 表示源代码中带有插值的字符串，如"Hello ${name}!"
+// Parts中字符串片段与插值表达式交替出现，首尾都是字符串片段，空片段也会保留
+
+type InterpolatedStringExpression struct {
+	Parts    []Expression // 字符串片段和插值表达式，偶数下标为*StringExpression
+	PosStart *util.Pos    // 表达式的起始位置
+	PosEnd   *util.Pos    // 表达式的结束位置
+}
+
+// String 返回插值字符串表达式的字符串表示
+// 返回带引号的字符串，插值表达式写在${}中
+//
+// 返回值:
+//
+//	带引号的字符串表示
+func (ise *InterpolatedStringExpression) String() string {
+	var sb strings.Builder
+	sb.WriteString("\"")
+	for i, part := range ise.Parts {
+		if i%2 == 1 {
+			sb.WriteString("${" + part.String() + "}")
+			continue
+		}
+		quoted := strconv.Quote(part.(*StringExpression).Value)
+		sb.WriteString(strings.ReplaceAll(quoted[1:len(quoted)-1], "${", "\\${"))
+	}
+	sb.WriteString("\"")
+	return sb.String()
+}
+
+// Expression 是标记方法，用于类型判断
+// 实现Expression接口
+func (ise *InterpolatedStringExpression) Expression() {}
+
+// IsLvalue 方法，返回是否为左值
+func (ise *InterpolatedStringExpression) IsLvalue() bool {
+	return false
+}
+
 // ListExpression 是列表表达式节点
 // 表示源代码中的列表
 
@@ -247,6 +288,55 @@ func (le *ListExpression) IsLvalue() bool {
 	return false
 }
 
+// MapExpression 是映射表达式节点
+// 表示源代码中的映射，如{"a": 1, "b": 2}，空映射写作{:}
+
+type MapExpression struct {
+	Entries  []*MapEntry // 键值对，按源代码中的顺序排列
+	PosStart *util.Pos   // 表达式的起始位置
+	PosEnd   *util.Pos   // 表达式的结束位置
+}
+
+// MapEntry 是映射表达式中的一个键值对
+
+type MapEntry struct {
+	Key   Expression // 键表达式
+	Value Expression // 值表达式
+}
+
+// String 返回映射表达式的字符串表示
+// 返回带花括号的键值对，空映射为{:}
+//
+// 返回值:
+//
+//	带花括号的映射表示
+func (me *MapExpression) String() string {
+	if len(me.Entries) == 0 {
+		return "{:}"
+	}
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, entry := range me.Entries {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(entry.Key.String())
+		sb.WriteString(": ")
+		sb.WriteString(entry.Value.String())
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// Expression 是标记方法，用于类型判断
+// 实现Expression接口
+func (me *MapExpression) Expression() {}
+
+// IsLvalue 方法，返回是否为左值
+func (me *MapExpression) IsLvalue() bool {
+	return false
+}
+
 // GroupedExpression 是分组表达式节点
 // 表示带括号的表达式，用于改变运算优先级
 
@@ -366,7 +456,6 @@ type CompoundAssignmentExpression struct {
 //	复合赋值表达式的字符串表示
 func (ce *CompoundAssignmentExpression) String() string {
 	var sb strings.Builder
-	sb.WriteString("var ")
 	sb.WriteString(ce.Name.String())
 	sb.WriteString(" ")
 	sb.WriteString(ce.Operator.Literal)
@@ -488,6 +577,7 @@ func (pe *PostfixUnaryIncDecExpression) IsLvalue() bool {
 
 type BlockExpression struct {
 	Statements []Statement // 语句块
+	Layout     *Layout     // 作用域解析得到的环境布局，未经解析时为nil
 	PosStart   *util.Pos   // 表达式的起始位置
 	PosEnd     *util.Pos   // 表达式的结束位置
 }
@@ -527,6 +617,7 @@ type IfExpression struct {
 	Condition   Expression // 条件表达式
 	Consequence Statement  // 条件为真时执行的分支体
 	Alternative Statement  // else 分支体
+	Layout      *Layout    // 分支所在作用域的环境布局，未经解析时为nil
 	PosStart    *util.Pos  // 表达式的起始位置
 	PosEnd      *util.Pos  // 表达式的结束位置
 }
@@ -559,6 +650,76 @@ func (ie *IfExpression) IsLvalue() bool {
 	return false
 }
 
+// FunctionExpression 是匿名函数表达式节点
+// 与函数声明相同但没有函数名，求值得到捕获当前环境的函数
+
+type FunctionExpression struct {
+	Parameter []*Parameter // 参数
+	Body      Statement    // 函数体
+	Layout    *Layout      // 函数作用域的环境布局，未经解析时为nil
+	PosStart  *util.Pos    // 表达式的起始位置
+	PosEnd    *util.Pos    // 表达式的结束位置
+}
+
+// String 返回函数表达式的字符串表示
+// 格式为：func(<para>) <body>
+//
+// 返回值:
+//
+//	函数表达式的字符串表示
+func (fe *FunctionExpression) String() string {
+	var sb strings.Builder
+	sb.WriteString("func(")
+	for i, p := range fe.Parameter {
+		sb.WriteString(p.String())
+		if i != len(fe.Parameter)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString(") ")
+	sb.WriteString(fe.Body.String())
+	return sb.String()
+}
+
+// Expression 是标记方法，用于类型判断
+// 实现Expression接口
+func (fe *FunctionExpression) Expression() {}
+
+// IsLvalue 方法，返回是否为左值
+func (fe *FunctionExpression) IsLvalue() bool {
+	return false
+}
+
+// TernaryExpression 是条件运算符表达式节点
+// 只计算条件所选的一个分支，分支不创建新的作用域
+
+type TernaryExpression struct {
+	Condition   Expression // 条件表达式
+	Consequence Expression // 条件为真时的表达式
+	Alternative Expression // 条件为假时的表达式
+	PosStart    *util.Pos  // 表达式的起始位置
+	PosEnd      *util.Pos  // 表达式的结束位置
+}
+
+// String 返回条件运算符表达式的字符串表示
+// 格式为：<cond> ? <expr> : <expr>
+//
+// 返回值:
+//
+//	条件运算符表达式的字符串表示
+func (te *TernaryExpression) String() string {
+	return te.Condition.String() + " ? " + te.Consequence.String() + " : " + te.Alternative.String()
+}
+
+// Expression 是标记方法，用于类型判断
+// 实现Expression接口
+func (te *TernaryExpression) Expression() {}
+
+// IsLvalue 方法，返回是否为左值
+func (te *TernaryExpression) IsLvalue() bool {
+	return false
+}
+
 // CallExpression 是函数调用表达式节点
 
 type CallExpression struct {
@@ -631,3 +792,44 @@ func (ie *IndexExpression) Expression() {}
 func (ie *IndexExpression) IsLvalue() bool {
 	return true
 }
+
+// SliceExpression 是切片表达式节点
+// 截取列表或字符串中[Low, High)范围内的元素，省略的边界为nil
+
+type SliceExpression struct {
+	Target   Expression // 被截取的目标
+	Low      Expression // 起始索引(包含)，省略时为nil
+	High     Expression // 结束索引(不包含)，省略时为nil
+	PosStart *util.Pos  // 表达式的起始位置
+	PosEnd   *util.Pos  // 表达式的结束位置
+}
+
+// String 返回切片表达式的字符串表示
+// 格式为：<target>[<low>:<high>]，省略的边界为空
+//
+// 返回值:
+//
+//	切片表达式的字符串表示
+func (se *SliceExpression) String() string {
+	var sb strings.Builder
+	sb.WriteString(se.Target.String())
+	sb.WriteString("[")
+	if se.Low != nil {
+		sb.WriteString(se.Low.String())
+	}
+	sb.WriteString(":")
+	if se.High != nil {
+		sb.WriteString(se.High.String())
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// Expression 是标记方法，用于类型判断
+// 实现Expression接口
+func (se *SliceExpression) Expression() {}
+
+// IsLvalue 方法，返回是否为左值
+func (se *SliceExpression) IsLvalue() bool {
+	return false
+}
@@ -58,3 +58,130 @@ func (p *Program) String() string {
 	}
 	return strings.Join(nodes, ";\n") + ";"
 }
+
+// Span 返回节点在源代码中的起止位置
+//
+// 参数:
+//
+//	node - AST节点
+//
+// 返回值:
+//
+//	*util.Pos - 节点的起始位置，未知节点类型时为nil
+//	*util.Pos - 节点的结束位置，未知节点类型时为nil
+func Span(node Node) (*util.Pos, *util.Pos) {
+	switch n := node.(type) {
+	case *Program:
+		return n.PosStart, n.PosEnd
+	case *ForStatement:
+		return n.PosStart, n.PosEnd
+	case *ForInStatement:
+		return n.PosStart, n.PosEnd
+	case *WhileStatement:
+		return n.PosStart, n.PosEnd
+	case *ExpressionStatement:
+		return n.PosStart, n.PosEnd
+	case *FunctionDeclarationStatement:
+		return n.PosStart, n.PosEnd
+	case *ReturnStatement:
+		return n.PosStart, n.PosEnd
+	case *BreakStatement:
+		return n.PosStart, n.PosEnd
+	case *ContinueStatement:
+		return n.PosStart, n.PosEnd
+	case *TryStatement:
+		return n.PosStart, n.PosEnd
+	case *CatchClause:
+		return n.PosStart, n.PosEnd
+	case *ThrowStatement:
+		return n.PosStart, n.PosEnd
+	case *ImportStatement:
+		return n.PosStart, n.PosEnd
+	case *PrefixExpression:
+		return n.PosStart, n.PosEnd
+	case *InfixExpression:
+		return n.PosStart, n.PosEnd
+	case *IntExpression:
+		return n.PosStart, n.PosEnd
+	case *FloatExpression:
+		return n.PosStart, n.PosEnd
+	case *BoolExpression:
+		return n.PosStart, n.PosEnd
+	case *NullExpression:
+		return n.PosStart, n.PosEnd
+	case *StringExpression:
+		return n.PosStart, n.PosEnd
+	case *InterpolatedStringExpression:
+		return n.PosStart, n.PosEnd
+	case *ListExpression:
+		return n.PosStart, n.PosEnd
+	case *MapExpression:
+		return n.PosStart, n.PosEnd
+	case *IdentifierExpression:
+		return n.PosStart, n.PosEnd
+	case *GroupedExpression:
+		return n.PosStart, n.PosEnd
+	case *VarInitializationExpression:
+		return n.PosStart, n.PosEnd
+	case *VarAssignmentExpression:
+		return n.PosStart, n.PosEnd
+	case *CompoundAssignmentExpression:
+		return n.PosStart, n.PosEnd
+	case *PrefixUnaryIncDecExpression:
+		return n.PosStart, n.PosEnd
+	case *PostfixUnaryIncDecExpression:
+		return n.PosStart, n.PosEnd
+	case *BlockExpression:
+		return n.PosStart, n.PosEnd
+	case *IfExpression:
+		return n.PosStart, n.PosEnd
+	case *TernaryExpression:
+		return n.PosStart, n.PosEnd
+	case *FunctionExpression:
+		return n.PosStart, n.PosEnd
+	case *CallExpression:
+		return n.PosStart, n.PosEnd
+	case *IndexExpression:
+		return n.PosStart, n.PosEnd
+	case *SliceExpression:
+		return n.PosStart, n.PosEnd
+	default:
+		return nil, nil
+	}
+}
+
+// Binding 标识符经过作用域解析后在执行时的存储位置，由sema包写入
+// 经过解析的局部作用域在执行时按槽位存放符号，模块顶层和含有import语句的作用域仍按名称存放
+
+type Binding struct {
+	Depth   int  // 从标识符所在的环境向外经过的环境层数
+	Slot    int  // 符号在该环境中的槽位，为-1时从该环境开始按名称查找
+	Builtin bool // 标识符引用内置函数且不会被遮蔽，执行时直接在全局环境中按名称查找
+}
+
+// Layout 作用域经过解析后在执行时创建的环境布局，由sema包写入
+
+type Layout struct {
+	Slots int // 环境中的槽位数量，即作用域中声明的名称数量
+}
+
+// FunctionParts 返回函数声明语句或函数表达式的参数和函数体，供需要同样处理两种函数的遍历使用
+//
+// 参数:
+//
+//	node - 函数声明语句或函数表达式
+//
+// 返回值:
+//
+//	[]*Parameter - 参数列表，node不是函数时为nil
+//	Statement - 函数体，node不是函数时为nil
+func FunctionParts(node Node) ([]*Parameter, Statement) {
+	switch n := node.(type) {
+	case *FunctionDeclarationStatement:
+		return n.Parameter, n.Body
+	case *FunctionExpression:
+		return n.Parameter, n.Body
+	default:
+		return nil, nil
+	}
+}
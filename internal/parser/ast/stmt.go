@@ -14,6 +14,7 @@ type ForStatement struct {
 	Condition      Expression // 条件表达式
 	Update         Statement  // 更新语句
 	Body           Statement  // 循环体语句
+	Layout         *Layout    // 作用域解析得到的环境布局，未经解析时为nil
 	PosStart       *util.Pos  // 语句的起始位置
 	PosEnd         *util.Pos  // 语句的结束位置
 }
@@ -41,6 +42,74 @@ func (fs *ForStatement) String() string {
 // 实现Statement接口
 func (fs *ForStatement) Statement() {}
 
+// ForInStatement 是for-in语句节点
+// 用于依次遍历列表、字符串、映射或迭代器中的元素
+
+type ForInStatement struct {
+	Variable      *IdentifierExpression // 循环变量，有两个循环变量时为映射的键
+	ValueVariable *IdentifierExpression // 映射的值对应的第二个循环变量，只有一个循环变量时为nil
+	Iterable      Expression            // 被遍历的表达式
+	Body          Statement             // 循环体语句
+	Layout        *Layout               // 作用域解析得到的环境布局，未经解析时为nil
+	PosStart      *util.Pos             // 语句的起始位置
+	PosEnd        *util.Pos             // 语句的结束位置
+}
+
+// String 返回for-in语句的字符串表示
+// 格式为：for <variable> in <iterable> <body>或for <key>, <value> in <iterable> <body>
+//
+// 返回值:
+//
+//	for-in语句的字符串表示
+func (fs *ForInStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("for ")
+	sb.WriteString(fs.Variable.String())
+	if fs.ValueVariable != nil {
+		sb.WriteString(", ")
+		sb.WriteString(fs.ValueVariable.String())
+	}
+	sb.WriteString(" in ")
+	sb.WriteString(fs.Iterable.String())
+	sb.WriteString(" ")
+	sb.WriteString(fs.Body.String())
+	return sb.String()
+}
+
+// Statement 是标记方法，用于类型判断
+// 实现Statement接口
+func (fs *ForInStatement) Statement() {}
+
+// WhileStatement 是while语句节点
+// 用于在条件为真时重复执行循环体
+
+type WhileStatement struct {
+	Condition Expression // 条件表达式
+	Body      Statement  // 循环体语句
+	Layout    *Layout    // 作用域解析得到的环境布局，未经解析时为nil
+	PosStart  *util.Pos  // 语句的起始位置
+	PosEnd    *util.Pos  // 语句的结束位置
+}
+
+// String 返回while语句的字符串表示
+// 格式为：while <condition> <body>
+//
+// 返回值:
+//
+//	while语句的字符串表示
+func (ws *WhileStatement) String() string {
+	var sb strings.Builder
+	sb.WriteString("while ")
+	sb.WriteString(ws.Condition.String())
+	sb.WriteString(" ")
+	sb.WriteString(ws.Body.String())
+	return sb.String()
+}
+
+// Statement 是标记方法，用于类型判断
+// 实现Statement接口
+func (ws *WhileStatement) Statement() {}
+
 // ExpressionStatement 是表达式语句节点
 // 用于将表达式作为独立语句执行
 
@@ -95,6 +164,7 @@ type FunctionDeclarationStatement struct {
 	Name      Expression   // 函数名
 	Parameter []*Parameter // 参数
 	Body      Statement    // 函数体
+	Layout    *Layout      // 函数作用域的环境布局，未经解析时为nil
 	PosStart  *util.Pos    // 语句的起始位置
 	PosEnd    *util.Pos    // 语句的结束位置
 }
@@ -152,3 +222,153 @@ func (rs *ReturnStatement) String() string {
 // Statement 是标记方法，用于类型判断
 // 实现Statement接口
 func (rs *ReturnStatement) Statement() {}
+
+// BreakStatement 是break语句节点
+// 用于结束最近的一层循环
+
+type BreakStatement struct {
+	PosStart *util.Pos // 语句的起始位置
+	PosEnd   *util.Pos // 语句的结束位置
+}
+
+// String 返回break语句的字符串表示
+//
+// 返回值:
+//
+//	表达式的字符串表示
+func (bs *BreakStatement) String() string {
+	return "break"
+}
+
+// Statement 是标记方法，用于类型判断
+// 实现Statement接口
+func (bs *BreakStatement) Statement() {}
+
+// ContinueStatement 是continue语句节点
+// 用于跳过本次迭代中剩余的语句，开始最近的一层循环的下一次迭代
+
+type ContinueStatement struct {
+	PosStart *util.Pos // 语句的起始位置
+	PosEnd   *util.Pos // 语句的结束位置
+}
+
+// String 返回continue语句的字符串表示
+//
+// 返回值:
+//
+//	表达式的字符串表示
+func (cs *ContinueStatement) String() string {
+	return "continue"
+}
+
+// Statement 是标记方法，用于类型判断
+// 实现Statement接口
+func (cs *ContinueStatement) Statement() {}
+
+// TryStatement 是try语句节点
+// 执行主体语句，主体中发生的运行时错误或抛出的值由catch子句处理
+
+type TryStatement struct {
+	Body     Statement    // 主体语句
+	Catch    *CatchClause // catch子句
+	Layout   *Layout      // 主体作用域解析得到的环境布局，未经解析时为nil
+	PosStart *util.Pos    // 语句的起始位置
+	PosEnd   *util.Pos    // 语句的结束位置
+}
+
+// String 返回try语句的字符串表示
+// 格式为：try <body> catch (<variable>) <handler>
+//
+// 返回值:
+//
+//	try语句的字符串表示
+func (ts *TryStatement) String() string {
+	return "try " + ts.Body.String() + " " + ts.Catch.String()
+}
+
+// Statement 是标记方法，用于类型判断
+// 实现Statement接口
+func (ts *TryStatement) Statement() {}
+
+// CatchClause 是try语句的catch子句
+// 捕获的错误绑定到变量，之后执行处理语句
+
+type CatchClause struct {
+	Variable *IdentifierExpression // 绑定错误的变量
+	Body     Statement             // 处理语句
+	Layout   *Layout               // 作用域解析得到的环境布局，未经解析时为nil
+	PosStart *util.Pos             // 子句的起始位置，即catch关键字的位置
+	PosEnd   *util.Pos             // 子句的结束位置
+}
+
+// String 返回catch子句的字符串表示
+// 格式为：catch (<variable>) <handler>
+//
+// 返回值:
+//
+//	catch子句的字符串表示
+func (cc *CatchClause) String() string {
+	return "catch (" + cc.Variable.String() + ") " + cc.Body.String()
+}
+
+// ThrowStatement 是throw语句节点
+// 用于抛出一个值，未被try语句捕获时中止程序
+
+type ThrowStatement struct {
+	Value    Expression // 抛出的表达式
+	PosStart *util.Pos  // 语句的起始位置
+	PosEnd   *util.Pos  // 语句的结束位置
+}
+
+// String 返回throw语句的字符串表示
+// 格式为：throw <expr>
+//
+// 返回值:
+//
+//	throw语句的字符串表示
+func (ts *ThrowStatement) String() string {
+	return "throw " + ts.Value.String()
+}
+
+// Statement 是标记方法，用于类型判断
+// 实现Statement接口
+func (ts *ThrowStatement) Statement() {}
+
+// ImportStatement 是导入语句节点
+// 用于执行另一个源文件并导入其顶层声明，按路径导入时Path不为nil，按名称导入时Name不为nil
+
+type ImportStatement struct {
+	Path     *StringExpression     // 被导入文件的路径，按名称导入时为nil
+	Name     *IdentifierExpression // 被导入模块的名称，按路径导入时为nil
+	PosStart *util.Pos             // 语句的起始位置
+	PosEnd   *util.Pos             // 语句的结束位置
+}
+
+// String 返回导入语句的字符串表示
+// 格式为：import "<path>" 或 import <name>
+//
+// 返回值:
+//
+//	导入语句的字符串表示
+func (is *ImportStatement) String() string {
+	if is.Name != nil {
+		return "import " + is.Name.String()
+	}
+	return "import " + is.Path.String()
+}
+
+// Spec 返回导入语句中书写的模块路径或名称，用于错误信息
+//
+// 返回值:
+//
+//	按路径导入时为路径，按名称导入时为名称
+func (is *ImportStatement) Spec() string {
+	if is.Name != nil {
+		return is.Name.Name
+	}
+	return is.Path.Value
+}
+
+// Statement 是标记方法，用于类型判断
+// 实现Statement接口
+func (is *ImportStatement) Statement() {}
@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/benchprog"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+)
+
+func BenchmarkParseLargeProgram(b *testing.B) {
+	// 所有基准测试脚本重复多次，得到数千行的源代码
+	var sb strings.Builder
+	for range 20 {
+		for _, program := range benchprog.Load(b) {
+			sb.WriteString(program.Source)
+		}
+	}
+	source := sb.String()
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	for range b.N {
+		p, err := NewParser(lexer.NewLexer("large.gh", source))
+		if err != nil {
+			b.Fatalf("err = %+v, expected nil", err)
+		}
+		if p.ParseProgram(); p.Err != nil {
+			b.Fatalf("err = %+v, expected nil", p.Err)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package parser
 import (
 	"strconv"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
 )
 
@@ -10,9 +12,15 @@ import (
 // 实现 error 接口
 
 type SyntaxError struct {
-	Message  string    // 错误描述文本
-	PosStart *util.Pos // 错误起始位置
-	PosEnd   *util.Pos // 错误结束位置
+	Code         string          // 错误代码，见errcode包
+	Message      string          // 错误描述文本
+	PosStart     *util.Pos       // 错误起始位置
+	PosEnd       *util.Pos       // 错误结束位置
+	EOF          bool            // 错误是否因提前遇到文件结尾而产生，此时继续输入可能使源代码完整
+	Expected     lexer.TokenType // 提前遇到文件结尾时缺少的token类型，缺少的是表达式或括号未闭合时为零值
+	Note         string          // 补充说明，如括号未闭合时分析中止的原因，没有时为空
+	NotePosStart *util.Pos       // 补充说明的起始位置
+	NotePosEnd   *util.Pos       // 补充说明的结束位置
 }
 
 // Error 生成格式化的非法令牌错误信息
@@ -28,11 +36,57 @@ func (e *SyntaxError) Error() string {
 	} else {
 		linePos = "lines " + strconv.Itoa(e.PosStart.Row) + "-" + strconv.Itoa(e.PosEnd.Row)
 	}
-	result := "File " + e.PosStart.File + ", " + linePos + "\n"
-	result += util.StringsWithArrows(e.PosStart.Text, e.PosStart, e.PosEnd, false)
+	result := "File " + e.PosStart.File() + ", " + linePos + "\n"
+	result += util.StringsWithArrows(e.PosStart.Text(), e.PosStart, e.PosEnd, false)
 	result += "\nSyntax Error"
 	if e.Message != "" {
 		result += ": " + e.Message
 	}
 	return result
 }
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，为"Syntax Error"
+func (e *SyntaxError) ErrorKind() string {
+	return "Syntax Error"
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *SyntaxError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *SyntaxError) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *SyntaxError) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 语法错误没有调用栈，总是为nil
+func (e *SyntaxError) Frames() *frame.Frame {
+	return nil
+}
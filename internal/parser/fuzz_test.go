@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// FuzzParser 检查任意输入下语法分析器不会panic、总能结束，且错误都带有位置
+// 种子语料位于testdata/fuzz/FuzzParser
+func FuzzParser(f *testing.F) {
+	f.Add("var x = 1;")
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, recover := range []bool{false, true} {
+			errs := make(chan []error, 1)
+			go func() {
+				p, err := NewParser(lexer.NewLexer("<fuzz>", input))
+				if err != nil {
+					errs <- []error{err}
+					return
+				}
+				p.Recover = recover
+				program := p.ParseProgram()
+				if p.Err == nil && program == nil {
+					t.Errorf("nil program without error on %q", input)
+				}
+				if p.Err != nil && len(p.Errors()) == 0 {
+					t.Errorf("error %q missing from Errors() on %q", p.Err, input)
+				}
+				errs <- p.Errors()
+			}()
+			select {
+			case got := <-errs:
+				for _, err := range got {
+					positioned, ok := err.(interface{ Pos() (*util.Pos, *util.Pos) })
+					if !ok {
+						t.Fatalf("error %T has no position on %q", err, input)
+					}
+					if posStart, posEnd := positioned.Pos(); posStart == nil || posEnd == nil {
+						t.Fatalf("error %q has nil position on %q", err, input)
+					}
+					_ = err.Error()
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatalf("parser did not terminate on %q (recover %v)", input, recover)
+			}
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
 	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
@@ -15,6 +16,7 @@ import (
 const (
 	LOWEST  = iota // 最低优先级
 	ASSIGN         // 赋值运算符优先级(=, +=, -=, *=, /= 等)
+	TERNARY        // 条件运算符优先级(?:)
 	LOGIC          // 逻辑运算符优先级(&&, ||)
 	BIT            // 位运算符优先级(^, &, |, <<, >>)
 	EQUALS         // 相等性运算符优先级(==, !=)
@@ -26,8 +28,19 @@ const (
 	CALL           // 函数调用优先级(fn())
 )
 
-// precedences 运算符优先级映射表，将token类型映射到对应的优先级常量
-var precedences = map[string]int{
+// MaxNestingDepth 表达式和语句的最大嵌套深度
+// 限制递归下降分析的深度，避免恶意或自动生成的源代码耗尽调用栈，同时限制之后遍历语法树时的递归深度
+const MaxNestingDepth = 1000
+
+// closers 开括号对应的闭括号
+var closers = map[lexer.TokenType]lexer.TokenType{
+	lexer.LPAREN:   lexer.RPAREN,
+	lexer.LBRACKET: lexer.RBRACKET,
+	lexer.LBRACE:   lexer.RBRACE,
+}
+
+// precedences 运算符优先级表，以token类型为下标，不是运算符的token为LOWEST
+var precedences = [lexer.TokenTypeCount]int{
 	lexer.EQUAL:             ASSIGN,
 	lexer.PLUS_EQUAL:        ASSIGN,
 	lexer.MINUS_EQUAL:       ASSIGN,
@@ -39,6 +52,7 @@ var precedences = map[string]int{
 	lexer.BITWISE_XOR_EQUAL: ASSIGN,
 	lexer.LEFT_SHIFT_EQUAL:  ASSIGN,
 	lexer.RIGHT_SHIFT_EQUAL: ASSIGN,
+	lexer.QUESTION:          TERNARY,
 	lexer.LOGICAL_AND:       LOGIC,
 	lexer.LOGICAL_OR:        LOGIC,
 	lexer.BITWISE_XOR:       BIT,
@@ -66,12 +80,20 @@ var precedences = map[string]int{
 // Parser 语法解析器结构体，负责将词法分析器产生的token流解析为AST
 
 type Parser struct {
-	L              *lexer.Lexer                                              // 词法分析器实例
-	CurrToken      *lexer.Token                                              // 当前正在处理的token
-	NextToken      *lexer.Token                                              // 下一个待处理的token
-	Err            error                                                     // 解析过程中产生的错误
-	PrefixParseFns map[string]func(*util.Pos) ast.Expression                 // 前缀表达式解析函数映射表
-	InfixParseFns  map[string]func(ast.Expression, *util.Pos) ast.Expression // 中缀表达式解析函数映射表
+	L              *lexer.Lexer                                                         // 词法分析器实例
+	CurrToken      lexer.Token                                                          // 当前正在处理的token
+	NextToken      lexer.Token                                                          // 下一个待处理的token
+	Err            error                                                                // 解析过程中产生的错误
+	PrefixParseFns [lexer.TokenTypeCount]func(*util.Pos) ast.Expression                 // 前缀表达式解析函数表，以token类型为下标
+	InfixParseFns  [lexer.TokenTypeCount]func(ast.Expression, *util.Pos) ast.Expression // 中缀表达式解析函数表，以token类型为下标
+	Recover        bool                                                                 // 是否在语法错误后跳过出错的语句继续分析，收集所有错误
+	errs           []error                                                              // 恢复模式下收集的错误，按出现顺序排列
+	depth          int                                                                  // 当前token所在的花括号嵌套深度，用于恢复时定位语句结尾
+	prev           lexer.Token                                                          // 当前token之前的token，用于定位文件结尾处的错误
+	delimiters     []lexer.Token                                                        // 当前语句中已经开始但尚未闭合的括号，按嵌套顺序排列
+	nesting        int                                                                  // 当前表达式和语句的嵌套深度，见MaxNestingDepth
+	partial        *ast.Program                                                         // 最近一次ParseProgram解析成功的语句，发生错误时也保留
+	statementBlock bool                                                                 // 当前的左花括号是否位于语句开头，此时{}为空的块表达式而不是空映射
 }
 
 // NewParser 创建一个新的语法解析器实例
@@ -84,43 +106,34 @@ type Parser struct {
 //
 //	新的Parser实例和可能的初始化错误
 func NewParser(l *lexer.Lexer) (*Parser, error) {
-	p := &Parser{L: l}
-	// 初始化当前token
-	p.CurrToken, p.Err = p.L.NextToken()
-	if p.Err != nil {
-		return nil, p.Err
+	p := &Parser{}
+	// 初始化前缀解析函数表
+	p.PrefixParseFns = [lexer.TokenTypeCount]func(*util.Pos) ast.Expression{
+		lexer.INT:          p.parseIntegerExpression,
+		lexer.FLOAT:        p.parseFloatExpression,
+		lexer.IDENT:        p.parseIdentifierExpression,
+		lexer.TRUE:         p.parseBoolExpression,
+		lexer.FALSE:        p.parseBoolExpression,
+		lexer.NULL:         p.parseNullExpression,
+		lexer.STRING:       p.parseStringExpression,
+		lexer.INTERP_START: p.parseInterpolatedStringExpression,
+		lexer.PLUS:         p.parsePrefixExpression,
+		lexer.MINUS:        p.parsePrefixExpression,
+		lexer.BANG:         p.parsePrefixExpression,
+		lexer.BITWISE_NOT:  p.parsePrefixExpression,
+		lexer.LPAREN:       p.parseGroupedExpression,
+		lexer.VAR:          p.parseVarInitializationExpression,
+		lexer.CONST:        p.parseVarInitializationExpression,
+		lexer.INCREMENT:    p.parsePrefixUnaryIncDecExpression,
+		lexer.DECREMENT:    p.parsePrefixUnaryIncDecExpression,
+		lexer.LBRACE:       p.parseBlockExpression,
+		lexer.IF:           p.parseIfExpression,
+		lexer.LBRACKET:     p.parseListExpression,
+		lexer.FUNC:         p.parseFunctionExpression,
 	}
-	p.L.NextChar()
-	// 初始化下一个token
-	p.NextToken, p.Err = p.L.NextToken()
-	if p.Err != nil {
-		return nil, p.Err
-	}
-	p.L.NextChar()
-	// 初始化前缀解析函数映射
-	p.PrefixParseFns = map[string]func(*util.Pos) ast.Expression{
-		lexer.INT:         p.parseIntegerExpression,
-		lexer.FLOAT:       p.parseFloatExpression,
-		lexer.IDENT:       p.parseIdentifierExpression,
-		lexer.TRUE:        p.parseBoolExpression,
-		lexer.FALSE:       p.parseBoolExpression,
-		lexer.NULL:        p.parseNullExpression,
-		lexer.STRING:      p.parseStringExpression,
-		lexer.PLUS:        p.parsePrefixExpression,
-		lexer.MINUS:       p.parsePrefixExpression,
-		lexer.BANG:        p.parsePrefixExpression,
-		lexer.BITWISE_NOT: p.parsePrefixExpression,
-		lexer.LPAREN:      p.parseGroupedExpression,
-		lexer.VAR:         p.parseVarInitializationExpression,
-		lexer.CONST:       p.parseVarInitializationExpression,
-		lexer.INCREMENT:   p.parsePrefixUnaryIncDecExpression,
-		lexer.DECREMENT:   p.parsePrefixUnaryIncDecExpression,
-		lexer.LBRACE:      p.parseBlockExpression,
-		lexer.IF:          p.parseIfExpression,
-		lexer.LBRACKET:    p.parseListExpression,
-	}
-	// 初始化中缀解析函数映射
-	p.InfixParseFns = map[string]func(ast.Expression, *util.Pos) ast.Expression{
+	// 初始化中缀解析函数表
+	p.InfixParseFns = [lexer.TokenTypeCount]func(ast.Expression, *util.Pos) ast.Expression{
+		lexer.QUESTION:          p.parseTernaryExpression,
 		lexer.LOGICAL_AND:       p.parseInfixExpression,
 		lexer.LOGICAL_OR:        p.parseInfixExpression,
 		lexer.BITWISE_XOR:       p.parseInfixExpression,
@@ -155,72 +168,325 @@ func NewParser(l *lexer.Lexer) (*Parser, error) {
 		lexer.LPAREN:            p.parseCallExpression,
 		lexer.LBRACKET:          p.parseIndexExpression,
 	}
+	if err := p.Reset(l); err != nil {
+		return nil, err
+	}
 	return p, nil
 }
 
+// Reset 让语法解析器从头分析新的词法分析器产生的token，之前的错误和状态全部丢弃
+// 解析函数表和Recover设置保留，用于REPL等需要反复分析输入的场景，避免每次重新创建解析函数表
+//
+// 参数:
+//
+//	l - 词法分析器实例
+//
+// 返回值:
+//
+//	error - 读取前两个token时的词法错误
+func (p *Parser) Reset(l *lexer.Lexer) error {
+	p.L = l
+	p.Err = nil
+	p.errs = nil
+	p.depth = 0
+	p.prev = lexer.Token{}
+	p.delimiters = nil
+	p.nesting = 0
+	p.partial = nil
+	p.statementBlock = false
+	// 初始化当前token
+	p.CurrToken, p.Err = p.L.NextToken()
+	if p.Err != nil {
+		return p.Err
+	}
+	p.trackDepth()
+	p.L.NextChar()
+	// 初始化下一个token
+	p.NextToken, p.Err = p.L.NextToken()
+	if p.Err != nil {
+		return p.Err
+	}
+	p.L.NextChar()
+	return nil
+}
+
 // Advance 前进到下一个token，更新CurrToken和NextToken
+// 读取NextToken时的词法错误在前进之后仍然保留，避免被之后读取的token覆盖而只报告"unexpected ILLEGAL"
 func (p *Parser) Advance() {
-	p.CurrToken = p.NextToken.Copy()
-	p.NextToken, p.Err = p.L.NextToken()
+	p.prev = p.CurrToken
+	p.CurrToken = p.NextToken
+	p.trackDepth()
+	var err error
+	p.NextToken, err = p.L.NextToken()
+	if p.Err == nil {
+		p.Err = err
+	}
 	p.L.NextChar()
 }
 
+// trackDepth 根据当前token更新花括号嵌套深度
+// 分号只能出现在花括号内，圆括号和方括号未闭合时语句同样在分号处结束，因此不计入深度
+func (p *Parser) trackDepth() {
+	switch p.CurrToken.Type {
+	case lexer.LBRACE:
+		p.depth++
+	case lexer.RBRACE:
+		p.depth--
+	}
+}
+
+// Errors 返回语法分析过程中产生的所有错误
+// 恢复模式下为收集的所有错误，按出现顺序排列；否则至多包含第一个错误
+//
+// 返回值:
+//
+//	[]error - 词法和语法错误，没有错误时为空
+func (p *Parser) Errors() []error {
+	if len(p.errs) == 0 && p.Err != nil {
+		return []error{p.Err}
+	}
+	return p.errs
+}
+
+// PartialProgram 返回最近一次ParseProgram解析成功的语句
+// 发生错误时ParseProgram返回nil，编辑器等需要分析不完整代码的场景可以通过此方法取得出错前的语句，
+// 恢复模式下还包括出错语句之后的语句
+//
+// 返回值:
+//
+//	*ast.Program - 解析成功的语句组成的Program节点，尚未调用ParseProgram时为nil
+func (p *Parser) PartialProgram() *ast.Program {
+	return p.partial
+}
+
+// recoverStatement 记录当前的错误并跳到出错语句之后，即下一个不在花括号内的分号之后
+// 词法错误之后的token流不可靠，不再继续分析
+//
+// 返回值:
+//
+//	bool - 是否可以继续分析之后的语句
+func (p *Parser) recoverStatement() bool {
+	p.errs = append(p.errs, p.Err)
+	if _, ok := p.Err.(*SyntaxError); !ok {
+		return false
+	}
+	p.Err = nil
+	for p.CurrToken.Type != lexer.EOF {
+		atEnd := p.CurrToken.Type == lexer.SEMICOLON && p.depth <= 0
+		p.Advance()
+		if p.Err != nil {
+			p.errs = append(p.errs, p.Err)
+			return false
+		}
+		if atEnd {
+			break
+		}
+	}
+	p.depth = 0
+	return true
+}
+
 // CheckNextAndAdvance 检查下一个token是否为预期类型，如果是则前进，否则设置错误
 //
 // 参数:
 //
 //	excepted - 预期的token类型
-func (p *Parser) CheckNextAndAdvance(excepted string) {
+func (p *Parser) CheckNextAndAdvance(excepted lexer.TokenType) {
 	if p.NextToken.Type != excepted {
-		// 创建语法错误，包含预期和实际token类型信息
-		p.Err = &SyntaxError{
-			Message:  fmt.Sprintf("expected \"%s\", but got \"%s\".", excepted, p.NextToken.Type),
-			PosStart: p.NextToken.PosStart.Copy(),
-			PosEnd:   p.NextToken.PosEnd.Copy(),
-		}
+		p.Err = p.expectedError(excepted, p.NextToken)
 	} else {
 		p.Advance()
 	}
 }
 
+// expectedError 创建缺少预期token的语法错误，包含预期和实际token类型信息
+// 实际遇到文件结尾时错误位于最后一个token之后
+//
+// 参数:
+//
+//	excepted - 预期的token类型
+//	got - 实际遇到的token
+//
+// 返回值:
+//
+//	*SyntaxError - 语法错误
+func (p *Parser) expectedError(excepted lexer.TokenType, got lexer.Token) *SyntaxError {
+	if got.Type == lexer.EOF {
+		return p.eofError(errcode.ExpectedToken, fmt.Sprintf("expected %s before end of file.", lexer.Describe(excepted)), excepted)
+	}
+	return &SyntaxError{
+		Code:     errcode.ExpectedToken,
+		Message:  fmt.Sprintf("expected \"%s\", but got \"%s\".", excepted, got.Type),
+		PosStart: got.PosStart,
+		PosEnd:   got.PosEnd,
+	}
+}
+
+// eofError 创建因提前遇到文件结尾而产生的语法错误
+// 错误位于最后一个token之后，即缺少的token应当出现的位置，而不是源代码末尾的空行
+//
+// 参数:
+//
+//	code - 错误代码
+//	message - 错误描述
+//	expected - 缺少的token类型，缺少的是表达式时为零值
+//
+// 返回值:
+//
+//	*SyntaxError - 语法错误
+func (p *Parser) eofError(code, message string, expected lexer.TokenType) *SyntaxError {
+	last := p.CurrToken
+	if last.Type == lexer.EOF && p.prev.Type != 0 {
+		last = p.prev
+	}
+	pos := last.PosEnd
+	// 源代码为空时没有最后一个token
+	if last.Type == lexer.EOF {
+		pos = last.PosStart
+	}
+	return &SyntaxError{
+		Code:     code,
+		Message:  message,
+		PosStart: pos,
+		PosEnd:   pos,
+		EOF:      true,
+		Expected: expected,
+	}
+}
+
+// enter 进入一层嵌套的表达式或语句，超出最大嵌套深度时设置语法错误
+// 返回true时调用者在离开时需要将嵌套深度减1
+//
+// 返回值:
+//
+//	bool - 是否未超出最大嵌套深度
+func (p *Parser) enter() bool {
+	if p.nesting >= MaxNestingDepth {
+		p.Err = &SyntaxError{
+			Code:     errcode.NestingTooDeep,
+			Message:  fmt.Sprintf("nesting exceeds the maximum depth of %d.", MaxNestingDepth),
+			PosStart: p.CurrToken.PosStart,
+			PosEnd:   p.CurrToken.PosEnd,
+		}
+		return false
+	}
+	p.nesting++
+	return true
+}
+
+// openDelimiter 记录当前token为一个开始的括号
+func (p *Parser) openDelimiter() {
+	p.delimiters = append(p.delimiters, p.CurrToken)
+}
+
+// closeDelimiter 在当前token闭合最内层的括号之后将其移除
+func (p *Parser) closeDelimiter() {
+	p.delimiters = p.delimiters[:len(p.delimiters)-1]
+}
+
+// reportUnclosed 如果当前的语法错误是因括号未闭合而产生的，即提前遇到文件结尾，
+// 或在圆括号、方括号中遇到分号，或遇到与最内层括号不匹配的闭括号，
+// 将其替换为指向最内层未闭合括号的错误，原错误的位置作为分析中止的位置附在错误中
+// 之后清空括号记录，开始分析下一个语句
+func (p *Parser) reportUnclosed() {
+	defer func() {
+		p.delimiters = p.delimiters[:0]
+	}()
+	err, ok := p.Err.(*SyntaxError)
+	if !ok || len(p.delimiters) == 0 {
+		return
+	}
+	open := p.delimiters[len(p.delimiters)-1]
+	if !err.EOF && !p.cannotContinue(err, open) {
+		return
+	}
+	p.Err = &SyntaxError{
+		Code:         errcode.UnclosedDelimiter,
+		Message:      fmt.Sprintf("unclosed \"%s\" opened at line %d, column %d.", open.Literal, open.PosStart.Row, open.PosStart.Col),
+		PosStart:     open.PosStart,
+		PosEnd:       open.PosEnd,
+		EOF:          err.EOF,
+		Note:         "parsing stopped here: " + err.Message,
+		NotePosStart: err.PosStart,
+		NotePosEnd:   err.PosEnd,
+	}
+}
+
+// cannotContinue 判断语法错误处的token是否说明括号一定未闭合
+//
+// 参数:
+//
+//	err - 语法错误
+//	open - 最内层未闭合的括号
+//
+// 返回值:
+//
+//	bool - 错误处的token是否为圆括号或方括号中的分号，或与最内层括号不匹配的闭括号
+func (p *Parser) cannotContinue(err *SyntaxError, open lexer.Token) bool {
+	for _, tok := range []lexer.Token{p.CurrToken, p.NextToken} {
+		if tok.PosStart.Idx != err.PosStart.Idx {
+			continue
+		}
+		switch tok.Type {
+		case lexer.SEMICOLON:
+			return open.Type != lexer.LBRACE
+		case lexer.RPAREN, lexer.RBRACKET, lexer.RBRACE:
+			return closers[open.Type] != tok.Type
+		}
+	}
+	return false
+}
+
 // ParseProgram 解析整个程序，生成AST的根节点Program
+// 恢复模式下遇到语法错误时跳过出错的语句继续分析，所有错误通过Errors获取，Err为第一个错误
 //
 // 返回值:
 //
-//	包含所有语句的Program节点
+//	包含所有语句的Program节点，发生错误时为nil
 func (p *Parser) ParseProgram() *ast.Program {
-	posStart := p.CurrToken.PosStart.Copy()
+	posStart := p.CurrToken.PosStart
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 	// 循环解析所有语句直到文件结束
 	for p.CurrToken.Type != lexer.EOF {
-		if p.Err != nil {
-			return nil
-		}
 		// 跳过空分号
-		for p.CurrToken.Type == lexer.SEMICOLON {
+		for p.Err == nil && p.CurrToken.Type == lexer.SEMICOLON {
 			p.Advance()
 		}
-		if p.CurrToken.Type == lexer.EOF {
+		if p.Err == nil && p.CurrToken.Type == lexer.EOF {
 			break
 		}
 		// 解析单个语句
-		statPosStart := p.CurrToken.PosStart.Copy()
-		stat := p.parseStatement(statPosStart)
-		if p.Err != nil {
-			return nil
+		var stat ast.Statement
+		if p.Err == nil {
+			statPosStart := p.CurrToken.PosStart
+			stat = p.parseStatement(statPosStart)
 		}
 		// 检查语句后的分号
-		p.CheckNextAndAdvance(lexer.SEMICOLON)
+		if p.Err == nil {
+			p.CheckNextAndAdvance(lexer.SEMICOLON)
+		}
 		if p.Err != nil {
-			return nil
+			p.reportUnclosed()
+			// 恢复模式下跳过出错的语句，继续分析之后的语句
+			if p.Recover && p.recoverStatement() {
+				continue
+			}
+			break
 		}
 		// 添加语句到程序节点
 		program.Statements = append(program.Statements, stat)
 		p.Advance()
 	}
+	if len(p.errs) > 0 {
+		p.Err = p.errs[0]
+	}
 	program.PosStart = posStart
-	program.PosEnd = p.CurrToken.PosEnd.Copy()
+	program.PosEnd = p.CurrToken.PosEnd
+	p.partial = program
+	if p.Err != nil {
+		return nil
+	}
 	return program
 }
 
@@ -234,23 +500,52 @@ func (p *Parser) ParseProgram() *ast.Program {
 //
 //	解析得到的语句节点
 func (p *Parser) parseStatement(posStart *util.Pos) ast.Statement {
+	if !p.enter() {
+		return nil
+	}
+	defer func() {
+		p.nesting--
+	}()
 	switch p.CurrToken.Type {
 	case lexer.FOR:
-		// 解析为for语句
+		// 解析为for语句或for-in语句
 		return p.parseForStatement(posStart)
+	case lexer.WHILE:
+		// 解析为while语句
+		return p.parseWhileStatement(posStart)
 	case lexer.FUNC:
+		// func之后是左括号时为匿名函数表达式，解析为表达式语句
+		if p.NextToken.Type == lexer.LPAREN {
+			return p.parseExpressionStatement(posStart)
+		}
 		// 解析为函数声明语句
 		return p.parseFunctionDeclarationStatement(posStart)
 	case lexer.RETURN:
 		// 解析为return语句
 		return p.parseReturnStatement(posStart)
+	case lexer.BREAK:
+		// 解析为break语句
+		return p.parseBreakStatement(posStart)
+	case lexer.CONTINUE:
+		// 解析为continue语句
+		return p.parseContinueStatement(posStart)
+	case lexer.IMPORT:
+		// 解析为import语句
+		return p.parseImportStatement(posStart)
+	case lexer.TRY:
+		// 解析为try语句
+		return p.parseTryStatement(posStart)
+	case lexer.THROW:
+		// 解析为throw语句
+		return p.parseThrowStatement(posStart)
 	default:
-		// 解析为表达式语句
+		// 解析为表达式语句，语句开头的{}为空的块表达式
+		p.statementBlock = p.CurrToken.Type == lexer.LBRACE
 		return p.parseExpressionStatement(posStart)
 	}
 }
 
-// parseForStatement 解析for语句
+// parseForStatement 解析for语句，for之后是可以带var的标识符和in或逗号时解析为for-in语句
 //
 // 参数:
 //
@@ -258,16 +553,32 @@ func (p *Parser) parseStatement(posStart *util.Pos) ast.Statement {
 //
 // 返回值:
 //
-//	for语句节点ForStatement
-func (p *Parser) parseForStatement(posStart *util.Pos) *ast.ForStatement {
+//	for语句节点ForStatement或for-in语句节点ForInStatement
+func (p *Parser) parseForStatement(posStart *util.Pos) ast.Statement {
+	p.Advance()
 	fs := &ast.ForStatement{
 		PosStart: posStart,
 	}
-	p.Advance()
-	// 解析初始化语句
-	fs.Initialization = p.parseStatement(p.CurrToken.PosStart.Copy())
-	if p.Err != nil {
-		return nil
+	if p.CurrToken.Type == lexer.VAR && p.NextToken.Type == lexer.IDENT {
+		// 跳过var之后才能区分for-in语句和以变量声明开始的for语句
+		initStart := p.CurrToken.PosStart
+		p.Advance()
+		if p.NextToken.Type == lexer.IN || p.NextToken.Type == lexer.COMMA {
+			return p.parseForInStatement(posStart)
+		}
+		expr := p.parseVarInitializationValue(false, initStart)
+		if p.Err != nil {
+			return nil
+		}
+		fs.Initialization = &ast.ExpressionStatement{Expr: expr, PosStart: initStart, PosEnd: p.CurrToken.PosEnd}
+	} else if p.CurrToken.Type == lexer.IDENT && (p.NextToken.Type == lexer.IN || p.NextToken.Type == lexer.COMMA) {
+		return p.parseForInStatement(posStart)
+	} else {
+		// 解析初始化语句
+		fs.Initialization = p.parseStatement(p.CurrToken.PosStart)
+		if p.Err != nil {
+			return nil
+		}
 	}
 	p.CheckNextAndAdvance(lexer.SEMICOLON)
 	if p.Err != nil {
@@ -285,63 +596,205 @@ func (p *Parser) parseForStatement(posStart *util.Pos) *ast.ForStatement {
 	}
 	p.Advance()
 	// 解析更新语句
-	fs.Update = p.parseStatement(p.CurrToken.PosStart.Copy())
+	fs.Update = p.parseStatement(p.CurrToken.PosStart)
 	if p.Err != nil {
 		return nil
 	}
 	p.Advance()
 	// 解析循环体语句
-	fs.Body = p.parseStatement(p.CurrToken.PosStart.Copy())
+	fs.Body = p.parseStatement(p.CurrToken.PosStart)
 	if p.Err != nil {
 		return nil
 	}
-	fs.PosEnd = p.CurrToken.PosEnd.Copy()
+	fs.PosEnd = p.CurrToken.PosEnd
 	return fs
 }
 
-// parseFunctionDeclarationStatement 解析函数表达式
+// parseForInStatement 解析for-in语句，当前token为第一个循环变量
 //
 // 参数:
 //
-//	posStart - 表达式的起始位置
+//	posStart - 语句的起始位置
+//
+// 返回值:
+//
+//	for-in语句节点ForInStatement
+func (p *Parser) parseForInStatement(posStart *util.Pos) *ast.ForInStatement {
+	fs := &ast.ForInStatement{
+		Variable: &ast.IdentifierExpression{Name: p.CurrToken.Literal, PosStart: p.CurrToken.PosStart, PosEnd: p.CurrToken.PosEnd},
+		PosStart: posStart,
+	}
+	if p.NextToken.Type == lexer.COMMA {
+		// 解析映射的值对应的第二个循环变量
+		p.Advance()
+		p.CheckNextAndAdvance(lexer.IDENT)
+		if p.Err != nil {
+			return nil
+		}
+		fs.ValueVariable = &ast.IdentifierExpression{Name: p.CurrToken.Literal, PosStart: p.CurrToken.PosStart, PosEnd: p.CurrToken.PosEnd}
+		if fs.ValueVariable.Name == fs.Variable.Name {
+			p.Err = &SyntaxError{
+				Code:     errcode.DuplicateVariable,
+				Message:  fmt.Sprintf("duplicate loop variable \"%s\", first declared at line %d, column %d.", fs.Variable.Name, fs.Variable.PosStart.Row, fs.Variable.PosStart.Col),
+				PosStart: fs.ValueVariable.PosStart,
+				PosEnd:   fs.ValueVariable.PosEnd,
+			}
+			return nil
+		}
+	}
+	// 跳过in
+	p.CheckNextAndAdvance(lexer.IN)
+	if p.Err != nil {
+		return nil
+	}
+	p.Advance()
+	// 解析被遍历的表达式
+	fs.Iterable = p.ParseExpression(LOWEST)
+	if p.Err != nil {
+		return nil
+	}
+	p.Advance()
+	// 解析循环体语句
+	fs.Body = p.parseStatement(p.CurrToken.PosStart)
+	if p.Err != nil {
+		return nil
+	}
+	fs.PosEnd = p.CurrToken.PosEnd
+	return fs
+}
+
+// parseWhileStatement 解析while语句
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
 //
 // 返回值:
 //
-//	函数表达式节点FunctionDeclarationStatement
+//	while语句节点WhileStatement
+func (p *Parser) parseWhileStatement(posStart *util.Pos) *ast.WhileStatement {
+	ws := &ast.WhileStatement{
+		PosStart: posStart,
+	}
+	p.Advance()
+	// 解析条件表达式
+	ws.Condition = p.ParseExpression(LOWEST)
+	if p.Err != nil {
+		return nil
+	}
+	p.Advance()
+	// 解析循环体语句
+	ws.Body = p.parseStatement(p.CurrToken.PosStart)
+	if p.Err != nil {
+		return nil
+	}
+	ws.PosEnd = p.CurrToken.PosEnd
+	return ws
+}
+
+// parseFunctionDeclarationStatement 解析函数声明语句
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
+//
+// 返回值:
+//
+//	函数声明语句节点FunctionDeclarationStatement
 func (p *Parser) parseFunctionDeclarationStatement(posStart *util.Pos) *ast.FunctionDeclarationStatement {
 	fe := &ast.FunctionDeclarationStatement{
-		PosStart:  posStart,
-		Parameter: make([]*ast.Parameter, 0),
+		PosStart: posStart,
 	}
 	// 解析函数名
 	p.CheckNextAndAdvance(lexer.IDENT)
 	if p.Err != nil {
 		return nil
 	}
-	fe.Name = p.parseIdentifierExpression(p.CurrToken.PosStart.Copy())
-	p.CheckNextAndAdvance(lexer.LPAREN)
+	fe.Name = p.parseIdentifierExpression(p.CurrToken.PosStart)
+	fe.Parameter, fe.Body = p.parseFunction()
+	if p.Err != nil {
+		return nil
+	}
+	fe.PosEnd = p.CurrToken.PosEnd
+	return fe
+}
+
+// parseFunctionExpression 解析匿名函数表达式
+//
+// 参数:
+//
+//	posStart - 表达式的起始位置
+//
+// 返回值:
+//
+//	函数表达式节点FunctionExpression
+func (p *Parser) parseFunctionExpression(posStart *util.Pos) ast.Expression {
+	fe := &ast.FunctionExpression{
+		PosStart: posStart,
+	}
+	fe.Parameter, fe.Body = p.parseFunction()
 	if p.Err != nil {
 		return nil
 	}
+	fe.PosEnd = p.CurrToken.PosEnd
+	return fe
+}
+
+// parseFunction 解析函数的参数列表和函数体，函数声明语句和函数表达式共用
+// 调用时当前token为参数列表之前的函数名或func关键字
+//
+// 返回值:
+//
+//	[]*ast.Parameter - 参数列表
+//	ast.Statement - 函数体
+func (p *Parser) parseFunction() ([]*ast.Parameter, ast.Statement) {
+	parameters := make([]*ast.Parameter, 0)
+	p.CheckNextAndAdvance(lexer.LPAREN)
+	if p.Err != nil {
+		return nil, nil
+	}
+	p.openDelimiter()
 	p.Advance()
 	haveDefault := false
 	// 解析函数参数
 	for p.CurrToken.Type != lexer.RPAREN {
-		paraPosStart := p.CurrToken.PosStart.Copy()
+		// 参数列表在文件结尾处中断时缺少的是右括号
+		if p.CurrToken.Type == lexer.EOF {
+			p.Err = p.expectedError(lexer.RPAREN, p.CurrToken)
+			return nil, nil
+		}
+		if p.CurrToken.Type != lexer.IDENT {
+			p.Err = p.expectedError(lexer.IDENT, p.CurrToken)
+			return nil, nil
+		}
+		paraPosStart := p.CurrToken.PosStart
 		// 解析参数
 		expr := p.parseIdentifierExpression(paraPosStart)
 		if p.Err != nil {
-			return nil
+			return nil, nil
 		}
 		para := expr.(*ast.IdentifierExpression)
+		// 同名参数在调用时会覆盖之前的参数，报告在第二次出现的位置
+		for _, prev := range parameters {
+			if prev.Name.Name == para.Name {
+				p.Err = &SyntaxError{
+					Code:     errcode.DuplicateParameter,
+					Message:  fmt.Sprintf("duplicate parameter \"%s\", first declared at line %d, column %d.", para.Name, prev.PosStart.Row, prev.PosStart.Col),
+					PosStart: paraPosStart,
+					PosEnd:   p.CurrToken.PosEnd,
+				}
+				return nil, nil
+			}
+		}
 		var defaultValue ast.Expression = nil
 		if haveDefault && p.NextToken.Type != lexer.EQUAL {
 			p.Err = &SyntaxError{
+				Code:     errcode.DefaultParamOrder,
 				Message:  "non-default parameter follows default parameter.",
 				PosStart: paraPosStart,
-				PosEnd:   p.CurrToken.PosEnd.Copy(),
+				PosEnd:   p.CurrToken.PosEnd,
 			}
-			return nil
+			return nil, nil
 		}
 		// 解析默认值
 		if p.NextToken.Type == lexer.EQUAL {
@@ -349,7 +802,7 @@ func (p *Parser) parseFunctionDeclarationStatement(posStart *util.Pos) *ast.Func
 			p.Advance()
 			defaultExpr := p.ParseExpression(LOWEST)
 			if p.Err != nil {
-				return nil
+				return nil, nil
 			}
 			defaultValue = defaultExpr
 			haveDefault = true
@@ -359,29 +812,33 @@ func (p *Parser) parseFunctionDeclarationStatement(posStart *util.Pos) *ast.Func
 			Name:         para,
 			DefaultValue: defaultValue,
 			PosStart:     paraPosStart,
-			PosEnd:       p.CurrToken.PosEnd.Copy(),
+			PosEnd:       p.CurrToken.PosEnd,
 		}
-		fe.Parameter = append(fe.Parameter, parameter)
+		parameters = append(parameters, parameter)
 		if p.Err != nil {
-			return nil
+			return nil, nil
 		}
 		// 检查参数后的逗号
+		if p.NextToken.Type == lexer.EOF {
+			p.Err = p.expectedError(lexer.RPAREN, p.NextToken)
+			return nil, nil
+		}
 		if p.NextToken.Type != lexer.RPAREN {
 			p.CheckNextAndAdvance(lexer.COMMA)
 			if p.Err != nil {
-				return nil
+				return nil, nil
 			}
 		}
 		p.Advance()
 	}
+	p.closeDelimiter()
 	p.Advance()
 	// 解析函数体
-	fe.Body = p.parseStatement(p.CurrToken.PosStart.Copy())
+	body := p.parseStatement(p.CurrToken.PosStart)
 	if p.Err != nil {
-		return nil
+		return nil, nil
 	}
-	fe.PosEnd = p.CurrToken.PosEnd.Copy()
-	return fe
+	return parameters, body
 }
 
 // parseReturnStatement 解析return语句
@@ -403,10 +860,150 @@ func (p *Parser) parseReturnStatement(posStart *util.Pos) *ast.ReturnStatement {
 	if p.Err != nil {
 		return nil
 	}
-	rs.PosEnd = p.CurrToken.PosEnd.Copy()
+	rs.PosEnd = p.CurrToken.PosEnd
 	return rs
 }
 
+// parseBreakStatement 解析break语句
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
+//
+// 返回值:
+//
+//	break语句节点BreakStatement
+func (p *Parser) parseBreakStatement(posStart *util.Pos) *ast.BreakStatement {
+	return &ast.BreakStatement{
+		PosStart: posStart,
+		PosEnd:   p.CurrToken.PosEnd,
+	}
+}
+
+// parseContinueStatement 解析continue语句
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
+//
+// 返回值:
+//
+//	continue语句节点ContinueStatement
+func (p *Parser) parseContinueStatement(posStart *util.Pos) *ast.ContinueStatement {
+	return &ast.ContinueStatement{
+		PosStart: posStart,
+		PosEnd:   p.CurrToken.PosEnd,
+	}
+}
+
+// parseTryStatement 解析try语句
+// 格式为：try <body> catch (<variable>) <handler>
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
+//
+// 返回值:
+//
+//	try语句节点TryStatement
+func (p *Parser) parseTryStatement(posStart *util.Pos) *ast.TryStatement {
+	ts := &ast.TryStatement{
+		PosStart: posStart,
+	}
+	p.Advance()
+	// 解析主体语句
+	ts.Body = p.parseStatement(p.CurrToken.PosStart)
+	if p.Err != nil {
+		return nil
+	}
+	p.CheckNextAndAdvance(lexer.CATCH)
+	if p.Err != nil {
+		return nil
+	}
+	cc := &ast.CatchClause{
+		PosStart: p.CurrToken.PosStart,
+	}
+	// 解析括号中的变量名
+	p.CheckNextAndAdvance(lexer.LPAREN)
+	if p.Err != nil {
+		return nil
+	}
+	p.openDelimiter()
+	p.CheckNextAndAdvance(lexer.IDENT)
+	if p.Err != nil {
+		return nil
+	}
+	cc.Variable = p.parseIdentifierExpression(p.CurrToken.PosStart).(*ast.IdentifierExpression)
+	p.CheckNextAndAdvance(lexer.RPAREN)
+	if p.Err != nil {
+		return nil
+	}
+	p.closeDelimiter()
+	p.Advance()
+	// 解析处理语句
+	cc.Body = p.parseStatement(p.CurrToken.PosStart)
+	if p.Err != nil {
+		return nil
+	}
+	cc.PosEnd = p.CurrToken.PosEnd
+	ts.Catch = cc
+	ts.PosEnd = p.CurrToken.PosEnd
+	return ts
+}
+
+// parseThrowStatement 解析throw语句
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
+//
+// 返回值:
+//
+//	throw语句节点ThrowStatement
+func (p *Parser) parseThrowStatement(posStart *util.Pos) *ast.ThrowStatement {
+	ts := &ast.ThrowStatement{
+		PosStart: posStart,
+	}
+	p.Advance()
+	// 解析抛出的表达式
+	ts.Value = p.ParseExpression(LOWEST)
+	if p.Err != nil {
+		return nil
+	}
+	ts.PosEnd = p.CurrToken.PosEnd
+	return ts
+}
+
+// parseImportStatement 解析import语句
+//
+// 参数:
+//
+//	posStart - 语句的起始位置
+//
+// 返回值:
+//
+//	import语句节点ImportStatement
+func (p *Parser) parseImportStatement(posStart *util.Pos) *ast.ImportStatement {
+	is := &ast.ImportStatement{
+		PosStart: posStart,
+	}
+	// 按名称导入模块
+	if p.NextToken.Type == lexer.IDENT {
+		p.Advance()
+		is.Name = p.parseIdentifierExpression(p.CurrToken.PosStart).(*ast.IdentifierExpression)
+		is.PosEnd = p.CurrToken.PosEnd
+		return is
+	}
+	// 解析导入路径
+	p.CheckNextAndAdvance(lexer.STRING)
+	if p.Err != nil {
+		return nil
+	}
+	is.Path = p.parseStringExpression(p.CurrToken.PosStart).(*ast.StringExpression)
+	is.PosEnd = p.CurrToken.PosEnd
+	return is
+}
+
 // parseExpressionStatement 解析表达式语句(由单个表达式组成的语句)
 //
 // 参数:
@@ -421,7 +1018,7 @@ func (p *Parser) parseExpressionStatement(posStart *util.Pos) *ast.ExpressionSta
 	if p.Err != nil {
 		return nil
 	}
-	return &ast.ExpressionStatement{Expr: expr, PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	return &ast.ExpressionStatement{Expr: expr, PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
 }
 
 // ParseExpression 解析表达式，根据运算符优先级递归构建表达式节点
@@ -434,18 +1031,35 @@ func (p *Parser) parseExpressionStatement(posStart *util.Pos) *ast.ExpressionSta
 //
 //	解析得到的表达式节点
 func (p *Parser) ParseExpression(precedence int) ast.Expression {
-	posStart := p.CurrToken.PosStart.Copy()
+	posStart := p.CurrToken.PosStart
 	// 根据当前token类型获取对应的前缀解析函数
 	prefixFn := p.PrefixParseFns[p.CurrToken.Type]
+	if p.CurrToken.Type == lexer.EOF {
+		p.Err = p.eofError(errcode.UnexpectedToken, "expected an expression before end of file.", 0)
+		return nil
+	}
 	if prefixFn == nil {
+		// 非法标记处已有词法错误，直接报告
+		if p.CurrToken.Type == lexer.ILLEGAL && p.Err != nil {
+			return nil
+		}
 		// 如果没有对应的前缀解析函数，返回语法错误
 		p.Err = &SyntaxError{
+			Code:     errcode.UnexpectedToken,
 			Message:  fmt.Sprintf("unexpected \"%s\".", p.CurrToken.Type),
 			PosStart: posStart,
-			PosEnd:   p.CurrToken.PosEnd.Copy(),
+			PosEnd:   p.CurrToken.PosEnd,
 		}
 		return nil
 	}
+	if !p.enter() {
+		return nil
+	}
+	// 左结合的中缀表达式不递归分析，但同样使语法树加深，每个运算符都计入嵌套深度
+	nested := 1
+	defer func() {
+		p.nesting -= nested
+	}()
 	expr := prefixFn(posStart)
 	if p.Err != nil {
 		return nil
@@ -457,6 +1071,10 @@ func (p *Parser) ParseExpression(precedence int) ast.Expression {
 			return expr
 		}
 		p.Advance()
+		if !p.enter() {
+			return nil
+		}
+		nested++
 		expr = infixFn(expr, posStart)
 		if p.Err != nil {
 			return nil
@@ -486,7 +1104,7 @@ func (p *Parser) parsePrefixExpression(posStart *util.Pos) ast.Expression {
 		return nil
 	}
 	pe.Value = expr
-	pe.PosEnd = p.CurrToken.PosEnd.Copy()
+	pe.PosEnd = p.CurrToken.PosEnd
 	return pe
 }
 
@@ -505,13 +1123,14 @@ func (p *Parser) parseIntegerExpression(posStart *util.Pos) ast.Expression {
 	if ok != nil {
 		// 转换失败时返回非法token错误
 		p.Err = &lexer.IllegalTokenError{
+			Code:     errcode.IllegalInteger,
 			Message:  "illegal integer.",
 			PosStart: posStart,
-			PosEnd:   p.CurrToken.PosEnd.Copy(),
+			PosEnd:   p.CurrToken.PosEnd,
 		}
 		return nil
 	}
-	return &ast.IntExpression{Value: num, PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	return &ast.IntExpression{Value: num, PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
 }
 
 // parseFloatExpression 解析浮点数表达式
@@ -529,13 +1148,14 @@ func (p *Parser) parseFloatExpression(posStart *util.Pos) ast.Expression {
 	if ok != nil {
 		// 转换失败时返回非法token错误
 		p.Err = &lexer.IllegalTokenError{
+			Code:     errcode.IllegalFloat,
 			Message:  "illegal float.",
 			PosStart: posStart,
-			PosEnd:   p.CurrToken.PosEnd.Copy(),
+			PosEnd:   p.CurrToken.PosEnd,
 		}
 		return nil
 	}
-	return &ast.FloatExpression{Value: num, PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	return &ast.FloatExpression{Value: num, PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
 }
 
 // parseIdentifierExpression 解析标识符表达式(变量名、函数名等)
@@ -548,7 +1168,7 @@ func (p *Parser) parseFloatExpression(posStart *util.Pos) ast.Expression {
 //
 //	标识符表达式节点IdentifierExpression
 func (p *Parser) parseIdentifierExpression(posStart *util.Pos) ast.Expression {
-	return &ast.IdentifierExpression{Name: p.CurrToken.Literal, PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	return &ast.IdentifierExpression{Name: p.CurrToken.Literal, PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
 }
 
 // parseBoolExpression 解析布尔表达式(true或false)
@@ -561,7 +1181,7 @@ func (p *Parser) parseIdentifierExpression(posStart *util.Pos) ast.Expression {
 //
 //	布尔表达式节点BoolExpression
 func (p *Parser) parseBoolExpression(posStart *util.Pos) ast.Expression {
-	return &ast.BoolExpression{Value: p.CurrToken.Literal == "true", PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	return &ast.BoolExpression{Value: p.CurrToken.Literal == "true", PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
 }
 
 // parseNullExpression 解析空值表达式(null)
@@ -574,7 +1194,7 @@ func (p *Parser) parseBoolExpression(posStart *util.Pos) ast.Expression {
 //
 //	空值表达式节点NullExpression
 func (p *Parser) parseNullExpression(posStart *util.Pos) ast.Expression {
-	return &ast.NullExpression{PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	return &ast.NullExpression{PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
 }
 
 // parseStringExpression 解析字符串表达式
@@ -587,7 +1207,42 @@ func (p *Parser) parseNullExpression(posStart *util.Pos) ast.Expression {
 //
 //	字符串表达式节点StringExpression
 func (p *Parser) parseStringExpression(posStart *util.Pos) ast.Expression {
-	return &ast.StringExpression{Value: p.CurrToken.Literal, PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	return &ast.StringExpression{Value: p.CurrToken.Literal, PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
+}
+
+// parseInterpolatedStringExpression 解析插值字符串表达式
+// 当前token为插值字符串的开头，之后交替出现插值表达式和字符串片段，直到插值字符串的结尾
+//
+// 参数:
+//
+//	posStart - 表达式的起始位置
+//
+// 返回值:
+//
+//	插值字符串表达式节点InterpolatedStringExpression
+func (p *Parser) parseInterpolatedStringExpression(posStart *util.Pos) ast.Expression {
+	ise := &ast.InterpolatedStringExpression{PosStart: posStart}
+	for {
+		ise.Parts = append(ise.Parts, &ast.StringExpression{Value: p.CurrToken.Literal, PosStart: p.CurrToken.PosStart, PosEnd: p.CurrToken.PosEnd})
+		if p.CurrToken.Type == lexer.INTERP_END {
+			ise.PosEnd = p.CurrToken.PosEnd
+			return ise
+		}
+		p.Advance()
+		expr := p.ParseExpression(LOWEST)
+		if p.Err != nil {
+			return nil
+		}
+		ise.Parts = append(ise.Parts, expr)
+		if p.NextToken.Type != lexer.INTERP_MID {
+			p.CheckNextAndAdvance(lexer.INTERP_END)
+			if p.Err != nil {
+				return nil
+			}
+			continue
+		}
+		p.Advance()
+	}
 }
 
 // parseGroupedExpression 解析分组表达式(括号内的表达式)
@@ -600,6 +1255,7 @@ func (p *Parser) parseStringExpression(posStart *util.Pos) ast.Expression {
 //
 //	分组表达式节点GroupedExpression
 func (p *Parser) parseGroupedExpression(posStart *util.Pos) ast.Expression {
+	p.openDelimiter()
 	p.Advance()
 	// 解析括号内的表达式
 	expr := p.ParseExpression(LOWEST)
@@ -608,7 +1264,11 @@ func (p *Parser) parseGroupedExpression(posStart *util.Pos) ast.Expression {
 	}
 	// 确保括号匹配
 	p.CheckNextAndAdvance(lexer.RPAREN)
-	return &ast.GroupedExpression{Expr: expr, PosStart: posStart, PosEnd: p.CurrToken.PosEnd.Copy()}
+	if p.Err != nil {
+		return nil
+	}
+	p.closeDelimiter()
+	return &ast.GroupedExpression{Expr: expr, PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
 }
 
 // parseVarInitializationExpression 解析变量初始化表达式(var或const)
@@ -628,8 +1288,22 @@ func (p *Parser) parseVarInitializationExpression(posStart *util.Pos) ast.Expres
 	if p.Err != nil {
 		return nil
 	}
+	return p.parseVarInitializationValue(isConst, posStart)
+}
+
+// parseVarInitializationValue 解析变量声明中变量名之后的部分，当前token为变量名
+//
+// 参数:
+//
+//	isConst - 是否为常量声明
+//	posStart - 表达式的起始位置
+//
+// 返回值:
+//
+//	变量初始化表达式节点VarInitialization
+func (p *Parser) parseVarInitializationValue(isConst bool, posStart *util.Pos) ast.Expression {
 	// 解析变量名
-	name := p.parseIdentifierExpression(p.CurrToken.PosStart.Copy())
+	name := p.parseIdentifierExpression(p.CurrToken.PosStart)
 	// 检查并消耗赋值运算符
 	p.CheckNextAndAdvance(lexer.EQUAL)
 	if p.Err != nil {
@@ -646,7 +1320,7 @@ func (p *Parser) parseVarInitializationExpression(posStart *util.Pos) ast.Expres
 		Name:     name,
 		Value:    value,
 		PosStart: posStart,
-		PosEnd:   p.CurrToken.PosEnd.Copy(),
+		PosEnd:   p.CurrToken.PosEnd,
 	}
 }
 
@@ -663,9 +1337,10 @@ func (p *Parser) parseVarInitializationExpression(posStart *util.Pos) ast.Expres
 func (p *Parser) parseVarAssignmentExpression(left ast.Expression, posStart *util.Pos) ast.Expression {
 	if !left.IsLvalue() {
 		p.Err = &SyntaxError{
+			Code:     errcode.InvalidAssignTarget,
 			Message:  "operation \"=\" requires an lvalue operand.",
 			PosStart: posStart,
-			PosEnd:   p.CurrToken.PosEnd.Copy(),
+			PosEnd:   p.CurrToken.PosEnd,
 		}
 		return nil
 	}
@@ -679,7 +1354,7 @@ func (p *Parser) parseVarAssignmentExpression(left ast.Expression, posStart *uti
 		Name:     left,
 		Value:    value,
 		PosStart: posStart,
-		PosEnd:   p.CurrToken.PosEnd.Copy(),
+		PosEnd:   p.CurrToken.PosEnd,
 	}
 }
 
@@ -687,9 +1362,10 @@ func (p *Parser) parseCompoundAssignmentExpression(left ast.Expression, posStart
 	// 检查左侧表达式是否为左值
 	if !left.IsLvalue() {
 		p.Err = &SyntaxError{
+			Code:     errcode.InvalidAssignTarget,
 			Message:  fmt.Sprintf("operation \"%s\" requires an lvalue operand.", p.CurrToken.Literal),
 			PosStart: posStart,
-			PosEnd:   p.CurrToken.PosEnd.Copy(),
+			PosEnd:   p.CurrToken.PosEnd,
 		}
 		return nil
 	}
@@ -706,7 +1382,7 @@ func (p *Parser) parseCompoundAssignmentExpression(left ast.Expression, posStart
 		Operator: operator,
 		Right:    right,
 		PosStart: posStart,
-		PosEnd:   p.CurrToken.PosEnd.Copy(),
+		PosEnd:   p.CurrToken.PosEnd,
 	}
 }
 
@@ -731,9 +1407,10 @@ func (p *Parser) parsePrefixUnaryIncDecExpression(posStart *util.Pos) ast.Expres
 	// 检查右侧表达式是否为左值
 	if !right.IsLvalue() {
 		p.Err = &SyntaxError{
+			Code:     errcode.InvalidAssignTarget,
 			Message:  "operation \"++\" or \"--\" requires an lvalue operand.",
 			PosStart: posStart,
-			PosEnd:   p.CurrToken.PosEnd.Copy(),
+			PosEnd:   p.CurrToken.PosEnd,
 		}
 		return nil
 	}
@@ -741,7 +1418,7 @@ func (p *Parser) parsePrefixUnaryIncDecExpression(posStart *util.Pos) ast.Expres
 		Operator: operator,
 		Right:    right,
 		PosStart: posStart,
-		PosEnd:   p.CurrToken.PosEnd.Copy(),
+		PosEnd:   p.CurrToken.PosEnd,
 	}
 }
 
@@ -751,9 +1428,10 @@ func (p *Parser) parsePostfixUnaryIncDecExpression(left ast.Expression, posStart
 	// 检查左侧表达式是否为左值
 	if !left.IsLvalue() {
 		p.Err = &SyntaxError{
+			Code:     errcode.InvalidAssignTarget,
 			Message:  "operation \"++\" or \"--\" requires an lvalue operand.",
 			PosStart: posStart,
-			PosEnd:   p.CurrToken.PosEnd.Copy(),
+			PosEnd:   p.CurrToken.PosEnd,
 		}
 		return nil
 	}
@@ -761,7 +1439,7 @@ func (p *Parser) parsePostfixUnaryIncDecExpression(left ast.Expression, posStart
 		Operator: operator,
 		Left:     left,
 		PosStart: posStart,
-		PosEnd:   p.CurrToken.PosEnd.Copy(),
+		PosEnd:   p.CurrToken.PosEnd,
 	}
 }
 
@@ -790,10 +1468,47 @@ func (p *Parser) parseInfixExpression(left ast.Expression, posStart *util.Pos) a
 		return nil
 	}
 	ie.Right = right
-	ie.PosEnd = p.CurrToken.PosEnd.Copy()
+	ie.PosEnd = p.CurrToken.PosEnd
 	return ie
 }
 
+// parseTernaryExpression 解析条件运算符表达式
+// 两个分支之间的表达式按最低优先级解析；条件运算符右结合，a ? b : c ? d : e 等价于 a ? b : (c ? d : e)
+//
+// 参数:
+//
+//	condition - 问号之前的条件表达式
+//	posStart - 表达式的起始位置
+//
+// 返回值:
+//
+//	条件运算符表达式节点TernaryExpression
+func (p *Parser) parseTernaryExpression(condition ast.Expression, posStart *util.Pos) ast.Expression {
+	te := &ast.TernaryExpression{
+		Condition: condition,
+		PosStart:  posStart,
+	}
+	p.Advance()
+	// 解析条件为真时的表达式
+	te.Consequence = p.ParseExpression(LOWEST)
+	if p.Err != nil {
+		return nil
+	}
+	// 检查并消耗分隔两个分支的冒号
+	p.CheckNextAndAdvance(lexer.COLON)
+	if p.Err != nil {
+		return nil
+	}
+	p.Advance()
+	// 以低于条件运算符的优先级解析，使之后的条件运算符成为这一分支的一部分
+	te.Alternative = p.ParseExpression(TERNARY - 1)
+	if p.Err != nil {
+		return nil
+	}
+	te.PosEnd = p.CurrToken.PosEnd
+	return te
+}
+
 // parseBlockExpression 解析块表达式
 //
 // 参数:
@@ -807,7 +1522,18 @@ func (p *Parser) parseBlockExpression(posStart *util.Pos) ast.Expression {
 	expr := &ast.BlockExpression{
 		PosStart: posStart,
 	}
+	statementBlock := p.statementBlock
+	p.statementBlock = false
+	p.openDelimiter()
 	p.Advance()
+	// {:}为空映射，表达式中的{}同样为空映射，语句开头的{}为空的块表达式
+	if (p.CurrToken.Type == lexer.COLON && p.NextToken.Type == lexer.RBRACE) || (p.CurrToken.Type == lexer.RBRACE && !statementBlock) {
+		if p.CurrToken.Type == lexer.COLON {
+			p.Advance()
+		}
+		p.closeDelimiter()
+		return &ast.MapExpression{Entries: []*ast.MapEntry{}, PosStart: posStart, PosEnd: p.CurrToken.PosEnd}
+	}
 	// 循环解析所有语句直到遇到右大括号
 	for p.CurrToken.Type != lexer.RBRACE {
 		if p.Err != nil {
@@ -821,11 +1547,15 @@ func (p *Parser) parseBlockExpression(posStart *util.Pos) ast.Expression {
 			break
 		}
 		// 解析单个语句
-		statPosStart := p.CurrToken.PosStart.Copy()
+		statPosStart := p.CurrToken.PosStart
 		stat := p.parseStatement(statPosStart)
 		if p.Err != nil {
 			return nil
 		}
+		// 第一个表达式之后是冒号时，花括号中的内容是映射的键值对
+		if es, ok := stat.(*ast.ExpressionStatement); ok && len(expr.Statements) == 0 && p.NextToken.Type == lexer.COLON {
+			return p.parseMapExpression(posStart, es.Expr)
+		}
 		// 如果块表达式还未结束
 		if p.NextToken.Type != lexer.RBRACE {
 			// 检查语句后的分号
@@ -838,10 +1568,62 @@ func (p *Parser) parseBlockExpression(posStart *util.Pos) ast.Expression {
 		expr.Statements = append(expr.Statements, stat)
 		p.Advance()
 	}
-	expr.PosEnd = p.CurrToken.PosEnd.Copy()
+	p.closeDelimiter()
+	expr.PosEnd = p.CurrToken.PosEnd
 	return expr
 }
 
+// parseMapExpression 解析映射表达式的键值对，左花括号和第一个键已由parseBlockExpression解析
+//
+// 参数:
+//
+//	posStart - 表达式的起始位置
+//	key - 第一个键表达式，当前token为它的最后一个token
+//
+// 返回值:
+//
+//	映射表达式节点MapExpression
+func (p *Parser) parseMapExpression(posStart *util.Pos, key ast.Expression) ast.Expression {
+	me := &ast.MapExpression{
+		Entries:  make([]*ast.MapEntry, 0),
+		PosStart: posStart,
+	}
+	for {
+		// 检查并消耗键之后的冒号
+		p.CheckNextAndAdvance(lexer.COLON)
+		if p.Err != nil {
+			return nil
+		}
+		p.Advance()
+		// 解析值表达式
+		value := p.ParseExpression(LOWEST)
+		if p.Err != nil {
+			return nil
+		}
+		me.Entries = append(me.Entries, &ast.MapEntry{Key: key, Value: value})
+		// 检查是否还有更多键值对(通过逗号分隔)
+		if p.NextToken.Type != lexer.RBRACE {
+			p.CheckNextAndAdvance(lexer.COMMA)
+			if p.Err != nil {
+				return nil
+			}
+		}
+		p.Advance()
+		if p.CurrToken.Type == lexer.RBRACE {
+			break
+		}
+		// 解析下一个键表达式
+		key = p.ParseExpression(LOWEST)
+		if p.Err != nil {
+			return nil
+		}
+	}
+	p.closeDelimiter()
+	// 设置映射表达式的结束位置
+	me.PosEnd = p.CurrToken.PosEnd
+	return me
+}
+
 // parseIfExpression 解析if表达式
 //
 // 参数:
@@ -880,7 +1662,7 @@ func (p *Parser) parseIfExpression(posStart *util.Pos) ast.Expression {
 		// 如果没有else分支，设置为nil
 		ie.Alternative = nil
 	}
-	ie.PosEnd = p.CurrToken.PosEnd.Copy()
+	ie.PosEnd = p.CurrToken.PosEnd
 	return ie
 }
 
@@ -898,10 +1680,12 @@ func (p *Parser) parseListExpression(posStart *util.Pos) ast.Expression {
 		Value:    make([]ast.Expression, 0),
 		PosStart: posStart,
 	}
+	p.openDelimiter()
 	p.Advance()
 	// 处理空列表的情况
 	if p.CurrToken.Type == lexer.RBRACKET {
-		le.PosEnd = p.CurrToken.PosEnd.Copy()
+		p.closeDelimiter()
+		le.PosEnd = p.CurrToken.PosEnd
 		return le
 	}
 	// 循环解析列表中的元素直到遇到右方括号
@@ -926,8 +1710,9 @@ func (p *Parser) parseListExpression(posStart *util.Pos) ast.Expression {
 		}
 		p.Advance()
 	}
+	p.closeDelimiter()
 	// 设置列表表达式的结束位置
-	le.PosEnd = p.CurrToken.PosEnd.Copy()
+	le.PosEnd = p.CurrToken.PosEnd
 	return le
 }
 
@@ -946,6 +1731,7 @@ func (p *Parser) parseCallExpression(left ast.Expression, posStart *util.Pos) as
 		Argument: make([]ast.Expression, 0),
 		PosStart: posStart,
 	}
+	p.openDelimiter()
 	p.Advance()
 	for p.CurrToken.Type != lexer.RPAREN {
 		// 如果不是逗号
@@ -966,11 +1752,12 @@ func (p *Parser) parseCallExpression(left ast.Expression, posStart *util.Pos) as
 		}
 		p.Advance()
 	}
-	ce.PosEnd = p.CurrToken.PosEnd.Copy()
+	p.closeDelimiter()
+	ce.PosEnd = p.CurrToken.PosEnd
 	return ce
 }
 
-// parseIndexExpression 解析索引表达式
+// parseIndexExpression 解析索引表达式，方括号中出现':'时解析为切片表达式
 //
 // 参数:
 //
@@ -979,25 +1766,71 @@ func (p *Parser) parseCallExpression(left ast.Expression, posStart *util.Pos) as
 //
 // 返回值:
 //
-//	索引表达式节点 IndexExpression
+//	索引表达式节点 IndexExpression 或切片表达式节点 SliceExpression
 func (p *Parser) parseIndexExpression(left ast.Expression, posStart *util.Pos) ast.Expression {
 	// 当前 CurrToken 为 '['
+	p.openDelimiter()
 	p.Advance()
+	// 省略起始索引的切片
+	if p.CurrToken.Type == lexer.COLON {
+		return p.parseSliceExpression(left, nil, posStart)
+	}
 	// 解析索引表达式
 	indexExpr := p.ParseExpression(LOWEST)
 	if p.Err != nil {
 		return nil
 	}
+	if p.NextToken.Type == lexer.COLON {
+		p.Advance()
+		return p.parseSliceExpression(left, indexExpr, posStart)
+	}
 	// 期待并消耗 ']'
 	p.CheckNextAndAdvance(lexer.RBRACKET)
 	if p.Err != nil {
 		return nil
 	}
+	p.closeDelimiter()
 	ie := &ast.IndexExpression{
 		Target:   left,
 		Index:    indexExpr,
 		PosStart: posStart,
-		PosEnd:   p.CurrToken.PosEnd.Copy(),
+		PosEnd:   p.CurrToken.PosEnd,
 	}
 	return ie
 }
+
+// parseSliceExpression 解析切片表达式中':'之后的部分，由parseIndexExpression在方括号中遇到':'时调用
+//
+// 参数:
+//
+//	left - 左侧目标表达式
+//	low - 已解析的起始索引，省略时为nil
+//	posStart - 表达式的起始位置
+//
+// 返回值:
+//
+//	切片表达式节点 SliceExpression
+func (p *Parser) parseSliceExpression(left, low ast.Expression, posStart *util.Pos) ast.Expression {
+	// 当前 CurrToken 为 ':'
+	var high ast.Expression
+	if p.NextToken.Type != lexer.RBRACKET {
+		p.Advance()
+		high = p.ParseExpression(LOWEST)
+		if p.Err != nil {
+			return nil
+		}
+	}
+	// 期待并消耗 ']'
+	p.CheckNextAndAdvance(lexer.RBRACKET)
+	if p.Err != nil {
+		return nil
+	}
+	p.closeDelimiter()
+	return &ast.SliceExpression{
+		Target:   left,
+		Low:      low,
+		High:     high,
+		PosStart: posStart,
+		PosEnd:   p.CurrToken.PosEnd,
+	}
+}
@@ -1,9 +1,12 @@
 package parser
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
 	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
 	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
 	"github.com/Ghost-Xiao/ghost-lang/internal/util"
@@ -20,8 +23,8 @@ func TestParser_ParseProgram(t *testing.T) {
 			input: "",
 			expected: &ast.Program{
 				Statements: []ast.Statement{},
-				PosStart:   util.NewPos(1, 1, 0, "<test>", ""),
-				PosEnd:     util.NewPos(1, 2, 1, "<test>", ""),
+				PosStart:   util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "")),
+				PosEnd:     util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "")),
 			},
 		},
 		{
@@ -35,39 +38,39 @@ var 真 = true;
 					&ast.ExpressionStatement{
 						Expr: &ast.IntExpression{
 							Value:    1,
-							PosStart: util.NewPos(1, 1, 0, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-							PosEnd:   util.NewPos(1, 2, 1, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+							PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+							PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 						},
-						PosStart: util.NewPos(1, 1, 0, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-						PosEnd:   util.NewPos(1, 2, 1, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+						PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+						PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 					},
 					&ast.ExpressionStatement{
 						Expr: &ast.VarInitializationExpression{
 							IsConst: false,
 							Name: &ast.IdentifierExpression{
 								Name:     "真",
-								PosStart: util.NewPos(2, 5, 7, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-								PosEnd:   util.NewPos(2, 6, 10, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+								PosStart: util.NewPos(2, 5, 7, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+								PosEnd:   util.NewPos(2, 6, 10, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 							},
 							Value: &ast.BoolExpression{
 								Value:    true,
-								PosStart: util.NewPos(2, 9, 13, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-								PosEnd:   util.NewPos(2, 13, 17, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+								PosStart: util.NewPos(2, 9, 13, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+								PosEnd:   util.NewPos(2, 13, 17, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 							},
-							PosStart: util.NewPos(2, 1, 3, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-							PosEnd:   util.NewPos(2, 13, 17, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+							PosStart: util.NewPos(2, 1, 3, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+							PosEnd:   util.NewPos(2, 13, 17, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 						},
-						PosStart: util.NewPos(2, 1, 3, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-						PosEnd:   util.NewPos(2, 13, 17, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+						PosStart: util.NewPos(2, 1, 3, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+						PosEnd:   util.NewPos(2, 13, 17, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 					},
 					&ast.ExpressionStatement{
 						Expr: &ast.StringExpression{
 							Value:    "hello\n",
-							PosStart: util.NewPos(3, 1, 19, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-							PosEnd:   util.NewPos(3, 10, 28, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+							PosStart: util.NewPos(3, 1, 19, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+							PosEnd:   util.NewPos(3, 10, 28, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 						},
-						PosStart: util.NewPos(3, 1, 19, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-						PosEnd:   util.NewPos(3, 10, 28, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+						PosStart: util.NewPos(3, 1, 19, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+						PosEnd:   util.NewPos(3, 10, 28, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 					},
 					&ast.ExpressionStatement{
 						Expr: &ast.InfixExpression{
@@ -75,37 +78,37 @@ var 真 = true;
 								Operator: &lexer.Token{
 									Type:     lexer.MINUS,
 									Literal:  "-",
-									PosStart: util.NewPos(4, 1, 30, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-									PosEnd:   util.NewPos(4, 2, 31, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+									PosStart: util.NewPos(4, 1, 30, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+									PosEnd:   util.NewPos(4, 2, 31, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 								},
 								Value: &ast.IntExpression{
 									Value:    1,
-									PosStart: util.NewPos(4, 2, 31, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-									PosEnd:   util.NewPos(4, 3, 32, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+									PosStart: util.NewPos(4, 2, 31, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+									PosEnd:   util.NewPos(4, 3, 32, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 								},
-								PosStart: util.NewPos(4, 1, 30, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-								PosEnd:   util.NewPos(4, 3, 32, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+								PosStart: util.NewPos(4, 1, 30, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+								PosEnd:   util.NewPos(4, 3, 32, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 							},
 							Operator: &lexer.Token{
 								Type:     lexer.PLUS,
 								Literal:  "+",
-								PosStart: util.NewPos(4, 4, 33, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-								PosEnd:   util.NewPos(4, 5, 34, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+								PosStart: util.NewPos(4, 4, 33, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+								PosEnd:   util.NewPos(4, 5, 34, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 							},
 							Right: &ast.IntExpression{
 								Value:    1,
-								PosStart: util.NewPos(4, 6, 35, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-								PosEnd:   util.NewPos(4, 7, 36, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+								PosStart: util.NewPos(4, 6, 35, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+								PosEnd:   util.NewPos(4, 7, 36, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 							},
-							PosStart: util.NewPos(4, 1, 30, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-							PosEnd:   util.NewPos(4, 7, 36, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+							PosStart: util.NewPos(4, 1, 30, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+							PosEnd:   util.NewPos(4, 7, 36, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 						},
-						PosStart: util.NewPos(4, 1, 30, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-						PosEnd:   util.NewPos(4, 7, 36, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+						PosStart: util.NewPos(4, 1, 30, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+						PosEnd:   util.NewPos(4, 7, 36, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
-				PosEnd:   util.NewPos(4, 9, 38, "<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
+				PosEnd:   util.NewPos(4, 8, 37, util.NewSourceFile("<test>", "1;\nvar 真 = true;\n\"hello\\n\";\n-1 + 1;")),
 			},
 		},
 	}
@@ -141,70 +144,70 @@ func TestParser_ParseForStatement(t *testing.T) {
 						IsConst: false,
 						Name: &ast.IdentifierExpression{
 							Name:     "i",
-							PosStart: util.NewPos(1, 9, 8, "<test>", "for var i = 1; i < 5; i++ 1;"),
-							PosEnd:   util.NewPos(1, 10, 9, "<test>", "for var i = 1; i < 5; i++ 1;"),
+							PosStart: util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+							PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 						},
 						Value: &ast.IntExpression{
 							Value:    1,
-							PosStart: util.NewPos(1, 13, 12, "<test>", "for var i = 1; i < 5; i++ 1;"),
-							PosEnd:   util.NewPos(1, 14, 13, "<test>", "for var i = 1; i < 5; i++ 1;"),
+							PosStart: util.NewPos(1, 13, 12, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+							PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 						},
-						PosStart: util.NewPos(1, 5, 4, "<test>", "for var i = 1; i < 5; i++ 1;"),
-						PosEnd:   util.NewPos(1, 14, 13, "<test>", "for var i = 1; i < 5; i++ 1;"),
+						PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+						PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 					},
-					PosStart: util.NewPos(1, 5, 4, "<test>", "for var i = 1; i < 5; i++ 1;"),
-					PosEnd:   util.NewPos(1, 14, 13, "<test>", "for var i = 1; i < 5; i++ 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+					PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 				},
 				Condition: &ast.InfixExpression{
 					Left: &ast.IdentifierExpression{
 						Name:     "i",
-						PosStart: util.NewPos(1, 16, 15, "<test>", "for var i = 1; i < 5; i++ 1;"),
-						PosEnd:   util.NewPos(1, 17, 16, "<test>", "for var i = 1; i < 5; i++ 1;"),
+						PosStart: util.NewPos(1, 16, 15, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+						PosEnd:   util.NewPos(1, 17, 16, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 					},
 					Operator: &lexer.Token{
 						Type:     lexer.LT,
 						Literal:  "<",
-						PosStart: util.NewPos(1, 18, 17, "<test>", "for var i = 1; i < 5; i++ 1;"),
-						PosEnd:   util.NewPos(1, 19, 18, "<test>", "for var i = 1; i < 5; i++ 1;"),
+						PosStart: util.NewPos(1, 18, 17, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+						PosEnd:   util.NewPos(1, 19, 18, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 					},
 					Right: &ast.IntExpression{
 						Value:    5,
-						PosStart: util.NewPos(1, 20, 19, "<test>", "for var i = 1; i < 5; i++ 1;"),
-						PosEnd:   util.NewPos(1, 21, 20, "<test>", "for var i = 1; i < 5; i++ 1;"),
+						PosStart: util.NewPos(1, 20, 19, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+						PosEnd:   util.NewPos(1, 21, 20, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 					},
-					PosStart: util.NewPos(1, 16, 15, "<test>", "for var i = 1; i < 5; i++ 1;"),
-					PosEnd:   util.NewPos(1, 21, 20, "<test>", "for var i = 1; i < 5; i++ 1;"),
+					PosStart: util.NewPos(1, 16, 15, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+					PosEnd:   util.NewPos(1, 21, 20, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 				},
 				Update: &ast.ExpressionStatement{
 					Expr: &ast.PostfixUnaryIncDecExpression{
 						Operator: &lexer.Token{
 							Type:     lexer.INCREMENT,
 							Literal:  "++",
-							PosStart: util.NewPos(1, 24, 23, "<test>", "for var i = 1; i < 5; i++ 1;"),
-							PosEnd:   util.NewPos(1, 26, 25, "<test>", "for var i = 1; i < 5; i++ 1;"),
+							PosStart: util.NewPos(1, 24, 23, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+							PosEnd:   util.NewPos(1, 26, 25, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 						},
 						Left: &ast.IdentifierExpression{
 							Name:     "i",
-							PosStart: util.NewPos(1, 23, 22, "<test>", "for var i = 1; i < 5; i++ 1;"),
-							PosEnd:   util.NewPos(1, 24, 23, "<test>", "for var i = 1; i < 5; i++ 1;"),
+							PosStart: util.NewPos(1, 23, 22, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+							PosEnd:   util.NewPos(1, 24, 23, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 						},
-						PosStart: util.NewPos(1, 23, 22, "<test>", "for var i = 1; i < 5; i++ 1;"),
-						PosEnd:   util.NewPos(1, 26, 25, "<test>", "for var i = 1; i < 5; i++ 1;"),
+						PosStart: util.NewPos(1, 23, 22, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+						PosEnd:   util.NewPos(1, 26, 25, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 					},
-					PosStart: util.NewPos(1, 23, 22, "<test>", "for var i = 1; i < 5; i++ 1;"),
-					PosEnd:   util.NewPos(1, 26, 25, "<test>", "for var i = 1; i < 5; i++ 1;"),
+					PosStart: util.NewPos(1, 23, 22, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+					PosEnd:   util.NewPos(1, 26, 25, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 				},
 				Body: &ast.ExpressionStatement{
 					Expr: &ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 27, 26, "<test>", "for var i = 1; i < 5; i++ 1;"),
-						PosEnd:   util.NewPos(1, 28, 27, "<test>", "for var i = 1; i < 5; i++ 1;"),
+						PosStart: util.NewPos(1, 27, 26, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+						PosEnd:   util.NewPos(1, 28, 27, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 					},
-					PosStart: util.NewPos(1, 27, 26, "<test>", "for var i = 1; i < 5; i++ 1;"),
-					PosEnd:   util.NewPos(1, 28, 27, "<test>", "for var i = 1; i < 5; i++ 1;"),
+					PosStart: util.NewPos(1, 27, 26, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+					PosEnd:   util.NewPos(1, 28, 27, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "for var i = 1; i < 5; i++ 1;"),
-				PosEnd:   util.NewPos(1, 28, 27, "<test>", "for var i = 1; i < 5; i++ 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
+				PosEnd:   util.NewPos(1, 28, 27, util.NewSourceFile("<test>", "for var i = 1; i < 5; i++ 1;")),
 			},
 		},
 	}
@@ -227,6 +230,345 @@ func TestParser_ParseForStatement(t *testing.T) {
 	}
 }
 
+func TestParser_ForUpdateClause(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		update   string
+		body     string
+		excepted int
+	}{
+		{
+			name:     "Compound Assignment",
+			input:    "for var i = 0; i < 10; i += 2 println(i); println(0);",
+			update:   "i += 2",
+			body:     "println(i)",
+			excepted: 2,
+		},
+		{
+			name:     "Assignment",
+			input:    "for var i = 1; i < 10; i = i * 2 { println(i); }; println(0);",
+			update:   "i = i * 2",
+			body:     "{\n    println(i)\n}",
+			excepted: 2,
+		},
+		{
+			name:     "Function Call",
+			input:    "for var i = 0; i < 3; update() println(i); println(0);",
+			update:   "update()",
+			body:     "println(i)",
+			excepted: 2,
+		},
+		{
+			name:     "Function Call With Block Body",
+			input:    "for var i = 0; i < 3; update() { i += 1; };",
+			update:   "update()",
+			body:     "{\n    i += 1\n}",
+			excepted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			if len(program.Statements) != tt.excepted {
+				t.Fatalf("excepted %d statements, got %d", tt.excepted, len(program.Statements))
+			}
+			fs := program.Statements[0].(*ast.ForStatement)
+			if fs.Update.String() != tt.update {
+				t.Errorf("excepted update %q, got %q", tt.update, fs.Update.String())
+			}
+			if fs.Body.String() != tt.body {
+				t.Errorf("excepted body %q, got %q", tt.body, fs.Body.String())
+			}
+		})
+	}
+}
+
+func TestParser_ParseWhileStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		condition string
+		body      string
+		excepted  int
+	}{
+		{
+			name:      "Block Body",
+			input:     "while i < 10 { i += 1; }; println(i);",
+			condition: "i < 10",
+			body:      "{\n    i += 1\n}",
+			excepted:  2,
+		},
+		{
+			name:      "Statement Body",
+			input:     "while !done() step(); println(0);",
+			condition: "!done()",
+			body:      "step()",
+			excepted:  2,
+		},
+		{
+			name:      "Nested While",
+			input:     "while a while b { b = false; };",
+			condition: "a",
+			body:      "while b {\n    b = false\n}",
+			excepted:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := NewParser(l)
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			if len(program.Statements) != tt.excepted {
+				t.Fatalf("excepted %d statements, got %d", tt.excepted, len(program.Statements))
+			}
+			ws := program.Statements[0].(*ast.WhileStatement)
+			if ws.Condition.String() != tt.condition {
+				t.Errorf("excepted condition %q, got %q", tt.condition, ws.Condition.String())
+			}
+			if ws.Body.String() != tt.body {
+				t.Errorf("excepted body %q, got %q", tt.body, ws.Body.String())
+			}
+			if ws.PosStart.Col != 1 || ws.PosEnd == nil {
+				t.Errorf("excepted the statement to start at column 1, got %+v-%+v", ws.PosStart, ws.PosEnd)
+			}
+		})
+	}
+}
+
+func TestParser_ParseForInStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		variable string
+		value    string
+		iterable string
+		body     string
+		excepted int
+	}{
+		{
+			name:     "Block Body",
+			input:    "for x in [1, 2] { println(x); }; println(0);",
+			variable: "x",
+			iterable: "[1, 2]",
+			body:     "{\n    println(x)\n}",
+			excepted: 2,
+		},
+		{
+			name:     "Statement Body",
+			input:    "for ch in \"ab\" println(ch);",
+			variable: "ch",
+			iterable: "\"ab\"",
+			body:     "println(ch)",
+			excepted: 1,
+		},
+		{
+			name:     "Expression Iterable",
+			input:    "for x in xs[1:] + ys s += x;",
+			variable: "x",
+			iterable: "xs[1:] + ys",
+			body:     "s += x",
+			excepted: 1,
+		},
+		{
+			name:     "Nested For",
+			input:    "for row in m for x in row println(x);",
+			variable: "row",
+			iterable: "m",
+			body:     "for x in row println(x)",
+			excepted: 1,
+		},
+		{
+			name:     "Var Keyword",
+			input:    "for var x in xs println(x);",
+			variable: "x",
+			iterable: "xs",
+			body:     "println(x)",
+			excepted: 1,
+		},
+		{
+			name:     "Key And Value",
+			input:    "for k, v in m println(k, v);",
+			variable: "k",
+			value:    "v",
+			iterable: "m",
+			body:     "println(k, v)",
+			excepted: 1,
+		},
+		{
+			name:     "Var Key And Value",
+			input:    "for var k, v in {1: 2} {}; println(0);",
+			variable: "k",
+			value:    "v",
+			iterable: "{1: 2}",
+			body:     "{\n    \n}",
+			excepted: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			if len(program.Statements) != tt.excepted {
+				t.Fatalf("excepted %d statements, got %d", tt.excepted, len(program.Statements))
+			}
+			fs, ok := program.Statements[0].(*ast.ForInStatement)
+			if !ok {
+				t.Fatalf("excepted *ast.ForInStatement, got %s", ast.Dump(program.Statements[0]))
+			}
+			var value string
+			if fs.ValueVariable != nil {
+				value = fs.ValueVariable.Name
+			}
+			got := []string{fs.Variable.Name, value, fs.Iterable.String(), fs.Body.String()}
+			excepted := []string{tt.variable, tt.value, tt.iterable, tt.body}
+			if !reflect.DeepEqual(got, excepted) {
+				t.Errorf("excepted %q, got %q", excepted, got)
+			}
+			if fs.PosStart.Col != 1 || fs.PosEnd == nil {
+				t.Errorf("excepted the statement to start at column 1, got %+v-%+v", fs.PosStart, fs.PosEnd)
+			}
+		})
+	}
+}
+
+func TestParser_ParseBreakStatement(t *testing.T) {
+	source := util.NewSourceFile("<test>", "while true { break; };")
+	p, _ := NewParser(lexer.NewLexer("<test>", source.Text))
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("err = %+v, expected nil", p.Err)
+	}
+	body := program.Statements[0].(*ast.WhileStatement).Body.(*ast.ExpressionStatement).Expr.(*ast.BlockExpression)
+	excepted := &ast.BreakStatement{
+		PosStart: util.NewPos(1, 14, 13, source),
+		PosEnd:   util.NewPos(1, 19, 18, source),
+	}
+	if !reflect.DeepEqual(body.Statements[0], excepted) {
+		t.Errorf("excepted %s, got %s", ast.Dump(excepted), ast.Dump(body.Statements[0]))
+	}
+}
+
+func TestParser_ParseContinueStatement(t *testing.T) {
+	source := util.NewSourceFile("<test>", "for var i = 0; i < 3; i++ continue;")
+	p, _ := NewParser(lexer.NewLexer("<test>", source.Text))
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("err = %+v, expected nil", p.Err)
+	}
+	excepted := &ast.ContinueStatement{
+		PosStart: util.NewPos(1, 27, 26, source),
+		PosEnd:   util.NewPos(1, 35, 34, source),
+	}
+	if got := program.Statements[0].(*ast.ForStatement).Body; !reflect.DeepEqual(got, excepted) {
+		t.Errorf("excepted %s, got %s", ast.Dump(excepted), ast.Dump(got))
+	}
+}
+
+func TestParser_ParseTryStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		body     string
+		variable string
+		handler  string
+		excepted int
+	}{
+		{
+			name:     "Block Bodies",
+			input:    "try { f(); } catch (e) { println(e); }; g();",
+			body:     "{\n    f()\n}",
+			variable: "e",
+			handler:  "{\n    println(e)\n}",
+			excepted: 2,
+		},
+		{
+			name:     "Statement Bodies",
+			input:    "try x = l[i] catch (err) x = null;",
+			body:     "x = l[i]",
+			variable: "err",
+			handler:  "x = null",
+			excepted: 1,
+		},
+		{
+			name:     "Return In Body",
+			input:    "try return f() catch (e) return 0;",
+			body:     "return f()",
+			variable: "e",
+			handler:  "return 0",
+			excepted: 1,
+		},
+		{
+			name:     "Nested Try",
+			input:    "try try f() catch (a) throw a catch (b) g();",
+			body:     "try f() catch (a) throw a",
+			variable: "b",
+			handler:  "g()",
+			excepted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			if len(program.Statements) != tt.excepted {
+				t.Fatalf("excepted %d statements, got %d", tt.excepted, len(program.Statements))
+			}
+			ts, ok := program.Statements[0].(*ast.TryStatement)
+			if !ok {
+				t.Fatalf("excepted *ast.TryStatement, got %s", ast.Dump(program.Statements[0]))
+			}
+			got := []string{ts.Body.String(), ts.Catch.Variable.Name, ts.Catch.Body.String()}
+			excepted := []string{tt.body, tt.variable, tt.handler}
+			if !reflect.DeepEqual(got, excepted) {
+				t.Errorf("excepted %q, got %q", excepted, got)
+			}
+			if ts.PosStart.Col != 1 || ts.Catch.PosEnd != ts.PosEnd {
+				t.Errorf("excepted the statement to start at column 1 and end with the catch clause, got %+v-%+v", ts.PosStart, ts.PosEnd)
+			}
+		})
+	}
+}
+
+func TestParser_ParseThrowStatement(t *testing.T) {
+	source := util.NewSourceFile("<test>", "throw \"bad\";")
+	p, _ := NewParser(lexer.NewLexer("<test>", source.Text))
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("err = %+v, expected nil", p.Err)
+	}
+	excepted := &ast.ThrowStatement{
+		Value: &ast.StringExpression{
+			Value:    "bad",
+			PosStart: util.NewPos(1, 7, 6, source),
+			PosEnd:   util.NewPos(1, 12, 11, source),
+		},
+		PosStart: util.NewPos(1, 1, 0, source),
+		PosEnd:   util.NewPos(1, 12, 11, source),
+	}
+	if got := program.Statements[0]; !reflect.DeepEqual(got, excepted) {
+		t.Errorf("excepted %s, got %s", ast.Dump(excepted), ast.Dump(got))
+	}
+}
+
 func TestParser_ParseFunctionDeclarationStatement(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -239,32 +581,32 @@ func TestParser_ParseFunctionDeclarationStatement(t *testing.T) {
 			expected: &ast.FunctionDeclarationStatement{
 				Name: &ast.IdentifierExpression{
 					Name:     "f",
-					PosStart: util.NewPos(1, 6, 5, "<test>", "func f(a) 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "func f(a) 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "func f(a) 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "func f(a) 1;")),
 				},
 				Parameter: []*ast.Parameter{
 					{
 						Name: &ast.IdentifierExpression{
 							Name:     "a",
-							PosStart: util.NewPos(1, 8, 7, "<test>", "func f(a) 1;"),
-							PosEnd:   util.NewPos(1, 9, 8, "<test>", "func f(a) 1;"),
+							PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "func f(a) 1;")),
+							PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "func f(a) 1;")),
 						},
 						DefaultValue: nil,
-						PosStart:     util.NewPos(1, 8, 7, "<test>", "func f(a) 1;"),
-						PosEnd:       util.NewPos(1, 9, 8, "<test>", "func f(a) 1;"),
+						PosStart:     util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "func f(a) 1;")),
+						PosEnd:       util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "func f(a) 1;")),
 					},
 				},
 				Body: &ast.ExpressionStatement{
 					Expr: &ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 11, 10, "<test>", "func f(a) 1;"),
-						PosEnd:   util.NewPos(1, 12, 11, "<test>", "func f(a) 1;"),
+						PosStart: util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func f(a) 1;")),
+						PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "func f(a) 1;")),
 					},
-					PosStart: util.NewPos(1, 11, 10, "<test>", "func f(a) 1;"),
-					PosEnd:   util.NewPos(1, 12, 11, "<test>", "func f(a) 1;"),
+					PosStart: util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func f(a) 1;")),
+					PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "func f(a) 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "func f(a) 1;"),
-				PosEnd:   util.NewPos(1, 12, 11, "<test>", "func f(a) 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "func f(a) 1;")),
+				PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "func f(a) 1;")),
 			},
 		},
 		{
@@ -273,36 +615,36 @@ func TestParser_ParseFunctionDeclarationStatement(t *testing.T) {
 			expected: &ast.FunctionDeclarationStatement{
 				Name: &ast.IdentifierExpression{
 					Name:     "f",
-					PosStart: util.NewPos(1, 6, 5, "<test>", "func f(a=1) 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "func f(a=1) 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "func f(a=1) 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "func f(a=1) 1;")),
 				},
 				Parameter: []*ast.Parameter{
 					{
 						Name: &ast.IdentifierExpression{
 							Name:     "a",
-							PosStart: util.NewPos(1, 8, 7, "<test>", "func f(a=1) 1;"),
-							PosEnd:   util.NewPos(1, 9, 8, "<test>", "func f(a=1) 1;"),
+							PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "func f(a=1) 1;")),
+							PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "func f(a=1) 1;")),
 						},
 						DefaultValue: &ast.IntExpression{
 							Value:    1,
-							PosStart: util.NewPos(1, 10, 9, "<test>", "func f(a=1) 1;"),
-							PosEnd:   util.NewPos(1, 11, 10, "<test>", "func f(a=1) 1;"),
+							PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "func f(a=1) 1;")),
+							PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func f(a=1) 1;")),
 						},
-						PosStart: util.NewPos(1, 8, 7, "<test>", "func f(a=1) 1;"),
-						PosEnd:   util.NewPos(1, 11, 10, "<test>", "func f(a=1) 1;"),
+						PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "func f(a=1) 1;")),
+						PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func f(a=1) 1;")),
 					},
 				},
 				Body: &ast.ExpressionStatement{
 					Expr: &ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 13, 12, "<test>", "func f(a=1) 1;"),
-						PosEnd:   util.NewPos(1, 14, 13, "<test>", "func f(a=1) 1;"),
+						PosStart: util.NewPos(1, 13, 12, util.NewSourceFile("<test>", "func f(a=1) 1;")),
+						PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "func f(a=1) 1;")),
 					},
-					PosStart: util.NewPos(1, 13, 12, "<test>", "func f(a=1) 1;"),
-					PosEnd:   util.NewPos(1, 14, 13, "<test>", "func f(a=1) 1;"),
+					PosStart: util.NewPos(1, 13, 12, util.NewSourceFile("<test>", "func f(a=1) 1;")),
+					PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "func f(a=1) 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "func f(a=1) 1;"),
-				PosEnd:   util.NewPos(1, 14, 13, "<test>", "func f(a=1) 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "func f(a=1) 1;")),
+				PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "func f(a=1) 1;")),
 			},
 		},
 	}
@@ -324,6 +666,78 @@ func TestParser_ParseFunctionDeclarationStatement(t *testing.T) {
 	}
 }
 
+func TestParser_DuplicateParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		pos      string
+	}{
+		{
+			name:     "First And Third",
+			input:    "func f(a, b, a) a;",
+			excepted: `duplicate parameter "a", first declared at line 1, column 8.`,
+			pos:      "1:14",
+		},
+		{
+			name:     "Adjacent",
+			input:    "func f(x, x) {};",
+			excepted: `duplicate parameter "x", first declared at line 1, column 8.`,
+			pos:      "1:11",
+		},
+		{
+			name:     "Default Values",
+			input:    "func f(a, b = 1,\n       b = 2) b;",
+			excepted: `duplicate parameter "b", first declared at line 1, column 11.`,
+			pos:      "2:8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(lexer.NewLexer("<test>", tt.input))
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			p.ParseProgram()
+			syntaxErr, ok := p.Err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("err = %+v, expected *SyntaxError", p.Err)
+			}
+			if syntaxErr.Code != errcode.DuplicateParameter {
+				t.Errorf("excepted code %s, got %s", errcode.DuplicateParameter, syntaxErr.Code)
+			}
+			if syntaxErr.Message != tt.excepted {
+				t.Errorf("excepted message %q, got %q", tt.excepted, syntaxErr.Message)
+			}
+			if pos := fmt.Sprintf("%d:%d", syntaxErr.PosStart.Row, syntaxErr.PosStart.Col); pos != tt.pos {
+				t.Errorf("excepted position %s, got %s", tt.pos, pos)
+			}
+		})
+	}
+}
+
+func TestParser_DuplicateLoopVariable(t *testing.T) {
+	p, err := NewParser(lexer.NewLexer("<test>", "for var k, k in m {};"))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	p.ParseProgram()
+	syntaxErr, ok := p.Err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("err = %+v, expected *SyntaxError", p.Err)
+	}
+	if syntaxErr.Code != errcode.DuplicateVariable {
+		t.Errorf("excepted code %s, got %s", errcode.DuplicateVariable, syntaxErr.Code)
+	}
+	if excepted := `duplicate loop variable "k", first declared at line 1, column 9.`; syntaxErr.Message != excepted {
+		t.Errorf("excepted message %q, got %q", excepted, syntaxErr.Message)
+	}
+	if pos := fmt.Sprintf("%d:%d", syntaxErr.PosStart.Row, syntaxErr.PosStart.Col); pos != "1:12" {
+		t.Errorf("excepted position 1:12, got %s", pos)
+	}
+}
+
 func TestParser_ParseReturnStatement(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -336,11 +750,11 @@ func TestParser_ParseReturnStatement(t *testing.T) {
 			expected: &ast.ReturnStatement{
 				ReturnValue: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 8, 7, "<test>", "return 1;"),
-					PosEnd:   util.NewPos(1, 9, 8, "<test>", "return 1;"),
+					PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "return 1;")),
+					PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "return 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "return 1;"),
-				PosEnd:   util.NewPos(1, 9, 8, "<test>", "return 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "return 1;")),
+				PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "return 1;")),
 			},
 		},
 	}
@@ -363,6 +777,58 @@ func TestParser_ParseReturnStatement(t *testing.T) {
 	}
 }
 
+func TestParser_ParseImportStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *ast.ImportStatement
+	}{
+		{
+			name:  "Import Statement",
+			input: `import "a.gh";`,
+			expected: &ast.ImportStatement{
+				Path: &ast.StringExpression{
+					Value:    "a.gh",
+					PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", `import "a.gh";`)),
+					PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", `import "a.gh";`)),
+				},
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", `import "a.gh";`)),
+				PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", `import "a.gh";`)),
+			},
+		},
+		{
+			name:  "Import By Name",
+			input: `import strings;`,
+			expected: &ast.ImportStatement{
+				Name: &ast.IdentifierExpression{
+					Name:     "strings",
+					PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", `import strings;`)),
+					PosEnd:   util.NewPos(1, 15, 14, util.NewSourceFile("<test>", `import strings;`)),
+				},
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", `import strings;`)),
+				PosEnd:   util.NewPos(1, 15, 14, util.NewSourceFile("<test>", `import strings;`)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.NewLexer("<test>", tt.input)
+			p, _ := NewParser(l)
+			program := p.ParseProgram()
+			expr := program.Statements[0].(*ast.ImportStatement)
+
+			if p.Err != nil {
+				t.Errorf("err = %+v, expected nil", p.Err)
+			}
+
+			if !reflect.DeepEqual(expr, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, expr)
+			}
+		})
+	}
+}
+
 func TestParser_ParsePrefixExpression(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -376,16 +842,16 @@ func TestParser_ParsePrefixExpression(t *testing.T) {
 				Operator: &lexer.Token{
 					Type:     lexer.MINUS,
 					Literal:  "-",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "-1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "-1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "-1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "-1;")),
 				},
 				Value: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 2, 1, "<test>", "-1;"),
-					PosEnd:   util.NewPos(1, 3, 2, "<test>", "-1;"),
+					PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "-1;")),
+					PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "-1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "-1;"),
-				PosEnd:   util.NewPos(1, 3, 2, "<test>", "-1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "-1;")),
+				PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "-1;")),
 			},
 		},
 		{
@@ -395,16 +861,16 @@ func TestParser_ParsePrefixExpression(t *testing.T) {
 				Operator: &lexer.Token{
 					Type:     lexer.BANG,
 					Literal:  "!",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "!true;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "!true;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "!true;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "!true;")),
 				},
 				Value: &ast.BoolExpression{
 					Value:    true,
-					PosStart: util.NewPos(1, 2, 1, "<test>", "!true;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "!true;"),
+					PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "!true;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "!true;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "!true;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "!true;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "!true;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "!true;")),
 			},
 		},
 		{
@@ -414,16 +880,16 @@ func TestParser_ParsePrefixExpression(t *testing.T) {
 				Operator: &lexer.Token{
 					Type:     lexer.BITWISE_NOT,
 					Literal:  "~",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "~1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "~1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "~1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "~1;")),
 				},
 				Value: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 2, 1, "<test>", "~1;"),
-					PosEnd:   util.NewPos(1, 3, 2, "<test>", "~1;"),
+					PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "~1;")),
+					PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "~1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "~1;"),
-				PosEnd:   util.NewPos(1, 3, 2, "<test>", "~1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "~1;")),
+				PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "~1;")),
 			},
 		},
 	}
@@ -458,22 +924,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 + 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 + 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 + 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 + 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.PLUS,
 					Literal:  "+",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 + 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 + 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 + 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 + 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 + 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 + 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 + 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 + 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 + 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 + 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 + 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 + 1;")),
 			},
 		},
 		{
@@ -482,22 +948,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 - 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 - 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 - 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 - 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.MINUS,
 					Literal:  "-",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 - 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 - 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 - 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 - 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 - 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 - 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 - 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 - 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 - 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 - 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 - 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 - 1;")),
 			},
 		},
 		{
@@ -506,22 +972,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 * 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 * 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 * 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 * 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.ASTERISK,
 					Literal:  "*",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 * 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 * 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 * 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 * 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 * 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 * 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 * 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 * 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 * 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 * 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 * 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 * 1;")),
 			},
 		},
 		{
@@ -530,22 +996,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 / 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 / 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 / 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 / 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.SLASH,
 					Literal:  "/",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 / 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 / 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 / 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 / 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 / 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 / 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 / 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 / 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 / 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 / 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 / 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 / 1;")),
 			},
 		},
 		{
@@ -554,22 +1020,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 % 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 % 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 % 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 % 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.PERCENT,
 					Literal:  "%",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 % 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 % 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 % 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 % 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 % 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 % 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 % 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 % 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 % 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 % 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 % 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 % 1;")),
 			},
 		},
 		{
@@ -578,22 +1044,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 == 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 == 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 == 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 == 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.EQUALS,
 					Literal:  "==",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 == 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "1 == 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 == 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 == 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "1 == 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 == 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 == 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 == 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 == 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 == 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 == 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 == 1;")),
 			},
 		},
 		{
@@ -602,22 +1068,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 != 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 != 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 != 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 != 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.NOT_EQUALS,
 					Literal:  "!=",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 != 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "1 != 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 != 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 != 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "1 != 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 != 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 != 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 != 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 != 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 != 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 != 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 != 1;")),
 			},
 		},
 		{
@@ -626,22 +1092,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 > 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 > 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 > 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 > 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.GT,
 					Literal:  ">",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 > 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 > 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 > 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 > 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 > 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 > 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 > 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 > 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 > 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 > 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 > 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 > 1;")),
 			},
 		},
 		{
@@ -650,22 +1116,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 >= 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 >= 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 >= 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 >= 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.GTE,
 					Literal:  ">=",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 >= 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "1 >= 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 >= 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 >= 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "1 >= 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 >= 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 >= 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 >= 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 >= 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 >= 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 >= 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 >= 1;")),
 			},
 		},
 		{
@@ -674,22 +1140,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 < 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 < 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 < 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 < 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.LT,
 					Literal:  "<",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 < 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 < 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 < 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 < 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 < 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 < 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 < 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 < 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 < 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 < 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 < 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 < 1;")),
 			},
 		},
 		{
@@ -698,22 +1164,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 <= 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 <= 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 <= 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 <= 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.LTE,
 					Literal:  "<=",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 <= 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "1 <= 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 <= 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 <= 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "1 <= 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 <= 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 <= 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 <= 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 <= 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 <= 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 <= 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 <= 1;")),
 			},
 		},
 		{
@@ -722,22 +1188,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 << 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 << 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 << 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 << 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.LEFT_SHIFT,
 					Literal:  "<<",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 << 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "1 << 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 << 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 << 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "1 << 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 << 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 << 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 << 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 << 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 << 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 << 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 << 1;")),
 			},
 		},
 		{
@@ -746,22 +1212,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 >> 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 >> 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 >> 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 >> 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.RIGHT_SHIFT,
 					Literal:  ">>",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 >> 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "1 >> 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 >> 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 >> 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "1 >> 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 >> 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 >> 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 >> 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 >> 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "1 >> 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 >> 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "1 >> 1;")),
 			},
 		},
 		{
@@ -770,22 +1236,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 & 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 & 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 & 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 & 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.BITWISE_AND,
 					Literal:  "&",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 & 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 & 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 & 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 & 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 & 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 & 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 & 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 & 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 & 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 & 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 & 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 & 1;")),
 			},
 		},
 		{
@@ -794,22 +1260,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 | 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 | 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 | 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 | 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.BITWISE_OR,
 					Literal:  "|",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 | 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 | 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 | 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 | 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 | 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 | 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 | 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 | 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 | 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 | 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 | 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 | 1;")),
 			},
 		},
 		{
@@ -818,22 +1284,22 @@ func TestParser_ParseInfixExpression(t *testing.T) {
 			expected: &ast.InfixExpression{
 				Left: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 1, 0, "<test>", "1 ^ 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "1 ^ 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 ^ 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1 ^ 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.BITWISE_XOR,
 					Literal:  "^",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "1 ^ 1;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "1 ^ 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "1 ^ 1;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1 ^ 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "1 ^ 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 ^ 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 ^ 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 ^ 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1 ^ 1;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "1 ^ 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1 ^ 1;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 ^ 1;")),
 			},
 		},
 	}
@@ -867,8 +1333,8 @@ func TestParser_ParseIntegerExpression(t *testing.T) {
 			input: "1;",
 			expected: &ast.IntExpression{
 				Value:    1,
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1;"),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", "1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1;")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "1;")),
 			},
 		},
 		{
@@ -876,8 +1342,8 @@ func TestParser_ParseIntegerExpression(t *testing.T) {
 			input: "123;",
 			expected: &ast.IntExpression{
 				Value:    123,
-				PosStart: util.NewPos(1, 1, 0, "<test>", "123;"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "123;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "123;")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "123;")),
 			},
 		},
 	}
@@ -910,8 +1376,8 @@ func TestParser_ParseFloatExpression(t *testing.T) {
 			input: "1.0;",
 			expected: &ast.FloatExpression{
 				Value:    1.0,
-				PosStart: util.NewPos(1, 1, 0, "<test>", "1.0;"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "1.0;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "1.0;")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "1.0;")),
 			},
 		},
 		{
@@ -919,8 +1385,8 @@ func TestParser_ParseFloatExpression(t *testing.T) {
 			input: "0.75;",
 			expected: &ast.FloatExpression{
 				Value:    0.75,
-				PosStart: util.NewPos(1, 1, 0, "<test>", "0.75;"),
-				PosEnd:   util.NewPos(1, 5, 4, "<test>", "0.75;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "0.75;")),
+				PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "0.75;")),
 			},
 		},
 	}
@@ -954,8 +1420,8 @@ func TestParser_ParseIdentifierExpression(t *testing.T) {
 			input: "x;",
 			expected: &ast.IdentifierExpression{
 				Name:     "x",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "x;"),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", "x;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "x;")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "x;")),
 			},
 		},
 		{
@@ -963,8 +1429,8 @@ func TestParser_ParseIdentifierExpression(t *testing.T) {
 			input: "真;",
 			expected: &ast.IdentifierExpression{
 				Name:     "真",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "真;"),
-				PosEnd:   util.NewPos(1, 2, 3, "<test>", "真;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "真;")),
+				PosEnd:   util.NewPos(1, 2, 3, util.NewSourceFile("<test>", "真;")),
 			},
 		},
 		{
@@ -972,8 +1438,8 @@ func TestParser_ParseIdentifierExpression(t *testing.T) {
 			input: "你好;",
 			expected: &ast.IdentifierExpression{
 				Name:     "你好",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "你好;"),
-				PosEnd:   util.NewPos(1, 3, 6, "<test>", "你好;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "你好;")),
+				PosEnd:   util.NewPos(1, 3, 6, util.NewSourceFile("<test>", "你好;")),
 			},
 		},
 	}
@@ -1007,8 +1473,8 @@ func TestParser_ParseBooleanExpression(t *testing.T) {
 			input: "true;",
 			expected: &ast.BoolExpression{
 				Value:    true,
-				PosStart: util.NewPos(1, 1, 0, "<test>", "true;"),
-				PosEnd:   util.NewPos(1, 5, 4, "<test>", "true;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "true;")),
+				PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "true;")),
 			},
 		},
 		{
@@ -1016,8 +1482,8 @@ func TestParser_ParseBooleanExpression(t *testing.T) {
 			input: "false;",
 			expected: &ast.BoolExpression{
 				Value:    false,
-				PosStart: util.NewPos(1, 1, 0, "<test>", "false;"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "false;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "false;")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "false;")),
 			},
 		},
 	}
@@ -1050,8 +1516,8 @@ func TestParser_ParseNullExpression(t *testing.T) {
 			name:  "Null Expression",
 			input: "null;",
 			expected: &ast.NullExpression{
-				PosStart: util.NewPos(1, 1, 0, "<test>", "null;"),
-				PosEnd:   util.NewPos(1, 5, 4, "<test>", "null;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "null;")),
+				PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "null;")),
 			},
 		},
 	}
@@ -1085,8 +1551,8 @@ func TestParser_ParseStringExpression(t *testing.T) {
 			input: "\"hello\";",
 			expected: &ast.StringExpression{
 				Value:    "hello",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"hello\";"),
-				PosEnd:   util.NewPos(1, 8, 7, "<test>", "\"hello\";"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"hello\";")),
+				PosEnd:   util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "\"hello\";")),
 			},
 		},
 		{
@@ -1094,8 +1560,8 @@ func TestParser_ParseStringExpression(t *testing.T) {
 			input: "\"hello\nworld\";",
 			expected: &ast.StringExpression{
 				Value:    "hello\nworld",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"hello\nworld\";"),
-				PosEnd:   util.NewPos(2, 7, 13, "<test>", "\"hello\nworld\";"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"hello\nworld\";")),
+				PosEnd:   util.NewPos(2, 7, 13, util.NewSourceFile("<test>", "\"hello\nworld\";")),
 			},
 		},
 		{
@@ -1103,8 +1569,8 @@ func TestParser_ParseStringExpression(t *testing.T) {
 			input: "\"hello\\nworld\\t\";",
 			expected: &ast.StringExpression{
 				Value:    "hello\nworld\t",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "\"hello\\nworld\\t\";"),
-				PosEnd:   util.NewPos(1, 17, 16, "<test>", "\"hello\\nworld\\t\";"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "\"hello\\nworld\\t\";")),
+				PosEnd:   util.NewPos(1, 17, 16, util.NewSourceFile("<test>", "\"hello\\nworld\\t\";")),
 			},
 		},
 	}
@@ -1127,6 +1593,48 @@ func TestParser_ParseStringExpression(t *testing.T) {
 	}
 }
 
+func TestParser_ParseInterpolatedStringExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		parts    []string
+		excepted string
+	}{
+		{name: "Single Interpolation", input: `"Hello ${name}!";`, parts: []string{`"Hello "`, "name", `"!"`}, excepted: `"Hello ${name}!"`},
+		{name: "Empty Fragments", input: `"${a}${b}";`, parts: []string{`""`, "a", `""`, "b", `""`}, excepted: `"${a}${b}"`},
+		{name: "Expression", input: `"sum: ${a + f(b)}";`, parts: []string{`"sum: "`, "a + f(b)", `""`}, excepted: `"sum: ${a + f(b)}"`},
+		{name: "Nested String", input: `"${f("${x}")}";`, parts: []string{`""`, `f("${x}")`, `""`}, excepted: `"${f("${x}")}"`},
+		{name: "Escaped Fragment", input: `"\${a}\n${b}";`, parts: []string{`"${a}\n"`, "b", `""`}, excepted: `"\${a}\n${b}"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			ise, ok := program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.InterpolatedStringExpression)
+			if !ok {
+				t.Fatalf("excepted *ast.InterpolatedStringExpression, got %s", ast.Dump(program.Statements[0]))
+			}
+			var got []string
+			for _, part := range ise.Parts {
+				got = append(got, part.String())
+			}
+			if !reflect.DeepEqual(got, tt.parts) {
+				t.Errorf("excepted parts %q, got %q", tt.parts, got)
+			}
+			if ise.String() != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, ise.String())
+			}
+			if ise.PosStart.Idx != 0 || ise.PosEnd.Idx != len(tt.input)-1 {
+				t.Errorf("excepted span 0-%d, got %d-%d", len(tt.input)-1, ise.PosStart.Idx, ise.PosEnd.Idx)
+			}
+		})
+	}
+}
+
 func TestParser_ParseGroupedExpression(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1141,28 +1649,28 @@ func TestParser_ParseGroupedExpression(t *testing.T) {
 					Expr: &ast.InfixExpression{
 						Left: &ast.IntExpression{
 							Value:    1,
-							PosStart: util.NewPos(1, 2, 1, "<test>", "(1 + 2);"),
-							PosEnd:   util.NewPos(1, 3, 2, "<test>", "(1 + 2);"),
+							PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "(1 + 2);")),
+							PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "(1 + 2);")),
 						},
 						Operator: &lexer.Token{
 							Type:     lexer.PLUS,
 							Literal:  "+",
-							PosStart: util.NewPos(1, 4, 3, "<test>", "(1 + 2);"),
-							PosEnd:   util.NewPos(1, 5, 4, "<test>", "(1 + 2);"),
+							PosStart: util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "(1 + 2);")),
+							PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "(1 + 2);")),
 						},
 						Right: &ast.IntExpression{
 							Value:    2,
-							PosStart: util.NewPos(1, 6, 5, "<test>", "(1 + 2);"),
-							PosEnd:   util.NewPos(1, 7, 6, "<test>", "(1 + 2);"),
+							PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "(1 + 2);")),
+							PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "(1 + 2);")),
 						},
-						PosStart: util.NewPos(1, 2, 1, "<test>", "(1 + 2);"),
-						PosEnd:   util.NewPos(1, 7, 6, "<test>", "(1 + 2);"),
+						PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "(1 + 2);")),
+						PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "(1 + 2);")),
 					},
-					PosStart: util.NewPos(1, 1, 0, "<test>", "(1 + 2);"),
-					PosEnd:   util.NewPos(1, 8, 7, "<test>", "(1 + 2);"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "(1 + 2);")),
+					PosEnd:   util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "(1 + 2);")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "(1 + 2);"),
-				PosEnd:   util.NewPos(1, 8, 7, "<test>", "(1 + 2);"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "(1 + 2);")),
+				PosEnd:   util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "(1 + 2);")),
 			},
 		},
 		{
@@ -1174,42 +1682,42 @@ func TestParser_ParseGroupedExpression(t *testing.T) {
 						Expr: &ast.InfixExpression{
 							Left: &ast.IntExpression{
 								Value:    1,
-								PosStart: util.NewPos(1, 2, 1, "<test>", "(1 + 2) * 3;"),
-								PosEnd:   util.NewPos(1, 3, 2, "<test>", "(1 + 2) * 3;"),
+								PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+								PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 							},
 							Operator: &lexer.Token{
 								Type:     lexer.PLUS,
 								Literal:  "+",
-								PosStart: util.NewPos(1, 4, 3, "<test>", "(1 + 2) * 3;"),
-								PosEnd:   util.NewPos(1, 5, 4, "<test>", "(1 + 2) * 3;"),
+								PosStart: util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+								PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 							},
 							Right: &ast.IntExpression{
 								Value:    2,
-								PosStart: util.NewPos(1, 6, 5, "<test>", "(1 + 2) * 3;"),
-								PosEnd:   util.NewPos(1, 7, 6, "<test>", "(1 + 2) * 3;"),
+								PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+								PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 							},
-							PosStart: util.NewPos(1, 2, 1, "<test>", "(1 + 2) * 3;"),
-							PosEnd:   util.NewPos(1, 7, 6, "<test>", "(1 + 2) * 3;"),
+							PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+							PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 						},
-						PosStart: util.NewPos(1, 1, 0, "<test>", "(1 + 2) * 3;"),
-						PosEnd:   util.NewPos(1, 8, 7, "<test>", "(1 + 2) * 3;"),
+						PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+						PosEnd:   util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 					},
 					Operator: &lexer.Token{
 						Type:     lexer.ASTERISK,
 						Literal:  "*",
-						PosStart: util.NewPos(1, 9, 8, "<test>", "(1 + 2) * 3;"),
-						PosEnd:   util.NewPos(1, 10, 9, "<test>", "(1 + 2) * 3;"),
+						PosStart: util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+						PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 					},
 					Right: &ast.IntExpression{
 						Value:    3,
-						PosStart: util.NewPos(1, 11, 10, "<test>", "(1 + 2) * 3;"),
-						PosEnd:   util.NewPos(1, 12, 11, "<test>", "(1 + 2) * 3;"),
+						PosStart: util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+						PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 					},
-					PosStart: util.NewPos(1, 1, 0, "<test>", "(1 + 2) * 3;"),
-					PosEnd:   util.NewPos(1, 12, 11, "<test>", "(1 + 2) * 3;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+					PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "(1 + 2) * 3;"),
-				PosEnd:   util.NewPos(1, 12, 11, "<test>", "(1 + 2) * 3;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
+				PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "(1 + 2) * 3;")),
 			},
 		},
 	}
@@ -1245,16 +1753,16 @@ func TestParser_ParseVarInitializationExpression(t *testing.T) {
 				IsConst: false,
 				Name: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 5, 4, "<test>", "var a = 1;"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "var a = 1;"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "var a = 1;")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "var a = 1;")),
 				},
 				Value: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 9, 8, "<test>", "var a = 1;"),
-					PosEnd:   util.NewPos(1, 10, 9, "<test>", "var a = 1;"),
+					PosStart: util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "var a = 1;")),
+					PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "var a = 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "var a = 1;"),
-				PosEnd:   util.NewPos(1, 10, 9, "<test>", "var a = 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "var a = 1;")),
+				PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "var a = 1;")),
 			},
 		},
 		{
@@ -1264,16 +1772,16 @@ func TestParser_ParseVarInitializationExpression(t *testing.T) {
 				IsConst: true,
 				Name: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 7, 6, "<test>", "const a = 1;"),
-					PosEnd:   util.NewPos(1, 8, 7, "<test>", "const a = 1;"),
+					PosStart: util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "const a = 1;")),
+					PosEnd:   util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "const a = 1;")),
 				},
 				Value: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 11, 10, "<test>", "const a = 1;"),
-					PosEnd:   util.NewPos(1, 12, 11, "<test>", "const a = 1;"),
+					PosStart: util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "const a = 1;")),
+					PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "const a = 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "const a = 1;"),
-				PosEnd:   util.NewPos(1, 12, 11, "<test>", "const a = 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "const a = 1;")),
+				PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "const a = 1;")),
 			},
 		},
 	}
@@ -1308,22 +1816,22 @@ func TestParser_ParseCompoundAssignmentExpression(t *testing.T) {
 			expected: &ast.CompoundAssignmentExpression{
 				Name: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "a += 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "a += 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a += 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "a += 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.PLUS_EQUAL,
 					Literal:  "+=",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "a += 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "a += 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "a += 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "a += 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "a += 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "a += 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "a += 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "a += 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "a += 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "a += 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a += 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "a += 1;")),
 			},
 		},
 		{
@@ -1332,22 +1840,22 @@ func TestParser_ParseCompoundAssignmentExpression(t *testing.T) {
 			expected: &ast.CompoundAssignmentExpression{
 				Name: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "a -= 1;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "a -= 1;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a -= 1;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "a -= 1;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.MINUS_EQUAL,
 					Literal:  "-=",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "a -= 1;"),
-					PosEnd:   util.NewPos(1, 5, 4, "<test>", "a -= 1;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "a -= 1;")),
+					PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "a -= 1;")),
 				},
 				Right: &ast.IntExpression{
 					Value:    1,
-					PosStart: util.NewPos(1, 6, 5, "<test>", "a -= 1;"),
-					PosEnd:   util.NewPos(1, 7, 6, "<test>", "a -= 1;"),
+					PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "a -= 1;")),
+					PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "a -= 1;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "a -= 1;"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "a -= 1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a -= 1;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "a -= 1;")),
 			},
 		},
 	}
@@ -1383,16 +1891,16 @@ func TestParser_ParsePrefixUnaryIncDecExpression(t *testing.T) {
 				Operator: &lexer.Token{
 					Type:     lexer.INCREMENT,
 					Literal:  "++",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "++a;"),
-					PosEnd:   util.NewPos(1, 3, 2, "<test>", "++a;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "++a;")),
+					PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "++a;")),
 				},
 				Right: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "++a;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "++a;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "++a;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "++a;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "++a;"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "++a;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "++a;")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "++a;")),
 			},
 		},
 		{
@@ -1402,16 +1910,16 @@ func TestParser_ParsePrefixUnaryIncDecExpression(t *testing.T) {
 				Operator: &lexer.Token{
 					Type:     lexer.DECREMENT,
 					Literal:  "--",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "--a;"),
-					PosEnd:   util.NewPos(1, 3, 2, "<test>", "--a;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "--a;")),
+					PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "--a;")),
 				},
 				Right: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 3, 2, "<test>", "--a;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "--a;"),
+					PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "--a;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "--a;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "--a;"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "--a;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "--a;")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "--a;")),
 			},
 		},
 	}
@@ -1446,17 +1954,17 @@ func TestParser_ParsePostfixUnaryIncDecExpression(t *testing.T) {
 			expected: &ast.PostfixUnaryIncDecExpression{
 				Left: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "a++;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "a++;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a++;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "a++;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.INCREMENT,
 					Literal:  "++",
-					PosStart: util.NewPos(1, 2, 1, "<test>", "a++;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "a++;"),
+					PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "a++;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "a++;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "a++;"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "a++;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a++;")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "a++;")),
 			},
 		},
 		{
@@ -1465,17 +1973,17 @@ func TestParser_ParsePostfixUnaryIncDecExpression(t *testing.T) {
 			expected: &ast.PostfixUnaryIncDecExpression{
 				Left: &ast.IdentifierExpression{
 					Name:     "a",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "a--;"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "a--;"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a--;")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "a--;")),
 				},
 				Operator: &lexer.Token{
 					Type:     lexer.DECREMENT,
 					Literal:  "--",
-					PosStart: util.NewPos(1, 2, 1, "<test>", "a--;"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "a--;"),
+					PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "a--;")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "a--;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "a--;"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "a--;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "a--;")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "a--;")),
 			},
 		},
 	}
@@ -1512,15 +2020,15 @@ func TestParser_ParseBlockExpression(t *testing.T) {
 					&ast.ExpressionStatement{
 						Expr: &ast.IntExpression{
 							Value:    1,
-							PosStart: util.NewPos(1, 3, 2, "<test>", "{ 1 };"),
-							PosEnd:   util.NewPos(1, 4, 3, "<test>", "{ 1 };"),
+							PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "{ 1 };")),
+							PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "{ 1 };")),
 						},
-						PosStart: util.NewPos(1, 3, 2, "<test>", "{ 1 };"),
-						PosEnd:   util.NewPos(1, 4, 3, "<test>", "{ 1 };"),
+						PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "{ 1 };")),
+						PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "{ 1 };")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "{ 1 };"),
-				PosEnd:   util.NewPos(1, 6, 5, "<test>", "{ 1 };"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "{ 1 };")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "{ 1 };")),
 			},
 		},
 	}
@@ -1555,21 +2063,21 @@ func TestParser_ParseIfExpression(t *testing.T) {
 			expected: &ast.IfExpression{
 				Condition: &ast.BoolExpression{
 					Value:    true,
-					PosStart: util.NewPos(1, 4, 3, "<test>", "if true 1;"),
-					PosEnd:   util.NewPos(1, 8, 7, "<test>", "if true 1;"),
+					PosStart: util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "if true 1;")),
+					PosEnd:   util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "if true 1;")),
 				},
 				Consequence: &ast.ExpressionStatement{
 					Expr: &ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 9, 8, "<test>", "if true 1;"),
-						PosEnd:   util.NewPos(1, 10, 9, "<test>", "if true 1;"),
+						PosStart: util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "if true 1;")),
+						PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "if true 1;")),
 					},
-					PosStart: util.NewPos(1, 9, 8, "<test>", "if true 1;"),
-					PosEnd:   util.NewPos(1, 10, 9, "<test>", "if true 1;"),
+					PosStart: util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "if true 1;")),
+					PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "if true 1;")),
 				},
 				Alternative: nil,
-				PosStart:    util.NewPos(1, 1, 0, "<test>", "if true 1;"),
-				PosEnd:      util.NewPos(1, 10, 9, "<test>", "if true 1;"),
+				PosStart:    util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "if true 1;")),
+				PosEnd:      util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "if true 1;")),
 			},
 		},
 		{
@@ -1578,29 +2086,29 @@ func TestParser_ParseIfExpression(t *testing.T) {
 			expected: &ast.IfExpression{
 				Condition: &ast.BoolExpression{
 					Value:    false,
-					PosStart: util.NewPos(1, 4, 3, "<test>", "if false 1 else 2;"),
-					PosEnd:   util.NewPos(1, 9, 8, "<test>", "if false 1 else 2;"),
+					PosStart: util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "if false 1 else 2;")),
+					PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "if false 1 else 2;")),
 				},
 				Consequence: &ast.ExpressionStatement{
 					Expr: &ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 10, 9, "<test>", "if false 1 else 2;"),
-						PosEnd:   util.NewPos(1, 11, 10, "<test>", "if false 1 else 2;"),
+						PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "if false 1 else 2;")),
+						PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "if false 1 else 2;")),
 					},
-					PosStart: util.NewPos(1, 10, 9, "<test>", "if false 1 else 2;"),
-					PosEnd:   util.NewPos(1, 11, 10, "<test>", "if false 1 else 2;"),
+					PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "if false 1 else 2;")),
+					PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "if false 1 else 2;")),
 				},
 				Alternative: &ast.ExpressionStatement{
 					Expr: &ast.IntExpression{
 						Value:    2,
-						PosStart: util.NewPos(1, 17, 16, "<test>", "if false 1 else 2;"),
-						PosEnd:   util.NewPos(1, 18, 17, "<test>", "if false 1 else 2;"),
+						PosStart: util.NewPos(1, 17, 16, util.NewSourceFile("<test>", "if false 1 else 2;")),
+						PosEnd:   util.NewPos(1, 18, 17, util.NewSourceFile("<test>", "if false 1 else 2;")),
 					},
-					PosStart: util.NewPos(1, 17, 16, "<test>", "if false 1 else 2;"),
-					PosEnd:   util.NewPos(1, 18, 17, "<test>", "if false 1 else 2;"),
+					PosStart: util.NewPos(1, 17, 16, util.NewSourceFile("<test>", "if false 1 else 2;")),
+					PosEnd:   util.NewPos(1, 18, 17, util.NewSourceFile("<test>", "if false 1 else 2;")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "if false 1 else 2;"),
-				PosEnd:   util.NewPos(1, 18, 17, "<test>", "if false 1 else 2;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "if false 1 else 2;")),
+				PosEnd:   util.NewPos(1, 18, 17, util.NewSourceFile("<test>", "if false 1 else 2;")),
 			},
 		},
 	}
@@ -1623,6 +2131,106 @@ func TestParser_ParseIfExpression(t *testing.T) {
 	}
 }
 
+func TestParser_ParseTernaryExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		condition   string
+		consequence string
+		alternative string
+	}{
+		{name: "Simple", input: "a > 1 ? \"x\" : \"y\";", condition: "a > 1", consequence: "\"x\"", alternative: "\"y\""},
+		{name: "Logical Condition", input: "a || b ? c : d;", condition: "a || b", consequence: "c", alternative: "d"},
+		{name: "Right Associative", input: "a ? b : c ? d : e;", condition: "a", consequence: "b", alternative: "c ? d : e"},
+		{name: "Nested Consequence", input: "a ? b ? c : d : e;", condition: "a", consequence: "b ? c : d", alternative: "e"},
+		{name: "Assignment In Consequence", input: "a ? x = 1 : y;", condition: "a", consequence: "x = 1", alternative: "y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			te, ok := program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.TernaryExpression)
+			if !ok {
+				t.Fatalf("excepted *ast.TernaryExpression, got %s", ast.Dump(program.Statements[0]))
+			}
+			got := []string{te.Condition.String(), te.Consequence.String(), te.Alternative.String()}
+			excepted := []string{tt.condition, tt.consequence, tt.alternative}
+			if !reflect.DeepEqual(got, excepted) {
+				t.Errorf("excepted %q, got %q", excepted, got)
+			}
+		})
+	}
+}
+
+func TestParser_TernaryInAssignment(t *testing.T) {
+	p, _ := NewParser(lexer.NewLexer("<test>", "x = a ? 1 : 2;"))
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("err = %+v, expected nil", p.Err)
+	}
+	assignment := program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.VarAssignmentExpression)
+	if _, ok := assignment.Value.(*ast.TernaryExpression); !ok {
+		t.Errorf("excepted the assigned value to be a ternary expression, got %s", ast.Dump(assignment.Value))
+	}
+}
+
+func TestParser_ParseFunctionExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+	}{
+		{name: "Expression Body", input: "var f = func(x) x + 1;", excepted: "func(x) x + 1"},
+		{name: "Block Body", input: "var f = func() { return 1; };", excepted: "func() {\n    return 1\n}"},
+		{name: "Default Value", input: "var f = func(a, b=2) a * b;", excepted: "func(a, b=2) a * b"},
+		{name: "Nested", input: "var f = func(a) func(b) a + b;", excepted: "func(a) func(b) a + b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			vi := program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.VarInitializationExpression)
+			fe, ok := vi.Value.(*ast.FunctionExpression)
+			if !ok {
+				t.Fatalf("excepted *ast.FunctionExpression, got %s", ast.Dump(vi.Value))
+			}
+			if got := fe.String(); got != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestParser_FunctionExpressionStatement(t *testing.T) {
+	p, _ := NewParser(lexer.NewLexer("<test>", "(func(x) x)(1);\nfunc(x) x;"))
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("err = %+v, expected nil", p.Err)
+	}
+	call, ok := program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("excepted *ast.CallExpression, got %s", ast.Dump(program.Statements[0]))
+	}
+	if _, ok := call.Function.(*ast.GroupedExpression).Expr.(*ast.FunctionExpression); !ok {
+		t.Errorf("excepted the callee to be a function expression, got %s", ast.Dump(call.Function))
+	}
+	statement, ok := program.Statements[1].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("excepted *ast.ExpressionStatement, got %s", ast.Dump(program.Statements[1]))
+	}
+	if _, ok := statement.Expr.(*ast.FunctionExpression); !ok {
+		t.Errorf("excepted a function expression, got %s", ast.Dump(statement.Expr))
+	}
+}
+
 func TestParser_ParseCallExpression(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1635,12 +2243,12 @@ func TestParser_ParseCallExpression(t *testing.T) {
 			expected: &ast.CallExpression{
 				Function: &ast.IdentifierExpression{
 					Name:     "f",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "f();"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "f();"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "f();")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "f();")),
 				},
 				Argument: make([]ast.Expression, 0),
-				PosStart: util.NewPos(1, 1, 0, "<test>", "f();"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "f();"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "f();")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "f();")),
 			},
 		},
 		{
@@ -1649,24 +2257,24 @@ func TestParser_ParseCallExpression(t *testing.T) {
 			expected: &ast.CallExpression{
 				Function: &ast.IdentifierExpression{
 					Name:     "f",
-					PosStart: util.NewPos(1, 1, 0, "<test>", "f(1, , 2);"),
-					PosEnd:   util.NewPos(1, 2, 1, "<test>", "f(1, , 2);"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "f(1, , 2);")),
+					PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "f(1, , 2);")),
 				},
 				Argument: []ast.Expression{
 					&ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 3, 2, "<test>", "f(1, , 2);"),
-						PosEnd:   util.NewPos(1, 4, 3, "<test>", "f(1, , 2);"),
+						PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "f(1, , 2);")),
+						PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "f(1, , 2);")),
 					},
 					nil,
 					&ast.IntExpression{
 						Value:    2,
-						PosStart: util.NewPos(1, 8, 7, "<test>", "f(1, , 2);"),
-						PosEnd:   util.NewPos(1, 9, 8, "<test>", "f(1, , 2);"),
+						PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "f(1, , 2);")),
+						PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "f(1, , 2);")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "f(1, , 2);"),
-				PosEnd:   util.NewPos(1, 10, 9, "<test>", "f(1, , 2);"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "f(1, , 2);")),
+				PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "f(1, , 2);")),
 			},
 		},
 	}
@@ -1700,8 +2308,8 @@ func TestParser_ParseListExpression(t *testing.T) {
 			input: "[];",
 			expected: &ast.ListExpression{
 				Value:    make([]ast.Expression, 0),
-				PosStart: util.NewPos(1, 1, 0, "<test>", "[];"),
-				PosEnd:   util.NewPos(1, 3, 2, "<test>", "[];"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[];")),
+				PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "[];")),
 			},
 		},
 		{
@@ -1711,12 +2319,12 @@ func TestParser_ParseListExpression(t *testing.T) {
 				Value: []ast.Expression{
 					&ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 2, 1, "<test>", "[1];"),
-						PosEnd:   util.NewPos(1, 3, 2, "<test>", "[1];"),
+						PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[1];")),
+						PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "[1];")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "[1];"),
-				PosEnd:   util.NewPos(1, 4, 3, "<test>", "[1];"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[1];")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "[1];")),
 			},
 		},
 		{
@@ -1726,22 +2334,22 @@ func TestParser_ParseListExpression(t *testing.T) {
 				Value: []ast.Expression{
 					&ast.IntExpression{
 						Value:    1,
-						PosStart: util.NewPos(1, 2, 1, "<test>", "[1, 2, 3];"),
-						PosEnd:   util.NewPos(1, 3, 2, "<test>", "[1, 2, 3];"),
+						PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[1, 2, 3];")),
+						PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "[1, 2, 3];")),
 					},
 					&ast.IntExpression{
 						Value:    2,
-						PosStart: util.NewPos(1, 5, 4, "<test>", "[1, 2, 3];"),
-						PosEnd:   util.NewPos(1, 6, 5, "<test>", "[1, 2, 3];"),
+						PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "[1, 2, 3];")),
+						PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "[1, 2, 3];")),
 					},
 					&ast.IntExpression{
 						Value:    3,
-						PosStart: util.NewPos(1, 8, 7, "<test>", "[1, 2, 3];"),
-						PosEnd:   util.NewPos(1, 9, 8, "<test>", "[1, 2, 3];"),
+						PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "[1, 2, 3];")),
+						PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "[1, 2, 3];")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "[1, 2, 3];"),
-				PosEnd:   util.NewPos(1, 10, 9, "<test>", "[1, 2, 3];"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[1, 2, 3];")),
+				PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "[1, 2, 3];")),
 			},
 		},
 		{
@@ -1751,17 +2359,17 @@ func TestParser_ParseListExpression(t *testing.T) {
 				Value: []ast.Expression{
 					&ast.StringExpression{
 						Value:    "hello",
-						PosStart: util.NewPos(1, 2, 1, "<test>", "[\"hello\", \"world\"];"),
-						PosEnd:   util.NewPos(1, 9, 8, "<test>", "[\"hello\", \"world\"];"),
+						PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[\"hello\", \"world\"];")),
+						PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "[\"hello\", \"world\"];")),
 					},
 					&ast.StringExpression{
 						Value:    "world",
-						PosStart: util.NewPos(1, 11, 10, "<test>", "[\"hello\", \"world\"];"),
-						PosEnd:   util.NewPos(1, 18, 17, "<test>", "[\"hello\", \"world\"];"),
+						PosStart: util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "[\"hello\", \"world\"];")),
+						PosEnd:   util.NewPos(1, 18, 17, util.NewSourceFile("<test>", "[\"hello\", \"world\"];")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "[\"hello\", \"world\"];"),
-				PosEnd:   util.NewPos(1, 19, 18, "<test>", "[\"hello\", \"world\"];"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[\"hello\", \"world\"];")),
+				PosEnd:   util.NewPos(1, 19, 18, util.NewSourceFile("<test>", "[\"hello\", \"world\"];")),
 			},
 		},
 		{
@@ -1771,22 +2379,22 @@ func TestParser_ParseListExpression(t *testing.T) {
 				Value: []ast.Expression{
 					&ast.BoolExpression{
 						Value:    true,
-						PosStart: util.NewPos(1, 2, 1, "<test>", "[true, false, true];"),
-						PosEnd:   util.NewPos(1, 6, 5, "<test>", "[true, false, true];"),
+						PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[true, false, true];")),
+						PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "[true, false, true];")),
 					},
 					&ast.BoolExpression{
 						Value:    false,
-						PosStart: util.NewPos(1, 8, 7, "<test>", "[true, false, true];"),
-						PosEnd:   util.NewPos(1, 13, 12, "<test>", "[true, false, true];"),
+						PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "[true, false, true];")),
+						PosEnd:   util.NewPos(1, 13, 12, util.NewSourceFile("<test>", "[true, false, true];")),
 					},
 					&ast.BoolExpression{
 						Value:    true,
-						PosStart: util.NewPos(1, 15, 14, "<test>", "[true, false, true];"),
-						PosEnd:   util.NewPos(1, 19, 18, "<test>", "[true, false, true];"),
+						PosStart: util.NewPos(1, 15, 14, util.NewSourceFile("<test>", "[true, false, true];")),
+						PosEnd:   util.NewPos(1, 19, 18, util.NewSourceFile("<test>", "[true, false, true];")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "[true, false, true];"),
-				PosEnd:   util.NewPos(1, 20, 19, "<test>", "[true, false, true];"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[true, false, true];")),
+				PosEnd:   util.NewPos(1, 20, 19, util.NewSourceFile("<test>", "[true, false, true];")),
 			},
 		},
 		{
@@ -1797,46 +2405,46 @@ func TestParser_ParseListExpression(t *testing.T) {
 					&ast.InfixExpression{
 						Left: &ast.IntExpression{
 							Value:    1,
-							PosStart: util.NewPos(1, 2, 1, "<test>", "[1 + 2, 3 + 4];"),
-							PosEnd:   util.NewPos(1, 3, 2, "<test>", "[1 + 2, 3 + 4];"),
+							PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+							PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 						},
 						Operator: &lexer.Token{
 							Type:     lexer.PLUS,
 							Literal:  "+",
-							PosStart: util.NewPos(1, 4, 3, "<test>", "[1 + 2, 3 + 4];"),
-							PosEnd:   util.NewPos(1, 5, 4, "<test>", "[1 + 2, 3 + 4];"),
+							PosStart: util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+							PosEnd:   util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 						},
 						Right: &ast.IntExpression{
 							Value:    2,
-							PosStart: util.NewPos(1, 6, 5, "<test>", "[1 + 2, 3 + 4];"),
-							PosEnd:   util.NewPos(1, 7, 6, "<test>", "[1 + 2, 3 + 4];"),
+							PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+							PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 						},
-						PosStart: util.NewPos(1, 2, 1, "<test>", "[1 + 2, 3 + 4];"),
-						PosEnd:   util.NewPos(1, 7, 6, "<test>", "[1 + 2, 3 + 4];"),
+						PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+						PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 					},
 					&ast.InfixExpression{
 						Left: &ast.IntExpression{
 							Value:    3,
-							PosStart: util.NewPos(1, 9, 8, "<test>", "[1 + 2, 3 + 4];"),
-							PosEnd:   util.NewPos(1, 10, 9, "<test>", "[1 + 2, 3 + 4];"),
+							PosStart: util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+							PosEnd:   util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 						},
 						Operator: &lexer.Token{
 							Type:     lexer.PLUS,
 							Literal:  "+",
-							PosStart: util.NewPos(1, 11, 10, "<test>", "[1 + 2, 3 + 4];"),
-							PosEnd:   util.NewPos(1, 12, 11, "<test>", "[1 + 2, 3 + 4];"),
+							PosStart: util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+							PosEnd:   util.NewPos(1, 12, 11, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 						},
 						Right: &ast.IntExpression{
 							Value:    4,
-							PosStart: util.NewPos(1, 13, 12, "<test>", "[1 + 2, 3 + 4];"),
-							PosEnd:   util.NewPos(1, 14, 13, "<test>", "[1 + 2, 3 + 4];"),
+							PosStart: util.NewPos(1, 13, 12, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+							PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 						},
-						PosStart: util.NewPos(1, 9, 8, "<test>", "[1 + 2, 3 + 4];"),
-						PosEnd:   util.NewPos(1, 14, 13, "<test>", "[1 + 2, 3 + 4];"),
+						PosStart: util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+						PosEnd:   util.NewPos(1, 14, 13, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 					},
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "[1 + 2, 3 + 4];"),
-				PosEnd:   util.NewPos(1, 15, 14, "<test>", "[1 + 2, 3 + 4];"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
+				PosEnd:   util.NewPos(1, 15, 14, util.NewSourceFile("<test>", "[1 + 2, 3 + 4];")),
 			},
 		},
 	}
@@ -1859,7 +2467,146 @@ func TestParser_ParseListExpression(t *testing.T) {
 	}
 }
 
-func TestParser_ParseIndexExpression_Structure(t *testing.T) {
+func TestParser_ParseMapExpression(t *testing.T) {
+	source := util.NewSourceFile("<test>", "{\"a\": 1};")
+	excepted := &ast.MapExpression{
+		Entries: []*ast.MapEntry{
+			{
+				Key: &ast.StringExpression{
+					Value:    "a",
+					PosStart: util.NewPos(1, 2, 1, source),
+					PosEnd:   util.NewPos(1, 5, 4, source),
+				},
+				Value: &ast.IntExpression{
+					Value:    1,
+					PosStart: util.NewPos(1, 7, 6, source),
+					PosEnd:   util.NewPos(1, 8, 7, source),
+				},
+			},
+		},
+		PosStart: util.NewPos(1, 1, 0, source),
+		PosEnd:   util.NewPos(1, 9, 8, source),
+	}
+	p, _ := NewParser(lexer.NewLexer("<test>", source.Text))
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("err = %+v, expected nil", p.Err)
+	}
+	got := program.Statements[0].(*ast.ExpressionStatement).Expr
+	if !reflect.DeepEqual(got, excepted) {
+		t.Errorf("expected %s, got %s", ast.Dump(excepted), ast.Dump(got))
+	}
+}
+
+func TestParser_MapOrBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		isMap    bool
+	}{
+		{name: "Empty Map", input: "var m = {:};", excepted: "{:}", isMap: true},
+		{name: "Several Entries", input: "var m = {\"a\": 1, 2: [3], x + 1: f(y)};", excepted: "{\"a\": 1, 2: [3], x + 1: f(y)}", isMap: true},
+		{name: "Trailing Comma", input: "var m = {\n    \"a\": 1,\n    \"b\": 2,\n};", excepted: "{\"a\": 1, \"b\": 2}", isMap: true},
+		{name: "Nested Maps", input: "var m = {\"a\": {\"b\": {:}}};", excepted: "{\"a\": {\"b\": {:}}}", isMap: true},
+		{name: "Empty Braces", input: "var m = {};", excepted: "{:}", isMap: true},
+		{name: "Empty Braces In Call", input: "var m = f({}, [{}], c ? {} : {:});", excepted: "f({:}, [{:}], c ? {:} : {:})"},
+		{name: "Block With Expression", input: "var m = { x; };", excepted: "{\n    x\n}"},
+		{name: "Block With Declaration", input: "var m = { var a = 1; a };", excepted: "{\n    var a = 1;\n    a\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			value := program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.VarInitializationExpression).Value
+			if _, ok := value.(*ast.MapExpression); ok != tt.isMap {
+				t.Errorf("excepted map = %v, got %T", tt.isMap, value)
+			}
+			if value.String() != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, value.String())
+			}
+		})
+	}
+}
+
+func TestParser_EmptyBraces(t *testing.T) {
+	// expr 返回第一条语句的表达式
+	expr := func(program *ast.Program) ast.Expression {
+		return program.Statements[0].(*ast.ExpressionStatement).Expr
+	}
+	tests := []struct {
+		name     string
+		input    string
+		node     func(*ast.Program) ast.Node
+		excepted string
+	}{
+		{
+			name:     "Statement",
+			input:    "{};",
+			node:     func(program *ast.Program) ast.Node { return expr(program) },
+			excepted: "*ast.BlockExpression",
+		},
+		{
+			name:  "If Body",
+			input: "if x {} else {};",
+			node: func(program *ast.Program) ast.Node {
+				return expr(program).(*ast.IfExpression).Alternative.(*ast.ExpressionStatement).Expr
+			},
+			excepted: "*ast.BlockExpression",
+		},
+		{
+			name:  "Function Body",
+			input: "func f() {};",
+			node: func(program *ast.Program) ast.Node {
+				return program.Statements[0].(*ast.FunctionDeclarationStatement).Body.(*ast.ExpressionStatement).Expr
+			},
+			excepted: "*ast.BlockExpression",
+		},
+		{
+			name:  "Left Operand At Statement Start",
+			input: "{} == {};",
+			node: func(program *ast.Program) ast.Node {
+				return expr(program).(*ast.InfixExpression).Left
+			},
+			excepted: "*ast.BlockExpression",
+		},
+		{
+			name:  "Right Operand",
+			input: "{} == {};",
+			node: func(program *ast.Program) ast.Node {
+				return expr(program).(*ast.InfixExpression).Right
+			},
+			excepted: "*ast.MapExpression",
+		},
+		{
+			name:  "Grouped",
+			input: "({});",
+			node: func(program *ast.Program) ast.Node {
+				return expr(program).(*ast.GroupedExpression).Expr
+			},
+			excepted: "*ast.MapExpression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			if got := fmt.Sprintf("%T", tt.node(program)); got != tt.excepted {
+				t.Errorf("excepted %s, got %s", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestParser_ParseIndexExpression_Structure(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -1873,20 +2620,20 @@ func TestParser_ParseIndexExpression_Structure(t *testing.T) {
 					Value: []ast.Expression{
 						&ast.IntExpression{
 							Value:    1,
-							PosStart: util.NewPos(1, 2, 1, "<test>", "[1][0];"),
-							PosEnd:   util.NewPos(1, 3, 2, "<test>", "[1][0];"),
+							PosStart: util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[1][0];")),
+							PosEnd:   util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "[1][0];")),
 						},
 					},
-					PosStart: util.NewPos(1, 1, 0, "<test>", "[1][0];"),
-					PosEnd:   util.NewPos(1, 4, 3, "<test>", "[1][0];"),
+					PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[1][0];")),
+					PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "[1][0];")),
 				},
 				Index: &ast.IntExpression{
 					Value:    0,
-					PosStart: util.NewPos(1, 5, 4, "<test>", "[1][0];"),
-					PosEnd:   util.NewPos(1, 6, 5, "<test>", "[1][0];"),
+					PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "[1][0];")),
+					PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "[1][0];")),
 				},
-				PosStart: util.NewPos(1, 1, 0, "<test>", "[1][0];"),
-				PosEnd:   util.NewPos(1, 7, 6, "<test>", "[1][0];"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[1][0];")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "[1][0];")),
 			},
 		},
 	}
@@ -1909,6 +2656,53 @@ func TestParser_ParseIndexExpression_Structure(t *testing.T) {
 	}
 }
 
+func TestParser_ParseSliceExpression(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		target string
+		low    string
+		high   string
+	}{
+		{name: "Both Bounds", input: "l[1:3];", target: "l", low: "1", high: "3"},
+		{name: "Omitted High", input: "l[1:];", target: "l", low: "1"},
+		{name: "Omitted Low", input: "l[:3];", target: "l", high: "3"},
+		{name: "Omitted Both", input: "l[:];", target: "l"},
+		{name: "Negative Low", input: "l[-2:];", target: "l", low: "-2"},
+		{name: "Expressions", input: "l[i + 1:len(l) - 1];", target: "l", low: "i + 1", high: "len(l) - 1"},
+		{name: "Ternary Low", input: "l[a ? 1 : 2:3];", target: "l", low: "a ? 1 : 2", high: "3"},
+		{name: "Sliced Index", input: "m[0][1:];", target: "m[0]", low: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewParser(lexer.NewLexer("<test>", tt.input))
+			program := p.ParseProgram()
+			if p.Err != nil {
+				t.Fatalf("err = %+v, expected nil", p.Err)
+			}
+			se, ok := program.Statements[0].(*ast.ExpressionStatement).Expr.(*ast.SliceExpression)
+			if !ok {
+				t.Fatalf("excepted *ast.SliceExpression, got %s", ast.Dump(program.Statements[0]))
+			}
+			got := []string{se.Target.String(), "", ""}
+			if se.Low != nil {
+				got[1] = se.Low.String()
+			}
+			if se.High != nil {
+				got[2] = se.High.String()
+			}
+			excepted := []string{tt.target, tt.low, tt.high}
+			if !reflect.DeepEqual(got, excepted) {
+				t.Errorf("excepted %q, got %q", excepted, got)
+			}
+			if se.IsLvalue() {
+				t.Errorf("excepted a slice expression not to be an lvalue")
+			}
+		})
+	}
+}
+
 func TestParser_Errors(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1920,8 +2714,134 @@ func TestParser_Errors(t *testing.T) {
 			input: "*1;",
 			err: &SyntaxError{
 				Message:  "unexpected \"ASTERISK\".",
-				PosStart: util.NewPos(1, 1, 0, "<test>", "*1;"),
-				PosEnd:   util.NewPos(1, 2, 1, "<test>", "*1;"),
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "*1;")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "*1;")),
+			},
+		},
+		{
+			name:  "Import Without Path",
+			input: "import 1;",
+			err: &SyntaxError{
+				Message:  "expected \"STRING\", but got \"INT\".",
+				PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "import 1;")),
+				PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "import 1;")),
+			},
+		},
+		{
+			name:  "Catch Variable Without Parentheses",
+			input: "try f() catch e {};",
+			err: &SyntaxError{
+				Message:  "expected \"LPAREN\", but got \"IDENT\".",
+				PosStart: util.NewPos(1, 15, 14, util.NewSourceFile("<test>", "try f() catch e {};")),
+				PosEnd:   util.NewPos(1, 16, 15, util.NewSourceFile("<test>", "try f() catch e {};")),
+			},
+		},
+		{
+			name:  "For In Without In",
+			input: "for k, v m {};",
+			err: &SyntaxError{
+				Message:  "expected \"IN\", but got \"IDENT\".",
+				PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "for k, v m {};")),
+				PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "for k, v m {};")),
+			},
+		},
+		{
+			name:  "Ternary Without Colon",
+			input: "a ? b;",
+			err: &SyntaxError{
+				Message:  "expected \"COLON\", but got \"SEMICOLON\".",
+				PosStart: util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "a ? b;")),
+				PosEnd:   util.NewPos(1, 7, 6, util.NewSourceFile("<test>", "a ? b;")),
+			},
+		},
+		{
+			name:  "Map Entry Without Value",
+			input: "var m = {\"a\": 1, \"b\"};",
+			err: &SyntaxError{
+				Message:  "expected \"COLON\", but got \"RBRACE\".",
+				PosStart: util.NewPos(1, 21, 20, util.NewSourceFile("<test>", "var m = {\"a\": 1, \"b\"};")),
+				PosEnd:   util.NewPos(1, 22, 21, util.NewSourceFile("<test>", "var m = {\"a\": 1, \"b\"};")),
+			},
+		},
+		{
+			name:  "Slice Assignment",
+			input: "l[0:1] = 3;",
+			err: &SyntaxError{
+				Message:  "operation \"=\" requires an lvalue operand.",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "l[0:1] = 3;")),
+				PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "l[0:1] = 3;")),
+			},
+		},
+		{
+			name:  "Unclosed List",
+			input: "var xs = [1,\n    2",
+			err: &SyntaxError{
+				Message:  "unclosed \"[\" opened at line 1, column 10.",
+				PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "var xs = [1,\n    2")),
+				PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "var xs = [1,\n    2")),
+			},
+		},
+		{
+			name:  "Unclosed List Before Element",
+			input: "[1,",
+			err: &SyntaxError{
+				Message:  "unclosed \"[\" opened at line 1, column 1.",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "[1,")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "[1,")),
+			},
+		},
+		{
+			name:  "Unclosed Grouped Expression",
+			input: "(1 + 2",
+			err: &SyntaxError{
+				Message:  "unclosed \"(\" opened at line 1, column 1.",
+				PosStart: util.NewPos(1, 1, 0, util.NewSourceFile("<test>", "(1 + 2")),
+				PosEnd:   util.NewPos(1, 2, 1, util.NewSourceFile("<test>", "(1 + 2")),
+			},
+		},
+		{
+			name:  "Unclosed Grouped Expression Missing Operand",
+			input: "1 * (2 +",
+			err: &SyntaxError{
+				Message:  "unclosed \"(\" opened at line 1, column 5.",
+				PosStart: util.NewPos(1, 5, 4, util.NewSourceFile("<test>", "1 * (2 +")),
+				PosEnd:   util.NewPos(1, 6, 5, util.NewSourceFile("<test>", "1 * (2 +")),
+			},
+		},
+		{
+			name:  "Unclosed Call",
+			input: "println(1, 2",
+			err: &SyntaxError{
+				Message:  "unclosed \"(\" opened at line 1, column 8.",
+				PosStart: util.NewPos(1, 8, 7, util.NewSourceFile("<test>", "println(1, 2")),
+				PosEnd:   util.NewPos(1, 9, 8, util.NewSourceFile("<test>", "println(1, 2")),
+			},
+		},
+		{
+			name:  "Unclosed Index",
+			input: "xs[0",
+			err: &SyntaxError{
+				Message:  "unclosed \"[\" opened at line 1, column 3.",
+				PosStart: util.NewPos(1, 3, 2, util.NewSourceFile("<test>", "xs[0")),
+				PosEnd:   util.NewPos(1, 4, 3, util.NewSourceFile("<test>", "xs[0")),
+			},
+		},
+		{
+			name:  "Unclosed Block",
+			input: "func f() {\n    return 1;\n",
+			err: &SyntaxError{
+				Message:  "unclosed \"{\" opened at line 1, column 10.",
+				PosStart: util.NewPos(1, 10, 9, util.NewSourceFile("<test>", "func f() {\n    return 1;\n")),
+				PosEnd:   util.NewPos(1, 11, 10, util.NewSourceFile("<test>", "func f() {\n    return 1;\n")),
+			},
+		},
+		{
+			name:  "Innermost Unclosed Bracket",
+			input: "{\n    f([1, (2",
+			err: &SyntaxError{
+				Message:  "unclosed \"(\" opened at line 2, column 11.",
+				PosStart: util.NewPos(2, 11, 12, util.NewSourceFile("<test>", "{\n    f([1, (2")),
+				PosEnd:   util.NewPos(2, 12, 13, util.NewSourceFile("<test>", "{\n    f([1, (2")),
 			},
 		},
 	}
@@ -1937,3 +2857,369 @@ func TestParser_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_EOFErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		pos      string
+		missing  lexer.TokenType
+	}{
+		{name: "Missing Semicolon", input: "var x = 1", excepted: `expected ";" before end of file.`, pos: "1:10", missing: lexer.SEMICOLON},
+		{name: "Missing Semicolon Before Trailing Lines", input: "var x = 1\n\n// done\n", excepted: `expected ";" before end of file.`, pos: "1:10", missing: lexer.SEMICOLON},
+		{name: "Missing Right Operand", input: "var x = 1 +\n", excepted: "expected an expression before end of file.", pos: "1:12"},
+		{name: "Missing Initializer", input: "var x =", excepted: "expected an expression before end of file.", pos: "1:8"},
+		{name: "Missing Variable Name", input: "var", excepted: "expected identifier before end of file.", pos: "1:4", missing: lexer.IDENT},
+		{name: "Missing Import Path", input: "import", excepted: "expected string before end of file.", pos: "1:7", missing: lexer.STRING},
+		{name: "Missing Parameter List", input: "func f", excepted: `expected "(" before end of file.`, pos: "1:7", missing: lexer.LPAREN},
+		{name: "Unclosed Parameter List", input: "func f(a, b", excepted: `unclosed "(" opened at line 1, column 7.`, pos: "1:7"},
+		{name: "Parameter List After Comma", input: "func f(a,", excepted: `unclosed "(" opened at line 1, column 7.`, pos: "1:7"},
+		{name: "Missing Function Body", input: "func f(a)", excepted: "expected an expression before end of file.", pos: "1:10"},
+		{name: "Missing For Update", input: "for var i = 0; i < 3", excepted: `expected ";" before end of file.`, pos: "1:21", missing: lexer.SEMICOLON},
+		{name: "Missing While Body", input: "while x < 3", excepted: "expected an expression before end of file.", pos: "1:12"},
+		{name: "Missing Else Branch", input: "if x {} else", excepted: "expected an expression before end of file.", pos: "1:13"},
+		{name: "Unclosed Block", input: "func f() {\n    return 1", excepted: "unclosed \"{\" opened at line 1, column 10.", pos: "1:10"},
+		{name: "Missing Catch", input: "try f()", excepted: `expected "catch" before end of file.`, pos: "1:8", missing: lexer.CATCH},
+		{name: "Missing Catch Variable", input: "try f() catch (", excepted: `unclosed "(" opened at line 1, column 15.`, pos: "1:15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(lexer.NewLexer("<test>", tt.input))
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			p.ParseProgram()
+			syntaxErr, ok := p.Err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("err = %+v, expected *SyntaxError", p.Err)
+			}
+			if syntaxErr.Message != tt.excepted {
+				t.Errorf("excepted message %q, got %q", tt.excepted, syntaxErr.Message)
+			}
+			if pos := fmt.Sprintf("%d:%d", syntaxErr.PosStart.Row, syntaxErr.PosStart.Col); pos != tt.pos {
+				t.Errorf("excepted position %s, got %s", tt.pos, pos)
+			}
+			if !syntaxErr.EOF || syntaxErr.Expected != tt.missing {
+				t.Errorf("excepted EOF error missing %q, got EOF = %v, Expected = %q", tt.missing, syntaxErr.EOF, syntaxErr.Expected)
+			}
+			// 错误范围不能超出源代码
+			if syntaxErr.PosEnd.Idx > len(tt.input) {
+				t.Errorf("excepted error to end within the input, got index %d", syntaxErr.PosEnd.Idx)
+			}
+		})
+	}
+}
+
+func TestParser_UnclosedDelimiters(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted string
+		pos      string
+		note     string
+	}{
+		{
+			name:     "Unclosed Block In Middle Of File",
+			input:    "var a = 1;\nfunc f(x) {\n    var y = x;\n\nprintln(a);\nvar b = 2;",
+			excepted: `unclosed "{" opened at line 2, column 11.`,
+			pos:      "2:11",
+			note:     "6:11",
+		},
+		{
+			name:     "Unclosed List Inside Call",
+			input:    "println([1, 2);",
+			excepted: `unclosed "[" opened at line 1, column 9.`,
+			pos:      "1:9",
+			note:     "1:14",
+		},
+		{
+			name:     "Innermost Unclosed Delimiter",
+			input:    "f(g(1);",
+			excepted: `unclosed "(" opened at line 1, column 2.`,
+			pos:      "1:2",
+			note:     "1:7",
+		},
+		{
+			name:     "Unclosed Index",
+			input:    "var x = xs[0;",
+			excepted: `unclosed "[" opened at line 1, column 11.`,
+			pos:      "1:11",
+			note:     "1:13",
+		},
+		{
+			name:     "Closed Delimiters Are Not Reported",
+			input:    "var x = (1 + 2) );",
+			excepted: `expected "SEMICOLON", but got "RPAREN".`,
+			pos:      "1:17",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(lexer.NewLexer("<test>", tt.input))
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			p.ParseProgram()
+			syntaxErr, ok := p.Err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("err = %+v, expected *SyntaxError", p.Err)
+			}
+			if syntaxErr.Message != tt.excepted {
+				t.Errorf("excepted message %q, got %q", tt.excepted, syntaxErr.Message)
+			}
+			if pos := fmt.Sprintf("%d:%d", syntaxErr.PosStart.Row, syntaxErr.PosStart.Col); pos != tt.pos {
+				t.Errorf("excepted position %s, got %s", tt.pos, pos)
+			}
+			note := ""
+			if syntaxErr.NotePosStart != nil {
+				note = fmt.Sprintf("%d:%d", syntaxErr.NotePosStart.Row, syntaxErr.NotePosStart.Col)
+			}
+			if note != tt.note {
+				t.Errorf("excepted note position %q, got %q", tt.note, note)
+			}
+		})
+	}
+}
+
+func TestParser_NestingLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   bool
+	}{
+		{
+			name:  "Nested Lists Within Limit",
+			input: strings.Repeat("[", MaxNestingDepth-2) + strings.Repeat("]", MaxNestingDepth-2) + ";",
+		},
+		{
+			name:  "Unclosed Nested Lists",
+			input: strings.Repeat("[", 100000),
+			err:   true,
+		},
+		{
+			name:  "Nested Groups",
+			input: strings.Repeat("(", MaxNestingDepth) + "1" + strings.Repeat(")", MaxNestingDepth) + ";",
+			err:   true,
+		},
+		{
+			name:  "Prefix Operators",
+			input: strings.Repeat("-", 100000) + "1;",
+			err:   true,
+		},
+		{
+			name:  "Nested For Statements",
+			input: strings.Repeat("for ", 100000),
+			err:   true,
+		},
+		{
+			name:  "Long Operator Chain",
+			input: "1" + strings.Repeat(" + 1", MaxNestingDepth) + ";",
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, recover := range []bool{false, true} {
+				p, err := NewParser(lexer.NewLexer("<test>", tt.input))
+				if err != nil {
+					t.Fatalf("err = %+v, expected nil", err)
+				}
+				p.Recover = recover
+				p.ParseProgram()
+				if !tt.err {
+					if p.Err != nil {
+						t.Fatalf("err = %+v, expected nil", p.Err)
+					}
+					continue
+				}
+				syntaxErr, ok := p.Err.(*SyntaxError)
+				if !ok || syntaxErr.Code != errcode.NestingTooDeep {
+					t.Fatalf("err = %+v, expected nesting error", p.Err)
+				}
+				if excepted := fmt.Sprintf("nesting exceeds the maximum depth of %d.", MaxNestingDepth); syntaxErr.Message != excepted {
+					t.Errorf("excepted message %q, got %q", excepted, syntaxErr.Message)
+				}
+				if p.nesting != 0 {
+					t.Errorf("excepted nesting depth to be restored, got %d", p.nesting)
+				}
+			}
+		})
+	}
+}
+
+func TestParser_Recover(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted []string
+	}{
+		{
+			name:     "No Errors",
+			input:    "var x = 1;\nprintln(x);\n",
+			excepted: nil,
+		},
+		{
+			name:  "Interleaved Statements",
+			input: "var a = 1;\nvar b = (1 + 2;\nprintln(a);\nvar c = ;\nfunc f() { return 1 2; };\nvar d = 4;\n",
+			excepted: []string{
+				`2:9: unclosed "(" opened at line 2, column 9.`,
+				`4:9: unexpected "SEMICOLON".`,
+				`5:21: expected "SEMICOLON", but got "INT".`,
+			},
+		},
+		{
+			name:  "Error Inside Block",
+			input: "if true {\n    var x = ;\n    println(x);\n};\nvar y = );\n",
+			excepted: []string{
+				`2:13: unexpected "SEMICOLON".`,
+				`5:9: unexpected "RPAREN".`,
+			},
+		},
+		{
+			name:  "Stray Closer",
+			input: "var x = 1;\n};\nvar y = 2 3;\n",
+			excepted: []string{
+				`2:1: unexpected "RBRACE".`,
+				`3:11: expected "SEMICOLON", but got "INT".`,
+			},
+		},
+		{
+			name:  "Stops At Illegal Token",
+			input: "var x = (1;\nvar y = @;\nvar z = ;\n",
+			excepted: []string{
+				`1:9: unclosed "(" opened at line 1, column 9.`,
+				`2:9: illegal token "@".`,
+			},
+		},
+		{
+			name:  "Unclosed Interpolation",
+			input: "var x = 1;\nvar s = \"a ${x\";\n",
+			excepted: []string{
+				`2:12: unterminated interpolation "${".`,
+			},
+		},
+		{
+			name:  "Unclosed At End",
+			input: "var x = ;\nprintln(1, 2",
+			excepted: []string{
+				`1:9: unexpected "SEMICOLON".`,
+				`2:8: unclosed "(" opened at line 2, column 8.`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(lexer.NewLexer("<test>", tt.input))
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			p.Recover = true
+			program := p.ParseProgram()
+			var got []string
+			for _, err := range p.Errors() {
+				switch e := err.(type) {
+				case *SyntaxError:
+					got = append(got, fmt.Sprintf("%d:%d: %s", e.PosStart.Row, e.PosStart.Col, e.Message))
+				case *lexer.IllegalTokenError:
+					got = append(got, fmt.Sprintf("%d:%d: %s", e.PosStart.Row, e.PosStart.Col, e.Message))
+				default:
+					t.Fatalf("unexpected error type %T", err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+			if len(tt.excepted) > 0 && (program != nil || p.Err != p.Errors()[0]) {
+				t.Errorf("excepted nil program and the first error in Err, got %v, %+v", program, p.Err)
+			}
+		})
+	}
+}
+
+func TestParser_PartialProgram(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		recover  bool
+		excepted []string
+	}{
+		{
+			name:     "No Errors",
+			input:    "var a = 1;\nvar b = 2;\n",
+			excepted: []string{"var a = 1", "var b = 2"},
+		},
+		{
+			name:     "Stops At First Error",
+			input:    "var a = 1;\nvar b = ;\nvar c = 3;\n",
+			excepted: []string{"var a = 1"},
+		},
+		{
+			name:     "Recover Skips Broken Statements",
+			input:    "var a = 1;\nvar b = ;\nvar c = 3;\nprintln(a +",
+			recover:  true,
+			excepted: []string{"var a = 1", "var c = 3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(lexer.NewLexer("<test>", tt.input))
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			p.Recover = tt.recover
+			program := p.ParseProgram()
+			partial := p.PartialProgram()
+			if p.Err == nil && partial != program {
+				t.Errorf("excepted the partial program to be the parsed program")
+			}
+			var got []string
+			for _, statement := range partial.Statements {
+				got = append(got, statement.String())
+			}
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestParser_Reset(t *testing.T) {
+	inputs := []string{
+		"var x = (1 + 2;\n",
+		"{ var y = [1, 2",
+		"var x = 1;\nprintln(x);\n",
+		"var a = ;\nvar b = 2 3;\n",
+		"if true { x += 1; } else { x -= 1; };\n",
+	}
+	l := lexer.NewLexer("<test>", "")
+	reused, err := NewParser(l)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	reused.Recover = true
+	for _, input := range inputs {
+		fresh, err := NewParser(lexer.NewLexer("<test>", input))
+		if err != nil {
+			t.Fatalf("err = %+v, expected nil", err)
+		}
+		fresh.Recover = true
+		excepted := fresh.ParseProgram()
+		l.Reset("<test>", input)
+		if err := reused.Reset(l); err != nil {
+			t.Fatalf("err = %+v, expected nil", err)
+		}
+		got := reused.ParseProgram()
+		if (excepted == nil) != (got == nil) || excepted != nil && ast.Dump(excepted) != ast.Dump(got) {
+			t.Errorf("input %q: excepted %v, got %v", input, excepted, got)
+		}
+		if fmt.Sprint(fresh.Errors()) != fmt.Sprint(reused.Errors()) {
+			t.Errorf("input %q: excepted errors %v, got %v", input, fresh.Errors(), reused.Errors())
+		}
+	}
+}
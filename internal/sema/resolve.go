@@ -0,0 +1,108 @@
+package sema
+
+import "github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+
+// Resolve 对程序进行语义检查，并将名称解析的结果写入语法树，供解释器按槽位存取局部变量
+// 检查发现的错误在执行时同样会产生，这里不报告；无法解析的标识符在执行时按名称查找
+// 同一个程序可以重复解析，之前写入的结果被覆盖
+//
+// 参数:
+//
+//	program - 程序的语法树
+//	opts - 检查选项
+//
+// 返回值:
+//
+//	*Info - 作用域和名称的解析结果
+func Resolve(program *ast.Program, opts Options) *Info {
+	info, _ := Check(program, opts)
+	info.Annotate()
+	return info
+}
+
+// Annotate 将解析结果写入语法树
+// 按槽位存放符号的作用域在创建它的节点上记录环境布局，
+// 每个读取、赋值或声明的标识符记录其符号在执行时所在的环境和槽位
+func (info *Info) Annotate() {
+	for node, s := range info.Scopes {
+		var layout *ast.Layout
+		if s.slotted() {
+			layout = &ast.Layout{Slots: len(s.Symbols)}
+		}
+		switch n := node.(type) {
+		case *ast.BlockExpression:
+			n.Layout = layout
+		case *ast.ForStatement:
+			n.Layout = layout
+		case *ast.ForInStatement:
+			n.Layout = layout
+		case *ast.WhileStatement:
+			n.Layout = layout
+		case *ast.FunctionDeclarationStatement:
+			n.Layout = layout
+		case *ast.FunctionExpression:
+			n.Layout = layout
+		case *ast.IfExpression:
+			n.Layout = layout
+		case *ast.TryStatement:
+			n.Layout = layout
+		case *ast.CatchClause:
+			n.Layout = layout
+		}
+	}
+	for ident, s := range info.refs {
+		sym, ok := info.Uses[ident]
+		if !ok {
+			sym = info.Defs[ident]
+		}
+		ident.Binding = binding(s, sym)
+	}
+}
+
+// binding 计算标识符在执行时的存储位置
+// 从标识符所在的作用域向外，经过的每个作用域在执行时都对应一个环境；
+// 符号位于按槽位存放的作用域中时直接使用其槽位，否则从遇到的第一个按名称存放的作用域开始按名称查找，
+// 按槽位存放的作用域中的名称都是静态确定的，跳过它们不影响查找结果
+//
+// 参数:
+//
+//	s - 标识符所在的作用域
+//	sym - 标识符引用或定义的符号，未解析时为nil
+//
+// 返回值:
+//
+//	*ast.Binding - 存储位置
+func binding(s *Scope, sym *Symbol) *ast.Binding {
+	if sym != nil && sym.Kind == BuiltinSymbol && !shadowed(s, sym.Name) {
+		return &ast.Binding{Slot: -1, Builtin: true}
+	}
+	depth := 0
+	for ; s.slotted(); s = s.Outer {
+		if sym != nil && sym.Scope == s {
+			return &ast.Binding{Depth: depth, Slot: sym.Index}
+		}
+		depth++
+	}
+	return &ast.Binding{Depth: depth, Slot: -1}
+}
+
+// shadowed 判断内置函数的名称在执行时是否可能被遮蔽
+// 名称在使用处解析，同一作用域中之后声明的同名符号在执行时仍可能先被找到；
+// import语句导入的名称在检查时未知，含有import语句的作用域也可能遮蔽内置函数
+//
+// 参数:
+//
+//	s - 标识符所在的作用域
+//	name - 内置函数的名称
+//
+// 返回值:
+//
+//	bool - 是否可能被遮蔽
+func shadowed(s *Scope, name string) bool {
+	for ; s.Kind != UniverseScope; s = s.Outer {
+		if s.imports || s.names[name] != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package sema
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+// bindings 返回程序中每个标识符的位置和存储位置，按源代码顺序排列
+func bindings(program *ast.Program, info *Info) []string {
+	var idents []*ast.IdentifierExpression
+	for ident := range info.refs {
+		idents = append(idents, ident)
+	}
+	got := make([]string, len(idents))
+	for _, ident := range idents {
+		i := 0
+		for _, other := range idents {
+			if other.PosStart.Idx < ident.PosStart.Idx {
+				i++
+			}
+		}
+		got[i] = fmt.Sprintf("%d:%d %s %d/%d", ident.PosStart.Row, ident.PosStart.Col, ident.Name, ident.Binding.Depth, ident.Binding.Slot)
+		if ident.Binding.Builtin {
+			got[i] += " builtin"
+		}
+	}
+	return got
+}
+
+func TestSema_Resolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted []string
+	}{
+		{
+			name:  "Module Names Are Looked Up By Name",
+			input: "var x = 1;\nprintln(x);",
+			excepted: []string{
+				"1:5 x 0/-1",
+				"2:1 println 0/-1 builtin",
+				"2:9 x 0/-1",
+			},
+		},
+		{
+			name:  "Locals Use Slots",
+			input: "func f(a, b) {\nvar c = a;\nfor var i = 0; i < b; i++ { c += i; };\nreturn c + x;\n};",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:8 a 0/0",
+				"1:11 b 0/1",
+				"2:5 c 0/0",
+				"2:9 a 1/0",
+				"3:9 i 0/0",
+				"3:16 i 0/0",
+				"3:20 b 2/1",
+				"3:23 i 0/0",
+				"3:29 c 2/0",
+				"3:34 i 1/0",
+				"4:8 c 0/0",
+				"4:12 x 2/-1",
+			},
+		},
+		{
+			name:  "If Branches Have Their Own Scope",
+			input: "func f(c) { if c var y = 1; return c; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:8 c 0/0",
+				"1:16 c 1/0",
+				"1:22 y 0/0",
+				"1:36 c 1/0",
+			},
+		},
+		{
+			name:  "While Loops Have Their Own Scope",
+			input: "func f(n) { while n > 0 var m = n--; return n; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:8 n 0/0",
+				"1:19 n 2/0",
+				"1:29 m 0/0",
+				"1:33 n 2/0",
+				"1:45 n 1/0",
+			},
+		},
+		{
+			name:  "For In Variables Use Slots",
+			input: "func f(l) { for x in l { var y = x; }; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:8 l 0/0",
+				"1:17 x 0/0",
+				"1:22 l 1/0",
+				"1:30 y 0/0",
+				"1:34 x 1/0",
+			},
+		},
+		{
+			name:  "Map Key And Value Variables Use Slots",
+			input: "func f(m) { for k, v in m { var y = v; }; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:8 m 0/0",
+				"1:17 k 0/0",
+				"1:20 v 0/1",
+				"1:25 m 1/0",
+				"1:33 y 0/0",
+				"1:37 v 1/1",
+			},
+		},
+		{
+			name:  "Catch Variables Use Slots",
+			input: "func f() { try { var a = 1; } catch (e) { var b = e; }; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:22 a 0/0",
+				"1:38 e 0/0",
+				"1:47 b 0/0",
+				"1:51 e 1/0",
+			},
+		},
+		{
+			name:  "Scopes With Imports Are Looked Up By Name",
+			input: "func f() { import m; var z = 1; return z + w; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:26 z 0/-1",
+				"1:40 z 0/-1",
+				"1:44 w 0/-1",
+			},
+		},
+		{
+			name:  "Builtins Shadowed By Locals",
+			input: "func f(len) { return len; };\nfunc g() { var x = len(\"a\"); { var len = 1; }; return x; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:8 len 0/0",
+				"1:22 len 1/0",
+				"2:6 g 0/-1",
+				"2:16 x 0/0",
+				"2:20 len 0/-1 builtin",
+				"2:36 len 0/0",
+				"2:55 x 0/0",
+			},
+		},
+		{
+			name:  "Builtins Declared Later In An Enclosing Scope",
+			input: "func f() { return len(\"a\"); };\nvar len = 1;",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:19 len 2/-1",
+				"2:5 len 0/-1",
+			},
+		},
+		{
+			name:  "Default Values Are Looked Up By Name In The Caller",
+			input: "func f(a) { return func(b = a, c = { var d = 1; d + len(b); }) c; };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:8 a 0/0",
+				"1:25 b 0/0",
+				"1:29 a 0/-1",
+				"1:32 c 0/1",
+				"1:42 d 0/0",
+				"1:49 d 0/0",
+				"1:53 len 1/-1",
+				"1:57 b 1/-1",
+				"1:64 c 0/1",
+			},
+		},
+		{
+			name:  "Builtins In Scopes With Imports",
+			input: "func f() { import m; return len(\"a\"); };",
+			excepted: []string{
+				"1:6 f 0/-1",
+				"1:29 len 0/-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parse(t, tt.input)
+			info := Resolve(program, Options{File: "main.gh"})
+			if got := bindings(program, info); !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestSema_ResolveLayouts(t *testing.T) {
+	program := parse(t, "func f(a) { var b = a; if b { var c = 1; var d = 2; }; };\nfor var i = 0; i < 1; i++ {};")
+	Resolve(program, Options{File: "main.gh"})
+	fn := program.Statements[0].(*ast.FunctionDeclarationStatement)
+	body := fn.Body.(*ast.ExpressionStatement).Expr.(*ast.BlockExpression)
+	ifExpression := body.Statements[1].(*ast.ExpressionStatement).Expr.(*ast.IfExpression)
+	branch := ifExpression.Consequence.(*ast.ExpressionStatement).Expr.(*ast.BlockExpression)
+	loop := program.Statements[1].(*ast.ForStatement)
+	got := []*ast.Layout{fn.Layout, body.Layout, ifExpression.Layout, branch.Layout, loop.Layout}
+	excepted := []*ast.Layout{{Slots: 1}, {Slots: 1}, {Slots: 0}, {Slots: 2}, {Slots: 1}}
+	if !reflect.DeepEqual(got, excepted) {
+		t.Errorf("excepted %+v, got %+v", excepted, got)
+	}
+}
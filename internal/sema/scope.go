@@ -0,0 +1,171 @@
+package sema
+
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// ScopeKind 作用域的种类，与解释器执行时创建的环境一一对应
+
+type ScopeKind int
+
+// 作用域的种类
+
+const (
+	UniverseScope ScopeKind = iota // 内置函数所在的全局作用域
+	ModuleScope                    // 程序的顶层作用域
+	FunctionScope                  // 函数参数所在的作用域，函数体是其中的语句块
+	BlockScope                     // 语句块的作用域
+	LoopScope                      // for语句的初始化、条件和更新语句、for-in语句的循环变量或while语句的条件所在的作用域，循环体是其中的语句块
+	IfScope                        // if表达式的分支所在的作用域，分支通常是其中的语句块
+	TryScope                       // try语句的主体或catch子句的变量所在的作用域，主体和处理语句通常是其中的语句块
+	CallerScope                    // 参数默认值所在的作用域，对应调用处的环境，检查时无法确定其中可见的名称
+)
+
+// SymbolKind 符号的种类
+
+type SymbolKind int
+
+// 符号的种类
+
+const (
+	BuiltinSymbol  SymbolKind = iota // 内置函数
+	VarSymbol                        // var声明的变量
+	ConstSymbol                      // const声明的常量
+	ParamSymbol                      // 函数参数
+	FuncSymbol                       // 函数声明
+	ImportedSymbol                   // 从导入的模块中导入的顶层声明
+)
+
+// Symbol 作用域中声明的名称
+
+type Symbol struct {
+	Name     string                            // 名称
+	Kind     SymbolKind                        // 符号的种类
+	Scope    *Scope                            // 声明所在的作用域
+	Index    int                               // 在所在作用域中按声明顺序的序号，可用作局部变量的槽位
+	Func     *ast.FunctionDeclarationStatement // 函数声明，只有FuncSymbol不为nil
+	PosStart *util.Pos                         // 声明的起始位置，内置函数和导入的名称为nil
+	PosEnd   *util.Pos                         // 声明的结束位置，内置函数和导入的名称为nil
+}
+
+// IsConst 返回符号是否不可重新赋值
+// 内置函数、常量和函数声明不可重新赋值
+//
+// 返回值:
+//
+//	bool - 是否不可重新赋值
+func (s *Symbol) IsConst() bool {
+	return s.Kind == BuiltinSymbol || s.Kind == ConstSymbol || s.Kind == FuncSymbol
+}
+
+// Scope 词法作用域
+
+type Scope struct {
+	Kind    ScopeKind          // 作用域的种类
+	Outer   *Scope             // 外层作用域，全局作用域为nil
+	Symbols []*Symbol          // 按声明顺序排列的符号，包括被同名声明覆盖的符号
+	names   map[string]*Symbol // 作用域中当前可见的名称
+	pending []ast.Node         // 在作用域结束时检查的函数声明语句和函数表达式
+	imports bool               // 作用域中是否有import语句
+}
+
+// newScope 创建作用域
+//
+// 参数:
+//
+//	kind - 作用域的种类
+//	outer - 外层作用域
+//
+// 返回值:
+//
+//	*Scope - 新的作用域
+func newScope(kind ScopeKind, outer *Scope) *Scope {
+	return &Scope{Kind: kind, Outer: outer, names: make(map[string]*Symbol)}
+}
+
+// declare 在作用域中声明名称
+//
+// 参数:
+//
+//	name - 名称
+//	kind - 符号的种类
+//	posStart - 声明的起始位置
+//	posEnd - 声明的结束位置
+//
+// 返回值:
+//
+//	*Symbol - 新的符号
+func (s *Scope) declare(name string, kind SymbolKind, posStart, posEnd *util.Pos) *Symbol {
+	sym := &Symbol{Name: name, Kind: kind, Scope: s, Index: len(s.Symbols), PosStart: posStart, PosEnd: posEnd}
+	s.names[name] = sym
+	s.Symbols = append(s.Symbols, sym)
+	return sym
+}
+
+// Lookup 从内到外查找当前可见的名称
+//
+// 参数:
+//
+//	name - 名称
+//
+// 返回值:
+//
+//	*Symbol - 找到的符号，不存在时为nil
+func (s *Scope) Lookup(name string) *Symbol {
+	for ; s != nil; s = s.Outer {
+		if sym, ok := s.names[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+// slotted 返回作用域在执行时是否按槽位存放符号
+// 全局作用域、模块顶层和含有import语句的作用域中的名称可能在执行时动态加入，按名称存放；
+// 参数默认值在调用处的环境中计算，其中的名称也按名称查找
+//
+// 返回值:
+//
+//	bool - 是否按槽位存放符号
+func (s *Scope) slotted() bool {
+	return s.Kind != UniverseScope && s.Kind != ModuleScope && s.Kind != CallerScope && !s.imports
+}
+
+// inCaller 返回作用域是否位于参数默认值之中，此时找不到的名称可能在调用处可见
+//
+// 返回值:
+//
+//	bool - 是否位于参数默认值之中
+func (s *Scope) inCaller() bool {
+	for ; s != nil; s = s.Outer {
+		if s.Kind == CallerScope {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleNames 返回作用域链中当前可见的所有名称，用于查找相近的名称
+//
+// 返回值:
+//
+//	[]string - 名称，可能重复
+func (s *Scope) visibleNames() []string {
+	var names []string
+	for ; s != nil; s = s.Outer {
+		for name := range s.names {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Info 检查得到的作用域信息，供解释器或编译器按声明分配存储位置
+
+type Info struct {
+	Scopes map[ast.Node]*Scope                   // 程序、函数、语句块、循环语句、if表达式、try语句和catch子句创建的作用域
+	Uses   map[*ast.IdentifierExpression]*Symbol // 读取或赋值的标识符引用的符号，未解析的标识符不在其中
+	Defs   map[*ast.IdentifierExpression]*Symbol // 变量、常量、函数和参数声明中的名称定义的符号
+	refs   map[*ast.IdentifierExpression]*Scope  // 每个读取、赋值或声明的标识符所在的作用域
+}
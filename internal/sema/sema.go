@@ -0,0 +1,581 @@
+// Package sema 在执行之前对语法树进行语义检查，按词法作用域解析所有名称
+// 报告未定义的变量、重复定义、对常量的赋值、同一文件中声明的函数的参数数量错误以及函数之外的return语句和循环之外的break、continue语句
+// 这些错误在执行时同样会产生，检查使其在产生任何副作用之前被发现
+package sema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// Error 语义检查发现的错误，错误类型和错误代码与执行时产生的相同错误一致
+// 实现 error 接口
+
+type Error struct {
+	Code     string    // 错误代码，见errcode包
+	Kind     string    // 错误类型，如"Variable Error"
+	Message  string    // 错误描述文本
+	PosStart *util.Pos // 错误起始位置
+	PosEnd   *util.Pos // 错误结束位置
+}
+
+// Error 返回错误的位置和描述
+//
+// 返回值:
+//
+//	string - 格式为<文件>:<行>:<列>: <错误类型>: <描述>的错误信息
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.PosStart.File(), e.PosStart.Row, e.PosStart.Col, e.Kind, e.Message)
+}
+
+// ErrorKind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型，与执行时产生的相同错误一致，如"Variable Error"
+func (e *Error) ErrorKind() string {
+	return e.Kind
+}
+
+// ErrorCode 返回错误代码
+//
+// 返回值:
+//
+//	string - 错误代码，见errcode包
+func (e *Error) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorMessage 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *Error) ErrorMessage() string {
+	return e.Message
+}
+
+// Pos 返回错误的位置
+//
+// 返回值:
+//
+//	*util.Pos - 错误起始位置
+//	*util.Pos - 错误结束位置
+func (e *Error) Pos() (*util.Pos, *util.Pos) {
+	return e.PosStart, e.PosEnd
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	*frame.Frame - 语义检查在执行之前进行，没有调用栈，总是为nil
+func (e *Error) Frames() *frame.Frame {
+	return nil
+}
+
+// Loader 查找并分析import语句导入的模块
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//	file - 导入模块的文件路径
+//
+// 返回值:
+//
+//	string - 模块的绝对路径
+//	*ast.Program - 模块的语法树
+//	bool - 是否找到并成功分析了模块
+type Loader func(importStatement *ast.ImportStatement, file string) (string, *ast.Program, bool)
+
+// Options 语义检查的选项
+
+type Options struct {
+	File    string   // 程序的文件路径，用于解析导入路径
+	Globals []string // 内置函数之外预先定义的名称，如之前执行的文件中的顶层声明
+	Load    Loader   // 查找导入的模块，为nil时导入的名称未知
+}
+
+// checker 解析名称并收集错误
+
+type checker struct {
+	opts    Options
+	info    *Info
+	errors  []*Error
+	unknown bool // 是否有无法确定的导入名称，此时不报告未定义的变量
+	funcs   int  // 当前所在的函数嵌套层数
+	loops   int  // 当前函数内所在的循环嵌套层数
+}
+
+// Check 对程序进行语义检查
+// 函数体在声明函数的作用域结束时才检查，函数中可以引用在函数声明之后声明的函数和变量；
+// 顶层语句按执行顺序检查，在声明之前读取名称是错误
+//
+// 参数:
+//
+//	program - 程序的语法树
+//	opts - 检查选项
+//
+// 返回值:
+//
+//	*Info - 作用域和名称的解析结果
+//	[]*Error - 按源代码位置排列的错误，没有错误时为nil
+func Check(program *ast.Program, opts Options) (*Info, []*Error) {
+	c := &checker{
+		opts: opts,
+		info: &Info{
+			Scopes: make(map[ast.Node]*Scope),
+			Uses:   make(map[*ast.IdentifierExpression]*Symbol),
+			Defs:   make(map[*ast.IdentifierExpression]*Symbol),
+			refs:   make(map[*ast.IdentifierExpression]*Scope),
+		},
+	}
+	universe := newScope(UniverseScope, nil)
+	for _, name := range object.NewGlobalEnvironment(nil).Names() {
+		universe.declare(name, BuiltinSymbol, nil, nil)
+	}
+	for _, name := range opts.Globals {
+		universe.declare(name, VarSymbol, nil, nil)
+	}
+	module := newScope(ModuleScope, universe)
+	c.info.Scopes[program] = module
+	c.statements(program.Statements, module)
+	c.close(module)
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		return c.errors[i].PosStart.Idx < c.errors[j].PosStart.Idx
+	})
+	return c.info, c.errors
+}
+
+// report 记录错误
+//
+// 参数:
+//
+//	code - 错误代码
+//	kind - 错误类型
+//	message - 错误描述
+//	posStart - 错误起始位置
+//	posEnd - 错误结束位置
+func (c *checker) report(code, kind, message string, posStart, posEnd *util.Pos) {
+	c.errors = append(c.errors, &Error{Code: code, Kind: kind, Message: message, PosStart: posStart, PosEnd: posEnd})
+}
+
+// define 在作用域中声明标识符，并记录标识符定义的符号
+//
+// 参数:
+//
+//	ident - 声明中的名称
+//	kind - 符号的种类
+//	s - 声明所在的作用域
+//	posStart - 声明的起始位置
+//	posEnd - 声明的结束位置
+//
+// 返回值:
+//
+//	*Symbol - 新的符号
+func (c *checker) define(ident *ast.IdentifierExpression, kind SymbolKind, s *Scope, posStart, posEnd *util.Pos) *Symbol {
+	sym := s.declare(ident.Name, kind, posStart, posEnd)
+	c.info.Defs[ident] = sym
+	c.info.refs[ident] = s
+	return sym
+}
+
+// close 结束作用域，检查在其中声明的函数体
+//
+// 参数:
+//
+//	s - 要结束的作用域
+func (c *checker) close(s *Scope) {
+	for len(s.pending) > 0 {
+		fn := s.pending[0]
+		s.pending = s.pending[1:]
+		c.function(fn, s)
+	}
+}
+
+// function 检查函数的默认值和函数体
+//
+// 参数:
+//
+//	fn - 函数声明语句或函数表达式
+//	outer - 声明函数的作用域
+func (c *checker) function(fn ast.Node, outer *Scope) {
+	parameter, body := ast.FunctionParts(fn)
+	params := newScope(FunctionScope, outer)
+	c.info.Scopes[fn] = params
+	c.funcs++
+	// 函数体中的break和continue不能作用于函数之外的循环
+	loops := c.loops
+	c.loops = 0
+	// 默认值在调用时于调用处的环境中计算，不能引用参数，其中的名称在执行时按名称查找
+	defaults := newScope(CallerScope, nil)
+	for _, param := range parameter {
+		c.expression(param.DefaultValue, defaults)
+		c.define(param.Name, ParamSymbol, params, param.PosStart, param.PosEnd)
+	}
+	c.close(defaults)
+	c.statement(body, params)
+	c.close(params)
+	c.funcs--
+	c.loops = loops
+}
+
+// statements 依次检查语句
+//
+// 参数:
+//
+//	statements - 语句列表
+//	s - 当前作用域
+func (c *checker) statements(statements []ast.Statement, s *Scope) {
+	for _, statement := range statements {
+		c.statement(statement, s)
+	}
+}
+
+// statement 检查语句
+//
+// 参数:
+//
+//	statement - 语句，可以为nil
+//	s - 当前作用域
+func (c *checker) statement(statement ast.Statement, s *Scope) {
+	switch n := statement.(type) {
+	case *ast.ExpressionStatement:
+		c.expression(n.Expr, s)
+	case *ast.ReturnStatement:
+		if c.funcs == 0 {
+			c.report(errcode.SyntaxError, "Syntax Error", "return statement is only allowed inside functions.", n.PosStart, n.PosEnd)
+		}
+		c.expression(n.ReturnValue, s)
+	case *ast.BreakStatement:
+		if c.loops == 0 {
+			c.report(errcode.SyntaxError, "Syntax Error", "break statement is only allowed inside loops.", n.PosStart, n.PosEnd)
+		}
+	case *ast.ContinueStatement:
+		if c.loops == 0 {
+			c.report(errcode.SyntaxError, "Syntax Error", "continue statement is only allowed inside loops.", n.PosStart, n.PosEnd)
+		}
+	case *ast.ForStatement:
+		loop := newScope(LoopScope, s)
+		c.info.Scopes[n] = loop
+		c.statement(n.Initialization, loop)
+		c.expression(n.Condition, loop)
+		c.statement(n.Update, loop)
+		c.loops++
+		c.statement(n.Body, loop)
+		c.loops--
+		c.close(loop)
+	case *ast.ForInStatement:
+		// 被遍历的表达式在当前作用域中计算，循环变量位于循环自己的作用域中
+		c.expression(n.Iterable, s)
+		loop := newScope(LoopScope, s)
+		c.info.Scopes[n] = loop
+		c.define(n.Variable, VarSymbol, loop, n.Variable.PosStart, n.Variable.PosEnd)
+		if n.ValueVariable != nil {
+			c.define(n.ValueVariable, VarSymbol, loop, n.ValueVariable.PosStart, n.ValueVariable.PosEnd)
+		}
+		c.loops++
+		c.statement(n.Body, loop)
+		c.loops--
+		c.close(loop)
+	case *ast.TryStatement:
+		body := newScope(TryScope, s)
+		c.info.Scopes[n] = body
+		c.statement(n.Body, body)
+		c.close(body)
+		// catch子句的变量位于处理语句自己的作用域中
+		catch := newScope(TryScope, s)
+		c.info.Scopes[n.Catch] = catch
+		c.define(n.Catch.Variable, VarSymbol, catch, n.Catch.Variable.PosStart, n.Catch.Variable.PosEnd)
+		c.statement(n.Catch.Body, catch)
+		c.close(catch)
+	case *ast.ThrowStatement:
+		c.expression(n.Value, s)
+	case *ast.WhileStatement:
+		loop := newScope(LoopScope, s)
+		c.info.Scopes[n] = loop
+		c.expression(n.Condition, loop)
+		c.loops++
+		c.statement(n.Body, loop)
+		c.loops--
+		c.close(loop)
+	case *ast.FunctionDeclarationStatement:
+		name := n.Name.(*ast.IdentifierExpression)
+		if s.Lookup(name.Name) != nil {
+			c.report(errcode.VariableError, "Variable Error", fmt.Sprintf("function \"%s\" already defined.", name.Name), n.PosStart, n.PosEnd)
+		}
+		sym := c.define(name, FuncSymbol, s, name.PosStart, name.PosEnd)
+		sym.Func = n
+		s.pending = append(s.pending, n)
+	case *ast.ImportStatement:
+		s.imports = true
+		c.importNames(n, s)
+	}
+}
+
+// importNames 在作用域中声明导入的模块的顶层名称
+// 模块无法加载时导入的名称未知，之后不再报告未定义的变量
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//	s - 当前作用域
+func (c *checker) importNames(importStatement *ast.ImportStatement, s *Scope) {
+	names, ok := c.exports(importStatement, c.opts.File, map[string]bool{})
+	if !ok {
+		c.unknown = true
+		return
+	}
+	for _, name := range names {
+		s.declare(name, ImportedSymbol, nil, nil)
+	}
+}
+
+// exports 返回模块的顶层声明，包括模块自身导入的名称
+//
+// 参数:
+//
+//	importStatement - import语句节点
+//	file - 导入模块的文件路径
+//	visited - 已经访问过的模块，用于跳过循环导入
+//
+// 返回值:
+//
+//	[]string - 模块的顶层名称
+//	bool - 是否确定了所有名称
+func (c *checker) exports(importStatement *ast.ImportStatement, file string, visited map[string]bool) ([]string, bool) {
+	if c.opts.Load == nil {
+		return nil, false
+	}
+	absPath, program, ok := c.opts.Load(importStatement, file)
+	if !ok {
+		return nil, false
+	}
+	// 循环导入在执行时产生错误，这里只需避免无限递归
+	if visited[absPath] {
+		return nil, true
+	}
+	visited[absPath] = true
+	var names []string
+	for _, statement := range program.Statements {
+		switch n := statement.(type) {
+		case *ast.ExpressionStatement:
+			if varInitialization, ok := n.Expr.(*ast.VarInitializationExpression); ok {
+				names = append(names, varInitialization.Name.(*ast.IdentifierExpression).Name)
+			}
+		case *ast.FunctionDeclarationStatement:
+			names = append(names, n.Name.(*ast.IdentifierExpression).Name)
+		case *ast.ImportStatement:
+			imported, ok := c.exports(n, absPath, visited)
+			if !ok {
+				return nil, false
+			}
+			names = append(names, imported...)
+		}
+	}
+	return names, true
+}
+
+// expression 检查表达式
+//
+// 参数:
+//
+//	expression - 表达式，可以为nil
+//	s - 当前作用域
+func (c *checker) expression(expression ast.Expression, s *Scope) {
+	switch n := expression.(type) {
+	case *ast.IdentifierExpression:
+		c.resolve(n, s, "", n.PosStart, n.PosEnd)
+	case *ast.PrefixExpression:
+		c.expression(n.Value, s)
+	case *ast.InfixExpression:
+		c.expression(n.Left, s)
+		c.expression(n.Right, s)
+	case *ast.ListExpression:
+		for _, value := range n.Value {
+			c.expression(value, s)
+		}
+	case *ast.InterpolatedStringExpression:
+		for _, part := range n.Parts {
+			c.expression(part, s)
+		}
+	case *ast.MapExpression:
+		for _, entry := range n.Entries {
+			c.expression(entry.Key, s)
+			c.expression(entry.Value, s)
+		}
+	case *ast.GroupedExpression:
+		c.expression(n.Expr, s)
+	case *ast.VarInitializationExpression:
+		name := n.Name.(*ast.IdentifierExpression)
+		if _, ok := s.names[name.Name]; ok {
+			c.report(errcode.VariableError, "Variable Error", fmt.Sprintf("variable \"%s\" already defined.", name.Name), n.PosStart, n.PosEnd)
+		}
+		c.expression(n.Value, s)
+		kind := VarSymbol
+		if n.IsConst {
+			kind = ConstSymbol
+		}
+		c.define(name, kind, s, name.PosStart, name.PosEnd)
+	case *ast.VarAssignmentExpression:
+		c.assign(n.Name, s, n.PosStart, n.PosEnd)
+		c.expression(n.Value, s)
+	case *ast.CompoundAssignmentExpression:
+		c.assign(n.Name, s, n.PosStart, n.PosEnd)
+		c.expression(n.Right, s)
+	case *ast.PrefixUnaryIncDecExpression:
+		c.assign(n.Right, s, n.PosStart, n.PosEnd)
+	case *ast.PostfixUnaryIncDecExpression:
+		c.assign(n.Left, s, n.PosStart, n.PosEnd)
+	case *ast.BlockExpression:
+		block := newScope(BlockScope, s)
+		c.info.Scopes[n] = block
+		c.statements(n.Statements, block)
+		c.close(block)
+	case *ast.IfExpression:
+		// 条件在当前作用域中计算，两个分支共用if表达式的作用域
+		c.expression(n.Condition, s)
+		branch := newScope(IfScope, s)
+		c.info.Scopes[n] = branch
+		c.statement(n.Consequence, branch)
+		c.statement(n.Alternative, branch)
+		c.close(branch)
+	case *ast.TernaryExpression:
+		// 分支在当前作用域中计算
+		c.expression(n.Condition, s)
+		c.expression(n.Consequence, s)
+		c.expression(n.Alternative, s)
+	case *ast.FunctionExpression:
+		// 与函数声明相同，函数体在当前作用域结束时检查
+		s.pending = append(s.pending, n)
+	case *ast.CallExpression:
+		c.call(n, s)
+	case *ast.IndexExpression:
+		c.expression(n.Target, s)
+		c.expression(n.Index, s)
+	case *ast.SliceExpression:
+		c.expression(n.Target, s)
+		c.expression(n.Low, s)
+		c.expression(n.High, s)
+	}
+}
+
+// resolve 解析标识符引用的符号，找不到时报告未定义的变量
+//
+// 参数:
+//
+//	ident - 标识符
+//	s - 当前作用域
+//	hint - 找不到时附加在错误信息之后的提示，没有时为空
+//	posStart - 错误起始位置
+//	posEnd - 错误结束位置
+//
+// 返回值:
+//
+//	*Symbol - 引用的符号，找不到时为nil
+func (c *checker) resolve(ident *ast.IdentifierExpression, s *Scope, hint string, posStart, posEnd *util.Pos) *Symbol {
+	c.info.refs[ident] = s
+	sym := s.Lookup(ident.Name)
+	if sym != nil {
+		c.info.Uses[ident] = sym
+		return sym
+	}
+	if c.unknown || s.inCaller() {
+		return nil
+	}
+	message := fmt.Sprintf("undefined variable \"%s\".", ident.Name)
+	if suggestion, ok := util.SuggestName(ident.Name, s.visibleNames()); ok {
+		message += fmt.Sprintf(" did you mean \"%s\"?", suggestion)
+	}
+	c.report(errcode.VariableError, "Variable Error", message+hint, posStart, posEnd)
+	return nil
+}
+
+// assign 检查赋值、复合赋值和自增自减的目标，目标必须已声明且不是常量
+// 对未定义的变量赋值时提示先用var声明，对索引表达式赋值时检查被索引的变量
+//
+// 参数:
+//
+//	target - 赋值的目标
+//	s - 当前作用域
+//	posStart - 赋值表达式的起始位置
+//	posEnd - 赋值表达式的结束位置
+func (c *checker) assign(target ast.Expression, s *Scope, posStart, posEnd *util.Pos) {
+	switch t := target.(type) {
+	case *ast.IdentifierExpression:
+		c.modify(t, s, fmt.Sprintf(" declare it first with \"var %s = ...\"", t.Name), posStart, posEnd)
+	case *ast.IndexExpression:
+		root := t.Target
+		for {
+			inner, ok := root.(*ast.IndexExpression)
+			if !ok {
+				break
+			}
+			c.expression(inner.Index, s)
+			root = inner.Target
+		}
+		if ident, ok := root.(*ast.IdentifierExpression); ok {
+			c.modify(ident, s, "", t.PosStart, t.PosEnd)
+		} else {
+			c.expression(root, s)
+		}
+		c.expression(t.Index, s)
+	default:
+		c.expression(target, s)
+	}
+}
+
+// modify 检查被修改的变量，变量必须已声明且不是常量
+//
+// 参数:
+//
+//	ident - 被修改的变量
+//	s - 当前作用域
+//	hint - 变量未定义时附加在错误信息之后的提示，没有时为空
+//	posStart - 错误起始位置
+//	posEnd - 错误结束位置
+func (c *checker) modify(ident *ast.IdentifierExpression, s *Scope, hint string, posStart, posEnd *util.Pos) {
+	if sym := c.resolve(ident, s, hint, posStart, posEnd); sym != nil && sym.IsConst() {
+		c.report(errcode.VariableError, "Variable Error", fmt.Sprintf("cannot redefine constant \"%s\".", ident.Name), posStart, posEnd)
+	}
+}
+
+// call 检查函数调用，调用同一文件中声明的函数时检查参数数量
+//
+// 参数:
+//
+//	call - 函数调用表达式
+//	s - 当前作用域
+func (c *checker) call(call *ast.CallExpression, s *Scope) {
+	c.expression(call.Function, s)
+	argLen := 0
+	for _, arg := range call.Argument {
+		if arg != nil {
+			argLen++
+		}
+		c.expression(arg, s)
+	}
+	ident, ok := call.Function.(*ast.IdentifierExpression)
+	if !ok {
+		return
+	}
+	sym := c.info.Uses[ident]
+	if sym == nil || sym.Func == nil {
+		return
+	}
+	defaultLen := 0
+	for _, param := range sym.Func.Parameter {
+		if param.DefaultValue != nil {
+			defaultLen++
+		}
+	}
+	if message := object.ArgumentCountMessage(len(sym.Func.Parameter), defaultLen, argLen); message != "" {
+		c.report(errcode.ArgumentError, "Argument Error", message, call.PosStart, call.PosEnd)
+	}
+}
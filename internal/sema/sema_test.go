@@ -0,0 +1,270 @@
+package sema
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+)
+
+// parse 分析源代码得到语法树
+func parse(t *testing.T, src string) *ast.Program {
+	t.Helper()
+	p, err := parser.NewParser(lexer.NewLexer("main.gh", src))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		t.Fatalf("parse err = %+v", p.Err)
+	}
+	return program
+}
+
+// check 检查源代码并返回错误的位置、错误代码和描述
+func check(t *testing.T, src string, opts Options) []string {
+	t.Helper()
+	_, errs := Check(parse(t, src), opts)
+	var got []string
+	for _, err := range errs {
+		got = append(got, fmt.Sprintf("%d:%d %s %s", err.PosStart.Row, err.PosStart.Col, err.Code, err.Message))
+	}
+	return got
+}
+
+func TestSema_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		excepted []string
+	}{
+		{
+			name:     "Clean Program",
+			input:    "var x = 1;\nfunc f(a, b = x) { return a + b; };\nprintln(f(x));",
+			excepted: nil,
+		},
+		{
+			name:     "Undefined Variable",
+			input:    "var count = 1;\nprintln(cuont);",
+			excepted: []string{`2:9 E3005 undefined variable "cuont". did you mean "count"?`},
+		},
+		{
+			name:     "Use Before Declaration",
+			input:    "println(x);\nvar x = 1;",
+			excepted: []string{`1:9 E3005 undefined variable "x".`},
+		},
+		{
+			name:     "Forward Reference In Function Body",
+			input:    "func main() { return helper() + limit; };\nfunc helper() { return main; };\nvar limit = 1;\nmain();",
+			excepted: nil,
+		},
+		{
+			name:     "Top Level Call Before Declaration",
+			input:    "f();\nfunc f() {};",
+			excepted: []string{`1:1 E3005 undefined variable "f".`},
+		},
+		{
+			name:     "Block Scope Ends",
+			input:    "if true { var y = 1; };\ny;",
+			excepted: []string{`2:1 E3005 undefined variable "y".`},
+		},
+		{
+			name:     "Loop Variable",
+			input:    "for var i = 0; i < 3; i++ { println(i); };\ni;",
+			excepted: []string{`2:1 E3005 undefined variable "i".`},
+		},
+		{
+			name:     "Assignment To Undefined",
+			input:    "total = 1;",
+			excepted: []string{`1:1 E3005 undefined variable "total". declare it first with "var total = ..."`},
+		},
+		{
+			name:  "Update Of Undefined",
+			input: "var count = 0;\ncuont += 1;\ntotal++;\nxs[0] = 1;",
+			excepted: []string{
+				`2:1 E3005 undefined variable "cuont". did you mean "count"? declare it first with "var cuont = ..."`,
+				`3:1 E3005 undefined variable "total". declare it first with "var total = ..."`,
+				`4:1 E3005 undefined variable "xs".`,
+			},
+		},
+		{
+			name:  "Assignment To Constant",
+			input: "const c = 1;\nc = 2;\nc += 1;\nc++;",
+			excepted: []string{
+				`2:1 E3005 cannot redefine constant "c".`,
+				`3:1 E3005 cannot redefine constant "c".`,
+				`4:1 E3005 cannot redefine constant "c".`,
+			},
+		},
+		{
+			name:     "Index Assignment To Constant",
+			input:    "const xs = [[1]];\nxs[0][0] = 2;",
+			excepted: []string{`2:1 E3005 cannot redefine constant "xs".`},
+		},
+		{
+			name:     "Assignment To Builtin",
+			input:    "print = 1;",
+			excepted: []string{`1:1 E3005 cannot redefine constant "print".`},
+		},
+		{
+			name:     "Local Shadows Constant",
+			input:    "const c = 1;\nfunc f() { var c = 2; c = 3; return c; };",
+			excepted: nil,
+		},
+		{
+			name:     "Variable Already Defined",
+			input:    "var x = 1;\nvar x = 2;\nif true { var x = 3; };",
+			excepted: []string{`2:1 E3005 variable "x" already defined.`},
+		},
+		{
+			name:     "Function Already Defined",
+			input:    "func len(x) {};",
+			excepted: []string{`1:1 E3005 function "len" already defined.`},
+		},
+		{
+			name:  "Argument Count",
+			input: "func f(a, b = 1) {};\nf(1);\nf(1, 2);\nf();\nf(1, 2, 3);",
+			excepted: []string{
+				`4:1 E3006 expected between 1 parameter and 2 parameters, got 0.`,
+				`5:1 E3006 expected between 1 parameter and 2 parameters, got 3.`,
+			},
+		},
+		{
+			name:     "Argument Count Of Reassigned Name Is Unknown",
+			input:    "var f = len;\nf(1, 2);",
+			excepted: nil,
+		},
+		{
+			name:     "Top Level Return",
+			input:    "return 1;\nfunc f() { return 2; };",
+			excepted: []string{`1:1 E3011 return statement is only allowed inside functions.`},
+		},
+		{
+			name:     "Break Outside Loops",
+			input:    "break;\nwhile true { break; { if true break; }; };\nfor var i = 0; i < 1; i++ { func f() { break; }; };",
+			excepted: []string{`1:1 E3011 break statement is only allowed inside loops.`, `3:40 E3011 break statement is only allowed inside loops.`},
+		},
+		{
+			name:     "Continue Outside Loops",
+			input:    "while true { continue; };\nfunc f() { continue; };",
+			excepted: []string{`2:12 E3011 continue statement is only allowed inside loops.`},
+		},
+		{
+			name:     "For In Loops",
+			input:    "for x in [1] { break; };\nfor x in xs { continue; };\nprintln(x);",
+			excepted: []string{`2:10 E3005 undefined variable "xs".`, `3:9 E3005 undefined variable "x".`},
+		},
+		{
+			name:     "Try Statements",
+			input:    "try break catch (e) println(e);\nwhile true { try continue catch (e) break; };\ntry {} catch (e) throw x;\nprintln(e);",
+			excepted: []string{`1:5 E3011 break statement is only allowed inside loops.`, `3:24 E3005 undefined variable "x".`, `4:9 E3005 undefined variable "e".`},
+		},
+		{
+			name:     "Function Expressions",
+			input:    "var f = func(a) a + b + g;\nvar b = 1;\nwhile true { var h = func() { break; }; };",
+			excepted: []string{`1:25 E3005 undefined variable "g".`, `3:31 E3011 break statement is only allowed inside loops.`},
+		},
+		{
+			name:     "Default Values Resolved At Call Site",
+			input:    "func f(a, b = a, c = k + len(a)) {};\nfunc g(x = { var y = 1; y + z; }) x;",
+			excepted: nil,
+		},
+		{
+			name:     "Errors In Default Values",
+			input:    "func f(a = { break; }) a;",
+			excepted: []string{`1:14 E3011 break statement is only allowed inside loops.`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := check(t, tt.input, Options{File: "main.gh"})
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestSema_Imports(t *testing.T) {
+	module := parse(t, "var pi = 3;\nfunc area(r) { return pi * r * r; };")
+	load := func(importStatement *ast.ImportStatement, file string) (string, *ast.Program, bool) {
+		if importStatement.Spec() != "geometry" {
+			return "", nil, false
+		}
+		return "/geometry.gh", module, true
+	}
+	tests := []struct {
+		name     string
+		input    string
+		load     Loader
+		excepted []string
+	}{
+		{
+			name:     "Imported Names",
+			input:    "import geometry;\nprintln(area(pi));\nprintln(volume);",
+			load:     load,
+			excepted: []string{`3:9 E3005 undefined variable "volume".`},
+		},
+		{
+			name:     "Unknown Module Disables Undefined Checks",
+			input:    "import missing;\nprintln(anything);\nreturn 0;",
+			load:     load,
+			excepted: []string{`3:1 E3011 return statement is only allowed inside functions.`},
+		},
+		{
+			name:     "No Loader",
+			input:    "import geometry;\nprintln(area);",
+			excepted: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := check(t, tt.input, Options{File: "main.gh", Load: tt.load})
+			if !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestSema_Globals(t *testing.T) {
+	got := check(t, "println(add(x, 1));", Options{File: "main.gh", Globals: []string{"add", "x"}})
+	if got != nil {
+		t.Errorf("excepted no errors, got %q", got)
+	}
+}
+
+func TestSema_Info(t *testing.T) {
+	program := parse(t, "var x = 1;\nfunc f(a) { var y = a; return x + y; };")
+	info, errs := Check(program, Options{File: "main.gh"})
+	if errs != nil {
+		t.Fatalf("err = %+v, expected nil", errs)
+	}
+	module := info.Scopes[program]
+	var names []string
+	for _, sym := range module.Symbols {
+		names = append(names, fmt.Sprintf("%s:%d", sym.Name, sym.Index))
+	}
+	if excepted := []string{"x:0", "f:1"}; !reflect.DeepEqual(names, excepted) {
+		t.Errorf("excepted module symbols %q, got %q", excepted, names)
+	}
+	fn := program.Statements[1].(*ast.FunctionDeclarationStatement)
+	params := info.Scopes[fn]
+	if params == nil || params.Kind != FunctionScope || len(params.Symbols) != 1 || params.Symbols[0].Kind != ParamSymbol {
+		t.Fatalf("excepted a function scope with one parameter, got %+v", params)
+	}
+	// 每个标识符都解析到声明它的作用域中的符号
+	uses := make(map[string]ScopeKind)
+	for ident, sym := range info.Uses {
+		uses[ident.Name] = sym.Scope.Kind
+	}
+	excepted := map[string]ScopeKind{"a": FunctionScope, "x": ModuleScope, "y": BlockScope}
+	if !reflect.DeepEqual(uses, excepted) {
+		t.Errorf("excepted uses %v, got %v", excepted, uses)
+	}
+}
@@ -0,0 +1,25 @@
+// Package stdlib 提供编译进解释器的标准库模块，可以通过 import <name>; 按名称导入
+package stdlib
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// modules 内嵌的标准库模块文件，目录中的<name>.gh即为名为name的模块
+//
+//go:embed all:modules
+var modules embed.FS
+
+// FS 返回标准库模块的文件系统，根目录下的<name>.gh为名为name的模块
+//
+// 返回值:
+//
+//	fs.FS - 标准库模块的文件系统
+func FS() fs.FS {
+	sub, err := fs.Sub(modules, "modules")
+	if err != nil {
+		panic("stdlib: " + err.Error())
+	}
+	return sub
+}
@@ -0,0 +1,40 @@
+// Package testutil 提供各个包的测试共用的断言
+// 比较解释器的值时使用object.DeepEquals而不是reflect.DeepEqual，
+// 共享的单例、函数的环境等内部状态不会影响比较结果
+package testutil
+
+import (
+	"testing"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+)
+
+// AssertObject 断言得到的值与期望的值在结构上相等，不相等时报告错误并继续执行
+//
+// 参数:
+//
+//	tb - 测试或基准测试
+//	excepted - 期望的值
+//	got - 得到的值
+func AssertObject(tb testing.TB, excepted, got object.Object) {
+	tb.Helper()
+	if !object.DeepEquals(excepted, got) {
+		tb.Errorf("excepted %s, got %s", describe(excepted), describe(got))
+	}
+}
+
+// describe 返回值的类型和字符串表示，用于错误信息
+//
+// 参数:
+//
+//	obj - 要描述的值
+//
+// 返回值:
+//
+//	string - 值的描述
+func describe(obj object.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+	return obj.Type() + " " + obj.String()
+}
@@ -6,9 +6,10 @@ package util
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
-// getDisplayWidth 计算字符串的显示宽度，考虑东亚字符（中文、日文、韩文）占2个字符宽度
+// DisplayWidth 计算字符串的显示宽度，考虑东亚字符（中文、日文、韩文）占2个字符宽度
 //
 // 参数:
 //
@@ -21,7 +22,7 @@ import (
 // 注意:
 //
 //	普通ASCII字符和符号计为1个宽度，东亚字符计为2个宽度
-func getDisplayWidth(s string) int {
+func DisplayWidth(s string) int {
 	width := 0
 	count := 0
 	for _, r := range s {
@@ -36,8 +37,111 @@ func getDisplayWidth(s string) int {
 	return width
 }
 
+// MaxSnippetWidth 错误信息中源代码行的最大显示宽度，更长的行只显示错误范围附近的部分
+const MaxSnippetWidth = 120
+
+// ellipsis 被截断的源代码行两端的省略标记
+const ellipsis = "…"
+
+// ClipLine 截取过长的源代码行中错误范围附近的部分，使其显示宽度不超过MaxSnippetWidth
+// 错误范围位于截取部分的中间，超出截取部分的错误范围被截断，被省略的两端以…标记
+//
+// 参数:
+//
+//	line - 源代码行，不含换行符
+//	from - 错误范围在行中的起始字节偏移
+//	to - 错误范围在行中的结束字节偏移
+//
+// 返回值:
+//
+//	string - 截取后的源代码行，不超过最大宽度时为原行
+//	int - 错误范围在截取后的行中的起始字节偏移
+//	int - 错误范围在截取后的行中的字节长度
+func ClipLine(line string, from, to int) (string, int, int) {
+	from = min(max(from, 0), len(line))
+	to = min(max(to, from), len(line))
+	if DisplayWidth(line) <= MaxSnippetWidth {
+		return line, from, to - from
+	}
+	// 两端的省略标记各占一列
+	budget := MaxSnippetWidth - 2*DisplayWidth(ellipsis)
+	spanWidth := max(DisplayWidth(line[from:to]), 1)
+	var start, end int
+	if spanWidth >= budget {
+		// 错误范围比可用宽度更长，从错误起始位置开始显示
+		start = from
+		end = walkRight(line, from, budget)
+		to = end
+	} else {
+		// 左侧先取一半的上下文，右侧用剩余的宽度，右侧到达行尾时剩余的宽度再用于左侧
+		rest := budget - spanWidth
+		start = walkLeft(line, from, rest/2)
+		end = walkRight(line, to, rest-DisplayWidth(line[start:from]))
+		start = walkLeft(line, from, rest-DisplayWidth(line[to:end]))
+	}
+	clipped := line[start:end]
+	offset := from - start
+	if start > 0 {
+		clipped = ellipsis + clipped
+		offset += len(ellipsis)
+	}
+	if end < len(line) {
+		clipped += ellipsis
+	}
+	return clipped, offset, to - from
+}
+
+// walkLeft 从idx开始向左移动，经过的字符的显示宽度不超过width
+//
+// 参数:
+//
+//	line - 源代码行
+//	idx - 起始字节偏移
+//	width - 最大显示宽度
+//
+// 返回值:
+//
+//	int - 移动后的字节偏移
+func walkLeft(line string, idx, width int) int {
+	for idx > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:idx])
+		w := DisplayWidth(string(r))
+		if w > width {
+			break
+		}
+		width -= w
+		idx -= size
+	}
+	return idx
+}
+
+// walkRight 从idx开始向右移动，经过的字符的显示宽度不超过width
+//
+// 参数:
+//
+//	line - 源代码行
+//	idx - 起始字节偏移
+//	width - 最大显示宽度
+//
+// 返回值:
+//
+//	int - 移动后的字节偏移
+func walkRight(line string, idx, width int) int {
+	for idx < len(line) {
+		r, size := utf8.DecodeRuneInString(line[idx:])
+		w := DisplayWidth(string(r))
+		if w > width {
+			break
+		}
+		width -= w
+		idx += size
+	}
+	return idx
+}
+
 // StringsWithArrows 生成带有箭头标记的错误位置可视化字符串
 // 用于在源代码中标记错误发生的位置范围，帮助开发者定位问题
+// 过长的行只显示错误范围附近的部分，见ClipLine
 //
 // 参数:
 //
@@ -58,28 +162,24 @@ func StringsWithArrows(text string, posStart *Pos, posEnd *Pos, special bool) st
 	} else {
 		lineStart = max(strings.LastIndex(text[:posStart.Idx], "\n")+1, 0)
 	}
-	var lineEnd int
-	startLine := lineStart + 1
-	if startLine > len(text) {
-		lineEnd = -1
-	} else {
-		// 查找错误起始位置所在行的结束索引
-		lineEnd = strings.Index(text[startLine:], "\n")
-	}
-	if lineEnd < 0 {
-		lineEnd = len(text)
-	} else {
-		lineEnd += lineStart + 1
-	}
-	// 计算需要显示的错误行数
-	lineCount := posEnd.Row - posStart.Row + 1
+	// 查找错误起始位置所在行的结束索引
+	lineEnd := lineEndIndex(text, lineStart)
+	// 计算需要显示的错误行数，结束位置超出源代码末尾时只显示到最后一行
+	lineCount := min(posEnd.Row-posStart.Row+1, strings.Count(text[lineStart:], "\n")+1)
 	if lineCount == 1 {
 		lineWithSpace := text[lineStart:lineEnd]
 		// 去除左侧空格
 		line := strings.TrimLeft(lineWithSpace, " ")
 		spaceCount := len(lineWithSpace) - len(line)
 		idxStart := posStart.Idx - lineStart - spaceCount
-		idxEnd := posEnd.Idx - lineStart - spaceCount
+		// 不占宽度的范围（如文件结尾处缺少的token）至少标记一个字符
+		idxEnd := max(posEnd.Idx-lineStart-spaceCount, idxStart+1)
+		// 过长的行只显示错误范围附近的部分
+		if idxStart <= len(line) && DisplayWidth(line) > MaxSnippetWidth {
+			var length int
+			line, idxStart, length = ClipLine(line, idxStart, idxEnd)
+			idxEnd = idxStart + max(length, 1)
+		}
 		// 写入缩进空格
 		if special {
 			res.WriteString("        ")
@@ -91,22 +191,22 @@ func StringsWithArrows(text string, posStart *Pos, posEnd *Pos, special bool) st
 		res.WriteString("\n")
 		// 写入箭头前的空格（考虑字符显示宽度）
 		if idxStart > len(line) {
-			res.WriteString(strings.Repeat(" ", getDisplayWidth(line)+idxStart-len(line)+4))
+			res.WriteString(strings.Repeat(" ", DisplayWidth(line)+idxStart-len(line)+4))
 			if special {
 				res.WriteString("    ")
 			}
 			// 根据错误范围长度写入箭头
 			res.WriteString(strings.Repeat("^", idxEnd-idxStart))
 		} else {
-			res.WriteString(strings.Repeat(" ", getDisplayWidth(line[:idxStart])+4))
+			res.WriteString(strings.Repeat(" ", DisplayWidth(line[:idxStart])+4))
 			if special {
 				res.WriteString("    ")
 			}
 			// 根据错误范围长度写入箭头
 			if idxEnd > len(line) {
-				res.WriteString(strings.Repeat("^", getDisplayWidth(line[idxStart:])+idxEnd-len(line)))
+				res.WriteString(strings.Repeat("^", DisplayWidth(line[idxStart:])+idxEnd-len(line)))
 			} else {
-				res.WriteString(strings.Repeat("^", getDisplayWidth(line[idxStart:idxEnd])))
+				res.WriteString(strings.Repeat("^", DisplayWidth(line[idxStart:idxEnd])))
 			}
 		}
 	} else {
@@ -114,7 +214,6 @@ func StringsWithArrows(text string, posStart *Pos, posEnd *Pos, special bool) st
 		var minSpaceCount int
 		_lineStart := lineStart
 		_lineEnd := lineEnd
-		_startLine := startLine
 		for j := range lineCount {
 			// 计算当前行的空格数量
 			_lineWithSpace := text[_lineStart:_lineEnd]
@@ -127,22 +226,12 @@ func StringsWithArrows(text string, posStart *Pos, posEnd *Pos, special bool) st
 			}
 			// 计算下一行的索引
 			_lineStart = _lineEnd + 1
-			_startLine = _lineStart + 1
-			if _startLine > len(text) {
-				_lineEnd = -1
-			} else {
-				_lineEnd = strings.Index(text[_startLine:], "\n")
-			}
-			if _lineEnd < 0 {
-				_lineEnd = len(text)
-			} else {
-				_lineEnd += _lineStart + 1
-			}
+			_lineEnd = lineEndIndex(text, _lineStart)
 		}
 		for i := range lineCount {
 			// 去除左侧空格
 			lineWithSpace := text[lineStart:lineEnd]
-			line := lineWithSpace[minSpaceCount:]
+			line, _, _ := ClipLine(lineWithSpace[minSpaceCount:], 0, 0)
 			// 在每行的前面加上“|”
 			if special {
 				res.WriteString("      | ")
@@ -157,19 +246,30 @@ func StringsWithArrows(text string, posStart *Pos, posEnd *Pos, special bool) st
 			}
 			// 更新下一行的索引
 			lineStart = lineEnd + 1
-			startLine = lineStart + 1
-			if startLine > len(text) {
-				lineEnd = -1
-			} else {
-				lineEnd = strings.Index(text[startLine:], "\n")
-			}
-			if lineEnd < 0 {
-				lineEnd = len(text)
-			} else {
-				lineEnd += lineStart + 1
-			}
+			lineEnd = lineEndIndex(text, lineStart)
 		}
 	}
 	// 移除多余的制表符和前导换行
 	return strings.TrimLeft(res.String(), "\n")
 }
+
+// lineEndIndex 查找从指定索引开始的行的结束索引
+//
+// 参数:
+//
+//	text - 源代码文本
+//	lineStart - 行的起始索引
+//
+// 返回值:
+//
+//	int - 行末换行符的索引，最后一行为文本长度
+func lineEndIndex(text string, lineStart int) int {
+	if lineStart >= len(text) {
+		return len(text)
+	}
+	lineEnd := strings.IndexByte(text[lineStart:], '\n')
+	if lineEnd < 0 {
+		return len(text)
+	}
+	return lineStart + lineEnd
+}
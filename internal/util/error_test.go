@@ -0,0 +1,87 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClipLine(t *testing.T) {
+	long := strings.Repeat("a", 200) + "bcd" + strings.Repeat("e", 200)
+	tests := []struct {
+		name     string
+		line     string
+		from     int
+		to       int
+		excepted string
+		span     string
+	}{
+		{
+			name:     "Short Line",
+			line:     "var x = @;",
+			from:     8,
+			to:       9,
+			excepted: "var x = @;",
+			span:     "@",
+		},
+		{
+			name:     "Centered Span",
+			line:     long,
+			from:     200,
+			to:       203,
+			excepted: "…" + strings.Repeat("a", 57) + "bcd" + strings.Repeat("e", 58) + "…",
+			span:     "bcd",
+		},
+		{
+			name:     "Span At Line End",
+			line:     long,
+			from:     len(long),
+			to:       len(long),
+			excepted: "…" + strings.Repeat("e", 117),
+			span:     "",
+		},
+		{
+			name:     "Span Longer Than Window",
+			line:     long,
+			from:     10,
+			to:       390,
+			excepted: "…" + strings.Repeat("a", 118) + "…",
+			span:     strings.Repeat("a", 118),
+		},
+		{
+			name:     "Wide Characters",
+			line:     strings.Repeat("幽灵", 100) + "@",
+			from:     600,
+			to:       601,
+			excepted: "…" + strings.Repeat("幽灵", 29) + "@",
+			span:     "@",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, offset, length := ClipLine(tt.line, tt.from, tt.to)
+			if got != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, got)
+			}
+			if span := got[offset : offset+length]; span != tt.span {
+				t.Errorf("excepted span %q, got %q", tt.span, span)
+			}
+			if width := DisplayWidth(got); width > MaxSnippetWidth {
+				t.Errorf("excepted width at most %d, got %d", MaxSnippetWidth, width)
+			}
+		})
+	}
+}
+
+func TestStringsWithArrows_LongLine(t *testing.T) {
+	text := strings.Repeat("x", 5000) + "@" + strings.Repeat("x", 5000)
+	got := strings.Split(StringsWithArrows(text, NewPos(1, 5001, 5000, NewSourceFile("<test>", text)), NewPos(1, 5002, 5001, NewSourceFile("<test>", text)), false), "\n")
+	line := []rune(strings.TrimPrefix(got[0], "    "))
+	if len(line) > MaxSnippetWidth {
+		t.Errorf("excepted line of at most %d columns, got %d", MaxSnippetWidth, len(line))
+	}
+	column := strings.Index(got[1], "^") - 4
+	if strings.Count(got[1], "^") != 1 || column < 0 || line[column] != '@' {
+		t.Errorf("excepted a single caret under the error, got %q under %q", got[1], string(line))
+	}
+}
@@ -5,21 +5,69 @@
 package util
 
 import (
+	"encoding/binary"
+	"errors"
 	"strconv"
 	"strings"
 	"unicode/utf8"
 )
 
-// Pos 表示源代码中的位置信息，包含行列号、字节索引、文件路径及当前字符
-// 用于在编译过程中精确定位代码元素，是错误提示和语法分析的基础数据结构
+// SourceFile 源代码文件，包含文件路径、源代码文本和每一行的起始位置
+// 同一文件中的所有位置共享同一个SourceFile，创建之后不再修改
+
+type SourceFile struct {
+	Name  string // 文件路径
+	Text  string // 源代码文本
+	lines []int  // 每一行起始位置的字节索引，第一行为0
+}
+
+// NewSourceFile 创建源代码文件，预先计算每一行的起始位置
+//
+// 参数:
+//
+//	name - 文件路径
+//	text - 源代码文本
+//
+// 返回值:
+//
+//	*SourceFile - 新创建的源代码文件
+func NewSourceFile(name, text string) *SourceFile {
+	lines := make([]int, 1, strings.Count(text, "\n")+1)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return &SourceFile{Name: name, Text: text, lines: lines}
+}
+
+// LineStart 返回某一行起始位置的字节索引
+//
+// 参数:
+//
+//	row - 行号，从1开始计数
+//
+// 返回值:
+//
+//	int - 行的起始位置，行号超出范围时为最近一行的起始位置
+func (f *SourceFile) LineStart(row int) int {
+	if f.lines == nil {
+		// 不是由NewSourceFile创建的SourceFile没有预先计算行的起始位置
+		return NewSourceFile(f.Name, f.Text).LineStart(row)
+	}
+	return f.lines[min(max(row, 1), len(f.lines))-1]
+}
+
+// Pos 表示源代码中的位置信息，包含行列号、字节索引、当前字符和所在的源代码文件
+// 结构体很小，可以直接按值复制，文件路径和源代码文本由同一文件中的所有位置共享
+// 词法分析器产生的位置之后不再修改，语法树节点和错误直接引用标记中的位置
 
 type Pos struct {
-	Row  int    // 行号，从1开始计数
-	Col  int    // 列号，从1开始计数
-	Idx  int    // 字节索引，从0开始计数
-	File string // 文件路径
-	Text string // 源代码文本
-	Char rune   // 当前位置的字符
+	Row    int         // 行号，从1开始计数
+	Col    int         // 列号，从1开始计数
+	Idx    int         // 字节索引，从0开始计数
+	Char   rune        // 当前位置的字符
+	Source *SourceFile // 所在的源代码文件
 }
 
 // NewPos 创建一个新的Pos实例
@@ -29,8 +77,7 @@ type Pos struct {
 //	row  - 行号
 //	col  - 列号
 //	idx  - 字节索引
-//	File - 文件路径
-//	text - 源代码文本
+//	source - 所在的源代码文件
 //
 // 返回值:
 //
@@ -38,12 +85,15 @@ type Pos struct {
 //
 // 注意:
 //
-//	如果idx超出text范围，Char将被设置为0
-func NewPos(row, col int, idx int, File string, text string) *Pos {
-	p := &Pos{Row: row, Col: col, Idx: idx, File: File, Text: text}
+//	如果idx超出源代码范围，Char将被设置为0
+func NewPos(row, col int, idx int, source *SourceFile) *Pos {
+	p := &Pos{Row: row, Col: col, Idx: idx, Source: source}
+	text := p.Text()
 	if idx < len(text) && idx >= 0 {
-		p.Char, _ = utf8.DecodeRuneInString(text[idx:])
-		if p.Char == utf8.RuneError {
+		var size int
+		p.Char, size = utf8.DecodeRuneInString(text[idx:])
+		// 非法的UTF-8编码读取为0，源代码中的U+FFFD字符本身是合法的
+		if p.Char == utf8.RuneError && size == 1 {
 			p.Char = 0
 		}
 	} else {
@@ -52,17 +102,38 @@ func NewPos(row, col int, idx int, File string, text string) *Pos {
 	return p
 }
 
-// Copy 创建当前Pos实例的深拷贝
+// File 返回位置所在的文件路径
 //
 // 返回值:
 //
-//	*Pos - 包含相同位置信息的新Pos指针
+//	string - 文件路径，没有源代码文件时为空
+func (p *Pos) File() string {
+	if p.Source == nil {
+		return ""
+	}
+	return p.Source.Name
+}
+
+// Text 返回位置所在的源代码文本
 //
-// 用途:
+// 返回值:
+//
+//	string - 源代码文本，没有源代码文件时为空
+func (p *Pos) Text() string {
+	if p.Source == nil {
+		return ""
+	}
+	return p.Source.Text
+}
+
+// Copy 创建当前位置的副本
+// 位置按值复制即可，不需要分配内存，只有需要指针时才使用Copy
+//
+// 返回值:
 //
-//	用于在不修改原位置信息的情况下创建独立的位置副本
-func (p *Pos) Copy() *Pos {
-	return &Pos{Row: p.Row, Col: p.Col, Idx: p.Idx, File: p.File, Text: p.Text, Char: p.Char}
+//	*Pos - 包含相同位置信息的新Pos指针
+func (p Pos) Copy() *Pos {
+	return &p
 }
 
 // Advance 将位置向前移动一个字符
@@ -73,7 +144,8 @@ func (p *Pos) Copy() *Pos {
 //   - 遇到换行符('\n')时行号加1，列号重置为1
 //   - 如果当前位置已超出文本范围，仍会增加列号和索引
 func (p *Pos) Advance() {
-	if p.Idx < len(p.Text) {
+	text := p.Text()
+	if p.Idx < len(text) {
 		// 获取当前字符的字节长度
 		size := utf8.RuneLen(p.Char)
 		p.Idx += size
@@ -85,11 +157,12 @@ func (p *Pos) Advance() {
 			p.Col++
 		}
 		// 更新当前字符
-		if p.Idx >= len(p.Text) {
+		if p.Idx >= len(text) {
 			p.Char = 0
 		} else {
-			p.Char, _ = utf8.DecodeRuneInString(p.Text[p.Idx:])
-			if p.Char == utf8.RuneError {
+			var size int
+			p.Char, size = utf8.DecodeRuneInString(text[p.Idx:])
+			if p.Char == utf8.RuneError && size == 1 {
 				p.Char = 0
 			}
 		}
@@ -115,21 +188,20 @@ func (p *Pos) Backup() {
 		p.Char = 0
 	} else {
 		// 获取前一个字符及其字节长度
-		char, size := utf8.DecodeLastRuneInString(p.Text[:p.Idx])
-		if char == utf8.RuneError {
-			p.Char = 0
+		text := p.Text()
+		char, size := utf8.DecodeLastRuneInString(text[:p.Idx])
+		// 与Advance一致，非法的UTF-8编码读取为0
+		if char == utf8.RuneError && size == 1 {
+			char = 0
 		}
 		p.Idx -= size
 		p.Char = char
 		// 如果是换行符，更新行号并计算列号
 		if p.Char == '\n' {
 			p.Row--
-			if p.Row < 0 {
-				p.Col = 0
-			} else {
-				lines := strings.Split(p.Text, "\n")
-				p.Col = utf8.RuneCountInString(lines[p.Row-1]) + 1
-			}
+			// 只统计上一行的字符数
+			lineStart := p.Source.LineStart(p.Row)
+			p.Col = utf8.RuneCountInString(text[lineStart:p.Idx]) + 1
 		} else {
 			p.Col--
 		}
@@ -147,5 +219,47 @@ func (p *Pos) Backup() {
 //
 //	主要用于错误信息展示，帮助开发者快速定位问题代码位置
 func (p *Pos) String() string {
-	return p.File + ":" + string(p.Char) + ":" + strconv.Itoa(p.Row) + ":" + strconv.Itoa(p.Col)
+	return p.File() + ":" + string(p.Char) + ":" + strconv.Itoa(p.Row) + ":" + strconv.Itoa(p.Col)
+}
+
+// GobEncode 将位置编码为行号、列号、字节索引和当前字符的变长整数
+// 源代码文件不编码，解码后由使用方重新设置
+//
+// 返回值:
+//
+//	[]byte - 编码结果
+//	error - 总是为nil
+func (p *Pos) GobEncode() ([]byte, error) {
+	buf := make([]byte, 0, 4*binary.MaxVarintLen64)
+	buf = binary.AppendVarint(buf, int64(p.Row))
+	buf = binary.AppendVarint(buf, int64(p.Col))
+	buf = binary.AppendVarint(buf, int64(p.Idx))
+	buf = binary.AppendVarint(buf, int64(p.Char))
+	return buf, nil
+}
+
+// GobDecode 从GobEncode的编码结果中恢复位置，Source保持为nil
+//
+// 参数:
+//
+//	data - 编码结果
+//
+// 返回值:
+//
+//	error - 编码结果不完整或包含多余数据时的错误
+func (p *Pos) GobDecode(data []byte) error {
+	var fields [4]int64
+	for i := range fields {
+		value, n := binary.Varint(data)
+		if n <= 0 {
+			return errors.New("util: invalid position encoding")
+		}
+		fields[i] = value
+		data = data[n:]
+	}
+	if len(data) != 0 {
+		return errors.New("util: invalid position encoding")
+	}
+	p.Row, p.Col, p.Idx, p.Char = int(fields[0]), int(fields[1]), int(fields[2]), rune(fields[3])
+	return nil
 }
@@ -13,43 +13,43 @@ func TestPos_Advance(t *testing.T) {
 	}{
 		{
 			name:        "Advance",
-			pos:         NewPos(1, 1, 0, "<text>", "Hello, World!"),
-			expectedPos: NewPos(1, 2, 1, "<text>", "Hello, World!"),
+			pos:         NewPos(1, 1, 0, NewSourceFile("<text>", "Hello, World!")),
+			expectedPos: NewPos(1, 2, 1, NewSourceFile("<text>", "Hello, World!")),
 		},
 		{
 			name:        "New line",
-			pos:         NewPos(1, 5, 4, "<text>", "Hello\nWorld!"),
-			expectedPos: NewPos(1, 6, 5, "<text>", "Hello\nWorld!"),
+			pos:         NewPos(1, 5, 4, NewSourceFile("<text>", "Hello\nWorld!")),
+			expectedPos: NewPos(1, 6, 5, NewSourceFile("<text>", "Hello\nWorld!")),
 		},
 		{
 			name:        "After new line",
-			pos:         NewPos(1, 6, 5, "<text>", "Hello\nWorld!"),
-			expectedPos: NewPos(2, 1, 6, "<text>", "Hello\nWorld!"),
+			pos:         NewPos(1, 6, 5, NewSourceFile("<text>", "Hello\nWorld!")),
+			expectedPos: NewPos(2, 1, 6, NewSourceFile("<text>", "Hello\nWorld!")),
 		},
 		{
 			name:        "End of text",
-			pos:         NewPos(2, 5, 11, "<text>", "Hello\nWorld!"),
-			expectedPos: NewPos(2, 6, 12, "<text>", "Hello\nWorld!"),
+			pos:         NewPos(2, 5, 11, NewSourceFile("<text>", "Hello\nWorld!")),
+			expectedPos: NewPos(2, 6, 12, NewSourceFile("<text>", "Hello\nWorld!")),
 		},
 		{
 			name:        "Empty text",
-			pos:         NewPos(1, 1, 0, "<text>", ""),
-			expectedPos: NewPos(1, 2, 1, "<text>", ""),
+			pos:         NewPos(1, 1, 0, NewSourceFile("<text>", "")),
+			expectedPos: NewPos(1, 2, 1, NewSourceFile("<text>", "")),
 		},
 		{
 			name:        "Single char",
-			pos:         NewPos(1, 1, 0, "<text>", "a"),
-			expectedPos: NewPos(1, 2, 1, "<text>", "a"),
+			pos:         NewPos(1, 1, 0, NewSourceFile("<text>", "a")),
+			expectedPos: NewPos(1, 2, 1, NewSourceFile("<text>", "a")),
 		},
 		{
 			name:        "Single new line",
-			pos:         NewPos(1, 1, 0, "<text>", "\n"),
-			expectedPos: NewPos(2, 1, 1, "<text>", "\n"),
+			pos:         NewPos(1, 1, 0, NewSourceFile("<text>", "\n")),
+			expectedPos: NewPos(2, 1, 1, NewSourceFile("<text>", "\n")),
 		},
 		{
 			name:        "Chinese words",
-			pos:         NewPos(1, 1, 0, "<text>", "你好，世界！"),
-			expectedPos: NewPos(1, 2, 3, "<text>", "你好，世界！"),
+			pos:         NewPos(1, 1, 0, NewSourceFile("<text>", "你好，世界！")),
+			expectedPos: NewPos(1, 2, 3, NewSourceFile("<text>", "你好，世界！")),
 		},
 	}
 	for _, tt := range tests {
@@ -70,43 +70,43 @@ func TestPos_Backup(t *testing.T) {
 	}{
 		{
 			name:        "Backup",
-			pos:         NewPos(1, 2, 1, "<text>", "Hello, World!"),
-			expectedPos: NewPos(1, 1, 0, "<text>", "Hello, World!"),
+			pos:         NewPos(1, 2, 1, NewSourceFile("<text>", "Hello, World!")),
+			expectedPos: NewPos(1, 1, 0, NewSourceFile("<text>", "Hello, World!")),
 		},
 		{
 			name:        "New Line",
-			pos:         NewPos(1, 6, 5, "<text>", "Hello\nWorld!"),
-			expectedPos: NewPos(1, 5, 4, "<text>", "Hello\nWorld!"),
+			pos:         NewPos(1, 6, 5, NewSourceFile("<text>", "Hello\nWorld!")),
+			expectedPos: NewPos(1, 5, 4, NewSourceFile("<text>", "Hello\nWorld!")),
 		},
 		{
 			name:        "After New Line",
-			pos:         NewPos(2, 1, 6, "<text>", "Hello\nWorld!"),
-			expectedPos: NewPos(1, 6, 5, "<text>", "Hello\nWorld!"),
+			pos:         NewPos(2, 1, 6, NewSourceFile("<text>", "Hello\nWorld!")),
+			expectedPos: NewPos(1, 6, 5, NewSourceFile("<text>", "Hello\nWorld!")),
 		},
 		{
 			name:        "End of Text",
-			pos:         NewPos(2, 6, 12, "<text>", "Hello\nWorld!"),
-			expectedPos: NewPos(2, 5, 11, "<text>", "Hello\nWorld!"),
+			pos:         NewPos(2, 6, 12, NewSourceFile("<text>", "Hello\nWorld!")),
+			expectedPos: NewPos(2, 5, 11, NewSourceFile("<text>", "Hello\nWorld!")),
 		},
 		{
 			name:        "Empty Text",
-			pos:         NewPos(1, 1, 0, "<text>", ""),
-			expectedPos: NewPos(1, 0, -1, "<text>", ""),
+			pos:         NewPos(1, 1, 0, NewSourceFile("<text>", "")),
+			expectedPos: NewPos(1, 0, -1, NewSourceFile("<text>", "")),
 		},
 		{
 			name:        "Single Char",
-			pos:         NewPos(1, 2, 1, "<text>", "a"),
-			expectedPos: NewPos(1, 1, 0, "<text>", "a"),
+			pos:         NewPos(1, 2, 1, NewSourceFile("<text>", "a")),
+			expectedPos: NewPos(1, 1, 0, NewSourceFile("<text>", "a")),
 		},
 		{
 			name:        "Single New Line",
-			pos:         NewPos(2, 1, 1, "<text>", "\n"),
-			expectedPos: NewPos(1, 1, 0, "<text>", "\n"),
+			pos:         NewPos(2, 1, 1, NewSourceFile("<text>", "\n")),
+			expectedPos: NewPos(1, 1, 0, NewSourceFile("<text>", "\n")),
 		},
 		{
 			name:        "Chinese Words",
-			pos:         NewPos(1, 2, 3, "<text>", "你好，世界！"),
-			expectedPos: NewPos(1, 1, 0, "<text>", "你好，世界！"),
+			pos:         NewPos(1, 2, 3, NewSourceFile("<text>", "你好，世界！")),
+			expectedPos: NewPos(1, 1, 0, NewSourceFile("<text>", "你好，世界！")),
 		},
 	}
 	for _, tt := range tests {
@@ -118,3 +118,44 @@ func TestPos_Backup(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceFile_LineStart(t *testing.T) {
+	source := NewSourceFile("<text>", "Hello\n你好\n\nWorld!")
+	tests := []struct {
+		name     string
+		source   *SourceFile
+		row      int
+		excepted int
+	}{
+		{name: "First line", source: source, row: 1, excepted: 0},
+		{name: "Wide characters", source: source, row: 3, excepted: 13},
+		{name: "Last line", source: source, row: 4, excepted: 14},
+		{name: "Row before first line", source: source, row: 0, excepted: 0},
+		{name: "Row after last line", source: source, row: 9, excepted: 14},
+		{name: "Literal source file", source: &SourceFile{Name: "<text>", Text: "a\nb"}, row: 2, excepted: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.source.LineStart(tt.row); got != tt.excepted {
+				t.Errorf("got = %d, expected %d", got, tt.excepted)
+			}
+		})
+	}
+}
+
+func TestPos_SharedSource(t *testing.T) {
+	source := NewSourceFile("main.gh", "var x;\nx;")
+	start := NewPos(2, 1, 7, source)
+	end := start.Copy()
+	end.Advance()
+	if start.Idx != 7 || end.Idx != 8 || end.Source != start.Source {
+		t.Errorf("start = %+v, end = %+v, expected independent positions in the same source", start, end)
+	}
+	if start.File() != "main.gh" || start.Text() != source.Text {
+		t.Errorf("File() = %q, Text() = %q, expected the source file's name and text", start.File(), start.Text())
+	}
+	var zero Pos
+	if zero.File() != "" || zero.Text() != "" {
+		t.Errorf("File() = %q, Text() = %q, expected empty strings without a source file", zero.File(), zero.Text())
+	}
+}
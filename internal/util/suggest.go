@@ -0,0 +1,73 @@
+package util
+
+import "sort"
+
+// minSuggestLength 查找相近名称的最短名称长度，更短的名称与几乎所有名称都相近，不给出建议
+const minSuggestLength = 3
+
+// SuggestName 在候选名称中查找与name编辑距离最小的名称，用于未定义变量的错误信息
+// 长度为3的名称允许距离为1，更长的名称允许距离为2；距离相同时取字典序最小的名称
+//
+// 参数:
+//
+//	name - 未定义的名称
+//	candidates - 候选名称，可以重复，顺序不影响结果
+//
+// 返回值:
+//
+//	string - 最相近的名称
+//	bool - 是否找到足够相近的名称
+func SuggestName(name string, candidates []string) (string, bool) {
+	length := len([]rune(name))
+	if length < minSuggestLength {
+		return "", false
+	}
+	maxDistance := 2
+	if length == minSuggestLength {
+		maxDistance = 1
+	}
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range sorted {
+		if candidate == name {
+			continue
+		}
+		if distance := levenshtein(name, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, best != ""
+}
+
+// levenshtein 计算两个字符串按字符插入、删除和替换的编辑距离
+//
+// 参数:
+//
+//	a - 第一个字符串
+//	b - 第二个字符串
+//
+// 返回值:
+//
+//	int - 编辑距离
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// 只保留上一行和当前行
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
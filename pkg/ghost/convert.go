@@ -0,0 +1,254 @@
+package ghost
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+)
+
+// FromGo 将Go值转换为脚本中的值，对照关系为:
+//
+//	nil、nil指针 -> null
+//	bool -> Bool
+//	int、int8 ~ int64、uint、uint8 ~ uint64、uintptr -> Int，超出int64范围时返回错误
+//	float32、float64 -> Float
+//	string -> String
+//	切片、数组 -> LIST，元素递归转换，元素类型必须一致，nil元素转换为null
+//	映射 -> MAP，键和值递归转换，键必须转换为Int、Float、String或Bool，按键排序插入
+//	指针、接口 -> 转换其指向的值
+//	Value -> 原样返回
+//
+// 通道、函数等其他类型返回错误
+//
+// 参数:
+//
+//	v - Go值
+//
+// 返回值:
+//
+//	Value - 转换后的值
+//	error - 类型不支持、整数超出范围、列表元素类型不一致或映射的键不可哈希时的错误
+func FromGo(v any) (Value, error) {
+	if value, ok := v.(Value); ok {
+		return value, nil
+	}
+	obj, err := fromReflect(reflect.ValueOf(v))
+	if err != nil {
+		return Value{}, fmt.Errorf("ghost: %w", err)
+	}
+	return Value{obj: obj}, nil
+}
+
+// fromReflect 按反射值的类型将其转换为解释器内部的值
+//
+// 参数:
+//
+//	rv - 反射值，无效的反射值表示nil
+//
+// 返回值:
+//
+//	object.Object - 解释器内部的值
+//	error - 转换失败时的错误
+func fromReflect(rv reflect.Value) (object.Object, error) {
+	if !rv.IsValid() {
+		return object.NullObj, nil
+	}
+	if rv.CanInterface() {
+		if value, ok := rv.Interface().(Value); ok {
+			return value.toObject(), nil
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return object.BoolOf(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Int{Value: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("integer %d overflows int64", rv.Uint())
+		}
+		return &object.Int{Value: int64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: rv.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: rv.String()}, nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return object.NullObj, nil
+		}
+		return fromReflect(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return &object.List{Elements: []object.Object{}}, nil
+		}
+		list := &object.List{Elements: make([]object.Object, 0, rv.Len())}
+		for i := 0; i < rv.Len(); i++ {
+			element, err := fromReflect(rv.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("list element %d: %w", i, err)
+			}
+			if !list.Accepts(element) {
+				return nil, fmt.Errorf("list element %d has type %s, expected %s", i, element.Type(), list.ElementType())
+			}
+			list.Elements = append(list.Elements, element)
+		}
+		return list, nil
+	case reflect.Map:
+		m := object.NewMap()
+		if rv.IsNil() {
+			return m, nil
+		}
+		entries := make([]*object.MapEntry, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := fromReflect(iter.Key())
+			if err != nil {
+				return nil, fmt.Errorf("map key %v: %w", iter.Key(), err)
+			}
+			if _, ok := key.(object.Hashable); !ok {
+				return nil, fmt.Errorf("map key has type %s, expected Int, Float, String or Bool", key.Type())
+			}
+			value, err := fromReflect(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("map value for key %s: %w", object.Repr(key, false), err)
+			}
+			entries = append(entries, &object.MapEntry{Key: key, Value: value})
+		}
+		// Go映射的遍历顺序是随机的，按键排序使转换结果稳定
+		slices.SortFunc(entries, func(a, b *object.MapEntry) int {
+			return compareKeys(a.Key, b.Key)
+		})
+		for _, entry := range entries {
+			if err := m.Set(entry.Key, entry.Value, nil, nil, nil); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("cannot convert Go value of type %s", rv.Type())
+	}
+}
+
+// compareKeys 比较两个映射键的顺序，不同类型的键按类型名排序，同类型的键按值排序
+//
+// 参数:
+//
+//	a - 第一个键
+//	b - 第二个键
+//
+// 返回值:
+//
+//	int - a在b之前时为负数，相等时为0，否则为正数
+func compareKeys(a, b object.Object) int {
+	if c := cmp.Compare(a.Type(), b.Type()); c != 0 {
+		return c
+	}
+	switch x := a.(type) {
+	case *object.Int:
+		return cmp.Compare(x.Value, b.(*object.Int).Value)
+	case *object.Float:
+		return cmp.Compare(x.Value, b.(*object.Float).Value)
+	case *object.String:
+		return cmp.Compare(x.Value, b.(*object.String).Value)
+	case *object.Bool:
+		if x.Value == b.(*object.Bool).Value {
+			return 0
+		}
+		if x.Value {
+			return 1
+		}
+		return -1
+	default:
+		return 0
+	}
+}
+
+// ToGo 将脚本中的值转换为Go值，对照关系为:
+//
+//	null -> nil
+//	Bool -> bool
+//	Int -> int64
+//	Float -> float64
+//	String -> string
+//	LIST -> []any，元素递归转换
+//	MAP -> 键全部为字符串时为map[string]any，否则为map[any]any，键和值递归转换
+//
+// 包含自身的列表和映射转换为同样包含自身的Go值；函数等其他值原样返回Value，可以再传回脚本
+//
+// 参数:
+//
+//	v - 脚本中的值
+//
+// 返回值:
+//
+//	any - 转换后的Go值
+func ToGo(v Value) any {
+	return toGo(v.obj, make(map[object.Object]any))
+}
+
+// toGo 递归地将解释器内部的值转换为Go值
+//
+// 参数:
+//
+//	obj - 解释器内部的值
+//	seen - 已经转换的列表和映射，再次遇到时返回同一个Go值
+//
+// 返回值:
+//
+//	any - 转换后的Go值
+func toGo(obj object.Object, seen map[object.Object]any) any {
+	switch o := obj.(type) {
+	case nil, *object.Null:
+		return nil
+	case *object.Bool:
+		return o.Value
+	case *object.Int:
+		return o.Value
+	case *object.Float:
+		return o.Value
+	case *object.String:
+		return o.Value
+	case *object.List:
+		if converted, ok := seen[o]; ok {
+			return converted
+		}
+		elements := make([]any, len(o.Elements))
+		seen[o] = elements
+		for i, element := range o.Elements {
+			elements[i] = toGo(element, seen)
+		}
+		return elements
+	case *object.Map:
+		if converted, ok := seen[o]; ok {
+			return converted
+		}
+		pairs := o.Pairs()
+		stringKeys := true
+		for _, entry := range pairs {
+			if _, ok := entry.Key.(*object.String); !ok {
+				stringKeys = false
+				break
+			}
+		}
+		if stringKeys {
+			m := make(map[string]any, len(pairs))
+			seen[o] = m
+			for _, entry := range pairs {
+				m[entry.Key.(*object.String).Value] = toGo(entry.Value, seen)
+			}
+			return m
+		}
+		m := make(map[any]any, len(pairs))
+		seen[o] = m
+		for _, entry := range pairs {
+			m[toGo(entry.Key, seen)] = toGo(entry.Value, seen)
+		}
+		return m
+	default:
+		return Value{obj: obj}
+	}
+}
@@ -0,0 +1,205 @@
+package ghost
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvert_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		excepted any
+	}{
+		{
+			name:     "Nil",
+			input:    nil,
+			excepted: nil,
+		},
+		{
+			name:     "Int64 Edge Values",
+			input:    []int64{math.MinInt64, -1, 0, math.MaxInt64},
+			excepted: []any{int64(math.MinInt64), int64(-1), int64(0), int64(math.MaxInt64)},
+		},
+		{
+			name:     "Integer Widths",
+			input:    []any{int8(-8), int16(16), int32(-32), uint8(8), uint32(32), uint64(math.MaxInt64), 7},
+			excepted: []any{int64(-8), int64(16), int64(-32), int64(8), int64(32), int64(math.MaxInt64), int64(7)},
+		},
+		{
+			name:     "Unicode Strings",
+			input:    [2]string{"héllo, 世界", "👻"},
+			excepted: []any{"héllo, 世界", "👻"},
+		},
+		{
+			name: "Nested Structure",
+			input: []any{
+				[]any{int64(math.MinInt64), nil, int64(math.MaxInt64)},
+				[]any{"ghost", "幽灵"},
+				[]any{float32(1.5), 2.25},
+				[]bool{true, false},
+				[]any{},
+			},
+			excepted: []any{
+				[]any{int64(math.MinInt64), nil, int64(math.MaxInt64)},
+				[]any{"ghost", "幽灵"},
+				[]any{1.5, 2.25},
+				[]any{true, false},
+				[]any{},
+			},
+		},
+		{
+			name:     "Pointer",
+			input:    &[]string{"a"},
+			excepted: []any{"a"},
+		},
+		{
+			name:     "String Keyed Map",
+			input:    map[string]any{"a": 1, "b": []any{"x"}, "c": map[string]any{"d": nil}},
+			excepted: map[string]any{"a": int64(1), "b": []any{"x"}, "c": map[string]any{"d": nil}},
+		},
+		{
+			name:     "Int Keyed Map",
+			input:    map[int]string{1: "one", 2: "two"},
+			excepted: map[any]any{int64(1): "one", int64(2): "two"},
+		},
+		{
+			name:     "Nil Map",
+			input:    map[string]int(nil),
+			excepted: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := FromGo(tt.input)
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if got := ToGo(value); !reflect.DeepEqual(got, tt.excepted) {
+				t.Errorf("excepted %#v, got %#v", tt.excepted, got)
+			}
+		})
+	}
+}
+
+func TestConvert_ScriptValues(t *testing.T) {
+	it := New()
+	value, err := it.EvalString("test", `[["a", "ü"], [null]];`)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	excepted := []any{[]any{"a", "ü"}, []any{nil}}
+	if got := ToGo(value); !reflect.DeepEqual(got, excepted) {
+		t.Errorf("excepted %#v, got %#v", excepted, got)
+	}
+	back, err := FromGo(ToGo(value))
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if !back.Equal(value) {
+		t.Errorf("excepted %s, got %s", value, back)
+	}
+
+	function, err := it.EvalString("test", "println;")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if got, ok := ToGo(function).(Value); !ok || !got.Equal(function) {
+		t.Errorf("excepted function to be returned as Value, got %#v", ToGo(function))
+	}
+}
+
+func TestConvert_MapOrder(t *testing.T) {
+	value, err := FromGo(map[string]int{"b": 2, "c": 3, "a": 1})
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if got := value.String(); got != "{a: 1, b: 2, c: 3}" {
+		t.Errorf("excepted keys in sorted order, got %s", got)
+	}
+}
+
+func TestConvert_ScriptMaps(t *testing.T) {
+	it := New()
+	value, err := it.EvalString("test", `var m = {"a": [1], "b": {1: true}}; m["self"] = m; m;`)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	got, ok := ToGo(value).(map[string]any)
+	if !ok {
+		t.Fatalf("excepted map[string]any, got %#v", ToGo(value))
+	}
+	if !reflect.DeepEqual(got["a"], []any{int64(1)}) || !reflect.DeepEqual(got["b"], map[any]any{int64(1): true}) {
+		t.Errorf("excepted converted values, got %#v", got)
+	}
+	if self, ok := got["self"].(map[string]any); !ok || reflect.ValueOf(self).Pointer() != reflect.ValueOf(got).Pointer() {
+		t.Errorf("excepted the map to contain itself, got %#v", got["self"])
+	}
+	back, err := FromGo(map[string]any{"a": []any{1}, "b": map[int]bool{1: true}})
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	excepted, err := it.EvalString("test", `{"a": [1], "b": {1: true}};`)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if !back.Equal(excepted) {
+		t.Errorf("excepted %s, got %s", excepted, back)
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		excepted string
+	}{
+		{
+			name:     "Channel",
+			input:    make(chan int),
+			excepted: "cannot convert Go value of type chan int",
+		},
+		{
+			name:     "Function",
+			input:    func() {},
+			excepted: "cannot convert Go value of type func()",
+		},
+		{
+			name:     "Unhashable Map Key",
+			input:    map[any]int{nil: 1},
+			excepted: "map key has type Null, expected Int, Float, String or Bool",
+		},
+		{
+			name:     "Unsupported Map Value",
+			input:    map[string]any{"a": make(chan int)},
+			excepted: `map value for key "a": cannot convert`,
+		},
+		{
+			name:     "Uint64 Overflow",
+			input:    uint64(math.MaxUint64),
+			excepted: "integer 18446744073709551615 overflows int64",
+		},
+		{
+			name:     "Mixed List",
+			input:    []any{1, "a"},
+			excepted: "list element 1 has type String, expected Int",
+		},
+		{
+			name:     "Nested Unsupported Element",
+			input:    []any{[]any{make(chan int)}},
+			excepted: "list element 0: list element 0: cannot convert",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromGo(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.excepted) {
+				t.Errorf("err = %+v, expected %q", err, tt.excepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package ghost
+
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// Error 词法、语法和运行时错误的公共接口，提供错误类型、位置和调用栈
+// 使用errors.As获取，Unwrap返回解释器内部的错误
+
+type Error interface {
+	error
+
+	// Kind 返回错误类型
+	//
+	// 返回值:
+	//
+	//	string - 错误类型，如"Syntax Error"、"Type Error"
+	Kind() string
+
+	// Code 返回稳定的错误代码
+	//
+	// 返回值:
+	//
+	//	string - 错误代码，如"E2001"
+	Code() string
+
+	// Message 返回不含位置信息的错误描述
+	//
+	// 返回值:
+	//
+	//	string - 错误描述文本
+	Message() string
+
+	// Start 返回错误的起始位置
+	//
+	// 返回值:
+	//
+	//	Position - 起始位置
+	Start() Position
+
+	// End 返回错误的结束位置
+	//
+	// 返回值:
+	//
+	//	Position - 结束位置
+	End() Position
+
+	// Frames 返回错误发生时的调用栈
+	//
+	// 返回值:
+	//
+	//	[]Frame - 从内到外排列的调用栈，最内层的位置为错误位置，词法和语法错误为空
+	Frames() []Frame
+}
+
+// Position 源代码中的位置
+
+type Position struct {
+	File   string // 源名称
+	Line   int    // 行号，从1开始计数
+	Column int    // 列号，从1开始计数
+	Offset int    // 字节偏移，从0开始计数
+}
+
+// Frame 调用栈中的一层
+
+type Frame struct {
+	Function string   // 函数名，如<function "f">，最外层为源名称
+	Start    Position // 该层中正在执行的表达式的起始位置
+	End      Position // 该层中正在执行的表达式的结束位置
+}
+
+// ExitError 脚本调用exit(n)产生的退出请求
+
+type ExitError struct {
+	Code int   // 请求的退出码
+	err  error // 解释器内部的退出请求
+}
+
+// Error 返回错误信息
+//
+// 返回值:
+//
+//	string - 错误信息
+func (e *ExitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 返回解释器内部的退出请求
+//
+// 返回值:
+//
+//	error - 解释器内部的退出请求
+func (e *ExitError) Unwrap() error {
+	return e.err
+}
+
+// scriptError 带位置信息的错误，实现Error接口
+
+type scriptError struct {
+	diagnostic *diagnostic.Diagnostic // 从错误中提取的诊断信息
+	err        error                  // 解释器内部的错误
+}
+
+// Error 返回带调用栈的错误信息
+//
+// 返回值:
+//
+//	string - 错误信息
+func (e *scriptError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 返回解释器内部的错误
+//
+// 返回值:
+//
+//	error - 解释器内部的错误
+func (e *scriptError) Unwrap() error {
+	return e.err
+}
+
+// Kind 返回错误类型
+//
+// 返回值:
+//
+//	string - 错误类型
+func (e *scriptError) Kind() string {
+	return e.diagnostic.Kind
+}
+
+// Code 返回稳定的错误代码
+//
+// 返回值:
+//
+//	string - 错误代码
+func (e *scriptError) Code() string {
+	return e.diagnostic.Code
+}
+
+// Message 返回不含位置信息的错误描述
+//
+// 返回值:
+//
+//	string - 错误描述文本
+func (e *scriptError) Message() string {
+	return e.diagnostic.Message
+}
+
+// Start 返回错误的起始位置
+//
+// 返回值:
+//
+//	Position - 起始位置
+func (e *scriptError) Start() Position {
+	return position(e.diagnostic.PosStart)
+}
+
+// End 返回错误的结束位置
+//
+// 返回值:
+//
+//	Position - 结束位置
+func (e *scriptError) End() Position {
+	return position(e.diagnostic.PosEnd)
+}
+
+// Frames 返回错误发生时的调用栈
+//
+// 返回值:
+//
+//	[]Frame - 从内到外排列的调用栈
+func (e *scriptError) Frames() []Frame {
+	stack := e.diagnostic.Stack()
+	frames := make([]Frame, len(stack))
+	for i, entry := range stack {
+		frames[i] = Frame{Function: entry.FuncName, Start: position(entry.PosStart), End: position(entry.PosEnd)}
+	}
+	return frames
+}
+
+// position 将解释器内部的位置转换为公共的位置
+//
+// 参数:
+//
+//	pos - 解释器内部的位置
+//
+// 返回值:
+//
+//	Position - 公共的位置
+func position(pos *util.Pos) Position {
+	return Position{File: pos.File(), Line: pos.Row, Column: pos.Col, Offset: pos.Idx}
+}
@@ -0,0 +1,60 @@
+package ghost_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Ghost-Xiao/ghost-lang/pkg/ghost"
+)
+
+func ExampleInterpreter_EvalString() {
+	it := ghost.New()
+	value, err := it.EvalString("example", "var x = 20;\nx * 2 + 2;")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	n, _ := value.Int64()
+	fmt.Println(n)
+	// Output: 42
+}
+
+func ExampleInterpreter_EvalString_sharedGlobals() {
+	it := ghost.New(ghost.WithArgs("alice"))
+	_, _ = it.EvalString("setup", "func greet(name) { return \"hello, \" + name; };")
+	value, _ := it.EvalString("main", "greet(args()[0]);")
+	fmt.Println(value)
+	// Output: hello, alice
+}
+
+func ExampleWithStdout() {
+	var out strings.Builder
+	it := ghost.New(ghost.WithStdout(&out))
+	_, _ = it.EvalString("main", "println(\"captured\");")
+	fmt.Print(strings.ToUpper(out.String()))
+	// Output: CAPTURED
+}
+
+func ExampleError() {
+	it := ghost.New()
+	_, err := it.EvalString("main", "var x = 1;\nx + \"a\";")
+	var scriptErr ghost.Error
+	if errors.As(err, &scriptErr) {
+		start := scriptErr.Start()
+		fmt.Printf("%s:%d:%d: %s: %s\n", start.File, start.Line, start.Column, scriptErr.Kind(), scriptErr.Message())
+	}
+	// Output: main:2:1: Operation Error: invalid operation "+".
+}
+
+func ExampleValue_Slice() {
+	value, _ := ghost.New().EvalString("main", "[1.5, 2.5, 3.5];")
+	elements, _ := value.Slice()
+	total := 0.0
+	for _, element := range elements {
+		f, _ := element.Float64()
+		total += f
+	}
+	fmt.Println(len(elements), total)
+	// Output: 3 7.5
+}
@@ -0,0 +1,419 @@
+// Package ghost 提供在Go程序中嵌入Ghost解释器的公共API
+// 解释器在持久的全局环境中执行源代码，多次执行之间共享变量、函数和已导入的模块
+
+package ghost
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/diagnostic"
+	"github.com/Ghost-Xiao/ghost-lang/internal/evaluator"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/lexer"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parsecache"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser"
+	"github.com/Ghost-Xiao/ghost-lang/internal/parser/ast"
+	"github.com/Ghost-Xiao/ghost-lang/internal/sema"
+)
+
+// config 创建解释器时的配置
+
+type config struct {
+	args    []string  // 传递给脚本的命令行参数，通过args()获取
+	strict  bool      // 是否启用严格数值模式
+	stdout  io.Writer // print、println等内置函数的输出
+	stderr  io.Writer // 内置函数的错误输出
+	sandbox bool      // 是否启用沙箱模式
+	limits  Limits    // 执行资源限制
+}
+
+// Limits 执行资源限制，为0的字段表示不限制，超出时返回的Error的Kind分别为
+// "Step Limit Error"、"Depth Limit Error"、"Alloc Limit Error"和"Timeout Error"
+//
+// 执行不受信任的代码时建议的取值为MaxSteps 10000000、MaxDepth 1000、MaxAlloc 64 << 20，
+// 即一般在一秒左右执行完毕、递归不会耗尽Go的栈空间、单个值不超过64MB
+// 步数和执行时间按每次Run或最外层的Call分别计算，执行时间每执行1024个节点检查一次，
+// 耗时的内置函数调用期间不会中断
+
+type Limits struct {
+	MaxSteps int64         // 最多执行的语法树节点数
+	MaxDepth int           // 脚本函数调用的最大嵌套深度
+	MaxAlloc int64         // 单个字符串或列表的最大字节数，字符串按UTF-8字节数计算，列表的每个元素按16字节计算
+	Timeout  time.Duration // 最长执行时间
+}
+
+// Option 创建解释器时的配置选项
+
+type Option func(*config)
+
+// WithArgs 设置脚本通过args()获取的命令行参数
+//
+// 参数:
+//
+//	args - 命令行参数
+//
+// 返回值:
+//
+//	Option - 配置选项
+func WithArgs(args ...string) Option {
+	return func(c *config) {
+		c.args = args
+	}
+}
+
+// WithStrict 设置是否启用严格数值模式，启用后整数和浮点数混合运算产生类型错误而不是提升为浮点数
+//
+// 参数:
+//
+//	strict - 是否启用严格数值模式
+//
+// 返回值:
+//
+//	Option - 配置选项
+func WithStrict(strict bool) Option {
+	return func(c *config) {
+		c.strict = strict
+	}
+}
+
+// WithStdout 设置print、println等内置函数的输出，未设置时使用os.Stdout，js/wasm平台上按行传递给全局函数ghostOutput
+//
+// 参数:
+//
+//	w - 输出
+//
+// 返回值:
+//
+//	Option - 配置选项
+func WithStdout(w io.Writer) Option {
+	return func(c *config) {
+		c.stdout = w
+	}
+}
+
+// WithStderr 设置内置函数的错误输出，未设置时使用os.Stderr，js/wasm平台上按行传递给全局函数ghostOutput
+//
+// 参数:
+//
+//	w - 错误输出
+//
+// 返回值:
+//
+//	Option - 配置选项
+func WithStderr(w io.Writer) Option {
+	return func(c *config) {
+		c.stderr = w
+	}
+}
+
+// WithSandbox 设置是否启用沙箱模式，用于执行不受信任的代码
+// 启用后exit、args等访问宿主系统的内置函数在调用时产生Sandbox Error，只能导入标准库模块；Register注册的Go函数不受影响
+//
+// 参数:
+//
+//	sandbox - 是否启用沙箱模式
+//
+// 返回值:
+//
+//	Option - 配置选项
+func WithSandbox(sandbox bool) Option {
+	return func(c *config) {
+		c.sandbox = sandbox
+	}
+}
+
+// WithLimits 设置执行资源限制
+//
+// 参数:
+//
+//	limits - 资源限制
+//
+// 返回值:
+//
+//	Option - 配置选项
+func WithLimits(limits Limits) Option {
+	return func(c *config) {
+		c.limits = limits
+	}
+}
+
+// Program 经过词法和语法分析的程序，可以在多个解释器中多次执行
+
+type Program struct {
+	name    string       // 源名称，错误位置中显示的文件名
+	file    string       // 源代码的绝对路径，用于解析导入路径
+	program *ast.Program // 程序的语法树
+}
+
+// Compile 对源代码进行词法和语法分析
+// path为源文件的路径时，相对导入路径基于其所在目录解析；不对应真实文件的源代码可以使用<stdin>等名称，此时基于工作目录解析
+//
+// 参数:
+//
+//	path - 源代码的路径或名称，错误位置中显示其文件名
+//	src - 源代码文本
+//
+// 返回值:
+//
+//	*Program - 编译后的程序
+//	error - 词法或语法错误，实现了Error接口
+func Compile(path, src string) (*Program, error) {
+	name, file := programPaths(path)
+	program, err := parse(name, src)
+	if err != nil {
+		return nil, err
+	}
+	return newProgram(name, file, program), nil
+}
+
+// CompileCached 与Compile相同，但将语法树缓存到源文件旁的<path>.ghostc文件中
+// 缓存文件与源代码匹配时直接使用缓存的语法树，跳过词法和语法分析；缓存文件不存在、已损坏或已过期时重新分析并写入缓存
+// 缓存文件无法写入时不影响编译结果
+//
+// 参数:
+//
+//	path - 源文件的路径，缓存文件位于同一目录
+//	src - 源代码文本
+//
+// 返回值:
+//
+//	*Program - 编译后的程序
+//	error - 词法或语法错误，实现了Error接口
+func CompileCached(path, src string) (*Program, error) {
+	name, file := programPaths(path)
+	cacheFile := parsecache.Path(path)
+	if program, ok := parsecache.Load(cacheFile, name, src); ok {
+		return newProgram(name, file, program), nil
+	}
+	program, err := parse(name, src)
+	if err != nil {
+		return nil, err
+	}
+	_ = parsecache.Store(cacheFile, src, program)
+	return newProgram(name, file, program), nil
+}
+
+// newProgram 创建编译后的程序，并对语法树进行作用域解析，执行时按槽位存取局部变量
+// 作用域解析只确定名称的存储位置，语义错误仍在执行到时报告
+//
+// 参数:
+//
+//	name - 源名称
+//	file - 用于解析导入路径的绝对路径
+//	program - 程序的语法树
+//
+// 返回值:
+//
+//	*Program - 编译后的程序
+func newProgram(name, file string, program *ast.Program) *Program {
+	sema.Resolve(program, sema.Options{File: file})
+	return &Program{name: name, file: file, program: program}
+}
+
+// programPaths 根据源代码的路径或名称确定源名称和用于解析导入路径的绝对路径
+//
+// 参数:
+//
+//	path - 源代码的路径或名称
+//
+// 返回值:
+//
+//	string - 源名称
+//	string - 绝对路径，无法确定时为path本身
+func programPaths(path string) (string, string) {
+	file, err := filepath.Abs(path)
+	if err != nil {
+		file = path
+	}
+	return filepath.Base(path), file
+}
+
+// parse 对源代码进行词法和语法分析
+//
+// 参数:
+//
+//	name - 源名称
+//	src - 源代码文本
+//
+// 返回值:
+//
+//	*ast.Program - 程序的语法树
+//	error - 词法或语法错误，实现了Error接口
+func parse(name, src string) (*ast.Program, error) {
+	l := lexer.NewLexer(name, src)
+	p, err := parser.NewParser(l)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	program := p.ParseProgram()
+	if p.Err != nil {
+		return nil, wrapError(p.Err)
+	}
+	return program, nil
+}
+
+// Interpreter 嵌入的Ghost解释器，不能被多个goroutine同时使用
+
+type Interpreter struct {
+	evaluator *evaluator.Evaluator // 执行程序的解释器，跨多次执行缓存已导入的模块
+	env       *object.Environment  // 持久的全局环境
+	active    int                  // 正在进行的Run和Call的嵌套层数，只在最外层开始执行时清零步数
+}
+
+// New 创建加载了内置函数的解释器
+//
+// 参数:
+//
+//	opts - 配置选项
+//
+// 返回值:
+//
+//	*Interpreter - 解释器
+func New(opts ...Option) *Interpreter {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	e := evaluator.NewEvaluator(nil)
+	e.Strict = c.strict
+	e.Stdout = c.stdout
+	e.Stderr = c.stderr
+	e.Sandbox = c.sandbox
+	e.Limits = evaluator.Limits{
+		MaxSteps: c.limits.MaxSteps,
+		MaxDepth: c.limits.MaxDepth,
+		MaxAlloc: c.limits.MaxAlloc,
+		Timeout:  c.limits.Timeout,
+	}
+	env := object.NewGlobalEnvironment(c.args)
+	if c.sandbox {
+		env.Sandbox()
+	}
+	return &Interpreter{
+		evaluator: e,
+		env:       env,
+	}
+}
+
+// EvalString 编译并执行源代码，相对导入路径基于工作目录解析
+//
+// 参数:
+//
+//	name - 源名称，显示在错误位置中
+//	src - 源代码文本
+//
+// 返回值:
+//
+//	Value - 最后一条顶层表达式语句的值，没有表达式语句时为null
+//	error - 词法、语法或运行时错误，实现了Error接口；脚本调用exit(n)时为*ExitError
+func (it *Interpreter) EvalString(name, src string) (Value, error) {
+	program, err := Compile(name, src)
+	if err != nil {
+		return Value{}, err
+	}
+	return it.Run(program)
+}
+
+// EvalFile 读取并执行源文件，制表符按4个空格处理
+//
+// 参数:
+//
+//	path - 源文件路径
+//
+// 返回值:
+//
+//	Value - 最后一条顶层表达式语句的值，没有表达式语句时为null
+//	error - 文件读取错误，或与EvalString相同的错误
+func (it *Interpreter) EvalFile(path string) (Value, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Value{}, fmt.Errorf("ghost: cannot read file: %w", err)
+	}
+	program, err := Compile(path, strings.ReplaceAll(string(data), "\t", "    "))
+	if err != nil {
+		return Value{}, err
+	}
+	return it.Run(program)
+}
+
+// Run 在解释器的全局环境中执行编译后的程序
+//
+// 参数:
+//
+//	program - 编译后的程序
+//
+// 返回值:
+//
+//	Value - 最后一条顶层表达式语句的值，没有表达式语句时为null
+//	error - 运行时错误，实现了Error接口；脚本调用exit(n)时为*ExitError
+func (it *Interpreter) Run(program *Program) (Value, error) {
+	e := it.evaluator
+	e.Frame = &frame.Frame{FuncName: program.name}
+	e.Err = nil
+	e.File = program.file
+	it.enter()
+	defer it.leave()
+	var result object.Object
+	for _, statement := range program.program.Statements {
+		result = nil
+		if expressionStatement, ok := statement.(*ast.ExpressionStatement); ok {
+			result = e.Eval(expressionStatement.Expr, it.env)
+		} else {
+			e.Eval(statement, it.env)
+		}
+		if e.Err != nil {
+			return Value{}, wrapError(e.Err)
+		}
+	}
+	return Value{obj: result}, nil
+}
+
+// enter 开始一次Run或Call，最外层开始执行时清零资源限制的步数
+func (it *Interpreter) enter() {
+	if it.active == 0 {
+		it.evaluator.ResetSteps()
+	}
+	it.active++
+}
+
+// leave 结束一次Run或Call
+func (it *Interpreter) leave() {
+	it.active--
+}
+
+// Sources 返回执行过程中读取过的所有模块文件的绝对路径
+// 包括加载失败的文件和找不到模块时尝试过的文件，不包括标准库模块，按字典序排列
+//
+// 返回值:
+//
+//	[]string - 模块文件的绝对路径
+func (it *Interpreter) Sources() []string {
+	return it.evaluator.Sources()
+}
+
+// wrapError 将解释器内部的错误包装为公共的错误类型
+//
+// 参数:
+//
+//	err - 解释器内部的错误
+//
+// 返回值:
+//
+//	error - 带位置信息的错误为Error，退出请求为*ExitError，其他错误原样返回
+func wrapError(err error) error {
+	var exitErr *object.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.Code, err: err}
+	}
+	if d, ok := diagnostic.FromError(err); ok {
+		return &scriptError{diagnostic: d, err: err}
+	}
+	return err
+}
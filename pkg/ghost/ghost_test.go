@@ -0,0 +1,272 @@
+package ghost
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInterpreter_EvalString(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		src      string
+		excepted string
+		kind     string
+	}{
+		{
+			name:     "Last Expression",
+			src:      "var x = 1;\nx + 2;",
+			excepted: "3",
+		},
+		{
+			name:     "Function Declaration Is Null",
+			src:      "func f() { return 1; };",
+			excepted: "null",
+		},
+		{
+			name:     "Args",
+			opts:     []Option{WithArgs("a", "b")},
+			src:      "len(args());",
+			excepted: "2",
+		},
+		{
+			name:     "Implicit Promotion",
+			src:      "1 + 2.5;",
+			excepted: "3.500000",
+		},
+		{
+			name: "Strict Mode",
+			opts: []Option{WithStrict(true)},
+			src:  "1 + 2.5;",
+			kind: "Type Error",
+		},
+		{
+			name: "Syntax Error",
+			src:  "var x = (1;",
+			kind: "Syntax Error",
+		},
+		{
+			name: "Runtime Error",
+			src:  "1 / 0;",
+			kind: "Math Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := New(tt.opts...).EvalString("test", tt.src)
+			if tt.kind != "" {
+				var scriptErr Error
+				if !errors.As(err, &scriptErr) {
+					t.Fatalf("excepted Error, got %+v", err)
+				}
+				if scriptErr.Kind() != tt.kind {
+					t.Errorf("excepted kind %q, got %q", tt.kind, scriptErr.Kind())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %+v, expected nil", err)
+			}
+			if value.String() != tt.excepted {
+				t.Errorf("excepted %q, got %q", tt.excepted, value.String())
+			}
+		})
+	}
+}
+
+func TestInterpreter_SharedGlobals(t *testing.T) {
+	it := New()
+	if _, err := it.EvalString("first", "var count = 1;"); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	value, err := it.EvalString("second", "count += 1;\ncount;")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if n, ok := value.Int64(); !ok || n != 2 {
+		t.Errorf("excepted 2, got %s", value)
+	}
+}
+
+func TestInterpreter_ErrorFrames(t *testing.T) {
+	src := "func f() {\n    return 1 + \"a\";\n};\nf();"
+	_, err := New().EvalString("main", src)
+	var scriptErr Error
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("excepted Error, got %+v", err)
+	}
+	var functions []string
+	var lines []int
+	for _, f := range scriptErr.Frames() {
+		functions = append(functions, f.Function)
+		lines = append(lines, f.Start.Line)
+	}
+	if excepted := []string{`<function "f">`, "main"}; !reflect.DeepEqual(functions, excepted) {
+		t.Errorf("excepted functions %v, got %v", excepted, functions)
+	}
+	if excepted := []int{2, 4}; !reflect.DeepEqual(lines, excepted) {
+		t.Errorf("excepted lines %v, got %v", excepted, lines)
+	}
+	if start := scriptErr.Start(); start != scriptErr.Frames()[0].Start {
+		t.Errorf("excepted error start %+v to match innermost frame, got %+v", scriptErr.Frames()[0].Start, start)
+	}
+}
+
+func TestInterpreter_ErrorCode(t *testing.T) {
+	_, err := New().EvalString("main", "var x = (1;")
+	// 宿主程序包装之后仍然可以获取错误代码
+	err = fmt.Errorf("loading config: %w", err)
+	var scriptErr Error
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("excepted Error, got %+v", err)
+	}
+	if scriptErr.Kind() != "Syntax Error" || scriptErr.Code() != "E2003" {
+		t.Errorf("excepted Syntax Error E2003, got %s %s", scriptErr.Kind(), scriptErr.Code())
+	}
+}
+
+func TestInterpreter_Exit(t *testing.T) {
+	_, err := New().EvalString("main", "exit(3);")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("excepted ExitError, got %+v", err)
+	}
+	if exitErr.Code != 3 {
+		t.Errorf("excepted code 3, got %d", exitErr.Code)
+	}
+}
+
+func TestInterpreter_EvalFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %+v", name, err)
+		}
+		return path
+	}
+	module := writeFile("module.gh", "var answer = 42;")
+	main := writeFile("main.gh", "import \"module.gh\";\nanswer;")
+
+	it := New()
+	value, err := it.EvalFile(main)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if n, ok := value.Int64(); !ok || n != 42 {
+		t.Errorf("excepted 42, got %s", value)
+	}
+	if excepted := []string{module}; !reflect.DeepEqual(it.Sources(), excepted) {
+		t.Errorf("excepted sources %v, got %v", excepted, it.Sources())
+	}
+	if _, err := it.EvalFile(filepath.Join(dir, "missing.gh")); err == nil {
+		t.Errorf("excepted error for missing file")
+	}
+}
+
+func TestInterpreter_Output(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	it := New(WithStdout(&stdout), WithStderr(&stderr))
+	if _, err := it.EvalString("first", `print("a");`); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if _, err := it.EvalString("second", `println("b");`); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if stdout.String() != "ab\n" {
+		t.Errorf("excepted %q, got %q", "ab\n", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("excepted empty stderr, got %q", stderr.String())
+	}
+}
+
+func TestInterpreter_Sandbox(t *testing.T) {
+	it := New(WithSandbox(true), WithArgs("secret"))
+	it.Register("double", []string{"n"}, func(args ...Value) (Value, error) {
+		n, _ := args[0].Int64()
+		return FromGo(n * 2)
+	})
+	value, err := it.EvalString("main", "double(21);")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if n, ok := value.Int64(); !ok || n != 42 {
+		t.Errorf("excepted 42, got %s", value)
+	}
+	for _, src := range []string{"args();", "exit(1);", "import \"module.gh\";"} {
+		_, err := it.EvalString("main", src)
+		var scriptErr Error
+		if !errors.As(err, &scriptErr) || scriptErr.Kind() != "Sandbox Error" {
+			t.Errorf("%s: excepted Sandbox Error, got %+v", src, err)
+		}
+	}
+}
+
+func TestInterpreter_Limits(t *testing.T) {
+	it := New(WithLimits(Limits{MaxSteps: 500}))
+	_, err := it.EvalString("main", "for var i = 0; i < 1; i += 0 { };")
+	var scriptErr Error
+	if !errors.As(err, &scriptErr) || scriptErr.Kind() != "Step Limit Error" {
+		t.Fatalf("excepted Step Limit Error, got %+v", err)
+	}
+	// 步数在每次执行之前清零
+	if _, err := it.EvalString("main", "func count(n) { var total = 0; for var i = 0; i < n; i += 1 { total += i; }; return total; };"); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	for i := 0; i < 3; i++ {
+		n, _ := FromGo(10)
+		if _, err := it.Call("count", n); err != nil {
+			t.Fatalf("err = %+v, expected nil", err)
+		}
+	}
+	n, _ := FromGo(1000)
+	if _, err := it.Call("count", n); !errors.As(err, &scriptErr) || scriptErr.Kind() != "Step Limit Error" {
+		t.Errorf("excepted Step Limit Error, got %+v", err)
+	}
+}
+
+func TestInterpreter_Timeout(t *testing.T) {
+	it := New(WithLimits(Limits{Timeout: 20 * time.Millisecond}))
+	start := time.Now()
+	_, err := it.EvalString("main", "for var i = 0; i < 1; i += 0 { };")
+	var scriptErr Error
+	if !errors.As(err, &scriptErr) || scriptErr.Kind() != "Timeout Error" {
+		t.Fatalf("excepted Timeout Error, got %+v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("excepted the loop to stop shortly after the timeout, took %s", elapsed)
+	}
+	// 执行时间在每次执行之前重新计算
+	if _, err := it.EvalString("main", "var total = 0; for var i = 0; i < 100; i += 1 { total += i; };"); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+}
+
+func TestValue_Accessors(t *testing.T) {
+	value, err := New().EvalString("test", "[true, false];")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if _, ok := value.Int64(); ok {
+		t.Errorf("excepted list not to be an int")
+	}
+	elements, ok := value.Slice()
+	if !ok || len(elements) != 2 {
+		t.Fatalf("excepted 2 elements, got %s", value)
+	}
+	if b, ok := elements[0].Bool(); !ok || !b {
+		t.Errorf("excepted true, got %s", elements[0])
+	}
+	var zero Value
+	if !zero.IsNull() || zero.Type() != "Null" || zero.String() != "null" {
+		t.Errorf("excepted zero value to be null, got %s %s", zero.Type(), zero)
+	}
+}
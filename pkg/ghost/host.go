@@ -0,0 +1,120 @@
+package ghost
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ghost-Xiao/ghost-lang/internal/errcode"
+	"github.com/Ghost-Xiao/ghost-lang/internal/frame"
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+	"github.com/Ghost-Xiao/ghost-lang/internal/util"
+)
+
+// hostFrameName 宿主程序调用脚本函数时使用的根栈帧名称
+const hostFrameName = "<host>"
+
+// Func 宿主程序注册给脚本调用的Go函数
+
+type Func func(args ...Value) (Value, error)
+
+// Register 在全局环境中注册可以被脚本调用的Go函数，同名的全局符号会被替换
+// Go函数中可以通过Call再次调用脚本中的函数，返回的错误在脚本中表现为Host Error
+//
+// 参数:
+//
+//	name - 函数名
+//	params - 参数名，脚本调用时传入的参数数量必须与之相同
+//	fn - Go函数
+func (it *Interpreter) Register(name string, params []string, fn Func) {
+	builtin := &object.BuiltinFunction{
+		Name:      name,
+		Parameter: params,
+		Fn: func(_ object.Interpreter, f *frame.Frame, posStart, posEnd *util.Pos, args ...object.Object) (object.Object, error) {
+			values := make([]Value, len(args))
+			for i, arg := range args {
+				values[i] = Value{obj: arg}
+			}
+			result, err := fn(values...)
+			if err != nil {
+				return nil, hostError(err, f, posStart, posEnd)
+			}
+			return result.toObject(), nil
+		},
+	}
+	it.env.Set(name, &object.Symbol{Name: name, Value: builtin, IsConst: true})
+}
+
+// Call 调用全局环境中的脚本函数或内置函数
+// 函数在以<host>为根的新调用栈中执行，可以在Register注册的Go函数执行期间重入，返回后恢复原来的调用栈
+//
+// 参数:
+//
+//	name - 函数名
+//	args - 按位置传入的参数
+//
+// 返回值:
+//
+//	Value - 函数的返回值
+//	error - 函数不存在或不可调用时的错误，或与Run相同的运行时错误
+func (it *Interpreter) Call(name string, args ...Value) (Value, error) {
+	symbol, ok := it.env.Get(name)
+	if !ok {
+		return Value{}, fmt.Errorf("ghost: undefined function %q", name)
+	}
+	switch symbol.Value.(type) {
+	case *object.Function, *object.BuiltinFunction:
+	default:
+		return Value{}, fmt.Errorf("ghost: %q is not a function, got %s", name, symbol.Value.Type())
+	}
+	argument := make([]object.Object, len(args))
+	for i, arg := range args {
+		argument[i] = arg.toObject()
+	}
+
+	e := it.evaluator
+	// 重入时保存外层执行的调用栈和错误状态，调用结束后恢复
+	outerFrame, outerErr := e.Frame, e.Err
+	defer func() {
+		e.Frame, e.Err = outerFrame, outerErr
+	}()
+	e.Frame = &frame.Frame{FuncName: hostFrameName}
+	e.Err = nil
+	it.enter()
+	defer it.leave()
+	result, err := e.CallFunction(symbol.Value, argument, nil, nil)
+	if err != nil {
+		return Value{}, wrapError(err)
+	}
+	return Value{obj: result}, nil
+}
+
+// hostError 将Go函数返回的错误转换为解释器内部的错误
+// 嵌套调用脚本函数产生的错误和退出请求原样传递，其他错误转换为HostError
+//
+// 参数:
+//
+//	err - Go函数返回的错误
+//	f - Go函数的调用栈帧
+//	posStart - 调用的起始位置
+//	posEnd - 调用的结束位置
+//
+// 返回值:
+//
+//	error - 解释器内部的错误
+func hostError(err error, f *frame.Frame, posStart, posEnd *util.Pos) error {
+	var scriptErr *scriptError
+	if errors.As(err, &scriptErr) {
+		return scriptErr.err
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.err
+	}
+	return &object.HostError{
+		Code:     errcode.HostError,
+		Frame:    f,
+		Message:  err.Error(),
+		PosStart: posStart,
+		PosEnd:   posEnd,
+	}
+}
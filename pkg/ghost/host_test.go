@@ -0,0 +1,138 @@
+package ghost
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newHostInterpreter 创建注册了回调脚本函数的Go函数并定义了测试函数的解释器
+func newHostInterpreter(t *testing.T) *Interpreter {
+	t.Helper()
+	it := New()
+	// twice 在Go中调用脚本函数square，再将结果乘以2
+	it.Register("twice", []string{"n"}, func(args ...Value) (Value, error) {
+		squared, err := it.Call("square", args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		n, _ := squared.Int64()
+		return FromGo(n * 2)
+	})
+	it.Register("fail", []string{"message"}, func(args ...Value) (Value, error) {
+		return Value{}, errors.New(args[0].String())
+	})
+	src := strings.Join([]string{
+		"func square(x) { return x * x; };",
+		"func handler(n) { var y = twice(n); return y + 1; };",
+		"func broken(n) { var y = twice(n); return y + \"a\"; };",
+		"func nested(n) { return twice(\"a\"); };",
+		"var limit = 10;",
+	}, "\n")
+	if _, err := it.EvalString("main", src); err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	return it
+}
+
+func TestInterpreter_Call(t *testing.T) {
+	it := newHostInterpreter(t)
+	arg, err := FromGo(3)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	value, err := it.Call("handler", arg)
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if n, ok := value.Int64(); !ok || n != 19 {
+		t.Errorf("excepted 19, got %s", value)
+	}
+	// 调用结束后解释器仍可正常执行脚本
+	value, err = it.EvalString("after", "handler(2);")
+	if err != nil {
+		t.Fatalf("err = %+v, expected nil", err)
+	}
+	if n, ok := value.Int64(); !ok || n != 9 {
+		t.Errorf("excepted 9, got %s", value)
+	}
+}
+
+func TestInterpreter_CallErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		function  string
+		src       string
+		kind      string
+		message   string
+		functions []string
+	}{
+		{
+			name:      "Error After Reentrant Call",
+			function:  "broken",
+			kind:      "Operation Error",
+			message:   "invalid operation \"+\".",
+			functions: []string{`<function "broken">`},
+		},
+		{
+			name:      "Error Inside Nested Call",
+			function:  "nested",
+			kind:      "Operation Error",
+			message:   "invalid operation \"*\".",
+			functions: []string{`<function "square">`},
+		},
+		{
+			name:      "Host Error",
+			src:       "func f() { fail(\"boom\"); }; f();",
+			kind:      "Host Error",
+			message:   "boom",
+			functions: []string{`<builtin "fail">`, `<function "f">`, "test"},
+		},
+		{
+			name:     "Undefined Function",
+			function: "missing",
+			message:  `ghost: undefined function "missing"`,
+		},
+		{
+			name:     "Not A Function",
+			function: "limit",
+			message:  `ghost: "limit" is not a function, got Int`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := newHostInterpreter(t)
+			var err error
+			if tt.src != "" {
+				_, err = it.EvalString("test", tt.src)
+			} else {
+				arg, _ := FromGo(3)
+				_, err = it.Call(tt.function, arg)
+			}
+			if err == nil {
+				t.Fatalf("err = nil, expected error")
+			}
+			if tt.kind == "" {
+				if err.Error() != tt.message {
+					t.Errorf("excepted %q, got %q", tt.message, err.Error())
+				}
+				return
+			}
+			var scriptErr Error
+			if !errors.As(err, &scriptErr) {
+				t.Fatalf("excepted Error, got %+v", err)
+			}
+			if scriptErr.Kind() != tt.kind || scriptErr.Message() != tt.message {
+				t.Errorf("excepted %s: %s, got %s: %s", tt.kind, tt.message, scriptErr.Kind(), scriptErr.Message())
+			}
+			var functions []string
+			for _, f := range scriptErr.Frames() {
+				functions = append(functions, f.Function)
+			}
+			if strings.Join(functions, ",") != strings.Join(tt.functions, ",") {
+				t.Errorf("excepted frames %v, got %v", tt.functions, functions)
+			}
+		})
+	}
+}
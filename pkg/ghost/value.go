@@ -0,0 +1,135 @@
+package ghost
+
+import (
+	"github.com/Ghost-Xiao/ghost-lang/internal/object"
+)
+
+// Value 脚本中的值，零值表示null
+
+type Value struct {
+	obj object.Object // 解释器内部的值，为nil时表示null
+}
+
+// Type 返回值的类型
+//
+// 返回值:
+//
+//	string - 值的类型，如"Int"、"String"、"LIST"、"Null"
+func (v Value) Type() string {
+	if v.obj == nil {
+		return "Null"
+	}
+	return v.obj.Type()
+}
+
+// IsNull 判断值是否为null
+//
+// 返回值:
+//
+//	bool - 是否为null
+func (v Value) IsNull() bool {
+	if v.obj == nil {
+		return true
+	}
+	_, ok := v.obj.(*object.Null)
+	return ok
+}
+
+// Int64 返回整数的值
+//
+// 返回值:
+//
+//	int64 - 整数的值，不是整数时为0
+//	bool - 是否为整数
+func (v Value) Int64() (int64, bool) {
+	if i, ok := v.obj.(*object.Int); ok {
+		return i.Value, true
+	}
+	return 0, false
+}
+
+// Float64 返回数值的浮点数形式，整数会转换为浮点数
+//
+// 返回值:
+//
+//	float64 - 数值，不是整数或浮点数时为0
+//	bool - 是否为整数或浮点数
+func (v Value) Float64() (float64, bool) {
+	switch n := v.obj.(type) {
+	case *object.Float:
+		return n.Value, true
+	case *object.Int:
+		return float64(n.Value), true
+	default:
+		return 0, false
+	}
+}
+
+// Bool 返回布尔值
+//
+// 返回值:
+//
+//	bool - 布尔值，不是布尔值时为false
+//	bool - 是否为布尔值
+func (v Value) Bool() (bool, bool) {
+	if b, ok := v.obj.(*object.Bool); ok {
+		return b.Value, true
+	}
+	return false, false
+}
+
+// Slice 返回列表的元素
+//
+// 返回值:
+//
+//	[]Value - 列表元素的副本，修改不影响脚本中的列表
+//	bool - 是否为列表
+func (v Value) Slice() ([]Value, bool) {
+	list, ok := v.obj.(*object.List)
+	if !ok {
+		return nil, false
+	}
+	values := make([]Value, len(list.Elements))
+	for i, element := range list.Elements {
+		values[i] = Value{obj: element}
+	}
+	return values, true
+}
+
+// String 返回值的字符串表示，字符串返回其内容本身
+//
+// 返回值:
+//
+//	string - 字符串表示，与println的输出相同
+func (v Value) String() string {
+	if v.obj == nil {
+		return "null"
+	}
+	return v.obj.String()
+}
+
+// Equal 判断两个值在结构上是否相等
+// 类型必须相同，列表逐个比较元素，函数只与自身相等；零值与null相等
+//
+// 参数:
+//
+//	other - 另一个值
+//
+// 返回值:
+//
+//	bool - 两个值是否相等
+func (v Value) Equal(other Value) bool {
+	return object.DeepEquals(v.toObject(), other.toObject())
+}
+
+// toObject 返回解释器内部的值，零值转换为null
+//
+// 返回值:
+//
+//	object.Object - 解释器内部的值
+func (v Value) toObject() object.Object {
+	if v.obj == nil {
+		return object.NullObj
+	}
+	return v.obj
+}